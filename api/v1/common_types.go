@@ -16,6 +16,11 @@ limitations under the License.
 
 package v1
 
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
 // Metadata contains the standard Kubernetes labels and annotations fields.
 type Metadata struct {
 	// Labels is a map of string keys and values that may match replicaset and
@@ -30,3 +35,47 @@ type Metadata struct {
 	// +optional
 	Annotations map[string]string `json:"annotations,omitempty"`
 }
+
+// RolloutWindow is a recurring maintenance window. Rollouts that only pick up changed configuration or certificates,
+// which restart the pods of a component, are deferred until the window opens, and batched into a single rollout.
+// Changes to the spec of the component are still rolled out immediately.
+type RolloutWindow struct {
+	// Schedule is when the window opens, in the standard cron syntax of five fields: minute, hour, day of the month,
+	// month and day of the week, in UTC, or a descriptor such as "@daily". E.g. "0 2 * * 6" opens the window at 02:00
+	// every Saturday.
+	Schedule string `json:"schedule"`
+
+	// Duration is how long the window stays open.
+	// Default: 1h
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+}
+
+// PodDisruptionBudget defines the PodDisruptionBudget of the pods of a component, which limits how many of them are
+// disrupted at once by voluntary disruptions such as node drains.
+type PodDisruptionBudget struct {
+	// MinAvailable is the number or percentage of the pods that must remain available during voluntary disruptions.
+	// If omitted, at most one pod is disrupted at a time.
+	// +optional
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+}
+
+// DisruptionPolicy defines how the pods of a component are stopped, e.g. when their nodes are drained, and how many of
+// them are disrupted at once. Settings that are omitted keep the defaults of the component.
+type DisruptionPolicy struct {
+	// TerminationGracePeriodSeconds is how long the pods are given to stop before they are killed.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+
+	// PreStopDelaySeconds is how long the pods keep serving after they are asked to stop and before they are sent
+	// SIGTERM, e.g. so that the Services stop sending them new connections first. The delay counts towards the
+	// termination grace period. It is only supported by the components whose images ship a shell to sleep in.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	PreStopDelaySeconds *int64 `json:"preStopDelaySeconds,omitempty"`
+
+	// PodDisruptionBudget limits how many pods are disrupted at once by voluntary disruptions.
+	// +optional
+	PodDisruptionBudget *PodDisruptionBudget `json:"podDisruptionBudget,omitempty"`
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -30,12 +32,385 @@ type LogCollectorSpec struct {
 	// +optional
 	AdditionalSources *AdditionalLogSourceSpec `json:"additionalSources,omitempty"`
 
+	// Candidate runs a candidate fluentd, e.g. with a new image or configuration, next to fluentd on a subset of the
+	// nodes, to validate it before it is promoted to all nodes. The candidate reads the same logs as fluentd and tags
+	// its outputs with a suffix. Its DaemonSet is removed once it is promoted or when this is omitted.
+	// +optional
+	Candidate *FluentdCandidate `json:"candidate,omitempty"`
+
 	// Configuration for enabling/disabling process path collection in flowlogs.
 	// If Enabled, this feature sets hostPID to true in order to read process cmdline.
 	// Default: Enabled
 	// +optional
 	// +kubebuilder:validation:Enum=Enabled;Disabled
 	CollectProcessPath *CollectProcessPathOption `json:"collectProcessPath,omitempty"`
+
+	// ComponentDisruptionPolicies define how the pods of each component are stopped and how many of them are disrupted
+	// at once. Fluentd and EKSLogForwarder are supported. Fluentd runs on every node, so its pods can't have a
+	// PodDisruptionBudget. The settings of the policy of Fluentd take precedence over terminationGracePeriodSeconds,
+	// and the PodDisruptionBudget of the EKS log source over that of the policy of EKSLogForwarder.
+	// +optional
+	// +listType=map
+	// +listMapKey=componentName
+	ComponentDisruptionPolicies []LogCollectorComponentDisruptionPolicy `json:"componentDisruptionPolicies,omitempty"`
+
+	// ComponentResources can be used to customize the resource requirements for each component.
+	// Only Fluentd is supported for this spec. If omitted, the defaults are used, or the presets for the provider of
+	// the cluster if ProviderResourcePresets is enabled.
+	// +optional
+	ComponentResources []LogCollectorComponentResource `json:"componentResources,omitempty"`
+
+	// ProviderResourcePresets is whether the components that are not customized use the resource presets for the
+	// provider of the cluster, e.g. larger Elasticsearch nodes on EKS, instead of the default resources. Enabling the
+	// presets restarts the components whose resources change.
+	// Default: Disabled
+	// +optional
+	// +kubebuilder:validation:Enum=Enabled;Disabled
+	ProviderResourcePresets *ProviderResourcePresetsOption `json:"providerResourcePresets,omitempty"`
+
+	// ContainerLogs configures where fluentd finds the log files of the containers on the nodes, e.g. the logs of the
+	// envoy proxies of the L7 log collection. If omitted, the log files of the containers aren't mounted into fluentd.
+	// +optional
+	ContainerLogs *ContainerLogs `json:"containerLogs,omitempty"`
+
+	// ControlPlaneNodes configures fluentd on the control plane nodes, which are the nodes with the
+	// node-role.kubernetes.io/control-plane or node-role.kubernetes.io/master label.
+	// +optional
+	ControlPlaneNodes *FluentdControlPlaneNodes `json:"controlPlaneNodes,omitempty"`
+
+	// DNSConfig adds DNS resolution options to the fluentd pods, such as nameservers, search domains and ndots, e.g. so
+	// that the es-gateway names resolve on clusters with node-local DNS caching and custom cluster domains. It is merged
+	// with the DNS configuration that the cluster generates for the pods.
+	// +optional
+	DNSConfig *corev1.PodDNSConfig `json:"dnsConfig,omitempty"`
+
+	// ElasticsearchOutput tunes how fluentd sends the logs to Elasticsearch, e.g. to send smaller bulk requests to a
+	// small cluster, or to wait longer for a big one. If omitted, the defaults of fluentd are used.
+	// +optional
+	ElasticsearchOutput *FluentdElasticsearchOutput `json:"elasticsearchOutput,omitempty"`
+
+	// ESGatewayTokenAuth makes fluentd authenticate to the Elasticsearch gateway with projected service account tokens
+	// instead of the credentials of its Elasticsearch user. It requires a fluentd image that supports the token
+	// authentication. It is ignored on managed clusters, whose fluentd authenticates to the management cluster.
+	// Default: Disabled
+	// +optional
+	ESGatewayTokenAuth ESGatewayTokenAuthType `json:"esGatewayTokenAuth,omitempty"`
+
+	// ImagePullSecrets are container registry pull secrets of fluentd and the EKS log forwarder, e.g. to pull their
+	// images from another registry than the other images. The secrets must be in the namespace of the operator. They
+	// are used along with the ImagePullSecrets of the Installation and copied to the namespace of fluentd, so their
+	// names must differ from those of the Installation.
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// LogFileRotation bounds the disk usage of the log files in /var/log/calico on each node, which Felix and the CNI
+	// plugin write and fluentd collects. If omitted, the defaults of Felix and the CNI plugin are used.
+	// +optional
+	LogFileRotation *LogFileRotation `json:"logFileRotation,omitempty"`
+
+	// Mode is which logs fluentd forwards. AuditOnly forwards only the audit logs to the additional log stores and
+	// skips the flow and DNS log filters, e.g. on clusters whose sole requirement is the collection of compliance
+	// evidence, with smaller default resource requirements for fluentd. The syslog store then forwards the audit logs
+	// if it has no log types.
+	// Default: Full
+	// +optional
+	Mode LogCollectorMode `json:"mode,omitempty"`
+
+	// NodePools override the environment of fluentd on the nodes of each pool, e.g. to flush the logs of the nodes
+	// that generate many flows more often. The fluentd pods of each pool are run by a DaemonSet of their own. A node
+	// that matches several pools belongs to the first one.
+	// +optional
+	NodePools []FluentdNodePool `json:"nodePools,omitempty"`
+
+	// RedactionRules hash or drop fields of the flow and DNS logs, such as source IPs or DNS query names, before the logs
+	// are sent to any of the log stores, e.g. to export them to third-party SIEMs in compliance with privacy
+	// regulations. They apply after the filters of the fluentd-filters ConfigMap.
+	// +optional
+	RedactionRules []LogRedactionRule `json:"redactionRules,omitempty"`
+
+	// RolloutWindow defers the restarts of fluentd for changed configuration or certificates, e.g. of its filters or
+	// of the credentials of the log stores, until the window opens, to avoid restarting fluentd on every node during
+	// business hours. If omitted, fluentd is restarted as soon as they change.
+	// +optional
+	RolloutWindow *RolloutWindow `json:"rolloutWindow,omitempty"`
+
+	// TerminationGracePeriodSeconds is how long fluentd is given to flush the logs that it buffers before it is killed
+	// when its pod is stopped, e.g. when its node is drained.
+	// Default: 30
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+
+	// VerticalPodAutoscaling configures VerticalPodAutoscalers for fluentd and the EKS log forwarder, so that their
+	// memory requests track their actual usage, e.g. the flow volumes of the nodes of each node pool. The
+	// VerticalPodAutoscaler API must be installed in the cluster. If omitted, no VerticalPodAutoscalers are created.
+	// +optional
+	VerticalPodAutoscaling *LogCollectorVerticalPodAutoscaling `json:"verticalPodAutoscaling,omitempty"`
+}
+
+// ESGatewayTokenAuthType defines whether fluentd authenticates to the Elasticsearch gateway with service account tokens.
+// +kubebuilder:validation:Enum=Enabled;Disabled
+type ESGatewayTokenAuthType string
+
+const (
+	ESGatewayTokenAuthEnabled  ESGatewayTokenAuthType = "Enabled"
+	ESGatewayTokenAuthDisabled ESGatewayTokenAuthType = "Disabled"
+)
+
+// ESGatewayTokenAuthEnabled returns whether fluentd authenticates to the Elasticsearch gateway with service account
+// tokens.
+func (s *LogCollectorSpec) ESGatewayTokenAuthEnabled() bool {
+	return s != nil && s.ESGatewayTokenAuth == ESGatewayTokenAuthEnabled
+}
+
+// ContainerRuntime is the container runtime of the nodes, which determines where and in which format the log files of
+// the containers are written.
+// +kubebuilder:validation:Enum=Docker;Containerd;CRIO
+type ContainerRuntime string
+
+const (
+	ContainerRuntimeDocker     ContainerRuntime = "Docker"
+	ContainerRuntimeContainerd ContainerRuntime = "Containerd"
+	ContainerRuntimeCRIO       ContainerRuntime = "CRIO"
+)
+
+// ContainerLogs configures where the log files of the containers are found on the nodes.
+type ContainerLogs struct {
+	// Runtime is the container runtime of the nodes. Docker writes the logs of the containers as JSON under
+	// /var/lib/docker/containers, while containerd and CRI-O write them in the CRI format under /var/log/pods. If
+	// omitted, the runtime is detected from the nodes, which must then all report the same runtime. If the nodes don't
+	// report a known runtime on a cluster provisioned by Cluster API, it defaults to containerd, which the node images
+	// of its infrastructure providers run. The runtime of the nodes of a node pool is overridden in the pool.
+	// +optional
+	Runtime *ContainerRuntime `json:"runtime,omitempty"`
+
+	// BasePath is the directory that the container runtime writes the log files of the containers to, which the files
+	// in /var/log/containers link to. It overrides the default directory of the runtime, e.g. for Docker with a custom
+	// data root.
+	// +optional
+	BasePath string `json:"basePath,omitempty"`
+}
+
+// VerticalPodAutoscalingMode controls when the memory requests recommended by a VerticalPodAutoscaler are applied.
+// +kubebuilder:validation:Enum=Off;Initial;Auto
+type VerticalPodAutoscalingMode string
+
+const (
+	// VerticalPodAutoscalingOff only computes the recommended memory requests, without applying them.
+	VerticalPodAutoscalingOff VerticalPodAutoscalingMode = "Off"
+	// VerticalPodAutoscalingInitial applies the recommended memory requests to pods when they are created.
+	VerticalPodAutoscalingInitial VerticalPodAutoscalingMode = "Initial"
+	// VerticalPodAutoscalingAuto also applies the recommended memory requests to running pods, which restarts them.
+	VerticalPodAutoscalingAuto VerticalPodAutoscalingMode = "Auto"
+)
+
+// LogCollectorVerticalPodAutoscaling configures the VerticalPodAutoscalers of the log collection components.
+type LogCollectorVerticalPodAutoscaling struct {
+	// Mode controls when the recommended memory requests are applied.
+	// Default: Off
+	// +optional
+	Mode VerticalPodAutoscalingMode `json:"mode,omitempty"`
+
+	// MinAllowedMemory is the lowest memory request that is recommended.
+	// +optional
+	MinAllowedMemory *resource.Quantity `json:"minAllowedMemory,omitempty"`
+
+	// MaxAllowedMemory is the highest memory request that is recommended.
+	// +optional
+	MaxAllowedMemory *resource.Quantity `json:"maxAllowedMemory,omitempty"`
+}
+
+// FluentdNodePool is a set of nodes with its own fluentd environment.
+type FluentdNodePool struct {
+	// Name identifies the pool. It is part of the name of the fluentd DaemonSet of the pool.
+	// +kubebuilder:validation:MaxLength=40
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+
+	// NodeSelector selects the nodes of the pool by their labels.
+	// +kubebuilder:validation:MinProperties=1
+	NodeSelector map[string]string `json:"nodeSelector"`
+
+	// Env overrides environment variables of fluentd on the nodes of the pool.
+	// +optional
+	Env []FluentdEnvVar `json:"env,omitempty"`
+
+	// ContainerRuntime overrides the container runtime of spec.containerLogs on the nodes of the pool, e.g. when they
+	// run a different runtime than the other nodes. It has no effect unless spec.containerLogs is set.
+	// +optional
+	ContainerRuntime *ContainerRuntime `json:"containerRuntime,omitempty"`
+}
+
+// FluentdCandidate is a candidate fluentd that runs next to fluentd on the selected nodes.
+type FluentdCandidate struct {
+	// NodeSelector selects the nodes that the candidate runs on, next to fluentd.
+	// +kubebuilder:validation:MinProperties=1
+	NodeSelector map[string]string `json:"nodeSelector"`
+
+	// Image is the fluentd image of the candidate. If omitted, the candidate runs the image of fluentd.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Env overrides environment variables of the candidate.
+	// +optional
+	Env []FluentdEnvVar `json:"env,omitempty"`
+
+	// OutputSuffix is appended to the index suffix of the candidate, so that the logs it sends can be told apart from
+	// those of fluentd.
+	// Default: candidate
+	// +optional
+	// +kubebuilder:validation:MaxLength=20
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	OutputSuffix string `json:"outputSuffix,omitempty"`
+
+	// Promote promotes the candidate: fluentd runs with the image and the environment of the candidate on all nodes,
+	// and the DaemonSet of the candidate is removed.
+	// +optional
+	Promote bool `json:"promote,omitempty"`
+}
+
+// ControlPlaneNodeScheduling defines whether fluentd runs on the control plane nodes.
+// +kubebuilder:validation:Enum=Include;Exclude
+type ControlPlaneNodeScheduling string
+
+const (
+	ControlPlaneNodeSchedulingInclude ControlPlaneNodeScheduling = "Include"
+	ControlPlaneNodeSchedulingExclude ControlPlaneNodeScheduling = "Exclude"
+)
+
+// FluentdControlPlaneNodes configures fluentd on the control plane nodes.
+type FluentdControlPlaneNodes struct {
+	// Scheduling defines whether fluentd runs on the control plane nodes. Excluding them keeps fluentd off the control
+	// plane nodes, at the cost of not collecting their logs.
+	// Default: Include
+	// +optional
+	Scheduling ControlPlaneNodeScheduling `json:"scheduling,omitempty"`
+
+	// ResourceRequirements of fluentd on the control plane nodes, which then run fluentd with a DaemonSet of their own.
+	// It is ignored when the control plane nodes are excluded. If omitted, fluentd has the same resources on the
+	// control plane nodes as on the other nodes.
+	// +optional
+	ResourceRequirements *corev1.ResourceRequirements `json:"resourceRequirements,omitempty"`
+}
+
+// LogFileRotation defines when the log files in /var/log/calico are rotated and how many of the rotated files are
+// kept. The limits apply to each log type, i.e. to the flow, DNS and L7 logs of Felix and to the logs of the CNI
+// plugin, so the disk usage of a node is bounded by the number of log types times MaxFiles + 1 times MaxFileSizeMB.
+type LogFileRotation struct {
+	// MaxFileSizeMB is the size in megabytes at which a log file is rotated.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxFileSizeMB *int32 `json:"maxFileSizeMB,omitempty"`
+
+	// MaxFiles is how many rotated files are kept for each log type, in addition to the file that is being written.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxFiles *int32 `json:"maxFiles,omitempty"`
+}
+
+// FluentdElasticsearchOutput configures the Elasticsearch output of fluentd.
+type FluentdElasticsearchOutput struct {
+	// BulkMessageSize is the maximum size of the logs that fluentd buffers before sending them to Elasticsearch with
+	// one bulk request.
+	// Default: 8Mi
+	// +optional
+	BulkMessageSize *resource.Quantity `json:"bulkMessageSize,omitempty"`
+
+	// BulkRequestThreshold is the size above which a bulk request is sliced into several requests, so that no request
+	// exceeds the http.max_content_length of Elasticsearch.
+	// Default: 20Mi
+	// +optional
+	BulkRequestThreshold *resource.Quantity `json:"bulkRequestThreshold,omitempty"`
+
+	// RequestTimeout is how long fluentd waits for Elasticsearch to respond to a request before retrying it.
+	// Default: 5s
+	// +optional
+	RequestTimeout *metav1.Duration `json:"requestTimeout,omitempty"`
+
+	// ReconnectOnError makes fluentd reset its connections to Elasticsearch after any error, instead of only after
+	// connection errors, e.g. to recover from an Elasticsearch node that was replaced.
+	// Default: false
+	// +optional
+	ReconnectOnError *bool `json:"reconnectOnError,omitempty"`
+}
+
+// FluentdEnvVar is an environment variable of fluentd that can be overridden.
+type FluentdEnvVar struct {
+	// Name of the environment variable.
+	// +kubebuilder:validation:Enum=ELASTIC_FLUSH_INTERVAL;S3_FLUSH_INTERVAL;SYSLOG_FLUSH_INTERVAL;SPLUNK_FLUSH_INTERVAL
+	Name string `json:"name"`
+
+	// Value of the environment variable.
+	Value string `json:"value"`
+}
+
+// LogRedactionLogType is the type of the logs that a redaction rule applies to.
+// +kubebuilder:validation:Enum=Flows;DNS
+type LogRedactionLogType string
+
+const (
+	LogRedactionLogTypeFlows LogRedactionLogType = "Flows"
+	LogRedactionLogTypeDNS   LogRedactionLogType = "DNS"
+)
+
+// LogRedactionAction is how a redaction rule redacts a field.
+// +kubebuilder:validation:Enum=Hash;Drop
+type LogRedactionAction string
+
+const (
+	// LogRedactionHash replaces the value of the field with its SHA-256 hash, so that logs can still be correlated.
+	LogRedactionHash LogRedactionAction = "Hash"
+	// LogRedactionDrop removes the field from the logs.
+	LogRedactionDrop LogRedactionAction = "Drop"
+)
+
+// LogRedactionRule redacts a field of the logs of a type.
+type LogRedactionRule struct {
+	// LogType is the type of the logs to redact.
+	LogType LogRedactionLogType `json:"logType"`
+
+	// Field is the name of the field of the logs to redact, e.g. source_ip for flow logs or qname for DNS logs.
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9_]+$`
+	Field string `json:"field"`
+
+	// Action is how the field is redacted.
+	Action LogRedactionAction `json:"action"`
+}
+
+// LogCollectorComponentName CRD enum
+type LogCollectorComponentName string
+
+const (
+	ComponentNameFluentd         LogCollectorComponentName = "Fluentd"
+	ComponentNameEKSLogForwarder LogCollectorComponentName = "EKSLogForwarder"
+)
+
+// The LogCollectorComponentResource struct associates a ResourceRequirements with a component by name
+type LogCollectorComponentResource struct {
+	// ComponentName is an enum which identifies the component
+	// +kubebuilder:validation:Enum=Fluentd
+	ComponentName LogCollectorComponentName `json:"componentName"`
+	// ResourceRequirements allows customization of limits and requests for compute resources such as cpu and memory.
+	ResourceRequirements *corev1.ResourceRequirements `json:"resourceRequirements"`
+}
+
+// LogCollectorComponentDisruptionPolicy associates a DisruptionPolicy with a component by name.
+type LogCollectorComponentDisruptionPolicy struct {
+	// ComponentName identifies the component.
+	// +kubebuilder:validation:Enum=Fluentd;EKSLogForwarder
+	ComponentName LogCollectorComponentName `json:"componentName"`
+
+	DisruptionPolicy `json:",inline"`
+}
+
+// ComponentDisruptionPolicy returns the DisruptionPolicy of the component, or nil if it has none.
+func (s *LogCollectorSpec) ComponentDisruptionPolicy(name LogCollectorComponentName) *DisruptionPolicy {
+	for i := range s.ComponentDisruptionPolicies {
+		if s.ComponentDisruptionPolicies[i].ComponentName == name {
+			return &s.ComponentDisruptionPolicies[i].DisruptionPolicy
+		}
+	}
+	return nil
 }
 
 type CollectProcessPathOption string
@@ -45,6 +420,18 @@ const (
 	CollectProcessPathDisable CollectProcessPathOption = "Disabled"
 )
 
+// LogCollectorMode is which logs fluentd forwards.
+// +kubebuilder:validation:Enum=Full;AuditOnly
+type LogCollectorMode string
+
+const (
+	// LogCollectorModeFull collects the flow, DNS, audit and other logs.
+	LogCollectorModeFull LogCollectorMode = "Full"
+
+	// LogCollectorModeAuditOnly forwards only the audit logs to the additional log stores.
+	LogCollectorModeAuditOnly LogCollectorMode = "AuditOnly"
+)
+
 type AdditionalLogStoreSpec struct {
 	// If specified, enables exporting of flow, audit, and DNS logs to Amazon S3 storage.
 	// +optional
@@ -134,6 +521,19 @@ type SyslogStoreSpec struct {
 type SplunkStoreSpec struct {
 	// Location for splunk's http event collector end point. example `https://1.2.3.4:8088`
 	Endpoint string `json:"endpoint"`
+
+	// TLS configures the verification of the certificate of an https end point.
+	// +optional
+	TLS *AdditionalStoreTLS `json:"tls,omitempty"`
+}
+
+// AdditionalStoreTLS configures how fluentd verifies the certificate of an additional log store. If CASecret is not
+// set, the certificate is verified with the trusted bundle of the cluster.
+type AdditionalStoreTLS struct {
+	// CASecret selects the key of a secret in the tigera-operator namespace that holds the CA bundle that verifies
+	// the certificate of the store, instead of the trusted bundle.
+	// +optional
+	CASecret *corev1.SecretKeySelector `json:"caSecret,omitempty"`
 }
 
 // EksConfigSpec defines configuration for fetching EKS audit logs.
@@ -153,12 +553,38 @@ type EksCloudwatchLogsSpec struct {
 	// Default: 60
 	// +optional
 	FetchInterval int32 `json:"fetchInterval,omitempty"`
+
+	// PodDisruptionBudget limits how many pods of the EKS log forwarder are disrupted at once by voluntary
+	// disruptions.
+	// +optional
+	PodDisruptionBudget *PodDisruptionBudget `json:"podDisruptionBudget,omitempty"`
 }
 
 // LogCollectorStatus defines the observed state of Tigera flow and DNS log collection
 type LogCollectorStatus struct {
 	// State provides user-readable status.
 	State string `json:"state,omitempty"`
+
+	// ManagedClusters is the status of the log collection of each managed cluster, on management clusters. The logs of
+	// a managed cluster are shipped to the management cluster through Guardian.
+	// +optional
+	ManagedClusters []ManagedClusterLogCollectorStatus `json:"managedClusters,omitempty"`
+}
+
+// ManagedClusterLogCollectorStatus is the status of the log collection of a managed cluster.
+type ManagedClusterLogCollectorStatus struct {
+	// Name is the name of the ManagedCluster.
+	Name string `json:"name"`
+
+	// Connected is whether the managed cluster is connected to the management cluster through Guardian.
+	Connected bool `json:"connected"`
+
+	// LastFlowLogTime is the end time of the most recent flow log of the managed cluster indexed in the last day.
+	// +optional
+	LastFlowLogTime *metav1.Time `json:"lastFlowLogTime,omitempty"`
+
+	// Healthy is whether the managed cluster is connected and its flow logs were indexed in the last 15 minutes.
+	Healthy bool `json:"healthy"`
 }
 
 // +kubebuilder:object:root=true
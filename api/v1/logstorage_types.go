@@ -18,7 +18,24 @@ package v1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// DefaultElasticsearchReplicas is the default number of replicas of the shards of the log indices.
+	DefaultElasticsearchReplicas = 0
+	// DefaultElasticsearchClusterName is the default name of the cluster in the names of the log indices.
+	DefaultElasticsearchClusterName = "cluster"
+	// DefaultElasticsearchStorageClass is the default StorageClass of the volumes of Elasticsearch.
+	DefaultElasticsearchStorageClass = "tigera-elasticsearch"
+	// DefaultLogStoragePriorityClassName is the default PriorityClass of the pods of Elasticsearch, Kibana and the
+	// curator.
+	DefaultLogStoragePriorityClassName = "tigera-log-storage-critical"
+	// DefaultECKOperatorMemory is the default memory request and limit of the ECK operator.
+	DefaultECKOperatorMemory = "512Mi"
 )
 
 // LogStorageSpec defines the desired state of Tigera flow and DNS log storage.
@@ -30,6 +47,22 @@ type LogStorageSpec struct {
 	// +optional
 	Indices *Indices `json:"indices,omitempty"`
 
+	// IndexTemplates overrides the index templates that the log indices are created with. The overrides are applied by
+	// a job before fluentd is first deployed, and again when they change or Elasticsearch is recreated.
+	// +optional
+	IndexTemplates *IndexTemplates `json:"indexTemplates,omitempty"`
+
+	// ClusterName is the name of the cluster in the names of the log indices,
+	// tigera_secure_ee_<log type>.<cluster name>.<suffix>, which is passed on to the components that write, curate and
+	// search the logs and to the Kibana dashboards. It tells the logs of the clusters of a federation of Elasticsearch
+	// clusters apart. Changing it on an existing cluster starts new indices, and leaves the indices of the previous name
+	// to expire.
+	// Default: cluster
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-_a-z0-9]*[a-z0-9])?$`
+	// +optional
+	ClusterName string `json:"clusterName,omitempty"`
+
 	// Retention defines how long data is retained in the Elasticsearch cluster before it is cleared.
 	// +optional
 	Retention *Retention `json:"retention,omitempty"`
@@ -38,6 +71,8 @@ type LogStorageSpec struct {
 	// Tigera Elasticsearch cluster. The StorageClassName should only be modified when no LogStorage is currently
 	// active. We recommend choosing a storage class dedicated to Tigera LogStorage only. Otherwise, data retention
 	// cannot be guaranteed during upgrades. See https://docs.tigera.io/maintenance/upgrading for up-to-date instructions.
+	// The NodeSets of spec.nodes.nodeSets can override it with their own StorageClass, e.g. to put the nodes that hold
+	// the most recent logs on faster storage than the others.
 	// Default: tigera-elasticsearch
 	// +optional
 	StorageClassName string `json:"storageClassName,omitempty"`
@@ -48,12 +83,311 @@ type LogStorageSpec struct {
 	// +optional
 	DataNodeSelector map[string]string `json:"dataNodeSelector,omitempty"`
 
+	// ComponentDisruptionPolicies define how the pods of each component are stopped and how many of them are disrupted
+	// at once. ECKOperator, Elasticsearch, Kibana, ESGateway, EsCurator, ESMetrics, ESKubeControllers and Jobs are
+	// supported, where Jobs applies to the jobs that the operator runs against Elasticsearch and Kibana. The ECK
+	// operator runs a single pod, so the PodDisruptionBudget of its policy is ignored. EsCurator, ESMetrics,
+	// ESKubeControllers and Jobs can't have a PodDisruptionBudget, and the images of ECKOperator, ESGateway, ESMetrics
+	// and ESKubeControllers have no shell for a preStop delay. The PodDisruptionBudgets of spec.nodes and spec.kibana
+	// take precedence over those of the policies.
+	// +optional
+	// +listType=map
+	// +listMapKey=componentName
+	ComponentDisruptionPolicies []LogStorageComponentDisruptionPolicy `json:"componentDisruptionPolicies,omitempty"`
+
 	// ComponentResources can be used to customize the resource requirements for each component.
-	// Only ECKOperator is supported for this spec.
+	// ECKOperator, Kibana and EsCurator are supported for this spec. Components that are not customized use the
+	// defaults, or the presets for the provider of the cluster if ProviderResourcePresets is enabled.
 	// +optional
 	ComponentResources []LogStorageComponentResource `json:"componentResources,omitempty"`
+
+	// ProviderResourcePresets is whether the components that are not customized use the resource presets for the
+	// provider of the cluster, e.g. larger Elasticsearch nodes on EKS, instead of the default resources. Enabling the
+	// presets restarts the components whose resources change.
+	// Default: Disabled
+	// +optional
+	// +kubebuilder:validation:Enum=Enabled;Disabled
+	ProviderResourcePresets *ProviderResourcePresetsOption `json:"providerResourcePresets,omitempty"`
+
+	// DefaultPriorityClass is whether the operator creates the tigera-log-storage-critical PriorityClass and sets it on
+	// the pods of the components that ComponentPriorityClasses doesn't customize, so that the log storage isn't among
+	// the first pods to be evicted under node pressure. Enabling or disabling it restarts those pods.
+	// Default: Disabled
+	// +optional
+	// +kubebuilder:validation:Enum=Enabled;Disabled
+	DefaultPriorityClass *DefaultPriorityClassOption `json:"defaultPriorityClass,omitempty"`
+
+	// ComponentPriorityClasses set the PriorityClass of the pods of each component. Elasticsearch, Kibana and
+	// EsCurator are supported. Components that are not customized use the tigera-log-storage-critical PriorityClass
+	// if DefaultPriorityClass is enabled, and no PriorityClass otherwise. The PriorityClass of the ECK operator is set
+	// by spec.eckOperator.
+	// +optional
+	// +listType=map
+	// +listMapKey=componentName
+	ComponentPriorityClasses []LogStorageComponentPriorityClass `json:"componentPriorityClasses,omitempty"`
+
+	// AdminUserRotation configures the rotation of the credentials of the Elasticsearch admin (elastic) user. If
+	// omitted, the credentials are never rotated.
+	// +optional
+	AdminUserRotation *ESUserRotation `json:"adminUserRotation,omitempty"`
+
+	// ComponentUserRotation configures the rotation of the credentials of the Elasticsearch users of the operator,
+	// fluentd, curator and the manager. If omitted, the credentials are never rotated.
+	// +optional
+	ComponentUserRotation *ESUserRotation `json:"componentUserRotation,omitempty"`
+
+	// RemoteClusters are Elasticsearch clusters that are searched from the Elasticsearch cluster and Kibana of this
+	// cluster using cross-cluster search, e.g. the Elasticsearch clusters of managed clusters.
+	// +optional
+	RemoteClusters []RemoteElasticsearchCluster `json:"remoteClusters,omitempty"`
+
+	// ESGateway configures the limits, timeouts, replicas and resources of the gateway that proxies requests to
+	// Elasticsearch and Kibana.
+	// +optional
+	ESGateway *ESGatewaySpec `json:"esGateway,omitempty"`
+
+	// UpgradePreflight configures the checks that are run before Elasticsearch or the ECK operator is upgraded to a
+	// new version. The checks always run before an upgrade, the upgrade is blocked until they pass.
+	// +optional
+	UpgradePreflight *UpgradePreflight `json:"upgradePreflight,omitempty"`
+
+	// DataLossAcknowledged acknowledges a change of the LogStorage that deletes the data of Elasticsearch, e.g. a change
+	// of the StorageClass or of the storage of a NodeSet, which replaces the volumes of its Elasticsearch nodes, or the
+	// connection of the cluster to a management cluster, which deletes Elasticsearch. Such a change is only applied
+	// when DataLossAcknowledged is the generation of the LogStorage that has the change, i.e. its metadata.generation
+	// plus one when it is set in the same update as the change. Otherwise, the LogStorage is degraded with the changes
+	// that would delete data, and Elasticsearch and Kibana are not updated.
+	// +optional
+	DataLossAcknowledged *int64 `json:"dataLossAcknowledged,omitempty"`
+
+	// TenantRoles are Elasticsearch roles that grant read-only access to a subset of the documents and fields of the
+	// log indices, e.g. to the flow logs of the namespaces of an application team. The roles are created in
+	// Elasticsearch as tigera_tenant_<name>, and can be mapped to users and groups like the built-in roles.
+	// +optional
+	TenantRoles []TenantRole `json:"tenantRoles,omitempty"`
+
+	// KibanaSpaces are Kibana spaces for the teams that share Kibana. The default dashboards and index patterns of
+	// Kibana are copied into each space, and the members of the OIDC groups of a space are granted read-only access to
+	// it. Spaces that are removed are kept in Kibana, but the members of their groups lose access to them.
+	// +optional
+	KibanaSpaces []KibanaSpace `json:"kibanaSpaces,omitempty"`
+
+	// Tenancy isolates the logs of the teams that share the Elasticsearch cluster of a management cluster. Each tenant
+	// gets an Elasticsearch role that can only read the log indices of its managed clusters, an Elasticsearch user with
+	// the role, and a Kibana space that its OIDC groups can access.
+	// +optional
+	Tenancy *LogStorageTenancy `json:"tenancy,omitempty"`
+
+	// IngestionLatency enables the measurement of the time it takes for flow and DNS logs to be indexed after they
+	// are written on the nodes. The latency is exported by the operator as a Prometheus histogram.
+	// +optional
+	IngestionLatency *IngestionLatency `json:"ingestionLatency,omitempty"`
+
+	// StorageEstimation enables the periodic estimation of the storage that the logs consume, which is published in
+	// the status of the LogStorage.
+	// +optional
+	StorageEstimation *StorageEstimation `json:"storageEstimation,omitempty"`
+
+	// Ports are the ports that the Elasticsearch and Kibana pods listen on. The services of Elasticsearch and Kibana
+	// keep their default ports, so the clients of the services are unaffected.
+	// +optional
+	Ports *LogStoragePorts `json:"ports,omitempty"`
+
+	// CuratorBackpressure configures when the curator CronJob is suspended while Elasticsearch recovers, so that the
+	// deletion of indices doesn't add to the load of the recovery. The curator is resumed once Elasticsearch has
+	// recovered.
+	// +optional
+	CuratorBackpressure *CuratorBackpressure `json:"curatorBackpressure,omitempty"`
+
+	// Curator enables the legacy elastic-curator CronJob, which deletes the indices that are older than their retention
+	// period every hour. When the curator is disabled, the indices are rolled over and deleted by the Elasticsearch index
+	// lifecycle management policies that are derived from the retention periods.
+	// Default: Disabled
+	// +kubebuilder:validation:Enum=Enabled;Disabled
+	// +optional
+	Curator *CuratorOption `json:"curator,omitempty"`
+
+	// Snapshots configures periodic snapshots of the log indices to an object store, so that the logs can be restored
+	// if the Elasticsearch cluster is lost. The snapshots are taken by an Elasticsearch snapshot lifecycle management
+	// policy.
+	// +optional
+	Snapshots *Snapshots `json:"snapshots,omitempty"`
+
+	// SecureSettings add the keys of secrets to the keystore of Elasticsearch, e.g. the credentials of an S3 snapshot
+	// repository or the keys of a SAML realm. The secrets must be in the tigera-operator namespace.
+	// +optional
+	SecureSettings []SecureSettingsSource `json:"secureSettings,omitempty"`
+
+	// Kibana overrides the replicas, resources and scheduling of Kibana, which otherwise follow the control plane
+	// settings of the Installation.
+	// +optional
+	Kibana *KibanaSpec `json:"kibana,omitempty"`
+
+	// ECKOperator overrides the resources and scheduling of the ECK operator, which otherwise follow the control plane
+	// settings of the Installation.
+	// +optional
+	ECKOperator *ECKOperatorSpec `json:"eckOperator,omitempty"`
+
+	// DNSConfig adds DNS resolution options to the curator pods, such as nameservers, search domains and ndots, e.g. so
+	// that the Elasticsearch names resolve on clusters with node-local DNS caching and custom cluster domains. It is
+	// merged with the DNS configuration that the cluster generates for the pods.
+	// +optional
+	DNSConfig *corev1.PodDNSConfig `json:"dnsConfig,omitempty"`
+
+	// ImagePullSecrets are container registry pull secrets of Elasticsearch, Kibana, the ECK operator and the curator,
+	// e.g. to pull their images from another registry than the other images. The secrets must be in the namespace of
+	// the operator. They are used along with the ImagePullSecrets of the Installation and copied to the namespaces of
+	// the components, so their names must differ from those of the Installation.
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// RolloutWindow defers the restarts of Elasticsearch and Kibana for changed certificates or credentials until the
+	// window opens, to avoid restarting the Elasticsearch nodes during business hours. If omitted, they are restarted
+	// as soon as these change.
+	// +optional
+	RolloutWindow *RolloutWindow `json:"rolloutWindow,omitempty"`
+
+	// Monitoring configures the exporter that the Tigera Prometheus scrapes the metrics of Elasticsearch from, and the
+	// alerts on those metrics.
+	// +optional
+	Monitoring *LogStorageMonitoring `json:"monitoring,omitempty"`
+
+	// TransportTLS configures the TLS of the transport layer that the Elasticsearch nodes communicate with each other
+	// and with remote clusters over, e.g. to restrict it to the protocols and ciphers that a crypto policy allows.
+	// +optional
+	TransportTLS *ElasticsearchTransportTLS `json:"transportTLS,omitempty"`
+
+	// Verification enables a job that verifies that Elasticsearch and Kibana are functional after they are rolled out,
+	// by writing and searching a test document through the Elasticsearch gateway and opening the login page of Kibana.
+	// The result is reported by the Verified condition of the status, and the LogStorage isn't available until the
+	// verification succeeds.
+	// +optional
+	Verification *LogStorageVerification `json:"verification,omitempty"`
+
+	// ArchiveRestores restore the flow logs that the LogCollector archived to S3 into temporary indices, e.g. to
+	// investigate an incident past the retention of the flow logs. Each restore is run once by a job, and its index is
+	// deleted once it expires or the restore is removed.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	ArchiveRestores []ArchiveRestore `json:"archiveRestores,omitempty"`
+
+	// EnterpriseLicense references an Elastic enterprise license, which the operator copies to the namespace of the ECK
+	// operator and keeps in sync with its secret. The license replaces the trial license that is applied in FIPS mode.
+	// Its expiry is reported by the LicenseValid condition of the status.
+	// +optional
+	EnterpriseLicense *ElasticEnterpriseLicense `json:"enterpriseLicense,omitempty"`
+
+	// ReadOnlyAccess grants short-lived, read-only access to the logs in Elasticsearch and Kibana, e.g. to auditors and
+	// incident responders, instead of sharing the credentials of the elastic superuser. Each grant gets an Elasticsearch
+	// user that can read the log indices and use Kibana in read-only mode, whose credentials are stored in the secret
+	// tigera-read-only-<name>-elasticsearch-user in the tigera-operator namespace. The user and the secret are deleted
+	// once the grant expires or is removed. An expired grant has to be removed, or renamed, to be granted again.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	ReadOnlyAccess []ReadOnlyAccessGrant `json:"readOnlyAccess,omitempty"`
 }
 
+// ReadOnlyAccessGrant grants read-only access to the logs to the Elasticsearch user tigera-read-only-<name>.
+type ReadOnlyAccessGrant struct {
+	// Name identifies the grant and its Elasticsearch user.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=40
+	Name string `json:"name"`
+
+	// TTL is how long the credentials of the grant are valid after they are created.
+	// Default: 8h
+	// +optional
+	TTL *metav1.Duration `json:"ttl,omitempty"`
+}
+
+// ElasticEnterpriseLicense references a secret that holds an Elastic enterprise license.
+type ElasticEnterpriseLicense struct {
+	// SecretName is the name of the secret in the tigera-operator namespace. Its license key holds the license file as
+	// it is issued by Elastic.
+	SecretName string `json:"secretName"`
+}
+
+// ArchiveRestore restores the archived flow logs of a period into the index tigera_secure_ee_restored_flows.<name>.
+type ArchiveRestore struct {
+	// Name identifies the restore and its index.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=40
+	Name string `json:"name"`
+
+	// Start is the beginning of the period of the restored flow logs. The archive is restored by the hour, so the
+	// flow logs of the whole hour of Start are restored.
+	Start metav1.Time `json:"start"`
+
+	// End is the end of the period of the restored flow logs.
+	End metav1.Time `json:"end"`
+
+	// TTL is how long the index of the restore is kept after the restore completes.
+	// Default: 24h
+	// +optional
+	TTL *metav1.Duration `json:"ttl,omitempty"`
+}
+
+// LogStorageVerification configures the job that verifies that Elasticsearch and Kibana are functional.
+type LogStorageVerification struct {
+	// Timeout is how long the verification job may run before it is failed.
+	// Default: 5m
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// ElasticsearchTransportTLS configures the TLS of the transport layer of Elasticsearch.
+type ElasticsearchTransportTLS struct {
+	// VerificationMode is how the nodes verify the certificates of their peers. Certificate verifies that a
+	// certificate is signed by a trusted CA, while Full also verifies that the names of the certificate match the peer.
+	// Default: Certificate
+	// +kubebuilder:validation:Enum=Full;Certificate
+	// +optional
+	VerificationMode *TransportTLSVerificationMode `json:"verificationMode,omitempty"`
+
+	// SupportedProtocols are the TLS protocols that the nodes accept, which must be TLSv1.3 or TLSv1.2. If omitted,
+	// the defaults of Elasticsearch are used.
+	// +optional
+	SupportedProtocols []string `json:"supportedProtocols,omitempty"`
+
+	// CipherSuites are the JSSE names of the cipher suites that the nodes accept, in order of preference, e.g.
+	// TLS_AES_256_GCM_SHA384. If omitted, the defaults of Elasticsearch are used.
+	// +optional
+	CipherSuites []string `json:"cipherSuites,omitempty"`
+}
+
+type TransportTLSVerificationMode string
+
+const (
+	TransportTLSVerificationFull        TransportTLSVerificationMode = "Full"
+	TransportTLSVerificationCertificate TransportTLSVerificationMode = "Certificate"
+)
+
+// LogStorageMonitoring configures the monitoring of Elasticsearch by the Tigera Prometheus.
+type LogStorageMonitoring struct {
+	// Exporter enables the elasticsearch_exporter deployment that exposes the cluster health, JVM and indexing rate
+	// metrics of Elasticsearch to the Tigera Prometheus.
+	// Default: Enabled
+	// +kubebuilder:validation:Enum=Enabled;Disabled
+	// +optional
+	Exporter *LogStorageMonitoringOption `json:"exporter,omitempty"`
+
+	// Alerts enables the PrometheusRule with the alerts on the health, the JVM heap usage and the indexing rate of
+	// Elasticsearch. The alerts require the exporter, and the PrometheusRule CRD of the prometheus-operator.
+	// Default: Disabled
+	// +kubebuilder:validation:Enum=Enabled;Disabled
+	// +optional
+	Alerts *LogStorageMonitoringOption `json:"alerts,omitempty"`
+}
+
+type LogStorageMonitoringOption string
+
+const (
+	LogStorageMonitoringEnabled  LogStorageMonitoringOption = "Enabled"
+	LogStorageMonitoringDisabled LogStorageMonitoringOption = "Disabled"
+)
+
 // LogStorageStatus defines the observed state of Tigera flow and DNS log storage.
 type LogStorageStatus struct {
 	// State provides user-readable status.
@@ -66,6 +400,669 @@ type LogStorageStatus struct {
 	// KibanaHash represents the current revision and configuration of the installed Kibana dashboard. This
 	// is an opaque string which can be monitored for changes to perform actions when Kibana is modified.
 	KibanaHash string `json:"kibanaHash,omitempty"`
+
+	// AdminUserRotationToken is the value of spec.adminUserRotation.rotationToken that the most recent rotation of the
+	// Elasticsearch admin user credentials was performed for.
+	AdminUserRotationToken string `json:"adminUserRotationToken,omitempty"`
+
+	// LastAdminUserRotation is the time at which the most recent rotation of the Elasticsearch admin user credentials
+	// was started.
+	LastAdminUserRotation *metav1.Time `json:"lastAdminUserRotation,omitempty"`
+
+	// RotatedAdminUserSecretUID is the UID of the admin user secret that the most recent rotation of the Elasticsearch
+	// admin user credentials replaces. The rotation is pending as long as the secret with this UID exists.
+	// +optional
+	RotatedAdminUserSecretUID types.UID `json:"rotatedAdminUserSecretUID,omitempty"`
+
+	// ComponentUserRotationToken is the value of spec.componentUserRotation.rotationToken that the most recent rotation
+	// of the credentials of the Elasticsearch users of the components was performed for.
+	ComponentUserRotationToken string `json:"componentUserRotationToken,omitempty"`
+
+	// LastComponentUserRotation is the time at which the most recent rotation of the credentials of the Elasticsearch
+	// users of the components was started.
+	LastComponentUserRotation *metav1.Time `json:"lastComponentUserRotation,omitempty"`
+
+	// RotatedComponentUserSecretUIDs are the UIDs of the user secrets of the components that the most recent rotation
+	// of the credentials of the Elasticsearch users of the components replaces. The rotation is pending as long as any
+	// secret with one of these UIDs exists.
+	// +optional
+	RotatedComponentUserSecretUIDs []types.UID `json:"rotatedComponentUserSecretUIDs,omitempty"`
+
+	// StorageEstimate is the most recent estimate of the storage consumption of the logs, when the StorageEstimation
+	// of the LogStorage is set.
+	// +optional
+	StorageEstimate *StorageEstimate `json:"storageEstimate,omitempty"`
+
+	// ArchiveRestores are the states of the restores of the archived flow logs.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	ArchiveRestores []ArchiveRestoreStatus `json:"archiveRestores,omitempty"`
+
+	// LicenseExpiry is the time at which the Elastic enterprise license of the EnterpriseLicense expires.
+	// +optional
+	LicenseExpiry *metav1.Time `json:"licenseExpiry,omitempty"`
+
+	// ReadOnlyAccess are the states of the grants of read-only access to the logs.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	ReadOnlyAccess []ReadOnlyAccessStatus `json:"readOnlyAccess,omitempty"`
+
+	// Conditions represent the most recently observed health of the Elasticsearch cluster: whether its health is
+	// green, whether all of its shards are assigned and whether the disks of its nodes are below the high disk
+	// watermark. When the verification is enabled, the Verified condition reports its result. When index templates
+	// are overridden, the IndexTemplatesApplied condition reports whether they are applied.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+const (
+	// LogStorageConditionElasticsearchHealthy is the condition type that is true when the health of the Elasticsearch
+	// cluster is green. The reason is the health of the cluster.
+	LogStorageConditionElasticsearchHealthy = "ElasticsearchHealthy"
+	// LogStorageConditionShardsAssigned is the condition type that is true when all the shards of the Elasticsearch
+	// cluster are assigned to a node.
+	LogStorageConditionShardsAssigned = "ShardsAssigned"
+	// LogStorageConditionDiskAvailable is the condition type that is true when the disk usage of all the Elasticsearch
+	// nodes is below the high disk watermark. The reason is the highest disk watermark that is exceeded.
+	LogStorageConditionDiskAvailable = "DiskAvailable"
+	// LogStorageConditionVerified is the condition type that is true when the verification job succeeded for the
+	// current Elasticsearch and Kibana clusters.
+	LogStorageConditionVerified = "Verified"
+	// LogStorageConditionLicenseValid is the condition type that is true when the Elastic enterprise license of the
+	// EnterpriseLicense is valid. The reason is ExpiringSoon when the license expires within 30 days.
+	LogStorageConditionLicenseValid = "LicenseValid"
+	// LogStorageConditionIndexTemplatesApplied is the condition type that is true when the overrides of the index
+	// templates of the IndexTemplates are applied to the current Elasticsearch cluster.
+	LogStorageConditionIndexTemplatesApplied = "IndexTemplatesApplied"
+)
+
+// LogStoragePorts defines the ports that the Elasticsearch and Kibana pods listen on, for environments that reserve the
+// default ports on the network of the pods. The transport port of Elasticsearch cannot be changed, as ECK discovers the
+// Elasticsearch nodes on the default transport port.
+type LogStoragePorts struct {
+	// ElasticsearchHTTP is the port of the HTTP interface of Elasticsearch.
+	// Default: 9200
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	ElasticsearchHTTP *int32 `json:"elasticsearchHTTP,omitempty"`
+
+	// Kibana is the port of the HTTP interface of Kibana.
+	// Default: 5601
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Kibana *int32 `json:"kibana,omitempty"`
+}
+
+// IngestionLatency defines the objective for the time it takes for flow and DNS logs to be indexed.
+type IngestionLatency struct {
+	// SLO is the maximum 99th percentile of the ingestion latency of the flow and DNS logs indexed in the last five
+	// minutes. LogStorage is degraded while the latency exceeds it. If omitted, the latency is only measured.
+	// +optional
+	SLO *metav1.Duration `json:"slo,omitempty"`
+}
+
+// StorageEstimation defines how often the storage consumption of the logs is estimated, and the price of the storage.
+type StorageEstimation struct {
+	// Interval is how often the storage consumption is estimated.
+	// Default: 1h
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+
+	// PricePerGiBMonth is the price of a GiB of storage for a month, e.g. 0.10, that the monthly cost of the retained
+	// logs is estimated with. If omitted, the cost isn't estimated.
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	// +optional
+	PricePerGiBMonth string `json:"pricePerGiBMonth,omitempty"`
+}
+
+// StorageEstimate is an estimate of the storage consumption of the logs. It is based on the flow, DNS, L7 and audit
+// logs written in the last day, which take up most of the storage.
+type StorageEstimate struct {
+	// Time is when the estimate was made.
+	Time metav1.Time `json:"time"`
+
+	// Capacity is the disk capacity of the Elasticsearch nodes.
+	Capacity resource.Quantity `json:"capacity"`
+
+	// Used is the disk usage of the Elasticsearch nodes.
+	Used resource.Quantity `json:"used"`
+
+	// IngestedPerDay is the storage, including replicas, that the logs written in a day consume.
+	IngestedPerDay resource.Quantity `json:"ingestedPerDay"`
+
+	// Retained is the projected storage of the logs once they are kept for their full retention periods.
+	Retained resource.Quantity `json:"retained"`
+
+	// DaysUntilFull is the projected number of days until the disk usage of the Elasticsearch nodes reaches the flood
+	// stage watermark, at which the indices stop accepting logs, if the disk usage grew by the daily ingest. It is
+	// omitted if no logs were written in the last day.
+	// +optional
+	DaysUntilFull *int32 `json:"daysUntilFull,omitempty"`
+
+	// MonthlyCost is the estimated monthly cost of the retained storage, at the PricePerGiBMonth of the
+	// StorageEstimation.
+	// +optional
+	MonthlyCost string `json:"monthlyCost,omitempty"`
+}
+
+// ArchiveRestoreStatus is the state of a restore of the archived flow logs.
+type ArchiveRestoreStatus struct {
+	// Name is the name of the restore.
+	Name string `json:"name"`
+
+	// Index is the index that the flow logs are restored into.
+	Index string `json:"index"`
+
+	// Phase is the phase of the restore: Running, Succeeded, Failed or Expired. The index of an expired restore is
+	// deleted.
+	Phase ArchiveRestorePhase `json:"phase"`
+
+	// CompletionTime is when the restore succeeded.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// ExpirationTime is when the index of the restore is deleted.
+	// +optional
+	ExpirationTime *metav1.Time `json:"expirationTime,omitempty"`
+}
+
+// ReadOnlyAccessStatus is the state of a grant of read-only access to the logs.
+type ReadOnlyAccessStatus struct {
+	// Name is the name of the grant.
+	Name string `json:"name"`
+
+	// Phase is the phase of the grant: Active or Expired. The Elasticsearch user and the secret of an expired grant are
+	// deleted.
+	Phase ReadOnlyAccessPhase `json:"phase"`
+
+	// SecretName is the name of the secret in the tigera-operator namespace with the credentials of the grant.
+	SecretName string `json:"secretName"`
+
+	// CreationTime is when the credentials of the grant were created.
+	// +optional
+	CreationTime *metav1.Time `json:"creationTime,omitempty"`
+
+	// ExpirationTime is when the credentials of the grant expire.
+	// +optional
+	ExpirationTime *metav1.Time `json:"expirationTime,omitempty"`
+}
+
+type ReadOnlyAccessPhase string
+
+const (
+	ReadOnlyAccessActive  ReadOnlyAccessPhase = "Active"
+	ReadOnlyAccessExpired ReadOnlyAccessPhase = "Expired"
+)
+
+type ArchiveRestorePhase string
+
+const (
+	ArchiveRestoreRunning   ArchiveRestorePhase = "Running"
+	ArchiveRestoreSucceeded ArchiveRestorePhase = "Succeeded"
+	ArchiveRestoreFailed    ArchiveRestorePhase = "Failed"
+	ArchiveRestoreExpired   ArchiveRestorePhase = "Expired"
+)
+
+type CuratorOption string
+
+const (
+	CuratorEnabled  CuratorOption = "Enabled"
+	CuratorDisabled CuratorOption = "Disabled"
+)
+
+// CuratorBackpressure defines when Elasticsearch is considered to be recovering.
+type CuratorBackpressure struct {
+	// RelocatingShardsThreshold is the number of relocating shards above which Elasticsearch is recovering, while its
+	// health is yellow or red.
+	// Default: 0
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	RelocatingShardsThreshold *int32 `json:"relocatingShardsThreshold,omitempty"`
+}
+
+// Snapshots defines where and when the snapshots of the log indices are taken, and how long they are kept.
+type Snapshots struct {
+	// Repository is the object store that the snapshots are stored in.
+	Repository SnapshotRepository `json:"repository"`
+
+	// Schedule is the schedule on which the snapshots are taken, in the cron syntax of Elasticsearch.
+	// Default: 0 30 1 * * ?
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+
+	// Retention defines when the snapshots are deleted. If omitted, the snapshots are kept.
+	// +optional
+	Retention *SnapshotRetention `json:"retention,omitempty"`
+}
+
+type SnapshotRepositoryType string
+
+const (
+	SnapshotRepositoryS3    SnapshotRepositoryType = "S3"
+	SnapshotRepositoryGCS   SnapshotRepositoryType = "GCS"
+	SnapshotRepositoryAzure SnapshotRepositoryType = "Azure"
+)
+
+// SnapshotRepository defines the object store that the snapshots are stored in.
+type SnapshotRepository struct {
+	// Type is the type of the object store.
+	// +kubebuilder:validation:Enum=S3;GCS;Azure
+	Type SnapshotRepositoryType `json:"type"`
+
+	// Bucket is the name of the bucket, or of the container for Azure, that the snapshots are stored in.
+	Bucket string `json:"bucket"`
+
+	// BasePath is the path within the bucket that the snapshots are stored under.
+	// +optional
+	BasePath string `json:"basePath,omitempty"`
+
+	// SecretName is the name of the secret in the tigera-operator namespace that holds the credentials of the object
+	// store: the access_key and secret_key keys for S3, the credentials_file key for GCS, or the account and key keys
+	// for Azure. The credentials are added to the keystore of Elasticsearch.
+	SecretName string `json:"secretName"`
+}
+
+// SecureSettingsSource references a secret whose keys are added to the keystore of Elasticsearch.
+type SecureSettingsSource struct {
+	// SecretName is the name of the secret in the tigera-operator namespace.
+	SecretName string `json:"secretName"`
+
+	// Entries map the keys of the secret to the settings of the keystore. If omitted, all the keys of the secret are
+	// added as settings of the same name.
+	// +optional
+	Entries []SecureSettingsEntry `json:"entries,omitempty"`
+}
+
+// SecureSettingsEntry maps a key of a secret to a setting of the keystore of Elasticsearch.
+type SecureSettingsEntry struct {
+	// Key is the key of the secret.
+	Key string `json:"key"`
+
+	// Setting is the name of the setting in the keystore, e.g. s3.client.default.access_key.
+	// Default: the key of the secret
+	// +optional
+	Setting string `json:"setting,omitempty"`
+}
+
+// SnapshotRetention defines when the snapshots are deleted.
+type SnapshotRetention struct {
+	// ExpireAfter is the age after which snapshots are deleted.
+	// +optional
+	ExpireAfter *metav1.Duration `json:"expireAfter,omitempty"`
+
+	// MinCount is the minimum number of snapshots that are kept, even if they are older than ExpireAfter.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MinCount *int32 `json:"minCount,omitempty"`
+
+	// MaxCount is the maximum number of snapshots that are kept, even if they are younger than ExpireAfter.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxCount *int32 `json:"maxCount,omitempty"`
+}
+
+// ESUserRotation defines when the credentials of Elasticsearch users are regenerated, either of the admin user or
+// of the users of the components. When the credentials are rotated, the components that use them are restarted so
+// that they pick up the new credentials.
+type ESUserRotation struct {
+	// Interval is the maximum age of the Elasticsearch user credentials. Credentials older than this are
+	// regenerated. If omitted, the credentials are only rotated on demand.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+
+	// RotationToken is an opaque value that triggers an immediate rotation of the Elasticsearch user credentials
+	// whenever it is changed.
+	// +optional
+	RotationToken string `json:"rotationToken,omitempty"`
+}
+
+// RemoteElasticsearchCluster defines an Elasticsearch cluster that is searched using cross-cluster search. Searches on
+// the remote cluster are performed on behalf of the local user, so the remote cluster must grant the roles of the local
+// users on its indices.
+type RemoteElasticsearchCluster struct {
+	// Alias is the name that identifies the remote cluster in searches, e.g. <alias>:tigera_secure_ee_flows*.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-_a-z0-9]*[a-z0-9])?$`
+	Alias string `json:"alias"`
+
+	// Seeds are the transport addresses (host:port) of the nodes of the remote cluster that are used to discover it.
+	// Exactly one of Seeds and ProxyAddress must be set.
+	// +kubebuilder:validation:MinItems=1
+	// +optional
+	Seeds []string `json:"seeds,omitempty"`
+
+	// ProxyAddress is the transport address (host:port) of a single endpoint of the remote cluster, e.g. the load
+	// balancer in front of the Elasticsearch cluster of another regional management cluster, through which all the
+	// connections to the remote cluster are made instead of discovering its nodes. Exactly one of Seeds and
+	// ProxyAddress must be set.
+	// +optional
+	ProxyAddress string `json:"proxyAddress,omitempty"`
+
+	// ServerName is the server name that is sent in the TLS handshake with the proxy address, for proxies that route
+	// connections by their server name. It can only be set with ProxyAddress.
+	// +optional
+	ServerName string `json:"serverName,omitempty"`
+
+	// CertificateAuthoritySecretName is the name of a secret in the tigera-operator namespace that holds the certificate
+	// (under the tls.crt key) of the CA that signed the transport certificates of the remote cluster. The remote cluster
+	// must in turn trust the transport certificates of this cluster. If omitted, the transport certificates of the
+	// remote cluster must be signed by a CA that this cluster already trusts.
+	// +optional
+	CertificateAuthoritySecretName string `json:"certificateAuthoritySecretName,omitempty"`
+}
+
+// ESGatewaySpec defines the limits and timeouts of the Elasticsearch gateway. Fields that are omitted use the defaults
+// of the gateway.
+type ESGatewaySpec struct {
+	// MaxConnections is the maximum number of concurrent connections that the gateway accepts.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxConnections *int32 `json:"maxConnections,omitempty"`
+
+	// RequestTimeout is the maximum duration of a request that is proxied by the gateway. Increase it if long running
+	// queries, such as those of large compliance reports, fail with a 504 status.
+	// +optional
+	RequestTimeout *metav1.Duration `json:"requestTimeout,omitempty"`
+
+	// IdleTimeout is how long an idle client connection is kept open by the gateway.
+	// +optional
+	IdleTimeout *metav1.Duration `json:"idleTimeout,omitempty"`
+
+	// MaxBodySize is the maximum size of the body of a request that is proxied by the gateway.
+	// +optional
+	MaxBodySize *resource.Quantity `json:"maxBodySize,omitempty"`
+
+	// Replicas is the number of gateway pods. It is ignored when Autoscaling is set.
+	// Default: the ControlPlaneReplicas of the Installation
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Resources are the resource requirements of the gateway container.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Autoscaling scales the gateway with a HorizontalPodAutoscaler on the CPU utilization of its pods, so that the
+	// gateway keeps up with the ingest of large clusters. The utilization is relative to the CPU requests of Resources,
+	// which should be set.
+	// +optional
+	Autoscaling *ESGatewayAutoscaling `json:"autoscaling,omitempty"`
+}
+
+// ESGatewayAutoscaling configures the HorizontalPodAutoscaler of the Elasticsearch gateway.
+type ESGatewayAutoscaling struct {
+	// MinReplicas is the minimum number of gateway pods.
+	// Default: 1
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas is the maximum number of gateway pods.
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// TargetCPUUtilizationPercentage is the average CPU utilization of the gateway pods, as a percentage of their CPU
+	// requests, that the autoscaler maintains.
+	// Default: 80
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TargetCPUUtilizationPercentage *int32 `json:"targetCPUUtilizationPercentage,omitempty"`
+}
+
+// KibanaSpec overrides the replicas, resources and scheduling of Kibana. Fields that are omitted use the control plane
+// settings of the Installation, or the default resources of Kibana.
+type KibanaSpec struct {
+	// Replicas is the number of Kibana pods.
+	// Default: the ControlPlaneReplicas of the Installation
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Resources are the resource requirements of the Kibana container. They take precedence over the Kibana entry of
+	// ComponentResources.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// NodeSelector is the node selector of the Kibana pods.
+	// Default: the ControlPlaneNodeSelector of the Installation
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations are the tolerations of the Kibana pods.
+	// Default: the ControlPlaneTolerations of the Installation
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// ExtraConfig is Kibana configuration that is added to the kibana.yml of Kibana, e.g. to configure telemetry,
+	// map tiles or the security session settings. Settings that the operator manages, like the server and the
+	// connection to Elasticsearch, can't be overridden.
+	// +optional
+	ExtraConfig map[string]string `json:"extraConfig,omitempty"`
+
+	// ElasticsearchHosts are the URLs of the Elasticsearch endpoints that Kibana connects to, e.g. a coordinating-only
+	// service or the Elasticsearch gateway, instead of the Elasticsearch cluster that ECK associates it with. Kibana
+	// authenticates with an Elasticsearch user that the operator manages, and trusts the certificates signed by the
+	// operator CA.
+	// +optional
+	ElasticsearchHosts []string `json:"elasticsearchHosts,omitempty"`
+
+	// PodMetadata are labels and annotations that are added to the Kibana pods, e.g. the annotations of a service
+	// mesh. Labels and annotations that the operator sets take precedence.
+	// +optional
+	PodMetadata *Metadata `json:"podMetadata,omitempty"`
+
+	// PodDisruptionBudget limits how many Kibana pods are disrupted at once by voluntary disruptions.
+	// +optional
+	PodDisruptionBudget *PodDisruptionBudget `json:"podDisruptionBudget,omitempty"`
+
+	// SavedObjects are Kibana saved objects, e.g. dashboards and index patterns, that are imported into Kibana once it
+	// is running, so that custom dashboards survive reinstalls of Kibana. They are imported again, overwriting the
+	// objects with the same IDs, when their ConfigMaps change or when Elasticsearch or Kibana is recreated.
+	// +optional
+	SavedObjects []KibanaSavedObjectsSource `json:"savedObjects,omitempty"`
+}
+
+// KibanaSavedObjectsSource is a ConfigMap in the tigera-operator namespace with Kibana saved objects. Each key of the
+// ConfigMap holds saved objects in the NDJSON format of the export API of Kibana.
+type KibanaSavedObjectsSource struct {
+	// ConfigMapName is the name of the ConfigMap.
+	ConfigMapName string `json:"configMapName"`
+
+	// SpaceID is the ID of the Kibana space that the saved objects are imported into, e.g. the ID of one of the
+	// KibanaSpaces of the LogStorage.
+	// Default: the default space
+	// +kubebuilder:validation:Pattern=`^[a-z0-9_-]+$`
+	// +optional
+	SpaceID string `json:"spaceID,omitempty"`
+}
+
+// ECKOperatorSpec overrides the resources and scheduling of the ECK operator. Fields that are omitted use the control
+// plane settings of the Installation, or the default resources of the ECK operator.
+type ECKOperatorSpec struct {
+	// Resources are the resource requirements of the ECK operator container. They take precedence over the ECKOperator
+	// entry of ComponentResources.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// NodeSelector is the node selector of the ECK operator pod.
+	// Default: the ControlPlaneNodeSelector of the Installation
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations are the tolerations of the ECK operator pod.
+	// Default: the ControlPlaneTolerations of the Installation
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// PriorityClassName is the name of the PriorityClass of the ECK operator pod.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// ValidatingWebhook makes the ECK operator validate the changes of the Elasticsearch and Kibana resources with a
+	// validating webhook, so that invalid changes are rejected when they are made instead of failing when ECK
+	// reconciles them. The certificate of the webhook is issued by the operator. Changes are not validated while the
+	// webhook is unavailable.
+	// Default: Disabled
+	// +kubebuilder:validation:Enum=Enabled;Disabled
+	// +optional
+	ValidatingWebhook *ECKValidatingWebhook `json:"validatingWebhook,omitempty"`
+}
+
+// ECKValidatingWebhook enables or disables the validating webhook of the ECK operator.
+type ECKValidatingWebhook string
+
+const (
+	ECKValidatingWebhookEnabled  ECKValidatingWebhook = "Enabled"
+	ECKValidatingWebhookDisabled ECKValidatingWebhook = "Disabled"
+)
+
+// UpgradePreflight defines the checks that are run before Elasticsearch or the ECK operator is upgraded: the deprecation
+// API of Elasticsearch must not report critical issues, the disk usage of the nodes must leave room for the upgrade
+// and, if a snapshot repository is given, it must hold a recent enough successful snapshot. An upgrade of the ECK
+// operator is rolled out while Elasticsearch and Kibana are unmanaged by ECK, and they are only managed and upgraded
+// again once the health of the cluster is green.
+type UpgradePreflight struct {
+	// Force upgrades Elasticsearch even if the checks fail.
+	// +optional
+	Force bool `json:"force,omitempty"`
+
+	// MaxDiskUsagePercent is the maximum disk usage of the Elasticsearch nodes for the upgrade to proceed.
+	// Default: 80
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	MaxDiskUsagePercent *int32 `json:"maxDiskUsagePercent,omitempty"`
+
+	// MaxSnapshotAge is the maximum age of the latest successful snapshot of the snapshot repository for the upgrade to
+	// proceed. It only applies if a snapshot repository is given. If omitted, the age of the snapshots is not checked.
+	// +optional
+	MaxSnapshotAge *metav1.Duration `json:"maxSnapshotAge,omitempty"`
+
+	// SnapshotRepository is the name of an Elasticsearch snapshot repository that must hold a successful snapshot for
+	// the upgrade to proceed. If omitted, snapshots are not checked.
+	// +optional
+	SnapshotRepository string `json:"snapshotRepository,omitempty"`
+}
+
+// TenantRole defines an Elasticsearch role with document-level and field-level security on the log indices.
+type TenantRole struct {
+	// Name of the role. The role is created in Elasticsearch as tigera_tenant_<name>.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-_a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=64
+	Name string `json:"name"`
+
+	// Indices are the index privileges of the role.
+	// +kubebuilder:validation:MinItems=1
+	Indices []TenantRoleIndices `json:"indices"`
+}
+
+// TenantRoleIndices grants read access to the documents of a set of indices that match all the document filters, and
+// to a subset of their fields.
+type TenantRoleIndices struct {
+	// Names are the names or patterns of the indices, e.g. tigera_secure_ee_flows.*
+	// +kubebuilder:validation:MinItems=1
+	Names []string `json:"names"`
+
+	// DocumentFilters restrict the documents that the role can read to those that match all the filters. If omitted,
+	// all the documents of the indices can be read.
+	// +optional
+	DocumentFilters []TenantDocumentFilter `json:"documentFilters,omitempty"`
+
+	// GrantedFields are the fields that the role can read. Patterns, e.g. source_*, are supported. If omitted, all
+	// the fields can be read.
+	// +optional
+	GrantedFields []string `json:"grantedFields,omitempty"`
+
+	// DeniedFields are fields that the role cannot read, even if they are granted.
+	// +optional
+	DeniedFields []string `json:"deniedFields,omitempty"`
+}
+
+// TenantDocumentFilter matches the documents whose field has one of the values, e.g. the documents whose
+// source_namespace is one of the namespaces of a team.
+type TenantDocumentFilter struct {
+	// Field is the name of the field of the documents, e.g. source_namespace or cluster.
+	Field string `json:"field"`
+
+	// Values are the values of the field that match.
+	// +kubebuilder:validation:MinItems=1
+	Values []string `json:"values"`
+}
+
+// KibanaSpace defines a Kibana space and the OIDC groups whose members can access it.
+type KibanaSpace struct {
+	// ID of the space, which is part of its URL.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-_a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=64
+	ID string `json:"id"`
+
+	// Name of the space that is displayed in Kibana.
+	// Default: the ID of the space
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Groups are the OIDC groups whose members are granted read-only access to the space.
+	// +kubebuilder:validation:MinItems=1
+	Groups []string `json:"groups"`
+
+	// TenantRole is the name of one of the tenant roles that restricts the documents and fields of the log indices that
+	// the members of the groups can read. If omitted, they can read all the log indices.
+	// +optional
+	TenantRole string `json:"tenantRole,omitempty"`
+}
+
+// TenancyMode is how the logs of the tenants are isolated from the users that aren't members of a tenant.
+type TenancyMode string
+
+const (
+	TenancyModeShared   TenancyMode = "Shared"
+	TenancyModeIsolated TenancyMode = "Isolated"
+)
+
+// LogStorageTenancy defines the tenants of the log indices.
+type LogStorageTenancy struct {
+	// Mode is how the logs of the tenants are isolated. In the Shared mode, the Kibana spaces of the LogStorage without
+	// a tenant role can read all the log indices, including those of the tenants. In the Isolated mode, every Kibana
+	// space of the LogStorage must have a tenant role.
+	// Default: Shared
+	// +kubebuilder:validation:Enum=Shared;Isolated
+	// +optional
+	Mode *TenancyMode `json:"mode,omitempty"`
+
+	// Tenants are the teams whose logs are isolated from each other.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Tenants []LogStorageTenant `json:"tenants,omitempty"`
+}
+
+// LogStorageTenant defines a team and the managed clusters whose logs it owns.
+type LogStorageTenant struct {
+	// Name of the tenant. The Elasticsearch role of the tenant is created as tigera_tenant_<name>, its Elasticsearch
+	// user as tigera-tenant-<name>, and its Kibana space with the ID <name>.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=48
+	Name string `json:"name"`
+
+	// Clusters are the names of the managed clusters of the tenant. The tenant can only read the log indices of these
+	// clusters, i.e. the indices with the prefix tigera_secure_ee_<log type>.<cluster>.
+	// +kubebuilder:validation:MinItems=1
+	Clusters []string `json:"clusters"`
+
+	// Groups are the OIDC groups whose members are granted read-only access to the Kibana space of the tenant. If
+	// omitted, no Kibana space is created for the tenant.
+	// +optional
+	Groups []string `json:"groups,omitempty"`
+
+	// UserSecretNamespace is the namespace that the secret with the credentials of the Elasticsearch user of the tenant
+	// is copied to, so that the team can use it. If omitted, the secret is only in the namespace of the operator.
+	// +optional
+	UserSecretNamespace string `json:"userSecretNamespace,omitempty"`
 }
 
 // Nodes defines the configuration for a set of identical Elasticsearch cluster nodes, each of type master, data, and ingest.
@@ -80,6 +1077,110 @@ type Nodes struct {
 	// ResourceRequirements defines the resource limits and requirements for the Elasticsearch cluster.
 	// +optional
 	ResourceRequirements *corev1.ResourceRequirements `json:"resourceRequirements,omitempty"`
+
+	// JVMHeap defines how the JVM heap of the Elasticsearch nodes is sized.
+	// +optional
+	JVMHeap *JVMHeap `json:"jvmHeap,omitempty"`
+
+	// DiskWatermarks are the disk usage thresholds of the Elasticsearch nodes at which Elasticsearch stops allocating
+	// shards to a node, relocates shards away from it, and makes its indices read-only. If omitted, the defaults of
+	// Elasticsearch of 85%, 90% and 95% are used, which leave a lot of unused space on large disks. They are applied
+	// through the cluster settings of Elasticsearch, so changing them doesn't restart the nodes.
+	// +optional
+	DiskWatermarks *DiskWatermarks `json:"diskWatermarks,omitempty"`
+
+	// PodDisruptionBudget limits how many Elasticsearch nodes are disrupted at once by voluntary disruptions. If omitted,
+	// the default PodDisruptionBudget of ECK is used, which depends on the health of the cluster.
+	// +optional
+	PodDisruptionBudget *PodDisruptionBudget `json:"podDisruptionBudget,omitempty"`
+
+	// ExtraConfig is Elasticsearch configuration that is added to the elasticsearch.yml of each node, e.g. to tune
+	// thread pools, search queue sizes or circuit breakers. Settings that the operator manages, like the security and
+	// remote cluster settings, can't be overridden.
+	// +optional
+	ExtraConfig map[string]string `json:"extraConfig,omitempty"`
+
+	// PodMetadata are labels and annotations that are added to the Elasticsearch pods of every NodeSet, e.g. the
+	// annotations of a service mesh or a backup agent. Labels and annotations that the operator sets take precedence.
+	// +optional
+	PodMetadata *Metadata `json:"podMetadata,omitempty"`
+
+	// ZoneAwareness spreads the Elasticsearch nodes over one NodeSet per zone of the K8s nodes that Elasticsearch can
+	// run on, as read from their topology.kubernetes.io/zone label, and makes them aware of their zone, so that the
+	// replicas of a shard are allocated to nodes in other zones than the primary shard. When it is Enabled, each
+	// NodeSet without SelectionAttributes is split per zone. When it is Auto and no NodeSets are set, the nodes are
+	// spread if the K8s nodes are in several zones. An existing Elasticsearch cluster without zone NodeSets keeps its
+	// NodeSets in Auto mode, as replacing them would move all of its data.
+	// Default: Auto
+	// +optional
+	ZoneAwareness ZoneAwarenessType `json:"zoneAwareness,omitempty"`
+}
+
+// ZoneAwarenessType defines whether the Elasticsearch nodes are made aware of the zone of their K8s node.
+// +kubebuilder:validation:Enum=Enabled;Disabled;Auto
+type ZoneAwarenessType string
+
+const (
+	ZoneAwarenessEnabled  ZoneAwarenessType = "Enabled"
+	ZoneAwarenessDisabled ZoneAwarenessType = "Disabled"
+	ZoneAwarenessAuto     ZoneAwarenessType = "Auto"
+)
+
+// JVMHeapSizing is the method used to size the JVM heap of the Elasticsearch nodes.
+// +kubebuilder:validation:Enum=Fixed;ContainerAware
+type JVMHeapSizing string
+
+const (
+	// JVMHeapSizingFixed sets the heap to a fixed size (-Xms/-Xmx) of half the memory request of the Elasticsearch
+	// nodes, computed by the operator.
+	JVMHeapSizingFixed JVMHeapSizing = "Fixed"
+
+	// JVMHeapSizingContainerAware lets the JVM size the heap as a percentage of the memory limit of the container, as
+	// read from the cgroup (v1 or v2) of the container at startup. The heap then follows the memory limit when it is
+	// changed, including when the limit is resized in-place.
+	JVMHeapSizingContainerAware JVMHeapSizing = "ContainerAware"
+)
+
+// JVMHeap defines how the JVM heap of the Elasticsearch nodes is sized.
+type JVMHeap struct {
+	// Sizing is the method used to size the JVM heap.
+	// Default: Fixed
+	// +optional
+	Sizing JVMHeapSizing `json:"sizing,omitempty"`
+
+	// MaxRAMPercentage is the percentage of the memory limit of the container that is used for the JVM heap when the
+	// sizing is ContainerAware. The initial heap is set to the same percentage.
+	// Default: 50
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=90
+	MaxRAMPercentage *int32 `json:"maxRAMPercentage,omitempty"`
+}
+
+// DiskWatermarks defines the disk allocation watermarks of the Elasticsearch nodes, as percentages of their disk usage.
+// The watermarks that are set must be above the disk usage at which the curator removes the oldest indices, which is
+// 80%, and the watermarks must increase from low to high to flood stage.
+type DiskWatermarks struct {
+	// Low is the disk usage above which no shards are allocated to a node.
+	// Default: 85
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	Low *int32 `json:"low,omitempty"`
+
+	// High is the disk usage above which shards are relocated away from a node.
+	// Default: 90
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	High *int32 `json:"high,omitempty"`
+
+	// FloodStage is the disk usage above which the indices with a shard on a node are made read-only.
+	// Default: 95
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	FloodStage *int32 `json:"floodStage,omitempty"`
 }
 
 // NodeSets defines configuration specific to each Elasticsearch Node Set
@@ -88,6 +1189,35 @@ type NodeSet struct {
 	// Elasticsearch cluster awareness attributes for the Elasticsearch nodes. The list of SelectionAttributes are used
 	// to define Node Affinities and set the node awareness configuration in the running Elasticsearch instance.
 	SelectionAttributes []NodeSetSelectionAttribute `json:"selectionAttributes,omitempty"`
+
+	// Tolerations are the tolerations of the Elasticsearch pods of the NodeSet, e.g. to schedule them on a tainted
+	// pool of storage-optimized nodes. If specified, they replace the control plane tolerations of the Installation.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Affinity is the affinity of the Elasticsearch pods of the NodeSet. If specified, it replaces the node affinity
+	// derived from the SelectionAttributes of the NodeSet.
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// PodTemplatePatch is a strategic merge patch of the pod template of the Elasticsearch nodes of the NodeSet, for
+	// advanced cases like adding the sidecar of an APM agent or custom volumes. It is applied after the operator has
+	// generated the pod template. The service account, and the images, commands, arguments and security contexts of
+	// the containers and the volumes that the operator generates, can't be patched.
+	// +optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Type=object
+	PodTemplatePatch *runtime.RawExtension `json:"podTemplatePatch,omitempty"`
+
+	// StorageClassName is the name of the StorageClass of the volumes of the Elasticsearch nodes of the NodeSet, e.g.
+	// local NVMe storage for the nodes that hold the most recent logs. If omitted, spec.storageClassName is used.
+	// +optional
+	StorageClassName string `json:"storageClassName,omitempty"`
+
+	// StorageSize is the size of the volumes of the Elasticsearch nodes of the NodeSet. If omitted, the storage
+	// request of spec.nodes.resourceRequirements is used.
+	// +optional
+	StorageSize *resource.Quantity `json:"storageSize,omitempty"`
 }
 
 // NodeSetSelectionAttribute defines a K8s node "attribute" the Elasticsearch nodes should be aware of. The "Name" and "Value"
@@ -109,6 +1239,18 @@ type Indices struct {
 	Replicas *int32 `json:"replicas,omitempty"`
 }
 
+// IndexTemplates defines the overrides of the index templates of the log indices, e.g. of their number of shards and
+// replicas, field mappings and custom analyzers. The overrides take precedence over the index templates that the
+// components that write the logs install, and apply to the indices that are created after they are applied.
+type IndexTemplates struct {
+	// ConfigMapName is the name of a ConfigMap in the tigera-operator namespace with the overrides. Each key of the
+	// ConfigMap is the log type of the indices that the override applies to, one of flows, dns, bgp, l7, audit_ee,
+	// audit_kube, snapshots, compliance_reports, benchmark_results or events, and holds a JSON object with the
+	// settings, mappings and aliases of an Elasticsearch index template. The override of a key that is removed is
+	// deleted from Elasticsearch.
+	ConfigMapName string `json:"configMapName"`
+}
+
 // Retention defines how long data is retained in an Elasticsearch cluster before it is cleared.
 type Retention struct {
 	// Flows configures the retention period for flow logs, in days.  Logs written on a day that started at least this long ago
@@ -152,24 +1294,164 @@ type Retention struct {
 	// Default: 8
 	// +optional
 	BGPLogs *int32 `json:"bgpLogs"`
+
+	// Limits bound the number of documents or the size of the indices of a type, in addition to their retention
+	// period. They are applied by the index lifecycle policy of the type, which rolls an index over once it holds a
+	// quarter of a limit, like it does for the disk space that is allocated to the type. Indices are still removed at
+	// the end of their retention period.
+	// +optional
+	Limits []RetentionLimit `json:"limits,omitempty"`
+}
+
+// RetentionIndexType identifies the indices of a type of logs that a retention limit applies to.
+// +kubebuilder:validation:Enum=Flows;AuditReports;Snapshots;ComplianceReports;DNSLogs;BGPLogs
+type RetentionIndexType string
+
+const (
+	RetentionIndexTypeFlows             RetentionIndexType = "Flows"
+	RetentionIndexTypeAuditReports      RetentionIndexType = "AuditReports"
+	RetentionIndexTypeSnapshots         RetentionIndexType = "Snapshots"
+	RetentionIndexTypeComplianceReports RetentionIndexType = "ComplianceReports"
+	RetentionIndexTypeDNSLogs           RetentionIndexType = "DNSLogs"
+	RetentionIndexTypeBGPLogs           RetentionIndexType = "BGPLogs"
+)
+
+// RetentionLimit bounds the number of documents or the size of the indices of a type.
+type RetentionLimit struct {
+	// IndexType is the type of the indices that the limit applies to.
+	IndexType RetentionIndexType `json:"indexType"`
+
+	// MaxDocs is the maximum number of documents in the indices of the type.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxDocs *int64 `json:"maxDocs,omitempty"`
+
+	// MaxSize is the maximum size of the primary shards of the indices of the type.
+	// +optional
+	MaxSize *resource.Quantity `json:"maxSize,omitempty"`
 }
 
 // LogStorageComponentName CRD enum
 type LogStorageComponentName string
 
 const (
-	ComponentNameECKOperator LogStorageComponentName = "ECKOperator"
+	ComponentNameECKOperator   LogStorageComponentName = "ECKOperator"
+	ComponentNameKibana        LogStorageComponentName = "Kibana"
+	ComponentNameEsCurator     LogStorageComponentName = "EsCurator"
+	ComponentNameElasticsearch LogStorageComponentName = "Elasticsearch"
+	ComponentNameESGateway     LogStorageComponentName = "ESGateway"
+
+	ComponentNameESMetrics         LogStorageComponentName = "ESMetrics"
+	ComponentNameESKubeControllers LogStorageComponentName = "ESKubeControllers"
+	ComponentNameLogStorageJobs    LogStorageComponentName = "Jobs"
+)
+
+// ProviderResourcePresetsOption is whether the resource presets for the provider of the cluster are used.
+type ProviderResourcePresetsOption string
+
+const (
+	ProviderResourcePresetsEnabled  ProviderResourcePresetsOption = "Enabled"
+	ProviderResourcePresetsDisabled ProviderResourcePresetsOption = "Disabled"
 )
 
 // The ComponentResource struct associates a ResourceRequirements with a component by name
 type LogStorageComponentResource struct {
 	// ComponentName is an enum which identifies the component
-	// +kubebuilder:validation:Enum=ECKOperator
+	// +kubebuilder:validation:Enum=ECKOperator;Kibana;EsCurator
 	ComponentName LogStorageComponentName `json:"componentName"`
 	// ResourceRequirements allows customization of limits and requests for compute resources such as cpu and memory.
 	ResourceRequirements *corev1.ResourceRequirements `json:"resourceRequirements"`
 }
 
+// LogStorageComponentDisruptionPolicy associates a DisruptionPolicy with a component by name.
+type LogStorageComponentDisruptionPolicy struct {
+	// ComponentName identifies the component.
+	// +kubebuilder:validation:Enum=ECKOperator;Elasticsearch;Kibana;ESGateway;EsCurator;ESMetrics;ESKubeControllers;Jobs
+	ComponentName LogStorageComponentName `json:"componentName"`
+
+	DisruptionPolicy `json:",inline"`
+}
+
+// LogStorageComponentPriorityClass associates a PriorityClass with a component by name.
+type LogStorageComponentPriorityClass struct {
+	// ComponentName identifies the component.
+	// +kubebuilder:validation:Enum=Elasticsearch;Kibana;EsCurator
+	ComponentName LogStorageComponentName `json:"componentName"`
+
+	// PriorityClassName is the name of the PriorityClass of the pods of the component.
+	PriorityClassName string `json:"priorityClassName"`
+}
+
+// DefaultPriorityClassOption is whether the operator creates the default PriorityClass of the log storage.
+type DefaultPriorityClassOption string
+
+const (
+	DefaultPriorityClassEnabled  DefaultPriorityClassOption = "Enabled"
+	DefaultPriorityClassDisabled DefaultPriorityClassOption = "Disabled"
+)
+
+// DefaultPriorityClassEnabled returns whether the operator creates the default PriorityClass of the log storage.
+func (s *LogStorageSpec) DefaultPriorityClassEnabled() bool {
+	return s.DefaultPriorityClass != nil && *s.DefaultPriorityClass == DefaultPriorityClassEnabled
+}
+
+// ComponentPriorityClassName returns the name of the PriorityClass of the component. Unless the component overrides
+// it, this is the PriorityClass that the operator creates for the log storage if it is enabled, or none.
+func (s *LogStorageSpec) ComponentPriorityClassName(name LogStorageComponentName) string {
+	for _, priorityClass := range s.ComponentPriorityClasses {
+		if priorityClass.ComponentName == name {
+			return priorityClass.PriorityClassName
+		}
+	}
+	if s.DefaultPriorityClassEnabled() {
+		return DefaultLogStoragePriorityClassName
+	}
+	return ""
+}
+
+// ComponentDisruptionPolicy returns the DisruptionPolicy of the component, or nil if it has none.
+func (s *LogStorageSpec) ComponentDisruptionPolicy(name LogStorageComponentName) *DisruptionPolicy {
+	for i := range s.ComponentDisruptionPolicies {
+		if s.ComponentDisruptionPolicies[i].ComponentName == name {
+			return &s.ComponentDisruptionPolicies[i].DisruptionPolicy
+		}
+	}
+	return nil
+}
+
+// ECKValidatingWebhookEnabled returns whether the validating webhook of the ECK operator is enabled.
+func (s *LogStorageSpec) ECKValidatingWebhookEnabled() bool {
+	return s.ECKOperator != nil && s.ECKOperator.ValidatingWebhook != nil && *s.ECKOperator.ValidatingWebhook == ECKValidatingWebhookEnabled
+}
+
+// StorageClassNames returns the names of the StorageClasses of the volumes of Elasticsearch: the StorageClass of the
+// cluster and those of the NodeSets that override it.
+func (s *LogStorageSpec) StorageClassNames() []string {
+	names := []string{s.StorageClassName}
+	if s.Nodes == nil {
+		return names
+	}
+	for _, nodeSet := range s.Nodes.NodeSets {
+		if nodeSet.StorageClassName != "" && nodeSet.StorageClassName != s.StorageClassName {
+			names = append(names, nodeSet.StorageClassName)
+		}
+	}
+	return names
+}
+
+// Tenants returns the tenants of the LogStorage.
+func (s *LogStorageSpec) Tenants() []LogStorageTenant {
+	if s.Tenancy == nil {
+		return nil
+	}
+	return s.Tenancy.Tenants
+}
+
+// TenancyIsolated returns whether every Kibana space must be restricted by a tenant role.
+func (s *LogStorageSpec) TenancyIsolated() bool {
+	return s.Tenancy != nil && s.Tenancy.Mode != nil && *s.Tenancy.Mode == TenancyModeIsolated
+}
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Cluster
@@ -17,6 +17,7 @@ limitations under the License.
 package v1
 
 import (
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -30,6 +31,32 @@ type ManagementClusterConnectionSpec struct {
 	// TLS provides options for configuring how Managed Clusters can establish an mTLS connection with the Management Cluster.
 	// +optional
 	TLS *ManagementClusterTLS `json:"tls,omitempty"`
+
+	// LogBuffer configures a store on the managed cluster that retains the logs collected by fluentd while they cannot
+	// be sent to the management cluster, e.g. because the tunnel is down, and replays them once it is restored. If
+	// omitted, logs are sent to the management cluster directly.
+	// +optional
+	LogBuffer *LogBuffer `json:"logBuffer,omitempty"`
+}
+
+// LogBuffer defines the store that retains the logs of a managed cluster while they cannot be sent to the management
+// cluster.
+type LogBuffer struct {
+	// Retention is how long logs are retained while they cannot be sent to the management cluster. Logs that are
+	// older are dropped.
+	// Default: 6h
+	// +optional
+	Retention *metav1.Duration `json:"retention,omitempty"`
+
+	// StorageClassName is the storage class of the volume that holds the buffered logs. If omitted, the default
+	// storage class of the cluster is used.
+	// +optional
+	StorageClassName string `json:"storageClassName,omitempty"`
+
+	// Storage is the size of the volume that holds the buffered logs. Once it is full, the oldest logs are dropped.
+	// Default: 10Gi
+	// +optional
+	Storage *resource.Quantity `json:"storage,omitempty"`
 }
 
 type ManagementClusterTLS struct {
@@ -31,8 +31,42 @@ type TigeraStatusSpec struct {
 // TigeraStatusStatus defines the observed state of TigeraStatus
 type TigeraStatusStatus struct {
 	// Conditions represents the latest observed set of conditions for this component. A component may be one or more of
-	// Available, Progressing, or Degraded.
+	// Available, Progressing, or Degraded. The CopyDriftDetected condition is added once the operator repairs a copy of
+	// a secret or ConfigMap of the component that was modified.
 	Conditions []TigeraStatusCondition `json:"conditions"`
+
+	// RenderedComponents are the objects that the operator renders for this component, so that the objects that the
+	// operator owns can be enumerated without relying on their labels. They are maintained by the operator.
+	// +optional
+	RenderedComponents []RenderedComponent `json:"renderedComponents,omitempty"`
+}
+
+// RenderedComponent is an object that the operator renders and owns.
+// +k8s:deepcopy-gen=true
+type RenderedComponent struct {
+	// Kind is the kind of the object.
+	Kind string `json:"kind"`
+
+	// Name is the name of the object.
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the object, if it is namespaced.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// ResourceVersion is the resource version of the object as the operator last applied it, which changes whenever
+	// the operator changes the object.
+	// +optional
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+
+	// Owner is the kind and name of the resource that the object is rendered for, e.g. LogStorage/tigera-secure.
+	// +optional
+	Owner string `json:"owner,omitempty"`
+
+	// Ignored is whether the user has labelled the object with operator.tigera.io/ignore=true as managed by the user,
+	// so that the operator no longer updates it.
+	// +optional
+	Ignored bool `json:"ignored,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -77,12 +111,17 @@ const (
 
 	// Ready indicates that the component is healthy and ready.it is identical to Available and used in Status conditions for CRs.
 	ComponentReady StatusConditionType = "Ready"
+
+	// CopyDriftDetected means the operator found copies of secrets or ConfigMaps in the namespaces of the component that
+	// were modified since it wrote them, and overwrote them with the data of their source. It stays true for an hour
+	// after the last repair.
+	ComponentCopyDriftDetected StatusConditionType = "CopyDriftDetected"
 )
 
 // TigeraStatusCondition represents a condition attached to a particular component.
 // +k8s:deepcopy-gen=true
 type TigeraStatusCondition struct {
-	// The type of condition. May be Available, Progressing, or Degraded.
+	// The type of condition. May be Available, Progressing, Degraded, or CopyDriftDetected.
 	Type StatusConditionType `json:"type"`
 
 	// The status of the condition. May be True, False, or Unknown.
@@ -134,6 +173,8 @@ const (
 	InternalServerError       TigeraStatusReason = "InternalServerError"
 	NotApplicable             TigeraStatusReason = "NotApplicable"
 	UpgradeError              TigeraStatusReason = "UpgradeError"
+	CopyDriftRepaired         TigeraStatusReason = "CopyDriftRepaired"
+	AllCopiesInSync           TigeraStatusReason = "AllCopiesInSync"
 	Unknown                   TigeraStatusReason = "Unknown"
 )
 
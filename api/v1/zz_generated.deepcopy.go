@@ -25,6 +25,8 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -290,7 +292,7 @@ func (in *AdditionalLogSourceSpec) DeepCopyInto(out *AdditionalLogSourceSpec) {
 	if in.EksCloudwatchLog != nil {
 		in, out := &in.EksCloudwatchLog, &out.EksCloudwatchLog
 		*out = new(EksCloudwatchLogsSpec)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -320,7 +322,7 @@ func (in *AdditionalLogStoreSpec) DeepCopyInto(out *AdditionalLogStoreSpec) {
 	if in.Splunk != nil {
 		in, out := &in.Splunk, &out.Splunk
 		*out = new(SplunkStoreSpec)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -334,6 +336,26 @@ func (in *AdditionalLogStoreSpec) DeepCopy() *AdditionalLogStoreSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdditionalStoreTLS) DeepCopyInto(out *AdditionalStoreTLS) {
+	*out = *in
+	if in.CASecret != nil {
+		in, out := &in.CASecret, &out.CASecret
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdditionalStoreTLS.
+func (in *AdditionalStoreTLS) DeepCopy() *AdditionalStoreTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(AdditionalStoreTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AmazonCloudIntegration) DeepCopyInto(out *AmazonCloudIntegration) {
 	*out = *in
@@ -547,6 +569,51 @@ func (in *ApplicationLayerStatus) DeepCopy() *ApplicationLayerStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArchiveRestore) DeepCopyInto(out *ArchiveRestore) {
+	*out = *in
+	in.Start.DeepCopyInto(&out.Start)
+	in.End.DeepCopyInto(&out.End)
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArchiveRestore.
+func (in *ArchiveRestore) DeepCopy() *ArchiveRestore {
+	if in == nil {
+		return nil
+	}
+	out := new(ArchiveRestore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArchiveRestoreStatus) DeepCopyInto(out *ArchiveRestoreStatus) {
+	*out = *in
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ExpirationTime != nil {
+		in, out := &in.ExpirationTime, &out.ExpirationTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArchiveRestoreStatus.
+func (in *ArchiveRestoreStatus) DeepCopy() *ArchiveRestoreStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ArchiveRestoreStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Auth) DeepCopyInto(out *Auth) {
 	*out = *in
@@ -1388,554 +1455,528 @@ func (in *ComponentResource) DeepCopy() *ComponentResource {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EksCloudwatchLogsSpec) DeepCopyInto(out *EksCloudwatchLogsSpec) {
+func (in *ContainerLogs) DeepCopyInto(out *ContainerLogs) {
 	*out = *in
+	if in.Runtime != nil {
+		in, out := &in.Runtime, &out.Runtime
+		*out = new(ContainerRuntime)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EksCloudwatchLogsSpec.
-func (in *EksCloudwatchLogsSpec) DeepCopy() *EksCloudwatchLogsSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerLogs.
+func (in *ContainerLogs) DeepCopy() *ContainerLogs {
 	if in == nil {
 		return nil
 	}
-	out := new(EksCloudwatchLogsSpec)
+	out := new(ContainerLogs)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GroupSearch) DeepCopyInto(out *GroupSearch) {
+func (in *CuratorBackpressure) DeepCopyInto(out *CuratorBackpressure) {
 	*out = *in
-	if in.UserMatchers != nil {
-		in, out := &in.UserMatchers, &out.UserMatchers
-		*out = make([]UserMatch, len(*in))
-		copy(*out, *in)
+	if in.RelocatingShardsThreshold != nil {
+		in, out := &in.RelocatingShardsThreshold, &out.RelocatingShardsThreshold
+		*out = new(int32)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GroupSearch.
-func (in *GroupSearch) DeepCopy() *GroupSearch {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CuratorBackpressure.
+func (in *CuratorBackpressure) DeepCopy() *CuratorBackpressure {
 	if in == nil {
 		return nil
 	}
-	out := new(GroupSearch)
+	out := new(CuratorBackpressure)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IPAMSpec) DeepCopyInto(out *IPAMSpec) {
+func (in *DiskWatermarks) DeepCopyInto(out *DiskWatermarks) {
 	*out = *in
+	if in.Low != nil {
+		in, out := &in.Low, &out.Low
+		*out = new(int32)
+		**out = **in
+	}
+	if in.High != nil {
+		in, out := &in.High, &out.High
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FloodStage != nil {
+		in, out := &in.FloodStage, &out.FloodStage
+		*out = new(int32)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPAMSpec.
-func (in *IPAMSpec) DeepCopy() *IPAMSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskWatermarks.
+func (in *DiskWatermarks) DeepCopy() *DiskWatermarks {
 	if in == nil {
 		return nil
 	}
-	out := new(IPAMSpec)
+	out := new(DiskWatermarks)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IPPool) DeepCopyInto(out *IPPool) {
+func (in *DisruptionPolicy) DeepCopyInto(out *DisruptionPolicy) {
 	*out = *in
-	if in.BlockSize != nil {
-		in, out := &in.BlockSize, &out.BlockSize
-		*out = new(int32)
+	if in.TerminationGracePeriodSeconds != nil {
+		in, out := &in.TerminationGracePeriodSeconds, &out.TerminationGracePeriodSeconds
+		*out = new(int64)
 		**out = **in
 	}
-	if in.DisableBGPExport != nil {
-		in, out := &in.DisableBGPExport, &out.DisableBGPExport
-		*out = new(bool)
+	if in.PreStopDelaySeconds != nil {
+		in, out := &in.PreStopDelaySeconds, &out.PreStopDelaySeconds
+		*out = new(int64)
 		**out = **in
 	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(PodDisruptionBudget)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPPool.
-func (in *IPPool) DeepCopy() *IPPool {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DisruptionPolicy.
+func (in *DisruptionPolicy) DeepCopy() *DisruptionPolicy {
 	if in == nil {
 		return nil
 	}
-	out := new(IPPool)
+	out := new(DisruptionPolicy)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Image) DeepCopyInto(out *Image) {
+func (in *ECKOperatorSpec) DeepCopyInto(out *ECKOperatorSpec) {
 	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ValidatingWebhook != nil {
+		in, out := &in.ValidatingWebhook, &out.ValidatingWebhook
+		*out = new(ECKValidatingWebhook)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Image.
-func (in *Image) DeepCopy() *Image {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ECKOperatorSpec.
+func (in *ECKOperatorSpec) DeepCopy() *ECKOperatorSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(Image)
+	out := new(ECKOperatorSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ImageSet) DeepCopyInto(out *ImageSet) {
+func (in *ESGatewayAutoscaling) DeepCopyInto(out *ESGatewayAutoscaling) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetCPUUtilizationPercentage != nil {
+		in, out := &in.TargetCPUUtilizationPercentage, &out.TargetCPUUtilizationPercentage
+		*out = new(int32)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageSet.
-func (in *ImageSet) DeepCopy() *ImageSet {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ESGatewayAutoscaling.
+func (in *ESGatewayAutoscaling) DeepCopy() *ESGatewayAutoscaling {
 	if in == nil {
 		return nil
 	}
-	out := new(ImageSet)
+	out := new(ESGatewayAutoscaling)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ImageSet) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ImageSetList) DeepCopyInto(out *ImageSetList) {
+func (in *ESGatewaySpec) DeepCopyInto(out *ESGatewaySpec) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]ImageSet, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.MaxConnections != nil {
+		in, out := &in.MaxConnections, &out.MaxConnections
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RequestTimeout != nil {
+		in, out := &in.RequestTimeout, &out.RequestTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.IdleTimeout != nil {
+		in, out := &in.IdleTimeout, &out.IdleTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MaxBodySize != nil {
+		in, out := &in.MaxBodySize, &out.MaxBodySize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(ESGatewayAutoscaling)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageSetList.
-func (in *ImageSetList) DeepCopy() *ImageSetList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ESGatewaySpec.
+func (in *ESGatewaySpec) DeepCopy() *ESGatewaySpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ImageSetList)
+	out := new(ESGatewaySpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ImageSetList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ImageSetSpec) DeepCopyInto(out *ImageSetSpec) {
+func (in *ESUserRotation) DeepCopyInto(out *ESUserRotation) {
 	*out = *in
-	if in.Images != nil {
-		in, out := &in.Images, &out.Images
-		*out = make([]Image, len(*in))
-		copy(*out, *in)
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(metav1.Duration)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageSetSpec.
-func (in *ImageSetSpec) DeepCopy() *ImageSetSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ESUserRotation.
+func (in *ESUserRotation) DeepCopy() *ESUserRotation {
 	if in == nil {
 		return nil
 	}
-	out := new(ImageSetSpec)
+	out := new(ESUserRotation)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Indices) DeepCopyInto(out *Indices) {
+func (in *EksCloudwatchLogsSpec) DeepCopyInto(out *EksCloudwatchLogsSpec) {
 	*out = *in
-	if in.Replicas != nil {
-		in, out := &in.Replicas, &out.Replicas
-		*out = new(int32)
-		**out = **in
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(PodDisruptionBudget)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Indices.
-func (in *Indices) DeepCopy() *Indices {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EksCloudwatchLogsSpec.
+func (in *EksCloudwatchLogsSpec) DeepCopy() *EksCloudwatchLogsSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(Indices)
+	out := new(EksCloudwatchLogsSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Installation) DeepCopyInto(out *Installation) {
+func (in *ElasticEnterpriseLicense) DeepCopyInto(out *ElasticEnterpriseLicense) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Installation.
-func (in *Installation) DeepCopy() *Installation {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticEnterpriseLicense.
+func (in *ElasticEnterpriseLicense) DeepCopy() *ElasticEnterpriseLicense {
 	if in == nil {
 		return nil
 	}
-	out := new(Installation)
+	out := new(ElasticEnterpriseLicense)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Installation) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticsearchTransportTLS) DeepCopyInto(out *ElasticsearchTransportTLS) {
+	*out = *in
+	if in.VerificationMode != nil {
+		in, out := &in.VerificationMode, &out.VerificationMode
+		*out = new(TransportTLSVerificationMode)
+		**out = **in
 	}
-	return nil
+	if in.SupportedProtocols != nil {
+		in, out := &in.SupportedProtocols, &out.SupportedProtocols
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CipherSuites != nil {
+		in, out := &in.CipherSuites, &out.CipherSuites
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchTransportTLS.
+func (in *ElasticsearchTransportTLS) DeepCopy() *ElasticsearchTransportTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticsearchTransportTLS)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *InstallationList) DeepCopyInto(out *InstallationList) {
+func (in *FluentdCandidate) DeepCopyInto(out *FluentdCandidate) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]Installation, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
 		}
 	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]FluentdEnvVar, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstallationList.
-func (in *InstallationList) DeepCopy() *InstallationList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FluentdCandidate.
+func (in *FluentdCandidate) DeepCopy() *FluentdCandidate {
 	if in == nil {
 		return nil
 	}
-	out := new(InstallationList)
+	out := new(FluentdCandidate)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *InstallationList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FluentdControlPlaneNodes) DeepCopyInto(out *FluentdControlPlaneNodes) {
+	*out = *in
+	if in.ResourceRequirements != nil {
+		in, out := &in.ResourceRequirements, &out.ResourceRequirements
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
 	}
-	return nil
 }
 
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *InstallationSpec) DeepCopyInto(out *InstallationSpec) {
-	*out = *in
-	if in.ImagePullSecrets != nil {
-		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
-		*out = make([]corev1.LocalObjectReference, len(*in))
-		copy(*out, *in)
-	}
-	if in.CNI != nil {
-		in, out := &in.CNI, &out.CNI
-		*out = new(CNISpec)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.CalicoNetwork != nil {
-		in, out := &in.CalicoNetwork, &out.CalicoNetwork
-		*out = new(CalicoNetworkSpec)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.TyphaAffinity != nil {
-		in, out := &in.TyphaAffinity, &out.TyphaAffinity
-		*out = new(TyphaAffinity)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.ControlPlaneNodeSelector != nil {
-		in, out := &in.ControlPlaneNodeSelector, &out.ControlPlaneNodeSelector
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.ControlPlaneTolerations != nil {
-		in, out := &in.ControlPlaneTolerations, &out.ControlPlaneTolerations
-		*out = make([]corev1.Toleration, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.ControlPlaneReplicas != nil {
-		in, out := &in.ControlPlaneReplicas, &out.ControlPlaneReplicas
-		*out = new(int32)
-		**out = **in
-	}
-	if in.NodeMetricsPort != nil {
-		in, out := &in.NodeMetricsPort, &out.NodeMetricsPort
-		*out = new(int32)
-		**out = **in
-	}
-	if in.TyphaMetricsPort != nil {
-		in, out := &in.TyphaMetricsPort, &out.TyphaMetricsPort
-		*out = new(int32)
-		**out = **in
-	}
-	in.NodeUpdateStrategy.DeepCopyInto(&out.NodeUpdateStrategy)
-	if in.ComponentResources != nil {
-		in, out := &in.ComponentResources, &out.ComponentResources
-		*out = make([]ComponentResource, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.CertificateManagement != nil {
-		in, out := &in.CertificateManagement, &out.CertificateManagement
-		*out = new(CertificateManagement)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.NonPrivileged != nil {
-		in, out := &in.NonPrivileged, &out.NonPrivileged
-		*out = new(NonPrivilegedType)
-		**out = **in
-	}
-	if in.CalicoNodeDaemonSet != nil {
-		in, out := &in.CalicoNodeDaemonSet, &out.CalicoNodeDaemonSet
-		*out = new(CalicoNodeDaemonSet)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.CalicoKubeControllersDeployment != nil {
-		in, out := &in.CalicoKubeControllersDeployment, &out.CalicoKubeControllersDeployment
-		*out = new(CalicoKubeControllersDeployment)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.TyphaDeployment != nil {
-		in, out := &in.TyphaDeployment, &out.TyphaDeployment
-		*out = new(TyphaDeployment)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.CalicoWindowsUpgradeDaemonSet != nil {
-		in, out := &in.CalicoWindowsUpgradeDaemonSet, &out.CalicoWindowsUpgradeDaemonSet
-		*out = new(CalicoWindowsUpgradeDaemonSet)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.FIPSMode != nil {
-		in, out := &in.FIPSMode, &out.FIPSMode
-		*out = new(FIPSMode)
-		**out = **in
-	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstallationSpec.
-func (in *InstallationSpec) DeepCopy() *InstallationSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FluentdControlPlaneNodes.
+func (in *FluentdControlPlaneNodes) DeepCopy() *FluentdControlPlaneNodes {
 	if in == nil {
 		return nil
 	}
-	out := new(InstallationSpec)
+	out := new(FluentdControlPlaneNodes)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *InstallationStatus) DeepCopyInto(out *InstallationStatus) {
+func (in *FluentdElasticsearchOutput) DeepCopyInto(out *FluentdElasticsearchOutput) {
 	*out = *in
-	if in.Computed != nil {
-		in, out := &in.Computed, &out.Computed
-		*out = new(InstallationSpec)
-		(*in).DeepCopyInto(*out)
+	if in.BulkMessageSize != nil {
+		in, out := &in.BulkMessageSize, &out.BulkMessageSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.BulkRequestThreshold != nil {
+		in, out := &in.BulkRequestThreshold, &out.BulkRequestThreshold
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.RequestTimeout != nil {
+		in, out := &in.RequestTimeout, &out.RequestTimeout
+		*out = new(metav1.Duration)
+		**out = **in
 	}
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]metav1.Condition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.ReconnectOnError != nil {
+		in, out := &in.ReconnectOnError, &out.ReconnectOnError
+		*out = new(bool)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstallationStatus.
-func (in *InstallationStatus) DeepCopy() *InstallationStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FluentdElasticsearchOutput.
+func (in *FluentdElasticsearchOutput) DeepCopy() *FluentdElasticsearchOutput {
 	if in == nil {
 		return nil
 	}
-	out := new(InstallationStatus)
+	out := new(FluentdElasticsearchOutput)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IntrusionDetection) DeepCopyInto(out *IntrusionDetection) {
+func (in *FluentdEnvVar) DeepCopyInto(out *FluentdEnvVar) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntrusionDetection.
-func (in *IntrusionDetection) DeepCopy() *IntrusionDetection {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FluentdEnvVar.
+func (in *FluentdEnvVar) DeepCopy() *FluentdEnvVar {
 	if in == nil {
 		return nil
 	}
-	out := new(IntrusionDetection)
+	out := new(FluentdEnvVar)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *IntrusionDetection) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IntrusionDetectionComponentResource) DeepCopyInto(out *IntrusionDetectionComponentResource) {
+func (in *FluentdNodePool) DeepCopyInto(out *FluentdNodePool) {
 	*out = *in
-	if in.ResourceRequirements != nil {
-		in, out := &in.ResourceRequirements, &out.ResourceRequirements
-		*out = new(corev1.ResourceRequirements)
-		(*in).DeepCopyInto(*out)
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]FluentdEnvVar, len(*in))
+		copy(*out, *in)
+	}
+	if in.ContainerRuntime != nil {
+		in, out := &in.ContainerRuntime, &out.ContainerRuntime
+		*out = new(ContainerRuntime)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntrusionDetectionComponentResource.
-func (in *IntrusionDetectionComponentResource) DeepCopy() *IntrusionDetectionComponentResource {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FluentdNodePool.
+func (in *FluentdNodePool) DeepCopy() *FluentdNodePool {
 	if in == nil {
 		return nil
 	}
-	out := new(IntrusionDetectionComponentResource)
+	out := new(FluentdNodePool)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IntrusionDetectionList) DeepCopyInto(out *IntrusionDetectionList) {
+func (in *GroupSearch) DeepCopyInto(out *GroupSearch) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]IntrusionDetection, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.UserMatchers != nil {
+		in, out := &in.UserMatchers, &out.UserMatchers
+		*out = make([]UserMatch, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntrusionDetectionList.
-func (in *IntrusionDetectionList) DeepCopy() *IntrusionDetectionList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GroupSearch.
+func (in *GroupSearch) DeepCopy() *GroupSearch {
 	if in == nil {
 		return nil
 	}
-	out := new(IntrusionDetectionList)
+	out := new(GroupSearch)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *IntrusionDetectionList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IntrusionDetectionSpec) DeepCopyInto(out *IntrusionDetectionSpec) {
+func (in *IPAMSpec) DeepCopyInto(out *IPAMSpec) {
 	*out = *in
-	if in.ComponentResources != nil {
-		in, out := &in.ComponentResources, &out.ComponentResources
-		*out = make([]IntrusionDetectionComponentResource, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	out.AnomalyDetection = in.AnomalyDetection
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntrusionDetectionSpec.
-func (in *IntrusionDetectionSpec) DeepCopy() *IntrusionDetectionSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPAMSpec.
+func (in *IPAMSpec) DeepCopy() *IPAMSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(IntrusionDetectionSpec)
+	out := new(IPAMSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IntrusionDetectionStatus) DeepCopyInto(out *IntrusionDetectionStatus) {
+func (in *IPPool) DeepCopyInto(out *IPPool) {
 	*out = *in
+	if in.BlockSize != nil {
+		in, out := &in.BlockSize, &out.BlockSize
+		*out = new(int32)
+		**out = **in
+	}
+	if in.DisableBGPExport != nil {
+		in, out := &in.DisableBGPExport, &out.DisableBGPExport
+		*out = new(bool)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntrusionDetectionStatus.
-func (in *IntrusionDetectionStatus) DeepCopy() *IntrusionDetectionStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPPool.
+func (in *IPPool) DeepCopy() *IPPool {
 	if in == nil {
 		return nil
 	}
-	out := new(IntrusionDetectionStatus)
+	out := new(IPPool)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LogCollectionSpec) DeepCopyInto(out *LogCollectionSpec) {
+func (in *Image) DeepCopyInto(out *Image) {
 	*out = *in
-	if in.CollectLogs != nil {
-		in, out := &in.CollectLogs, &out.CollectLogs
-		*out = new(LogCollectionStatusType)
-		**out = **in
-	}
-	if in.LogIntervalSeconds != nil {
-		in, out := &in.LogIntervalSeconds, &out.LogIntervalSeconds
-		*out = new(int64)
-		**out = **in
-	}
-	if in.LogRequestsPerInterval != nil {
-		in, out := &in.LogRequestsPerInterval, &out.LogRequestsPerInterval
-		*out = new(int64)
-		**out = **in
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogCollectionSpec.
-func (in *LogCollectionSpec) DeepCopy() *LogCollectionSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Image.
+func (in *Image) DeepCopy() *Image {
 	if in == nil {
 		return nil
 	}
-	out := new(LogCollectionSpec)
+	out := new(Image)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LogCollector) DeepCopyInto(out *LogCollector) {
+func (in *ImageSet) DeepCopyInto(out *ImageSet) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogCollector.
-func (in *LogCollector) DeepCopy() *LogCollector {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageSet.
+func (in *ImageSet) DeepCopy() *ImageSet {
 	if in == nil {
 		return nil
 	}
-	out := new(LogCollector)
+	out := new(ImageSet)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *LogCollector) DeepCopyObject() runtime.Object {
+func (in *ImageSet) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1943,31 +1984,31 @@ func (in *LogCollector) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LogCollectorList) DeepCopyInto(out *LogCollectorList) {
+func (in *ImageSetList) DeepCopyInto(out *ImageSetList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]LogCollector, len(*in))
+		*out = make([]ImageSet, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogCollectorList.
-func (in *LogCollectorList) DeepCopy() *LogCollectorList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageSetList.
+func (in *ImageSetList) DeepCopy() *ImageSetList {
 	if in == nil {
 		return nil
 	}
-	out := new(LogCollectorList)
+	out := new(ImageSetList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *LogCollectorList) DeepCopyObject() runtime.Object {
+func (in *ImageSetList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1975,123 +2016,133 @@ func (in *LogCollectorList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LogCollectorSpec) DeepCopyInto(out *LogCollectorSpec) {
+func (in *ImageSetSpec) DeepCopyInto(out *ImageSetSpec) {
 	*out = *in
-	if in.AdditionalStores != nil {
-		in, out := &in.AdditionalStores, &out.AdditionalStores
-		*out = new(AdditionalLogStoreSpec)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.AdditionalSources != nil {
-		in, out := &in.AdditionalSources, &out.AdditionalSources
-		*out = new(AdditionalLogSourceSpec)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.CollectProcessPath != nil {
-		in, out := &in.CollectProcessPath, &out.CollectProcessPath
-		*out = new(CollectProcessPathOption)
-		**out = **in
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]Image, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogCollectorSpec.
-func (in *LogCollectorSpec) DeepCopy() *LogCollectorSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageSetSpec.
+func (in *ImageSetSpec) DeepCopy() *ImageSetSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(LogCollectorSpec)
+	out := new(ImageSetSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LogCollectorStatus) DeepCopyInto(out *LogCollectorStatus) {
+func (in *IndexTemplates) DeepCopyInto(out *IndexTemplates) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogCollectorStatus.
-func (in *LogCollectorStatus) DeepCopy() *LogCollectorStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexTemplates.
+func (in *IndexTemplates) DeepCopy() *IndexTemplates {
 	if in == nil {
 		return nil
 	}
-	out := new(LogCollectorStatus)
+	out := new(IndexTemplates)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LogStorage) DeepCopyInto(out *LogStorage) {
+func (in *Indices) DeepCopyInto(out *Indices) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogStorage.
-func (in *LogStorage) DeepCopy() *LogStorage {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Indices.
+func (in *Indices) DeepCopy() *Indices {
 	if in == nil {
 		return nil
 	}
-	out := new(LogStorage)
+	out := new(Indices)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *LogStorage) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngestionLatency) DeepCopyInto(out *IngestionLatency) {
+	*out = *in
+	if in.SLO != nil {
+		in, out := &in.SLO, &out.SLO
+		*out = new(metav1.Duration)
+		**out = **in
 	}
-	return nil
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngestionLatency.
+func (in *IngestionLatency) DeepCopy() *IngestionLatency {
+	if in == nil {
+		return nil
+	}
+	out := new(IngestionLatency)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LogStorageComponentResource) DeepCopyInto(out *LogStorageComponentResource) {
+func (in *Installation) DeepCopyInto(out *Installation) {
 	*out = *in
-	if in.ResourceRequirements != nil {
-		in, out := &in.ResourceRequirements, &out.ResourceRequirements
-		*out = new(corev1.ResourceRequirements)
-		(*in).DeepCopyInto(*out)
-	}
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogStorageComponentResource.
-func (in *LogStorageComponentResource) DeepCopy() *LogStorageComponentResource {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Installation.
+func (in *Installation) DeepCopy() *Installation {
 	if in == nil {
 		return nil
 	}
-	out := new(LogStorageComponentResource)
+	out := new(Installation)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Installation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LogStorageList) DeepCopyInto(out *LogStorageList) {
+func (in *InstallationList) DeepCopyInto(out *InstallationList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]LogStorage, len(*in))
+		*out = make([]Installation, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogStorageList.
-func (in *LogStorageList) DeepCopy() *LogStorageList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstallationList.
+func (in *InstallationList) DeepCopy() *InstallationList {
 	if in == nil {
 		return nil
 	}
-	out := new(LogStorageList)
+	out := new(InstallationList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *LogStorageList) DeepCopyObject() runtime.Object {
+func (in *InstallationList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -2099,84 +2150,160 @@ func (in *LogStorageList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LogStorageSpec) DeepCopyInto(out *LogStorageSpec) {
+func (in *InstallationSpec) DeepCopyInto(out *InstallationSpec) {
 	*out = *in
-	if in.Nodes != nil {
-		in, out := &in.Nodes, &out.Nodes
-		*out = new(Nodes)
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]corev1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.CNI != nil {
+		in, out := &in.CNI, &out.CNI
+		*out = new(CNISpec)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.Indices != nil {
-		in, out := &in.Indices, &out.Indices
-		*out = new(Indices)
+	if in.CalicoNetwork != nil {
+		in, out := &in.CalicoNetwork, &out.CalicoNetwork
+		*out = new(CalicoNetworkSpec)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.Retention != nil {
-		in, out := &in.Retention, &out.Retention
-		*out = new(Retention)
+	if in.TyphaAffinity != nil {
+		in, out := &in.TyphaAffinity, &out.TyphaAffinity
+		*out = new(TyphaAffinity)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.DataNodeSelector != nil {
-		in, out := &in.DataNodeSelector, &out.DataNodeSelector
+	if in.ControlPlaneNodeSelector != nil {
+		in, out := &in.ControlPlaneNodeSelector, &out.ControlPlaneNodeSelector
 		*out = make(map[string]string, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val
 		}
 	}
+	if in.ControlPlaneTolerations != nil {
+		in, out := &in.ControlPlaneTolerations, &out.ControlPlaneTolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ControlPlaneReplicas != nil {
+		in, out := &in.ControlPlaneReplicas, &out.ControlPlaneReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.NodeMetricsPort != nil {
+		in, out := &in.NodeMetricsPort, &out.NodeMetricsPort
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TyphaMetricsPort != nil {
+		in, out := &in.TyphaMetricsPort, &out.TyphaMetricsPort
+		*out = new(int32)
+		**out = **in
+	}
+	in.NodeUpdateStrategy.DeepCopyInto(&out.NodeUpdateStrategy)
 	if in.ComponentResources != nil {
 		in, out := &in.ComponentResources, &out.ComponentResources
-		*out = make([]LogStorageComponentResource, len(*in))
+		*out = make([]ComponentResource, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.CertificateManagement != nil {
+		in, out := &in.CertificateManagement, &out.CertificateManagement
+		*out = new(CertificateManagement)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NonPrivileged != nil {
+		in, out := &in.NonPrivileged, &out.NonPrivileged
+		*out = new(NonPrivilegedType)
+		**out = **in
+	}
+	if in.CalicoNodeDaemonSet != nil {
+		in, out := &in.CalicoNodeDaemonSet, &out.CalicoNodeDaemonSet
+		*out = new(CalicoNodeDaemonSet)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CalicoKubeControllersDeployment != nil {
+		in, out := &in.CalicoKubeControllersDeployment, &out.CalicoKubeControllersDeployment
+		*out = new(CalicoKubeControllersDeployment)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TyphaDeployment != nil {
+		in, out := &in.TyphaDeployment, &out.TyphaDeployment
+		*out = new(TyphaDeployment)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CalicoWindowsUpgradeDaemonSet != nil {
+		in, out := &in.CalicoWindowsUpgradeDaemonSet, &out.CalicoWindowsUpgradeDaemonSet
+		*out = new(CalicoWindowsUpgradeDaemonSet)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FIPSMode != nil {
+		in, out := &in.FIPSMode, &out.FIPSMode
+		*out = new(FIPSMode)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogStorageSpec.
-func (in *LogStorageSpec) DeepCopy() *LogStorageSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstallationSpec.
+func (in *InstallationSpec) DeepCopy() *InstallationSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(LogStorageSpec)
+	out := new(InstallationSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LogStorageStatus) DeepCopyInto(out *LogStorageStatus) {
+func (in *InstallationStatus) DeepCopyInto(out *InstallationStatus) {
 	*out = *in
+	if in.Computed != nil {
+		in, out := &in.Computed, &out.Computed
+		*out = new(InstallationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogStorageStatus.
-func (in *LogStorageStatus) DeepCopy() *LogStorageStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstallationStatus.
+func (in *InstallationStatus) DeepCopy() *InstallationStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(LogStorageStatus)
+	out := new(InstallationStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ManagementCluster) DeepCopyInto(out *ManagementCluster) {
+func (in *IntrusionDetection) DeepCopyInto(out *IntrusionDetection) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagementCluster.
-func (in *ManagementCluster) DeepCopy() *ManagementCluster {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntrusionDetection.
+func (in *IntrusionDetection) DeepCopy() *IntrusionDetection {
 	if in == nil {
 		return nil
 	}
-	out := new(ManagementCluster)
+	out := new(IntrusionDetection)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ManagementCluster) DeepCopyObject() runtime.Object {
+func (in *IntrusionDetection) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -2184,57 +2311,51 @@ func (in *ManagementCluster) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ManagementClusterConnection) DeepCopyInto(out *ManagementClusterConnection) {
+func (in *IntrusionDetectionComponentResource) DeepCopyInto(out *IntrusionDetectionComponentResource) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
+	if in.ResourceRequirements != nil {
+		in, out := &in.ResourceRequirements, &out.ResourceRequirements
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagementClusterConnection.
-func (in *ManagementClusterConnection) DeepCopy() *ManagementClusterConnection {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntrusionDetectionComponentResource.
+func (in *IntrusionDetectionComponentResource) DeepCopy() *IntrusionDetectionComponentResource {
 	if in == nil {
 		return nil
 	}
-	out := new(ManagementClusterConnection)
+	out := new(IntrusionDetectionComponentResource)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ManagementClusterConnection) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ManagementClusterConnectionList) DeepCopyInto(out *ManagementClusterConnectionList) {
+func (in *IntrusionDetectionList) DeepCopyInto(out *IntrusionDetectionList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]ManagementClusterConnection, len(*in))
+		*out = make([]IntrusionDetection, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagementClusterConnectionList.
-func (in *ManagementClusterConnectionList) DeepCopy() *ManagementClusterConnectionList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntrusionDetectionList.
+func (in *IntrusionDetectionList) DeepCopy() *IntrusionDetectionList {
 	if in == nil {
 		return nil
 	}
-	out := new(ManagementClusterConnectionList)
+	out := new(IntrusionDetectionList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ManagementClusterConnectionList) DeepCopyObject() runtime.Object {
+func (in *IntrusionDetectionList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -2242,94 +2363,221 @@ func (in *ManagementClusterConnectionList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ManagementClusterConnectionSpec) DeepCopyInto(out *ManagementClusterConnectionSpec) {
+func (in *IntrusionDetectionSpec) DeepCopyInto(out *IntrusionDetectionSpec) {
 	*out = *in
-	if in.TLS != nil {
-		in, out := &in.TLS, &out.TLS
-		*out = new(ManagementClusterTLS)
+	if in.ComponentResources != nil {
+		in, out := &in.ComponentResources, &out.ComponentResources
+		*out = make([]IntrusionDetectionComponentResource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	out.AnomalyDetection = in.AnomalyDetection
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntrusionDetectionSpec.
+func (in *IntrusionDetectionSpec) DeepCopy() *IntrusionDetectionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IntrusionDetectionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IntrusionDetectionStatus) DeepCopyInto(out *IntrusionDetectionStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntrusionDetectionStatus.
+func (in *IntrusionDetectionStatus) DeepCopy() *IntrusionDetectionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IntrusionDetectionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JVMHeap) DeepCopyInto(out *JVMHeap) {
+	*out = *in
+	if in.MaxRAMPercentage != nil {
+		in, out := &in.MaxRAMPercentage, &out.MaxRAMPercentage
+		*out = new(int32)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagementClusterConnectionSpec.
-func (in *ManagementClusterConnectionSpec) DeepCopy() *ManagementClusterConnectionSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JVMHeap.
+func (in *JVMHeap) DeepCopy() *JVMHeap {
 	if in == nil {
 		return nil
 	}
-	out := new(ManagementClusterConnectionSpec)
+	out := new(JVMHeap)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ManagementClusterList) DeepCopyInto(out *ManagementClusterList) {
+func (in *KibanaSavedObjectsSource) DeepCopyInto(out *KibanaSavedObjectsSource) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]ManagementCluster, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KibanaSavedObjectsSource.
+func (in *KibanaSavedObjectsSource) DeepCopy() *KibanaSavedObjectsSource {
+	if in == nil {
+		return nil
+	}
+	out := new(KibanaSavedObjectsSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KibanaSpace) DeepCopyInto(out *KibanaSpace) {
+	*out = *in
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagementClusterList.
-func (in *ManagementClusterList) DeepCopy() *ManagementClusterList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KibanaSpace.
+func (in *KibanaSpace) DeepCopy() *KibanaSpace {
 	if in == nil {
 		return nil
 	}
-	out := new(ManagementClusterList)
+	out := new(KibanaSpace)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ManagementClusterList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KibanaSpec) DeepCopyInto(out *KibanaSpec) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
 	}
-	return nil
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExtraConfig != nil {
+		in, out := &in.ExtraConfig, &out.ExtraConfig
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ElasticsearchHosts != nil {
+		in, out := &in.ElasticsearchHosts, &out.ElasticsearchHosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PodMetadata != nil {
+		in, out := &in.PodMetadata, &out.PodMetadata
+		*out = new(Metadata)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(PodDisruptionBudget)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SavedObjects != nil {
+		in, out := &in.SavedObjects, &out.SavedObjects
+		*out = make([]KibanaSavedObjectsSource, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KibanaSpec.
+func (in *KibanaSpec) DeepCopy() *KibanaSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KibanaSpec)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ManagementClusterSpec) DeepCopyInto(out *ManagementClusterSpec) {
+func (in *LogCollectionSpec) DeepCopyInto(out *LogCollectionSpec) {
 	*out = *in
-	if in.TLS != nil {
-		in, out := &in.TLS, &out.TLS
-		*out = new(TLS)
+	if in.CollectLogs != nil {
+		in, out := &in.CollectLogs, &out.CollectLogs
+		*out = new(LogCollectionStatusType)
+		**out = **in
+	}
+	if in.LogIntervalSeconds != nil {
+		in, out := &in.LogIntervalSeconds, &out.LogIntervalSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.LogRequestsPerInterval != nil {
+		in, out := &in.LogRequestsPerInterval, &out.LogRequestsPerInterval
+		*out = new(int64)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagementClusterSpec.
-func (in *ManagementClusterSpec) DeepCopy() *ManagementClusterSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogCollectionSpec.
+func (in *LogCollectionSpec) DeepCopy() *LogCollectionSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ManagementClusterSpec)
+	out := new(LogCollectionSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ManagementClusterTLS) DeepCopyInto(out *ManagementClusterTLS) {
+func (in *LogBuffer) DeepCopyInto(out *LogBuffer) {
 	*out = *in
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		x := (*in).DeepCopy()
+		*out = &x
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagementClusterTLS.
-func (in *ManagementClusterTLS) DeepCopy() *ManagementClusterTLS {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogBuffer.
+func (in *LogBuffer) DeepCopy() *LogBuffer {
 	if in == nil {
 		return nil
 	}
-	out := new(ManagementClusterTLS)
+	out := new(LogBuffer)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Manager) DeepCopyInto(out *Manager) {
+func (in *LogCollector) DeepCopyInto(out *LogCollector) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -2337,18 +2585,18 @@ func (in *Manager) DeepCopyInto(out *Manager) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Manager.
-func (in *Manager) DeepCopy() *Manager {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogCollector.
+func (in *LogCollector) DeepCopy() *LogCollector {
 	if in == nil {
 		return nil
 	}
-	out := new(Manager)
+	out := new(LogCollector)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Manager) DeepCopyObject() runtime.Object {
+func (in *LogCollector) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -2356,31 +2604,67 @@ func (in *Manager) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ManagerList) DeepCopyInto(out *ManagerList) {
+func (in *LogCollectorComponentDisruptionPolicy) DeepCopyInto(out *LogCollectorComponentDisruptionPolicy) {
+	*out = *in
+	in.DisruptionPolicy.DeepCopyInto(&out.DisruptionPolicy)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogCollectorComponentDisruptionPolicy.
+func (in *LogCollectorComponentDisruptionPolicy) DeepCopy() *LogCollectorComponentDisruptionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(LogCollectorComponentDisruptionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogCollectorComponentResource) DeepCopyInto(out *LogCollectorComponentResource) {
+	*out = *in
+	if in.ResourceRequirements != nil {
+		in, out := &in.ResourceRequirements, &out.ResourceRequirements
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogCollectorComponentResource.
+func (in *LogCollectorComponentResource) DeepCopy() *LogCollectorComponentResource {
+	if in == nil {
+		return nil
+	}
+	out := new(LogCollectorComponentResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogCollectorList) DeepCopyInto(out *LogCollectorList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]Manager, len(*in))
+		*out = make([]LogCollector, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagerList.
-func (in *ManagerList) DeepCopy() *ManagerList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogCollectorList.
+func (in *LogCollectorList) DeepCopy() *LogCollectorList {
 	if in == nil {
 		return nil
 	}
-	out := new(ManagerList)
+	out := new(LogCollectorList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ManagerList) DeepCopyObject() runtime.Object {
+func (in *LogCollectorList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -2388,393 +2672,1782 @@ func (in *ManagerList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ManagerSpec) DeepCopyInto(out *ManagerSpec) {
+func (in *LogCollectorSpec) DeepCopyInto(out *LogCollectorSpec) {
 	*out = *in
-	if in.Auth != nil {
-		in, out := &in.Auth, &out.Auth
-		*out = new(Auth)
+	if in.AdditionalStores != nil {
+		in, out := &in.AdditionalStores, &out.AdditionalStores
+		*out = new(AdditionalLogStoreSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AdditionalSources != nil {
+		in, out := &in.AdditionalSources, &out.AdditionalSources
+		*out = new(AdditionalLogSourceSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Candidate != nil {
+		in, out := &in.Candidate, &out.Candidate
+		*out = new(FluentdCandidate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CollectProcessPath != nil {
+		in, out := &in.CollectProcessPath, &out.CollectProcessPath
+		*out = new(CollectProcessPathOption)
 		**out = **in
 	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagerSpec.
-func (in *ManagerSpec) DeepCopy() *ManagerSpec {
-	if in == nil {
-		return nil
+	if in.ComponentDisruptionPolicies != nil {
+		in, out := &in.ComponentDisruptionPolicies, &out.ComponentDisruptionPolicies
+		*out = make([]LogCollectorComponentDisruptionPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	out := new(ManagerSpec)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ManagerStatus) DeepCopyInto(out *ManagerStatus) {
+	if in.ComponentResources != nil {
+		in, out := &in.ComponentResources, &out.ComponentResources
+		*out = make([]LogCollectorComponentResource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ProviderResourcePresets != nil {
+		in, out := &in.ProviderResourcePresets, &out.ProviderResourcePresets
+		*out = new(ProviderResourcePresetsOption)
+		**out = **in
+	}
+	if in.ContainerLogs != nil {
+		in, out := &in.ContainerLogs, &out.ContainerLogs
+		*out = new(ContainerLogs)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ControlPlaneNodes != nil {
+		in, out := &in.ControlPlaneNodes, &out.ControlPlaneNodes
+		*out = new(FluentdControlPlaneNodes)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DNSConfig != nil {
+		in, out := &in.DNSConfig, &out.DNSConfig
+		*out = new(corev1.PodDNSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ElasticsearchOutput != nil {
+		in, out := &in.ElasticsearchOutput, &out.ElasticsearchOutput
+		*out = new(FluentdElasticsearchOutput)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]corev1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.LogFileRotation != nil {
+		in, out := &in.LogFileRotation, &out.LogFileRotation
+		*out = new(LogFileRotation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodePools != nil {
+		in, out := &in.NodePools, &out.NodePools
+		*out = make([]FluentdNodePool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RedactionRules != nil {
+		in, out := &in.RedactionRules, &out.RedactionRules
+		*out = make([]LogRedactionRule, len(*in))
+		copy(*out, *in)
+	}
+	if in.RolloutWindow != nil {
+		in, out := &in.RolloutWindow, &out.RolloutWindow
+		*out = new(RolloutWindow)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TerminationGracePeriodSeconds != nil {
+		in, out := &in.TerminationGracePeriodSeconds, &out.TerminationGracePeriodSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.VerticalPodAutoscaling != nil {
+		in, out := &in.VerticalPodAutoscaling, &out.VerticalPodAutoscaling
+		*out = new(LogCollectorVerticalPodAutoscaling)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogCollectorSpec.
+func (in *LogCollectorSpec) DeepCopy() *LogCollectorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LogCollectorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogCollectorStatus) DeepCopyInto(out *LogCollectorStatus) {
+	*out = *in
+	if in.ManagedClusters != nil {
+		in, out := &in.ManagedClusters, &out.ManagedClusters
+		*out = make([]ManagedClusterLogCollectorStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogCollectorStatus.
+func (in *LogCollectorStatus) DeepCopy() *LogCollectorStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LogCollectorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogCollectorVerticalPodAutoscaling) DeepCopyInto(out *LogCollectorVerticalPodAutoscaling) {
+	*out = *in
+	if in.MinAllowedMemory != nil {
+		in, out := &in.MinAllowedMemory, &out.MinAllowedMemory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.MaxAllowedMemory != nil {
+		in, out := &in.MaxAllowedMemory, &out.MaxAllowedMemory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogCollectorVerticalPodAutoscaling.
+func (in *LogCollectorVerticalPodAutoscaling) DeepCopy() *LogCollectorVerticalPodAutoscaling {
+	if in == nil {
+		return nil
+	}
+	out := new(LogCollectorVerticalPodAutoscaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogFileRotation) DeepCopyInto(out *LogFileRotation) {
+	*out = *in
+	if in.MaxFileSizeMB != nil {
+		in, out := &in.MaxFileSizeMB, &out.MaxFileSizeMB
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxFiles != nil {
+		in, out := &in.MaxFiles, &out.MaxFiles
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogFileRotation.
+func (in *LogFileRotation) DeepCopy() *LogFileRotation {
+	if in == nil {
+		return nil
+	}
+	out := new(LogFileRotation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogRedactionRule) DeepCopyInto(out *LogRedactionRule) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogRedactionRule.
+func (in *LogRedactionRule) DeepCopy() *LogRedactionRule {
+	if in == nil {
+		return nil
+	}
+	out := new(LogRedactionRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogStorage) DeepCopyInto(out *LogStorage) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogStorage.
+func (in *LogStorage) DeepCopy() *LogStorage {
+	if in == nil {
+		return nil
+	}
+	out := new(LogStorage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LogStorage) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogStorageComponentDisruptionPolicy) DeepCopyInto(out *LogStorageComponentDisruptionPolicy) {
+	*out = *in
+	in.DisruptionPolicy.DeepCopyInto(&out.DisruptionPolicy)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogStorageComponentDisruptionPolicy.
+func (in *LogStorageComponentDisruptionPolicy) DeepCopy() *LogStorageComponentDisruptionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(LogStorageComponentDisruptionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogStorageComponentPriorityClass) DeepCopyInto(out *LogStorageComponentPriorityClass) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogStorageComponentPriorityClass.
+func (in *LogStorageComponentPriorityClass) DeepCopy() *LogStorageComponentPriorityClass {
+	if in == nil {
+		return nil
+	}
+	out := new(LogStorageComponentPriorityClass)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogStorageComponentResource) DeepCopyInto(out *LogStorageComponentResource) {
+	*out = *in
+	if in.ResourceRequirements != nil {
+		in, out := &in.ResourceRequirements, &out.ResourceRequirements
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogStorageComponentResource.
+func (in *LogStorageComponentResource) DeepCopy() *LogStorageComponentResource {
+	if in == nil {
+		return nil
+	}
+	out := new(LogStorageComponentResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogStorageList) DeepCopyInto(out *LogStorageList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LogStorage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogStorageList.
+func (in *LogStorageList) DeepCopy() *LogStorageList {
+	if in == nil {
+		return nil
+	}
+	out := new(LogStorageList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LogStorageList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogStorageMonitoring) DeepCopyInto(out *LogStorageMonitoring) {
+	*out = *in
+	if in.Exporter != nil {
+		in, out := &in.Exporter, &out.Exporter
+		*out = new(LogStorageMonitoringOption)
+		**out = **in
+	}
+	if in.Alerts != nil {
+		in, out := &in.Alerts, &out.Alerts
+		*out = new(LogStorageMonitoringOption)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogStorageMonitoring.
+func (in *LogStorageMonitoring) DeepCopy() *LogStorageMonitoring {
+	if in == nil {
+		return nil
+	}
+	out := new(LogStorageMonitoring)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogStoragePorts) DeepCopyInto(out *LogStoragePorts) {
+	*out = *in
+	if in.ElasticsearchHTTP != nil {
+		in, out := &in.ElasticsearchHTTP, &out.ElasticsearchHTTP
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Kibana != nil {
+		in, out := &in.Kibana, &out.Kibana
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogStoragePorts.
+func (in *LogStoragePorts) DeepCopy() *LogStoragePorts {
+	if in == nil {
+		return nil
+	}
+	out := new(LogStoragePorts)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogStorageSpec) DeepCopyInto(out *LogStorageSpec) {
+	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = new(Nodes)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Indices != nil {
+		in, out := &in.Indices, &out.Indices
+		*out = new(Indices)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.IndexTemplates != nil {
+		in, out := &in.IndexTemplates, &out.IndexTemplates
+		*out = new(IndexTemplates)
+		**out = **in
+	}
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(Retention)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DataNodeSelector != nil {
+		in, out := &in.DataNodeSelector, &out.DataNodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ComponentDisruptionPolicies != nil {
+		in, out := &in.ComponentDisruptionPolicies, &out.ComponentDisruptionPolicies
+		*out = make([]LogStorageComponentDisruptionPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ComponentResources != nil {
+		in, out := &in.ComponentResources, &out.ComponentResources
+		*out = make([]LogStorageComponentResource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ProviderResourcePresets != nil {
+		in, out := &in.ProviderResourcePresets, &out.ProviderResourcePresets
+		*out = new(ProviderResourcePresetsOption)
+		**out = **in
+	}
+	if in.DefaultPriorityClass != nil {
+		in, out := &in.DefaultPriorityClass, &out.DefaultPriorityClass
+		*out = new(DefaultPriorityClassOption)
+		**out = **in
+	}
+	if in.ComponentPriorityClasses != nil {
+		in, out := &in.ComponentPriorityClasses, &out.ComponentPriorityClasses
+		*out = make([]LogStorageComponentPriorityClass, len(*in))
+		copy(*out, *in)
+	}
+	if in.AdminUserRotation != nil {
+		in, out := &in.AdminUserRotation, &out.AdminUserRotation
+		*out = new(ESUserRotation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ComponentUserRotation != nil {
+		in, out := &in.ComponentUserRotation, &out.ComponentUserRotation
+		*out = new(ESUserRotation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RemoteClusters != nil {
+		in, out := &in.RemoteClusters, &out.RemoteClusters
+		*out = make([]RemoteElasticsearchCluster, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ESGateway != nil {
+		in, out := &in.ESGateway, &out.ESGateway
+		*out = new(ESGatewaySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UpgradePreflight != nil {
+		in, out := &in.UpgradePreflight, &out.UpgradePreflight
+		*out = new(UpgradePreflight)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DataLossAcknowledged != nil {
+		in, out := &in.DataLossAcknowledged, &out.DataLossAcknowledged
+		*out = new(int64)
+		**out = **in
+	}
+	if in.TenantRoles != nil {
+		in, out := &in.TenantRoles, &out.TenantRoles
+		*out = make([]TenantRole, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.KibanaSpaces != nil {
+		in, out := &in.KibanaSpaces, &out.KibanaSpaces
+		*out = make([]KibanaSpace, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Tenancy != nil {
+		in, out := &in.Tenancy, &out.Tenancy
+		*out = new(LogStorageTenancy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.IngestionLatency != nil {
+		in, out := &in.IngestionLatency, &out.IngestionLatency
+		*out = new(IngestionLatency)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StorageEstimation != nil {
+		in, out := &in.StorageEstimation, &out.StorageEstimation
+		*out = new(StorageEstimation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = new(LogStoragePorts)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CuratorBackpressure != nil {
+		in, out := &in.CuratorBackpressure, &out.CuratorBackpressure
+		*out = new(CuratorBackpressure)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Curator != nil {
+		in, out := &in.Curator, &out.Curator
+		*out = new(CuratorOption)
+		**out = **in
+	}
+	if in.Snapshots != nil {
+		in, out := &in.Snapshots, &out.Snapshots
+		*out = new(Snapshots)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecureSettings != nil {
+		in, out := &in.SecureSettings, &out.SecureSettings
+		*out = make([]SecureSettingsSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Kibana != nil {
+		in, out := &in.Kibana, &out.Kibana
+		*out = new(KibanaSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ECKOperator != nil {
+		in, out := &in.ECKOperator, &out.ECKOperator
+		*out = new(ECKOperatorSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DNSConfig != nil {
+		in, out := &in.DNSConfig, &out.DNSConfig
+		*out = new(corev1.PodDNSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]corev1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.RolloutWindow != nil {
+		in, out := &in.RolloutWindow, &out.RolloutWindow
+		*out = new(RolloutWindow)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Monitoring != nil {
+		in, out := &in.Monitoring, &out.Monitoring
+		*out = new(LogStorageMonitoring)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TransportTLS != nil {
+		in, out := &in.TransportTLS, &out.TransportTLS
+		*out = new(ElasticsearchTransportTLS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Verification != nil {
+		in, out := &in.Verification, &out.Verification
+		*out = new(LogStorageVerification)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ArchiveRestores != nil {
+		in, out := &in.ArchiveRestores, &out.ArchiveRestores
+		*out = make([]ArchiveRestore, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EnterpriseLicense != nil {
+		in, out := &in.EnterpriseLicense, &out.EnterpriseLicense
+		*out = new(ElasticEnterpriseLicense)
+		**out = **in
+	}
+	if in.ReadOnlyAccess != nil {
+		in, out := &in.ReadOnlyAccess, &out.ReadOnlyAccess
+		*out = make([]ReadOnlyAccessGrant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogStorageSpec.
+func (in *LogStorageSpec) DeepCopy() *LogStorageSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LogStorageSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogStorageStatus) DeepCopyInto(out *LogStorageStatus) {
+	*out = *in
+	if in.LastAdminUserRotation != nil {
+		in, out := &in.LastAdminUserRotation, &out.LastAdminUserRotation
+		*out = (*in).DeepCopy()
+	}
+	if in.LastComponentUserRotation != nil {
+		in, out := &in.LastComponentUserRotation, &out.LastComponentUserRotation
+		*out = (*in).DeepCopy()
+	}
+	if in.RotatedComponentUserSecretUIDs != nil {
+		in, out := &in.RotatedComponentUserSecretUIDs, &out.RotatedComponentUserSecretUIDs
+		*out = make([]types.UID, len(*in))
+		copy(*out, *in)
+	}
+	if in.StorageEstimate != nil {
+		in, out := &in.StorageEstimate, &out.StorageEstimate
+		*out = new(StorageEstimate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ArchiveRestores != nil {
+		in, out := &in.ArchiveRestores, &out.ArchiveRestores
+		*out = make([]ArchiveRestoreStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LicenseExpiry != nil {
+		in, out := &in.LicenseExpiry, &out.LicenseExpiry
+		*out = (*in).DeepCopy()
+	}
+	if in.ReadOnlyAccess != nil {
+		in, out := &in.ReadOnlyAccess, &out.ReadOnlyAccess
+		*out = make([]ReadOnlyAccessStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogStorageStatus.
+func (in *LogStorageStatus) DeepCopy() *LogStorageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LogStorageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogStorageTenancy) DeepCopyInto(out *LogStorageTenancy) {
+	*out = *in
+	if in.Mode != nil {
+		in, out := &in.Mode, &out.Mode
+		*out = new(TenancyMode)
+		**out = **in
+	}
+	if in.Tenants != nil {
+		in, out := &in.Tenants, &out.Tenants
+		*out = make([]LogStorageTenant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogStorageTenancy.
+func (in *LogStorageTenancy) DeepCopy() *LogStorageTenancy {
+	if in == nil {
+		return nil
+	}
+	out := new(LogStorageTenancy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogStorageTenant) DeepCopyInto(out *LogStorageTenant) {
+	*out = *in
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogStorageTenant.
+func (in *LogStorageTenant) DeepCopy() *LogStorageTenant {
+	if in == nil {
+		return nil
+	}
+	out := new(LogStorageTenant)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogStorageVerification) DeepCopyInto(out *LogStorageVerification) {
+	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogStorageVerification.
+func (in *LogStorageVerification) DeepCopy() *LogStorageVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(LogStorageVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagedClusterLogCollectorStatus) DeepCopyInto(out *ManagedClusterLogCollectorStatus) {
+	*out = *in
+	if in.LastFlowLogTime != nil {
+		in, out := &in.LastFlowLogTime, &out.LastFlowLogTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedClusterLogCollectorStatus.
+func (in *ManagedClusterLogCollectorStatus) DeepCopy() *ManagedClusterLogCollectorStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagedClusterLogCollectorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagementCluster) DeepCopyInto(out *ManagementCluster) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagementCluster.
+func (in *ManagementCluster) DeepCopy() *ManagementCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagementCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ManagementCluster) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagementClusterConnection) DeepCopyInto(out *ManagementClusterConnection) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagementClusterConnection.
+func (in *ManagementClusterConnection) DeepCopy() *ManagementClusterConnection {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagementClusterConnection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ManagementClusterConnection) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagementClusterConnectionList) DeepCopyInto(out *ManagementClusterConnectionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ManagementClusterConnection, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagementClusterConnectionList.
+func (in *ManagementClusterConnectionList) DeepCopy() *ManagementClusterConnectionList {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagementClusterConnectionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ManagementClusterConnectionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagementClusterConnectionSpec) DeepCopyInto(out *ManagementClusterConnectionSpec) {
+	*out = *in
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(ManagementClusterTLS)
+		**out = **in
+	}
+	if in.LogBuffer != nil {
+		in, out := &in.LogBuffer, &out.LogBuffer
+		*out = new(LogBuffer)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagementClusterConnectionSpec.
+func (in *ManagementClusterConnectionSpec) DeepCopy() *ManagementClusterConnectionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagementClusterConnectionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagementClusterList) DeepCopyInto(out *ManagementClusterList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ManagementCluster, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagementClusterList.
+func (in *ManagementClusterList) DeepCopy() *ManagementClusterList {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagementClusterList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ManagementClusterList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagementClusterSpec) DeepCopyInto(out *ManagementClusterSpec) {
+	*out = *in
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(TLS)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagementClusterSpec.
+func (in *ManagementClusterSpec) DeepCopy() *ManagementClusterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagementClusterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagementClusterTLS) DeepCopyInto(out *ManagementClusterTLS) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagementClusterTLS.
+func (in *ManagementClusterTLS) DeepCopy() *ManagementClusterTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagementClusterTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Manager) DeepCopyInto(out *Manager) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Manager.
+func (in *Manager) DeepCopy() *Manager {
+	if in == nil {
+		return nil
+	}
+	out := new(Manager)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Manager) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagerList) DeepCopyInto(out *ManagerList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Manager, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagerList.
+func (in *ManagerList) DeepCopy() *ManagerList {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagerList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ManagerList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagerSpec) DeepCopyInto(out *ManagerSpec) {
+	*out = *in
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(Auth)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagerSpec.
+func (in *ManagerSpec) DeepCopy() *ManagerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagerStatus) DeepCopyInto(out *ManagerStatus) {
+	*out = *in
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(Auth)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagerStatus.
+func (in *ManagerStatus) DeepCopy() *ManagerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Metadata) DeepCopyInto(out *Metadata) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Metadata.
+func (in *Metadata) DeepCopy() *Metadata {
+	if in == nil {
+		return nil
+	}
+	out := new(Metadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Monitor) DeepCopyInto(out *Monitor) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Monitor.
+func (in *Monitor) DeepCopy() *Monitor {
+	if in == nil {
+		return nil
+	}
+	out := new(Monitor)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Monitor) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitorList) DeepCopyInto(out *MonitorList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Monitor, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitorList.
+func (in *MonitorList) DeepCopy() *MonitorList {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitorList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MonitorList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitorSpec) DeepCopyInto(out *MonitorSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitorSpec.
+func (in *MonitorSpec) DeepCopy() *MonitorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitorStatus) DeepCopyInto(out *MonitorStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitorStatus.
+func (in *MonitorStatus) DeepCopy() *MonitorStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeAddressAutodetection) DeepCopyInto(out *NodeAddressAutodetection) {
+	*out = *in
+	if in.FirstFound != nil {
+		in, out := &in.FirstFound, &out.FirstFound
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Kubernetes != nil {
+		in, out := &in.Kubernetes, &out.Kubernetes
+		*out = new(KubernetesAutodetectionMethod)
+		**out = **in
+	}
+	if in.CIDRS != nil {
+		in, out := &in.CIDRS, &out.CIDRS
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeAddressAutodetection.
+func (in *NodeAddressAutodetection) DeepCopy() *NodeAddressAutodetection {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeAddressAutodetection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeAffinity) DeepCopyInto(out *NodeAffinity) {
+	*out = *in
+	if in.PreferredDuringSchedulingIgnoredDuringExecution != nil {
+		in, out := &in.PreferredDuringSchedulingIgnoredDuringExecution, &out.PreferredDuringSchedulingIgnoredDuringExecution
+		*out = make([]corev1.PreferredSchedulingTerm, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+		in, out := &in.RequiredDuringSchedulingIgnoredDuringExecution, &out.RequiredDuringSchedulingIgnoredDuringExecution
+		*out = new(corev1.NodeSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeAffinity.
+func (in *NodeAffinity) DeepCopy() *NodeAffinity {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeAffinity)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeSet) DeepCopyInto(out *NodeSet) {
+	*out = *in
+	if in.SelectionAttributes != nil {
+		in, out := &in.SelectionAttributes, &out.SelectionAttributes
+		*out = make([]NodeSetSelectionAttribute, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(corev1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodTemplatePatch != nil {
+		in, out := &in.PodTemplatePatch, &out.PodTemplatePatch
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StorageSize != nil {
+		in, out := &in.StorageSize, &out.StorageSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeSet.
+func (in *NodeSet) DeepCopy() *NodeSet {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeSet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeSetSelectionAttribute) DeepCopyInto(out *NodeSetSelectionAttribute) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeSetSelectionAttribute.
+func (in *NodeSetSelectionAttribute) DeepCopy() *NodeSetSelectionAttribute {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeSetSelectionAttribute)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Nodes) DeepCopyInto(out *Nodes) {
+	*out = *in
+	if in.NodeSets != nil {
+		in, out := &in.NodeSets, &out.NodeSets
+		*out = make([]NodeSet, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ResourceRequirements != nil {
+		in, out := &in.ResourceRequirements, &out.ResourceRequirements
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.JVMHeap != nil {
+		in, out := &in.JVMHeap, &out.JVMHeap
+		*out = new(JVMHeap)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DiskWatermarks != nil {
+		in, out := &in.DiskWatermarks, &out.DiskWatermarks
+		*out = new(DiskWatermarks)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(PodDisruptionBudget)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExtraConfig != nil {
+		in, out := &in.ExtraConfig, &out.ExtraConfig
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.PodMetadata != nil {
+		in, out := &in.PodMetadata, &out.PodMetadata
+		*out = new(Metadata)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Nodes.
+func (in *Nodes) DeepCopy() *Nodes {
+	if in == nil {
+		return nil
+	}
+	out := new(Nodes)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodDisruptionBudget) DeepCopyInto(out *PodDisruptionBudget) {
+	*out = *in
+	if in.MinAvailable != nil {
+		in, out := &in.MinAvailable, &out.MinAvailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodDisruptionBudget.
+func (in *PodDisruptionBudget) DeepCopy() *PodDisruptionBudget {
+	if in == nil {
+		return nil
+	}
+	out := new(PodDisruptionBudget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReadOnlyAccessGrant) DeepCopyInto(out *ReadOnlyAccessGrant) {
+	*out = *in
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReadOnlyAccessGrant.
+func (in *ReadOnlyAccessGrant) DeepCopy() *ReadOnlyAccessGrant {
+	if in == nil {
+		return nil
+	}
+	out := new(ReadOnlyAccessGrant)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReadOnlyAccessStatus) DeepCopyInto(out *ReadOnlyAccessStatus) {
+	*out = *in
+	if in.CreationTime != nil {
+		in, out := &in.CreationTime, &out.CreationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ExpirationTime != nil {
+		in, out := &in.ExpirationTime, &out.ExpirationTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReadOnlyAccessStatus.
+func (in *ReadOnlyAccessStatus) DeepCopy() *ReadOnlyAccessStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReadOnlyAccessStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemoteElasticsearchCluster) DeepCopyInto(out *RemoteElasticsearchCluster) {
+	*out = *in
+	if in.Seeds != nil {
+		in, out := &in.Seeds, &out.Seeds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteElasticsearchCluster.
+func (in *RemoteElasticsearchCluster) DeepCopy() *RemoteElasticsearchCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(RemoteElasticsearchCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RenderedComponent) DeepCopyInto(out *RenderedComponent) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RenderedComponent.
+func (in *RenderedComponent) DeepCopy() *RenderedComponent {
+	if in == nil {
+		return nil
+	}
+	out := new(RenderedComponent)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Retention) DeepCopyInto(out *Retention) {
+	*out = *in
+	if in.Flows != nil {
+		in, out := &in.Flows, &out.Flows
+		*out = new(int32)
+		**out = **in
+	}
+	if in.AuditReports != nil {
+		in, out := &in.AuditReports, &out.AuditReports
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Snapshots != nil {
+		in, out := &in.Snapshots, &out.Snapshots
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ComplianceReports != nil {
+		in, out := &in.ComplianceReports, &out.ComplianceReports
+		*out = new(int32)
+		**out = **in
+	}
+	if in.DNSLogs != nil {
+		in, out := &in.DNSLogs, &out.DNSLogs
+		*out = new(int32)
+		**out = **in
+	}
+	if in.BGPLogs != nil {
+		in, out := &in.BGPLogs, &out.BGPLogs
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = make([]RetentionLimit, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Retention.
+func (in *Retention) DeepCopy() *Retention {
+	if in == nil {
+		return nil
+	}
+	out := new(Retention)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetentionLimit) DeepCopyInto(out *RetentionLimit) {
 	*out = *in
-	if in.Auth != nil {
-		in, out := &in.Auth, &out.Auth
-		*out = new(Auth)
+	if in.MaxDocs != nil {
+		in, out := &in.MaxDocs, &out.MaxDocs
+		*out = new(int64)
 		**out = **in
 	}
+	if in.MaxSize != nil {
+		in, out := &in.MaxSize, &out.MaxSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagerStatus.
-func (in *ManagerStatus) DeepCopy() *ManagerStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetentionLimit.
+func (in *RetentionLimit) DeepCopy() *RetentionLimit {
 	if in == nil {
 		return nil
 	}
-	out := new(ManagerStatus)
+	out := new(RetentionLimit)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Metadata) DeepCopyInto(out *Metadata) {
+func (in *RolloutWindow) DeepCopyInto(out *RolloutWindow) {
 	*out = *in
-	if in.Labels != nil {
-		in, out := &in.Labels, &out.Labels
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.Annotations != nil {
-		in, out := &in.Annotations, &out.Annotations
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(metav1.Duration)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Metadata.
-func (in *Metadata) DeepCopy() *Metadata {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutWindow.
+func (in *RolloutWindow) DeepCopy() *RolloutWindow {
 	if in == nil {
 		return nil
 	}
-	out := new(Metadata)
+	out := new(RolloutWindow)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Monitor) DeepCopyInto(out *Monitor) {
+func (in *S3StoreSpec) DeepCopyInto(out *S3StoreSpec) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Monitor.
-func (in *Monitor) DeepCopy() *Monitor {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3StoreSpec.
+func (in *S3StoreSpec) DeepCopy() *S3StoreSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(Monitor)
+	out := new(S3StoreSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Monitor) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MonitorList) DeepCopyInto(out *MonitorList) {
+func (in *SecureSettingsEntry) DeepCopyInto(out *SecureSettingsEntry) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]Monitor, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitorList.
-func (in *MonitorList) DeepCopy() *MonitorList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecureSettingsEntry.
+func (in *SecureSettingsEntry) DeepCopy() *SecureSettingsEntry {
 	if in == nil {
 		return nil
 	}
-	out := new(MonitorList)
+	out := new(SecureSettingsEntry)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *MonitorList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MonitorSpec) DeepCopyInto(out *MonitorSpec) {
+func (in *SecureSettingsSource) DeepCopyInto(out *SecureSettingsSource) {
 	*out = *in
+	if in.Entries != nil {
+		in, out := &in.Entries, &out.Entries
+		*out = make([]SecureSettingsEntry, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitorSpec.
-func (in *MonitorSpec) DeepCopy() *MonitorSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecureSettingsSource.
+func (in *SecureSettingsSource) DeepCopy() *SecureSettingsSource {
 	if in == nil {
 		return nil
 	}
-	out := new(MonitorSpec)
+	out := new(SecureSettingsSource)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MonitorStatus) DeepCopyInto(out *MonitorStatus) {
+func (in *SnapshotRepository) DeepCopyInto(out *SnapshotRepository) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitorStatus.
-func (in *MonitorStatus) DeepCopy() *MonitorStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotRepository.
+func (in *SnapshotRepository) DeepCopy() *SnapshotRepository {
 	if in == nil {
 		return nil
 	}
-	out := new(MonitorStatus)
+	out := new(SnapshotRepository)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NodeAddressAutodetection) DeepCopyInto(out *NodeAddressAutodetection) {
+func (in *SnapshotRetention) DeepCopyInto(out *SnapshotRetention) {
 	*out = *in
-	if in.FirstFound != nil {
-		in, out := &in.FirstFound, &out.FirstFound
-		*out = new(bool)
+	if in.ExpireAfter != nil {
+		in, out := &in.ExpireAfter, &out.ExpireAfter
+		*out = new(metav1.Duration)
 		**out = **in
 	}
-	if in.Kubernetes != nil {
-		in, out := &in.Kubernetes, &out.Kubernetes
-		*out = new(KubernetesAutodetectionMethod)
+	if in.MinCount != nil {
+		in, out := &in.MinCount, &out.MinCount
+		*out = new(int32)
 		**out = **in
 	}
-	if in.CIDRS != nil {
-		in, out := &in.CIDRS, &out.CIDRS
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	if in.MaxCount != nil {
+		in, out := &in.MaxCount, &out.MaxCount
+		*out = new(int32)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeAddressAutodetection.
-func (in *NodeAddressAutodetection) DeepCopy() *NodeAddressAutodetection {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotRetention.
+func (in *SnapshotRetention) DeepCopy() *SnapshotRetention {
 	if in == nil {
 		return nil
 	}
-	out := new(NodeAddressAutodetection)
+	out := new(SnapshotRetention)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NodeAffinity) DeepCopyInto(out *NodeAffinity) {
+func (in *Snapshots) DeepCopyInto(out *Snapshots) {
 	*out = *in
-	if in.PreferredDuringSchedulingIgnoredDuringExecution != nil {
-		in, out := &in.PreferredDuringSchedulingIgnoredDuringExecution, &out.PreferredDuringSchedulingIgnoredDuringExecution
-		*out = make([]corev1.PreferredSchedulingTerm, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.RequiredDuringSchedulingIgnoredDuringExecution != nil {
-		in, out := &in.RequiredDuringSchedulingIgnoredDuringExecution, &out.RequiredDuringSchedulingIgnoredDuringExecution
-		*out = new(corev1.NodeSelector)
+	out.Repository = in.Repository
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(SnapshotRetention)
 		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeAffinity.
-func (in *NodeAffinity) DeepCopy() *NodeAffinity {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Snapshots.
+func (in *Snapshots) DeepCopy() *Snapshots {
 	if in == nil {
 		return nil
 	}
-	out := new(NodeAffinity)
+	out := new(Snapshots)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NodeSet) DeepCopyInto(out *NodeSet) {
+func (in *SplunkStoreSpec) DeepCopyInto(out *SplunkStoreSpec) {
 	*out = *in
-	if in.SelectionAttributes != nil {
-		in, out := &in.SelectionAttributes, &out.SelectionAttributes
-		*out = make([]NodeSetSelectionAttribute, len(*in))
-		copy(*out, *in)
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(AdditionalStoreTLS)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeSet.
-func (in *NodeSet) DeepCopy() *NodeSet {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SplunkStoreSpec.
+func (in *SplunkStoreSpec) DeepCopy() *SplunkStoreSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(NodeSet)
+	out := new(SplunkStoreSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NodeSetSelectionAttribute) DeepCopyInto(out *NodeSetSelectionAttribute) {
+func (in *StorageEstimate) DeepCopyInto(out *StorageEstimate) {
 	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	out.Capacity = in.Capacity.DeepCopy()
+	out.Used = in.Used.DeepCopy()
+	out.IngestedPerDay = in.IngestedPerDay.DeepCopy()
+	out.Retained = in.Retained.DeepCopy()
+	if in.DaysUntilFull != nil {
+		in, out := &in.DaysUntilFull, &out.DaysUntilFull
+		*out = new(int32)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeSetSelectionAttribute.
-func (in *NodeSetSelectionAttribute) DeepCopy() *NodeSetSelectionAttribute {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageEstimate.
+func (in *StorageEstimate) DeepCopy() *StorageEstimate {
 	if in == nil {
 		return nil
 	}
-	out := new(NodeSetSelectionAttribute)
+	out := new(StorageEstimate)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Nodes) DeepCopyInto(out *Nodes) {
+func (in *StorageEstimation) DeepCopyInto(out *StorageEstimation) {
 	*out = *in
-	if in.NodeSets != nil {
-		in, out := &in.NodeSets, &out.NodeSets
-		*out = make([]NodeSet, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.ResourceRequirements != nil {
-		in, out := &in.ResourceRequirements, &out.ResourceRequirements
-		*out = new(corev1.ResourceRequirements)
-		(*in).DeepCopyInto(*out)
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(metav1.Duration)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Nodes.
-func (in *Nodes) DeepCopy() *Nodes {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageEstimation.
+func (in *StorageEstimation) DeepCopy() *StorageEstimation {
 	if in == nil {
 		return nil
 	}
-	out := new(Nodes)
+	out := new(StorageEstimation)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Retention) DeepCopyInto(out *Retention) {
+func (in *SyslogStoreSpec) DeepCopyInto(out *SyslogStoreSpec) {
 	*out = *in
-	if in.Flows != nil {
-		in, out := &in.Flows, &out.Flows
-		*out = new(int32)
-		**out = **in
-	}
-	if in.AuditReports != nil {
-		in, out := &in.AuditReports, &out.AuditReports
-		*out = new(int32)
-		**out = **in
-	}
-	if in.Snapshots != nil {
-		in, out := &in.Snapshots, &out.Snapshots
-		*out = new(int32)
-		**out = **in
-	}
-	if in.ComplianceReports != nil {
-		in, out := &in.ComplianceReports, &out.ComplianceReports
-		*out = new(int32)
-		**out = **in
-	}
-	if in.DNSLogs != nil {
-		in, out := &in.DNSLogs, &out.DNSLogs
+	if in.PacketSize != nil {
+		in, out := &in.PacketSize, &out.PacketSize
 		*out = new(int32)
 		**out = **in
 	}
-	if in.BGPLogs != nil {
-		in, out := &in.BGPLogs, &out.BGPLogs
-		*out = new(int32)
-		**out = **in
+	if in.LogTypes != nil {
+		in, out := &in.LogTypes, &out.LogTypes
+		*out = make([]SyslogLogType, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Retention.
-func (in *Retention) DeepCopy() *Retention {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyslogStoreSpec.
+func (in *SyslogStoreSpec) DeepCopy() *SyslogStoreSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(Retention)
+	out := new(SyslogStoreSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *S3StoreSpec) DeepCopyInto(out *S3StoreSpec) {
+func (in *TLS) DeepCopyInto(out *TLS) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3StoreSpec.
-func (in *S3StoreSpec) DeepCopy() *S3StoreSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLS.
+func (in *TLS) DeepCopy() *TLS {
 	if in == nil {
 		return nil
 	}
-	out := new(S3StoreSpec)
+	out := new(TLS)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SplunkStoreSpec) DeepCopyInto(out *SplunkStoreSpec) {
+func (in *TenantDocumentFilter) DeepCopyInto(out *TenantDocumentFilter) {
 	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SplunkStoreSpec.
-func (in *SplunkStoreSpec) DeepCopy() *SplunkStoreSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantDocumentFilter.
+func (in *TenantDocumentFilter) DeepCopy() *TenantDocumentFilter {
 	if in == nil {
 		return nil
 	}
-	out := new(SplunkStoreSpec)
+	out := new(TenantDocumentFilter)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SyslogStoreSpec) DeepCopyInto(out *SyslogStoreSpec) {
+func (in *TenantRole) DeepCopyInto(out *TenantRole) {
 	*out = *in
-	if in.PacketSize != nil {
-		in, out := &in.PacketSize, &out.PacketSize
-		*out = new(int32)
-		**out = **in
-	}
-	if in.LogTypes != nil {
-		in, out := &in.LogTypes, &out.LogTypes
-		*out = make([]SyslogLogType, len(*in))
-		copy(*out, *in)
+	if in.Indices != nil {
+		in, out := &in.Indices, &out.Indices
+		*out = make([]TenantRoleIndices, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyslogStoreSpec.
-func (in *SyslogStoreSpec) DeepCopy() *SyslogStoreSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantRole.
+func (in *TenantRole) DeepCopy() *TenantRole {
 	if in == nil {
 		return nil
 	}
-	out := new(SyslogStoreSpec)
+	out := new(TenantRole)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TLS) DeepCopyInto(out *TLS) {
+func (in *TenantRoleIndices) DeepCopyInto(out *TenantRoleIndices) {
 	*out = *in
+	if in.Names != nil {
+		in, out := &in.Names, &out.Names
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DocumentFilters != nil {
+		in, out := &in.DocumentFilters, &out.DocumentFilters
+		*out = make([]TenantDocumentFilter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.GrantedFields != nil {
+		in, out := &in.GrantedFields, &out.GrantedFields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DeniedFields != nil {
+		in, out := &in.DeniedFields, &out.DeniedFields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLS.
-func (in *TLS) DeepCopy() *TLS {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantRoleIndices.
+func (in *TenantRoleIndices) DeepCopy() *TenantRoleIndices {
 	if in == nil {
 		return nil
 	}
-	out := new(TLS)
+	out := new(TenantRoleIndices)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -2879,6 +4552,11 @@ func (in *TigeraStatusStatus) DeepCopyInto(out *TigeraStatusStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.RenderedComponents != nil {
+		in, out := &in.RenderedComponents, &out.RenderedComponents
+		*out = make([]RenderedComponent, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TigeraStatusStatus.
@@ -3074,6 +4752,31 @@ func (in *TyphaDeploymentSpec) DeepCopy() *TyphaDeploymentSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradePreflight) DeepCopyInto(out *UpgradePreflight) {
+	*out = *in
+	if in.MaxDiskUsagePercent != nil {
+		in, out := &in.MaxDiskUsagePercent, &out.MaxDiskUsagePercent
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxSnapshotAge != nil {
+		in, out := &in.MaxSnapshotAge, &out.MaxSnapshotAge
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradePreflight.
+func (in *UpgradePreflight) DeepCopy() *UpgradePreflight {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradePreflight)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UserMatch) DeepCopyInto(out *UserMatch) {
 	*out = *in
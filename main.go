@@ -18,12 +18,16 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"net/http"
 	"net/url"
 	"os"
 	goruntime "runtime"
 	"strings"
+	"time"
 
 	"github.com/tigera/operator/pkg/render/common/networkpolicy"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 
@@ -31,6 +35,7 @@ import (
 	"github.com/ghodss/yaml"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
@@ -38,6 +43,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
@@ -53,6 +59,8 @@ import (
 	"github.com/tigera/operator/pkg/controller/utils"
 	"github.com/tigera/operator/pkg/crds"
 	"github.com/tigera/operator/pkg/dns"
+	"github.com/tigera/operator/pkg/preflight"
+	"github.com/tigera/operator/pkg/render"
 	"github.com/tigera/operator/version"
 	// +kubebuilder:scaffold:imports
 )
@@ -91,6 +99,10 @@ func main() {
 	var printEnterpriseCRDs string
 	var sgSetup bool
 	var manageCRDs bool
+	var healthProbeAddr string
+	var enableTestLogGenerator bool
+	var maxConcurrentReconciles int
+	var validateLogPipeline bool
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", true,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
@@ -108,6 +120,14 @@ func main() {
 		"Setup Security Groups in AWS (should only be used on OpenShift).")
 	flag.BoolVar(&manageCRDs, "manage-crds", false,
 		"Operator should manage the projectcalico.org and operator.tigera.io CRDs.")
+	flag.StringVar(&healthProbeAddr, "health-probe-bind-address", "0",
+		"The address that the /healthz and /readyz endpoints bind to, e.g. :8081. /readyz lists the readiness of each controller with ?verbose. Disabled by default.")
+	flag.BoolVar(&enableTestLogGenerator, "enable-test-log-generator", false,
+		"Deploy a generator of flow and DNS logs on the nodes labeled with operator.tigera.io/test-log-generator=true. Only meant for demos and CI.")
+	flag.IntVar(&maxConcurrentReconciles, "max-concurrent-reconciles", 1,
+		"The number of reconciles that the logcollector and logstorage controllers run concurrently.")
+	flag.BoolVar(&validateLogPipeline, "validate-log-pipeline", false,
+		"Check the prerequisites of LogStorage and LogCollector (the reachability of the registries of their images, the StorageClasses of Elasticsearch and the APIs they need), print a report and exit. Exits with 1 if a check fails.")
 	opts := zap.Options{}
 	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
@@ -187,6 +207,21 @@ func main() {
 		os.Exit(1)
 	}
 
+	if validateLogPipeline {
+		vc, err := client.New(cfg, client.Options{Scheme: scheme})
+		if err != nil {
+			log.Error(err, "")
+			os.Exit(1)
+		}
+		validator := &preflight.LogPipelineValidator{Client: vc, Clientset: cs}
+		report := validator.Validate(ctx)
+		report.Print(os.Stdout)
+		if !report.Passed() {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	// Because we only run this as a job that is set up by the operator, it should not be
 	// launched except by an operator that is the active operator. So we do not need to
 	// check that we're the active operator before running the AWS SG setup.
@@ -206,11 +241,12 @@ func main() {
 	log.Info("Active operator: proceeding")
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-		Scheme:             scheme,
-		MetricsBindAddress: metricsAddr(),
-		Port:               9443,
-		LeaderElection:     enableLeaderElection,
-		LeaderElectionID:   "operator-lock",
+		Scheme:                 scheme,
+		MetricsBindAddress:     metricsAddr(),
+		HealthProbeBindAddress: healthProbeAddr,
+		Port:                   9443,
+		LeaderElection:         enableLeaderElection,
+		LeaderElectionID:       "operator-lock",
 		// We should test this again in the future to see if the problem with LicenseKey updates
 		// being missed is resolved. Prior to controller-runtime 0.7 we observed Test failures
 		// where LicenseKey updates would be missed and the client cache did not have the LicenseKey.
@@ -218,8 +254,12 @@ func main() {
 		// for the LicenseKey. We should test again in the future to see if the cache issue is fixed
 		// and we can remove this. Here is a link to the upstream issue
 		// https://github.com/kubernetes-sigs/controller-runtime/issues/1316
+		//
+		// Events are read from the API server as well, so that reading one doesn't start an informer on every event
+		// of the cluster.
 		ClientDisableCacheFor: []client.Object{
 			&v3.LicenseKey{},
+			&corev1.Event{},
 		},
 		// NetworkPolicy is served through the Tigera API Server, which currently restricts List and Watch
 		// operations on NetworkPolicy to a single tier only, specified via label or field selector. If no
@@ -228,9 +268,16 @@ func main() {
 		// within so that it can receive the expected resources for List and Watch. If the operator needs to
 		// reconcile policy within multiple tiers, the API Server should be updated to serve policy from all
 		// tiers that the user is authorized for.
+		//
+		// The operator only watches the warning events of the Elasticsearch namespace, so the cache doesn't hold every
+		// event of the cluster.
 		NewCache: cache.BuilderWithOptions(cache.Options{
 			SelectorsByObject: cache.SelectorsByObject{
 				&v3.NetworkPolicy{}: {Label: policySelector},
+				&corev1.Event{}: {Field: fields.AndSelectors(
+					fields.OneTermEqualSelector("metadata.namespace", render.ElasticsearchNamespace),
+					fields.OneTermEqualSelector("type", corev1.EventTypeWarning),
+				)},
 			},
 		}),
 	})
@@ -253,6 +300,24 @@ func main() {
 	}
 	setupLog.WithValues("provider", provider).Info("Checking type of cluster")
 
+	// Attempt to discover the infrastructure provider of clusters provisioned by Cluster API. The discovery is retried,
+	// and if the nodes still can't be listed the operator runs without the defaults of the infrastructure provider.
+	var infrastructureProvider common.InfrastructureProvider
+	err = wait.PollImmediate(5*time.Second, 1*time.Minute, func() (bool, error) {
+		var discoverErr error
+		infrastructureProvider, discoverErr = utils.AutoDiscoverInfrastructureProvider(ctx, clientset)
+		if discoverErr != nil {
+			setupLog.Error(discoverErr, "Auto discovery of the infrastructure provider failed, retrying")
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		setupLog.Error(err, "Auto discovery of the infrastructure provider failed, continuing without the defaults of the infrastructure provider")
+		infrastructureProvider = common.InfrastructureProviderNone
+	}
+	setupLog.WithValues("infrastructureProvider", infrastructureProvider).Info("Checking the Cluster API infrastructure provider")
+
 	// Determine if PodSecurityPolicies are supported. PSPs were removed in
 	// Kubernetes v1.25. We can remove this check once the operator not longer
 	// supports Kubernetes < v1.25.0.
@@ -263,6 +328,15 @@ func main() {
 	}
 	setupLog.WithValues("supported", usePSP).Info("Checking if PodSecurityPolicies are supported by the cluster")
 
+	// Determine if CronJobs are served from batch/v1. The batch/v1beta1 CronJobs were removed in Kubernetes v1.25. We
+	// can remove this check once the operator no longer supports Kubernetes < v1.21.0.
+	useBatchV1CronJobs, err := utils.SupportsBatchV1CronJobs(clientset)
+	if err != nil {
+		setupLog.Error(err, "Failed to discover batch/v1 CronJob availability")
+		os.Exit(1)
+	}
+	setupLog.WithValues("supported", useBatchV1CronJobs).Info("Checking if batch/v1 CronJobs are supported by the cluster")
+
 	// Determine if we need to start the TSEE specific controllers.
 	enterpriseCRDExists, err := utils.RequiresTigeraSecure(mgr.GetConfig())
 	if err != nil {
@@ -293,11 +367,16 @@ func main() {
 		DetectedProvider:    provider,
 		EnterpriseCRDExists: enterpriseCRDExists,
 		UsePSP:              usePSP,
+		UseBatchV1CronJobs:  useBatchV1CronJobs,
 		AmazonCRDExists:     amazonCRDExists,
 		ClusterDomain:       clusterDomain,
 		KubernetesVersion:   kubernetesVersion,
 		ManageCRDs:          manageCRDs,
 		ShutdownContext:     sigHandler,
+
+		EnableTestLogGenerator:  enableTestLogGenerator,
+		InfrastructureProvider:  infrastructureProvider,
+		MaxConcurrentReconciles: maxConcurrentReconciles,
 	}
 
 	err = controllers.AddToManager(mgr, options)
@@ -306,6 +385,23 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err := mgr.AddHealthzCheck("ping", healthz.Ping); err != nil {
+		setupLog.Error(err, "unable to add the health check")
+		os.Exit(1)
+	}
+	// The watches of the controllers are established once the informers of the cache are synced.
+	if err := mgr.AddReadyzCheck("informers", func(req *http.Request) error {
+		ctx, cancel := context.WithTimeout(req.Context(), time.Second)
+		defer cancel()
+		if !mgr.GetCache().WaitForCacheSync(ctx) {
+			return fmt.Errorf("the informers are not synced")
+		}
+		return nil
+	}); err != nil {
+		setupLog.Error(err, "unable to add the readiness check of the informers")
+		os.Exit(1)
+	}
+
 	setupLog.Info("starting manager")
 	if err := mgr.Start(sigHandler); err != nil {
 		setupLog.Error(err, "problem running manager")
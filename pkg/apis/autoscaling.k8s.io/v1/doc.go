@@ -0,0 +1,7 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Package v1 contains the subset of the API of the Kubernetes vertical pod autoscaler that the operator renders.
+// +k8s:deepcopy-gen=package,register
+// +groupName=autoscaling.k8s.io
+
+package v1
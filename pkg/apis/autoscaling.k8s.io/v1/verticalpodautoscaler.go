@@ -0,0 +1,116 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VerticalPodAutoscalerList is a list of VerticalPodAutoscaler resources.
+type VerticalPodAutoscalerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []VerticalPodAutoscaler `json:"items"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VerticalPodAutoscaler sets the resource requests of the pods of a workload from their actual usage. The status of
+// the resource is left out, as it is only written by the autoscaler.
+type VerticalPodAutoscaler struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec VerticalPodAutoscalerSpec `json:"spec"`
+}
+
+// VerticalPodAutoscalerSpec is the specification of the behavior of the autoscaler.
+type VerticalPodAutoscalerSpec struct {
+	// TargetRef points to the controller managing the set of pods for the autoscaler to control.
+	TargetRef *autoscalingv1.CrossVersionObjectReference `json:"targetRef"`
+
+	// UpdatePolicy describes the rules on how changes are applied to the pods.
+	// +optional
+	UpdatePolicy *PodUpdatePolicy `json:"updatePolicy,omitempty"`
+
+	// ResourcePolicy controls how the autoscaler computes the recommended resources.
+	// +optional
+	ResourcePolicy *PodResourcePolicy `json:"resourcePolicy,omitempty"`
+}
+
+// UpdateMode controls when the autoscaler applies the recommended resources.
+type UpdateMode string
+
+const (
+	// UpdateModeOff means that the autoscaler only computes recommendations, without applying them.
+	UpdateModeOff UpdateMode = "Off"
+	// UpdateModeInitial means that the autoscaler only sets the resources of pods when they are created.
+	UpdateModeInitial UpdateMode = "Initial"
+	// UpdateModeAuto means that the autoscaler sets the resources of pods when they are created and updates them
+	// during their lifetime.
+	UpdateModeAuto UpdateMode = "Auto"
+)
+
+// PodUpdatePolicy describes the rules on how changes are applied to the pods.
+type PodUpdatePolicy struct {
+	// UpdateMode controls when the autoscaler applies the recommended resources.
+	// +optional
+	UpdateMode *UpdateMode `json:"updateMode,omitempty"`
+}
+
+// PodResourcePolicy controls how the autoscaler computes the recommended resources of the containers of the pods.
+type PodResourcePolicy struct {
+	// ContainerPolicies are the policies of the containers.
+	// +optional
+	ContainerPolicies []ContainerResourcePolicy `json:"containerPolicies,omitempty"`
+}
+
+// ContainerControlledValues controls which resource values are autoscaled.
+type ContainerControlledValues string
+
+const (
+	// ContainerControlledValuesRequestsAndLimits means that both requests and limits are autoscaled.
+	ContainerControlledValuesRequestsAndLimits ContainerControlledValues = "RequestsAndLimits"
+	// ContainerControlledValuesRequestsOnly means that only the requests are autoscaled.
+	ContainerControlledValuesRequestsOnly ContainerControlledValues = "RequestsOnly"
+)
+
+// ContainerResourcePolicy controls how the autoscaler computes the recommended resources of a container.
+type ContainerResourcePolicy struct {
+	// ContainerName is the name of the container or "*" for the containers that don't have a policy.
+	ContainerName string `json:"containerName,omitempty"`
+
+	// MinAllowed is the minimum amount of resources that is recommended for the container.
+	// +optional
+	MinAllowed corev1.ResourceList `json:"minAllowed,omitempty"`
+
+	// MaxAllowed is the maximum amount of resources that is recommended for the container.
+	// +optional
+	MaxAllowed corev1.ResourceList `json:"maxAllowed,omitempty"`
+
+	// ControlledResources are the resources that are autoscaled. Defaults to cpu and memory.
+	// +optional
+	ControlledResources *[]corev1.ResourceName `json:"controlledResources,omitempty"`
+
+	// ControlledValues controls which resource values are autoscaled. Defaults to RequestsAndLimits.
+	// +optional
+	ControlledValues *ContainerControlledValues `json:"controlledValues,omitempty"`
+}
@@ -0,0 +1,30 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+// InfrastructureProvider is the Cluster API infrastructure provider that provisioned the machines of the cluster.
+type InfrastructureProvider string
+
+const (
+	// InfrastructureProviderNone means that the cluster wasn't provisioned by Cluster API, or that its infrastructure
+	// provider isn't known.
+	InfrastructureProviderNone InfrastructureProvider = ""
+	// InfrastructureProviderAWS is the Cluster API provider for AWS (CAPA).
+	InfrastructureProviderAWS InfrastructureProvider = "AWS"
+	// InfrastructureProviderAzure is the Cluster API provider for Azure (CAPZ).
+	InfrastructureProviderAzure InfrastructureProvider = "Azure"
+	// InfrastructureProviderVSphere is the Cluster API provider for vSphere (CAPV).
+	InfrastructureProviderVSphere InfrastructureProvider = "vSphere"
+)
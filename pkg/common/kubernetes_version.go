@@ -59,3 +59,22 @@ func (v *VersionInfo) ProvidesCertV1API() bool {
 	}
 	return false
 }
+
+// SupportsInPlacePodResize returns if the resources of the containers of running pods can be resized given the
+// current k8s version. The InPlacePodVerticalScaling feature gate must also be enabled before 1.33, which can only be
+// told by attempting a resize.
+func (v *VersionInfo) SupportsInPlacePodResize() bool {
+	if v != nil && (v.Major > 1 || (v.Major == 1 && v.Minor >= 27)) {
+		return true
+	}
+	return false
+}
+
+// ProvidesPodResizeSubresource returns if the resources of running pods are resized through the resize subresource of
+// pods given the current k8s version, rather than by updating the pods.
+func (v *VersionInfo) ProvidesPodResizeSubresource() bool {
+	if v != nil && (v.Major > 1 || (v.Major == 1 && v.Minor >= 33)) {
+		return true
+	}
+	return false
+}
@@ -24,6 +24,7 @@ import (
 	"k8s.io/client-go/kubernetes/fake"
 
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 )
 
@@ -73,4 +74,14 @@ var _ = Describe("Test get Kubernetes version", func() {
 		Expect(err).To(HaveOccurred())
 		Expect(err).To(Equal(fmt.Errorf("failed to parse k8s minor version: %s", invalidMinor)))
 	})
+
+	DescribeTable("in-place pod resize", func(v *VersionInfo, resize, subresource bool) {
+		Expect(v.SupportsInPlacePodResize()).To(Equal(resize))
+		Expect(v.ProvidesPodResizeSubresource()).To(Equal(subresource))
+	},
+		Entry("unknown version", (*VersionInfo)(nil), false, false),
+		Entry("1.26", &VersionInfo{Major: 1, Minor: 26}, false, false),
+		Entry("1.27", &VersionInfo{Major: 1, Minor: 27}, true, false),
+		Entry("1.33", &VersionInfo{Major: 1, Minor: 33}, true, true),
+	)
 })
@@ -0,0 +1,56 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package components
+
+// imageArchitectures lists the CPU architectures in the manifests of the images that are not published for all the
+// architectures that the nodes of a cluster can run on. It must be kept in sync with the manifests of the images.
+var imageArchitectures = map[string][]string{
+	ComponentElasticsearch.Image: {"amd64"},
+	ComponentKibana.Image:        {"amd64"},
+	ComponentEsCurator.Image:     {"amd64"},
+}
+
+// Architectures returns the CPU architectures that all the images of the given components are published for, or nil
+// if they are published for all architectures.
+func Architectures(cs ...component) []string {
+	var archs []string
+	restricted := false
+	for _, c := range cs {
+		imageArchs, ok := imageArchitectures[c.Image]
+		if !ok {
+			continue
+		}
+		if !restricted {
+			archs = append([]string{}, imageArchs...)
+			restricted = true
+			continue
+		}
+		archs = intersect(archs, imageArchs)
+	}
+	return archs
+}
+
+func intersect(a, b []string) []string {
+	result := []string{}
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				result = append(result, x)
+				break
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,30 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package components
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("test Architectures", func() {
+	It("should not restrict the architectures of images that are published for all of them", func() {
+		Expect(Architectures(ComponentTigeraNode, ComponentElasticsearchOperator)).To(BeNil())
+	})
+
+	It("should return the architectures that all the restricted images are published for", func() {
+		Expect(Architectures(ComponentTigeraNode, ComponentElasticsearch, ComponentKibana)).To(Equal([]string{"amd64"}))
+	})
+})
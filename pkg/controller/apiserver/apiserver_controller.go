@@ -81,7 +81,14 @@ func Add(mgr manager.Manager, opts options.AddOptions) error {
 		})
 	}
 
-	return add(c, r)
+	if err := add(c, r); err != nil {
+		return err
+	}
+	flags := map[string]*utils.ReadyFlag{}
+	if opts.EnterpriseCRDExists {
+		flags["tier-watch"] = r.tierWatchReady
+	}
+	return utils.AddReadyzCheck(mgr, "apiserver-controller", flags)
 }
 
 // newReconciler returns a new reconcile.Reconciler
@@ -73,7 +73,10 @@ func Add(mgr manager.Manager, opts options.AddOptions) error {
 
 	go utils.WaitToAddLicenseKeyWatch(c, k8sClient, log, licenseAPIReady)
 
-	return add(mgr, c)
+	if err := add(mgr, c); err != nil {
+		return err
+	}
+	return utils.AddReadyzCheck(mgr, "applicationlayer-controller", map[string]*utils.ReadyFlag{"license-api": licenseAPIReady})
 }
 
 // newReconciler returns a new *reconcile.Reconciler.
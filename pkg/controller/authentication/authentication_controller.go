@@ -89,7 +89,10 @@ func Add(mgr manager.Manager, opts options.AddOptions) error {
 		{Name: networkpolicy.TigeraComponentDefaultDenyPolicyName, Namespace: render.DexNamespace},
 	})
 
-	return add(mgr, c)
+	if err := add(mgr, c); err != nil {
+		return err
+	}
+	return utils.AddReadyzCheck(mgr, controllerName, map[string]*utils.ReadyFlag{"tier-watch": tierWatchReady})
 }
 
 // newReconciler returns a new reconcile.Reconciler
@@ -279,6 +279,14 @@ var _ = Describe("Test CertificateManagement suite", func() {
 
 		})
 
+		It("should annotate the copies of its secret with their source", func() {
+			keyPair, err := certificateManager.GetOrCreateKeyPair(cli, appSecretName, appNs, appDNSNames)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(keyPair.Secret(common.OperatorNamespace()).Annotations).NotTo(HaveKey(rmeta.CopySourceAnnotation))
+			Expect(keyPair.Secret(appNs).Annotations).To(HaveKeyWithValue(rmeta.CopySourceAnnotation, common.OperatorNamespace()+"/"+appSecretName))
+		})
+
 		It("render the right spec for certificateManager issued key pairs", func() {
 			By("creating a key pair signed by certificateManager")
 			keyPair, err := certificateManager.GetOrCreateKeyPair(cli, appSecretName, appNs, appDNSNames)
@@ -473,7 +481,11 @@ var _ = Describe("Test CertificateManagement suite", func() {
 			}))
 			Expect(trustedBundle.MountPath()).To(Equal(certificatemanagement.TrustedCertBundleMountPath))
 			configMap := trustedBundle.ConfigMap(appNs)
-			Expect(configMap.ObjectMeta).To(Equal(metav1.ObjectMeta{Name: certificatemanagement.TrustedCertConfigMapName, Namespace: appNs}))
+			Expect(configMap.ObjectMeta).To(Equal(metav1.ObjectMeta{
+				Name:        certificatemanagement.TrustedCertConfigMapName,
+				Namespace:   appNs,
+				Annotations: map[string]string{rmeta.CopySourceAnnotation: common.OperatorNamespace() + "/" + certificatemanagement.CASecretName},
+			}))
 			Expect(configMap.TypeMeta).To(Equal(metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"}))
 			By("counting the number of pem blocks in the configmap")
 			bundle := configMap.Data[certificatemanagement.TrustedCertConfigMapKeyName]
@@ -88,7 +88,10 @@ func Add(mgr manager.Manager, opts options.AddOptions) error {
 		{Name: networkpolicy.TigeraComponentDefaultDenyPolicyName, Namespace: render.GuardianNamespace},
 	})
 
-	return add(mgr, controller)
+	if err := add(mgr, controller); err != nil {
+		return err
+	}
+	return utils.AddReadyzCheck(mgr, controllerName, map[string]*utils.ReadyFlag{"tier-watch": tierWatchReady})
 }
 
 // newReconciler returns a new reconcile.Reconciler
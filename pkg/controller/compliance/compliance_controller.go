@@ -85,7 +85,13 @@ func Add(mgr manager.Manager, opts options.AddOptions) error {
 		{Name: networkpolicy.TigeraComponentDefaultDenyPolicyName, Namespace: render.ComplianceNamespace},
 	})
 
-	return add(mgr, controller)
+	if err := add(mgr, controller); err != nil {
+		return err
+	}
+	return utils.AddReadyzCheck(mgr, "compliance-controller", map[string]*utils.ReadyFlag{
+		"license-api": licenseAPIReady,
+		"tier-watch":  tierWatchReady,
+	})
 }
 
 // newReconciler returns a new *reconcile.Reconciler
@@ -154,7 +154,14 @@ func Add(mgr manager.Manager, opts options.AddOptions) error {
 		)
 	}
 
-	return add(c, ri)
+	if err := add(c, ri); err != nil {
+		return err
+	}
+	flags := map[string]*utils.ReadyFlag{}
+	if opts.EnterpriseCRDExists {
+		flags["tier-watch"] = ri.tierWatchReady
+	}
+	return utils.AddReadyzCheck(mgr, "tigera-installation-controller", flags)
 }
 
 // newReconciler returns a new reconcile.Reconciler
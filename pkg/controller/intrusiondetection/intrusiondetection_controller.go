@@ -101,7 +101,14 @@ func Add(mgr manager.Manager, opts options.AddOptions) error {
 		{Name: dpi.DeepPacketInspectionPolicyName, Namespace: dpi.DeepPacketInspectionNamespace},
 	})
 
-	return add(mgr, controller)
+	if err := add(mgr, controller); err != nil {
+		return err
+	}
+	return utils.AddReadyzCheck(mgr, "intrusiondetection-controller", map[string]*utils.ReadyFlag{
+		"license-api": licenseAPIReady,
+		"dpi-api":     dpiAPIReady,
+		"tier-watch":  tierWatchReady,
+	})
 }
 
 // newReconciler returns a new reconcile.Reconciler
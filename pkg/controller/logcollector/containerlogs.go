@@ -0,0 +1,81 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logcollector
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/controller/utils"
+)
+
+// containerRuntimeInterval is how often the container runtime of the nodes is detected. The nodes are listed to detect
+// it, so it isn't done on every reconcile.
+const containerRuntimeInterval = 10 * time.Minute
+
+// containerRuntimeCache holds the container runtime of the nodes, as of the last detection.
+type containerRuntimeCache struct {
+	lock       sync.Mutex
+	runtime    operatorv1.ContainerRuntime
+	detectedAt time.Time
+}
+
+// get returns the container runtime of the nodes, detecting it if the cache is older than the interval. Failed
+// detections aren't cached.
+func (c *containerRuntimeCache) get(now time.Time, detect func() (operatorv1.ContainerRuntime, error)) (operatorv1.ContainerRuntime, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if !c.detectedAt.IsZero() && now.Sub(c.detectedAt) < containerRuntimeInterval {
+		return c.runtime, nil
+	}
+	runtime, err := detect()
+	if err != nil {
+		return "", err
+	}
+	c.runtime, c.detectedAt = runtime, now
+	return runtime, nil
+}
+
+// getContainerLogs returns the ContainerLogs of the LogCollector, with the container runtime detected from the nodes
+// when the LogCollector doesn't set it. When the nodes don't report a known runtime, the node images of the Cluster API
+// infrastructure providers run containerd, whose log files are under /var/log/pods. It returns nil when the
+// LogCollector doesn't opt in to mounting the log files of the containers, or when the runtime can't be determined.
+func (r *ReconcileLogCollector) getContainerLogs(ctx context.Context, logCollector *operatorv1.LogCollector) (*operatorv1.ContainerLogs, error) {
+	if logCollector.Spec.ContainerLogs == nil {
+		return nil, nil
+	}
+	containerLogs := logCollector.Spec.ContainerLogs.DeepCopy()
+	if containerLogs.Runtime != nil {
+		return containerLogs, nil
+	}
+
+	runtime, err := r.containerRuntime.get(time.Now(), func() (operatorv1.ContainerRuntime, error) {
+		return utils.DetectContainerRuntime(ctx, r.client)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if runtime == "" && r.infrastructureProvider != common.InfrastructureProviderNone {
+		runtime = operatorv1.ContainerRuntimeContainerd
+	}
+	if runtime == "" {
+		return nil, nil
+	}
+	containerLogs.Runtime = &runtime
+	return containerLogs, nil
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logcollector
+
+import (
+	"context"
+	"sync/atomic"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/tigera/operator/pkg/controller/utils"
+	"github.com/tigera/operator/pkg/render"
+)
+
+// inPlaceResizeEnabled returns whether the resources of the fluentd pods are resized in place rather than by
+// restarting the pods.
+func (r *ReconcileLogCollector) inPlaceResizeEnabled() bool {
+	return r.clientset != nil && r.kubernetesVersion.SupportsInPlacePodResize() && atomic.LoadInt32(&r.inPlaceResizeUnsupported) == 0
+}
+
+// getFluentdDaemonSet returns the fluentd DaemonSet of the given name, or nil if it doesn't exist.
+func (r *ReconcileLogCollector) getFluentdDaemonSet(ctx context.Context, name string) (*appsv1.DaemonSet, error) {
+	ds := &appsv1.DaemonSet{}
+	err := r.client.Get(ctx, types.NamespacedName{Name: name, Namespace: render.LogCollectorNamespace}, ds)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return ds, nil
+}
+
+// resizeFluentdPods resizes the running pods of the fluentd DaemonSet of the given name in place when the DaemonSet
+// uses the OnDelete update strategy, i.e. when only the resources of its pod template changed. The pods are labeled
+// with the current revision of the DaemonSet once resized, so that the DaemonSet considers them to be up to date.
+//
+// It returns whether all the pods are resized. If the API server rejects a resize, in-place resizing is disabled and
+// the change is rolled out by restarting the pods instead.
+func (r *ReconcileLogCollector) resizeFluentdPods(ctx context.Context, name string) (bool, error) {
+	ds, err := r.getFluentdDaemonSet(ctx, name)
+	if err != nil || ds == nil {
+		return ds == nil, err
+	}
+	if ds.Spec.UpdateStrategy.Type != appsv1.OnDeleteDaemonSetStrategyType {
+		return true, nil
+	}
+	if ds.Status.ObservedGeneration < ds.Generation {
+		// The revision of the current pod template isn't created yet.
+		return false, nil
+	}
+
+	revision, err := r.currentRevisionHash(ctx, ds)
+	if err != nil || revision == "" {
+		return false, err
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(ds.Spec.Selector)
+	if err != nil {
+		return false, err
+	}
+	pods := &corev1.PodList{}
+	if err := r.client.List(ctx, pods, client.InNamespace(render.LogCollectorNamespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return false, err
+	}
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if !metav1.IsControlledBy(pod, ds) || pod.DeletionTimestamp != nil {
+			continue
+		}
+		if pod.Labels[appsv1.DefaultDaemonSetUniqueLabelKey] == revision {
+			continue
+		}
+
+		if err := utils.ResizePod(ctx, r.clientset, r.kubernetesVersion, pod, &ds.Spec.Template); err != nil {
+			log.Error(err, "Failed to resize the fluentd pod in place, falling back to restarting the pods", "pod", pod.Name)
+			atomic.StoreInt32(&r.inPlaceResizeUnsupported, 1)
+			return false, nil
+		}
+
+		patchFrom := client.MergeFrom(pod.DeepCopy())
+		pod.Labels[appsv1.DefaultDaemonSetUniqueLabelKey] = revision
+		if err := r.client.Patch(ctx, pod, patchFrom); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// currentRevisionHash returns the hash of the most recent revision of the DaemonSet, which is the one of its current
+// pod template.
+func (r *ReconcileLogCollector) currentRevisionHash(ctx context.Context, ds *appsv1.DaemonSet) (string, error) {
+	selector, err := metav1.LabelSelectorAsSelector(ds.Spec.Selector)
+	if err != nil {
+		return "", err
+	}
+	revisions := &appsv1.ControllerRevisionList{}
+	if err := r.client.List(ctx, revisions, client.InNamespace(ds.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return "", err
+	}
+
+	var current *appsv1.ControllerRevision
+	for i := range revisions.Items {
+		rev := &revisions.Items[i]
+		if !metav1.IsControlledBy(rev, ds) {
+			continue
+		}
+		if current == nil || rev.Revision > current.Revision {
+			current = rev
+		}
+	}
+	if current == nil {
+		return "", nil
+	}
+	return current.Labels[appsv1.DefaultDaemonSetUniqueLabelKey], nil
+}
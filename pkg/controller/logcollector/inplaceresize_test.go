@@ -0,0 +1,138 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logcollector
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kfake "k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/tigera/operator/pkg/apis"
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/render"
+)
+
+var _ = Describe("fluentd in-place resize tests", func() {
+	var c client.Client
+	var clientset *kfake.Clientset
+	var ctx context.Context
+	var r ReconcileLogCollector
+	var pod *corev1.Pod
+
+	resources := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+	}
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(apis.AddToScheme(scheme)).NotTo(HaveOccurred())
+		Expect(appsv1.SchemeBuilder.AddToScheme(scheme)).ShouldNot(HaveOccurred())
+		c = fake.NewClientBuilder().WithScheme(scheme).Build()
+		ctx = context.Background()
+
+		labels := map[string]string{"k8s-app": render.FluentdNodeName}
+		ds := &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: render.FluentdNodeName, Namespace: render.LogCollectorNamespace, UID: "ds-uid"},
+			Spec: appsv1.DaemonSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: labels},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "fluentd", Resources: resources}},
+					},
+				},
+				UpdateStrategy: appsv1.DaemonSetUpdateStrategy{Type: appsv1.OnDeleteDaemonSetStrategyType},
+			},
+		}
+		Expect(c.Create(ctx, ds)).NotTo(HaveOccurred())
+
+		owner := *metav1.NewControllerRef(ds, appsv1.SchemeGroupVersion.WithKind("DaemonSet"))
+		for i, hash := range []string{"old", "new"} {
+			Expect(c.Create(ctx, &appsv1.ControllerRevision{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            fmt.Sprintf("%s-%s", render.FluentdNodeName, hash),
+					Namespace:       render.LogCollectorNamespace,
+					Labels:          map[string]string{"k8s-app": render.FluentdNodeName, appsv1.DefaultDaemonSetUniqueLabelKey: hash},
+					OwnerReferences: []metav1.OwnerReference{owner},
+				},
+				Revision: int64(i + 1),
+			})).NotTo(HaveOccurred())
+		}
+
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "fluentd-node-abcde",
+				Namespace:       render.LogCollectorNamespace,
+				Labels:          map[string]string{"k8s-app": render.FluentdNodeName, appsv1.DefaultDaemonSetUniqueLabelKey: "old"},
+				OwnerReferences: []metav1.OwnerReference{owner},
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "fluentd"}},
+			},
+		}
+		Expect(c.Create(ctx, pod)).NotTo(HaveOccurred())
+		clientset = kfake.NewSimpleClientset(pod.DeepCopy())
+
+		r = ReconcileLogCollector{
+			client:            c,
+			clientset:         clientset,
+			kubernetesVersion: &common.VersionInfo{Major: 1, Minor: 27},
+		}
+	})
+
+	It("should resize the pods in place and label them with the current revision", func() {
+		Expect(r.inPlaceResizeEnabled()).To(BeTrue())
+		resized, err := r.resizeFluentdPods(ctx, render.FluentdNodeName)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resized).To(BeTrue())
+
+		patched, err := clientset.CoreV1().Pods(render.LogCollectorNamespace).Get(ctx, pod.Name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(patched.Spec.Containers[0].Resources.Requests.Memory().String()).To(Equal("1Gi"))
+
+		current := &corev1.Pod{}
+		Expect(c.Get(ctx, types.NamespacedName{Name: pod.Name, Namespace: pod.Namespace}, current)).NotTo(HaveOccurred())
+		Expect(current.Labels).To(HaveKeyWithValue(appsv1.DefaultDaemonSetUniqueLabelKey, "new"))
+	})
+
+	It("should fall back to restarting the pods when the resize is rejected", func() {
+		clientset.PrependReactor("patch", "pods", func(action ktesting.Action) (bool, runtime.Object, error) {
+			return true, nil, fmt.Errorf("pod updates may not change fields other than the image")
+		})
+
+		resized, err := r.resizeFluentdPods(ctx, render.FluentdNodeName)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resized).To(BeFalse())
+		Expect(r.inPlaceResizeEnabled()).To(BeFalse())
+	})
+
+	It("should not resize in place on clusters that don't support it", func() {
+		r.kubernetesVersion = &common.VersionInfo{Major: 1, Minor: 26}
+		Expect(r.inPlaceResizeEnabled()).To(BeFalse())
+	})
+})
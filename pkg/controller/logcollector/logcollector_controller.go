@@ -24,8 +24,10 @@ import (
 
 	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
@@ -34,6 +36,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
@@ -50,6 +53,7 @@ import (
 	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
 	"github.com/tigera/operator/pkg/render/monitor"
+	"github.com/tigera/operator/pkg/tls/certificatemanagement"
 	"github.com/tigera/operator/pkg/url"
 )
 
@@ -66,21 +70,24 @@ func Add(mgr manager.Manager, opts options.AddOptions) error {
 	licenseAPIReady := &utils.ReadyFlag{}
 	tierWatchReady := &utils.ReadyFlag{}
 
+	k8sClient, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		log.Error(err, "Failed to establish a connection to k8s")
+		return err
+	}
+
 	// create the reconciler
-	reconciler := newReconciler(mgr, opts, licenseAPIReady, tierWatchReady)
+	reconciler := newReconciler(mgr, opts, licenseAPIReady, tierWatchReady, k8sClient)
 
 	// Create a new controller
-	controller, err := controller.New("logcollector-controller", mgr, controller.Options{Reconciler: reconcile.Reconciler(reconciler)})
+	controller, err := controller.New("logcollector-controller", mgr, controller.Options{
+		Reconciler:              reconcile.Reconciler(reconciler),
+		MaxConcurrentReconciles: opts.MaxConcurrentReconciles,
+	})
 	if err != nil {
 		return fmt.Errorf("Failed to create logcollector-controller: %v", err)
 	}
 
-	k8sClient, err := kubernetes.NewForConfig(mgr.GetConfig())
-	if err != nil {
-		log.Error(err, "Failed to establish a connection to k8s")
-		return err
-	}
-
 	go utils.WaitToAddLicenseKeyWatch(controller, k8sClient, log, licenseAPIReady)
 
 	go utils.WaitToAddTierWatch(networkpolicy.TigeraComponentTierName, controller, k8sClient, log, tierWatchReady)
@@ -88,20 +95,32 @@ func Add(mgr manager.Manager, opts options.AddOptions) error {
 		{Name: render.FluentdPolicyName, Namespace: render.LogCollectorNamespace},
 	})
 
-	return add(mgr, controller)
+	if err := add(mgr, controller); err != nil {
+		return err
+	}
+	return utils.AddReadyzCheck(mgr, "logcollector-controller", map[string]*utils.ReadyFlag{
+		"license-api": licenseAPIReady,
+		"tier-watch":  tierWatchReady,
+	})
 }
 
 // newReconciler returns a new reconcile.Reconciler
-func newReconciler(mgr manager.Manager, opts options.AddOptions, licenseAPIReady *utils.ReadyFlag, tierWatchReady *utils.ReadyFlag) reconcile.Reconciler {
+func newReconciler(mgr manager.Manager, opts options.AddOptions, licenseAPIReady *utils.ReadyFlag, tierWatchReady *utils.ReadyFlag, clientset kubernetes.Interface) reconcile.Reconciler {
 	c := &ReconcileLogCollector{
-		client:          mgr.GetClient(),
-		scheme:          mgr.GetScheme(),
-		provider:        opts.DetectedProvider,
-		status:          status.New(mgr.GetClient(), "log-collector", opts.KubernetesVersion),
-		clusterDomain:   opts.ClusterDomain,
-		licenseAPIReady: licenseAPIReady,
-		tierWatchReady:  tierWatchReady,
-		usePSP:          opts.UsePSP,
+		client:            mgr.GetClient(),
+		clientset:         clientset,
+		scheme:            mgr.GetScheme(),
+		provider:          opts.DetectedProvider,
+		status:            status.New(mgr.GetClient(), "log-collector", opts.KubernetesVersion),
+		clusterDomain:     opts.ClusterDomain,
+		kubernetesVersion: opts.KubernetesVersion,
+		licenseAPIReady:   licenseAPIReady,
+		tierWatchReady:    tierWatchReady,
+		usePSP:            opts.UsePSP,
+
+		enableTestLogGenerator: opts.EnableTestLogGenerator,
+		esCliCreator:           utils.NewElasticClient,
+		infrastructureProvider: opts.InfrastructureProvider,
 	}
 	c.status.Run(opts.ShutdownContext)
 	return c
@@ -142,6 +161,14 @@ func add(mgr manager.Manager, c controller.Controller) error {
 		}
 	}
 
+	// The secrets that the LogCollector names, such as its pull secrets and the CA bundles of the additional stores,
+	// can't be watched by name, so the secrets of the operator namespace are filtered by the references of the
+	// LogCollector. A rotation of these secrets then rolls fluentd right away instead of at the next reconcile.
+	err = c.Watch(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestForObject{}, logCollectorSecretPredicate(mgr.GetClient()))
+	if err != nil {
+		return fmt.Errorf("logcollector-controller failed to watch the secrets of the additional stores: %w", err)
+	}
+
 	for _, configMapName := range []string{render.FluentdFilterConfigMapName, relasticsearch.ClusterConfigConfigMapName} {
 		if err = utils.AddConfigMapWatch(c, configMapName, common.OperatorNamespace()); err != nil {
 			return fmt.Errorf("logcollector-controller failed to watch ConfigMap %s: %v", configMapName, err)
@@ -153,6 +180,18 @@ func add(mgr manager.Manager, c controller.Controller) error {
 		return fmt.Errorf("logcollector-controller failed to watch the node resource: %w", err)
 	}
 
+	// Watch the LogStorage, whose index templates are applied before fluentd starts writing logs.
+	err = c.Watch(&source.Kind{Type: &operatorv1.LogStorage{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return fmt.Errorf("logcollector-controller failed to watch LogStorage resource: %w", err)
+	}
+
+	// Watch the ManagementCluster, on which the status of the log collection of the managed clusters is reported.
+	err = c.Watch(&source.Kind{Type: &operatorv1.ManagementCluster{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return fmt.Errorf("logcollector-controller failed to watch ManagementCluster resource: %w", err)
+	}
+
 	return nil
 }
 
@@ -163,14 +202,38 @@ var _ reconcile.Reconciler = &ReconcileLogCollector{}
 type ReconcileLogCollector struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
-	client          client.Client
-	scheme          *runtime.Scheme
-	provider        operatorv1.Provider
-	status          status.StatusManager
-	clusterDomain   string
-	licenseAPIReady *utils.ReadyFlag
-	tierWatchReady  *utils.ReadyFlag
-	usePSP          bool
+	client            client.Client
+	clientset         kubernetes.Interface
+	scheme            *runtime.Scheme
+	provider          operatorv1.Provider
+	status            status.StatusManager
+	clusterDomain     string
+	kubernetesVersion *common.VersionInfo
+	licenseAPIReady   *utils.ReadyFlag
+	tierWatchReady    *utils.ReadyFlag
+	usePSP            bool
+
+	// Whether to deploy the test log generator on the nodes labeled for it.
+	enableTestLogGenerator bool
+
+	// inPlaceResizeUnsupported is set to 1 once the API server rejected an in-place resize of a fluentd pod, e.g.
+	// because the InPlacePodVerticalScaling feature gate is disabled, after which the pods are restarted to be resized.
+	// It is accessed atomically, as reconciles can run concurrently.
+	inPlaceResizeUnsupported int32
+
+	// esCliCreator creates the client of the Elasticsearch cluster that the logs of the managed clusters are indexed in.
+	esCliCreator utils.ElasticsearchClientCreator
+
+	// managedClusterLogs caches the end times of the most recent flow logs of the managed clusters.
+	managedClusterLogs managedClusterLogsCache
+
+	// containerRuntime caches the container runtime of the nodes, which the log files of the containers are mounted
+	// for.
+	containerRuntime containerRuntimeCache
+
+	// infrastructureProvider is the Cluster API infrastructure provider of the cluster, whose node images determine
+	// the default paths of the log files of the containers.
+	infrastructureProvider common.InfrastructureProvider
 }
 
 // GetLogCollector returns the default LogCollector instance with defaults populated.
@@ -189,6 +252,11 @@ func GetLogCollector(ctx context.Context, cli client.Client) (*operatorv1.LogCol
 				return nil, fmt.Errorf("Syslog config has invalid Endpoint: %s", err)
 			}
 		}
+		if instance.Spec.AdditionalStores.Splunk != nil {
+			if _, _, _, err := url.ParseEndpoint(instance.Spec.AdditionalStores.Splunk.Endpoint); err != nil {
+				return nil, fmt.Errorf("Splunk config has invalid Endpoint: %s", err)
+			}
+		}
 	}
 
 	return instance, nil
@@ -332,7 +400,26 @@ func (r *ReconcileLogCollector) Reconcile(ctx context.Context, request reconcile
 		return reconcile.Result{}, err
 	}
 
-	pullSecrets, err := utils.GetNetworkingPullSecrets(installation, r.client)
+	// Fluentd isn't deployed until the overrides of the index templates of the LogStorage are applied, so that the
+	// indices are created with them. Once it runs, it keeps writing logs while the overrides are applied again.
+	logStorage, err := utils.GetLogStorage(ctx, r.client)
+	if err != nil {
+		log.Error(err, "Failed to get the LogStorage")
+		r.status.SetDegraded("Failed to get the LogStorage", err.Error())
+		return reconcile.Result{}, err
+	}
+	var holdBackFluentd bool
+	if indexTemplatesPending(logStorage) {
+		ds, err := r.getFluentdDaemonSet(ctx, render.FluentdNodeName)
+		if err != nil {
+			log.Error(err, "Failed to get the fluentd DaemonSet")
+			r.status.SetDegraded("Failed to get the fluentd DaemonSet", err.Error())
+			return reconcile.Result{}, err
+		}
+		holdBackFluentd = ds == nil
+	}
+
+	pullSecrets, err := utils.GetComponentPullSecrets(ctx, installation, instance.Spec.ImagePullSecrets, r.client)
 	if err != nil {
 		log.Error(err, "Error with Pull secrets")
 		r.status.SetDegraded("Error retrieving pull secrets", err.Error())
@@ -429,6 +516,17 @@ func (r *ReconcileLogCollector) Reconcile(ctx context.Context, request reconcile
 		}
 	}
 
+	var splunkCA []byte
+	if instance.Spec.AdditionalStores != nil {
+		if splunk := instance.Spec.AdditionalStores.Splunk; splunk != nil {
+			if splunkCA, err = getAdditionalStoreCA(r.client, splunk.TLS); err != nil {
+				log.Error(err, "Error with the Splunk CA bundle")
+				r.status.SetDegraded("Error with the Splunk CA bundle", err.Error())
+				return reconcile.Result{}, err
+			}
+		}
+	}
+
 	// Try to grab the ManagementClusterConnection CR because we need it for network policy rendering,
 	// as well as validation with respect to Syslog.logTypes.
 	managementClusterConnection, err := utils.GetManagementClusterConnection(ctx, r.client)
@@ -445,6 +543,30 @@ func (r *ReconcileLogCollector) Reconcile(ctx context.Context, request reconcile
 	}
 	managedCluster := managementClusterConnection != nil
 
+	// On management clusters, the status of the log collection of the managed clusters is reported.
+	managementCluster, err := utils.GetManagementCluster(ctx, r.client)
+	if err != nil {
+		reqLogger.Error(err, "Error reading ManagementCluster")
+		r.status.SetDegraded("Error reading ManagementCluster", err.Error())
+		return reconcile.Result{}, err
+	}
+
+	var logBuffer *v1.LogBuffer
+	if managedCluster {
+		logBuffer = managementClusterConnection.Spec.LogBuffer
+	}
+	// The log buffer is only deleted when it is no longer configured, not on every reconcile.
+	removeLogBuffer := false
+	if logBuffer == nil {
+		err = r.client.Get(ctx, types.NamespacedName{Name: render.LogBufferName, Namespace: render.LogCollectorNamespace}, &appsv1.StatefulSet{})
+		if err != nil && !errors.IsNotFound(err) {
+			reqLogger.Error(err, "Error reading the log buffer")
+			r.status.SetDegraded("Error reading the log buffer", err.Error())
+			return reconcile.Result{}, err
+		}
+		removeLogBuffer = err == nil
+	}
+
 	if instance.Spec.AdditionalStores != nil {
 		if instance.Spec.AdditionalStores.Syslog != nil {
 			syslog := instance.Spec.AdditionalStores.Syslog
@@ -476,6 +598,21 @@ func (r *ReconcileLogCollector) Reconcile(ctx context.Context, request reconcile
 		r.status.SetDegraded("Error retrieving Fluentd filters", err.Error())
 		return reconcile.Result{}, err
 	}
+	filters = render.WithRedactionRules(filters, instance.Spec.RedactionRules)
+
+	// The hashed fields of the redaction rules are keyed with a key of the cluster, which is generated once.
+	var redactionKeySecret *corev1.Secret
+	if render.RedactionKeyRequired(instance.Spec.RedactionRules) {
+		redactionKeySecret, err = utils.GetSecret(ctx, r.client, render.FluentdRedactionKeySecret, common.OperatorNamespace())
+		if err != nil {
+			log.Error(err, "Error retrieving the redaction key")
+			r.status.SetDegraded("Error retrieving the redaction key", err.Error())
+			return reconcile.Result{}, err
+		}
+		if redactionKeySecret == nil {
+			redactionKeySecret = render.CreateFluentdRedactionKeySecret()
+		}
+	}
 
 	var eksConfig *render.EksCloudwatchLogConfig
 	if installation.KubernetesProvider == operatorv1.ProviderEKS {
@@ -496,30 +633,102 @@ func (r *ReconcileLogCollector) Reconcile(ctx context.Context, request reconcile
 		}
 	}
 
+	// The VerticalPodAutoscaler API can be installed at any time, so check for it on every reconcile.
+	var vpaAPI bool
+	if r.clientset != nil {
+		if vpaAPI, err = utils.SupportsVerticalPodAutoscalers(r.clientset); err != nil {
+			reqLogger.Error(err, "Failed to check for the VerticalPodAutoscaler API")
+			r.status.SetDegraded("Failed to check for the VerticalPodAutoscaler API", err.Error())
+			return reconcile.Result{}, err
+		}
+	}
+	if instance.Spec.VerticalPodAutoscaling != nil && !vpaAPI {
+		reqLogger.Info("VerticalPodAutoscaling is configured but the VerticalPodAutoscaler API is not installed")
+		r.status.SetDegraded("VerticalPodAutoscaling is configured but the VerticalPodAutoscaler API is not installed", "")
+		return reconcile.Result{}, nil
+	}
+
+	if err := validateNodePools(instance.Spec.NodePools); err != nil {
+		reqLogger.Error(err, "Invalid node pools")
+		r.status.SetDegraded("Invalid node pools", err.Error())
+		return reconcile.Result{}, nil
+	}
+	if err := validateDisruptionPolicy(instance.Spec.ComponentDisruptionPolicy(operatorv1.ComponentNameFluentd)); err != nil {
+		reqLogger.Error(err, "Invalid disruption policy")
+		r.status.SetDegraded("Invalid disruption policy", err.Error())
+		return reconcile.Result{}, nil
+	}
+	currentNodePools, err := getFluentdNodePools(ctx, r.client)
+	if err != nil {
+		reqLogger.Error(err, "Failed to get the fluentd DaemonSets of the node pools")
+		r.status.SetDegraded("Failed to get the fluentd DaemonSets of the node pools", err.Error())
+		return reconcile.Result{}, err
+	}
+
+	containerLogs, err := r.getContainerLogs(ctx, instance)
+	if err != nil {
+		reqLogger.Error(err, "Failed to detect the container runtime of the nodes")
+		r.status.SetDegraded("Failed to detect the container runtime of the nodes", err.Error())
+		return reconcile.Result{}, err
+	}
+
+	rolloutWindowOpen, untilRolloutWindow, err := utils.RolloutWindowOpen(instance.Spec.RolloutWindow, time.Now())
+	if err != nil {
+		reqLogger.Error(err, "Invalid rollout window")
+		r.status.SetDegraded("Invalid rollout window", err.Error())
+		return reconcile.Result{}, nil
+	}
+	deferRollouts := !rolloutWindowOpen
+
+	// Get the current fluentd DaemonSets, so that a change of only their resources is applied by resizing the pods in
+	// place when the cluster supports it, and so that rollouts of changed configuration are deferred until the rollout
+	// window opens.
+	inPlaceResize := r.inPlaceResizeEnabled()
+	var fluentdDaemonSet, fluentdWindowsDaemonSet *appsv1.DaemonSet
+	if inPlaceResize || deferRollouts {
+		if fluentdDaemonSet, err = r.getFluentdDaemonSet(ctx, render.FluentdNodeName); err == nil {
+			fluentdWindowsDaemonSet, err = r.getFluentdDaemonSet(ctx, render.FluentdNodeWindowsName)
+		}
+		if err != nil {
+			reqLogger.Error(err, "Failed to get the fluentd DaemonSets")
+			r.status.SetDegraded("Failed to get the fluentd DaemonSets", err.Error())
+			return reconcile.Result{}, err
+		}
+	}
+
 	// Create a component handler to manage the rendered component.
 	handler := utils.NewComponentHandler(log, r.client, r.scheme, instance)
 
 	fluentdCfg := &render.FluentdConfiguration{
-		LogCollector:     instance,
-		ESSecrets:        esSecrets,
-		ESClusterConfig:  esClusterConfig,
-		S3Credential:     s3Credential,
-		SplkCredential:   splunkCredential,
-		Filters:          filters,
-		EKSConfig:        eksConfig,
-		PullSecrets:      pullSecrets,
-		Installation:     installation,
-		ClusterDomain:    r.clusterDomain,
-		OSType:           rmeta.OSTypeLinux,
-		MetricsServerTLS: fluentdPrometheusTLS,
-		TrustedBundle:    trustedBundle,
-		ManagedCluster:   managedCluster,
-		UsePSP:           r.usePSP,
+		LogCollector:             instance,
+		ESSecrets:                esSecrets,
+		ESClusterConfig:          esClusterConfig,
+		S3Credential:             s3Credential,
+		SplkCredential:           splunkCredential,
+		SplunkCA:                 splunkCA,
+		Filters:                  filters,
+		RedactionKeySecret:       redactionKeySecret,
+		EKSConfig:                eksConfig,
+		PullSecrets:              pullSecrets,
+		Installation:             installation,
+		ClusterDomain:            r.clusterDomain,
+		OSType:                   rmeta.OSTypeLinux,
+		MetricsServerTLS:         fluentdPrometheusTLS,
+		TrustedBundle:            trustedBundle,
+		ManagedCluster:           managedCluster,
+		LogBuffer:                logBuffer,
+		RemoveLogBuffer:          removeLogBuffer,
+		UsePSP:                   r.usePSP,
+		InPlaceResize:            inPlaceResize,
+		CurrentDaemonSet:         fluentdDaemonSet,
+		DeferRollouts:            deferRollouts,
+		CurrentNodePools:         currentNodePools,
+		VerticalPodAutoscalerAPI: vpaAPI,
+		ContainerLogs:            containerLogs,
 	}
 	// Render the fluentd component for Linux
-	comp := render.Fluentd(fluentdCfg)
 	components := []render.Component{
-		comp,
+		fluentdComponent(fluentdCfg, holdBackFluentd),
 		rcertificatemanagement.CertificateManagement(&rcertificatemanagement.Config{
 			Namespace:       render.LogCollectorNamespace,
 			ServiceAccounts: []string{render.FluentdNodeName},
@@ -528,9 +737,56 @@ func (r *ReconcileLogCollector) Reconcile(ctx context.Context, request reconcile
 			},
 			TrustedBundle: trustedBundle,
 		}),
+		render.TestLogGenerator(&render.TestLogGeneratorConfiguration{
+			Installation: installation,
+			PullSecrets:  pullSecrets,
+			Enabled:      r.enableTestLogGenerator,
+		}),
+	}
+
+	// Render a fluentd component for Windows if the cluster has Windows nodes.
+	hasWindowsNodes, err := hasWindowsNodes(r.client)
+	if err != nil {
+		return reconcile.Result{}, err
 	}
 
-	if err = imageset.ApplyImageSet(ctx, r.client, variant, comp); err != nil {
+	if hasWindowsNodes {
+		// The metrics of Windows nodes are served with the same certificate as those of Linux nodes, unless the
+		// certificate is requested through certificate management, which isn't supported on Windows.
+		var windowsMetricsTLS certificatemanagement.KeyPairInterface
+		if !fluentdPrometheusTLS.UseCertificateManagement() {
+			windowsMetricsTLS = fluentdPrometheusTLS
+		}
+		components = append(components, fluentdComponent(&render.FluentdConfiguration{
+			LogCollector:             instance,
+			ESSecrets:                esSecrets,
+			ESClusterConfig:          esClusterConfig,
+			S3Credential:             s3Credential,
+			SplkCredential:           splunkCredential,
+			SplunkCA:                 splunkCA,
+			Filters:                  filters,
+			RedactionKeySecret:       redactionKeySecret,
+			EKSConfig:                eksConfig,
+			PullSecrets:              pullSecrets,
+			Installation:             installation,
+			ClusterDomain:            r.clusterDomain,
+			OSType:                   rmeta.OSTypeWindows,
+			MetricsServerTLS:         windowsMetricsTLS,
+			TrustedBundle:            trustedBundle,
+			ManagedCluster:           managedCluster,
+			LogBuffer:                logBuffer,
+			UsePSP:                   r.usePSP,
+			InPlaceResize:            inPlaceResize,
+			CurrentDaemonSet:         fluentdWindowsDaemonSet,
+			DeferRollouts:            deferRollouts,
+			CurrentNodePools:         currentNodePools,
+			VerticalPodAutoscalerAPI: vpaAPI,
+		}, holdBackFluentd))
+	}
+
+	// Resolve the images and render the objects of the components concurrently, before applying them in order.
+	components, err = imageset.RenderComponents(ctx, r.client, variant, components...)
+	if err != nil {
 		reqLogger.Error(err, "Error with images from ImageSet")
 		r.status.SetDegraded("Error with images from ImageSet", err.Error())
 		return reconcile.Result{}, err
@@ -543,43 +799,30 @@ func (r *ReconcileLogCollector) Reconcile(ctx context.Context, request reconcile
 		}
 	}
 
-	// Render a fluentd component for Windows if the cluster has Windows nodes.
-	hasWindowsNodes, err := hasWindowsNodes(r.client)
-	if err != nil {
+	// The pull secrets are copied by name, so a secret that is no longer referenced would otherwise stay behind.
+	if err := utils.DeleteStalePullSecretCopies(ctx, r.client, pullSecrets, render.LogCollectorNamespace); err != nil {
+		reqLogger.Error(err, "Failed to delete the stale pull secrets")
+		r.status.SetDegraded("Failed to delete the stale pull secrets", err.Error())
 		return reconcile.Result{}, err
 	}
 
-	if hasWindowsNodes {
-		fluentdCfg = &render.FluentdConfiguration{
-			LogCollector:    instance,
-			ESSecrets:       esSecrets,
-			ESClusterConfig: esClusterConfig,
-			S3Credential:    s3Credential,
-			SplkCredential:  splunkCredential,
-			Filters:         filters,
-			EKSConfig:       eksConfig,
-			PullSecrets:     pullSecrets,
-			Installation:    installation,
-			ClusterDomain:   r.clusterDomain,
-			OSType:          rmeta.OSTypeWindows,
-			TrustedBundle:   trustedBundle,
-			ManagedCluster:  managedCluster,
-			UsePSP:          r.usePSP,
-		}
-		comp = render.Fluentd(fluentdCfg)
-
-		if err = imageset.ApplyImageSet(ctx, r.client, variant, comp); err != nil {
-			reqLogger.Error(err, "Error with images from ImageSet")
-			r.status.SetDegraded("Error with images from ImageSet", err.Error())
-			return reconcile.Result{}, err
-		}
-
-		// Create a component handler to manage the rendered component.
-		handler = utils.NewComponentHandler(log, r.client, r.scheme, instance)
+	if holdBackFluentd {
+		log.Info("The index templates of the LogStorage are not applied yet, waiting until they are applied to deploy fluentd")
+		r.status.SetDegraded("Waiting for the index templates of the LogStorage to be applied", "")
+		return reconcile.Result{}, nil
+	}
 
-		if err := handler.CreateOrUpdateOrDelete(ctx, comp, r.status); err != nil {
-			r.status.SetDegraded("Error creating / updating resource", err.Error())
-			return reconcile.Result{}, err
+	if inPlaceResize {
+		for _, name := range []string{render.FluentdNodeName, render.FluentdNodeWindowsName} {
+			resized, err := r.resizeFluentdPods(ctx, name)
+			if err != nil {
+				reqLogger.Error(err, "Failed to resize the fluentd pods")
+				r.status.SetDegraded("Failed to resize the fluentd pods", err.Error())
+				return reconcile.Result{}, err
+			}
+			if !resized {
+				return reconcile.Result{RequeueAfter: 5 * time.Second}, nil
+			}
 		}
 	}
 
@@ -592,12 +835,30 @@ func (r *ReconcileLogCollector) Reconcile(ctx context.Context, request reconcile
 		return reconcile.Result{RequeueAfter: 30 * time.Second}, nil
 	}
 
+	var result reconcile.Result
+	instance.Status.ManagedClusters = nil
+	if managementCluster != nil {
+		// The status of the log collection of the managed clusters doesn't affect fluentd, so a failure to query it is
+		// only logged, and the last known statuses are reported.
+		if instance.Status.ManagedClusters, err = r.getManagedClusterLogStatuses(ctx); err != nil {
+			reqLogger.Error(err, "Failed to get the status of the log collection of the managed clusters")
+		}
+		result.RequeueAfter = managedClusterLogsInterval
+	}
+	if deferRollouts {
+		// Roll out the deferred changes once the rollout window opens.
+		reqLogger.V(1).Info("Deferring the rollouts of fluentd until the rollout window opens", "after", untilRolloutWindow)
+		if result.RequeueAfter == 0 || untilRolloutWindow < result.RequeueAfter {
+			result.RequeueAfter = untilRolloutWindow
+		}
+	}
+
 	// Everything is available - update the CR status.
 	instance.Status.State = operatorv1.TigeraStatusReady
 	if err = r.client.Status().Update(ctx, instance); err != nil {
 		return reconcile.Result{}, err
 	}
-	return reconcile.Result{}, nil
+	return result, nil
 }
 
 func hasWindowsNodes(c client.Client) (bool, error) {
@@ -610,6 +871,44 @@ func hasWindowsNodes(c client.Client) (bool, error) {
 	return len(nodes.Items) > 0, nil
 }
 
+// validateDisruptionPolicy returns an error if the disruption policy of fluentd has a PodDisruptionBudget, since fluentd
+// runs on every node and its pods aren't evicted by node drains.
+func validateDisruptionPolicy(policy *operatorv1.DisruptionPolicy) error {
+	if policy != nil && policy.PodDisruptionBudget != nil {
+		return fmt.Errorf("the disruption policy of Fluentd can't have a PodDisruptionBudget")
+	}
+	return nil
+}
+
+// validateNodePools returns an error if the names of the node pools are not unique.
+func validateNodePools(pools []operatorv1.FluentdNodePool) error {
+	names := map[string]bool{}
+	for _, pool := range pools {
+		if names[pool.Name] {
+			return fmt.Errorf("node pool %q is defined more than once", pool.Name)
+		}
+		names[pool.Name] = true
+	}
+	return nil
+}
+
+// getFluentdNodePools returns the names of the node pools that have fluentd DaemonSets in the cluster.
+func getFluentdNodePools(ctx context.Context, cli client.Client) ([]string, error) {
+	daemonSets := &appsv1.DaemonSetList{}
+	if err := cli.List(ctx, daemonSets, client.InNamespace(render.LogCollectorNamespace), client.HasLabels{render.FluentdNodePoolLabel}); err != nil {
+		return nil, err
+	}
+	names := map[string]bool{}
+	var pools []string
+	for _, ds := range daemonSets.Items {
+		if name := ds.Labels[render.FluentdNodePoolLabel]; !names[name] {
+			names[name] = true
+			pools = append(pools, name)
+		}
+	}
+	return pools, nil
+}
+
 func getS3Credential(client client.Client) (*render.S3Credential, error) {
 	secret := &corev1.Secret{}
 	secretNamespacedName := types.NamespacedName{
@@ -691,6 +990,87 @@ func getSplunkCredential(client client.Client) (*render.SplunkCredential, error)
 	}, nil
 }
 
+// fluentdComponent renders fluentd with the configuration, without its DaemonSets if holdBack is set, so that fluentd
+// isn't deployed while the other objects of the component are still applied.
+func fluentdComponent(cfg *render.FluentdConfiguration, holdBack bool) render.Component {
+	if holdBack {
+		return daemonSetHeldBackComponent{render.Fluentd(cfg)}
+	}
+	return render.Fluentd(cfg)
+}
+
+// daemonSetHeldBackComponent is a component without the DaemonSets that it creates.
+type daemonSetHeldBackComponent struct {
+	render.Component
+}
+
+func (c daemonSetHeldBackComponent) Objects() ([]client.Object, []client.Object) {
+	toCreate, toDelete := c.Component.Objects()
+	var objs []client.Object
+	for _, obj := range toCreate {
+		if _, ok := obj.(*appsv1.DaemonSet); !ok {
+			objs = append(objs, obj)
+		}
+	}
+	return objs, toDelete
+}
+
+// indexTemplatesPending returns true if the LogStorage overrides the index templates of the log indices and the
+// overrides aren't applied yet.
+func indexTemplatesPending(ls *operatorv1.LogStorage) bool {
+	return ls != nil && ls.DeletionTimestamp == nil && ls.Spec.IndexTemplates != nil &&
+		!meta.IsStatusConditionTrue(ls.Status.Conditions, operatorv1.LogStorageConditionIndexTemplatesApplied)
+}
+
+// logCollectorSecretPredicate filters the secrets of the operator namespace down to the ones that the LogCollector
+// refers to.
+func logCollectorSecretPredicate(cli client.Client) predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		if obj.GetNamespace() != common.OperatorNamespace() {
+			return false
+		}
+		instance := &operatorv1.LogCollector{}
+		if err := cli.Get(context.Background(), utils.DefaultTSEEInstanceKey, instance); err != nil {
+			return false
+		}
+		return logCollectorSecretNames(instance)[obj.GetName()]
+	})
+}
+
+// logCollectorSecretNames returns the names of the secrets in the operator namespace that the LogCollector refers to:
+// its pull secrets and the secrets of its additional stores.
+func logCollectorSecretNames(instance *operatorv1.LogCollector) map[string]bool {
+	names := map[string]bool{}
+	for _, ref := range instance.Spec.ImagePullSecrets {
+		names[ref.Name] = true
+	}
+	stores := instance.Spec.AdditionalStores
+	if stores == nil {
+		return names
+	}
+	if stores.Splunk != nil && stores.Splunk.TLS != nil && stores.Splunk.TLS.CASecret != nil {
+		names[stores.Splunk.TLS.CASecret.Name] = true
+	}
+	return names
+}
+
+// getAdditionalStoreCA returns the CA bundle of an additional log store from the secret of its TLS configuration, or nil
+// if the store verifies its certificate with the trusted bundle.
+func getAdditionalStoreCA(client client.Client, tls *operatorv1.AdditionalStoreTLS) ([]byte, error) {
+	if tls == nil || tls.CASecret == nil {
+		return nil, nil
+	}
+	s := &corev1.Secret{}
+	if err := client.Get(context.Background(), types.NamespacedName{Name: tls.CASecret.Name, Namespace: common.OperatorNamespace()}, s); err != nil {
+		return nil, fmt.Errorf("Failed to read secret %q: %s", tls.CASecret.Name, err)
+	}
+	bundle, ok := s.Data[tls.CASecret.Key]
+	if !ok || len(bundle) == 0 {
+		return nil, fmt.Errorf("Expected secret %q to have a field named %q", tls.CASecret.Name, tls.CASecret.Key)
+	}
+	return bundle, nil
+}
+
 func getFluentdFilters(client client.Client) (*render.FluentdFilters, error) {
 	cm := &corev1.ConfigMap{}
 	cmNamespacedName := types.NamespacedName{
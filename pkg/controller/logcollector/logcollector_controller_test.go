@@ -17,6 +17,7 @@ package logcollector
 import (
 	"context"
 	"fmt"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -27,15 +28,23 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllertest"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
 	operatorv1 "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/apis"
+	vpav1 "github.com/tigera/operator/pkg/apis/autoscaling.k8s.io/v1"
 	"github.com/tigera/operator/pkg/common"
 	"github.com/tigera/operator/pkg/components"
 	"github.com/tigera/operator/pkg/controller/certificatemanager"
@@ -402,6 +411,16 @@ var _ = Describe("LogCollector controller tests", func() {
 				Expect(node.Env).To(ContainElements(splunkVars))
 			})
 
+			It("should return an error when the Splunk endpoint is invalid", func() {
+				instance := &operatorv1.LogCollector{}
+				Expect(c.Get(ctx, types.NamespacedName{Name: "tigera-secure"}, instance)).NotTo(HaveOccurred())
+				instance.Spec.AdditionalStores.Splunk.Endpoint = "https://otherhost"
+				Expect(c.Update(ctx, instance)).NotTo(HaveOccurred())
+
+				_, err := GetLogCollector(ctx, c)
+				Expect(err).To(MatchError("Splunk config has invalid Endpoint: invalid host: otherhost"))
+			})
+
 			Context("Disable feature via license", func() {
 				BeforeEach(func() {
 					By("Deleting the previous license")
@@ -537,6 +556,348 @@ var _ = Describe("LogCollector controller tests", func() {
 		})
 	})
 
+	Context("vertical pod autoscaling", func() {
+		BeforeEach(func() {
+			lc := &operatorv1.LogCollector{}
+			Expect(c.Get(ctx, types.NamespacedName{Name: "tigera-secure"}, lc)).NotTo(HaveOccurred())
+			lc.Spec.VerticalPodAutoscaling = &operatorv1.LogCollectorVerticalPodAutoscaling{Mode: operatorv1.VerticalPodAutoscalingInitial}
+			Expect(c.Update(ctx, lc)).NotTo(HaveOccurred())
+		})
+
+		It("should degrade if the VerticalPodAutoscaler API is not installed", func() {
+			mockStatus.On("SetDegraded", "VerticalPodAutoscaling is configured but the VerticalPodAutoscaler API is not installed", "").Return()
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).ShouldNot(HaveOccurred())
+			mockStatus.AssertExpectations(GinkgoT())
+		})
+
+		It("should create a VerticalPodAutoscaler for fluentd", func() {
+			clientset := kfake.NewSimpleClientset()
+			clientset.Resources = []*metav1.APIResourceList{{
+				GroupVersion: "autoscaling.k8s.io/v1",
+				APIResources: []metav1.APIResource{{Name: "verticalpodautoscalers", Kind: "VerticalPodAutoscaler"}},
+			}}
+			r.clientset = clientset
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			vpa := &vpav1.VerticalPodAutoscaler{}
+			Expect(c.Get(ctx, types.NamespacedName{Name: render.FluentdNodeName, Namespace: render.LogCollectorNamespace}, vpa)).NotTo(HaveOccurred())
+			Expect(*vpa.Spec.UpdatePolicy.UpdateMode).To(Equal(vpav1.UpdateModeInitial))
+		})
+	})
+
+	Context("index templates", func() {
+		BeforeEach(func() {
+			Expect(c.Create(ctx, &operatorv1.LogStorage{
+				ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"},
+				Spec:       operatorv1.LogStorageSpec{IndexTemplates: &operatorv1.IndexTemplates{ConfigMapName: "index-templates"}},
+			})).NotTo(HaveOccurred())
+		})
+
+		It("should not deploy fluentd until the index templates of the LogStorage are first applied", func() {
+			mockStatus.On("SetDegraded", "Waiting for the index templates of the LogStorage to be applied", "").Return()
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).ShouldNot(HaveOccurred())
+			mockStatus.AssertExpectations(GinkgoT())
+			err = c.Get(ctx, types.NamespacedName{Name: render.FluentdNodeName, Namespace: render.LogCollectorNamespace}, &appsv1.DaemonSet{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+			Expect(c.Get(ctx, types.NamespacedName{Name: render.LogCollectorNamespace}, &corev1.Namespace{})).NotTo(HaveOccurred())
+
+			ls := &operatorv1.LogStorage{}
+			Expect(c.Get(ctx, types.NamespacedName{Name: "tigera-secure"}, ls)).NotTo(HaveOccurred())
+			ls.Status.Conditions = []metav1.Condition{{
+				Type:               operatorv1.LogStorageConditionIndexTemplatesApplied,
+				Status:             metav1.ConditionTrue,
+				Reason:             "Applied",
+				LastTransitionTime: metav1.Now(),
+			}}
+			Expect(c.Status().Update(ctx, ls)).NotTo(HaveOccurred())
+
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(c.Get(ctx, types.NamespacedName{Name: render.FluentdNodeName, Namespace: render.LogCollectorNamespace}, &appsv1.DaemonSet{})).NotTo(HaveOccurred())
+
+			By("keeping fluentd deployed while the index templates are applied again")
+			Expect(c.Get(ctx, types.NamespacedName{Name: "tigera-secure"}, ls)).NotTo(HaveOccurred())
+			ls.Status.Conditions[0].Status = metav1.ConditionFalse
+			Expect(c.Status().Update(ctx, ls)).NotTo(HaveOccurred())
+
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(c.Get(ctx, types.NamespacedName{Name: render.FluentdNodeName, Namespace: render.LogCollectorNamespace}, &appsv1.DaemonSet{})).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("node pools", func() {
+		BeforeEach(func() {
+			lc := &operatorv1.LogCollector{}
+			Expect(c.Get(ctx, types.NamespacedName{Name: "tigera-secure"}, lc)).NotTo(HaveOccurred())
+			lc.Spec.NodePools = []operatorv1.FluentdNodePool{{Name: "gpu", NodeSelector: map[string]string{"pool": "gpu"}}}
+			Expect(c.Update(ctx, lc)).NotTo(HaveOccurred())
+		})
+
+		It("should create and delete the DaemonSets of the node pools", func() {
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).ShouldNot(HaveOccurred())
+			ds := &appsv1.DaemonSet{}
+			Expect(c.Get(ctx, types.NamespacedName{Name: "fluentd-node-pool-gpu", Namespace: render.LogCollectorNamespace}, ds)).NotTo(HaveOccurred())
+			Expect(ds.Labels).To(HaveKeyWithValue(render.FluentdNodePoolLabel, "gpu"))
+
+			lc := &operatorv1.LogCollector{}
+			Expect(c.Get(ctx, types.NamespacedName{Name: "tigera-secure"}, lc)).NotTo(HaveOccurred())
+			lc.Spec.NodePools = nil
+			Expect(c.Update(ctx, lc)).NotTo(HaveOccurred())
+
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).ShouldNot(HaveOccurred())
+			err = c.Get(ctx, types.NamespacedName{Name: "fluentd-node-pool-gpu", Namespace: render.LogCollectorNamespace}, ds)
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should degrade if a node pool is defined more than once", func() {
+			lc := &operatorv1.LogCollector{}
+			Expect(c.Get(ctx, types.NamespacedName{Name: "tigera-secure"}, lc)).NotTo(HaveOccurred())
+			lc.Spec.NodePools = append(lc.Spec.NodePools, lc.Spec.NodePools[0])
+			Expect(c.Update(ctx, lc)).NotTo(HaveOccurred())
+			mockStatus.On("SetDegraded", "Invalid node pools", `node pool "gpu" is defined more than once`).Return()
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).ShouldNot(HaveOccurred())
+			mockStatus.AssertExpectations(GinkgoT())
+		})
+	})
+
+	Context("managed clusters", func() {
+		It("should report the log collection of the managed clusters by name", func() {
+			now := time.Now()
+			connected := v3.ManagedClusterStatus{Conditions: []v3.ManagedClusterStatusCondition{
+				{Type: v3.ManagedClusterStatusTypeConnected, Status: v3.ManagedClusterStatusValueTrue},
+			}}
+			clusters := []v3.ManagedCluster{
+				{ObjectMeta: metav1.ObjectMeta{Name: "stale"}, Status: connected},
+				{ObjectMeta: metav1.ObjectMeta{Name: "disconnected"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "healthy"}, Status: connected},
+				{ObjectMeta: metav1.ObjectMeta{Name: "no-logs"}, Status: connected},
+			}
+			lastFlowLogTimes := map[string]time.Time{
+				"stale":        now.Add(-time.Hour),
+				"disconnected": now.Add(-time.Minute),
+				"healthy":      now.Add(-time.Minute),
+			}
+
+			statuses := managedClusterLogStatuses(clusters, lastFlowLogTimes, now)
+			Expect(statuses).To(Equal([]operatorv1.ManagedClusterLogCollectorStatus{
+				{Name: "disconnected", Connected: false, LastFlowLogTime: &metav1.Time{Time: now.Add(-time.Minute)}, Healthy: false},
+				{Name: "healthy", Connected: true, LastFlowLogTime: &metav1.Time{Time: now.Add(-time.Minute)}, Healthy: true},
+				{Name: "no-logs", Connected: true, Healthy: false},
+				{Name: "stale", Connected: true, LastFlowLogTime: &metav1.Time{Time: now.Add(-time.Hour)}, Healthy: false},
+			}))
+		})
+	})
+
+	Context("managed cluster logs cache", func() {
+		It("should only query the flow logs once per interval and keep the last result on errors", func() {
+			var cache managedClusterLogsCache
+			now := time.Now()
+			queries := 0
+			lastFlowLogTimes := map[string]time.Time{"healthy": now}
+			query := func() (map[string]time.Time, error) {
+				queries++
+				return lastFlowLogTimes, nil
+			}
+			failingQuery := func() (map[string]time.Time, error) {
+				queries++
+				return nil, fmt.Errorf("elasticsearch is unavailable")
+			}
+
+			Expect(cache.get(now, query)).To(Equal(lastFlowLogTimes))
+			Expect(cache.get(now.Add(time.Minute), query)).To(Equal(lastFlowLogTimes))
+			Expect(queries).To(Equal(1))
+
+			times, err := cache.get(now.Add(managedClusterLogsInterval), failingQuery)
+			Expect(err).To(HaveOccurred())
+			Expect(times).To(Equal(lastFlowLogTimes))
+			Expect(queries).To(Equal(2))
+			Expect(cache.get(now.Add(managedClusterLogsInterval+time.Minute), query)).To(Equal(lastFlowLogTimes))
+			Expect(queries).To(Equal(2))
+		})
+	})
+
+	Context("container logs", func() {
+		createNode := func(name, runtimeVersion string) {
+			Expect(c.Create(ctx, &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: name},
+				Status:     corev1.NodeStatus{NodeInfo: corev1.NodeSystemInfo{ContainerRuntimeVersion: runtimeVersion}},
+			})).NotTo(HaveOccurred())
+		}
+
+		It("should not mount the log files of the containers unless the LogCollector opts in", func() {
+			createNode("node1", "containerd://1.6.8")
+
+			containerLogs, err := r.getContainerLogs(ctx, &operatorv1.LogCollector{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(containerLogs).To(BeNil())
+		})
+
+		It("should use the runtime and base path of the LogCollector", func() {
+			createNode("node1", "cri-o://1.24.2")
+			docker := operatorv1.ContainerRuntimeDocker
+			logCollector := &operatorv1.LogCollector{Spec: operatorv1.LogCollectorSpec{
+				ContainerLogs: &operatorv1.ContainerLogs{Runtime: &docker, BasePath: "/data/docker/containers"},
+			}}
+
+			containerLogs, err := r.getContainerLogs(ctx, logCollector)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(containerLogs).To(Equal(logCollector.Spec.ContainerLogs))
+		})
+
+		It("should detect the runtime when the LogCollector only sets the base path", func() {
+			createNode("node1", "cri-o://1.24.2")
+			logCollector := &operatorv1.LogCollector{Spec: operatorv1.LogCollectorSpec{
+				ContainerLogs: &operatorv1.ContainerLogs{BasePath: "/data/pods"},
+			}}
+
+			containerLogs, err := r.getContainerLogs(ctx, logCollector)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*containerLogs.Runtime).To(Equal(operatorv1.ContainerRuntimeCRIO))
+			Expect(containerLogs.BasePath).To(Equal("/data/pods"))
+		})
+
+		It("should default the runtime on the nodes of a Cluster API infrastructure provider", func() {
+			createNode("node1", "")
+			logCollector := &operatorv1.LogCollector{Spec: operatorv1.LogCollectorSpec{
+				ContainerLogs: &operatorv1.ContainerLogs{},
+			}}
+
+			containerLogs, err := r.getContainerLogs(ctx, logCollector)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(containerLogs).To(BeNil())
+
+			r.infrastructureProvider = common.InfrastructureProviderVSphere
+			containerLogs, err = r.getContainerLogs(ctx, logCollector)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*containerLogs.Runtime).To(Equal(operatorv1.ContainerRuntimeContainerd))
+		})
+
+		It("should return an error when the nodes run different runtimes", func() {
+			createNode("node1", "docker://20.10.17")
+			createNode("node2", "containerd://1.6.8")
+			logCollector := &operatorv1.LogCollector{Spec: operatorv1.LogCollectorSpec{
+				ContainerLogs: &operatorv1.ContainerLogs{},
+			}}
+
+			_, err := r.getContainerLogs(ctx, logCollector)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should only detect the runtime once per interval", func() {
+			var cache containerRuntimeCache
+			now := time.Now()
+			detections := 0
+			detect := func() (operatorv1.ContainerRuntime, error) {
+				detections++
+				return operatorv1.ContainerRuntimeContainerd, nil
+			}
+
+			Expect(cache.get(now, detect)).To(Equal(operatorv1.ContainerRuntimeContainerd))
+			Expect(cache.get(now.Add(time.Minute), detect)).To(Equal(operatorv1.ContainerRuntimeContainerd))
+			Expect(detections).To(Equal(1))
+			Expect(cache.get(now.Add(containerRuntimeInterval), detect)).To(Equal(operatorv1.ContainerRuntimeContainerd))
+			Expect(detections).To(Equal(2))
+		})
+	})
+
+	Context("additional store secrets", func() {
+		BeforeEach(func() {
+			lc := &operatorv1.LogCollector{}
+			Expect(c.Get(ctx, types.NamespacedName{Name: "tigera-secure"}, lc)).NotTo(HaveOccurred())
+			lc.Spec.AdditionalStores = &operatorv1.AdditionalLogStoreSpec{
+				Splunk: &operatorv1.SplunkStoreSpec{
+					Endpoint: "https://1.2.3.4:8088",
+					TLS: &operatorv1.AdditionalStoreTLS{
+						CASecret: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "splunk-ca"}, Key: "ca.crt"},
+					},
+				},
+			}
+			Expect(c.Update(ctx, lc)).NotTo(HaveOccurred())
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: render.SplunkFluentdTokenSecretName, Namespace: common.OperatorNamespace()},
+				Data:       map[string][]byte{render.SplunkFluentdSecretTokenKey: []byte("token")},
+			})).NotTo(HaveOccurred())
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "splunk-ca", Namespace: common.OperatorNamespace()},
+				Data:       map[string][]byte{"ca.crt": []byte("ca-1")},
+			})).NotTo(HaveOccurred())
+		})
+
+		// watchSecret passes an update of the secret through the watch of the secrets of the additional stores, and
+		// returns the requests that it enqueued.
+		watchSecret := func(secret *corev1.Secret) []reconcile.Request {
+			q := controllertest.Queue{Interface: workqueue.New()}
+			evt := event.UpdateEvent{ObjectOld: secret, ObjectNew: secret}
+			if logCollectorSecretPredicate(c).Update(evt) {
+				(&handler.EnqueueRequestForObject{}).Update(evt, q)
+			}
+			var requests []reconcile.Request
+			for q.Len() > 0 {
+				item, _ := q.Get()
+				requests = append(requests, item.(reconcile.Request))
+				q.Done(item)
+			}
+			return requests
+		}
+
+		It("should watch the secrets that the additional stores refer to", func() {
+			lc := &operatorv1.LogCollector{}
+			Expect(c.Get(ctx, types.NamespacedName{Name: "tigera-secure"}, lc)).NotTo(HaveOccurred())
+			Expect(logCollectorSecretNames(lc)).To(Equal(map[string]bool{"splunk-ca": true}))
+		})
+
+		It("should not reconcile when a secret that the additional stores don't refer to changes", func() {
+			Expect(watchSecret(&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: common.OperatorNamespace()},
+			})).To(BeEmpty())
+			Expect(watchSecret(&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "splunk-ca", Namespace: "other"},
+			})).To(BeEmpty())
+		})
+
+		It("should watch the pull secrets of the LogCollector", func() {
+			lc := &operatorv1.LogCollector{}
+			Expect(c.Get(ctx, types.NamespacedName{Name: "tigera-secure"}, lc)).NotTo(HaveOccurred())
+			lc.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "fluentd-registry"}}
+			Expect(logCollectorSecretNames(lc)).To(Equal(map[string]bool{"splunk-ca": true, "fluentd-registry": true}))
+		})
+
+		It("should roll fluentd when the CA bundle of a store rotates", func() {
+			annotation := "hash.operator.tigera.io/" + render.SplunkFluentdCASecretName
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).ShouldNot(HaveOccurred())
+			ds := &appsv1.DaemonSet{}
+			Expect(c.Get(ctx, types.NamespacedName{Name: "fluentd-node", Namespace: render.LogCollectorNamespace}, ds)).NotTo(HaveOccurred())
+			hash := ds.Spec.Template.Annotations[annotation]
+			Expect(hash).NotTo(BeEmpty())
+
+			secret := &corev1.Secret{}
+			Expect(c.Get(ctx, types.NamespacedName{Name: "splunk-ca", Namespace: common.OperatorNamespace()}, secret)).NotTo(HaveOccurred())
+			secret.Data["ca.crt"] = []byte("ca-2")
+			Expect(c.Update(ctx, secret)).NotTo(HaveOccurred())
+
+			requests := watchSecret(secret)
+			Expect(requests).To(Equal([]reconcile.Request{
+				{NamespacedName: types.NamespacedName{Name: "splunk-ca", Namespace: common.OperatorNamespace()}},
+			}))
+			_, err = r.Reconcile(ctx, requests[0])
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(c.Get(ctx, types.NamespacedName{Name: "fluentd-node", Namespace: render.LogCollectorNamespace}, ds)).NotTo(HaveOccurred())
+			Expect(ds.Spec.Template.Annotations[annotation]).NotTo(Equal(hash))
+		})
+	})
+
 	Context("allow-tigera reconciliation", func() {
 		var readyFlag *utils.ReadyFlag
 
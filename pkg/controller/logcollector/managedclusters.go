@@ -0,0 +1,126 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logcollector
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
+	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+)
+
+const (
+	// managedClusterLogsInterval is how often the end times of the most recent flow logs of the managed clusters are
+	// queried. The query aggregates the flow logs of the last day, so it isn't run on every reconcile.
+	managedClusterLogsInterval = 5 * time.Minute
+
+	// managedClusterLogsTimeout bounds the query of the end times of the most recent flow logs.
+	managedClusterLogsTimeout = 30 * time.Second
+
+	// managedClusterLogsStaleAfter is how long after its most recent flow log the log collection of a managed cluster
+	// is unhealthy. Fluentd flushes the flow logs every five minutes by default.
+	managedClusterLogsStaleAfter = 15 * time.Minute
+)
+
+// managedClusterConnected returns whether the managed cluster is connected to the management cluster through Guardian.
+func managedClusterConnected(mc *v3.ManagedCluster) bool {
+	for _, c := range mc.Status.Conditions {
+		if c.Type == v3.ManagedClusterStatusTypeConnected {
+			return c.Status == v3.ManagedClusterStatusValueTrue
+		}
+	}
+	return false
+}
+
+// managedClusterLogStatuses returns the status of the log collection of the managed clusters, sorted by name, from
+// their connection status and the end time of their most recent flow log.
+func managedClusterLogStatuses(clusters []v3.ManagedCluster, lastFlowLogTimes map[string]time.Time, now time.Time) []operatorv1.ManagedClusterLogCollectorStatus {
+	var statuses []operatorv1.ManagedClusterLogCollectorStatus
+	for i := range clusters {
+		mc := &clusters[i]
+		status := operatorv1.ManagedClusterLogCollectorStatus{
+			Name:      mc.Name,
+			Connected: managedClusterConnected(mc),
+		}
+		if last, ok := lastFlowLogTimes[mc.Name]; ok {
+			status.LastFlowLogTime = &metav1.Time{Time: last}
+			status.Healthy = status.Connected && now.Sub(last) <= managedClusterLogsStaleAfter
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// managedClusterLogsCache holds the end times of the most recent flow logs of the managed clusters, as of the last
+// query.
+type managedClusterLogsCache struct {
+	lock             sync.Mutex
+	lastFlowLogTimes map[string]time.Time
+	queriedAt        time.Time
+}
+
+// get returns the end times of the most recent flow logs of the managed clusters, querying them if the cache is older
+// than the interval. If the query fails, the cache is returned along with the error, and the query is retried after
+// the interval.
+func (c *managedClusterLogsCache) get(now time.Time, query func() (map[string]time.Time, error)) (map[string]time.Time, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if now.Sub(c.queriedAt) < managedClusterLogsInterval {
+		return c.lastFlowLogTimes, nil
+	}
+	c.queriedAt = now
+	lastFlowLogTimes, err := query()
+	if err != nil {
+		return c.lastFlowLogTimes, err
+	}
+	c.lastFlowLogTimes = lastFlowLogTimes
+	return lastFlowLogTimes, nil
+}
+
+// getManagedClusterLogStatuses returns the status of the log collection of the managed clusters of this management
+// cluster. The flow logs of the managed clusters are shipped through Guardian to the Elasticsearch cluster of the
+// management cluster, so a managed cluster whose flow logs are no longer indexed has broken log shipping.
+//
+// The end times of the most recent flow logs are cached between queries. If Elasticsearch can't be queried, the
+// statuses are returned from the cache along with the error.
+func (r *ReconcileLogCollector) getManagedClusterLogStatuses(ctx context.Context) ([]operatorv1.ManagedClusterLogCollectorStatus, error) {
+	clusters := &v3.ManagedClusterList{}
+	if err := r.client.List(ctx, clusters); err != nil {
+		return nil, err
+	}
+	if len(clusters.Items) == 0 {
+		return nil, nil
+	}
+
+	now := time.Now()
+	lastFlowLogTimes, err := r.managedClusterLogs.get(now, func() (map[string]time.Time, error) {
+		ctx, cancel := context.WithTimeout(ctx, managedClusterLogsTimeout)
+		defer cancel()
+		esClient, err := r.esCliCreator(r.client, ctx, relasticsearch.HTTPSEndpoint(rmeta.OSTypeLinux, r.clusterDomain))
+		if err != nil {
+			return nil, err
+		}
+		return esClient.LastFlowLogTimes(ctx)
+	})
+	return managedClusterLogStatuses(clusters.Items, lastFlowLogTimes, now), err
+}
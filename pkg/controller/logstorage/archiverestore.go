@@ -0,0 +1,352 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/controller/utils"
+	"github.com/tigera/operator/pkg/controller/utils/imageset"
+	"github.com/tigera/operator/pkg/crypto"
+	"github.com/tigera/operator/pkg/render"
+	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
+	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/tls/certificatemanagement"
+)
+
+// archiveRestoreUserRole is the Elasticsearch role of the user of the archive restore jobs. It can read the mappings of
+// the flow log indices, and create, write, refresh and delete the indices of the restores.
+var archiveRestoreUserRole = map[string]interface{}{
+	"indices": []interface{}{
+		map[string]interface{}{
+			"names":      []string{"tigera_secure_ee_flows*"},
+			"privileges": []string{"view_index_metadata"},
+		},
+		map[string]interface{}{
+			"names":      []string{render.ArchiveRestoreIndexPrefix + "*"},
+			"privileges": []string{"create_index", "delete_index", "write", "maintenance"},
+		},
+	},
+}
+
+// archiveRestoreTTL returns how long the index of the restore is kept after the restore completes.
+func archiveRestoreTTL(restore operatorv1.ArchiveRestore) time.Duration {
+	if restore.TTL != nil && restore.TTL.Duration > 0 {
+		return restore.TTL.Duration
+	}
+	return render.DefaultArchiveRestoreTTL
+}
+
+// archiveRestoreS3Credential returns the credentials of the S3 store of the LogCollector.
+func archiveRestoreS3Credential(ctx context.Context, cli client.Client) (*render.S3Credential, error) {
+	secret := &corev1.Secret{}
+	if err := cli.Get(ctx, client.ObjectKey{Name: render.S3FluentdSecretName, Namespace: common.OperatorNamespace()}, secret); err != nil {
+		return nil, err
+	}
+	credential := &render.S3Credential{KeyId: secret.Data[render.S3KeyIdName], KeySecret: secret.Data[render.S3KeySecretName]}
+	if len(credential.KeyId) == 0 || len(credential.KeySecret) == 0 {
+		return nil, fmt.Errorf("expected secret %q to have the fields %q and %q", render.S3FluentdSecretName, render.S3KeyIdName, render.S3KeySecretName)
+	}
+	return credential, nil
+}
+
+// nextArchiveRestoreStatus returns the state of the restore at the given time, given its current state and its job,
+// which is nil if it doesn't exist.
+func nextArchiveRestoreStatus(restore operatorv1.ArchiveRestore, current operatorv1.ArchiveRestoreStatus, job *batchv1.Job, now time.Time) operatorv1.ArchiveRestoreStatus {
+	next := current
+	next.Name = restore.Name
+	next.Index = render.ArchiveRestoreIndex(restore)
+	switch {
+	case current.Phase == operatorv1.ArchiveRestoreExpired:
+	case current.Phase == operatorv1.ArchiveRestoreSucceeded:
+		if current.ExpirationTime != nil && !now.Before(current.ExpirationTime.Time) {
+			next.Phase = operatorv1.ArchiveRestoreExpired
+		}
+	case job == nil:
+		// A restore that failed isn't run again unless it is changed, since it would restore the same flow logs.
+		if current.Phase != operatorv1.ArchiveRestoreFailed {
+			next.Phase = operatorv1.ArchiveRestoreRunning
+		}
+	case jobCondition(job, batchv1.JobComplete) != nil:
+		completed := jobCondition(job, batchv1.JobComplete).LastTransitionTime
+		expiration := metav1.NewTime(completed.Add(archiveRestoreTTL(restore)))
+		next.Phase = operatorv1.ArchiveRestoreSucceeded
+		next.CompletionTime = &completed
+		next.ExpirationTime = &expiration
+		if !now.Before(expiration.Time) {
+			next.Phase = operatorv1.ArchiveRestoreExpired
+		}
+	case jobCondition(job, batchv1.JobFailed) != nil:
+		next.Phase = operatorv1.ArchiveRestoreFailed
+	default:
+		next.Phase = operatorv1.ArchiveRestoreRunning
+	}
+	return next
+}
+
+// restoreArchives runs a job for each restore of the archived flow logs in the LogStorage, and tracks their states in
+// the status of the LogStorage, which is updated at the end of the reconciliation. The indices of the restores are
+// deleted once they expire or the restores are removed from the LogStorage. The returned result requeues the request
+// for the next expiration.
+//
+// It returns a reconcile.Result, a 'proceed' bool indicating if the Reconcile function should proceed with the
+// reconcile process, and an error.
+func (r *ReconcileLogStorage) restoreArchives(
+	ls *operatorv1.LogStorage,
+	install *operatorv1.InstallationSpec,
+	variant operatorv1.ProductVariant,
+	pullSecrets []*corev1.Secret,
+	trustedBundle certificatemanagement.TrustedBundle,
+	logCollector *operatorv1.LogCollector,
+	hdler utils.ComponentHandler,
+	reqLogger logr.Logger,
+	ctx context.Context,
+) (reconcile.Result, bool, error) {
+	var restores []operatorv1.ArchiveRestore
+	if ls != nil && ls.DeletionTimestamp == nil {
+		restores = ls.Spec.ArchiveRestores
+	}
+
+	cfg := &render.ArchiveRestoreConfiguration{
+		LogStorage:    ls,
+		Installation:  install,
+		PullSecrets:   pullSecrets,
+		TrustedBundle: trustedBundle,
+		Provider:      r.provider,
+		ClusterDomain: r.clusterDomain,
+	}
+	if len(restores) > 0 {
+		if logCollector == nil || logCollector.Spec.AdditionalStores == nil || logCollector.Spec.AdditionalStores.S3 == nil {
+			r.status.SetDegraded("Invalid archive restores", "the LogCollector doesn't archive the flow logs to S3")
+			return reconcile.Result{}, false, nil
+		}
+		credential, err := archiveRestoreS3Credential(ctx, r.client)
+		if err != nil {
+			reqLogger.Error(err, "Failed to get the S3 credentials of the LogCollector")
+			r.status.SetDegraded("Invalid archive restores", err.Error())
+			return reconcile.Result{}, false, nil
+		}
+		cfg.S3, cfg.S3Credential = logCollector.Spec.AdditionalStores.S3, credential
+	}
+
+	userSecret, err := utils.GetSecret(ctx, r.client, render.ArchiveRestoreUserSecret, common.OperatorNamespace())
+	if err != nil {
+		reqLogger.Error(err, "Failed to get the Elasticsearch user secret of the archive restore jobs")
+		r.status.SetDegraded("Failed to get the Elasticsearch user secret of the archive restore jobs", err.Error())
+		return reconcile.Result{}, false, err
+	}
+	if len(restores) > 0 && userSecret == nil {
+		userSecret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      render.ArchiveRestoreUserSecret,
+				Namespace: common.OperatorNamespace(),
+			},
+			Data: map[string][]byte{
+				"username": []byte(render.ArchiveRestoreUserName),
+				"password": []byte(crypto.GeneratePassword(16)),
+			},
+		}
+	}
+	// The user is deleted together with its secret once there are no restores left. It goes away with Elasticsearch
+	// when the LogStorage is removed.
+	if userSecret != nil && ls != nil && ls.DeletionTimestamp == nil {
+		if result, proceed, err := r.applyArchiveRestoreUser(len(restores) > 0, userSecret, reqLogger, ctx); err != nil || !proceed {
+			return result, proceed, err
+		}
+	}
+	cfg.UserSecret = userSecret
+
+	current := map[string]operatorv1.ArchiveRestoreStatus{}
+	if ls != nil {
+		for _, status := range ls.Status.ArchiveRestores {
+			current[status.Name] = status
+		}
+	}
+	jobs := map[string]*batchv1.Job{}
+	if len(restores) > 0 || len(current) > 0 {
+		jobList := &batchv1.JobList{}
+		if err := r.client.List(ctx, jobList, client.InNamespace(render.ElasticsearchNamespace), client.MatchingLabels{"k8s-app": render.ArchiveRestoreName}); err != nil {
+			reqLogger.Error(err, "Failed to list the archive restore jobs")
+			r.status.SetDegraded("Failed to list the archive restore jobs", err.Error())
+			return reconcile.Result{}, false, err
+		}
+		for i := range jobList.Items {
+			jobs[jobList.Items[i].Name] = &jobList.Items[i]
+		}
+	}
+
+	var esClient utils.ElasticClient
+	deleteIndex := func(index string) error {
+		if esClient == nil {
+			var err error
+			if esClient, err = r.esCliCreator(r.client, ctx, relasticsearch.HTTPSEndpoint(rmeta.OSTypeLinux, r.clusterDomain)); err != nil {
+				return err
+			}
+		}
+		return esClient.DeleteIndex(ctx, index)
+	}
+	deleteJob := func(job *batchv1.Job) error {
+		if err := r.client.Delete(ctx, job, client.PropagationPolicy("Background")); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+
+	now := time.Now()
+	var requeueAfter time.Duration
+	var statuses []operatorv1.ArchiveRestoreStatus
+	var running []operatorv1.ArchiveRestore
+	for _, restore := range restores {
+		job := jobs[render.ArchiveRestoreJobName(restore)]
+		delete(jobs, render.ArchiveRestoreJobName(restore))
+
+		status := current[restore.Name]
+		if job != nil && job.Spec.Template.Annotations[render.ArchiveRestoreHashAnnotation] != render.ArchiveRestoreHash(cfg, restore) {
+			// Jobs can't be updated, so the restore is run again by a new job once the job of the previous period is
+			// deleted.
+			if err := deleteJob(job); err != nil {
+				reqLogger.Error(err, "Failed to delete the archive restore job", "name", restore.Name)
+				r.status.SetDegraded("Failed to delete the archive restore job", err.Error())
+				return reconcile.Result{}, false, err
+			}
+			statuses = append(statuses, operatorv1.ArchiveRestoreStatus{
+				Name:  restore.Name,
+				Index: render.ArchiveRestoreIndex(restore),
+				Phase: operatorv1.ArchiveRestoreRunning,
+			})
+			requeueAfter = minRequeueAfter(requeueAfter, upgradePreflightPollInterval)
+			continue
+		}
+
+		next := nextArchiveRestoreStatus(restore, status, job, now)
+		switch next.Phase {
+		case operatorv1.ArchiveRestoreExpired:
+			if status.Phase != operatorv1.ArchiveRestoreExpired {
+				reqLogger.Info("Deleting the index of the expired archive restore", "name", restore.Name, "index", next.Index)
+				if err := deleteIndex(next.Index); err != nil {
+					reqLogger.Error(err, "Failed to delete the index of the archive restore", "name", restore.Name)
+					r.status.SetDegraded("Failed to delete the index of the archive restore", err.Error())
+					return reconcile.Result{}, false, err
+				}
+			}
+			if job != nil {
+				if err := deleteJob(job); err != nil {
+					reqLogger.Error(err, "Failed to delete the archive restore job", "name", restore.Name)
+					r.status.SetDegraded("Failed to delete the archive restore job", err.Error())
+					return reconcile.Result{}, false, err
+				}
+			}
+		case operatorv1.ArchiveRestoreSucceeded:
+			requeueAfter = minRequeueAfter(requeueAfter, next.ExpirationTime.Sub(now))
+			if job != nil {
+				running = append(running, restore)
+			}
+		case operatorv1.ArchiveRestoreFailed:
+			if job != nil {
+				running = append(running, restore)
+			}
+		default:
+			running = append(running, restore)
+		}
+		statuses = append(statuses, next)
+	}
+
+	// The indices and the jobs of the restores that were removed from the LogStorage are deleted.
+	for name, status := range current {
+		removed := true
+		for _, restore := range restores {
+			removed = removed && restore.Name != name
+		}
+		if removed && status.Phase != operatorv1.ArchiveRestoreExpired {
+			reqLogger.Info("Deleting the index of the removed archive restore", "name", name, "index", status.Index)
+			if err := deleteIndex(status.Index); err != nil {
+				reqLogger.Error(err, "Failed to delete the index of the archive restore", "name", name)
+				r.status.SetDegraded("Failed to delete the index of the archive restore", err.Error())
+				return reconcile.Result{}, false, err
+			}
+		}
+	}
+	for _, job := range jobs {
+		if err := deleteJob(job); err != nil {
+			reqLogger.Error(err, "Failed to delete the archive restore job", "name", job.Name)
+			r.status.SetDegraded("Failed to delete the archive restore job", err.Error())
+			return reconcile.Result{}, false, err
+		}
+	}
+
+	cfg.Restores = running
+	archiveRestoreComponent := render.ArchiveRestore(cfg)
+	if err := imageset.ApplyImageSet(ctx, r.client, variant, archiveRestoreComponent); err != nil {
+		reqLogger.Error(err, "Error with images from ImageSet")
+		r.status.SetDegraded("Error with images from ImageSet", err.Error())
+		return reconcile.Result{}, false, err
+	}
+	if err := hdler.CreateOrUpdateOrDelete(ctx, archiveRestoreComponent, r.status); err != nil {
+		reqLogger.Error(err, "Error creating / updating resource")
+		r.status.SetDegraded("Error creating / updating resource", err.Error())
+		return reconcile.Result{}, false, err
+	}
+
+	if ls != nil {
+		ls.Status.ArchiveRestores = statuses
+	}
+	return reconcile.Result{RequeueAfter: requeueAfter}, true, nil
+}
+
+// applyArchiveRestoreUser creates or updates the Elasticsearch user of the archive restore jobs, or deletes it when
+// there are no restores.
+//
+// It returns a reconcile.Result, a 'proceed' bool indicating if the Reconcile function should continue, and an error.
+func (r *ReconcileLogStorage) applyArchiveRestoreUser(enabled bool, userSecret *corev1.Secret, reqLogger logr.Logger, ctx context.Context) (reconcile.Result, bool, error) {
+	esClient, err := r.esCliCreator(r.client, ctx, relasticsearch.HTTPSEndpoint(rmeta.OSTypeLinux, r.clusterDomain))
+	if err != nil {
+		reqLogger.Error(err, "failed to create the Elasticsearch client")
+		r.status.SetDegraded("Failed to connect to Elasticsearch", err.Error())
+		return reconcile.Result{}, false, err
+	}
+
+	if !enabled {
+		if err = esClient.DeleteUser(ctx, render.ArchiveRestoreUserName); err != nil {
+			reqLogger.Error(err, "failed to delete the Elasticsearch user of the archive restore jobs")
+			r.status.SetDegraded("Failed to delete the Elasticsearch user of the archive restore jobs", err.Error())
+			return reconcile.Result{}, false, err
+		}
+		return reconcile.Result{}, true, nil
+	}
+	if err = esClient.SetRole(ctx, render.ArchiveRestoreRoleName, archiveRestoreUserRole); err != nil {
+		reqLogger.Error(err, "failed to create or update the Elasticsearch role of the archive restore jobs")
+		r.status.SetDegraded("Failed to create or update the Elasticsearch role of the archive restore jobs", err.Error())
+		return reconcile.Result{}, false, err
+	}
+	username, password := string(userSecret.Data["username"]), string(userSecret.Data["password"])
+	if err = esClient.SetUser(ctx, username, password, []string{render.ArchiveRestoreRoleName}); err != nil {
+		reqLogger.Error(err, "failed to create or update the Elasticsearch user of the archive restore jobs")
+		r.status.SetDegraded("Failed to create or update the Elasticsearch user of the archive restore jobs", err.Error())
+		return reconcile.Result{}, false, err
+	}
+	return reconcile.Result{}, true, nil
+}
@@ -15,6 +15,7 @@
 package common
 
 import (
+	"bytes"
 	"context"
 
 	"github.com/tigera/operator/pkg/render/kubecontrollers"
@@ -107,6 +108,12 @@ func CreateKubeControllersSecrets(ctx context.Context, esAdminUserSecret *corev1
 				"password": esAdminUserSecret.Data[esAdminUserName],
 			},
 		}
+	} else if !bytes.Equal(kubeControllersSecureUserSecret.Data["password"], esAdminUserSecret.Data[esAdminUserName]) {
+		// The admin user credentials have been rotated.
+		kubeControllersSecureUserSecret.Data = map[string][]byte{
+			"username": []byte(esAdminUserName),
+			"password": esAdminUserSecret.Data[esAdminUserName],
+		}
 	}
 
 	return kubeControllersGatewaySecret, kubeControllersVerificationSecret, kubeControllersSecureUserSecret, nil
@@ -0,0 +1,125 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta "k8s.io/api/batch/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/controller/utils"
+	"github.com/tigera/operator/pkg/render"
+	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
+	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+)
+
+// curatorBackpressureInterval is how often the health of Elasticsearch is checked while the curator is suspended, so
+// that the curator is resumed soon after Elasticsearch has recovered.
+const curatorBackpressureInterval = time.Minute
+
+// esRecovering returns whether Elasticsearch is recovering, i.e. its health is yellow or red and more shards than the
+// threshold of the LogStorage are relocating.
+func esRecovering(ls *operatorv1.LogStorage, health *utils.ClusterHealth) bool {
+	if health.Status != "yellow" && health.Status != "red" {
+		return false
+	}
+	var threshold int32
+	if ls.Spec.CuratorBackpressure != nil && ls.Spec.CuratorBackpressure.RelocatingShardsThreshold != nil {
+		threshold = *ls.Spec.CuratorBackpressure.RelocatingShardsThreshold
+	}
+	return health.RelocatingShards > int(threshold)
+}
+
+// newCuratorCronJob returns an empty curator CronJob of the API that the curator is rendered with, and the suspend
+// field of its spec.
+func (r *ReconcileLogStorage) newCuratorCronJob() (client.Object, **bool) {
+	if r.useBatchV1CronJobs {
+		cronJob := &batchv1.CronJob{}
+		return cronJob, &cronJob.Spec.Suspend
+	}
+	cronJob := &batchv1beta.CronJob{}
+	return cronJob, &cronJob.Spec.Suspend
+}
+
+// curatorSuspended returns whether the curator CronJob is currently suspended, so that rendering the CronJob doesn't
+// resume the curator while Elasticsearch is recovering.
+func (r *ReconcileLogStorage) curatorSuspended(ctx context.Context) (bool, error) {
+	cronJob, suspend := r.newCuratorCronJob()
+	if err := r.client.Get(ctx, types.NamespacedName{Name: render.EsCuratorName, Namespace: render.ElasticsearchNamespace}, cronJob); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return *suspend != nil && **suspend, nil
+}
+
+// applyCuratorBackpressure suspends the curator CronJob while Elasticsearch is recovering, so that the deletion of
+// indices doesn't add to the load of the recovery, and resumes it once Elasticsearch has recovered. While the curator
+// is suspended, the returned result requeues the request for the next health check.
+func (r *ReconcileLogStorage) applyCuratorBackpressure(ls *operatorv1.LogStorage, reqLogger logr.Logger, ctx context.Context) (reconcile.Result, bool, error) {
+	cronJob, cronJobSuspend := r.newCuratorCronJob()
+	if err := r.client.Get(ctx, types.NamespacedName{Name: render.EsCuratorName, Namespace: render.ElasticsearchNamespace}, cronJob); err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, true, nil
+		}
+		reqLogger.Error(err, "failed to get the curator CronJob")
+		r.status.SetDegraded("Failed to get the curator CronJob", err.Error())
+		return reconcile.Result{}, false, err
+	}
+
+	esClient, err := r.esCliCreator(r.client, ctx, relasticsearch.HTTPSEndpoint(rmeta.OSTypeLinux, r.clusterDomain))
+	if err != nil {
+		reqLogger.Error(err, "failed to create the Elasticsearch client")
+		r.status.SetDegraded("Failed to connect to Elasticsearch", err.Error())
+		return reconcile.Result{}, false, err
+	}
+	health, err := esClient.ClusterHealth(ctx)
+	if err != nil {
+		reqLogger.Error(err, "failed to get the health of Elasticsearch")
+		r.status.SetDegraded("Failed to get the health of Elasticsearch", err.Error())
+		return reconcile.Result{}, false, err
+	}
+
+	suspend := esRecovering(ls, health)
+	suspended := *cronJobSuspend != nil && **cronJobSuspend
+	if suspend != suspended {
+		if suspend {
+			reqLogger.Info("Suspending the curator while Elasticsearch recovers", "status", health.Status, "relocatingShards", health.RelocatingShards)
+		} else {
+			reqLogger.Info("Resuming the curator now that Elasticsearch has recovered")
+		}
+		patchFrom := client.MergeFrom(cronJob.DeepCopyObject().(client.Object))
+		*cronJobSuspend = &suspend
+		if err := r.client.Patch(ctx, cronJob, patchFrom); err != nil {
+			reqLogger.Error(err, "failed to suspend or resume the curator CronJob")
+			r.status.SetDegraded("Failed to suspend or resume the curator CronJob", err.Error())
+			return reconcile.Result{}, false, err
+		}
+	}
+
+	if suspend {
+		return reconcile.Result{RequeueAfter: curatorBackpressureInterval}, true, nil
+	}
+	return reconcile.Result{}, true, nil
+}
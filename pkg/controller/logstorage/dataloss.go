@@ -0,0 +1,108 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"fmt"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/render"
+)
+
+// dataLossAcknowledged returns whether the changes of the current generation of the LogStorage that delete the data
+// of Elasticsearch are acknowledged.
+func dataLossAcknowledged(ls *operatorv1.LogStorage) bool {
+	return ls.Spec.DataLossAcknowledged != nil && *ls.Spec.DataLossAcknowledged == ls.Generation
+}
+
+// nodeSetStorageClass returns the StorageClass of the volumes of the NodeSet, or "" if it has none.
+func nodeSetStorageClass(nodeSet esv1.NodeSet) string {
+	if len(nodeSet.VolumeClaimTemplates) == 0 || nodeSet.VolumeClaimTemplates[0].Spec.StorageClassName == nil {
+		return ""
+	}
+	return *nodeSet.VolumeClaimTemplates[0].Spec.StorageClassName
+}
+
+// nodeSetZone returns the zone of the Elasticsearch nodes of the NodeSet, or "" if it isn't in a single zone.
+func nodeSetZone(nodeSet esv1.NodeSet) string {
+	if nodeSet.Config == nil {
+		return ""
+	}
+	zone, _ := nodeSet.Config.Data["node.attr.zone"].(string)
+	return zone
+}
+
+// renderedElasticsearch returns the Elasticsearch cluster that the sub-components render, and whether they delete it.
+func renderedElasticsearch(subComponents []*render.LogStorageSubComponent) (*esv1.Elasticsearch, bool) {
+	var desired *esv1.Elasticsearch
+	var deleted bool
+	for _, subComponent := range subComponents {
+		if subComponent.Name != render.LogStorageSubComponentElasticsearch {
+			continue
+		}
+		toCreate, toDelete := subComponent.Objects()
+		for _, obj := range toCreate {
+			if es, ok := obj.(*esv1.Elasticsearch); ok {
+				desired = es
+			}
+		}
+		for _, obj := range toDelete {
+			if _, ok := obj.(*esv1.Elasticsearch); ok {
+				deleted = true
+			}
+		}
+	}
+	return desired, deleted
+}
+
+// destructiveChanges returns the changes from the current to the desired Elasticsearch cluster that delete the data of
+// the current cluster: the deletion of the cluster while the LogStorage isn't deleted, and the replacement of its
+// NodeSets, which happens when their storage changes in a way that can't be applied to their volumes in place.
+func destructiveChanges(ls *operatorv1.LogStorage, current, desired *esv1.Elasticsearch, deleted bool) []string {
+	if ls == nil || ls.DeletionTimestamp != nil || current == nil {
+		return nil
+	}
+	if deleted {
+		return []string{"Elasticsearch is deleted, since the cluster is connected to a management cluster"}
+	}
+	if desired == nil {
+		return nil
+	}
+
+	desiredNodeSets := map[string]bool{}
+	desiredStorageClasses := map[string]bool{}
+	desiredZones := map[string]bool{}
+	for _, nodeSet := range desired.Spec.NodeSets {
+		desiredNodeSets[nodeSet.Name] = true
+		desiredStorageClasses[nodeSetStorageClass(nodeSet)] = true
+		desiredZones[nodeSetZone(nodeSet)] = true
+	}
+	var changes []string
+	for _, nodeSet := range current.Spec.NodeSets {
+		if desiredNodeSets[nodeSet.Name] {
+			continue
+		}
+		if zone := nodeSetZone(nodeSet); zone != "" && !desiredZones[zone] {
+			changes = append(changes, fmt.Sprintf("NodeSet %s is removed, since Elasticsearch no longer runs in its zone %s", nodeSet.Name, zone))
+		} else if storageClass := nodeSetStorageClass(nodeSet); !desiredStorageClasses[storageClass] {
+			changes = append(changes, fmt.Sprintf("NodeSet %s is replaced, since its StorageClass %s is no longer used", nodeSet.Name, storageClass))
+		} else {
+			changes = append(changes, fmt.Sprintf("NodeSet %s is replaced, since its storage changed", nodeSet.Name))
+		}
+	}
+	return changes
+}
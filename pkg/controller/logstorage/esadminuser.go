@@ -0,0 +1,132 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+)
+
+// credentialRotationDue returns whether credentials created at the given time need to be rotated, given the rotation
+// spec, the rotation token of the most recent rotation and whether that rotation is still pending. If the credentials
+// are rotated on an interval, it also returns the time left until the next rotation is due, so that the caller can
+// requeue the request.
+func credentialRotationDue(rotation *operatorv1.ESUserRotation, rotatedToken string, pending bool, created metav1.Time, now time.Time) (bool, time.Duration) {
+	// A rotation was recorded, but the credentials that were current at that time have not been replaced yet.
+	if pending {
+		return true, 0
+	}
+
+	if rotation == nil {
+		return false, 0
+	}
+
+	if rotation.RotationToken != rotatedToken {
+		return true, 0
+	}
+
+	if rotation.Interval != nil && rotation.Interval.Duration > 0 {
+		remaining := created.Add(rotation.Interval.Duration).Sub(now)
+		if remaining <= 0 {
+			return true, 0
+		}
+		return false, remaining
+	}
+
+	return false, 0
+}
+
+// credentialRotationPending returns true if a rotation has been recorded for the secret with the given UID, and the
+// given secret is still that secret, i.e. it hasn't been regenerated yet. The secret is identified by its UID rather
+// than compared with the time of the rotation, since the clock of the operator can be skewed from the clock of the API
+// server that sets the creation timestamps.
+func credentialRotationPending(rotatedUID types.UID, secret *corev1.Secret) bool {
+	return rotatedUID != "" && secret != nil && secret.UID == rotatedUID
+}
+
+// adminUserRotationDue returns whether the Elasticsearch admin user credentials in the given secret need to be
+// rotated. If the credentials are rotated on an interval, it also returns the time left until the next rotation is
+// due, so that the caller can requeue the request.
+func adminUserRotationDue(ls *operatorv1.LogStorage, esAdminUserSecret *corev1.Secret, now time.Time) (bool, time.Duration) {
+	if ls == nil || esAdminUserSecret == nil {
+		return false, 0
+	}
+	return credentialRotationDue(ls.Spec.AdminUserRotation, ls.Status.AdminUserRotationToken, adminUserRotationPending(ls, esAdminUserSecret), esAdminUserSecret.CreationTimestamp, now)
+}
+
+// adminUserRotationPending returns true if a rotation has been recorded in the LogStorage status, but the given
+// secret is the one that it replaces.
+func adminUserRotationPending(ls *operatorv1.LogStorage, esAdminUserSecret *corev1.Secret) bool {
+	return credentialRotationPending(ls.Status.RotatedAdminUserSecretUID, esAdminUserSecret)
+}
+
+// rotateEsAdminUser rotates the credentials of the Elasticsearch admin user when they are due according to the
+// LogStorage spec.
+//
+// ECK manages the admin (elastic) user in the file realm of Elasticsearch, which cannot be changed through the
+// Elasticsearch security API. Instead, the credentials are rotated by deleting the secret that holds them: ECK then
+// generates a new password and reconfigures the Elasticsearch file realm with it. The regenerated secret triggers a
+// new reconcile that copies it to the operator namespace, after which the Elasticsearch client of this controller
+// authenticates with the new credentials and the consumers of the secret roll because of their hash annotations.
+//
+// The rotation is recorded in the LogStorage status with the UID of the secret before the secret is deleted, so that a
+// failure after that point doesn't cause the regenerated secret to be deleted again.
+//
+// It returns a reconcile.Result, a 'proceed' bool indicating if the Reconcile function should continue, and an error.
+func (r *ReconcileLogStorage) rotateEsAdminUser(
+	ls *operatorv1.LogStorage,
+	esAdminUserSecret *corev1.Secret,
+	reqLogger logr.Logger,
+	ctx context.Context,
+) (reconcile.Result, bool, error) {
+	now := time.Now()
+	due, _ := adminUserRotationDue(ls, esAdminUserSecret, now)
+	if !due {
+		return reconcile.Result{}, true, nil
+	}
+
+	if !adminUserRotationPending(ls, esAdminUserSecret) {
+		reqLogger.Info("Rotating the Elasticsearch admin user credentials")
+		if ls.Spec.AdminUserRotation != nil {
+			ls.Status.AdminUserRotationToken = ls.Spec.AdminUserRotation.RotationToken
+		}
+		rotationTime := metav1.NewTime(now)
+		ls.Status.LastAdminUserRotation = &rotationTime
+		ls.Status.RotatedAdminUserSecretUID = esAdminUserSecret.UID
+		if err := r.client.Status().Update(ctx, ls); err != nil {
+			reqLogger.Error(err, "failed to record the Elasticsearch admin user rotation")
+			r.status.SetDegraded("Failed to record the Elasticsearch admin user rotation", err.Error())
+			return reconcile.Result{}, false, err
+		}
+	}
+
+	if err := r.client.Delete(ctx, esAdminUserSecret); err != nil && !errors.IsNotFound(err) {
+		reqLogger.Error(err, "failed to delete the Elasticsearch admin user secret")
+		r.status.SetDegraded("Failed to rotate the Elasticsearch admin user credentials", err.Error())
+		return reconcile.Result{}, false, err
+	}
+
+	reqLogger.Info("Waiting for the Elasticsearch admin user credentials to be regenerated")
+	return reconcile.Result{RequeueAfter: 10 * time.Second}, false, nil
+}
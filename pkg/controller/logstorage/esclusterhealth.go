@@ -0,0 +1,165 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/controller/utils"
+	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
+	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+)
+
+// esHealthConditions returns the conditions of the LogStorage that reflect the health of the Elasticsearch cluster and
+// the disk usage of its nodes, in percent by node name.
+func esHealthConditions(ls *operatorv1.LogStorage, health *utils.ClusterHealth, diskUsage map[string]int) []metav1.Condition {
+	healthy := metav1.Condition{
+		Type:               operatorv1.LogStorageConditionElasticsearchHealthy,
+		Status:             metav1.ConditionUnknown,
+		Reason:             "Unknown",
+		Message:            fmt.Sprintf("The health of the Elasticsearch cluster is %s", health.Status),
+		ObservedGeneration: ls.Generation,
+	}
+	switch health.Status {
+	case "green":
+		healthy.Status = metav1.ConditionTrue
+		healthy.Reason = "Green"
+	case "yellow":
+		healthy.Status = metav1.ConditionFalse
+		healthy.Reason = "Yellow"
+	case "red":
+		healthy.Status = metav1.ConditionFalse
+		healthy.Reason = "Red"
+	}
+
+	assigned := metav1.Condition{
+		Type:               operatorv1.LogStorageConditionShardsAssigned,
+		Status:             metav1.ConditionTrue,
+		Reason:             "AllShardsAssigned",
+		Message:            "All the shards are assigned",
+		ObservedGeneration: ls.Generation,
+	}
+	if health.UnassignedShards > 0 {
+		assigned.Status = metav1.ConditionFalse
+		assigned.Reason = "UnassignedShards"
+		assigned.Message = fmt.Sprintf("%d shards are unassigned", health.UnassignedShards)
+	}
+
+	low, high, floodStage := diskWatermarks(&ls.Spec)
+	disk := metav1.Condition{
+		Type:               operatorv1.LogStorageConditionDiskAvailable,
+		Status:             metav1.ConditionTrue,
+		Reason:             "BelowWatermarks",
+		Message:            fmt.Sprintf("The disk usage of all the nodes is below the low disk watermark of %d%%", low),
+		ObservedGeneration: ls.Generation,
+	}
+	var nodes []string
+	for node := range diskUsage {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	var maxPercent int
+	var exceeded []string
+	for _, node := range nodes {
+		percent := diskUsage[node]
+		if percent >= int(low) {
+			exceeded = append(exceeded, fmt.Sprintf("%s (%d%%)", node, percent))
+		}
+		if percent > maxPercent {
+			maxPercent = percent
+		}
+	}
+	if len(exceeded) > 0 {
+		// Elasticsearch stops allocating shards to a node past the low watermark, which only matters for new indices.
+		// The disk is considered unavailable once shards are moved away from a node past the high watermark.
+		switch {
+		case maxPercent >= int(floodStage):
+			disk.Status = metav1.ConditionFalse
+			disk.Reason = "FloodStageWatermarkExceeded"
+		case maxPercent >= int(high):
+			disk.Status = metav1.ConditionFalse
+			disk.Reason = "HighWatermarkExceeded"
+		default:
+			disk.Reason = "LowWatermarkExceeded"
+		}
+		disk.Message = fmt.Sprintf("The disk usage of nodes is above the low disk watermark of %d%%: %s", low, strings.Join(exceeded, ", "))
+	}
+
+	return []metav1.Condition{healthy, assigned, disk}
+}
+
+// esHealthUnknownConditions returns the conditions of the LogStorage for when the health of the Elasticsearch cluster
+// could not be fetched, so that the conditions don't keep showing the last health that was observed.
+func esHealthUnknownConditions(ls *operatorv1.LogStorage, err error) []metav1.Condition {
+	var conditions []metav1.Condition
+	for _, conditionType := range []string{
+		operatorv1.LogStorageConditionElasticsearchHealthy,
+		operatorv1.LogStorageConditionShardsAssigned,
+		operatorv1.LogStorageConditionDiskAvailable,
+	} {
+		conditions = append(conditions, metav1.Condition{
+			Type:               conditionType,
+			Status:             metav1.ConditionUnknown,
+			Reason:             "HealthUnavailable",
+			Message:            fmt.Sprintf("Failed to get the health of Elasticsearch: %s", err),
+			ObservedGeneration: ls.Generation,
+		})
+	}
+	return conditions
+}
+
+// applyElasticsearchHealth gets the health of Elasticsearch through es-gateway and sets it in the conditions of the
+// LogStorage, which is updated at the end of the reconciliation. The health is refreshed whenever the LogStorage is
+// reconciled. When it can't be fetched, the conditions are set to unknown and the reconciliation carries on.
+func (r *ReconcileLogStorage) applyElasticsearchHealth(ls *operatorv1.LogStorage, reqLogger logr.Logger, ctx context.Context) (reconcile.Result, bool, error) {
+	conditions, err := r.esHealthConditions(ls, ctx)
+	if err != nil {
+		reqLogger.Error(err, "failed to get the health of Elasticsearch")
+		conditions = esHealthUnknownConditions(ls, err)
+	}
+
+	// SetStatusCondition only changes the transition time of a condition when its status changes.
+	for _, condition := range conditions {
+		meta.SetStatusCondition(&ls.Status.Conditions, condition)
+	}
+	return reconcile.Result{}, true, nil
+}
+
+// esHealthConditions fetches the health of Elasticsearch and the disk usage of its nodes, and returns the conditions
+// of the LogStorage that reflect them.
+func (r *ReconcileLogStorage) esHealthConditions(ls *operatorv1.LogStorage, ctx context.Context) ([]metav1.Condition, error) {
+	esClient, err := r.esCliCreator(r.client, ctx, relasticsearch.HTTPSEndpoint(rmeta.OSTypeLinux, r.clusterDomain))
+	if err != nil {
+		return nil, err
+	}
+	health, err := esClient.ClusterHealth(ctx)
+	if err != nil {
+		return nil, err
+	}
+	diskUsage, err := esClient.NodeDiskUsage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return esHealthConditions(ls, health, diskUsage), nil
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/controller/utils"
+	"github.com/tigera/operator/pkg/render"
+)
+
+// esComponentUserSecrets are the secrets in the operator namespace with the credentials of the Elasticsearch users of
+// the components that are rotated by spec.componentUserRotation.
+var esComponentUserSecrets = []string{
+	render.ElasticsearchOperatorUserSecret,
+	render.ElasticsearchLogCollectorUserSecret,
+	render.ElasticsearchCuratorUserSecret,
+	render.ElasticsearchManagerUserSecret,
+}
+
+// componentUserRotationDue returns whether the credentials of the Elasticsearch users of the components in the given
+// secrets need to be rotated. The users are rotated together, so the rotation is due as soon as the oldest credentials
+// are due. If the credentials are rotated on an interval, it also returns the time left until the next rotation is
+// due, so that the caller can requeue the request.
+func componentUserRotationDue(ls *operatorv1.LogStorage, userSecrets []*corev1.Secret, now time.Time) (bool, time.Duration) {
+	if ls == nil {
+		return false, 0
+	}
+	var oldest *corev1.Secret
+	for _, s := range userSecrets {
+		if s != nil && (oldest == nil || s.CreationTimestamp.Before(&oldest.CreationTimestamp)) {
+			oldest = s
+		}
+	}
+	if oldest == nil {
+		return false, 0
+	}
+	return credentialRotationDue(ls.Spec.ComponentUserRotation, ls.Status.ComponentUserRotationToken, componentUserRotationPending(ls, userSecrets), oldest.CreationTimestamp, now)
+}
+
+// getEsComponentUserSecrets returns the secrets with the credentials of the Elasticsearch users of the components, the
+// ones that don't exist yet being nil.
+func (r *ReconcileLogStorage) getEsComponentUserSecrets(ctx context.Context) ([]*corev1.Secret, error) {
+	var userSecrets []*corev1.Secret
+	for _, name := range esComponentUserSecrets {
+		s, err := utils.GetSecret(ctx, r.client, name, common.OperatorNamespace())
+		if err != nil {
+			return nil, err
+		}
+		userSecrets = append(userSecrets, s)
+	}
+	return userSecrets, nil
+}
+
+// rotateEsComponentUsers rotates the credentials of the Elasticsearch users of the operator, fluentd, curator and the
+// manager when they are due according to the LogStorage spec, the same way as rotateEsAdminUser does for the admin
+// user.
+//
+// The users and the secrets with their credentials are managed by the Elasticsearch controllers of kube-controllers,
+// which create a user with a new password when its secret is missing. The credentials are rotated by deleting the
+// secrets: the regenerated secrets trigger reconciles of the controllers that copy them to the namespaces of the
+// components, and the components roll because of the hash annotations of the secrets on their pods.
+//
+// The rotation is recorded in the LogStorage status with the UIDs of the secrets before the secrets are deleted, so
+// that a failure after that point only deletes the secrets that weren't regenerated yet.
+//
+// It returns a reconcile.Result, a 'proceed' bool indicating if the Reconcile function should continue, and an error.
+// The rest of the reconcile proceeds while the secrets are regenerated, since the operator and the components keep
+// working with the current credentials until their secrets are copied. The result requeues the request until the
+// secrets are regenerated, or for the next rotation if the credentials are rotated on an interval.
+func (r *ReconcileLogStorage) rotateEsComponentUsers(ls *operatorv1.LogStorage, reqLogger logr.Logger, ctx context.Context) (reconcile.Result, bool, error) {
+	userSecrets, err := r.getEsComponentUserSecrets(ctx)
+	if err != nil {
+		reqLogger.Error(err, "failed to get the Elasticsearch user secrets of the components")
+		r.status.SetDegraded("Failed to get the Elasticsearch user secrets of the components", err.Error())
+		return reconcile.Result{}, false, err
+	}
+
+	now := time.Now()
+	due, untilRotation := componentUserRotationDue(ls, userSecrets, now)
+	if !due {
+		return reconcile.Result{RequeueAfter: untilRotation}, true, nil
+	}
+
+	if !componentUserRotationPending(ls, userSecrets) {
+		reqLogger.Info("Rotating the credentials of the Elasticsearch users of the components")
+		if ls.Spec.ComponentUserRotation != nil {
+			ls.Status.ComponentUserRotationToken = ls.Spec.ComponentUserRotation.RotationToken
+		}
+		rotationTime := metav1.NewTime(now)
+		ls.Status.LastComponentUserRotation = &rotationTime
+		ls.Status.RotatedComponentUserSecretUIDs = nil
+		for _, s := range userSecrets {
+			if s != nil {
+				ls.Status.RotatedComponentUserSecretUIDs = append(ls.Status.RotatedComponentUserSecretUIDs, s.UID)
+			}
+		}
+		if err := r.client.Status().Update(ctx, ls); err != nil {
+			reqLogger.Error(err, "failed to record the rotation of the Elasticsearch users of the components")
+			r.status.SetDegraded("Failed to record the rotation of the Elasticsearch users of the components", err.Error())
+			return reconcile.Result{}, false, err
+		}
+	}
+
+	for _, s := range userSecrets {
+		if !componentUserSecretRotated(ls.Status.RotatedComponentUserSecretUIDs, s) {
+			continue
+		}
+		if err := r.client.Delete(ctx, s); err != nil && !errors.IsNotFound(err) {
+			reqLogger.Error(err, "failed to delete the Elasticsearch user secret", "name", s.Name)
+			r.status.SetDegraded("Failed to rotate the credentials of the Elasticsearch users of the components", err.Error())
+			return reconcile.Result{}, false, err
+		}
+	}
+
+	reqLogger.Info("Waiting for the credentials of the Elasticsearch users of the components to be regenerated")
+	return reconcile.Result{RequeueAfter: 10 * time.Second}, true, nil
+}
+
+// componentUserRotationPending returns true if a rotation has been recorded in the LogStorage status, but any of the
+// given secrets is one that it replaces.
+func componentUserRotationPending(ls *operatorv1.LogStorage, userSecrets []*corev1.Secret) bool {
+	for _, s := range userSecrets {
+		if componentUserSecretRotated(ls.Status.RotatedComponentUserSecretUIDs, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// componentUserSecretRotated returns true if the given secret is one of the secrets with the given UIDs, i.e. it is
+// replaced by the recorded rotation but hasn't been regenerated yet.
+func componentUserSecretRotated(rotatedUIDs []types.UID, secret *corev1.Secret) bool {
+	for _, uid := range rotatedUIDs {
+		if credentialRotationPending(uid, secret) {
+			return true
+		}
+	}
+	return false
+}
@@ -21,7 +21,6 @@ import (
 	"github.com/tigera/operator/pkg/controller/certificatemanager"
 	"github.com/tigera/operator/pkg/dns"
 	rcertificatemanagement "github.com/tigera/operator/pkg/render/certificatemanagement"
-	"github.com/tigera/operator/pkg/tls/certificatemanagement"
 	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
@@ -35,6 +34,7 @@ import (
 )
 
 func (r *ReconcileLogStorage) createEsGateway(
+	ls *operatorv1.LogStorage,
 	install *operatorv1.InstallationSpec,
 	variant operatorv1.ProductVariant,
 	pullSecrets []*corev1.Secret,
@@ -52,18 +52,15 @@ func (r *ReconcileLogStorage) createEsGateway(
 		r.status.SetDegraded("Error creating TLS certificate", err.Error())
 		return reconcile.Result{}, false, err
 	}
-	var kibanaCertificate certificatemanagement.CertificateInterface
-	if !operatorv1.IsFIPSModeEnabled(install.FIPSMode) {
-		kibanaCertificate, err := certificateManager.GetCertificate(r.client, render.TigeraKibanaCertSecret, common.OperatorNamespace())
-		if err != nil {
-			reqLogger.Error(err, "failed to get Kibana tls certificate secret")
-			r.status.SetDegraded("Failed to get Kibana tls certificate secret", err.Error())
-			return reconcile.Result{}, false, err
-		} else if kibanaCertificate == nil {
-			reqLogger.Info("Waiting for internal Kibana tls certificate secret to be available")
-			r.status.SetDegraded("Waiting for internal Kibana tls certificate secret to be available", "")
-			return reconcile.Result{}, false, nil
-		}
+	kibanaCertificate, err := certificateManager.GetCertificate(r.client, render.TigeraKibanaCertSecret, common.OperatorNamespace())
+	if err != nil {
+		reqLogger.Error(err, "failed to get Kibana tls certificate secret")
+		r.status.SetDegraded("Failed to get Kibana tls certificate secret", err.Error())
+		return reconcile.Result{}, false, err
+	} else if kibanaCertificate == nil {
+		reqLogger.Info("Waiting for internal Kibana tls certificate secret to be available")
+		r.status.SetDegraded("Waiting for internal Kibana tls certificate secret to be available", "")
+		return reconcile.Result{}, false, nil
 	}
 	esInternalCertificate, err := certificateManager.GetCertificate(r.client, render.TigeraElasticsearchInternalCertSecret, common.OperatorNamespace())
 	if err != nil {
@@ -96,6 +93,38 @@ func (r *ReconcileLogStorage) createEsGateway(
 		return reconcile.Result{}, false, err
 	}
 
+	logCollector, err := utils.GetLogCollector(ctx, r.client)
+	if err != nil {
+		reqLogger.Error(err, "failed to get the LogCollector")
+		r.status.SetDegraded("Failed to get the LogCollector", err.Error())
+		return reconcile.Result{}, false, err
+	}
+
+	// The gateway only accepts the tokens of fluentd when the LogCollector opts in to them. The credentials of fluentd
+	// are created along with its Elasticsearch user, until then the gateway doesn't accept the tokens of fluentd.
+	var fluentdUserSecret *corev1.Secret
+	if logCollector != nil && logCollector.Spec.ESGatewayTokenAuthEnabled() {
+		fluentdUserSecret, err = utils.GetSecret(ctx, r.client, render.ElasticsearchLogCollectorUserSecret, common.OperatorNamespace())
+		if err != nil {
+			reqLogger.Error(err, "failed to get the fluentd Elasticsearch user secret")
+			r.status.SetDegraded("Failed to get the fluentd Elasticsearch user secret", err.Error())
+			return reconcile.Result{}, false, err
+		}
+	}
+
+	pdbExists, err := r.podDisruptionBudgetExists(ctx, esgateway.DeploymentName, render.ElasticsearchNamespace)
+	if err != nil {
+		reqLogger.Error(err, "failed to get the Elasticsearch gateway PodDisruptionBudget")
+		r.status.SetDegraded("Failed to get the Elasticsearch gateway PodDisruptionBudget", err.Error())
+		return reconcile.Result{}, false, err
+	}
+	hpaExists, err := r.horizontalPodAutoscalerExists(ctx, esgateway.DeploymentName, render.ElasticsearchNamespace)
+	if err != nil {
+		reqLogger.Error(err, "failed to get the Elasticsearch gateway HorizontalPodAutoscaler")
+		r.status.SetDegraded("Failed to get the Elasticsearch gateway HorizontalPodAutoscaler", err.Error())
+		return reconcile.Result{}, false, err
+	}
+
 	cfg := &esgateway.Config{
 		Installation:               install,
 		PullSecrets:                pullSecrets,
@@ -103,7 +132,14 @@ func (r *ReconcileLogStorage) createEsGateway(
 		KubeControllersUserSecrets: []*corev1.Secret{kubeControllersGatewaySecret, kubeControllersVerificationSecret, kubeControllersSecureUserSecret},
 		ClusterDomain:              r.clusterDomain,
 		EsAdminUserName:            esAdminUserName,
+		EsAdminUserSecret:          esAdminUserSecret,
 		ESGatewayKeyPair:           gatewayKeyPair,
+		Spec:                       ls.Spec.ESGateway,
+		Ports:                      ls.Spec.Ports,
+		FluentdUserSecret:          fluentdUserSecret,
+		DisruptionPolicy:           ls.Spec.ComponentDisruptionPolicy(operatorv1.ComponentNameESGateway),
+		RemovePodDisruptionBudget:  pdbExists,
+		RemoveHPA:                  hpaExists,
 	}
 
 	esGatewayComponent := esgateway.EsGateway(cfg)
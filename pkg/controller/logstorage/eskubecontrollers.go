@@ -34,12 +34,14 @@ import (
 )
 
 func (r *ReconcileLogStorage) createEsKubeControllers(
+	ls *operatorv1.LogStorage,
 	install *operatorv1.InstallationSpec,
 	hdler utils.ComponentHandler,
 	reqLogger logr.Logger,
 	managementCluster *operatorv1.ManagementCluster,
 	authentication *operatorv1.Authentication,
 	esLicenseType render.ElasticsearchLicenseType,
+	clusterConfig *relasticsearch.ClusterConfig,
 	ctx context.Context,
 ) (reconcile.Result, bool, error) {
 	kubeControllersUserSecret, err := utils.GetSecret(ctx, r.client, kubecontrollers.ElasticsearchKubeControllersUserSecret, common.OperatorNamespace())
@@ -83,6 +85,10 @@ func (r *ReconcileLogStorage) createEsKubeControllers(
 		KubeControllersGatewaySecret: kubeControllersUserSecret,
 		LogStorageExists:             true,
 		TrustedBundle:                trustedBundle,
+		ESClusterConfig:              clusterConfig,
+	}
+	if ls != nil {
+		kubeControllersCfg.DisruptionPolicy = ls.Spec.ComponentDisruptionPolicy(operatorv1.ComponentNameESKubeControllers)
 	}
 	esKubeControllerComponents := kubecontrollers.NewElasticsearchKubeControllers(&kubeControllersCfg)
 
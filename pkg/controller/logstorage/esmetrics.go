@@ -20,9 +20,16 @@ import (
 
 	"github.com/go-logr/logr"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+
 	operatorv1 "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/common"
 	"github.com/tigera/operator/pkg/controller/certificatemanager"
@@ -37,6 +44,7 @@ import (
 )
 
 func (r *ReconcileLogStorage) createEsMetrics(
+	ls *operatorv1.LogStorage,
 	install *operatorv1.InstallationSpec,
 	variant operatorv1.ProductVariant,
 	pullSecrets []*corev1.Secret,
@@ -46,6 +54,34 @@ func (r *ReconcileLogStorage) createEsMetrics(
 	hdler utils.ComponentHandler,
 	clusterDomain string,
 ) (reconcile.Result, bool, error) {
+	alerts, removeAlerts, err := r.esMetricsAlerts(ctx, ls)
+	if err != nil {
+		reqLogger.Error(err, "Failed to check the alerts on the Elasticsearch metrics")
+		r.status.SetDegraded("Failed to check the alerts on the Elasticsearch metrics", err.Error())
+		return reconcile.Result{}, false, err
+	}
+
+	if !exporterEnabled(ls) {
+		// The exporter is only removed once, when the monitoring of the LogStorage disables it.
+		deployment := &appsv1.Deployment{}
+		err := r.client.Get(ctx, types.NamespacedName{Name: esmetrics.ElasticsearchMetricsName, Namespace: render.ElasticsearchNamespace}, deployment)
+		if err != nil && !errors.IsNotFound(err) {
+			reqLogger.Error(err, "Failed to get the Elasticsearch metrics exporter")
+			r.status.SetDegraded("Failed to get the Elasticsearch metrics exporter", err.Error())
+			return reconcile.Result{}, false, err
+		} else if errors.IsNotFound(err) && !removeAlerts {
+			return reconcile.Result{}, true, nil
+		}
+
+		component := esmetrics.ElasticsearchMetrics(&esmetrics.Config{Installation: install, Disabled: true, RemoveAlerts: removeAlerts})
+		if err := hdler.CreateOrUpdateOrDelete(ctx, component, r.status); err != nil {
+			reqLogger.Error(err, err.Error())
+			r.status.SetDegraded("Error removing the Elasticsearch metrics exporter", err.Error())
+			return reconcile.Result{}, false, err
+		}
+		return reconcile.Result{}, true, nil
+	}
+
 	esMetricsSecret, err := utils.GetSecret(context.Background(), r.client, esmetrics.ElasticsearchMetricsSecret, common.OperatorNamespace())
 	if err != nil {
 		reqLogger.Error(err, "Failed to retrieve Elasticsearch metrics user secret.")
@@ -104,6 +140,9 @@ func (r *ReconcileLogStorage) createEsMetrics(
 		ClusterDomain:        r.clusterDomain,
 		ServerTLS:            serverTLS,
 		TrustedBundle:        trustedBundle,
+		Alerts:               alerts,
+		RemoveAlerts:         removeAlerts,
+		DisruptionPolicy:     ls.Spec.ComponentDisruptionPolicy(operatorv1.ComponentNameESMetrics),
 	}
 	esMetricsComponent := esmetrics.ElasticsearchMetrics(esMetricsCfg)
 	components := []render.Component{esMetricsComponent,
@@ -133,3 +172,57 @@ func (r *ReconcileLogStorage) createEsMetrics(
 
 	return reconcile.Result{}, true, nil
 }
+
+// exporterEnabled returns whether the monitoring of the LogStorage enables the metrics exporter, which it does by default.
+func exporterEnabled(ls *operatorv1.LogStorage) bool {
+	return ls == nil || ls.Spec.Monitoring == nil || ls.Spec.Monitoring.Exporter == nil ||
+		*ls.Spec.Monitoring.Exporter != operatorv1.LogStorageMonitoringDisabled
+}
+
+// alertsEnabled returns whether the monitoring of the LogStorage enables the alerts along with the exporter. The alerts
+// are disabled by default.
+func alertsEnabled(ls *operatorv1.LogStorage) bool {
+	return exporterEnabled(ls) && ls != nil && ls.Spec.Monitoring != nil && ls.Spec.Monitoring.Alerts != nil &&
+		*ls.Spec.Monitoring.Alerts == operatorv1.LogStorageMonitoringEnabled
+}
+
+// esMetricsAlerts returns whether the PrometheusRule with the alerts on the Elasticsearch metrics is rendered, and
+// whether it is removed because it exists while the alerts are disabled. Neither is done unless the API server serves
+// the PrometheusRule CRD of the prometheus-operator.
+func (r *ReconcileLogStorage) esMetricsAlerts(ctx context.Context, ls *operatorv1.LogStorage) (bool, bool, error) {
+	served, err := prometheusRuleServed(r.clientset)
+	if err != nil || !served {
+		return false, false, err
+	}
+	if alertsEnabled(ls) {
+		return true, false, nil
+	}
+
+	rule := &monitoringv1.PrometheusRule{}
+	err = r.client.Get(ctx, client.ObjectKey{Name: esmetrics.ElasticsearchMetricsRuleName, Namespace: common.TigeraPrometheusNamespace}, rule)
+	if errors.IsNotFound(err) {
+		return false, false, nil
+	} else if err != nil {
+		return false, false, err
+	}
+	return false, true, nil
+}
+
+// prometheusRuleServed returns whether the API server serves the PrometheusRule CRD of the prometheus-operator.
+func prometheusRuleServed(cs kubernetes.Interface) (bool, error) {
+	if cs == nil {
+		return false, nil
+	}
+	resources, err := cs.Discovery().ServerResourcesForGroupVersion("monitoring.coreos.com/v1")
+	if errors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	for _, res := range resources.APIResources {
+		if res.Kind == monitoringv1.PrometheusRuleKind {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/tigera/operator/pkg/render"
+)
+
+// esClusterNameLabel is the label that ECK sets on the StatefulSets and PVCs of an Elasticsearch cluster.
+const esClusterNameLabel = "elasticsearch.k8s.elastic.co/cluster-name"
+
+// isEsStorageFailureEvent returns whether the event may report a failure to provision the storage of Elasticsearch:
+// a PVC that can't be bound or provisioned, e.g. because there is no default StorageClass, or a PVC that can't be
+// created by the StatefulSet, e.g. because the storage quota is exceeded.
+func isEsStorageFailureEvent(e *corev1.Event) bool {
+	if e.Type != corev1.EventTypeWarning {
+		return false
+	}
+	switch e.InvolvedObject.Kind {
+	case "PersistentVolumeClaim":
+		return e.Reason == "ProvisioningFailed" || e.Reason == "FailedBinding"
+	case "StatefulSet":
+		return e.Reason == "FailedCreate" && strings.Contains(e.Message, "persistentvolumeclaims")
+	}
+	return false
+}
+
+// esStorageFailure returns the message of the most recent event that reports a failure to provision the storage of
+// Elasticsearch, or an empty string if the storage isn't failing to be provisioned. Only the events of the PVCs that are
+// still pending and of the StatefulSets that are missing pods are considered, so that failures that have been resolved
+// are not reported.
+func (r *ReconcileLogStorage) esStorageFailure(ctx context.Context) (string, error) {
+	selector := client.MatchingLabels{esClusterNameLabel: render.ElasticsearchName}
+	failing := map[string]bool{}
+
+	pvcs := &corev1.PersistentVolumeClaimList{}
+	if err := r.client.List(ctx, pvcs, client.InNamespace(render.ElasticsearchNamespace), selector); err != nil {
+		return "", err
+	}
+	for _, pvc := range pvcs.Items {
+		if pvc.Status.Phase == corev1.ClaimPending {
+			failing["PersistentVolumeClaim/"+pvc.Name] = true
+		}
+	}
+
+	statefulSets := &apps.StatefulSetList{}
+	if err := r.client.List(ctx, statefulSets, client.InNamespace(render.ElasticsearchNamespace), selector); err != nil {
+		return "", err
+	}
+	for _, sts := range statefulSets.Items {
+		if sts.Spec.Replicas != nil && sts.Status.Replicas < *sts.Spec.Replicas {
+			failing["StatefulSet/"+sts.Name] = true
+		}
+	}
+
+	if len(failing) == 0 {
+		return "", nil
+	}
+
+	events := &corev1.EventList{}
+	if err := r.client.List(ctx, events, client.InNamespace(render.ElasticsearchNamespace)); err != nil {
+		return "", err
+	}
+	var latest *corev1.Event
+	for i := range events.Items {
+		e := &events.Items[i]
+		if !isEsStorageFailureEvent(e) || !failing[e.InvolvedObject.Kind+"/"+e.InvolvedObject.Name] {
+			continue
+		}
+		if latest == nil || eventTime(e).After(eventTime(latest)) {
+			latest = e
+		}
+	}
+	if latest == nil {
+		return "", nil
+	}
+	return fmt.Sprintf("%s %s: %s", latest.InvolvedObject.Kind, latest.InvolvedObject.Name, latest.Message), nil
+}
+
+// eventTime returns the time at which the event was last seen.
+func eventTime(e *corev1.Event) time.Time {
+	if !e.LastTimestamp.IsZero() {
+		return e.LastTimestamp.Time
+	}
+	if !e.EventTime.IsZero() {
+		return e.EventTime.Time
+	}
+	return e.CreationTimestamp.Time
+}
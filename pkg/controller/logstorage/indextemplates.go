@@ -0,0 +1,294 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/controller/utils"
+	"github.com/tigera/operator/pkg/controller/utils/imageset"
+	"github.com/tigera/operator/pkg/crypto"
+	"github.com/tigera/operator/pkg/render"
+	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
+	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/tls/certificatemanagement"
+)
+
+// indexTemplatesRetryInterval is how long after failing the job of the index templates is run again.
+const indexTemplatesRetryInterval = 5 * time.Minute
+
+// indexTemplateLogTypes are the log types of the indices whose index templates can be overridden.
+var indexTemplateLogTypes = map[string]bool{
+	"flows":              true,
+	"dns":                true,
+	"bgp":                true,
+	"l7":                 true,
+	"audit_ee":           true,
+	"audit_kube":         true,
+	"snapshots":          true,
+	"compliance_reports": true,
+	"benchmark_results":  true,
+	"events":             true,
+}
+
+// indexTemplateFields are the fields of an index template that an override may set. The patterns and the order of the
+// template are set by the operator.
+var indexTemplateFields = map[string]bool{
+	"settings": true,
+	"mappings": true,
+	"aliases":  true,
+}
+
+// indexTemplates returns the index templates of the overrides of the ConfigMap, by their name. It returns an error if a
+// key of the ConfigMap isn't a log type, or doesn't hold a JSON object with only the settings, mappings and aliases of
+// an index template.
+func indexTemplates(cm *corev1.ConfigMap) (map[string]string, error) {
+	templates := map[string]string{}
+	for logType, override := range cm.Data {
+		if !indexTemplateLogTypes[logType] {
+			return nil, fmt.Errorf("key %s of ConfigMap %s/%s is not the log type of an index", logType, cm.Namespace, cm.Name)
+		}
+		template := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(override), &template); err != nil {
+			return nil, fmt.Errorf("key %s of ConfigMap %s/%s does not hold a JSON object: %w", logType, cm.Namespace, cm.Name, err)
+		}
+		for field := range template {
+			if !indexTemplateFields[field] {
+				return nil, fmt.Errorf("key %s of ConfigMap %s/%s sets field %s, only settings, mappings and aliases can be overridden", logType, cm.Namespace, cm.Name, field)
+			}
+		}
+		template["index_patterns"] = []string{render.IndexTemplateOverridesPattern(logType)}
+		template["order"] = render.IndexTemplateOverridesOrder
+		body, err := json.Marshal(template)
+		if err != nil {
+			return nil, err
+		}
+		templates[render.IndexTemplateOverridesName(logType)] = string(body)
+	}
+	return templates, nil
+}
+
+// getIndexTemplates returns the index templates of the overrides of the LogStorage. It returns an error if the
+// ConfigMap of the overrides is missing or invalid.
+func (r *ReconcileLogStorage) getIndexTemplates(ctx context.Context, ls *operatorv1.LogStorage) (map[string]string, error) {
+	name := ls.Spec.IndexTemplates.ConfigMapName
+	cm := &corev1.ConfigMap{}
+	if err := r.client.Get(ctx, client.ObjectKey{Name: name, Namespace: common.OperatorNamespace()}, cm); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, fmt.Errorf("ConfigMap %s/%s of the index templates not found", common.OperatorNamespace(), name)
+		}
+		return nil, err
+	}
+	return indexTemplates(cm)
+}
+
+// indexTemplatesUserRole is the Elasticsearch role of the user of the index templates job, which can only manage the
+// index templates.
+var indexTemplatesUserRole = map[string]interface{}{
+	"cluster": []string{"manage_index_templates"},
+}
+
+// setIndexTemplatesCondition sets the IndexTemplatesApplied condition of the LogStorage, which is written with its
+// status at the end of the reconciliation.
+func setIndexTemplatesCondition(ls *operatorv1.LogStorage, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&ls.Status.Conditions, metav1.Condition{
+		Type:               operatorv1.LogStorageConditionIndexTemplatesApplied,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: ls.Generation,
+	})
+}
+
+// applyIndexTemplates runs the job that applies the overrides of the index templates of the LogStorage, once
+// Elasticsearch is operational. The job is run again whenever the overrides change or Elasticsearch is recreated. The
+// IndexTemplatesApplied condition of the LogStorage tells the log collector when it can deploy fluentd. The rest of
+// the reconciliation proceeds while the job runs or after it failed, which the condition reports.
+//
+// It returns a reconcile.Result, a 'proceed' bool indicating if the Reconcile function should proceed with the
+// reconcile process, and an error.
+func (r *ReconcileLogStorage) applyIndexTemplates(
+	ls *operatorv1.LogStorage,
+	install *operatorv1.InstallationSpec,
+	variant operatorv1.ProductVariant,
+	pullSecrets []*corev1.Secret,
+	trustedBundle certificatemanagement.TrustedBundle,
+	hdler utils.ComponentHandler,
+	reqLogger logr.Logger,
+	ctx context.Context,
+) (reconcile.Result, bool, error) {
+	enabled := ls != nil && ls.DeletionTimestamp == nil && ls.Spec.IndexTemplates != nil
+
+	cfg := &render.ElasticsearchIndexTemplatesConfiguration{
+		LogStorage:    ls,
+		Installation:  install,
+		PullSecrets:   pullSecrets,
+		TrustedBundle: trustedBundle,
+		Provider:      r.provider,
+		Enabled:       enabled,
+	}
+	if enabled {
+		var err error
+		if cfg.Templates, err = r.getIndexTemplates(ctx, ls); err != nil {
+			reqLogger.Error(err, err.Error())
+			r.status.SetDegraded("Failed to get the index templates", err.Error())
+			return reconcile.Result{}, false, err
+		}
+		es, err := r.getElasticsearch(ctx)
+		if err != nil {
+			reqLogger.Error(err, "Failed to get Elasticsearch")
+			r.status.SetDegraded("Failed to get Elasticsearch", err.Error())
+			return reconcile.Result{}, false, err
+		}
+		if es == nil {
+			r.status.SetDegraded("Waiting for Elasticsearch to apply the index templates", "")
+			return reconcile.Result{}, false, nil
+		}
+		cfg.ElasticsearchUID = es.UID
+	} else if ls != nil {
+		meta.RemoveStatusCondition(&ls.Status.Conditions, operatorv1.LogStorageConditionIndexTemplatesApplied)
+	}
+
+	userSecret, err := utils.GetSecret(ctx, r.client, render.ElasticsearchIndexTemplatesUserSecret, common.OperatorNamespace())
+	if err != nil {
+		reqLogger.Error(err, "Failed to get the Elasticsearch user secret of the index templates job")
+		r.status.SetDegraded("Failed to get the Elasticsearch user secret of the index templates job", err.Error())
+		return reconcile.Result{}, false, err
+	}
+	if enabled && userSecret == nil {
+		userSecret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      render.ElasticsearchIndexTemplatesUserSecret,
+				Namespace: common.OperatorNamespace(),
+			},
+			Data: map[string][]byte{
+				"username": []byte(render.ElasticsearchIndexTemplatesUserName),
+				"password": []byte(crypto.GeneratePassword(16)),
+			},
+		}
+	}
+	// The user is deleted together with its secret once the index templates are no longer overridden. It goes away
+	// with Elasticsearch when the LogStorage is removed.
+	if userSecret != nil && ls != nil && ls.DeletionTimestamp == nil {
+		if result, proceed, err := r.applyIndexTemplatesUser(enabled, userSecret, reqLogger, ctx); err != nil || !proceed {
+			return result, proceed, err
+		}
+	}
+	cfg.UserSecret = userSecret
+
+	indexTemplatesComponent := render.ElasticsearchIndexTemplates(cfg)
+	if err := imageset.ApplyImageSet(ctx, r.client, variant, indexTemplatesComponent); err != nil {
+		reqLogger.Error(err, "Error with images from ImageSet")
+		r.status.SetDegraded("Error with images from ImageSet", err.Error())
+		return reconcile.Result{}, false, err
+	}
+
+	var job *batchv1.Job
+	if enabled {
+		// Jobs can't be updated, so the job is recreated when the templates or the cluster change or when the retry
+		// interval has passed after it failed.
+		job = &batchv1.Job{}
+		err := r.client.Get(ctx, client.ObjectKey{Name: render.ElasticsearchIndexTemplatesName, Namespace: render.ElasticsearchNamespace}, job)
+		if err != nil && !errors.IsNotFound(err) {
+			reqLogger.Error(err, "Failed to get the index templates job")
+			r.status.SetDegraded("Failed to get the index templates job", err.Error())
+			return reconcile.Result{}, false, err
+		}
+		if errors.IsNotFound(err) {
+			job = nil
+		} else {
+			changed := job.Spec.Template.Annotations[render.ElasticsearchIndexTemplatesHashAnnotation] != render.ElasticsearchIndexTemplatesHash(cfg)
+			failed := jobCondition(job, batchv1.JobFailed)
+			if changed || (failed != nil && time.Since(failed.LastTransitionTime.Time) > indexTemplatesRetryInterval) {
+				if err := r.client.Delete(ctx, job, client.PropagationPolicy("Background")); err != nil && !errors.IsNotFound(err) {
+					reqLogger.Error(err, "Failed to delete the index templates job")
+					r.status.SetDegraded("Failed to delete the index templates job", err.Error())
+					return reconcile.Result{}, false, err
+				}
+				setIndexTemplatesCondition(ls, metav1.ConditionFalse, "ApplyPending", "Waiting for the index templates job to be recreated")
+				return reconcile.Result{RequeueAfter: upgradePreflightPollInterval}, true, nil
+			}
+			if failed != nil {
+				reqLogger.Info("The index templates job failed", "reason", failed.Reason)
+				setIndexTemplatesCondition(ls, metav1.ConditionFalse, "ApplyFailed", fmt.Sprintf("The index templates job failed (%s), see the logs of the %s/%s job",
+					failed.Reason, render.ElasticsearchNamespace, render.ElasticsearchIndexTemplatesName))
+				return reconcile.Result{RequeueAfter: indexTemplatesRetryInterval}, true, nil
+			}
+		}
+	}
+
+	if err := hdler.CreateOrUpdateOrDelete(ctx, indexTemplatesComponent, r.status); err != nil {
+		reqLogger.Error(err, "Error creating / updating resource")
+		r.status.SetDegraded("Error creating / updating resource", err.Error())
+		return reconcile.Result{}, false, err
+	}
+
+	if enabled {
+		if job == nil || jobCondition(job, batchv1.JobComplete) == nil {
+			setIndexTemplatesCondition(ls, metav1.ConditionFalse, "ApplyPending", "Waiting for the index templates job to complete")
+			return reconcile.Result{RequeueAfter: upgradePreflightPollInterval}, true, nil
+		}
+		setIndexTemplatesCondition(ls, metav1.ConditionTrue, "Applied", "The index templates job succeeded")
+	}
+	return reconcile.Result{}, true, nil
+}
+
+// applyIndexTemplatesUser creates or updates the Elasticsearch user of the index templates job, or deletes it when
+// the index templates are no longer overridden.
+//
+// It returns a reconcile.Result, a 'proceed' bool indicating if the Reconcile function should continue, and an error.
+func (r *ReconcileLogStorage) applyIndexTemplatesUser(enabled bool, userSecret *corev1.Secret, reqLogger logr.Logger, ctx context.Context) (reconcile.Result, bool, error) {
+	esClient, err := r.esCliCreator(r.client, ctx, relasticsearch.HTTPSEndpoint(rmeta.OSTypeLinux, r.clusterDomain))
+	if err != nil {
+		reqLogger.Error(err, "failed to create the Elasticsearch client")
+		r.status.SetDegraded("Failed to connect to Elasticsearch", err.Error())
+		return reconcile.Result{}, false, err
+	}
+
+	if !enabled {
+		if err = esClient.DeleteUser(ctx, render.ElasticsearchIndexTemplatesUserName); err != nil {
+			reqLogger.Error(err, "failed to delete the Elasticsearch user of the index templates job")
+			r.status.SetDegraded("Failed to delete the Elasticsearch user of the index templates job", err.Error())
+			return reconcile.Result{}, false, err
+		}
+		return reconcile.Result{}, true, nil
+	}
+	if err = esClient.SetRole(ctx, render.ElasticsearchIndexTemplatesRoleName, indexTemplatesUserRole); err != nil {
+		reqLogger.Error(err, "failed to create or update the Elasticsearch role of the index templates job")
+		r.status.SetDegraded("Failed to create or update the Elasticsearch role of the index templates job", err.Error())
+		return reconcile.Result{}, false, err
+	}
+	username, password := string(userSecret.Data["username"]), string(userSecret.Data["password"])
+	if err = esClient.SetUser(ctx, username, password, []string{render.ElasticsearchIndexTemplatesRoleName}); err != nil {
+		reqLogger.Error(err, "failed to create or update the Elasticsearch user of the index templates job")
+		r.status.SetDegraded("Failed to create or update the Elasticsearch user of the index templates job", err.Error())
+		return reconcile.Result{}, false, err
+	}
+	return reconcile.Result{}, true, nil
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/controller/utils"
+	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
+	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+)
+
+const (
+	// ingestLatencyWindow is the time window of the indexed logs that the ingestion latency is measured over.
+	ingestLatencyWindow = 5 * time.Minute
+
+	// ingestLatencyInterval is how often the ingestion latency is measured, so that each measurement covers the logs
+	// indexed since the previous one.
+	ingestLatencyInterval = ingestLatencyWindow
+)
+
+// ingestLatencyBuckets are the upper bounds, in seconds, of the buckets of the ingestion latency histogram.
+var ingestLatencyBuckets = []float64{1, 5, 10, 30, 60, 120, 300, 600, 1800}
+
+var ingestLatencyDesc = prometheus.NewDesc(
+	"tigera_operator_log_ingestion_latency_seconds",
+	"Time between logs being written on the nodes and being indexed in Elasticsearch, for the logs indexed in the last five minutes.",
+	[]string{"log_type"},
+	nil,
+)
+
+// ingestLatencyCollector exports the most recent measurement of the ingestion latency of each log type as a histogram.
+type ingestLatencyCollector struct {
+	lock      sync.Mutex
+	latencies map[string]*utils.IngestLatency
+}
+
+var ingestLatencyMetrics = &ingestLatencyCollector{latencies: map[string]*utils.IngestLatency{}}
+
+func init() {
+	metrics.Registry.MustRegister(ingestLatencyMetrics)
+}
+
+func (c *ingestLatencyCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- ingestLatencyDesc
+}
+
+func (c *ingestLatencyCollector) Collect(ch chan<- prometheus.Metric) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for logType, latency := range c.latencies {
+		ch <- prometheus.MustNewConstHistogram(ingestLatencyDesc, latency.Count, latency.Sum.Seconds(), latency.Buckets, logType)
+	}
+}
+
+func (c *ingestLatencyCollector) set(logType string, latency *utils.IngestLatency) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.latencies[logType] = latency
+}
+
+func (c *ingestLatencyCollector) reset() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.latencies = map[string]*utils.IngestLatency{}
+}
+
+// applyIngestionLatency measures the ingestion latency of flow and DNS logs when it is enabled in LogStorage, and
+// degrades LogStorage while the 99th percentile of the latency exceeds the SLO. The latency is measured once per
+// interval, when the ingest latency pipeline is also checked, and the returned result requeues the request for the next
+// measurement. The pipeline is otherwise only set up or removed when the ingestion latency is enabled or disabled.
+func (r *ReconcileLogStorage) applyIngestionLatency(ls *operatorv1.LogStorage, reqLogger logr.Logger, ctx context.Context) (reconcile.Result, bool, error) {
+	enabled := ls.Spec.IngestionLatency != nil
+	now := time.Now()
+	due := enabled && now.Sub(r.ingestLatencyMeasuredAt) >= ingestLatencyInterval
+	changed := r.ingestLatencyPipelineEnabled == nil || *r.ingestLatencyPipelineEnabled != enabled
+	if !enabled {
+		ingestLatencyMetrics.reset()
+		r.ingestLatencyMeasuredAt = time.Time{}
+	}
+	if !due && !changed {
+		return r.ingestLatencyResult(ls, now)
+	}
+
+	esClient, err := r.esCliCreator(r.client, ctx, relasticsearch.HTTPSEndpoint(rmeta.OSTypeLinux, r.clusterDomain))
+	if err != nil {
+		reqLogger.Error(err, "failed to create the Elasticsearch client")
+		r.status.SetDegraded("Failed to connect to Elasticsearch", err.Error())
+		return reconcile.Result{}, false, err
+	}
+
+	if err = esClient.SetIngestLatencyPipeline(ctx, enabled); err != nil {
+		reqLogger.Error(err, "failed to create or update the Elasticsearch ingest latency pipeline")
+		r.status.SetDegraded("Failed to create or update the Elasticsearch ingest latency pipeline", err.Error())
+		return reconcile.Result{}, false, err
+	}
+	r.ingestLatencyPipelineEnabled = &enabled
+	if !enabled {
+		return reconcile.Result{}, true, nil
+	}
+
+	var p99 time.Duration
+	for logType, indexPattern := range utils.IngestLatencyIndexPatterns {
+		latency, err := esClient.IngestLatency(ctx, indexPattern, ingestLatencyWindow, ingestLatencyBuckets)
+		if err != nil {
+			reqLogger.Error(err, "failed to measure the ingestion latency of logs", "logType", logType)
+			r.status.SetDegraded("Failed to measure the ingestion latency of logs", err.Error())
+			return reconcile.Result{}, false, err
+		}
+		ingestLatencyMetrics.set(logType, latency)
+		if latency.P99 > p99 {
+			p99 = latency.P99
+		}
+	}
+
+	r.ingestLatencyMeasuredAt = now
+	r.ingestLatencyP99 = p99
+	return r.ingestLatencyResult(ls, now)
+}
+
+// ingestLatencyResult compares the last measurement of the ingestion latency with the SLO, and requeues the request for
+// the next measurement.
+func (r *ReconcileLogStorage) ingestLatencyResult(ls *operatorv1.LogStorage, now time.Time) (reconcile.Result, bool, error) {
+	if ls.Spec.IngestionLatency == nil {
+		return reconcile.Result{}, true, nil
+	}
+	result := reconcile.Result{RequeueAfter: r.ingestLatencyMeasuredAt.Add(ingestLatencyInterval).Sub(now)}
+	if slo := ls.Spec.IngestionLatency.SLO; slo != nil && r.ingestLatencyP99 > slo.Duration {
+		r.status.SetDegraded("Log ingestion latency SLO exceeded", fmt.Sprintf("The 99th percentile of the ingestion latency is %s, which exceeds the SLO of %s", r.ingestLatencyP99, slo.Duration))
+		return result, false, nil
+	}
+	return result, true, nil
+}
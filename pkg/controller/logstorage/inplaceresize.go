@@ -0,0 +1,138 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/tigera/operator/pkg/controller/utils"
+)
+
+// inPlaceResizeEnabled returns whether the resources of the Elasticsearch pods are resized in place rather than by
+// letting ECK restart the pods.
+func (r *ReconcileLogStorage) inPlaceResizeEnabled() bool {
+	return r.clientset != nil && r.kubernetesVersion.SupportsInPlacePodResize() && atomic.LoadInt32(&r.inPlaceResizeUnsupported) == 0
+}
+
+// resizeElasticsearchPods resizes the running pods of the NodeSets of the Elasticsearch cluster in place when only the
+// resources of the pod template of their StatefulSet changed from the revision that they run. The pods are labeled with
+// the update revision of the StatefulSet once resized, so that ECK considers them to be up to date and doesn't restart
+// them. Pods with other changes are left to the rolling restart of ECK.
+//
+// It returns whether the StatefulSets were checked, which they aren't until the StatefulSet controller observed their
+// latest pod template. If the API server rejects a resize, in-place resizing is disabled and ECK restarts the pods
+// instead.
+func (r *ReconcileLogStorage) resizeElasticsearchPods(ctx context.Context, elasticsearch *esv1.Elasticsearch) (bool, error) {
+	if elasticsearch == nil {
+		return true, nil
+	}
+	for _, nodeSet := range elasticsearch.Spec.NodeSets {
+		sts := &appsv1.StatefulSet{}
+		err := r.client.Get(ctx, client.ObjectKey{Name: esv1.StatefulSet(elasticsearch.Name, nodeSet.Name), Namespace: elasticsearch.Namespace}, sts)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return false, err
+		}
+		if sts.Status.ObservedGeneration < sts.Generation {
+			return false, nil
+		}
+		if sts.Status.UpdateRevision == "" || sts.Status.UpdateRevision == sts.Status.CurrentRevision {
+			continue
+		}
+
+		selector, err := metav1.LabelSelectorAsSelector(sts.Spec.Selector)
+		if err != nil {
+			return false, err
+		}
+		pods := &corev1.PodList{}
+		if err := r.client.List(ctx, pods, client.InNamespace(sts.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return false, err
+		}
+
+		for i := range pods.Items {
+			pod := &pods.Items[i]
+			revision := pod.Labels[appsv1.StatefulSetRevisionLabel]
+			if !metav1.IsControlledBy(pod, sts) || pod.DeletionTimestamp != nil || revision == sts.Status.UpdateRevision {
+				continue
+			}
+			resourcesOnly, err := r.onlyResourcesChanged(ctx, sts, revision)
+			if err != nil {
+				return false, err
+			}
+			if !resourcesOnly {
+				continue
+			}
+
+			if err := utils.ResizePod(ctx, r.clientset, r.kubernetesVersion, pod, &sts.Spec.Template); err != nil {
+				log.Error(err, "Failed to resize the Elasticsearch pod in place, falling back to restarting the pods", "pod", pod.Name)
+				atomic.StoreInt32(&r.inPlaceResizeUnsupported, 1)
+				return true, nil
+			}
+
+			patchFrom := client.MergeFrom(pod.DeepCopy())
+			pod.Labels[appsv1.StatefulSetRevisionLabel] = sts.Status.UpdateRevision
+			if err := r.client.Patch(ctx, pod, patchFrom); err != nil {
+				return false, err
+			}
+		}
+	}
+	return true, nil
+}
+
+// onlyResourcesChanged returns whether the pod template of the given revision of the StatefulSet only differs from its
+// current pod template in the resources of the containers. A revision that no longer exists is reported as changed.
+func (r *ReconcileLogStorage) onlyResourcesChanged(ctx context.Context, sts *appsv1.StatefulSet, revision string) (bool, error) {
+	rev := &appsv1.ControllerRevision{}
+	if err := r.client.Get(ctx, client.ObjectKey{Name: revision, Namespace: sts.Namespace}, rev); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	// The revisions of a StatefulSet hold a patch that replaces its pod template.
+	var data struct {
+		Spec struct {
+			Template corev1.PodTemplateSpec `json:"template"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal(rev.Data.Raw, &data); err != nil {
+		return false, err
+	}
+	return equality.Semantic.DeepEqual(withoutResources(&data.Spec.Template), withoutResources(&sts.Spec.Template)), nil
+}
+
+// withoutResources returns a copy of the pod template without the resources of its containers.
+func withoutResources(template *corev1.PodTemplateSpec) *corev1.PodTemplateSpec {
+	t := template.DeepCopy()
+	for i := range t.Spec.InitContainers {
+		t.Spec.InitContainers[i].Resources = corev1.ResourceRequirements{}
+	}
+	for i := range t.Spec.Containers {
+		t.Spec.Containers[i].Resources = corev1.ResourceRequirements{}
+	}
+	return t
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kfake "k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/tigera/operator/pkg/apis"
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/render"
+)
+
+var _ = Describe("Elasticsearch in-place resize tests", func() {
+	var c client.Client
+	var clientset *kfake.Clientset
+	var ctx context.Context
+	var r ReconcileLogStorage
+	var es *esv1.Elasticsearch
+	var sts *appsv1.StatefulSet
+	var pod *corev1.Pod
+
+	stsName := esv1.StatefulSet(render.ElasticsearchName, "default")
+	labels := map[string]string{"elasticsearch.k8s.elastic.co/statefulset-name": stsName}
+
+	template := func(memory string, env ...corev1.EnvVar) corev1.PodTemplateSpec {
+		return corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: labels},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Name: "elasticsearch",
+					Env:  env,
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse(memory)},
+					},
+				}},
+			},
+		}
+	}
+
+	createRevision := func(name string, t corev1.PodTemplateSpec) {
+		raw, err := json.Marshal(map[string]interface{}{"spec": map[string]interface{}{"template": t}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Create(ctx, &appsv1.ControllerRevision{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: render.ElasticsearchNamespace},
+			Data:       runtime.RawExtension{Raw: raw},
+		})).NotTo(HaveOccurred())
+	}
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(apis.AddToScheme(scheme)).NotTo(HaveOccurred())
+		Expect(appsv1.SchemeBuilder.AddToScheme(scheme)).ShouldNot(HaveOccurred())
+		Expect(corev1.SchemeBuilder.AddToScheme(scheme)).ShouldNot(HaveOccurred())
+		c = fake.NewClientBuilder().WithScheme(scheme).Build()
+		ctx = context.Background()
+
+		es = &esv1.Elasticsearch{
+			ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchName, Namespace: render.ElasticsearchNamespace},
+			Spec:       esv1.ElasticsearchSpec{NodeSets: []esv1.NodeSet{{Name: "default"}}},
+		}
+
+		sts = &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: stsName, Namespace: render.ElasticsearchNamespace, UID: "sts-uid"},
+			Spec: appsv1.StatefulSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+				Template: template("4Gi"),
+			},
+		}
+		Expect(c.Create(ctx, sts)).NotTo(HaveOccurred())
+		sts.Status = appsv1.StatefulSetStatus{ObservedGeneration: sts.Generation, CurrentRevision: stsName + "-old", UpdateRevision: stsName + "-new"}
+		Expect(c.Status().Update(ctx, sts)).NotTo(HaveOccurred())
+		createRevision(stsName+"-new", sts.Spec.Template)
+
+		podLabels := map[string]string{appsv1.StatefulSetRevisionLabel: stsName + "-old"}
+		for k, v := range labels {
+			podLabels[k] = v
+		}
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            stsName + "-0",
+				Namespace:       render.ElasticsearchNamespace,
+				Labels:          podLabels,
+				OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(sts, appsv1.SchemeGroupVersion.WithKind("StatefulSet"))},
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "elasticsearch"}},
+			},
+		}
+		Expect(c.Create(ctx, pod)).NotTo(HaveOccurred())
+		clientset = kfake.NewSimpleClientset(pod.DeepCopy())
+
+		r = ReconcileLogStorage{
+			client:            c,
+			clientset:         clientset,
+			kubernetesVersion: &common.VersionInfo{Major: 1, Minor: 27},
+		}
+	})
+
+	podRevision := func() string {
+		current := &corev1.Pod{}
+		Expect(c.Get(ctx, types.NamespacedName{Name: pod.Name, Namespace: pod.Namespace}, current)).NotTo(HaveOccurred())
+		return current.Labels[appsv1.StatefulSetRevisionLabel]
+	}
+
+	It("should resize the pods in place when only their resources changed and label them with the update revision", func() {
+		createRevision(stsName+"-old", template("2Gi"))
+
+		Expect(r.inPlaceResizeEnabled()).To(BeTrue())
+		resized, err := r.resizeElasticsearchPods(ctx, es)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resized).To(BeTrue())
+
+		patched, err := clientset.CoreV1().Pods(render.ElasticsearchNamespace).Get(ctx, pod.Name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(patched.Spec.Containers[0].Resources.Requests.Memory().String()).To(Equal("4Gi"))
+		Expect(podRevision()).To(Equal(stsName + "-new"))
+	})
+
+	It("should leave the pods to ECK when more than their resources changed", func() {
+		createRevision(stsName+"-old", template("2Gi", corev1.EnvVar{Name: "ES_JAVA_OPTS", Value: "-Xms1G -Xmx1G"}))
+
+		resized, err := r.resizeElasticsearchPods(ctx, es)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resized).To(BeTrue())
+		Expect(clientset.Actions()).To(BeEmpty())
+		Expect(podRevision()).To(Equal(stsName + "-old"))
+	})
+
+	It("should fall back to restarting the pods when the resize is rejected", func() {
+		createRevision(stsName+"-old", template("2Gi"))
+		clientset.PrependReactor("patch", "pods", func(action ktesting.Action) (bool, runtime.Object, error) {
+			return true, nil, fmt.Errorf("pod updates may not change fields other than the image")
+		})
+
+		resized, err := r.resizeElasticsearchPods(ctx, es)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resized).To(BeTrue())
+		Expect(r.inPlaceResizeEnabled()).To(BeFalse())
+		Expect(podRevision()).To(Equal(stsName + "-old"))
+	})
+
+	It("should not resize in place on clusters that don't support it", func() {
+		r.kubernetesVersion = &common.VersionInfo{Major: 1, Minor: 26}
+		Expect(r.inPlaceResizeEnabled()).To(BeFalse())
+	})
+})
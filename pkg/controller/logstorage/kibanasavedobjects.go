@@ -0,0 +1,221 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/controller/utils"
+	"github.com/tigera/operator/pkg/controller/utils/imageset"
+	"github.com/tigera/operator/pkg/crypto"
+	"github.com/tigera/operator/pkg/render"
+	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
+	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/tls/certificatemanagement"
+)
+
+// kibanaSavedObjectsRetryInterval is how long after failing the job of the Kibana saved objects is run again.
+const kibanaSavedObjectsRetryInterval = 5 * time.Minute
+
+// getKibanaSavedObjectsConfigMaps returns the ConfigMaps of the saved objects of the LogStorage in the operator
+// namespace, by name. It returns an error if a ConfigMap is missing.
+func (r *ReconcileLogStorage) getKibanaSavedObjectsConfigMaps(ctx context.Context, ls *operatorv1.LogStorage) (map[string]*corev1.ConfigMap, error) {
+	configMaps := map[string]*corev1.ConfigMap{}
+	for _, source := range ls.Spec.Kibana.SavedObjects {
+		cm := &corev1.ConfigMap{}
+		if err := r.client.Get(ctx, client.ObjectKey{Name: source.ConfigMapName, Namespace: common.OperatorNamespace()}, cm); err != nil {
+			if errors.IsNotFound(err) {
+				return nil, fmt.Errorf("ConfigMap %s/%s of the Kibana saved objects not found", common.OperatorNamespace(), source.ConfigMapName)
+			}
+			return nil, err
+		}
+		configMaps[cm.Name] = cm
+	}
+	return configMaps, nil
+}
+
+// importKibanaSavedObjects runs the job that imports the Kibana saved objects of the LogStorage, once Kibana is
+// operational. The job is run again whenever the saved objects change, or Elasticsearch or Kibana is recreated.
+//
+// It returns a reconcile.Result, a 'proceed' bool indicating if the Reconcile function should proceed with the
+// reconcile process, and an error.
+func (r *ReconcileLogStorage) importKibanaSavedObjects(
+	ls *operatorv1.LogStorage,
+	install *operatorv1.InstallationSpec,
+	variant operatorv1.ProductVariant,
+	pullSecrets []*corev1.Secret,
+	trustedBundle certificatemanagement.TrustedBundle,
+	hdler utils.ComponentHandler,
+	reqLogger logr.Logger,
+	ctx context.Context,
+) (reconcile.Result, bool, error) {
+	enabled := ls != nil && ls.DeletionTimestamp == nil && ls.Spec.Kibana != nil && len(ls.Spec.Kibana.SavedObjects) > 0
+
+	cfg := &render.KibanaSavedObjectsConfiguration{
+		LogStorage:    ls,
+		Installation:  install,
+		PullSecrets:   pullSecrets,
+		TrustedBundle: trustedBundle,
+		Provider:      r.provider,
+		Enabled:       enabled,
+	}
+	if enabled {
+		var err error
+		if cfg.ConfigMaps, err = r.getKibanaSavedObjectsConfigMaps(ctx, ls); err != nil {
+			reqLogger.Error(err, err.Error())
+			r.status.SetDegraded("Failed to get the ConfigMaps of the Kibana saved objects", err.Error())
+			return reconcile.Result{}, false, err
+		}
+		es, err := r.getElasticsearch(ctx)
+		if err != nil {
+			reqLogger.Error(err, "Failed to get Elasticsearch")
+			r.status.SetDegraded("Failed to get Elasticsearch", err.Error())
+			return reconcile.Result{}, false, err
+		}
+		kb, err := r.getKibana(ctx)
+		if err != nil {
+			reqLogger.Error(err, "Failed to get Kibana")
+			r.status.SetDegraded("Failed to get Kibana", err.Error())
+			return reconcile.Result{}, false, err
+		}
+		if es == nil || kb == nil {
+			r.status.SetDegraded("Waiting for Elasticsearch and Kibana to import the Kibana saved objects", "")
+			return reconcile.Result{}, false, nil
+		}
+		cfg.ElasticsearchUID, cfg.KibanaUID = es.UID, kb.UID
+	}
+
+	userSecret, err := utils.GetSecret(ctx, r.client, render.KibanaSavedObjectsUserSecret, common.OperatorNamespace())
+	if err != nil {
+		reqLogger.Error(err, "Failed to get the Elasticsearch user secret of the Kibana saved objects job")
+		r.status.SetDegraded("Failed to get the Elasticsearch user secret of the Kibana saved objects job", err.Error())
+		return reconcile.Result{}, false, err
+	}
+	// The objects of the job and its user are cleaned up once when the last saved objects are removed, which deletes
+	// the user secret.
+	if !enabled && userSecret == nil {
+		return reconcile.Result{}, true, nil
+	}
+	if ls != nil && ls.DeletionTimestamp == nil {
+		if enabled && userSecret == nil {
+			userSecret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      render.KibanaSavedObjectsUserSecret,
+					Namespace: common.OperatorNamespace(),
+				},
+				Data: map[string][]byte{
+					"username": []byte(render.KibanaSavedObjectsUserName),
+					"password": []byte(crypto.GeneratePassword(16)),
+				},
+			}
+		}
+		if result, proceed, err := r.applyKibanaSavedObjectsUser(enabled, userSecret, reqLogger, ctx); err != nil || !proceed {
+			return result, proceed, err
+		}
+	}
+	cfg.UserSecret = userSecret
+
+	savedObjectsComponent := render.KibanaSavedObjects(cfg)
+	if err := imageset.ApplyImageSet(ctx, r.client, variant, savedObjectsComponent); err != nil {
+		reqLogger.Error(err, "Error with images from ImageSet")
+		r.status.SetDegraded("Error with images from ImageSet", err.Error())
+		return reconcile.Result{}, false, err
+	}
+
+	if enabled {
+		// Jobs can't be updated, so the job is recreated when the saved objects or the clusters change or when the
+		// retry interval has passed after it failed.
+		job := &batchv1.Job{}
+		err := r.client.Get(ctx, client.ObjectKey{Name: render.KibanaSavedObjectsName, Namespace: render.ElasticsearchNamespace}, job)
+		if err != nil && !errors.IsNotFound(err) {
+			reqLogger.Error(err, "Failed to get the Kibana saved objects job")
+			r.status.SetDegraded("Failed to get the Kibana saved objects job", err.Error())
+			return reconcile.Result{}, false, err
+		}
+		if err == nil {
+			changed := job.Spec.Template.Annotations[render.KibanaSavedObjectsHashAnnotation] != render.KibanaSavedObjectsHash(cfg)
+			failed := jobCondition(job, batchv1.JobFailed)
+			if changed || (failed != nil && time.Since(failed.LastTransitionTime.Time) > kibanaSavedObjectsRetryInterval) {
+				if err := r.client.Delete(ctx, job, client.PropagationPolicy("Background")); err != nil && !errors.IsNotFound(err) {
+					reqLogger.Error(err, "Failed to delete the Kibana saved objects job")
+					r.status.SetDegraded("Failed to delete the Kibana saved objects job", err.Error())
+					return reconcile.Result{}, false, err
+				}
+				r.status.SetDegraded("Waiting for the Kibana saved objects job to be recreated", "")
+				return reconcile.Result{RequeueAfter: upgradePreflightPollInterval}, false, nil
+			}
+			if failed != nil {
+				r.status.SetDegraded("Failed to import the Kibana saved objects",
+					fmt.Sprintf("see the logs of the %s/%s job", render.ElasticsearchNamespace, render.KibanaSavedObjectsName))
+				return reconcile.Result{RequeueAfter: kibanaSavedObjectsRetryInterval}, false, nil
+			}
+		}
+	}
+
+	if err := hdler.CreateOrUpdateOrDelete(ctx, savedObjectsComponent, r.status); err != nil {
+		reqLogger.Error(err, "Error creating / updating resource")
+		r.status.SetDegraded("Error creating / updating resource", err.Error())
+		return reconcile.Result{}, false, err
+	}
+	return reconcile.Result{}, true, nil
+}
+
+// applyKibanaSavedObjectsUser creates or updates the Elasticsearch user of the job of the Kibana saved objects, or
+// deletes it when there are no saved objects. The user has the same privileges as the user of the job of the Kibana
+// spaces: it can manage the saved objects of Kibana, but it can't read the log indices or manage the security of
+// Elasticsearch.
+//
+// It returns a reconcile.Result, a 'proceed' bool indicating if the Reconcile function should continue, and an error.
+func (r *ReconcileLogStorage) applyKibanaSavedObjectsUser(enabled bool, userSecret *corev1.Secret, reqLogger logr.Logger, ctx context.Context) (reconcile.Result, bool, error) {
+	esClient, err := r.esCliCreator(r.client, ctx, relasticsearch.HTTPSEndpoint(rmeta.OSTypeLinux, r.clusterDomain))
+	if err != nil {
+		reqLogger.Error(err, "failed to create the Elasticsearch client")
+		r.status.SetDegraded("Failed to connect to Elasticsearch", err.Error())
+		return reconcile.Result{}, false, err
+	}
+
+	if !enabled {
+		if err = esClient.DeleteUser(ctx, render.KibanaSavedObjectsUserName); err != nil {
+			reqLogger.Error(err, "failed to delete the Elasticsearch user of the Kibana saved objects job")
+			r.status.SetDegraded("Failed to delete the Elasticsearch user of the Kibana saved objects job", err.Error())
+			return reconcile.Result{}, false, err
+		}
+		return reconcile.Result{}, true, nil
+	}
+	if err = esClient.SetRole(ctx, render.KibanaSavedObjectsRoleName, kibanaSpacesUserRole); err != nil {
+		reqLogger.Error(err, "failed to create or update the Elasticsearch role of the Kibana saved objects job")
+		r.status.SetDegraded("Failed to create or update the Elasticsearch role of the Kibana saved objects job", err.Error())
+		return reconcile.Result{}, false, err
+	}
+	username, password := string(userSecret.Data["username"]), string(userSecret.Data["password"])
+	if err = esClient.SetUser(ctx, username, password, []string{render.KibanaSavedObjectsRoleName}); err != nil {
+		reqLogger.Error(err, "failed to create or update the Elasticsearch user of the Kibana saved objects job")
+		r.status.SetDegraded("Failed to create or update the Elasticsearch user of the Kibana saved objects job", err.Error())
+		return reconcile.Result{}, false, err
+	}
+	return reconcile.Result{}, true, nil
+}
@@ -0,0 +1,218 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/controller/utils"
+	"github.com/tigera/operator/pkg/controller/utils/imageset"
+	"github.com/tigera/operator/pkg/crypto"
+	"github.com/tigera/operator/pkg/render"
+	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
+	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/tls/certificatemanagement"
+)
+
+// kibanaSpacesRetryInterval is how long after failing the job of the Kibana spaces is run again.
+const kibanaSpacesRetryInterval = 5 * time.Minute
+
+// kibanaSpacesUserRole is the Elasticsearch role of the user of the job of the Kibana spaces. It can manage the Kibana
+// spaces and their saved objects, but it can't read the log indices or manage the security of Elasticsearch.
+var kibanaSpacesUserRole = map[string]interface{}{
+	"applications": []interface{}{
+		map[string]interface{}{
+			"application": "kibana-.kibana",
+			"privileges":  []string{"all"},
+			"resources":   []string{"*"},
+		},
+	},
+}
+
+// validateKibanaSpaces returns an error if a Kibana space of the LogStorage refers to a tenant role that doesn't exist,
+// or has no tenant role while the tenancy of the LogStorage is isolated.
+func validateKibanaSpaces(ls *operatorv1.LogStorage) error {
+	tenantRoles := map[string]bool{}
+	for _, role := range ls.Spec.TenantRoles {
+		tenantRoles[role.Name] = true
+	}
+	for _, tenant := range ls.Spec.Tenants() {
+		tenantRoles[tenant.Name] = true
+	}
+	for _, space := range ls.Spec.KibanaSpaces {
+		if space.TenantRole != "" && !tenantRoles[space.TenantRole] {
+			return fmt.Errorf("Kibana space %q refers to tenant role %q, which is not defined", space.ID, space.TenantRole)
+		}
+		if space.TenantRole == "" && ls.Spec.TenancyIsolated() {
+			return fmt.Errorf("Kibana space %q has no tenant role, which the isolated tenancy requires", space.ID)
+		}
+	}
+	return nil
+}
+
+// createKibanaSpaces runs the job that creates the Kibana spaces of the LogStorage, once Kibana is operational. The
+// job is run again whenever the spaces change. The roles and role mappings that grant access to the spaces are managed
+// by the operator, so that those of the spaces that are removed are deleted.
+//
+// It returns a reconcile.Result, a 'proceed' bool indicating if the Reconcile function should proceed with the
+// reconcile process, and an error.
+func (r *ReconcileLogStorage) createKibanaSpaces(
+	ls *operatorv1.LogStorage,
+	install *operatorv1.InstallationSpec,
+	variant operatorv1.ProductVariant,
+	pullSecrets []*corev1.Secret,
+	trustedBundle certificatemanagement.TrustedBundle,
+	hdler utils.ComponentHandler,
+	reqLogger logr.Logger,
+	ctx context.Context,
+) (reconcile.Result, bool, error) {
+	enabled := ls != nil && ls.DeletionTimestamp == nil && len(render.LogStorageKibanaSpaces(ls)) > 0
+	if enabled {
+		if err := validateKibanaSpaces(ls); err != nil {
+			r.status.SetDegraded("Invalid Kibana spaces", err.Error())
+			return reconcile.Result{}, false, nil
+		}
+	}
+
+	userSecret, err := utils.GetSecret(ctx, r.client, render.KibanaSpacesUserSecret, common.OperatorNamespace())
+	if err != nil {
+		reqLogger.Error(err, "Failed to get the Elasticsearch user secret of the Kibana spaces job")
+		r.status.SetDegraded("Failed to get the Elasticsearch user secret of the Kibana spaces job", err.Error())
+		return reconcile.Result{}, false, err
+	}
+	// The roles and the user are cleaned up once when the last space is removed, which deletes the user secret.
+	if ls != nil && ls.DeletionTimestamp == nil && (enabled || userSecret != nil) {
+		if enabled && userSecret == nil {
+			userSecret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      render.KibanaSpacesUserSecret,
+					Namespace: common.OperatorNamespace(),
+				},
+				Data: map[string][]byte{
+					"username": []byte(render.KibanaSpacesUserName),
+					"password": []byte(crypto.GeneratePassword(16)),
+				},
+			}
+		}
+		if result, proceed, err := r.applyKibanaSpaceRoles(ls, enabled, userSecret, reqLogger, ctx); err != nil || !proceed {
+			return result, proceed, err
+		}
+	}
+
+	spacesComponent := render.KibanaSpaces(&render.KibanaSpacesConfiguration{
+		LogStorage:    ls,
+		Installation:  install,
+		PullSecrets:   pullSecrets,
+		TrustedBundle: trustedBundle,
+		Provider:      r.provider,
+		UserSecret:    userSecret,
+		Enabled:       enabled,
+	})
+	if err := imageset.ApplyImageSet(ctx, r.client, variant, spacesComponent); err != nil {
+		reqLogger.Error(err, "Error with images from ImageSet")
+		r.status.SetDegraded("Error with images from ImageSet", err.Error())
+		return reconcile.Result{}, false, err
+	}
+
+	if enabled {
+		// Jobs can't be updated, so the job is recreated when the spaces change or when the retry interval has passed
+		// after it failed.
+		job := &batchv1.Job{}
+		err := r.client.Get(ctx, client.ObjectKey{Name: render.KibanaSpacesName, Namespace: render.ElasticsearchNamespace}, job)
+		if err != nil && !errors.IsNotFound(err) {
+			reqLogger.Error(err, "Failed to get the Kibana spaces job")
+			r.status.SetDegraded("Failed to get the Kibana spaces job", err.Error())
+			return reconcile.Result{}, false, err
+		}
+		if err == nil {
+			changed := job.Spec.Template.Annotations[render.KibanaSpacesHashAnnotation] != rmeta.AnnotationHash(render.LogStorageKibanaSpaces(ls))
+			failed := jobCondition(job, batchv1.JobFailed)
+			if changed || (failed != nil && time.Since(failed.LastTransitionTime.Time) > kibanaSpacesRetryInterval) {
+				if err := r.client.Delete(ctx, job, client.PropagationPolicy("Background")); err != nil && !errors.IsNotFound(err) {
+					reqLogger.Error(err, "Failed to delete the Kibana spaces job")
+					r.status.SetDegraded("Failed to delete the Kibana spaces job", err.Error())
+					return reconcile.Result{}, false, err
+				}
+				r.status.SetDegraded("Waiting for the Kibana spaces job to be recreated", "")
+				return reconcile.Result{RequeueAfter: upgradePreflightPollInterval}, false, nil
+			}
+			if failed != nil {
+				r.status.SetDegraded("Failed to create the Kibana spaces",
+					fmt.Sprintf("see the logs of the %s/%s job", render.ElasticsearchNamespace, render.KibanaSpacesName))
+				return reconcile.Result{RequeueAfter: kibanaSpacesRetryInterval}, false, nil
+			}
+		}
+	}
+
+	if err := hdler.CreateOrUpdateOrDelete(ctx, spacesComponent, r.status); err != nil {
+		reqLogger.Error(err, "Error creating / updating resource")
+		r.status.SetDegraded("Error creating / updating resource", err.Error())
+		return reconcile.Result{}, false, err
+	}
+	return reconcile.Result{}, true, nil
+}
+
+// applyKibanaSpaceRoles creates or updates the Elasticsearch roles and role mappings of the Kibana spaces, deleting
+// those of the spaces that were removed, and the Elasticsearch user of the job of the Kibana spaces, which is deleted
+// when there are no spaces.
+//
+// It returns a reconcile.Result, a 'proceed' bool indicating if the Reconcile function should continue, and an error.
+func (r *ReconcileLogStorage) applyKibanaSpaceRoles(ls *operatorv1.LogStorage, enabled bool, userSecret *corev1.Secret, reqLogger logr.Logger, ctx context.Context) (reconcile.Result, bool, error) {
+	esClient, err := r.esCliCreator(r.client, ctx, relasticsearch.HTTPSEndpoint(rmeta.OSTypeLinux, r.clusterDomain))
+	if err != nil {
+		reqLogger.Error(err, "failed to create the Elasticsearch client")
+		r.status.SetDegraded("Failed to connect to Elasticsearch", err.Error())
+		return reconcile.Result{}, false, err
+	}
+
+	if err = esClient.SetKibanaSpaceRoles(ctx, ls); err != nil {
+		reqLogger.Error(err, "failed to create or update the Elasticsearch roles of the Kibana spaces")
+		r.status.SetDegraded("Failed to create or update the Elasticsearch roles of the Kibana spaces", err.Error())
+		return reconcile.Result{}, false, err
+	}
+
+	if !enabled {
+		if err = esClient.DeleteUser(ctx, render.KibanaSpacesUserName); err != nil {
+			reqLogger.Error(err, "failed to delete the Elasticsearch user of the Kibana spaces job")
+			r.status.SetDegraded("Failed to delete the Elasticsearch user of the Kibana spaces job", err.Error())
+			return reconcile.Result{}, false, err
+		}
+		return reconcile.Result{}, true, nil
+	}
+	if err = esClient.SetRole(ctx, render.KibanaSpacesRoleName, kibanaSpacesUserRole); err != nil {
+		reqLogger.Error(err, "failed to create or update the Elasticsearch role of the Kibana spaces job")
+		r.status.SetDegraded("Failed to create or update the Elasticsearch role of the Kibana spaces job", err.Error())
+		return reconcile.Result{}, false, err
+	}
+	username, password := string(userSecret.Data["username"]), string(userSecret.Data["password"])
+	if err = esClient.SetUser(ctx, username, password, []string{render.KibanaSpacesRoleName}); err != nil {
+		reqLogger.Error(err, "failed to create or update the Elasticsearch user of the Kibana spaces job")
+		r.status.SetDegraded("Failed to create or update the Elasticsearch user of the Kibana spaces job", err.Error())
+		return reconcile.Result{}, false, err
+	}
+	return reconcile.Result{}, true, nil
+}
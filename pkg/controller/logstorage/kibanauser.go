@@ -0,0 +1,140 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	cmnv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
+	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/controller/utils"
+	"github.com/tigera/operator/pkg/crypto"
+	"github.com/tigera/operator/pkg/render"
+	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
+	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+)
+
+// kibanaElasticsearchUserRoles are the Elasticsearch roles of the user that Kibana authenticates with when it connects
+// to the Elasticsearch hosts of the LogStorage.
+var kibanaElasticsearchUserRoles = []string{"kibana_system"}
+
+// kibanaElasticsearchHostsOverridden returns whether Kibana connects to the Elasticsearch hosts of the LogStorage
+// instead of the Elasticsearch cluster that ECK associates it with.
+func kibanaElasticsearchHostsOverridden(ls *operatorv1.LogStorage) bool {
+	return ls != nil && ls.Spec.Kibana != nil && len(ls.Spec.Kibana.ElasticsearchHosts) > 0
+}
+
+// kibanaOperational returns whether Kibana is operational. Kibana reports that it is associated with Elasticsearch
+// once it is connected to it, unless it connects to the Elasticsearch hosts of the LogStorage, in which case there is
+// no association and its health is reported instead.
+func kibanaOperational(ls *operatorv1.LogStorage, kibana *kbv1.Kibana) bool {
+	if kibana == nil {
+		return false
+	}
+	if kibanaElasticsearchHostsOverridden(ls) {
+		return kibana.Status.Health == cmnv1.GreenHealth
+	}
+	return kibana.Status.AssociationStatus == cmnv1.AssociationEstablished
+}
+
+// getKibanaElasticsearchUserSecret returns the credentials of the Elasticsearch user of Kibana if the LogStorage
+// overrides the Elasticsearch hosts of Kibana, generating them if they don't exist yet. The returned secret is
+// rendered with Kibana.
+func (r *ReconcileLogStorage) getKibanaElasticsearchUserSecret(ctx context.Context, ls *operatorv1.LogStorage) (*corev1.Secret, error) {
+	if !kibanaElasticsearchHostsOverridden(ls) {
+		return nil, nil
+	}
+	userSecret, err := utils.GetSecret(ctx, r.client, render.KibanaElasticsearchUserSecret, common.OperatorNamespace())
+	if err != nil {
+		return nil, err
+	}
+	if userSecret == nil {
+		userSecret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      render.KibanaElasticsearchUserSecret,
+				Namespace: common.OperatorNamespace(),
+			},
+			Data: map[string][]byte{
+				"username": []byte(render.KibanaElasticsearchUserName),
+				"password": []byte(crypto.GeneratePassword(16)),
+			},
+		}
+	}
+	return userSecret, nil
+}
+
+// applyKibanaElasticsearchUser creates or updates the Elasticsearch user that Kibana authenticates with when it
+// connects to the Elasticsearch hosts of the LogStorage. ECK creates the user of Kibana for the association otherwise.
+//
+// It returns a reconcile.Result, a 'proceed' bool indicating if the Reconcile function should continue, and an error.
+func (r *ReconcileLogStorage) applyKibanaElasticsearchUser(userSecret *corev1.Secret, reqLogger logr.Logger, ctx context.Context) (reconcile.Result, bool, error) {
+	esClient, err := r.esCliCreator(r.client, ctx, relasticsearch.HTTPSEndpoint(rmeta.OSTypeLinux, r.clusterDomain))
+	if err != nil {
+		reqLogger.Error(err, "failed to create the Elasticsearch client")
+		r.status.SetDegraded("Failed to connect to Elasticsearch", err.Error())
+		return reconcile.Result{}, false, err
+	}
+
+	username, password := string(userSecret.Data["username"]), string(userSecret.Data["password"])
+	if err = esClient.SetUser(ctx, username, password, kibanaElasticsearchUserRoles); err != nil {
+		reqLogger.Error(err, "failed to create or update the Elasticsearch user of Kibana")
+		r.status.SetDegraded("Failed to create or update the Elasticsearch user of Kibana", err.Error())
+		return reconcile.Result{}, false, err
+	}
+	return reconcile.Result{}, true, nil
+}
+
+// deleteKibanaElasticsearchUser deletes the Elasticsearch user of Kibana and its credentials once the LogStorage no
+// longer overrides the Elasticsearch hosts of Kibana. The credentials are only deleted after the user, so that the
+// deletion is retried until it succeeds.
+//
+// It returns a reconcile.Result, a 'proceed' bool indicating if the Reconcile function should continue, and an error.
+func (r *ReconcileLogStorage) deleteKibanaElasticsearchUser(reqLogger logr.Logger, ctx context.Context) (reconcile.Result, bool, error) {
+	userSecret, err := utils.GetSecret(ctx, r.client, render.KibanaElasticsearchUserSecret, common.OperatorNamespace())
+	if err != nil {
+		reqLogger.Error(err, "failed to get the credentials of the Elasticsearch user of Kibana")
+		r.status.SetDegraded("Failed to get the credentials of the Elasticsearch user of Kibana", err.Error())
+		return reconcile.Result{}, false, err
+	}
+	if userSecret == nil {
+		return reconcile.Result{}, true, nil
+	}
+
+	esClient, err := r.esCliCreator(r.client, ctx, relasticsearch.HTTPSEndpoint(rmeta.OSTypeLinux, r.clusterDomain))
+	if err != nil {
+		reqLogger.Error(err, "failed to create the Elasticsearch client")
+		r.status.SetDegraded("Failed to connect to Elasticsearch", err.Error())
+		return reconcile.Result{}, false, err
+	}
+	if err = esClient.DeleteUser(ctx, string(userSecret.Data["username"])); err != nil {
+		reqLogger.Error(err, "failed to delete the Elasticsearch user of Kibana")
+		r.status.SetDegraded("Failed to delete the Elasticsearch user of Kibana", err.Error())
+		return reconcile.Result{}, false, err
+	}
+	if err = r.client.Delete(ctx, userSecret); err != nil && !errors.IsNotFound(err) {
+		reqLogger.Error(err, "failed to delete the credentials of the Elasticsearch user of Kibana")
+		r.status.SetDegraded("Failed to delete the credentials of the Elasticsearch user of Kibana", err.Error())
+		return reconcile.Result{}, false, err
+	}
+	return reconcile.Result{}, true, nil
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/controller/utils"
+	"github.com/tigera/operator/pkg/render"
+)
+
+// licenseExpiryWarning is how long before the expiry of the enterprise license the LicenseValid condition warns of it.
+const licenseExpiryWarning = 30 * 24 * time.Hour
+
+// elasticLicense is the part of an Elastic license file that the operator reads.
+type elasticLicense struct {
+	License struct {
+		UID                string `json:"uid"`
+		Type               string `json:"type"`
+		ExpiryDateInMillis int64  `json:"expiry_date_in_millis"`
+	} `json:"license"`
+}
+
+// expiry returns the time at which the license expires.
+func (l *elasticLicense) expiry() time.Time {
+	return time.Unix(0, l.License.ExpiryDateInMillis*int64(time.Millisecond))
+}
+
+// parseElasticLicense parses a license file as it is issued by Elastic.
+func parseElasticLicense(data []byte) (*elasticLicense, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("the %s key is missing", render.ECKLicenseKey)
+	}
+	license := &elasticLicense{}
+	if err := json.Unmarshal(data, license); err != nil {
+		return nil, err
+	}
+	if license.License.ExpiryDateInMillis == 0 {
+		return nil, fmt.Errorf("the license has no expiry date")
+	}
+	return license, nil
+}
+
+// getEnterpriseLicenseSecret returns the secret in the operator namespace that holds the enterprise license of the
+// LogStorage, and the license. It returns an error if the secret is missing, or doesn't hold a license.
+func (r *ReconcileLogStorage) getEnterpriseLicenseSecret(ctx context.Context, ls *operatorv1.LogStorage) (*corev1.Secret, *elasticLicense, error) {
+	if ls.Spec.EnterpriseLicense == nil {
+		return nil, nil, nil
+	}
+	name := ls.Spec.EnterpriseLicense.SecretName
+	s, err := utils.GetSecret(ctx, r.client, name, common.OperatorNamespace())
+	if err != nil {
+		return nil, nil, err
+	}
+	if s == nil {
+		return nil, nil, fmt.Errorf("secret %s/%s for the enterprise license not found", common.OperatorNamespace(), name)
+	}
+	license, err := parseElasticLicense(s.Data[render.ECKLicenseKey])
+	if err != nil {
+		return nil, nil, fmt.Errorf("secret %s/%s does not hold a valid Elastic license: %w", common.OperatorNamespace(), name, err)
+	}
+	return s, license, nil
+}
+
+// licenseCondition returns the LicenseValid condition of the LogStorage for the enterprise license, and the time until
+// the condition changes because the license is about to expire or expires. The time is zero once the license expired.
+func licenseCondition(ls *operatorv1.LogStorage, license *elasticLicense, now time.Time) (metav1.Condition, time.Duration) {
+	expiry := license.expiry()
+	condition := metav1.Condition{
+		Type:               operatorv1.LogStorageConditionLicenseValid,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Valid",
+		Message:            fmt.Sprintf("The %s license expires at %s", license.License.Type, expiry.UTC().Format(time.RFC3339)),
+		ObservedGeneration: ls.Generation,
+	}
+	untilExpiry := expiry.Sub(now)
+	switch {
+	case untilExpiry <= 0:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Expired"
+		condition.Message = fmt.Sprintf("The %s license expired at %s", license.License.Type, expiry.UTC().Format(time.RFC3339))
+		return condition, 0
+	case untilExpiry <= licenseExpiryWarning:
+		condition.Reason = "ExpiringSoon"
+		return condition, untilExpiry
+	}
+	return condition, untilExpiry - licenseExpiryWarning
+}
+
+// applyEnterpriseLicense sets the expiry of the enterprise license in the status of the LogStorage, which is updated at
+// the end of the reconciliation. It returns the time after which the status needs to be updated again.
+func applyEnterpriseLicense(ls *operatorv1.LogStorage, license *elasticLicense, now time.Time) time.Duration {
+	if license == nil {
+		ls.Status.LicenseExpiry = nil
+		meta.RemoveStatusCondition(&ls.Status.Conditions, operatorv1.LogStorageConditionLicenseValid)
+		return 0
+	}
+	expiry := metav1.NewTime(license.expiry())
+	ls.Status.LicenseExpiry = &expiry
+
+	// SetStatusCondition only changes the transition time of a condition when its status changes.
+	condition, untilChange := licenseCondition(ls, license, now)
+	meta.SetStatusCondition(&ls.Status.Conditions, condition)
+	return untilChange
+}
@@ -18,32 +18,41 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"strings"
+	"time"
 
 	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
 	"github.com/tigera/operator/pkg/common"
 	"github.com/tigera/operator/pkg/controller/certificatemanager"
 	"github.com/tigera/operator/pkg/dns"
+	"github.com/tigera/operator/pkg/ptr"
 	rcertificatemanagement "github.com/tigera/operator/pkg/render/certificatemanagement"
 	"github.com/tigera/operator/pkg/tls/certificatemanagement"
 	apps "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
-	cmnv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
 	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
 	"github.com/go-logr/logr"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/controller/status"
 	"github.com/tigera/operator/pkg/controller/utils"
 	"github.com/tigera/operator/pkg/controller/utils/imageset"
 	"github.com/tigera/operator/pkg/render"
+	rcomp "github.com/tigera/operator/pkg/render/common/components"
 	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
 )
@@ -76,23 +85,99 @@ func (r *ReconcileLogStorage) createLogStorage(
 	certificateManager certificatemanager.CertificateManager,
 	applyTrial bool,
 	keyStoreSecret *corev1.Secret,
+	enterpriseLicenseSecret *corev1.Secret,
 ) (reconcile.Result, bool, bool, error) {
-	var elasticKeyPair, kibanaKeyPair certificatemanagement.KeyPairInterface
+	var elasticKeyPair, kibanaKeyPair, eckWebhookKeyPair certificatemanagement.KeyPairInterface
 	var err error
 	finalizerCleanup := false
 	var trustedBundle certificatemanagement.TrustedBundle
+	var remoteClusterCASecrets []*corev1.Secret
+	var snapshotRepositorySecret *corev1.Secret
+	var secureSettingsSecrets []*corev1.Secret
+	var kibanaUserSecret *corev1.Secret
+	var containerOverrides rcomp.ContainerOverrides
+	expandableStorageClasses := map[string]bool{}
 
 	if managementClusterConnection == nil {
-		// Check if there is a StorageClass available to run Elasticsearch on.
-		if err = r.client.Get(ctx, client.ObjectKey{Name: ls.Spec.StorageClassName}, &storagev1.StorageClass{}); err != nil {
-			if errors.IsNotFound(err) {
-				err := fmt.Errorf("couldn't find storage class %s, this must be provided", ls.Spec.StorageClassName)
-				reqLogger.Error(err, err.Error())
+		// Check if the StorageClasses to run Elasticsearch on are available, and which of them allow the volumes of
+		// Elasticsearch to be expanded.
+		for _, storageClassName := range ls.Spec.StorageClassNames() {
+			storageClass := &storagev1.StorageClass{}
+			if err = r.client.Get(ctx, client.ObjectKey{Name: storageClassName}, storageClass); err != nil {
+				if errors.IsNotFound(err) {
+					// The default storage class isn't created again while the LogStorage is being deleted.
+					if sc := defaultStorageClass(r.infrastructureProvider, storageClassName); sc != nil && ls.DeletionTimestamp == nil {
+						reqLogger.Info("Creating the default storage class of the infrastructure provider", "storageClass", storageClassName, "provisioner", sc.Provisioner)
+						if err = r.client.Create(ctx, sc); err != nil {
+							reqLogger.Error(err, "Failed to create storage class")
+							r.status.SetDegraded("Failed to create storage class", err.Error())
+							return reconcile.Result{}, false, finalizerCleanup, err
+						}
+						expandableStorageClasses[storageClassName] = sc.AllowVolumeExpansion != nil && *sc.AllowVolumeExpansion
+						continue
+					}
+					err := fmt.Errorf("couldn't find storage class %s, this must be provided", storageClassName)
+					reqLogger.Error(err, err.Error())
+					r.status.SetDegraded("Failed to get storage class", err.Error())
+					return reconcile.Result{}, false, finalizerCleanup, nil
+				}
+				reqLogger.Error(err, "Failed to get storage class")
 				r.status.SetDegraded("Failed to get storage class", err.Error())
-				return reconcile.Result{}, false, finalizerCleanup, nil
+				return reconcile.Result{}, false, finalizerCleanup, err
 			}
-			reqLogger.Error(err, "Failed to get storage class")
-			r.status.SetDegraded("Failed to get storage class", err.Error())
+			expandableStorageClasses[storageClassName] = storageClass.AllowVolumeExpansion != nil && *storageClass.AllowVolumeExpansion
+		}
+
+		if err = validateDiskWatermarks(&ls.Spec); err != nil {
+			reqLogger.Error(err, err.Error())
+			r.status.SetDegraded("Invalid disk watermarks", err.Error())
+			return reconcile.Result{}, false, finalizerCleanup, nil
+		}
+		if err = validateDisruptionPolicies(&ls.Spec); err != nil {
+			reqLogger.Error(err, err.Error())
+			r.status.SetDegraded("Invalid disruption policies", err.Error())
+			return reconcile.Result{}, false, finalizerCleanup, nil
+		}
+		if err = validatePodTemplatePatches(&ls.Spec); err != nil {
+			reqLogger.Error(err, err.Error())
+			r.status.SetDegraded("Invalid pod template patches", err.Error())
+			return reconcile.Result{}, false, finalizerCleanup, nil
+		}
+		if err = validateRemoteClusters(ls); err != nil {
+			reqLogger.Error(err, err.Error())
+			r.status.SetDegraded("Invalid remote Elasticsearch clusters", err.Error())
+			return reconcile.Result{}, false, finalizerCleanup, nil
+		}
+		if err = validateTransportTLS(&ls.Spec); err != nil {
+			reqLogger.Error(err, err.Error())
+			r.status.SetDegraded("Invalid transport TLS", err.Error())
+			return reconcile.Result{}, false, finalizerCleanup, nil
+		}
+		containerOverrides, err = rcomp.ParseContainerOverrides(ls.Annotations,
+			render.ECKOperatorContainerOverridesKey, render.ElasticsearchContainerOverridesKey, render.KibanaContainerOverridesKey)
+		if err != nil {
+			reqLogger.Error(err, err.Error())
+			r.status.SetDegraded("Invalid container overrides", err.Error())
+			return reconcile.Result{}, false, finalizerCleanup, nil
+		}
+		if remoteClusterCASecrets, err = r.getRemoteClusterCASecrets(ctx, ls); err != nil {
+			reqLogger.Error(err, err.Error())
+			r.status.SetDegraded("Failed to get the CA certificates of the remote Elasticsearch clusters", err.Error())
+			return reconcile.Result{}, false, finalizerCleanup, err
+		}
+		if snapshotRepositorySecret, err = r.getSnapshotRepositorySecret(ctx, ls); err != nil {
+			reqLogger.Error(err, err.Error())
+			r.status.SetDegraded("Failed to get the credentials of the snapshot repository", err.Error())
+			return reconcile.Result{}, false, finalizerCleanup, err
+		}
+		if secureSettingsSecrets, err = r.getSecureSettingsSecrets(ctx, ls); err != nil {
+			reqLogger.Error(err, err.Error())
+			r.status.SetDegraded("Failed to get the secrets of the secure settings", err.Error())
+			return reconcile.Result{}, false, finalizerCleanup, err
+		}
+		if kibanaUserSecret, err = r.getKibanaElasticsearchUserSecret(ctx, ls); err != nil {
+			reqLogger.Error(err, err.Error())
+			r.status.SetDegraded("Failed to get the credentials of the Elasticsearch user of Kibana", err.Error())
 			return reconcile.Result{}, false, finalizerCleanup, err
 		}
 
@@ -103,14 +188,20 @@ func (r *ReconcileLogStorage) createLogStorage(
 			return reconcile.Result{}, false, finalizerCleanup, err
 		}
 		trustedBundle = certificateManager.CreateTrustedBundle(elasticKeyPair)
-		if !operatorv1.IsFIPSModeEnabled(install.FIPSMode) {
-			kbDNSNames := dns.GetServiceDNSNames(render.KibanaServiceName, render.KibanaNamespace, r.clusterDomain)
-			if kibanaKeyPair, err = certificateManager.GetOrCreateKeyPair(r.client, render.TigeraKibanaCertSecret, common.OperatorNamespace(), kbDNSNames); err != nil {
+		kbDNSNames := dns.GetServiceDNSNames(render.KibanaServiceName, render.KibanaNamespace, r.clusterDomain)
+		if kibanaKeyPair, err = certificateManager.GetOrCreateKeyPair(r.client, render.TigeraKibanaCertSecret, common.OperatorNamespace(), kbDNSNames); err != nil {
+			reqLogger.Error(err, err.Error())
+			r.status.SetDegraded("Failed to create Kibana secrets", err.Error())
+			return reconcile.Result{}, false, finalizerCleanup, err
+		}
+		trustedBundle.AddCertificates(kibanaKeyPair)
+		if ls.Spec.ECKValidatingWebhookEnabled() {
+			webhookDNSNames := dns.GetServiceDNSNames(render.ECKWebhookServiceName, render.ECKOperatorNamespace, r.clusterDomain)
+			if eckWebhookKeyPair, err = certificateManager.GetOrCreateKeyPair(r.client, render.ECKWebhookCertSecret, common.OperatorNamespace(), webhookDNSNames); err != nil {
 				reqLogger.Error(err, err.Error())
-				r.status.SetDegraded("Failed to create Kibana secrets", err.Error())
+				r.status.SetDegraded("Failed to create the ECK webhook secret", err.Error())
 				return reconcile.Result{}, false, finalizerCleanup, err
 			}
-			trustedBundle.AddCertificates(kibanaKeyPair)
 		}
 	}
 
@@ -121,14 +212,11 @@ func (r *ReconcileLogStorage) createLogStorage(
 		return reconcile.Result{}, false, finalizerCleanup, err
 	}
 
-	var kibana *kbv1.Kibana
-	if !operatorv1.IsFIPSModeEnabled(install.FIPSMode) {
-		kibana, err = r.getKibana(ctx)
-		if err != nil {
-			reqLogger.Error(err, err.Error())
-			r.status.SetDegraded("An error occurred trying to retrieve Kibana", err.Error())
-			return reconcile.Result{}, false, finalizerCleanup, err
-		}
+	kibana, err := r.getKibana(ctx)
+	if err != nil {
+		reqLogger.Error(err, err.Error())
+		r.status.SetDegraded("An error occurred trying to retrieve Kibana", err.Error())
+		return reconcile.Result{}, false, finalizerCleanup, err
 	}
 
 	// If Authentication spec present, we use it to configure dex as an authentication proxy.
@@ -149,6 +237,19 @@ func (r *ReconcileLogStorage) createLogStorage(
 		}
 	}
 
+	// Kibana logs users in through Dex when it proxies an OIDC provider, with the client secret of the manager.
+	var dexSecret *corev1.Secret
+	if baseURL != "" && authentication != nil && authentication.Spec.OIDC != nil && authentication.Spec.OIDC.Type != operatorv1.OIDCTypeTigera {
+		if dexSecret, err = utils.GetSecret(ctx, r.client, render.DexObjectName, common.OperatorNamespace()); err != nil {
+			reqLogger.Error(err, "Failed to get the Dex secret")
+			r.status.SetDegraded("Failed to get the Dex secret", err.Error())
+			return reconcile.Result{}, false, finalizerCleanup, err
+		} else if dexSecret == nil {
+			r.status.SetDegraded(fmt.Sprintf("Waiting for secret '%s' to become available", render.DexObjectName), "")
+			return reconcile.Result{}, false, finalizerCleanup, nil
+		}
+	}
+
 	var unusedTLSSecret *corev1.Secret
 	if install.CertificateManagement != nil {
 		// Eck requires us to provide a TLS secret for Kibana and Elasticsearch. It will also inspect that it has a
@@ -166,43 +267,104 @@ func (r *ReconcileLogStorage) createLogStorage(
 		}
 	}
 
+	curatorSuspended, err := r.curatorSuspended(ctx)
+	if err != nil {
+		reqLogger.Error(err, "failed to get the curator CronJob")
+		r.status.SetDegraded("Failed to get the curator CronJob", err.Error())
+		return reconcile.Result{}, false, finalizerCleanup, err
+	}
+
+	logCollector, err := utils.GetLogCollector(ctx, r.client)
+	if err != nil {
+		reqLogger.Error(err, "failed to get the LogCollector")
+		r.status.SetDegraded("Failed to get the LogCollector", err.Error())
+		return reconcile.Result{}, false, finalizerCleanup, err
+	}
+
+	var zones []string
+	if managementClusterConnection == nil {
+		if zones, err = elasticsearchZones(ctx, r.client, ls, install); err != nil {
+			reqLogger.Error(err, "failed to get the zones of the nodes")
+			r.status.SetDegraded("Failed to get the zones of the nodes", err.Error())
+			return reconcile.Result{}, false, finalizerCleanup, err
+		}
+	}
+
+	var rolloutWindow *operatorv1.RolloutWindow
+	if ls != nil {
+		rolloutWindow = ls.Spec.RolloutWindow
+	}
+	rolloutWindowOpen, untilRolloutWindow, err := utils.RolloutWindowOpen(rolloutWindow, time.Now())
+	if err != nil {
+		reqLogger.Error(err, "Invalid rollout window")
+		r.status.SetDegraded("Invalid rollout window", err.Error())
+		return reconcile.Result{}, false, finalizerCleanup, nil
+	}
+	deferRollouts := !rolloutWindowOpen
+
+	esPodDisruptionBudgetExists, err := r.podDisruptionBudgetExists(ctx, render.ElasticsearchName, render.ElasticsearchNamespace)
+	if err != nil {
+		reqLogger.Error(err, "failed to get the Elasticsearch PodDisruptionBudget")
+		r.status.SetDegraded("Failed to get the Elasticsearch PodDisruptionBudget", err.Error())
+		return reconcile.Result{}, false, finalizerCleanup, err
+	}
+
 	var components []render.Component
 
 	logStorageCfg := &render.ElasticsearchConfiguration{
-		LogStorage:                  ls,
-		Installation:                install,
-		ManagementCluster:           managementCluster,
-		ManagementClusterConnection: managementClusterConnection,
-		Elasticsearch:               elasticsearch,
-		Kibana:                      kibana,
-		ClusterConfig:               clusterConfig,
-		ElasticsearchUserSecret:     esAdminUserSecret,
-		ElasticsearchKeyPair:        elasticKeyPair,
-		KibanaKeyPair:               kibanaKeyPair,
-		PullSecrets:                 pullSecrets,
-		Provider:                    r.provider,
-		CuratorSecrets:              curatorSecrets,
-		ESService:                   esService,
-		KbService:                   kbService,
-		ClusterDomain:               r.clusterDomain,
-		BaseURL:                     baseURL,
-		ElasticLicenseType:          esLicenseType,
-		TrustedBundle:               trustedBundle,
-		UnusedTLSSecret:             unusedTLSSecret,
-		UsePSP:                      r.usePSP,
-		ApplyTrial:                  applyTrial,
-		KeyStoreSecret:              keyStoreSecret,
-	}
-
-	component := render.LogStorage(logStorageCfg)
-
-	if err = imageset.ApplyImageSet(ctx, r.client, variant, component); err != nil {
+		LogStorage:                    ls,
+		Installation:                  install,
+		ManagementCluster:             managementCluster,
+		ManagementClusterConnection:   managementClusterConnection,
+		Elasticsearch:                 elasticsearch,
+		Kibana:                        kibana,
+		ClusterConfig:                 clusterConfig,
+		ElasticsearchUserSecret:       esAdminUserSecret,
+		ElasticsearchKeyPair:          elasticKeyPair,
+		KibanaKeyPair:                 kibanaKeyPair,
+		PullSecrets:                   pullSecrets,
+		Provider:                      r.provider,
+		CuratorSecrets:                curatorSecrets,
+		ESService:                     esService,
+		KbService:                     kbService,
+		ClusterDomain:                 r.clusterDomain,
+		BaseURL:                       baseURL,
+		ElasticLicenseType:            esLicenseType,
+		TrustedBundle:                 trustedBundle,
+		UnusedTLSSecret:               unusedTLSSecret,
+		UsePSP:                        r.usePSP,
+		UseBatchV1CronJobs:            r.useBatchV1CronJobs,
+		ExpandableStorageClasses:      expandableStorageClasses,
+		ApplyTrial:                    applyTrial,
+		KeyStoreSecret:                keyStoreSecret,
+		EnterpriseLicenseSecret:       enterpriseLicenseSecret,
+		RemoteClusterCASecrets:        remoteClusterCASecrets,
+		SnapshotRepositorySecret:      snapshotRepositorySecret,
+		SecureSettingsSecrets:         secureSettingsSecrets,
+		KibanaElasticsearchUserSecret: kibanaUserSecret,
+		Authentication:                authentication,
+		DexSecret:                     dexSecret,
+		ContainerOverrides:            containerOverrides,
+		CuratorSuspended:              curatorSuspended,
+		DeferRollouts:                 deferRollouts,
+		ECKWebhookKeyPair:             eckWebhookKeyPair,
+		Zones:                         zones,
+
+		RemoveElasticsearchPodDisruptionBudget: esPodDisruptionBudgetExists,
+	}
+
+	subComponents := render.LogStorageSubComponents(logStorageCfg)
+	for _, subComponent := range subComponents {
+		components = append(components, subComponent)
+	}
+
+	if err = imageset.ApplyImageSet(ctx, r.client, variant, components...); err != nil {
 		reqLogger.Error(err, "Error with images from ImageSet")
 		r.status.SetDegraded("Error with images from ImageSet", err.Error())
 		return reconcile.Result{}, false, finalizerCleanup, err
 	}
 
-	components = append(components, component,
+	certificateComponents := []render.Component{
 		rcertificatemanagement.CertificateManagement(&rcertificatemanagement.Config{
 			Namespace:       render.ElasticsearchNamespace,
 			ServiceAccounts: []string{render.ElasticsearchName},
@@ -214,24 +376,101 @@ func (r *ReconcileLogStorage) createLogStorage(
 			},
 			TrustedBundle: trustedBundle,
 		}),
-	)
-	if !operatorv1.IsFIPSModeEnabled(install.FIPSMode) {
-		components = append(components, component,
-			rcertificatemanagement.CertificateManagement(&rcertificatemanagement.Config{
-				Namespace:       render.KibanaNamespace,
-				ServiceAccounts: []string{render.KibanaName},
-				KeyPairOptions: []rcertificatemanagement.KeyPairOption{
-					// We do not want to delete the secret from the tigera-elasticsearch when CertificateManagement is
-					// enabled. Instead, it will be replaced with a TLS secret that serves merely to pass ECK's validation
-					// checks.
-					rcertificatemanagement.NewKeyPairOption(kibanaKeyPair, true, kibanaKeyPair != nil && !kibanaKeyPair.UseCertificateManagement()),
-				},
-				TrustedBundle: trustedBundle,
-			}),
-		)
-	}
-
-	for _, component := range components {
+		rcertificatemanagement.CertificateManagement(&rcertificatemanagement.Config{
+			Namespace:       render.KibanaNamespace,
+			ServiceAccounts: []string{render.KibanaName},
+			KeyPairOptions: []rcertificatemanagement.KeyPairOption{
+				// We do not want to delete the secret from the tigera-elasticsearch when CertificateManagement is
+				// enabled. Instead, it will be replaced with a TLS secret that serves merely to pass ECK's validation
+				// checks.
+				rcertificatemanagement.NewKeyPairOption(kibanaKeyPair, true, kibanaKeyPair != nil && !kibanaKeyPair.UseCertificateManagement()),
+			},
+			TrustedBundle: trustedBundle,
+		}),
+	}
+	if eckWebhookKeyPair != nil {
+		certificateComponents = append(certificateComponents, rcertificatemanagement.CertificateManagement(&rcertificatemanagement.Config{
+			Namespace:       render.ECKOperatorNamespace,
+			ServiceAccounts: []string{render.ECKOperatorName},
+			KeyPairOptions: []rcertificatemanagement.KeyPairOption{
+				rcertificatemanagement.NewKeyPairOption(eckWebhookKeyPair, true, true),
+			},
+		}))
+	}
+
+	var upgradeResult reconcile.Result
+	var upgradeBlocked bool
+	var upgradeBlockedMsg string
+	if managementClusterConnection == nil {
+		upgradeResult, upgradeBlocked, upgradeBlockedMsg, err = r.checkUpgradePreflight(ls, elasticsearch, install, variant, pullSecrets, trustedBundle, hdler, reqLogger, ctx)
+		if err != nil {
+			return upgradeResult, false, finalizerCleanup, err
+		}
+	}
+	// An upgrade of the ECK operator is held back with the upgrade of Elasticsearch until the pre-flight checks pass,
+	// and then rolled out before Elasticsearch and Kibana are updated.
+	eckUpgradeBlocked := upgradeBlocked && eckUpgradePending(ls, elasticsearch)
+	var eckUpgradeResult reconcile.Result
+	var eckUpgradeMsg string
+	if managementClusterConnection == nil && !upgradeBlocked {
+		eckUpgradeResult, eckUpgradeMsg, err = r.checkECKOperatorUpgrade(ls, elasticsearch, kibana, subComponents, reqLogger, ctx)
+		if err != nil {
+			return eckUpgradeResult, false, finalizerCleanup, err
+		}
+	}
+
+	// Changes that delete the data of Elasticsearch are held back until they are acknowledged for the generation of the
+	// LogStorage that has them.
+	var dataLossMsg string
+	desiredElasticsearch, elasticsearchDeleted := renderedElasticsearch(subComponents)
+	if changes := destructiveChanges(ls, elasticsearch, desiredElasticsearch, elasticsearchDeleted); len(changes) > 0 && !dataLossAcknowledged(ls) {
+		dataLossMsg = fmt.Sprintf("%s; set spec.dataLossAcknowledged to the generation of the LogStorage to apply the change", strings.Join(changes, "; "))
+	}
+
+	// Apply every log storage sub-component, even if applying one of them fails, so that a failure in one of them
+	// (e.g. Kibana) doesn't prevent the others from being updated or hide their status. The sub-components are created
+	// root-first and deleted leaf-first, so that e.g. Kibana isn't created before Elasticsearch, and Elasticsearch
+	// isn't deleted before Kibana and the curator.
+	var dependentComponents []utils.DependentComponent
+	for _, subComponent := range subComponents {
+		// Hold back the update of Elasticsearch, and of Kibana which can't run ahead of it, until the ECK operator is
+		// upgraded and the loss of data is acknowledged.
+		if (eckUpgradeMsg != "" || dataLossMsg != "") && (subComponent.Name == render.LogStorageSubComponentElasticsearch || subComponent.Name == render.LogStorageSubComponentKibana) {
+			continue
+		}
+		if eckUpgradeBlocked && subComponent.Name == render.LogStorageSubComponentECKOperator {
+			continue
+		}
+		var component render.Component = subComponent
+		if upgradeBlocked {
+			// Only hold back the objects that the pre-flight checks cover until they pass.
+			component = upgradeHeldBackComponent{Component: subComponent, kibana: kibana}
+		}
+		dependentComponent := utils.DependentComponent{Name: string(subComponent.Name), Component: component}
+		for _, dep := range subComponent.DependsOn {
+			dependentComponent.DependsOn = append(dependentComponent.DependsOn, string(dep))
+		}
+		dependentComponents = append(dependentComponents, dependentComponent)
+	}
+	applyErrs, err := utils.CreateOrUpdateOrDeleteInOrder(ctx, hdler, dependentComponents, r.status)
+	if err != nil {
+		reqLogger.Error(err, "Failed to order the log storage components")
+		r.status.SetDegraded("Failed to order the log storage components", err.Error())
+		return reconcile.Result{}, false, finalizerCleanup, err
+	}
+
+	var applyErr error
+	var failedSubComponents, failures []string
+	for _, subComponent := range dependentComponents {
+		if err := applyErrs[subComponent.Name]; err != nil {
+			reqLogger.Error(err, "Error creating / updating resource", "subComponent", subComponent.Name)
+			failedSubComponents = append(failedSubComponents, subComponent.Name)
+			failures = append(failures, fmt.Sprintf("%s: %s", subComponent.Name, err))
+			applyErr = err
+		}
+	}
+
+	for _, component := range certificateComponents {
 		if err := hdler.CreateOrUpdateOrDelete(ctx, component, r.status); err != nil {
 			reqLogger.Error(err, err.Error())
 			r.status.SetDegraded("Error creating / updating resource", err.Error())
@@ -239,29 +478,239 @@ func (r *ReconcileLogStorage) createLogStorage(
 		}
 	}
 
-	if ls != nil && ls.DeletionTimestamp != nil && elasticsearch == nil && kibana == nil {
-		finalizerCleanup = true
+	if managementClusterConnection == nil && applyErr == nil {
+		if err := r.deleteStaleSecureSettingsCopies(ctx, secureSettingsSecrets); err != nil {
+			reqLogger.Error(err, "Failed to delete the stale copies of the secure settings secrets")
+			r.status.SetDegraded("Failed to delete the stale copies of the secure settings secrets", err.Error())
+			return reconcile.Result{}, false, finalizerCleanup, err
+		}
 	}
 
+	var notOperational []string
 	if managementClusterConnection == nil {
 		if elasticsearch == nil || elasticsearch.Status.Phase != esv1.ElasticsearchReadyPhase {
-			r.status.SetDegraded("Waiting for Elasticsearch cluster to be operational", "")
-			return reconcile.Result{}, false, finalizerCleanup, nil
+			notOperational = append(notOperational, "Elasticsearch")
+		}
+		if !kibanaOperational(ls, kibana) {
+			notOperational = append(notOperational, "Kibana")
 		}
+	}
 
-		if !operatorv1.IsFIPSModeEnabled(install.FIPSMode) && (kibana == nil || kibana.Status.AssociationStatus != cmnv1.AssociationEstablished) {
-			r.status.SetDegraded("Waiting for Kibana cluster to be operational", "")
+	// Report the state of each sub-component, so that e.g. a Kibana failure doesn't mask the state of Elasticsearch.
+	for _, subComponent := range subComponents {
+		var msgs []string
+		if err := applyErrs[string(subComponent.Name)]; err != nil {
+			msgs = append(msgs, fmt.Sprintf("Error creating / updating resource: %s", err))
+		}
+		for _, name := range notOperational {
+			if name == string(subComponent.Name) {
+				msgs = append(msgs, fmt.Sprintf("Waiting for %s cluster to be operational", name))
+			}
+		}
+		setSubComponentDegraded(r.status, string(subComponent.Name), strings.Join(msgs, "; "))
+	}
+
+	if len(failedSubComponents) > 0 {
+		r.status.SetDegraded("Error creating / updating resource", strings.Join(failures, "; "))
+		return reconcile.Result{}, false, finalizerCleanup, applyErr
+	}
+
+	if ls != nil && ls.DeletionTimestamp != nil && elasticsearch == nil && kibana == nil {
+		if err := r.deleteDefaultStorageClass(ctx); err != nil {
+			reqLogger.Error(err, "Failed to delete the default storage class")
+			r.status.SetDegraded("Failed to delete the default storage class", err.Error())
+			return reconcile.Result{}, false, finalizerCleanup, err
+		}
+		finalizerCleanup = true
+	}
+
+	if dataLossMsg != "" {
+		r.status.SetDegraded("The LogStorage change deletes the data of Elasticsearch", dataLossMsg)
+		return reconcile.Result{}, false, finalizerCleanup, nil
+	}
+
+	if upgradeBlocked {
+		r.status.SetDegraded(upgradeBlockedMsg, "")
+		return upgradeResult, false, finalizerCleanup, nil
+	}
+
+	if eckUpgradeMsg != "" {
+		r.status.SetDegraded(eckUpgradeMsg, "")
+		return eckUpgradeResult, false, finalizerCleanup, nil
+	}
+
+	// Resize the Elasticsearch pods in place when only their resources changed, before ECK restarts them for it.
+	if managementClusterConnection == nil && r.inPlaceResizeEnabled() {
+		resized, err := r.resizeElasticsearchPods(ctx, elasticsearch)
+		if err != nil {
+			reqLogger.Error(err, "Failed to resize the Elasticsearch pods")
+			r.status.SetDegraded("Failed to resize the Elasticsearch pods", err.Error())
+			return reconcile.Result{}, false, finalizerCleanup, err
+		}
+		if !resized {
+			return reconcile.Result{RequeueAfter: 5 * time.Second}, false, finalizerCleanup, nil
+		}
+	}
+
+	// Elasticsearch is reported first, since Kibana can't become operational without it.
+	if len(notOperational) > 0 && notOperational[0] == "Elasticsearch" {
+		// Report why the storage of Elasticsearch can't be provisioned, if that is what it is waiting for, since it
+		// won't become operational until the storage is fixed.
+		storageFailure, err := r.esStorageFailure(ctx)
+		if err != nil {
+			reqLogger.Error(err, "Failed to check the provisioning of the Elasticsearch storage")
+		} else if storageFailure != "" {
+			r.status.SetDegraded("Failed to provision the Elasticsearch storage", storageFailure)
 			return reconcile.Result{}, false, finalizerCleanup, nil
 		}
 	}
+	// Kibana can't connect to the Elasticsearch hosts of the LogStorage until its user exists, so the user is created
+	// as soon as Elasticsearch is operational.
+	if kibanaUserSecret != nil && (len(notOperational) == 0 || notOperational[0] != "Elasticsearch") {
+		result, proceed, err := r.applyKibanaElasticsearchUser(kibanaUserSecret, reqLogger, ctx)
+		if err != nil || !proceed {
+			return result, false, finalizerCleanup, err
+		}
+	} else if kibanaUserSecret == nil && managementClusterConnection == nil && len(notOperational) == 0 {
+		result, proceed, err := r.deleteKibanaElasticsearchUser(reqLogger, ctx)
+		if err != nil || !proceed {
+			return result, false, finalizerCleanup, err
+		}
+	}
+	// The index templates only need Elasticsearch, and are applied before fluentd is deployed. The result requeues
+	// the request while their job runs.
+	var indexTemplatesResult reconcile.Result
+	if managementClusterConnection == nil && (len(notOperational) == 0 || notOperational[0] != "Elasticsearch") {
+		result, proceed, err := r.applyIndexTemplates(ls, install, variant, pullSecrets, trustedBundle, hdler, reqLogger, ctx)
+		if err != nil || !proceed {
+			return result, false, finalizerCleanup, err
+		}
+		indexTemplatesResult = result
+	}
+	if len(notOperational) > 0 {
+		r.status.SetDegraded(fmt.Sprintf("Waiting for %s cluster to be operational", notOperational[0]), "")
+		return reconcile.Result{}, false, finalizerCleanup, nil
+	}
+
+	if managementClusterConnection == nil {
+		result, proceed, err := r.createKibanaSpaces(ls, install, variant, pullSecrets, trustedBundle, hdler, reqLogger, ctx)
+		if err != nil || !proceed {
+			return result, false, finalizerCleanup, err
+		}
+		result, proceed, err = r.importKibanaSavedObjects(ls, install, variant, pullSecrets, trustedBundle, hdler, reqLogger, ctx)
+		if err != nil || !proceed {
+			return result, false, finalizerCleanup, err
+		}
+		result, proceed, err = r.verifyLogStorage(ls, install, variant, pullSecrets, trustedBundle, elasticsearch, kibana, hdler, reqLogger, ctx)
+		if err != nil || !proceed {
+			return result, false, finalizerCleanup, err
+		}
+		// The result requeues the request for the expiration of the next restored index.
+		result, proceed, err = r.restoreArchives(ls, install, variant, pullSecrets, trustedBundle, logCollector, hdler, reqLogger, ctx)
+		result.RequeueAfter = minRequeueAfter(result.RequeueAfter, indexTemplatesResult.RequeueAfter)
+		if deferRollouts {
+			// Roll out the deferred changes once the rollout window opens.
+			result.RequeueAfter = minRequeueAfter(result.RequeueAfter, untilRolloutWindow)
+		}
+		return result, proceed, finalizerCleanup, err
+	}
 
 	return reconcile.Result{}, true, finalizerCleanup, nil
 }
 
-func (r *ReconcileLogStorage) validateLogStorage(curatorSecrets []*corev1.Secret, esLicenseType render.ElasticsearchLicenseType, reqLogger logr.Logger, ctx context.Context) (reconcile.Result, bool, error) {
+// defaultStorageClassLabel labels the storage class that the operator creates for Elasticsearch on the infrastructure
+// provider, so that only that storage class is deleted with the LogStorage.
+const defaultStorageClassLabel = "operator.tigera.io/logstorage-default-storage-class"
+
+// infrastructureStorageProvisioners are the CSI provisioners of the disks of the Cluster API infrastructure providers.
+var infrastructureStorageProvisioners = map[common.InfrastructureProvider]string{
+	common.InfrastructureProviderAWS:     "ebs.csi.aws.com",
+	common.InfrastructureProviderAzure:   "disk.csi.azure.com",
+	common.InfrastructureProviderVSphere: "csi.vsphere.vmware.com",
+}
+
+// defaultStorageClass returns the storage class that is created when the default storage class of Elasticsearch doesn't
+// exist on a cluster that was provisioned by Cluster API, or nil if there is none. Its volumes are retained when they
+// are released, so that the logs aren't lost if the LogStorage is deleted by accident, and are bound once the pods of
+// Elasticsearch are scheduled, so that they are provisioned in the zones of the pods.
+func defaultStorageClass(provider common.InfrastructureProvider, name string) *storagev1.StorageClass {
+	provisioner, ok := infrastructureStorageProvisioners[provider]
+	if !ok || name != DefaultElasticsearchStorageClass {
+		return nil
+	}
+	reclaimPolicy := corev1.PersistentVolumeReclaimRetain
+	bindingMode := storagev1.VolumeBindingWaitForFirstConsumer
+	return &storagev1.StorageClass{
+		ObjectMeta:           metav1.ObjectMeta{Name: name, Labels: map[string]string{defaultStorageClassLabel: "true"}},
+		Provisioner:          provisioner,
+		ReclaimPolicy:        &reclaimPolicy,
+		VolumeBindingMode:    &bindingMode,
+		AllowVolumeExpansion: ptr.BoolToPtr(true),
+	}
+}
+
+// CreatesDefaultStorageClass returns whether the operator creates the storage class of the given name for Elasticsearch
+// when it doesn't exist on a cluster of the infrastructure provider.
+func CreatesDefaultStorageClass(provider common.InfrastructureProvider, name string) bool {
+	return defaultStorageClass(provider, name) != nil
+}
+
+// IsDefaultStorageClass returns whether the operator created the storage class for Elasticsearch on the infrastructure
+// provider.
+func IsDefaultStorageClass(sc *storagev1.StorageClass) bool {
+	return sc.Labels[defaultStorageClassLabel] == "true"
+}
+
+// podDisruptionBudgetExists returns whether the PodDisruptionBudget of the given name exists, so that a budget that is
+// no longer configured is only deleted when there is one to delete.
+func (r *ReconcileLogStorage) podDisruptionBudgetExists(ctx context.Context, name, namespace string) (bool, error) {
+	if err := r.client.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, &policyv1.PodDisruptionBudget{}); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// horizontalPodAutoscalerExists returns whether the autoscaling/v2 HorizontalPodAutoscaler of the given name exists.
+// It doesn't exist on the clusters that don't serve the autoscaling/v2 API.
+func (r *ReconcileLogStorage) horizontalPodAutoscalerExists(ctx context.Context, name, namespace string) (bool, error) {
+	if err := r.client.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, &autoscalingv2.HorizontalPodAutoscaler{}); err != nil {
+		if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// deleteDefaultStorageClass deletes the storage class that the operator created for Elasticsearch on the infrastructure
+// provider. The volumes that it provisioned are retained. A storage class of the same name that the operator didn't
+// create is left as it is.
+func (r *ReconcileLogStorage) deleteDefaultStorageClass(ctx context.Context) error {
+	sc := &storagev1.StorageClass{}
+	if err := r.client.Get(ctx, client.ObjectKey{Name: DefaultElasticsearchStorageClass}, sc); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if !IsDefaultStorageClass(sc) {
+		return nil
+	}
+	if err := r.client.Delete(ctx, sc); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func (r *ReconcileLogStorage) validateLogStorage(ls *operatorv1.LogStorage, curatorSecrets []*corev1.Secret, esLicenseType render.ElasticsearchLicenseType, reqLogger logr.Logger, ctx context.Context) (reconcile.Result, bool, error) {
 	var err error
 
-	if len(curatorSecrets) == 0 {
+	// The curator secrets are only needed by the legacy curator, the indices are otherwise deleted by the ILM policies.
+	curatorEnabled := ls.Spec.Curator != nil && *ls.Spec.Curator == operatorv1.CuratorEnabled
+	if curatorEnabled && len(curatorSecrets) == 0 {
 		reqLogger.Info("waiting for curator secrets to become available")
 		r.status.SetDegraded("Waiting for curator secrets to become available", "")
 		return reconcile.Result{}, false, nil
@@ -296,6 +745,46 @@ func (r *ReconcileLogStorage) applyILMPolicies(ls *operatorv1.LogStorage, reqLog
 	return reconcile.Result{}, true, nil
 }
 
+// applyDiskWatermarks applies the disk allocation watermarks of the LogStorage to the Elasticsearch cluster.
+func (r *ReconcileLogStorage) applyDiskWatermarks(ls *operatorv1.LogStorage, reqLogger logr.Logger, ctx context.Context) (reconcile.Result, bool, error) {
+	esClient, err := r.esCliCreator(r.client, ctx, relasticsearch.HTTPSEndpoint(rmeta.OSTypeLinux, r.clusterDomain))
+	if err != nil {
+		reqLogger.Error(err, "failed to create the Elasticsearch client")
+		r.status.SetDegraded("Failed to connect to Elasticsearch", err.Error())
+		return reconcile.Result{}, false, err
+	}
+
+	if err = esClient.SetDiskWatermarks(ctx, ls); err != nil {
+		reqLogger.Error(err, "failed to apply the Elasticsearch disk watermarks")
+		r.status.SetDegraded("Failed to apply the Elasticsearch disk watermarks", err.Error())
+		return reconcile.Result{}, false, err
+	}
+	return reconcile.Result{}, true, nil
+}
+
+// applyTenantRoles creates the Elasticsearch roles of the tenant roles in LogStorage. The document-level and
+// field-level security of the roles requires an Elasticsearch license that supports them.
+func (r *ReconcileLogStorage) applyTenantRoles(ls *operatorv1.LogStorage, esLicenseType render.ElasticsearchLicenseType, reqLogger logr.Logger, ctx context.Context) (reconcile.Result, bool, error) {
+	if len(ls.Spec.TenantRoles) != 0 && esLicenseType == render.ElasticsearchLicenseTypeBasic {
+		r.status.SetDegraded("Tenant roles require document and field level security, which the basic Elasticsearch license does not support", "")
+		return reconcile.Result{}, false, nil
+	}
+
+	esClient, err := r.esCliCreator(r.client, ctx, relasticsearch.HTTPSEndpoint(rmeta.OSTypeLinux, r.clusterDomain))
+	if err != nil {
+		reqLogger.Error(err, "failed to create the Elasticsearch client")
+		r.status.SetDegraded("Failed to connect to Elasticsearch", err.Error())
+		return reconcile.Result{}, false, err
+	}
+
+	if err = esClient.SetTenantRoles(ctx, ls); err != nil {
+		reqLogger.Error(err, "failed to create or update Elasticsearch tenant roles")
+		r.status.SetDegraded("Failed to create or update Elasticsearch tenant roles", err.Error())
+		return reconcile.Result{}, false, err
+	}
+	return reconcile.Result{}, true, nil
+}
+
 func addLogStorageWatches(c controller.Controller) error {
 	// Watch for changes in storage classes, as new storage classes may be made available for LogStorage.
 	err := c.Watch(&source.Kind{
@@ -351,5 +840,81 @@ func addLogStorageWatches(c controller.Controller) error {
 		}
 	}
 
+	// Watch the PVCs of Elasticsearch and the events of their provisioning to report the failures to provision them.
+	if err = utils.AddNamespacedWatch(c, &corev1.PersistentVolumeClaim{
+		TypeMeta:   metav1.TypeMeta{Kind: "PersistentVolumeClaim", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: render.ElasticsearchNamespace},
+	}); err != nil {
+		return fmt.Errorf("log-storage-controller failed to watch the PersistentVolumeClaim resource: %w", err)
+	}
+	// The cache of the events only holds the warning events of the Elasticsearch namespace, see main.go.
+	if err = c.Watch(&source.Kind{Type: &corev1.Event{}}, &handler.EnqueueRequestForObject{}, predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		e, ok := obj.(*corev1.Event)
+		return ok && e.Namespace == render.ElasticsearchNamespace && isEsStorageFailureEvent(e)
+	})); err != nil {
+		return fmt.Errorf("log-storage-controller failed to watch the Event resource: %w", err)
+	}
+
+	// Watch the job of the Kibana spaces to report its failures.
+	if err = utils.AddNamespacedWatch(c, &batchv1.Job{
+		TypeMeta:   metav1.TypeMeta{Kind: "Job", APIVersion: "batch/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: render.KibanaSpacesName, Namespace: render.ElasticsearchNamespace},
+	}); err != nil {
+		return fmt.Errorf("log-storage-controller failed to watch the Job resource: %w", err)
+	}
+	// Watch the job of the Kibana saved objects to report its failures, and the ConfigMaps in the operator namespace
+	// to import the saved objects again when they change.
+	if err = utils.AddNamespacedWatch(c, &batchv1.Job{
+		TypeMeta:   metav1.TypeMeta{Kind: "Job", APIVersion: "batch/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: render.KibanaSavedObjectsName, Namespace: render.ElasticsearchNamespace},
+	}); err != nil {
+		return fmt.Errorf("log-storage-controller failed to watch the Job resource: %w", err)
+	}
+	if err = utils.AddConfigMapWatch(c, "", common.OperatorNamespace()); err != nil {
+		return fmt.Errorf("log-storage-controller failed to watch the ConfigMap resource: %w", err)
+	}
+	// Watch the LogStorage verification job to report its result.
+	if err = utils.AddNamespacedWatch(c, &batchv1.Job{
+		TypeMeta:   metav1.TypeMeta{Kind: "Job", APIVersion: "batch/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: render.LogStorageVerificationName, Namespace: render.ElasticsearchNamespace},
+	}); err != nil {
+		return fmt.Errorf("log-storage-controller failed to watch the Job resource: %w", err)
+	}
+	// Watch the archive restore jobs to report their progress.
+	if err = c.Watch(&source.Kind{Type: &batchv1.Job{}}, &handler.EnqueueRequestForObject{}, predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj.GetNamespace() == render.ElasticsearchNamespace && obj.GetLabels()["k8s-app"] == render.ArchiveRestoreName
+	})); err != nil {
+		return fmt.Errorf("log-storage-controller failed to watch the Job resource: %w", err)
+	}
+	// Watch the S3 credentials of the LogCollector that the archive restore jobs use.
+	if err = utils.AddSecretsWatch(c, render.S3FluentdSecretName, common.OperatorNamespace()); err != nil {
+		return fmt.Errorf("log-storage-controller failed to watch the Secret resource: %w", err)
+	}
+	// Watch the secrets that hold an Elastic license, to keep the copy of the enterprise license in sync, and the copy
+	// in the namespace of the ECK operator.
+	if err = c.Watch(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestForObject{}, predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		s, ok := obj.(*corev1.Secret)
+		return ok && s.Namespace == common.OperatorNamespace() && s.Data[render.ECKLicenseKey] != nil
+	})); err != nil {
+		return fmt.Errorf("log-storage-controller failed to watch the Secret resource: %w", err)
+	}
+	if err = utils.AddSecretsWatch(c, render.ECKEnterpriseLicense, render.ECKOperatorNamespace); err != nil {
+		return fmt.Errorf("log-storage-controller failed to watch the Secret resource: %w", err)
+	}
+
 	return nil
 }
+
+// setSubComponentDegraded reports the degradation of a log storage sub-component to the status manager, or clears it if
+// the message is empty, when the status manager reports the sub-components separately.
+func setSubComponentDegraded(sm status.StatusManager, name, msg string) {
+	tracker, ok := sm.(status.SubComponentTracker)
+	if !ok {
+		return
+	}
+	if msg == "" {
+		tracker.ClearSubComponentDegraded(name)
+	} else {
+		tracker.SetSubComponentDegraded(name, msg)
+	}
+}
@@ -17,6 +17,7 @@ package logstorage
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/tigera/operator/pkg/render/common/networkpolicy"
@@ -62,8 +63,8 @@ import (
 var log = logf.Log.WithName("controller_logstorage")
 
 const (
-	defaultEckOperatorMemorySetting  = "512Mi"
-	DefaultElasticsearchStorageClass = "tigera-elasticsearch"
+	defaultEckOperatorMemorySetting  = operatorv1.DefaultECKOperatorMemory
+	DefaultElasticsearchStorageClass = operatorv1.DefaultElasticsearchStorageClass
 	LogStorageFinalizer              = "tigera.io/eck-cleanup"
 )
 
@@ -82,7 +83,7 @@ func Add(mgr manager.Manager, opts options.AddOptions) error {
 	}
 
 	// Create the controller
-	c, err := controller.New("log-storage-controller", mgr, controller.Options{Reconciler: r})
+	c, err := controller.New("log-storage-controller", mgr, controller.Options{Reconciler: r, MaxConcurrentReconciles: opts.MaxConcurrentReconciles})
 	if err != nil {
 		return err
 	}
@@ -91,6 +92,7 @@ func Add(mgr manager.Manager, opts options.AddOptions) error {
 	if err != nil {
 		return fmt.Errorf("log-storage-controller failed to establish a connection to k8s: %w", err)
 	}
+	r.clientset = k8sClient
 
 	go utils.WaitToAddTierWatch(networkpolicy.TigeraComponentTierName, c, k8sClient, log, tierWatchReady)
 	go utils.WaitToAddNetworkPolicyWatches(c, k8sClient, log, []types.NamespacedName{
@@ -106,7 +108,10 @@ func Add(mgr manager.Manager, opts options.AddOptions) error {
 		{Name: kubecontrollers.EsKubeControllerNetworkPolicyName, Namespace: common.CalicoNamespace},
 	})
 
-	return add(mgr, c)
+	if err := add(mgr, c); err != nil {
+		return err
+	}
+	return utils.AddReadyzCheck(mgr, "log-storage-controller", map[string]*utils.ReadyFlag{"tier-watch": tierWatchReady})
 }
 
 // newReconciler returns a new reconcile.Reconciler
@@ -127,6 +132,10 @@ func newReconciler(
 		clusterDomain:  opts.ClusterDomain,
 		tierWatchReady: tierWatchReady,
 		usePSP:         opts.UsePSP,
+
+		useBatchV1CronJobs:     opts.UseBatchV1CronJobs,
+		infrastructureProvider: opts.InfrastructureProvider,
+		kubernetesVersion:      opts.KubernetesVersion,
 	}
 
 	c.status.Run(opts.ShutdownContext)
@@ -173,6 +182,13 @@ func add(mgr manager.Manager, c controller.Controller) error {
 		return err
 	}
 
+	// The secrets that the LogStorage names, such as the secret of the snapshot repository, can't be watched by name,
+	// so the secrets of the operator namespace are filtered by the references of the LogStorage.
+	err = c.Watch(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestForObject{}, logStorageSecretPredicate(mgr.GetClient()))
+	if err != nil {
+		return fmt.Errorf("log-storage-controller failed to watch the secrets of the LogStorage: %w", err)
+	}
+
 	// Watch all the secrets created by this controller so we can regenerate any that are deleted
 	for _, secretName := range []string{
 		render.TigeraElasticsearchGatewaySecret, render.TigeraKibanaCertSecret,
@@ -183,6 +199,11 @@ func add(mgr manager.Manager, c controller.Controller) error {
 		}
 	}
 
+	// The gateway swaps the service account tokens of fluentd for the credentials of its Elasticsearch user.
+	if err = utils.AddSecretsWatch(c, render.ElasticsearchLogCollectorUserSecret, common.OperatorNamespace()); err != nil {
+		return fmt.Errorf("log-storage-controller failed to watch the Secret resource: %w", err)
+	}
+
 	// Catch if something modifies the certs that this controller creates.
 	if err = utils.AddSecretsWatch(c, relasticsearch.PublicCertSecret, common.OperatorNamespace()); err != nil {
 		return fmt.Errorf("log-storage-controller failed to watch the Secret resource: %w", err)
@@ -200,6 +221,11 @@ func add(mgr manager.Manager, c controller.Controller) error {
 		return fmt.Errorf("log-storage-controller failed to watch the Secret resource: %w", err)
 	}
 
+	// Watch the admin user secret generated by ECK, so that we notice when it is regenerated after a rotation.
+	if err = utils.AddSecretsWatch(c, render.ElasticsearchAdminUserSecret, render.ElasticsearchNamespace); err != nil {
+		return fmt.Errorf("log-storage-controller failed to watch the Secret resource in the %s namespace: %w", render.ElasticsearchNamespace, err)
+	}
+
 	if err = utils.AddConfigMapWatch(c, relasticsearch.ClusterConfigConfigMapName, common.OperatorNamespace()); err != nil {
 		return fmt.Errorf("log-storage-controller failed to watch the ConfigMap resource: %w", err)
 	}
@@ -224,6 +250,13 @@ func add(mgr manager.Manager, c controller.Controller) error {
 		return fmt.Errorf("log-storage-controller failed to watch primary resource: %w", err)
 	}
 
+	// Watch the LogCollector, which configures the S3 bucket that archived logs are restored from and the authentication
+	// of fluentd to es-gateway.
+	err = c.Watch(&source.Kind{Type: &operatorv1.LogCollector{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return fmt.Errorf("log-storage-controller failed to watch LogCollector resource: %w", err)
+	}
+
 	return nil
 }
 
@@ -242,6 +275,29 @@ type ReconcileLogStorage struct {
 	clusterDomain  string
 	tierWatchReady *utils.ReadyFlag
 	usePSP         bool
+
+	// useBatchV1CronJobs is whether the curator CronJob is a batch/v1 CronJob rather than a batch/v1beta1 one.
+	useBatchV1CronJobs bool
+
+	// infrastructureProvider is the Cluster API infrastructure provider of the cluster, which has a default storage
+	// class for Elasticsearch.
+	infrastructureProvider common.InfrastructureProvider
+
+	clientset         kubernetes.Interface
+	kubernetesVersion *common.VersionInfo
+
+	// inPlaceResizeUnsupported is set to 1 once the API server rejected an in-place resize of an Elasticsearch pod,
+	// e.g. because the InPlacePodVerticalScaling feature gate is disabled, after which ECK restarts the pods to resize
+	// them.
+	inPlaceResizeUnsupported int32
+
+	// ingestLatencyPipelineEnabled is whether the ingest latency pipeline was last set up or removed, nil until it is
+	// done once after the operator starts.
+	ingestLatencyPipelineEnabled *bool
+	// ingestLatencyMeasuredAt is when the ingestion latency was last measured, and ingestLatencyP99 the 99th percentile
+	// that was measured.
+	ingestLatencyMeasuredAt time.Time
+	ingestLatencyP99        time.Duration
 }
 
 // fillDefaults populates the default values onto an LogStorage object.
@@ -284,6 +340,10 @@ func fillDefaults(opr *operatorv1.LogStorage) {
 		opr.Spec.Indices.Replicas = &replicas
 	}
 
+	if opr.Spec.ClusterName == "" {
+		opr.Spec.ClusterName = render.DefaultElasticsearchClusterName
+	}
+
 	if opr.Spec.StorageClassName == "" {
 		opr.Spec.StorageClassName = DefaultElasticsearchStorageClass
 	}
@@ -325,6 +385,118 @@ func validateComponentResources(spec *operatorv1.LogStorageSpec) error {
 	return nil
 }
 
+// validateDisruptionPolicies returns an error if a disruption policy of the LogStorage has a preStop delay for a
+// component whose image has no shell to sleep in, or a PodDisruptionBudget for a component whose pods aren't kept
+// available, such as the curator and the jobs that run to completion.
+func validateDisruptionPolicies(spec *operatorv1.LogStorageSpec) error {
+	for _, policy := range spec.ComponentDisruptionPolicies {
+		switch policy.ComponentName {
+		case operatorv1.ComponentNameECKOperator, operatorv1.ComponentNameESGateway, operatorv1.ComponentNameESMetrics, operatorv1.ComponentNameESKubeControllers:
+			if policy.PreStopDelaySeconds != nil {
+				return fmt.Errorf("the disruption policy of %s can't have a preStop delay", policy.ComponentName)
+			}
+		}
+		switch policy.ComponentName {
+		case operatorv1.ComponentNameEsCurator, operatorv1.ComponentNameESMetrics, operatorv1.ComponentNameESKubeControllers, operatorv1.ComponentNameLogStorageJobs:
+			if policy.PodDisruptionBudget != nil {
+				return fmt.Errorf("the disruption policy of %s can't have a PodDisruptionBudget", policy.ComponentName)
+			}
+		}
+	}
+	return nil
+}
+
+// validateDiskWatermarks returns an error if a disk watermark that is set in the LogStorage isn't above the disk usage
+// at which the curator removes the oldest indices, in which case Elasticsearch would stop allocating shards before the
+// curator frees up disk space, or if it breaks the order of the watermarks from low to high to flood stage. The
+// watermarks that aren't set keep the defaults of Elasticsearch, which are only validated against the set ones.
+func validateDiskWatermarks(spec *operatorv1.LogStorageSpec) error {
+	if spec.Nodes == nil || spec.Nodes.DiskWatermarks == nil {
+		return nil
+	}
+	watermarks := spec.Nodes.DiskWatermarks
+	for _, w := range []struct {
+		field   string
+		percent *int32
+	}{
+		{"low", watermarks.Low},
+		{"high", watermarks.High},
+		{"floodStage", watermarks.FloodStage},
+	} {
+		if w.percent != nil && *w.percent <= render.MaxTotalStoragePercent {
+			return fmt.Errorf("spec.nodes.diskWatermarks.%s %d%% must be above the maximum total storage of the retention, %d%%", w.field, *w.percent, render.MaxTotalStoragePercent)
+		}
+	}
+
+	low, high, floodStage := diskWatermarks(spec)
+	if (watermarks.Low != nil || watermarks.High != nil) && low >= high {
+		return fmt.Errorf("spec.nodes.diskWatermarks: the low watermark (%d%%) must be below the high watermark (%d%%)", low, high)
+	}
+	if (watermarks.High != nil || watermarks.FloodStage != nil) && high >= floodStage {
+		return fmt.Errorf("spec.nodes.diskWatermarks: the high watermark (%d%%) must be below the flood stage watermark (%d%%)", high, floodStage)
+	}
+	return nil
+}
+
+// diskWatermarks returns the low, high and flood stage disk watermarks of the Elasticsearch nodes of the LogStorage, in
+// percent. Unset watermarks keep the defaults of Elasticsearch.
+func diskWatermarks(spec *operatorv1.LogStorageSpec) (int32, int32, int32) {
+	low, high, floodStage := int32(85), int32(90), int32(95)
+	if spec.Nodes == nil || spec.Nodes.DiskWatermarks == nil {
+		return low, high, floodStage
+	}
+	watermarks := spec.Nodes.DiskWatermarks
+	if watermarks.Low != nil {
+		low = *watermarks.Low
+	}
+	if watermarks.High != nil {
+		high = *watermarks.High
+	}
+	if watermarks.FloodStage != nil {
+		floodStage = *watermarks.FloodStage
+	}
+	return low, high, floodStage
+}
+
+// validatePodTemplatePatches returns an error if the pod template patch of a NodeSet of the LogStorage can't be applied.
+func validatePodTemplatePatches(spec *operatorv1.LogStorageSpec) error {
+	if spec.Nodes == nil {
+		return nil
+	}
+	for i, nodeSet := range spec.Nodes.NodeSets {
+		if nodeSet.PodTemplatePatch == nil {
+			continue
+		}
+		if _, err := render.ApplyPodTemplatePatch(corev1.PodTemplateSpec{}, nodeSet.PodTemplatePatch); err != nil {
+			return fmt.Errorf("the pod template patch of NodeSet %d is invalid: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// supportedTransportTLSProtocols are the TLS protocols that the transport layer of Elasticsearch can be restricted to.
+// Protocols older than TLSv1.2 are deprecated and not accepted.
+var supportedTransportTLSProtocols = []string{"TLSv1.3", "TLSv1.2"}
+
+// validateTransportTLS returns an error if the transport TLS of the LogStorage has a protocol that Elasticsearch
+// doesn't support, or a cipher suite that isn't named.
+func validateTransportTLS(spec *operatorv1.LogStorageSpec) error {
+	if spec.TransportTLS == nil {
+		return nil
+	}
+	for _, protocol := range spec.TransportTLS.SupportedProtocols {
+		if !stringsutil.StringInSlice(protocol, supportedTransportTLSProtocols) {
+			return fmt.Errorf("the transport TLS protocol %q is not one of %s", protocol, strings.Join(supportedTransportTLSProtocols, ", "))
+		}
+	}
+	for _, cipher := range spec.TransportTLS.CipherSuites {
+		if strings.TrimSpace(cipher) == "" {
+			return fmt.Errorf("the transport TLS cipher suites must not be empty")
+		}
+	}
+	return nil
+}
+
 func setLogStorageFinalizer(ls *operatorv1.LogStorage) {
 	if ls.DeletionTimestamp == nil {
 		if !stringsutil.StringInSlice(LogStorageFinalizer, ls.GetFinalizers()) {
@@ -442,7 +614,11 @@ func (r *ReconcileLogStorage) Reconcile(ctx context.Context, request reconcile.R
 		return reconcile.Result{}, nil
 	}
 
-	pullSecrets, err := utils.GetNetworkingPullSecrets(install, r.client)
+	var pullSecretRefs []corev1.LocalObjectReference
+	if ls != nil {
+		pullSecretRefs = ls.Spec.ImagePullSecrets
+	}
+	pullSecrets, err := utils.GetComponentPullSecrets(ctx, install, pullSecretRefs, r.client)
 	if err != nil {
 		reqLogger.Error(err, "error retrieving pull secrets")
 		r.status.SetDegraded("An error occurring while retrieving the pull secrets", err.Error())
@@ -464,15 +640,18 @@ func (r *ReconcileLogStorage) Reconcile(ctx context.Context, request reconcile.R
 	}
 
 	var esAdminUserSecret *corev1.Secret
+	var eckAdminUserSecret *corev1.Secret
 	var clusterConfig *relasticsearch.ClusterConfig
 	var curatorSecrets []*corev1.Secret
 	var esLicenseType render.ElasticsearchLicenseType
 	var applyTrial bool
 	var keyStoreSecret *corev1.Secret
+	var enterpriseLicenseSecret *corev1.Secret
+	var enterpriseLicense *elasticLicense
 
 	if managementClusterConnection == nil {
 		flowShards := logstoragecommon.CalculateFlowShards(ls.Spec.Nodes, logstoragecommon.DefaultElasticsearchShards)
-		clusterConfig = relasticsearch.NewClusterConfig(render.DefaultElasticsearchClusterName, ls.Replicas(), logstoragecommon.DefaultElasticsearchShards, flowShards)
+		clusterConfig = relasticsearch.NewClusterConfig(ls.Spec.ClusterName, ls.Replicas(), logstoragecommon.DefaultElasticsearchShards, flowShards)
 
 		// Get the admin user secret to copy to the operator namespace.
 		esAdminUserSecret, err = utils.GetSecret(ctx, r.client, render.ElasticsearchAdminUserSecret, render.ElasticsearchNamespace)
@@ -482,6 +661,12 @@ func (r *ReconcileLogStorage) Reconcile(ctx context.Context, request reconcile.R
 			return reconcile.Result{}, err
 		}
 		if esAdminUserSecret != nil {
+			result, proceed, err := r.rotateEsAdminUser(ls, esAdminUserSecret, reqLogger, ctx)
+			if err != nil || !proceed {
+				return result, err
+			}
+			// Keep the secret generated by ECK around, the copy doesn't carry its creation timestamp.
+			eckAdminUserSecret = esAdminUserSecret
 			esAdminUserSecret = rsecret.CopyToNamespace(common.OperatorNamespace(), esAdminUserSecret)[0]
 		}
 
@@ -490,6 +675,12 @@ func (r *ReconcileLogStorage) Reconcile(ctx context.Context, request reconcile.R
 			r.status.SetDegraded("Failed to get curator credentials", err.Error())
 			return reconcile.Result{}, err
 		}
+		enterpriseLicenseSecret, enterpriseLicense, err = r.getEnterpriseLicenseSecret(ctx, ls)
+		if err != nil {
+			reqLogger.Error(err, err.Error())
+			r.status.SetDegraded("Failed to get the Elastic enterprise license", err.Error())
+			return reconcile.Result{}, err
+		}
 		if operatorv1.IsFIPSModeEnabled(install.FIPSMode) {
 			applyTrial, err = r.applyElasticTrialSecret(ctx, install)
 			if err != nil {
@@ -564,6 +755,7 @@ func (r *ReconcileLogStorage) Reconcile(ctx context.Context, request reconcile.R
 		certificateManager,
 		applyTrial,
 		keyStoreSecret,
+		enterpriseLicenseSecret,
 	)
 
 	if ls != nil && ls.DeletionTimestamp != nil && finalizerCleanup {
@@ -581,14 +773,18 @@ func (r *ReconcileLogStorage) Reconcile(ctx context.Context, request reconcile.R
 		return result, err
 	}
 
+	// The time after which the cluster is checked again, e.g. to measure the ingestion latency of logs.
+	requeueAfter := result.RequeueAfter
 	if managementClusterConnection == nil {
 		result, proceed, err = r.createEsKubeControllers(
+			ls,
 			install,
 			hdler,
 			reqLogger,
 			managementCluster,
 			authentication,
 			esLicenseType,
+			clusterConfig,
 			ctx,
 		)
 		if err != nil || !proceed {
@@ -596,6 +792,7 @@ func (r *ReconcileLogStorage) Reconcile(ctx context.Context, request reconcile.R
 		}
 
 		result, proceed, err = r.createEsGateway(
+			ls,
 			install,
 			variant,
 			pullSecrets,
@@ -614,12 +811,45 @@ func (r *ReconcileLogStorage) Reconcile(ctx context.Context, request reconcile.R
 			return result, err
 		}
 
-		result, proceed, err = r.validateLogStorage(curatorSecrets, esLicenseType, reqLogger, ctx)
+		result, proceed, err = r.applySnapshotPolicy(ls, reqLogger, ctx)
+		if err != nil || !proceed {
+			return result, err
+		}
+
+		result, proceed, err = r.applyDiskWatermarks(ls, reqLogger, ctx)
+		if err != nil || !proceed {
+			return result, err
+		}
+
+		result, proceed, err = r.applyTenantRoles(ls, esLicenseType, reqLogger, ctx)
+		if err != nil || !proceed {
+			return result, err
+		}
+
+		result, proceed, err = r.applyTenants(ls, hdler, reqLogger, ctx)
+		if err != nil || !proceed {
+			return result, err
+		}
+
+		result, proceed, err = r.applyReadOnlyAccess(ls, hdler, reqLogger, ctx)
+		if err != nil || !proceed {
+			return result, err
+		}
+		requeueAfter = minRequeueAfter(requeueAfter, result.RequeueAfter)
+
+		result, proceed, err = r.rotateEsComponentUsers(ls, reqLogger, ctx)
+		if err != nil || !proceed {
+			return result, err
+		}
+		requeueAfter = minRequeueAfter(requeueAfter, result.RequeueAfter)
+
+		result, proceed, err = r.validateLogStorage(ls, curatorSecrets, esLicenseType, reqLogger, ctx)
 		if err != nil || !proceed {
 			return result, err
 		}
 
 		result, proceed, err = r.createEsMetrics(
+			ls,
 			install,
 			variant,
 			pullSecrets,
@@ -632,6 +862,38 @@ func (r *ReconcileLogStorage) Reconcile(ctx context.Context, request reconcile.R
 		if err != nil || !proceed {
 			return result, err
 		}
+
+		// The pull secrets are copied by name, so a secret that is no longer referenced would otherwise stay behind.
+		if err := utils.DeleteStalePullSecretCopies(ctx, r.client, pullSecrets, render.ECKOperatorNamespace, render.ElasticsearchNamespace, render.KibanaNamespace); err != nil {
+			reqLogger.Error(err, "Failed to delete the stale pull secrets")
+			r.status.SetDegraded("Failed to delete the stale pull secrets", err.Error())
+			return reconcile.Result{}, err
+		}
+
+		result, proceed, err = r.applyIngestionLatency(ls, reqLogger, ctx)
+		if err != nil || !proceed {
+			return result, err
+		}
+		requeueAfter = minRequeueAfter(requeueAfter, result.RequeueAfter)
+
+		result, proceed, err = r.applyCuratorBackpressure(ls, reqLogger, ctx)
+		if err != nil || !proceed {
+			return result, err
+		}
+		requeueAfter = minRequeueAfter(requeueAfter, result.RequeueAfter)
+
+		result, proceed, err = r.applyStorageEstimate(ls, reqLogger, ctx)
+		if err != nil || !proceed {
+			return result, err
+		}
+		requeueAfter = minRequeueAfter(requeueAfter, result.RequeueAfter)
+
+		result, proceed, err = r.applyElasticsearchHealth(ls, reqLogger, ctx)
+		if err != nil || !proceed {
+			return result, err
+		}
+
+		requeueAfter = minRequeueAfter(requeueAfter, applyEnterpriseLicense(ls, enterpriseLicense, time.Now()))
 	}
 
 	r.status.ClearDegraded()
@@ -648,7 +910,21 @@ func (r *ReconcileLogStorage) Reconcile(ctx context.Context, request reconcile.R
 		}
 	}
 
-	return reconcile.Result{}, nil
+	// If the Elasticsearch admin user credentials are rotated periodically, make sure we get to reconcile when the
+	// next rotation is due.
+	if _, untilRotation := adminUserRotationDue(ls, eckAdminUserSecret, time.Now()); untilRotation > 0 {
+		requeueAfter = minRequeueAfter(requeueAfter, untilRotation)
+	}
+
+	return reconcile.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// minRequeueAfter returns the earliest of two requeue delays, where zero means that no requeue is needed.
+func minRequeueAfter(a, b time.Duration) time.Duration {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+	return a
 }
 
 func (r *ReconcileLogStorage) getElasticsearch(ctx context.Context) (*esv1.Elasticsearch, error) {
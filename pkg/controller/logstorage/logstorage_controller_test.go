@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
@@ -31,17 +32,23 @@ import (
 
 	admissionv1beta1 "k8s.io/api/admissionregistration/v1beta1"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
 	batchv1beta "k8s.io/api/batch/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
@@ -53,6 +60,7 @@ import (
 	"github.com/tigera/operator/pkg/controller/status"
 	"github.com/tigera/operator/pkg/controller/utils"
 	"github.com/tigera/operator/pkg/dns"
+	"github.com/tigera/operator/pkg/ptr"
 	"github.com/tigera/operator/pkg/render"
 	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
@@ -78,6 +86,7 @@ var (
 	curatorUsrSecretObjMeta   = metav1.ObjectMeta{Name: render.ElasticsearchCuratorUserSecret, Namespace: common.OperatorNamespace()}
 	esMetricsUsrSecretObjMeta = metav1.ObjectMeta{Name: esmetrics.ElasticsearchMetricsSecret, Namespace: common.OperatorNamespace()}
 	storageClassName          = "test-storage-class"
+	curatorEnabled            = operatorv1.CuratorEnabled
 
 	esDNSNames         = dns.GetServiceDNSNames(render.ElasticsearchServiceName, render.ElasticsearchNamespace, dns.DefaultClusterDomain)
 	esGatewayDNSNmes   = dns.GetServiceDNSNames(esgateway.ServiceName, render.ElasticsearchNamespace, dns.DefaultClusterDomain)
@@ -107,7 +116,9 @@ var _ = Describe("LogStorage controller", func() {
 		Expect(apis.AddToScheme(scheme)).ShouldNot(HaveOccurred())
 		Expect(storagev1.SchemeBuilder.AddToScheme(scheme)).ShouldNot(HaveOccurred())
 		Expect(appsv1.SchemeBuilder.AddToScheme(scheme)).ShouldNot(HaveOccurred())
+		Expect(autoscalingv2.SchemeBuilder.AddToScheme(scheme)).ShouldNot(HaveOccurred())
 		Expect(rbacv1.SchemeBuilder.AddToScheme(scheme)).ShouldNot(HaveOccurred())
+		Expect(schedulingv1.SchemeBuilder.AddToScheme(scheme)).ShouldNot(HaveOccurred())
 		Expect(batchv1beta.SchemeBuilder.AddToScheme(scheme)).ShouldNot(HaveOccurred())
 		Expect(admissionv1beta1.SchemeBuilder.AddToScheme(scheme)).ShouldNot(HaveOccurred())
 
@@ -398,6 +409,7 @@ var _ = Describe("LogStorage controller", func() {
 								Count: int64(1),
 							},
 							StorageClassName: storageClassName,
+							Curator:          &curatorEnabled,
 						},
 					})).ShouldNot(HaveOccurred())
 
@@ -478,6 +490,237 @@ var _ = Describe("LogStorage controller", func() {
 					mockStatus.AssertExpectations(GinkgoT())
 				})
 
+				It("test LogStorage rotates the Elasticsearch admin user credentials", func() {
+					Expect(cli.Create(ctx, &storagev1.StorageClass{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: storageClassName,
+						},
+					})).ShouldNot(HaveOccurred())
+
+					Expect(cli.Create(ctx, &operatorv1.LogStorage{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "tigera-secure",
+						},
+						Spec: operatorv1.LogStorageSpec{
+							Nodes: &operatorv1.Nodes{
+								Count: int64(1),
+							},
+							StorageClassName: storageClassName,
+							Curator:          &curatorEnabled,
+							AdminUserRotation: &operatorv1.ESUserRotation{
+								Interval: &metav1.Duration{Duration: 24 * time.Hour},
+							},
+						},
+					})).ShouldNot(HaveOccurred())
+
+					Expect(cli.Create(ctx, &corev1.ConfigMap{
+						ObjectMeta: metav1.ObjectMeta{Namespace: render.ECKOperatorNamespace, Name: render.ECKLicenseConfigMapName},
+						Data:       map[string]string{"eck_license_level": string(render.ElasticsearchLicenseTypeEnterprise)},
+					})).ShouldNot(HaveOccurred())
+
+					r, err := NewReconcilerWithShims(cli, scheme, mockStatus, operatorv1.ProviderNone, mockEsCliCreator, dns.DefaultClusterDomain, readyFlag)
+					Expect(err).ShouldNot(HaveOccurred())
+
+					mockStatus.On("SetDegraded", "Waiting for Elasticsearch cluster to be operational", "").Return()
+					_, err = r.Reconcile(ctx, reconcile.Request{})
+					Expect(err).ShouldNot(HaveOccurred())
+
+					es := &esv1.Elasticsearch{}
+					Expect(cli.Get(ctx, esObjKey, es)).ShouldNot(HaveOccurred())
+					es.Status.Phase = esv1.ElasticsearchReadyPhase
+					Expect(cli.Update(ctx, es)).ShouldNot(HaveOccurred())
+
+					kb := &kbv1.Kibana{}
+					Expect(cli.Get(ctx, kbObjKey, kb)).ShouldNot(HaveOccurred())
+					kb.Status.AssociationStatus = cmnv1.AssociationEstablished
+					Expect(cli.Update(ctx, kb)).ShouldNot(HaveOccurred())
+
+					esAdminUserSecretKey := client.ObjectKey{Name: render.ElasticsearchAdminUserSecret, Namespace: render.ElasticsearchNamespace}
+					Expect(cli.Create(ctx, &corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:              esAdminUserSecretKey.Name,
+							Namespace:         esAdminUserSecretKey.Namespace,
+							UID:               "admin-user-secret",
+							CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour)),
+						},
+						Data: map[string][]byte{
+							"elastic": []byte("password"),
+						},
+					})).ShouldNot(HaveOccurred())
+					Expect(cli.Create(ctx, &corev1.Secret{ObjectMeta: curatorUsrSecretObjMeta})).ShouldNot(HaveOccurred())
+					Expect(cli.Create(ctx, &corev1.Secret{ObjectMeta: esMetricsUsrSecretObjMeta})).ShouldNot(HaveOccurred())
+
+					By("requeuing for when the admin user credentials expire")
+					mockStatus.On("ClearDegraded")
+					result, err := r.Reconcile(ctx, reconcile.Request{})
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(result.RequeueAfter).Should(BeNumerically("~", 23*time.Hour, time.Minute))
+
+					By("annotating ES gateway with the hash of the admin user credentials")
+					deploy := &appsv1.Deployment{}
+					Expect(cli.Get(ctx, client.ObjectKey{Name: esgateway.DeploymentName, Namespace: render.ElasticsearchNamespace}, deploy)).ShouldNot(HaveOccurred())
+					Expect(deploy.Spec.Template.Annotations).Should(HaveKey(esgateway.EsAdminUserSecretHashAnnotation))
+
+					By("rotating the admin user credentials when the rotation token changes")
+					ls := &operatorv1.LogStorage{}
+					Expect(cli.Get(ctx, utils.DefaultTSEEInstanceKey, ls)).ShouldNot(HaveOccurred())
+					ls.Spec.AdminUserRotation.RotationToken = "rotate-now"
+					Expect(cli.Update(ctx, ls)).ShouldNot(HaveOccurred())
+
+					result, err = r.Reconcile(ctx, reconcile.Request{})
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(result).Should(Equal(reconcile.Result{RequeueAfter: 10 * time.Second}))
+
+					Expect(cli.Get(ctx, esAdminUserSecretKey, &corev1.Secret{})).Should(WithTransform(errors.IsNotFound, BeTrue()))
+					Expect(cli.Get(ctx, utils.DefaultTSEEInstanceKey, ls)).ShouldNot(HaveOccurred())
+					Expect(ls.Status.AdminUserRotationToken).Should(Equal("rotate-now"))
+					Expect(ls.Status.LastAdminUserRotation).ShouldNot(BeNil())
+					Expect(ls.Status.RotatedAdminUserSecretUID).Should(Equal(types.UID("admin-user-secret")))
+
+					mockStatus.AssertExpectations(GinkgoT())
+				})
+
+				It("test LogStorage reports the failures to provision the Elasticsearch storage", func() {
+					Expect(cli.Create(ctx, &storagev1.StorageClass{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: storageClassName,
+						},
+					})).ShouldNot(HaveOccurred())
+
+					Expect(cli.Create(ctx, &operatorv1.LogStorage{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "tigera-secure",
+						},
+						Spec: operatorv1.LogStorageSpec{
+							Nodes: &operatorv1.Nodes{
+								Count: int64(1),
+							},
+							StorageClassName: storageClassName,
+							Curator:          &curatorEnabled,
+						},
+					})).ShouldNot(HaveOccurred())
+
+					Expect(cli.Create(ctx, &corev1.ConfigMap{
+						ObjectMeta: metav1.ObjectMeta{Namespace: render.ECKOperatorNamespace, Name: render.ECKLicenseConfigMapName},
+						Data:       map[string]string{"eck_license_level": string(render.ElasticsearchLicenseTypeEnterprise)},
+					})).ShouldNot(HaveOccurred())
+
+					Expect(cli.Create(ctx, &corev1.PersistentVolumeClaim{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "elasticsearch-data-tigera-secure-es-0",
+							Namespace: render.ElasticsearchNamespace,
+							Labels:    map[string]string{"elasticsearch.k8s.elastic.co/cluster-name": render.ElasticsearchName},
+						},
+						Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+					})).ShouldNot(HaveOccurred())
+					Expect(cli.Create(ctx, &corev1.Event{
+						ObjectMeta: metav1.ObjectMeta{Name: "provisioning-failed", Namespace: render.ElasticsearchNamespace},
+						InvolvedObject: corev1.ObjectReference{
+							Kind:      "PersistentVolumeClaim",
+							Name:      "elasticsearch-data-tigera-secure-es-0",
+							Namespace: render.ElasticsearchNamespace,
+						},
+						Type:          corev1.EventTypeWarning,
+						Reason:        "ProvisioningFailed",
+						Message:       "storageclass.storage.k8s.io \"test-storage-class\" not found",
+						LastTimestamp: metav1.Now(),
+					})).ShouldNot(HaveOccurred())
+
+					r, err := NewReconcilerWithShims(cli, scheme, mockStatus, operatorv1.ProviderNone, mockEsCliCreator, dns.DefaultClusterDomain, readyFlag)
+					Expect(err).ShouldNot(HaveOccurred())
+
+					mockStatus.On("SetDegraded", "Failed to provision the Elasticsearch storage",
+						"PersistentVolumeClaim elasticsearch-data-tigera-secure-es-0: storageclass.storage.k8s.io \"test-storage-class\" not found").Return()
+					result, err := r.Reconcile(ctx, reconcile.Request{})
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(result).Should(Equal(reconcile.Result{}))
+
+					By("no longer reporting the failure once the PVC is bound")
+					pvc := &corev1.PersistentVolumeClaim{}
+					Expect(cli.Get(ctx, client.ObjectKey{Name: "elasticsearch-data-tigera-secure-es-0", Namespace: render.ElasticsearchNamespace}, pvc)).ShouldNot(HaveOccurred())
+					pvc.Status.Phase = corev1.ClaimBound
+					Expect(cli.Update(ctx, pvc)).ShouldNot(HaveOccurred())
+
+					mockStatus.On("SetDegraded", "Waiting for Elasticsearch cluster to be operational", "").Return()
+					_, err = r.Reconcile(ctx, reconcile.Request{})
+					Expect(err).ShouldNot(HaveOccurred())
+
+					mockStatus.AssertExpectations(GinkgoT())
+				})
+
+				It("test LogStorage is degraded when the ingestion latency of logs exceeds the SLO", func() {
+					Expect(cli.Create(ctx, &storagev1.StorageClass{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: storageClassName,
+						},
+					})).ShouldNot(HaveOccurred())
+
+					Expect(cli.Create(ctx, &operatorv1.LogStorage{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "tigera-secure",
+						},
+						Spec: operatorv1.LogStorageSpec{
+							Nodes: &operatorv1.Nodes{
+								Count: int64(1),
+							},
+							StorageClassName: storageClassName,
+							Curator:          &curatorEnabled,
+							IngestionLatency: &operatorv1.IngestionLatency{
+								SLO: &metav1.Duration{Duration: 5 * time.Minute},
+							},
+						},
+					})).ShouldNot(HaveOccurred())
+
+					Expect(cli.Create(ctx, &corev1.ConfigMap{
+						ObjectMeta: metav1.ObjectMeta{Namespace: render.ECKOperatorNamespace, Name: render.ECKLicenseConfigMapName},
+						Data:       map[string]string{"eck_license_level": string(render.ElasticsearchLicenseTypeEnterprise)},
+					})).ShouldNot(HaveOccurred())
+
+					r, err := NewReconcilerWithShims(cli, scheme, mockStatus, operatorv1.ProviderNone, mockEsCliCreator, dns.DefaultClusterDomain, readyFlag)
+					Expect(err).ShouldNot(HaveOccurred())
+
+					mockStatus.On("SetDegraded", "Waiting for Elasticsearch cluster to be operational", "").Return()
+					_, err = r.Reconcile(ctx, reconcile.Request{})
+					Expect(err).ShouldNot(HaveOccurred())
+
+					es := &esv1.Elasticsearch{}
+					Expect(cli.Get(ctx, esObjKey, es)).ShouldNot(HaveOccurred())
+					es.Status.Phase = esv1.ElasticsearchReadyPhase
+					Expect(cli.Update(ctx, es)).ShouldNot(HaveOccurred())
+
+					kb := &kbv1.Kibana{}
+					Expect(cli.Get(ctx, kbObjKey, kb)).ShouldNot(HaveOccurred())
+					kb.Status.AssociationStatus = cmnv1.AssociationEstablished
+					Expect(cli.Update(ctx, kb)).ShouldNot(HaveOccurred())
+
+					Expect(cli.Create(ctx, &corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchAdminUserSecret, Namespace: render.ElasticsearchNamespace},
+						Data:       map[string][]byte{"elastic": []byte("password")},
+					})).ShouldNot(HaveOccurred())
+					Expect(cli.Create(ctx, &corev1.Secret{ObjectMeta: curatorUsrSecretObjMeta})).ShouldNot(HaveOccurred())
+					Expect(cli.Create(ctx, &corev1.Secret{ObjectMeta: esMetricsUsrSecretObjMeta})).ShouldNot(HaveOccurred())
+
+					By("requeuing for the next measurement while the latency is within the SLO")
+					mockStatus.On("ClearDegraded")
+					result, err := r.Reconcile(ctx, reconcile.Request{})
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(result.RequeueAfter).Should(BeNumerically(">", 0))
+					Expect(result.RequeueAfter).Should(BeNumerically("<=", 5*time.Minute))
+
+					By("degrading when the last measured latency exceeds the SLO")
+					ls := &operatorv1.LogStorage{}
+					Expect(cli.Get(ctx, utils.DefaultTSEEInstanceKey, ls)).ShouldNot(HaveOccurred())
+					ls.Spec.IngestionLatency.SLO = &metav1.Duration{Duration: time.Minute}
+					Expect(cli.Update(ctx, ls)).ShouldNot(HaveOccurred())
+
+					mockStatus.On("SetDegraded", "Log ingestion latency SLO exceeded", "The 99th percentile of the ingestion latency is 2m0s, which exceeds the SLO of 1m0s").Return()
+					result, err = r.Reconcile(ctx, reconcile.Request{})
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(result.RequeueAfter).Should(BeNumerically("~", 5*time.Minute, time.Second))
+
+					mockStatus.AssertExpectations(GinkgoT())
+				})
+
 				It("test LogStorage reconciles successfully for elasticsearch basic license", func() {
 
 					Expect(cli.Create(ctx, &operatorv1.Authentication{
@@ -510,6 +753,7 @@ var _ = Describe("LogStorage controller", func() {
 								Count: int64(1),
 							},
 							StorageClassName: storageClassName,
+							Curator:          &curatorEnabled,
 						},
 					})).ShouldNot(HaveOccurred())
 
@@ -643,6 +887,7 @@ var _ = Describe("LogStorage controller", func() {
 								Count: int64(1),
 							},
 							StorageClassName: storageClassName,
+							Curator:          &curatorEnabled,
 						},
 					})).ShouldNot(HaveOccurred())
 
@@ -682,6 +927,7 @@ var _ = Describe("LogStorage controller", func() {
 								Count: int64(1),
 							},
 							StorageClassName: storageClassName,
+							Curator:          &curatorEnabled,
 						},
 					})).ShouldNot(HaveOccurred())
 
@@ -784,6 +1030,7 @@ var _ = Describe("LogStorage controller", func() {
 						},
 						Spec: operatorv1.LogStorageSpec{
 							StorageClassName: storageClassName,
+							Curator:          &curatorEnabled,
 						},
 					})).ShouldNot(HaveOccurred())
 
@@ -818,6 +1065,7 @@ var _ = Describe("LogStorage controller", func() {
 						},
 						Spec: operatorv1.LogStorageSpec{
 							StorageClassName: storageClassName,
+							Curator:          &curatorEnabled,
 						},
 					})).ShouldNot(HaveOccurred())
 
@@ -864,6 +1112,7 @@ var _ = Describe("LogStorage controller", func() {
 									Count: int64(1),
 								},
 								StorageClassName: storageClassName,
+								Curator:          &curatorEnabled,
 							},
 						},
 						&esv1.Elasticsearch{
@@ -935,6 +1184,7 @@ var _ = Describe("LogStorage controller", func() {
 									Count: int64(1),
 								},
 								StorageClassName: storageClassName,
+								Curator:          &curatorEnabled,
 							},
 						},
 						&esv1.Elasticsearch{
@@ -1013,6 +1263,7 @@ var _ = Describe("LogStorage controller", func() {
 								Count: int64(1),
 							},
 							StorageClassName: storageClassName,
+							Curator:          &curatorEnabled,
 						},
 					})).ShouldNot(HaveOccurred())
 
@@ -1043,6 +1294,7 @@ var _ = Describe("LogStorage controller", func() {
 									Count: int64(1),
 								},
 								StorageClassName: storageClassName,
+								Curator:          &curatorEnabled,
 							},
 						})).ShouldNot(HaveOccurred())
 
@@ -1340,6 +1592,7 @@ var _ = Describe("LogStorage controller", func() {
 									Count: int64(1),
 								},
 								StorageClassName: storageClassName,
+								Curator:          &curatorEnabled,
 							},
 						})).ShouldNot(HaveOccurred())
 
@@ -1515,6 +1768,813 @@ var _ = Describe("LogStorage controller", func() {
 			Expect(validateComponentResources(&ls.Spec)).To(BeNil())
 		})
 	})
+	Context("validateDiskWatermarks", func() {
+		DescribeTable("validating the disk watermarks",
+			func(low, high, floodStage *int32, valid bool) {
+				spec := &operatorv1.LogStorageSpec{Nodes: &operatorv1.Nodes{
+					DiskWatermarks: &operatorv1.DiskWatermarks{Low: low, High: high, FloodStage: floodStage},
+				}}
+				if valid {
+					Expect(validateDiskWatermarks(spec)).NotTo(HaveOccurred())
+				} else {
+					Expect(validateDiskWatermarks(spec)).To(HaveOccurred())
+				}
+			},
+			Entry("defaults", nil, nil, nil, true),
+			Entry("raised watermarks", ptr.Int32ToPtr(95), ptr.Int32ToPtr(97), ptr.Int32ToPtr(99), true),
+			Entry("only a raised flood stage", nil, nil, ptr.Int32ToPtr(98), true),
+			Entry("low at the maximum total storage of the retention", ptr.Int32ToPtr(80), nil, nil, false),
+			Entry("high below low", ptr.Int32ToPtr(92), nil, nil, false),
+			Entry("flood stage equal to high", nil, ptr.Int32ToPtr(95), nil, false),
+			Entry("only a raised high", nil, ptr.Int32ToPtr(92), nil, true),
+			Entry("high at the maximum total storage of the retention", nil, ptr.Int32ToPtr(80), ptr.Int32ToPtr(82), false),
+			Entry("flood stage at the maximum total storage of the retention", ptr.Int32ToPtr(81), ptr.Int32ToPtr(82), ptr.Int32ToPtr(80), false),
+		)
+	})
+	Context("validateDisruptionPolicies", func() {
+		minAvailable := intstr.FromInt(1)
+		DescribeTable("validating the disruption policies",
+			func(name operatorv1.LogStorageComponentName, policy operatorv1.DisruptionPolicy, valid bool) {
+				spec := &operatorv1.LogStorageSpec{ComponentDisruptionPolicies: []operatorv1.LogStorageComponentDisruptionPolicy{
+					{ComponentName: name, DisruptionPolicy: policy},
+				}}
+				if valid {
+					Expect(validateDisruptionPolicies(spec)).NotTo(HaveOccurred())
+				} else {
+					Expect(validateDisruptionPolicies(spec)).To(HaveOccurred())
+				}
+			},
+			Entry("a preStop delay of Kibana", operatorv1.ComponentNameKibana, operatorv1.DisruptionPolicy{PreStopDelaySeconds: ptr.Int64ToPtr(5)}, true),
+			Entry("a preStop delay of the curator", operatorv1.ComponentNameEsCurator, operatorv1.DisruptionPolicy{PreStopDelaySeconds: ptr.Int64ToPtr(5)}, true),
+			Entry("a preStop delay of the gateway", operatorv1.ComponentNameESGateway, operatorv1.DisruptionPolicy{PreStopDelaySeconds: ptr.Int64ToPtr(5)}, false),
+			Entry("a preStop delay of the metrics", operatorv1.ComponentNameESMetrics, operatorv1.DisruptionPolicy{PreStopDelaySeconds: ptr.Int64ToPtr(5)}, false),
+			Entry("a PodDisruptionBudget of the gateway", operatorv1.ComponentNameESGateway, operatorv1.DisruptionPolicy{PodDisruptionBudget: &operatorv1.PodDisruptionBudget{MinAvailable: &minAvailable}}, true),
+			Entry("a PodDisruptionBudget of the kube controllers", operatorv1.ComponentNameESKubeControllers, operatorv1.DisruptionPolicy{PodDisruptionBudget: &operatorv1.PodDisruptionBudget{MinAvailable: &minAvailable}}, false),
+			Entry("a PodDisruptionBudget of the jobs", operatorv1.ComponentNameLogStorageJobs, operatorv1.DisruptionPolicy{PodDisruptionBudget: &operatorv1.PodDisruptionBudget{MinAvailable: &minAvailable}}, false),
+		)
+	})
+	Context("destructiveChanges", func() {
+		nodeSet := func(name, storageClass string) esv1.NodeSet {
+			return esv1.NodeSet{
+				Name: name,
+				VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{
+					Spec: corev1.PersistentVolumeClaimSpec{StorageClassName: &storageClass},
+				}},
+			}
+		}
+		elasticsearch := func(nodeSets ...esv1.NodeSet) *esv1.Elasticsearch {
+			return &esv1.Elasticsearch{Spec: esv1.ElasticsearchSpec{NodeSets: nodeSets}}
+		}
+		ls := &operatorv1.LogStorage{ObjectMeta: metav1.ObjectMeta{Generation: 3}}
+
+		It("should report the replacement of the NodeSets and the deletion of Elasticsearch", func() {
+			current := elasticsearch(nodeSet("a", "fast"), nodeSet("b", "fast"))
+			Expect(destructiveChanges(ls, current, elasticsearch(nodeSet("a", "fast"), nodeSet("b", "fast")), false)).To(BeEmpty())
+			Expect(destructiveChanges(ls, current, elasticsearch(nodeSet("a", "fast"), nodeSet("c", "fast")), false)).To(Equal([]string{
+				"NodeSet b is replaced, since its storage changed",
+			}))
+			Expect(destructiveChanges(ls, current, elasticsearch(nodeSet("c", "slow")), false)).To(Equal([]string{
+				"NodeSet a is replaced, since its StorageClass fast is no longer used",
+				"NodeSet b is replaced, since its StorageClass fast is no longer used",
+			}))
+			Expect(destructiveChanges(ls, current, nil, true)).To(HaveLen(1))
+		})
+
+		It("should report the removal of the NodeSet of a zone", func() {
+			zoneNodeSet := func(name, zone string) esv1.NodeSet {
+				ns := nodeSet(name, "fast")
+				ns.Config = &cmnv1.Config{Data: map[string]interface{}{"node.attr.zone": zone}}
+				return ns
+			}
+			current := elasticsearch(zoneNodeSet("a", "zone-a"), zoneNodeSet("b", "zone-b"))
+			Expect(destructiveChanges(ls, current, elasticsearch(zoneNodeSet("a", "zone-a")), false)).To(Equal([]string{
+				"NodeSet b is removed, since Elasticsearch no longer runs in its zone zone-b",
+			}))
+		})
+
+		It("should not report the changes of a new cluster or a deleted LogStorage", func() {
+			Expect(destructiveChanges(ls, nil, elasticsearch(nodeSet("a", "fast")), false)).To(BeEmpty())
+			deleted := ls.DeepCopy()
+			now := metav1.Now()
+			deleted.DeletionTimestamp = &now
+			Expect(destructiveChanges(deleted, elasticsearch(nodeSet("a", "fast")), nil, true)).To(BeEmpty())
+		})
+
+		It("should only acknowledge the loss of data for the generation of the LogStorage", func() {
+			acknowledged := ls.DeepCopy()
+			Expect(dataLossAcknowledged(acknowledged)).To(BeFalse())
+			acknowledged.Spec.DataLossAcknowledged = ptr.Int64ToPtr(2)
+			Expect(dataLossAcknowledged(acknowledged)).To(BeFalse())
+			acknowledged.Spec.DataLossAcknowledged = ptr.Int64ToPtr(3)
+			Expect(dataLossAcknowledged(acknowledged)).To(BeTrue())
+		})
+	})
+	Context("validatePodTemplatePatches", func() {
+		DescribeTable("validating the pod template patches of the NodeSets",
+			func(patch string, valid bool) {
+				spec := &operatorv1.LogStorageSpec{Nodes: &operatorv1.Nodes{
+					NodeSets: []operatorv1.NodeSet{{PodTemplatePatch: &runtime.RawExtension{Raw: []byte(patch)}}},
+				}}
+				if valid {
+					Expect(validatePodTemplatePatches(spec)).NotTo(HaveOccurred())
+				} else {
+					Expect(validatePodTemplatePatches(spec)).To(HaveOccurred())
+				}
+			},
+			Entry("a sidecar", `{"spec": {"containers": [{"name": "apm-agent", "image": "apm-agent:1.0"}]}}`, true),
+			Entry("invalid JSON", `{"spec": `, false),
+			Entry("a field of the wrong type", `{"spec": {"containers": "apm-agent"}}`, false),
+		)
+	})
+	Context("validateTransportTLS", func() {
+		DescribeTable("validating the transport TLS",
+			func(protocols, ciphers []string, valid bool) {
+				spec := &operatorv1.LogStorageSpec{TransportTLS: &operatorv1.ElasticsearchTransportTLS{
+					SupportedProtocols: protocols,
+					CipherSuites:       ciphers,
+				}}
+				if valid {
+					Expect(validateTransportTLS(spec)).NotTo(HaveOccurred())
+				} else {
+					Expect(validateTransportTLS(spec)).To(HaveOccurred())
+				}
+			},
+			Entry("defaults", nil, nil, true),
+			Entry("TLS 1.3 and 1.2 with a cipher suite", []string{"TLSv1.3", "TLSv1.2"}, []string{"TLS_AES_256_GCM_SHA384"}, true),
+			Entry("SSLv3", []string{"SSLv3"}, nil, false),
+			Entry("TLS 1.1", []string{"TLSv1.2", "TLSv1.1"}, nil, false),
+			Entry("an empty cipher suite", nil, []string{" "}, false),
+		)
+	})
+	Context("adminUserRotationDue", func() {
+		now := time.Now()
+		secretCreatedAt := func(t time.Time) *corev1.Secret {
+			return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(t)}}
+		}
+		secretWithUID := func(uid types.UID, t time.Time) *corev1.Secret {
+			return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{UID: uid, CreationTimestamp: metav1.NewTime(t)}}
+		}
+
+		DescribeTable("checking whether the admin user credentials must be rotated",
+			func(spec *operatorv1.ESUserRotation, st operatorv1.LogStorageStatus, secret *corev1.Secret, expectDue bool, expectRemaining time.Duration) {
+				ls := &operatorv1.LogStorage{Spec: operatorv1.LogStorageSpec{AdminUserRotation: spec}, Status: st}
+				due, remaining := adminUserRotationDue(ls, secret, now)
+				Expect(due).To(Equal(expectDue))
+				Expect(remaining).To(Equal(expectRemaining))
+			},
+			Entry("nil spec", (*operatorv1.ESUserRotation)(nil), operatorv1.LogStorageStatus{}, secretCreatedAt(now.Add(-time.Hour)), false, time.Duration(0)),
+			Entry("nil secret", &operatorv1.ESUserRotation{RotationToken: "a"}, operatorv1.LogStorageStatus{}, (*corev1.Secret)(nil), false, time.Duration(0)),
+			Entry("token changed", &operatorv1.ESUserRotation{RotationToken: "b"},
+				operatorv1.LogStorageStatus{AdminUserRotationToken: "a"}, secretCreatedAt(now.Add(-time.Hour)), true, time.Duration(0)),
+			Entry("token unchanged", &operatorv1.ESUserRotation{RotationToken: "a"},
+				operatorv1.LogStorageStatus{AdminUserRotationToken: "a"}, secretCreatedAt(now.Add(-time.Hour)), false, time.Duration(0)),
+			Entry("interval elapsed", &operatorv1.ESUserRotation{Interval: &metav1.Duration{Duration: time.Hour}},
+				operatorv1.LogStorageStatus{}, secretCreatedAt(now.Add(-2*time.Hour)), true, time.Duration(0)),
+			Entry("interval not elapsed", &operatorv1.ESUserRotation{Interval: &metav1.Duration{Duration: 2 * time.Hour}},
+				operatorv1.LogStorageStatus{}, secretCreatedAt(now.Add(-time.Hour)), false, time.Hour),
+			Entry("zero interval", &operatorv1.ESUserRotation{Interval: &metav1.Duration{}},
+				operatorv1.LogStorageStatus{}, secretCreatedAt(now.Add(-time.Hour)), false, time.Duration(0)),
+			Entry("rotation recorded but secret not yet replaced", (*operatorv1.ESUserRotation)(nil),
+				operatorv1.LogStorageStatus{RotatedAdminUserSecretUID: "old"}, secretWithUID("old", now.Add(-time.Hour)), true, time.Duration(0)),
+			Entry("rotation recorded and secret replaced", (*operatorv1.ESUserRotation)(nil),
+				operatorv1.LogStorageStatus{RotatedAdminUserSecretUID: "old"}, secretWithUID("new", now.Add(-time.Minute)), false, time.Duration(0)),
+			Entry("rotation recorded and secret replaced with an earlier creation timestamp", (*operatorv1.ESUserRotation)(nil),
+				operatorv1.LogStorageStatus{LastAdminUserRotation: &metav1.Time{Time: now}, RotatedAdminUserSecretUID: "old"},
+				secretWithUID("new", now.Add(-time.Minute)), false, time.Duration(0)),
+		)
+	})
+	Context("componentUserRotationDue", func() {
+		now := time.Now()
+		secretCreatedAt := func(t time.Time) *corev1.Secret {
+			return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(t)}}
+		}
+		secretWithUID := func(uid types.UID, t time.Time) *corev1.Secret {
+			return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{UID: uid, CreationTimestamp: metav1.NewTime(t)}}
+		}
+
+		DescribeTable("checking whether the credentials of the users of the components must be rotated",
+			func(spec *operatorv1.ESUserRotation, st operatorv1.LogStorageStatus, secrets []*corev1.Secret, expectDue bool, expectRemaining time.Duration) {
+				ls := &operatorv1.LogStorage{Spec: operatorv1.LogStorageSpec{ComponentUserRotation: spec}, Status: st}
+				due, remaining := componentUserRotationDue(ls, secrets, now)
+				Expect(due).To(Equal(expectDue))
+				Expect(remaining).To(Equal(expectRemaining))
+			},
+			Entry("no secrets", &operatorv1.ESUserRotation{RotationToken: "a"}, operatorv1.LogStorageStatus{}, []*corev1.Secret{nil, nil}, false, time.Duration(0)),
+			Entry("token changed", &operatorv1.ESUserRotation{RotationToken: "b"},
+				operatorv1.LogStorageStatus{ComponentUserRotationToken: "a"}, []*corev1.Secret{secretCreatedAt(now.Add(-time.Hour))}, true, time.Duration(0)),
+			Entry("the admin user token changed", &operatorv1.ESUserRotation{RotationToken: "a"},
+				operatorv1.LogStorageStatus{ComponentUserRotationToken: "a", AdminUserRotationToken: "b"}, []*corev1.Secret{secretCreatedAt(now.Add(-time.Hour))}, false, time.Duration(0)),
+			Entry("interval elapsed for the oldest secret", &operatorv1.ESUserRotation{Interval: &metav1.Duration{Duration: 2 * time.Hour}},
+				operatorv1.LogStorageStatus{}, []*corev1.Secret{secretCreatedAt(now.Add(-time.Hour)), nil, secretCreatedAt(now.Add(-3 * time.Hour))}, true, time.Duration(0)),
+			Entry("interval not elapsed for the oldest secret", &operatorv1.ESUserRotation{Interval: &metav1.Duration{Duration: 2 * time.Hour}},
+				operatorv1.LogStorageStatus{}, []*corev1.Secret{secretCreatedAt(now.Add(-time.Minute)), secretCreatedAt(now.Add(-time.Hour))}, false, time.Hour),
+			Entry("rotation recorded but a secret not yet replaced", (*operatorv1.ESUserRotation)(nil),
+				operatorv1.LogStorageStatus{RotatedComponentUserSecretUIDs: []types.UID{"old-1", "old-2"}},
+				[]*corev1.Secret{secretWithUID("new-1", now), secretWithUID("old-2", now.Add(-time.Hour))}, true, time.Duration(0)),
+			Entry("rotation recorded and all secrets replaced", (*operatorv1.ESUserRotation)(nil),
+				operatorv1.LogStorageStatus{RotatedComponentUserSecretUIDs: []types.UID{"old-1", "old-2"}},
+				[]*corev1.Secret{secretWithUID("new-1", now), nil}, false, time.Duration(0)),
+			Entry("rotation recorded and all secrets replaced with earlier creation timestamps", (*operatorv1.ESUserRotation)(nil),
+				operatorv1.LogStorageStatus{LastComponentUserRotation: &metav1.Time{Time: now}, RotatedComponentUserSecretUIDs: []types.UID{"old-1", "old-2"}},
+				[]*corev1.Secret{secretWithUID("new-1", now.Add(-time.Minute)), secretWithUID("new-2", now.Add(-time.Minute))}, false, time.Duration(0)),
+		)
+	})
+	Context("esRecovering", func() {
+		threshold := int32(5)
+		DescribeTable("checking whether Elasticsearch is recovering",
+			func(spec *operatorv1.CuratorBackpressure, health utils.ClusterHealth, expected bool) {
+				ls := &operatorv1.LogStorage{Spec: operatorv1.LogStorageSpec{CuratorBackpressure: spec}}
+				Expect(esRecovering(ls, &health)).To(Equal(expected))
+			},
+			Entry("green", (*operatorv1.CuratorBackpressure)(nil), utils.ClusterHealth{Status: "green", RelocatingShards: 3}, false),
+			Entry("yellow without relocating shards", (*operatorv1.CuratorBackpressure)(nil), utils.ClusterHealth{Status: "yellow"}, false),
+			Entry("yellow with relocating shards", (*operatorv1.CuratorBackpressure)(nil), utils.ClusterHealth{Status: "yellow", RelocatingShards: 1}, true),
+			Entry("red with relocating shards", (*operatorv1.CuratorBackpressure)(nil), utils.ClusterHealth{Status: "red", RelocatingShards: 1}, true),
+			Entry("yellow below the threshold", &operatorv1.CuratorBackpressure{RelocatingShardsThreshold: &threshold},
+				utils.ClusterHealth{Status: "yellow", RelocatingShards: 5}, false),
+			Entry("yellow above the threshold", &operatorv1.CuratorBackpressure{RelocatingShardsThreshold: &threshold},
+				utils.ClusterHealth{Status: "yellow", RelocatingShards: 6}, true),
+		)
+	})
+	Context("esHealthConditions", func() {
+		conditionStatus := func(conditions []metav1.Condition, conditionType string) (metav1.ConditionStatus, string) {
+			for _, condition := range conditions {
+				if condition.Type == conditionType {
+					return condition.Status, condition.Reason
+				}
+			}
+			return "", ""
+		}
+
+		DescribeTable("setting the conditions from the health of Elasticsearch",
+			func(health utils.ClusterHealth, diskUsage map[string]int, conditionType string, status metav1.ConditionStatus, reason string) {
+				ls := &operatorv1.LogStorage{}
+				actualStatus, actualReason := conditionStatus(esHealthConditions(ls, &health, diskUsage), conditionType)
+				Expect(actualStatus).To(Equal(status))
+				Expect(actualReason).To(Equal(reason))
+			},
+			Entry("green", utils.ClusterHealth{Status: "green"}, nil,
+				operatorv1.LogStorageConditionElasticsearchHealthy, metav1.ConditionTrue, "Green"),
+			Entry("red", utils.ClusterHealth{Status: "red"}, nil,
+				operatorv1.LogStorageConditionElasticsearchHealthy, metav1.ConditionFalse, "Red"),
+			Entry("unknown", utils.ClusterHealth{Status: "unavailable"}, nil,
+				operatorv1.LogStorageConditionElasticsearchHealthy, metav1.ConditionUnknown, "Unknown"),
+			Entry("all shards assigned", utils.ClusterHealth{Status: "green"}, nil,
+				operatorv1.LogStorageConditionShardsAssigned, metav1.ConditionTrue, "AllShardsAssigned"),
+			Entry("unassigned shards", utils.ClusterHealth{Status: "yellow", UnassignedShards: 2}, nil,
+				operatorv1.LogStorageConditionShardsAssigned, metav1.ConditionFalse, "UnassignedShards"),
+			Entry("disks below the watermarks", utils.ClusterHealth{Status: "green"}, map[string]int{"es-0": 50, "es-1": 84},
+				operatorv1.LogStorageConditionDiskAvailable, metav1.ConditionTrue, "BelowWatermarks"),
+			Entry("disk above the low watermark", utils.ClusterHealth{Status: "green"}, map[string]int{"es-0": 50, "es-1": 85},
+				operatorv1.LogStorageConditionDiskAvailable, metav1.ConditionTrue, "LowWatermarkExceeded"),
+			Entry("disk above the high watermark", utils.ClusterHealth{Status: "green"}, map[string]int{"es-0": 92, "es-1": 85},
+				operatorv1.LogStorageConditionDiskAvailable, metav1.ConditionFalse, "HighWatermarkExceeded"),
+			Entry("disk above the flood stage watermark", utils.ClusterHealth{Status: "green"}, map[string]int{"es-0": 97},
+				operatorv1.LogStorageConditionDiskAvailable, metav1.ConditionFalse, "FloodStageWatermarkExceeded"),
+		)
+
+		It("sets all the conditions to unknown when the health can't be fetched", func() {
+			conditions := esHealthUnknownConditions(&operatorv1.LogStorage{}, fmt.Errorf("connection refused"))
+			Expect(conditions).To(HaveLen(3))
+			for _, conditionType := range []string{
+				operatorv1.LogStorageConditionElasticsearchHealthy,
+				operatorv1.LogStorageConditionShardsAssigned,
+				operatorv1.LogStorageConditionDiskAvailable,
+			} {
+				status, reason := conditionStatus(conditions, conditionType)
+				Expect(status).To(Equal(metav1.ConditionUnknown))
+				Expect(reason).To(Equal("HealthUnavailable"))
+			}
+		})
+	})
+	Context("nextArchiveRestoreStatus", func() {
+		now := time.Now()
+		restore := operatorv1.ArchiveRestore{Name: "incident", TTL: &metav1.Duration{Duration: time.Hour}}
+		jobWithCondition := func(conditionType batchv1.JobConditionType, at time.Time) *batchv1.Job {
+			return &batchv1.Job{Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{
+				{Type: conditionType, Status: corev1.ConditionTrue, LastTransitionTime: metav1.NewTime(at)},
+			}}}
+		}
+
+		It("should run a new restore", func() {
+			status := nextArchiveRestoreStatus(restore, operatorv1.ArchiveRestoreStatus{}, nil, now)
+			Expect(status.Phase).To(Equal(operatorv1.ArchiveRestoreRunning))
+			Expect(status.Index).To(Equal("tigera_secure_ee_restored_flows.incident"))
+		})
+
+		It("should expire the index after the TTL once the job completes", func() {
+			status := nextArchiveRestoreStatus(restore, operatorv1.ArchiveRestoreStatus{Phase: operatorv1.ArchiveRestoreRunning},
+				jobWithCondition(batchv1.JobComplete, now.Add(-time.Minute)), now)
+			Expect(status.Phase).To(Equal(operatorv1.ArchiveRestoreSucceeded))
+			Expect(status.ExpirationTime.Time).To(BeTemporally("~", now.Add(59*time.Minute), time.Second))
+
+			status = nextArchiveRestoreStatus(restore, status, nil, now.Add(time.Hour))
+			Expect(status.Phase).To(Equal(operatorv1.ArchiveRestoreExpired))
+		})
+
+		It("should not run a failed restore again when its job is deleted", func() {
+			status := nextArchiveRestoreStatus(restore, operatorv1.ArchiveRestoreStatus{Phase: operatorv1.ArchiveRestoreRunning},
+				jobWithCondition(batchv1.JobFailed, now), now)
+			Expect(status.Phase).To(Equal(operatorv1.ArchiveRestoreFailed))
+
+			status = nextArchiveRestoreStatus(restore, status, nil, now)
+			Expect(status.Phase).To(Equal(operatorv1.ArchiveRestoreFailed))
+		})
+	})
+	Context("nextReadOnlyAccessStatus", func() {
+		now := time.Now()
+		grant := operatorv1.ReadOnlyAccessGrant{Name: "auditor", TTL: &metav1.Duration{Duration: time.Hour}}
+
+		It("should grant a new access until its TTL", func() {
+			status := nextReadOnlyAccessStatus(grant, operatorv1.ReadOnlyAccessStatus{}, nil, now)
+			Expect(status.Phase).To(Equal(operatorv1.ReadOnlyAccessActive))
+			Expect(status.SecretName).To(Equal("tigera-read-only-auditor-elasticsearch-user"))
+			Expect(status.ExpirationTime.Time).To(BeTemporally("~", now.Add(time.Hour), time.Second))
+
+			status = nextReadOnlyAccessStatus(grant, status, nil, now.Add(time.Hour))
+			Expect(status.Phase).To(Equal(operatorv1.ReadOnlyAccessExpired))
+		})
+
+		It("should expire from the creation of the secret when the state is lost", func() {
+			userSecret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(now.Add(-2 * time.Hour))}}
+			status := nextReadOnlyAccessStatus(grant, operatorv1.ReadOnlyAccessStatus{}, userSecret, now)
+			Expect(status.Phase).To(Equal(operatorv1.ReadOnlyAccessExpired))
+		})
+
+		It("should follow changes of the TTL of an active access, but not grant an expired one again", func() {
+			status := nextReadOnlyAccessStatus(grant, operatorv1.ReadOnlyAccessStatus{}, nil, now)
+			extended := operatorv1.ReadOnlyAccessGrant{Name: "auditor", TTL: &metav1.Duration{Duration: 2 * time.Hour}}
+			status = nextReadOnlyAccessStatus(extended, status, nil, now.Add(time.Hour))
+			Expect(status.Phase).To(Equal(operatorv1.ReadOnlyAccessActive))
+
+			status = nextReadOnlyAccessStatus(extended, status, nil, now.Add(2*time.Hour))
+			Expect(status.Phase).To(Equal(operatorv1.ReadOnlyAccessExpired))
+			status = nextReadOnlyAccessStatus(operatorv1.ReadOnlyAccessGrant{Name: "auditor"}, status, nil, now.Add(2*time.Hour))
+			Expect(status.Phase).To(Equal(operatorv1.ReadOnlyAccessExpired))
+		})
+	})
+	Context("estimateStorage", func() {
+		now := time.Now()
+		var ls *operatorv1.LogStorage
+		usage := &utils.StorageUsage{
+			CapacityBytes:       100 << 30,
+			UsedBytes:           50 << 30,
+			IngestedBytesPerDay: map[string]int64{"flows": 1 << 30, "dns": 1 << 30, "l7": 1 << 30},
+		}
+
+		BeforeEach(func() {
+			ls = &operatorv1.LogStorage{Spec: operatorv1.LogStorageSpec{StorageEstimation: &operatorv1.StorageEstimation{}}}
+			fillDefaults(ls)
+		})
+
+		It("should project the retained storage and the days until the disks are full", func() {
+			estimate := estimateStorage(ls, usage, now)
+			Expect(estimate.Time.Time).To(Equal(now))
+			Expect(estimate.Capacity.Value()).To(Equal(int64(100 << 30)))
+			Expect(estimate.Used.Value()).To(Equal(int64(50 << 30)))
+			Expect(estimate.IngestedPerDay.Value()).To(Equal(int64(3 << 30)))
+			// Flow and DNS logs are kept for 8 days, L7 logs for a day.
+			Expect(estimate.Retained.Value()).To(Equal(int64(17 << 30)))
+			// 45GiB are left until the flood stage watermark at 95%.
+			Expect(estimate.DaysUntilFull).To(Equal(ptr.Int32ToPtr(15)))
+			Expect(estimate.MonthlyCost).To(BeEmpty())
+		})
+
+		It("should estimate the monthly cost of the retained storage", func() {
+			ls.Spec.StorageEstimation.PricePerGiBMonth = "0.10"
+			Expect(estimateStorage(ls, usage, now).MonthlyCost).To(Equal("1.70"))
+		})
+
+		It("should not project the days until the disks are full without ingest", func() {
+			estimate := estimateStorage(ls, &utils.StorageUsage{CapacityBytes: 100 << 30, IngestedBytesPerDay: map[string]int64{}}, now)
+			Expect(estimate.DaysUntilFull).To(BeNil())
+		})
+
+		DescribeTable("checking whether the storage is due to be estimated",
+			func(interval *metav1.Duration, estimated *time.Time, expectDue bool, expectRemaining time.Duration) {
+				ls.Spec.StorageEstimation.Interval = interval
+				if estimated != nil {
+					ls.Status.StorageEstimate = &operatorv1.StorageEstimate{Time: metav1.NewTime(*estimated)}
+				}
+				due, remaining := storageEstimateDue(ls, now)
+				Expect(due).To(Equal(expectDue))
+				Expect(remaining).To(Equal(expectRemaining))
+			},
+			Entry("never estimated", (*metav1.Duration)(nil), (*time.Time)(nil), true, time.Hour),
+			Entry("interval elapsed", (*metav1.Duration)(nil), ptrTime(now.Add(-2*time.Hour)), true, time.Hour),
+			Entry("interval not elapsed", &metav1.Duration{Duration: 2 * time.Hour}, ptrTime(now.Add(-time.Hour)), false, time.Hour),
+		)
+	})
+	Context("elasticsearchZones", func() {
+		node := func(name string, labels map[string]string) *corev1.Node {
+			return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+		}
+
+		BeforeEach(func() {
+			Expect(cli.Create(ctx, node("node-1", map[string]string{corev1.LabelTopologyZone: "zone-b"}))).NotTo(HaveOccurred())
+			Expect(cli.Create(ctx, node("node-2", map[string]string{corev1.LabelTopologyZone: "zone-a"}))).NotTo(HaveOccurred())
+			Expect(cli.Create(ctx, node("node-3", map[string]string{corev1.LabelTopologyZone: "zone-a"}))).NotTo(HaveOccurred())
+			Expect(cli.Create(ctx, node("node-4", map[string]string{corev1.LabelTopologyZone: "zone-c", "storage": "true"}))).NotTo(HaveOccurred())
+			Expect(cli.Create(ctx, node("node-5", nil))).NotTo(HaveOccurred())
+		})
+
+		It("should return the sorted zones of the nodes", func() {
+			zones, err := elasticsearchZones(ctx, cli, &operatorv1.LogStorage{}, &operatorv1.InstallationSpec{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(zones).To(Equal([]string{"zone-a", "zone-b", "zone-c"}))
+		})
+
+		It("should only return the zones of the nodes selected by the data node selector", func() {
+			ls := &operatorv1.LogStorage{Spec: operatorv1.LogStorageSpec{DataNodeSelector: map[string]string{"storage": "true"}}}
+			zones, err := elasticsearchZones(ctx, cli, ls, &operatorv1.InstallationSpec{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(zones).To(Equal([]string{"zone-c"}))
+		})
+	})
+	Context("deleteKibanaElasticsearchUser", func() {
+		It("should delete the credentials of the Elasticsearch user of Kibana", func() {
+			r := &ReconcileLogStorage{client: cli, esCliCreator: mockEsCliCreator, status: &status.MockStatus{}}
+			Expect(cli.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: render.KibanaElasticsearchUserSecret, Namespace: common.OperatorNamespace()},
+				Data:       map[string][]byte{"username": []byte(render.KibanaElasticsearchUserName), "password": []byte("password")},
+			})).NotTo(HaveOccurred())
+
+			_, proceed, err := r.deleteKibanaElasticsearchUser(log, ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(proceed).To(BeTrue())
+			err = cli.Get(ctx, types.NamespacedName{Name: render.KibanaElasticsearchUserSecret, Namespace: common.OperatorNamespace()}, &corev1.Secret{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+
+			By("proceeding when there is no user to delete")
+			_, proceed, err = r.deleteKibanaElasticsearchUser(log, ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(proceed).To(BeTrue())
+		})
+	})
+	Context("deleteStaleSecureSettingsCopies", func() {
+		It("should only delete the copies of the secure settings secrets that are no longer referenced", func() {
+			r := &ReconcileLogStorage{client: cli, status: &status.MockStatus{}}
+			for _, name := range []string{"saml-keys", "s3-keys"} {
+				Expect(cli.Create(ctx, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: render.ElasticsearchNamespace,
+					Labels:    map[string]string{render.SecureSettingsSecretLabel: "true"},
+				}})).NotTo(HaveOccurred())
+			}
+			Expect(cli.Create(ctx, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+				Name: "other", Namespace: render.ElasticsearchNamespace,
+			}})).NotTo(HaveOccurred())
+
+			current := []*corev1.Secret{{ObjectMeta: metav1.ObjectMeta{Name: "saml-keys", Namespace: common.OperatorNamespace()}}}
+			Expect(r.deleteStaleSecureSettingsCopies(ctx, current)).NotTo(HaveOccurred())
+
+			list := &corev1.SecretList{}
+			Expect(cli.List(ctx, list, client.InNamespace(render.ElasticsearchNamespace))).NotTo(HaveOccurred())
+			var names []string
+			for _, s := range list.Items {
+				names = append(names, s.Name)
+			}
+			Expect(names).To(ConsistOf("saml-keys", "other"))
+		})
+	})
+	Context("logStorageSecretPredicate", func() {
+		It("should only pass the secrets that the LogStorage refers to", func() {
+			Expect(cli.Create(ctx, &operatorv1.LogStorage{
+				ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"},
+				Spec: operatorv1.LogStorageSpec{
+					Snapshots: &operatorv1.Snapshots{
+						Repository: operatorv1.SnapshotRepository{Type: operatorv1.SnapshotRepositoryS3, Bucket: "logs", SecretName: "s3-credentials"},
+					},
+					ImagePullSecrets: []corev1.LocalObjectReference{{Name: "es-registry"}},
+					SecureSettings:   []operatorv1.SecureSettingsSource{{SecretName: "saml-keys"}},
+				},
+			})).NotTo(HaveOccurred())
+
+			p := logStorageSecretPredicate(cli)
+			Expect(p.Generic(event.GenericEvent{Object: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "s3-credentials", Namespace: common.OperatorNamespace()},
+			}})).To(BeTrue())
+			Expect(p.Generic(event.GenericEvent{Object: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "es-registry", Namespace: common.OperatorNamespace()},
+			}})).To(BeTrue())
+			Expect(p.Generic(event.GenericEvent{Object: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "saml-keys", Namespace: common.OperatorNamespace()},
+			}})).To(BeTrue())
+			Expect(p.Generic(event.GenericEvent{Object: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "s3-credentials", Namespace: render.ElasticsearchNamespace},
+			}})).To(BeFalse())
+			Expect(p.Generic(event.GenericEvent{Object: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: common.OperatorNamespace()},
+			}})).To(BeFalse())
+		})
+	})
+
+	Context("validateRemoteClusters", func() {
+		DescribeTable("validating the remote clusters",
+			func(remoteClusters []operatorv1.RemoteElasticsearchCluster, expectErr bool) {
+				ls := &operatorv1.LogStorage{Spec: operatorv1.LogStorageSpec{RemoteClusters: remoteClusters}}
+				err := validateRemoteClusters(ls)
+				if expectErr {
+					Expect(err).To(HaveOccurred())
+				} else {
+					Expect(err).NotTo(HaveOccurred())
+				}
+			},
+			Entry("no remote clusters", ([]operatorv1.RemoteElasticsearchCluster)(nil), false),
+			Entry("valid remote clusters", []operatorv1.RemoteElasticsearchCluster{
+				{Alias: "a", Seeds: []string{"es.a.example.com:9300"}},
+				{Alias: "b", Seeds: []string{"10.0.0.1:9300", "[fd00::1]:9300"}},
+			}, false),
+			Entry("duplicate alias", []operatorv1.RemoteElasticsearchCluster{
+				{Alias: "a", Seeds: []string{"es.a.example.com:9300"}},
+				{Alias: "a", Seeds: []string{"es.b.example.com:9300"}},
+			}, true),
+			Entry("seed without port", []operatorv1.RemoteElasticsearchCluster{
+				{Alias: "a", Seeds: []string{"es.a.example.com"}},
+			}, true),
+			Entry("proxy address", []operatorv1.RemoteElasticsearchCluster{
+				{Alias: "a", ProxyAddress: "es.a.example.com:9400", ServerName: "es.a.example.com"},
+			}, false),
+			Entry("neither seeds nor proxy address", []operatorv1.RemoteElasticsearchCluster{
+				{Alias: "a"},
+			}, true),
+			Entry("seeds and proxy address", []operatorv1.RemoteElasticsearchCluster{
+				{Alias: "a", Seeds: []string{"es.a.example.com:9300"}, ProxyAddress: "es.a.example.com:9400"},
+			}, true),
+			Entry("server name without proxy address", []operatorv1.RemoteElasticsearchCluster{
+				{Alias: "a", Seeds: []string{"es.a.example.com:9300"}, ServerName: "es.a.example.com"},
+			}, true),
+			Entry("proxy address without port", []operatorv1.RemoteElasticsearchCluster{
+				{Alias: "a", ProxyAddress: "es.a.example.com"},
+			}, true),
+		)
+	})
+	Context("enterpriseLicense", func() {
+		now := time.Now()
+		licenseExpiringAt := func(t time.Time) *elasticLicense {
+			license, err := parseElasticLicense([]byte(fmt.Sprintf(`{"license":{"uid":"1","type":"enterprise","expiry_date_in_millis":%d}}`, t.UnixNano()/int64(time.Millisecond))))
+			Expect(err).NotTo(HaveOccurred())
+			return license
+		}
+
+		It("should get the license from the secret of the LogStorage", func() {
+			ls := &operatorv1.LogStorage{Spec: operatorv1.LogStorageSpec{EnterpriseLicense: &operatorv1.ElasticEnterpriseLicense{SecretName: "elastic-license"}}}
+			r := &ReconcileLogStorage{client: cli}
+			_, _, err := r.getEnterpriseLicenseSecret(ctx, ls)
+			Expect(err).To(HaveOccurred())
+
+			Expect(cli.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "elastic-license", Namespace: common.OperatorNamespace()},
+				Data:       map[string][]byte{render.ECKLicenseKey: []byte(`{"license":{"type":"enterprise","expiry_date_in_millis":1700000000000}}`)},
+			})).NotTo(HaveOccurred())
+			secret, license, err := r.getEnterpriseLicenseSecret(ctx, ls)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(secret.Name).To(Equal("elastic-license"))
+			Expect(license.License.Type).To(Equal("enterprise"))
+			Expect(license.expiry().Unix()).To(Equal(int64(1700000000)))
+		})
+
+		DescribeTable("parsing the license",
+			func(data string, expectErr bool) {
+				_, err := parseElasticLicense([]byte(data))
+				if expectErr {
+					Expect(err).To(HaveOccurred())
+				} else {
+					Expect(err).NotTo(HaveOccurred())
+				}
+			},
+			Entry("valid license", `{"license":{"type":"enterprise","expiry_date_in_millis":1700000000000}}`, false),
+			Entry("missing license", ``, true),
+			Entry("invalid json", `license`, true),
+			Entry("no expiry", `{"license":{"type":"enterprise"}}`, true),
+		)
+
+		DescribeTable("reporting the expiry of the license",
+			func(expiry time.Time, status metav1.ConditionStatus, reason string, expectRequeue time.Duration) {
+				ls := &operatorv1.LogStorage{}
+				requeue := applyEnterpriseLicense(ls, licenseExpiringAt(expiry), now)
+				Expect(ls.Status.LicenseExpiry.Unix()).To(Equal(expiry.Unix()))
+				condition := meta.FindStatusCondition(ls.Status.Conditions, operatorv1.LogStorageConditionLicenseValid)
+				Expect(condition).NotTo(BeNil())
+				Expect(condition.Status).To(Equal(status))
+				Expect(condition.Reason).To(Equal(reason))
+				Expect(requeue).To(BeNumerically("~", expectRequeue, time.Millisecond))
+			},
+			Entry("valid", now.Add(40*24*time.Hour), metav1.ConditionTrue, "Valid", 10*24*time.Hour),
+			Entry("expiring soon", now.Add(24*time.Hour), metav1.ConditionTrue, "ExpiringSoon", 24*time.Hour),
+			Entry("expired", now.Add(-time.Hour), metav1.ConditionFalse, "Expired", time.Duration(0)),
+		)
+
+		It("should clear the expiry when the LogStorage has no license", func() {
+			ls := &operatorv1.LogStorage{}
+			applyEnterpriseLicense(ls, licenseExpiringAt(now.Add(time.Hour)), now)
+			Expect(applyEnterpriseLicense(ls, nil, now)).To(Equal(time.Duration(0)))
+			Expect(ls.Status.LicenseExpiry).To(BeNil())
+			Expect(ls.Status.Conditions).To(BeEmpty())
+		})
+	})
+	Context("defaultStorageClass", func() {
+		It("should default the storage class of Elasticsearch on the infrastructure provider", func() {
+			sc := defaultStorageClass(common.InfrastructureProviderAWS, DefaultElasticsearchStorageClass)
+			Expect(sc).NotTo(BeNil())
+			Expect(sc.Name).To(Equal(DefaultElasticsearchStorageClass))
+			Expect(sc.Provisioner).To(Equal("ebs.csi.aws.com"))
+			Expect(*sc.ReclaimPolicy).To(Equal(corev1.PersistentVolumeReclaimRetain))
+			Expect(*sc.VolumeBindingMode).To(Equal(storagev1.VolumeBindingWaitForFirstConsumer))
+			Expect(sc.Labels).To(HaveKeyWithValue(defaultStorageClassLabel, "true"))
+		})
+
+		It("should only delete the default storage class that the operator created", func() {
+			r := &ReconcileLogStorage{client: cli, status: &status.MockStatus{}}
+			Expect(cli.Create(ctx, defaultStorageClass(common.InfrastructureProviderAWS, DefaultElasticsearchStorageClass))).NotTo(HaveOccurred())
+			Expect(r.deleteDefaultStorageClass(ctx)).NotTo(HaveOccurred())
+			err := cli.Get(ctx, client.ObjectKey{Name: DefaultElasticsearchStorageClass}, &storagev1.StorageClass{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+
+			Expect(cli.Create(ctx, &storagev1.StorageClass{
+				ObjectMeta:  metav1.ObjectMeta{Name: DefaultElasticsearchStorageClass},
+				Provisioner: "ebs.csi.aws.com",
+			})).NotTo(HaveOccurred())
+			Expect(r.deleteDefaultStorageClass(ctx)).NotTo(HaveOccurred())
+			Expect(cli.Get(ctx, client.ObjectKey{Name: DefaultElasticsearchStorageClass}, &storagev1.StorageClass{})).NotTo(HaveOccurred())
+		})
+
+		It("should not default a storage class without an infrastructure provider or with a custom name", func() {
+			Expect(defaultStorageClass(common.InfrastructureProviderNone, DefaultElasticsearchStorageClass)).To(BeNil())
+			Expect(defaultStorageClass(common.InfrastructureProviderAWS, "custom")).To(BeNil())
+		})
+	})
+	Context("upgradePending", func() {
+		DescribeTable("detecting a pending Elasticsearch upgrade",
+			func(ls *operatorv1.LogStorage, elasticsearch *esv1.Elasticsearch, expected bool) {
+				Expect(upgradePending(ls, elasticsearch)).To(Equal(expected))
+			},
+			Entry("no Elasticsearch cluster", &operatorv1.LogStorage{}, (*esv1.Elasticsearch)(nil), false),
+			Entry("no version", &operatorv1.LogStorage{}, &esv1.Elasticsearch{}, false),
+			Entry("same version", &operatorv1.LogStorage{},
+				&esv1.Elasticsearch{Spec: esv1.ElasticsearchSpec{Version: components.ComponentEckElasticsearch.Version}}, false),
+			Entry("different version", &operatorv1.LogStorage{},
+				&esv1.Elasticsearch{Spec: esv1.ElasticsearchSpec{Version: "7.0.0"}}, true),
+			Entry("LogStorage being deleted", &operatorv1.LogStorage{ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &metav1.Time{Time: time.Now()}}},
+				&esv1.Elasticsearch{Spec: esv1.ElasticsearchSpec{Version: "7.0.0"}}, false),
+			Entry("different ECK operator version", &operatorv1.LogStorage{},
+				&esv1.Elasticsearch{
+					ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"common.k8s.elastic.co/controller-version": "1.0.0"}},
+					Spec:       esv1.ElasticsearchSpec{Version: components.ComponentEckElasticsearch.Version},
+				}, true),
+			Entry("same ECK operator version", &operatorv1.LogStorage{},
+				&esv1.Elasticsearch{
+					ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"common.k8s.elastic.co/controller-version": components.ComponentECKElasticsearchOperator.Version}},
+					Spec:       esv1.ElasticsearchSpec{Version: components.ComponentEckElasticsearch.Version},
+				}, false),
+		)
+	})
+	Context("indexTemplates", func() {
+		var cm *corev1.ConfigMap
+
+		BeforeEach(func() {
+			cm = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "index-templates", Namespace: common.OperatorNamespace()},
+				Data: map[string]string{
+					"flows": `{"settings": {"number_of_shards": 3, "analysis": {"analyzer": {"lower": {"tokenizer": "keyword"}}}}}`,
+				},
+			}
+		})
+
+		It("should set the patterns and the order of the index templates", func() {
+			templates, err := indexTemplates(cm)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(templates).To(HaveLen(1))
+			Expect(templates).To(HaveKey("tigera_secure_ee_flows_overrides"))
+			Expect(templates["tigera_secure_ee_flows_overrides"]).To(MatchJSON(`{
+				"index_patterns": ["tigera_secure_ee_flows.*"],
+				"order": 100,
+				"settings": {"number_of_shards": 3, "analysis": {"analyzer": {"lower": {"tokenizer": "keyword"}}}}
+			}`))
+		})
+
+		It("should reject a key that is not a log type", func() {
+			cm.Data["flow"] = `{}`
+			_, err := indexTemplates(cm)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should reject a template that is not a JSON object", func() {
+			cm.Data["dns"] = `[1, 2]`
+			_, err := indexTemplates(cm)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should reject a template that sets its patterns", func() {
+			cm.Data["dns"] = `{"index_patterns": ["*"]}`
+			_, err := indexTemplates(cm)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+	Context("eckOperatorRolledOut", func() {
+		var current, desired *appsv1.StatefulSet
+
+		BeforeEach(func() {
+			desired = &appsv1.StatefulSet{Spec: appsv1.StatefulSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "manager", Image: "eck-operator:new"}},
+			}}}}
+			current = desired.DeepCopy()
+			current.Generation = 2
+			current.Spec.Replicas = ptr.Int32ToPtr(1)
+			current.Status = appsv1.StatefulSetStatus{
+				ObservedGeneration: 2,
+				UpdatedReplicas:    1,
+				ReadyReplicas:      1,
+				CurrentRevision:    "rev-2",
+				UpdateRevision:     "rev-2",
+			}
+		})
+
+		It("should be rolled out once the replicas run the latest revision", func() {
+			Expect(eckOperatorRolledOut(current, desired)).To(BeTrue())
+			Expect(eckOperatorRolledOut(current, nil)).To(BeTrue())
+		})
+
+		It("should not be rolled out while the new revision rolls out", func() {
+			current.Status.CurrentRevision = "rev-1"
+			Expect(eckOperatorRolledOut(current, desired)).To(BeFalse())
+		})
+
+		It("should not be rolled out before the new generation is observed", func() {
+			current.Status.ObservedGeneration = 1
+			Expect(eckOperatorRolledOut(current, desired)).To(BeFalse())
+		})
+
+		It("should not be rolled out while the replica isn't ready", func() {
+			current.Status.ReadyReplicas = 0
+			Expect(eckOperatorRolledOut(current, desired)).To(BeFalse())
+		})
+
+		It("should not be rolled out before the new image is applied", func() {
+			current.Spec.Template.Spec.Containers[0].Image = "eck-operator:old"
+			Expect(eckOperatorRolledOut(current, desired)).To(BeFalse())
+		})
+	})
+	Context("checkECKOperatorUpgrade", func() {
+		It("should pause the ECK reconciliation of Elasticsearch and Kibana until the new ECK operator is rolled out", func() {
+			r := &ReconcileLogStorage{client: cli, esCliCreator: mockEsCliCreator, status: &status.MockStatus{}}
+			ls := &operatorv1.LogStorage{}
+			Expect(cli.Create(ctx, &esv1.Elasticsearch{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        render.ElasticsearchName,
+					Namespace:   render.ElasticsearchNamespace,
+					Annotations: map[string]string{"common.k8s.elastic.co/controller-version": "1.0.0"},
+				},
+				Spec: esv1.ElasticsearchSpec{Version: components.ComponentEckElasticsearch.Version},
+			})).NotTo(HaveOccurred())
+			Expect(cli.Create(ctx, &kbv1.Kibana{
+				ObjectMeta: metav1.ObjectMeta{Name: render.KibanaName, Namespace: render.KibanaNamespace},
+			})).NotTo(HaveOccurred())
+			elasticsearch, err := r.getElasticsearch(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			kibana, err := r.getKibana(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, msg, err := r.checkECKOperatorUpgrade(ls, elasticsearch, kibana, nil, log, ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(msg).To(ContainSubstring("Waiting for the ECK operator to be upgraded"))
+			elasticsearch, err = r.getElasticsearch(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(elasticsearch.Annotations).To(HaveKeyWithValue(eckManagedAnnotation, "false"))
+			kibana, err = r.getKibana(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(kibana.Annotations).To(HaveKeyWithValue(eckManagedAnnotation, "false"))
+
+			By("managing them again once the ECK operator is rolled out and the cluster is green")
+			Expect(cli.Create(ctx, &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: render.ECKOperatorName, Namespace: render.ECKOperatorNamespace},
+				Status:     appsv1.StatefulSetStatus{UpdatedReplicas: 1, ReadyReplicas: 1},
+			})).NotTo(HaveOccurred())
+			_, msg, err = r.checkECKOperatorUpgrade(ls, elasticsearch, kibana, nil, log, ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(msg).To(BeEmpty())
+			elasticsearch, err = r.getElasticsearch(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(elasticsearch.Annotations).NotTo(HaveKey(eckManagedAnnotation))
+			kibana, err = r.getKibana(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(kibana.Annotations).NotTo(HaveKey(eckManagedAnnotation))
+		})
+	})
+	Context("upgradeHeldBack", func() {
+		DescribeTable("holding back the objects covered by the pre-flight checks",
+			func(obj client.Object, kibana *kbv1.Kibana, expected bool) {
+				Expect(upgradeHeldBack(obj, kibana)).To(Equal(expected))
+			},
+			Entry("Elasticsearch", &esv1.Elasticsearch{}, (*kbv1.Kibana)(nil), true),
+			Entry("new Kibana", &kbv1.Kibana{Spec: kbv1.KibanaSpec{Version: "7.17.0"}}, (*kbv1.Kibana)(nil), false),
+			Entry("Kibana of the same version", &kbv1.Kibana{Spec: kbv1.KibanaSpec{Version: "7.17.0"}},
+				&kbv1.Kibana{Spec: kbv1.KibanaSpec{Version: "7.17.0"}}, false),
+			Entry("Kibana upgrade", &kbv1.Kibana{Spec: kbv1.KibanaSpec{Version: "7.17.0"}},
+				&kbv1.Kibana{Spec: kbv1.KibanaSpec{Version: "7.16.0"}}, true),
+			Entry("other objects", &corev1.Secret{}, (*kbv1.Kibana)(nil), false),
+		)
+	})
 	Context("LogStorageSpec, fillDefaults", func() {
 		ls := operatorv1.LogStorage{Spec: operatorv1.LogStorageSpec{}}
 		fillDefaults(&ls)
@@ -1544,6 +2604,7 @@ var _ = Describe("LogStorage controller", func() {
 			Indices: &operatorv1.Indices{
 				Replicas: &replicas,
 			},
+			ClusterName:      render.DefaultElasticsearchClusterName,
 			StorageClassName: DefaultElasticsearchStorageClass,
 			ComponentResources: []operatorv1.LogStorageComponentResource{
 				{
@@ -1709,3 +2770,71 @@ func createKibanaSecrets() []client.Object {
 func (*mockESClient) SetILMPolicies(ctx context.Context, ls *operatorv1.LogStorage) error {
 	return nil
 }
+
+func (*mockESClient) SetTenantRoles(ctx context.Context, ls *operatorv1.LogStorage) error {
+	return nil
+}
+
+func (*mockESClient) SetKibanaSpaceRoles(ctx context.Context, ls *operatorv1.LogStorage) error {
+	return nil
+}
+
+func (*mockESClient) SetRole(ctx context.Context, name string, role map[string]interface{}) error {
+	return nil
+}
+
+func (*mockESClient) SetTenantUsers(ctx context.Context, ls *operatorv1.LogStorage, passwords map[string]string) error {
+	return nil
+}
+
+func (*mockESClient) SetUser(ctx context.Context, username, password string, roles []string) error {
+	return nil
+}
+
+func (*mockESClient) DeleteUser(ctx context.Context, username string) error {
+	return nil
+}
+
+func (*mockESClient) SetReadOnlyAccessUsers(ctx context.Context, passwords map[string]string) error {
+	return nil
+}
+
+func (*mockESClient) SetIngestLatencyPipeline(ctx context.Context, enabled bool) error {
+	return nil
+}
+
+func (*mockESClient) IngestLatency(ctx context.Context, indexPattern string, window time.Duration, bounds []float64) (*utils.IngestLatency, error) {
+	return &utils.IngestLatency{Count: 1, Sum: 2 * time.Minute, Buckets: map[float64]uint64{}, P99: 2 * time.Minute}, nil
+}
+
+func (*mockESClient) ClusterHealth(ctx context.Context) (*utils.ClusterHealth, error) {
+	return &utils.ClusterHealth{Status: "green"}, nil
+}
+
+func (*mockESClient) SetSnapshotPolicy(ctx context.Context, ls *operatorv1.LogStorage) error {
+	return nil
+}
+
+func (*mockESClient) SetDiskWatermarks(ctx context.Context, ls *operatorv1.LogStorage) error {
+	return nil
+}
+
+func (*mockESClient) LastFlowLogTimes(ctx context.Context) (map[string]time.Time, error) {
+	return map[string]time.Time{}, nil
+}
+
+func (*mockESClient) StorageUsage(ctx context.Context) (*utils.StorageUsage, error) {
+	return &utils.StorageUsage{IngestedBytesPerDay: map[string]int64{}}, nil
+}
+
+func (*mockESClient) NodeDiskUsage(ctx context.Context) (map[string]int, error) {
+	return map[string]int{}, nil
+}
+
+func (*mockESClient) DeleteIndex(ctx context.Context, index string) error {
+	return nil
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
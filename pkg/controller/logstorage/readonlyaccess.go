@@ -0,0 +1,176 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/controller/utils"
+	"github.com/tigera/operator/pkg/crypto"
+	"github.com/tigera/operator/pkg/render"
+	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
+	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+)
+
+// readOnlyAccessTTL returns how long the credentials of the grant are valid after they are created.
+func readOnlyAccessTTL(grant operatorv1.ReadOnlyAccessGrant) time.Duration {
+	if grant.TTL != nil && grant.TTL.Duration > 0 {
+		return grant.TTL.Duration
+	}
+	return render.DefaultReadOnlyAccessTTL
+}
+
+// nextReadOnlyAccessStatus returns the state of the grant at the given time, given its current state and the secret
+// with its credentials, which is nil if it doesn't exist. The creation time of the secret is used when the state of
+// the grant was lost, so that a failed update of the status doesn't extend the grant.
+func nextReadOnlyAccessStatus(grant operatorv1.ReadOnlyAccessGrant, current operatorv1.ReadOnlyAccessStatus, userSecret *corev1.Secret, now time.Time) operatorv1.ReadOnlyAccessStatus {
+	next := current
+	next.Name = grant.Name
+	next.SecretName = render.ElasticsearchReadOnlyAccessUserSecretName(grant.Name)
+	if current.Phase == operatorv1.ReadOnlyAccessExpired {
+		return next
+	}
+
+	created := current.CreationTime
+	if created == nil {
+		creationTime := metav1.NewTime(now)
+		if userSecret != nil && !userSecret.CreationTimestamp.IsZero() {
+			creationTime = userSecret.CreationTimestamp
+		}
+		created = &creationTime
+	}
+	// The expiration follows changes of the TTL of an active grant.
+	expiration := metav1.NewTime(created.Add(readOnlyAccessTTL(grant)))
+	next.CreationTime = created
+	next.ExpirationTime = &expiration
+	next.Phase = operatorv1.ReadOnlyAccessActive
+	if !now.Before(expiration.Time) {
+		next.Phase = operatorv1.ReadOnlyAccessExpired
+	}
+	return next
+}
+
+// readOnlyAccessUserSecret returns the secret with the credentials of the Elasticsearch user of the grant, generating
+// them if the secret doesn't exist yet.
+func readOnlyAccessUserSecret(grant string, userSecret *corev1.Secret, expiration *metav1.Time) *corev1.Secret {
+	if userSecret == nil {
+		userSecret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      render.ElasticsearchReadOnlyAccessUserSecretName(grant),
+				Namespace: common.OperatorNamespace(),
+			},
+			Data: map[string][]byte{
+				"username": []byte(render.ElasticsearchReadOnlyAccessUserName(grant)),
+				"password": []byte(crypto.GeneratePassword(16)),
+			},
+		}
+	}
+	userSecret.Labels = map[string]string{render.LogStorageReadOnlyAccessLabel: grant}
+	userSecret.Annotations = map[string]string{render.ReadOnlyAccessExpirationAnnotation: expiration.UTC().Format(time.RFC3339)}
+	return userSecret
+}
+
+// applyReadOnlyAccess creates the Elasticsearch users of the active grants of read-only access of the LogStorage and
+// the secrets with their credentials, and tracks the states of the grants in the status of the LogStorage, which is
+// updated at the end of the reconciliation. The users and the secrets of the grants are deleted once the grants expire
+// or are removed from the LogStorage. The returned result requeues the request for the next expiration.
+//
+// It returns a reconcile.Result, a 'proceed' bool indicating if the Reconcile function should continue, and an error.
+func (r *ReconcileLogStorage) applyReadOnlyAccess(ls *operatorv1.LogStorage, hdler utils.ComponentHandler, reqLogger logr.Logger, ctx context.Context) (reconcile.Result, bool, error) {
+	current := map[string]operatorv1.ReadOnlyAccessStatus{}
+	for _, status := range ls.Status.ReadOnlyAccess {
+		current[status.Name] = status
+	}
+
+	now := time.Now()
+	var requeueAfter time.Duration
+	var statuses []operatorv1.ReadOnlyAccessStatus
+	var userSecrets []*corev1.Secret
+	passwords := map[string]string{}
+	for _, grant := range ls.Spec.ReadOnlyAccess {
+		userSecret, err := utils.GetSecret(ctx, r.client, render.ElasticsearchReadOnlyAccessUserSecretName(grant.Name), common.OperatorNamespace())
+		if err != nil {
+			reqLogger.Error(err, "Failed to get the Elasticsearch user secret of the read-only access", "name", grant.Name)
+			r.status.SetDegraded("Failed to get the Elasticsearch user secret of a read-only access", err.Error())
+			return reconcile.Result{}, false, err
+		}
+
+		next := nextReadOnlyAccessStatus(grant, current[grant.Name], userSecret, now)
+		statuses = append(statuses, next)
+		if next.Phase == operatorv1.ReadOnlyAccessExpired {
+			if current[grant.Name].Phase != operatorv1.ReadOnlyAccessExpired {
+				reqLogger.Info("Revoking the expired read-only access", "name", grant.Name)
+			}
+			continue
+		}
+
+		userSecret = readOnlyAccessUserSecret(grant.Name, userSecret, next.ExpirationTime)
+		userSecrets = append(userSecrets, userSecret)
+		passwords[grant.Name] = string(userSecret.Data["password"])
+		requeueAfter = minRequeueAfter(requeueAfter, next.ExpirationTime.Sub(now))
+	}
+
+	secretList := &corev1.SecretList{}
+	if err := r.client.List(ctx, secretList, client.InNamespace(common.OperatorNamespace()), client.HasLabels{render.LogStorageReadOnlyAccessLabel}); err != nil {
+		reqLogger.Error(err, "Failed to list the Elasticsearch user secrets of the read-only access")
+		r.status.SetDegraded("Failed to list the Elasticsearch user secrets of the read-only access", err.Error())
+		return reconcile.Result{}, false, err
+	}
+	var removed []*corev1.Secret
+	for i := range secretList.Items {
+		s := &secretList.Items[i]
+		if _, ok := passwords[s.Labels[render.LogStorageReadOnlyAccessLabel]]; !ok {
+			removed = append(removed, s)
+		}
+	}
+
+	// The users are revoked before their secrets are deleted, so that a failure in between revokes them again on the
+	// next reconcile.
+	if len(passwords) > 0 || len(removed) > 0 {
+		esClient, err := r.esCliCreator(r.client, ctx, relasticsearch.HTTPSEndpoint(rmeta.OSTypeLinux, r.clusterDomain))
+		if err != nil {
+			reqLogger.Error(err, "failed to create the Elasticsearch client")
+			r.status.SetDegraded("Failed to connect to Elasticsearch", err.Error())
+			return reconcile.Result{}, false, err
+		}
+		if err = esClient.SetReadOnlyAccessUsers(ctx, passwords); err != nil {
+			reqLogger.Error(err, "failed to create or update the Elasticsearch users of the read-only access")
+			r.status.SetDegraded("Failed to create or update the Elasticsearch users of the read-only access", err.Error())
+			return reconcile.Result{}, false, err
+		}
+	}
+
+	readOnlyAccessComponent := render.LogStorageReadOnlyAccess(&render.LogStorageReadOnlyAccessConfiguration{
+		UserSecrets:        userSecrets,
+		RemovedUserSecrets: removed,
+	})
+	if err := hdler.CreateOrUpdateOrDelete(ctx, readOnlyAccessComponent, r.status); err != nil {
+		reqLogger.Error(err, "Error creating / updating resource")
+		r.status.SetDegraded("Error creating / updating resource", err.Error())
+		return reconcile.Result{}, false, err
+	}
+
+	ls.Status.ReadOnlyAccess = statuses
+	return reconcile.Result{RequeueAfter: requeueAfter}, true, nil
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	corev1 "k8s.io/api/core/v1"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/controller/utils"
+)
+
+// validateRemoteClusters returns an error if the remote clusters of the LogStorage have duplicate aliases, don't have
+// exactly one of seeds and a proxy address, or have addresses that are not host:port addresses.
+func validateRemoteClusters(ls *operatorv1.LogStorage) error {
+	aliases := map[string]bool{}
+	for _, rc := range ls.Spec.RemoteClusters {
+		if aliases[rc.Alias] {
+			return fmt.Errorf("remote cluster alias %s is used more than once", rc.Alias)
+		}
+		aliases[rc.Alias] = true
+
+		if (len(rc.Seeds) == 0) == (rc.ProxyAddress == "") {
+			return fmt.Errorf("remote cluster %s must have either seeds or a proxy address", rc.Alias)
+		}
+		if rc.ServerName != "" && rc.ProxyAddress == "" {
+			return fmt.Errorf("remote cluster %s has a server name without a proxy address", rc.Alias)
+		}
+		for _, seed := range rc.Seeds {
+			if _, _, err := net.SplitHostPort(seed); err != nil {
+				return fmt.Errorf("seed %q of remote cluster %s is invalid: %w", seed, rc.Alias, err)
+			}
+		}
+		if rc.ProxyAddress != "" {
+			if _, _, err := net.SplitHostPort(rc.ProxyAddress); err != nil {
+				return fmt.Errorf("proxy address %q of remote cluster %s is invalid: %w", rc.ProxyAddress, rc.Alias, err)
+			}
+		}
+	}
+	return nil
+}
+
+// getRemoteClusterCASecrets returns the secrets in the operator namespace that hold the CA certificates of the remote
+// clusters of the LogStorage.
+func (r *ReconcileLogStorage) getRemoteClusterCASecrets(ctx context.Context, ls *operatorv1.LogStorage) ([]*corev1.Secret, error) {
+	var secrets []*corev1.Secret
+	seen := map[string]bool{}
+	for _, rc := range ls.Spec.RemoteClusters {
+		name := rc.CertificateAuthoritySecretName
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		s, err := utils.GetSecret(ctx, r.client, name, common.OperatorNamespace())
+		if err != nil {
+			return nil, err
+		}
+		if s == nil {
+			return nil, fmt.Errorf("secret %s/%s for remote cluster %s not found", common.OperatorNamespace(), name, rc.Alias)
+		}
+		if len(s.Data[corev1.TLSCertKey]) == 0 {
+			return nil, fmt.Errorf("secret %s/%s for remote cluster %s does not have a %s key", common.OperatorNamespace(), name, rc.Alias, corev1.TLSCertKey)
+		}
+		secrets = append(secrets, s)
+	}
+	return secrets, nil
+}
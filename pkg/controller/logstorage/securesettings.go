@@ -0,0 +1,77 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/controller/utils"
+	"github.com/tigera/operator/pkg/render"
+)
+
+// getSecureSettingsSecrets returns the secrets in the operator namespace whose keys are added to the keystore of
+// Elasticsearch. It returns an error if a secret is missing, or doesn't have a key that is mapped to a setting.
+func (r *ReconcileLogStorage) getSecureSettingsSecrets(ctx context.Context, ls *operatorv1.LogStorage) ([]*corev1.Secret, error) {
+	var secrets []*corev1.Secret
+	seen := map[string]bool{}
+	for _, source := range ls.Spec.SecureSettings {
+		s, err := utils.GetSecret(ctx, r.client, source.SecretName, common.OperatorNamespace())
+		if err != nil {
+			return nil, err
+		}
+		if s == nil {
+			return nil, fmt.Errorf("secret %s/%s for the secure settings not found", common.OperatorNamespace(), source.SecretName)
+		}
+		for _, entry := range source.Entries {
+			if _, ok := s.Data[entry.Key]; !ok {
+				return nil, fmt.Errorf("secret %s/%s for the secure settings does not have a %s key", common.OperatorNamespace(), source.SecretName, entry.Key)
+			}
+		}
+		if !seen[s.Name] {
+			seen[s.Name] = true
+			secrets = append(secrets, s)
+		}
+	}
+	return secrets, nil
+}
+
+// deleteStaleSecureSettingsCopies deletes the copies of the secure settings secrets in the Elasticsearch namespace
+// that the LogStorage no longer refers to. The copies are rendered by name, so they would otherwise stay behind.
+func (r *ReconcileLogStorage) deleteStaleSecureSettingsCopies(ctx context.Context, secrets []*corev1.Secret) error {
+	current := map[string]bool{}
+	for _, s := range secrets {
+		current[s.Name] = true
+	}
+	list := &corev1.SecretList{}
+	if err := r.client.List(ctx, list, client.InNamespace(render.ElasticsearchNamespace), client.MatchingLabels{render.SecureSettingsSecretLabel: "true"}); err != nil {
+		return err
+	}
+	for i := range list.Items {
+		if current[list.Items[i].Name] {
+			continue
+		}
+		if err := r.client.Delete(ctx, &list.Items[i]); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/controller/utils"
+	"github.com/tigera/operator/pkg/render"
+	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
+	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+)
+
+// logStorageSecretPredicate filters the secrets of the operator namespace down to the ones that the LogStorage refers
+// to.
+func logStorageSecretPredicate(cli client.Client) predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		if obj.GetNamespace() != common.OperatorNamespace() {
+			return false
+		}
+		ls, err := utils.GetLogStorage(context.Background(), cli)
+		if err != nil || ls == nil {
+			return false
+		}
+		return logStorageSecretNames(ls)[obj.GetName()]
+	})
+}
+
+// logStorageSecretNames returns the names of the secrets in the operator namespace that the LogStorage refers to.
+func logStorageSecretNames(ls *operatorv1.LogStorage) map[string]bool {
+	names := map[string]bool{}
+	for _, ref := range ls.Spec.ImagePullSecrets {
+		names[ref.Name] = true
+	}
+	if ls.Spec.Snapshots != nil {
+		names[ls.Spec.Snapshots.Repository.SecretName] = true
+	}
+	for _, source := range ls.Spec.SecureSettings {
+		names[source.SecretName] = true
+	}
+	return names
+}
+
+// getSnapshotRepositorySecret returns the secret in the operator namespace that holds the credentials of the snapshot
+// repository of the LogStorage, or nil if the LogStorage doesn't take snapshots.
+func (r *ReconcileLogStorage) getSnapshotRepositorySecret(ctx context.Context, ls *operatorv1.LogStorage) (*corev1.Secret, error) {
+	if ls.Spec.Snapshots == nil {
+		return nil, nil
+	}
+	repo := ls.Spec.Snapshots.Repository
+	s, err := utils.GetSecret(ctx, r.client, repo.SecretName, common.OperatorNamespace())
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return nil, fmt.Errorf("secret %s/%s for the snapshot repository not found", common.OperatorNamespace(), repo.SecretName)
+	}
+	for _, key := range render.SnapshotRepositoryCredentialKeys[repo.Type] {
+		if len(s.Data[key]) == 0 {
+			return nil, fmt.Errorf("secret %s/%s for the %s snapshot repository does not have a %s key", common.OperatorNamespace(), repo.SecretName, repo.Type, key)
+		}
+	}
+	return s, nil
+}
+
+// applySnapshotPolicy registers the snapshot repository and creates the snapshot lifecycle management policy of the
+// LogStorage, or deletes them if the LogStorage doesn't take snapshots.
+func (r *ReconcileLogStorage) applySnapshotPolicy(ls *operatorv1.LogStorage, reqLogger logr.Logger, ctx context.Context) (reconcile.Result, bool, error) {
+	esClient, err := r.esCliCreator(r.client, ctx, relasticsearch.HTTPSEndpoint(rmeta.OSTypeLinux, r.clusterDomain))
+	if err != nil {
+		reqLogger.Error(err, "failed to create the Elasticsearch client")
+		r.status.SetDegraded("Failed to connect to Elasticsearch", err.Error())
+		return reconcile.Result{}, false, err
+	}
+
+	if err = esClient.SetSnapshotPolicy(ctx, ls); err != nil {
+		reqLogger.Error(err, "failed to create or update the Elasticsearch snapshot policy")
+		r.status.SetDegraded("Failed to create or update the Elasticsearch snapshot policy", err.Error())
+		return reconcile.Result{}, false, err
+	}
+	return reconcile.Result{}, true, nil
+}
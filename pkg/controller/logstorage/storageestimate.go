@@ -0,0 +1,128 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/controller/utils"
+	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
+	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+)
+
+// defaultStorageEstimateInterval is how often the storage consumption of the logs is estimated by default.
+const defaultStorageEstimateInterval = time.Hour
+
+// storageEstimateDue returns whether the storage consumption of the logs is due to be estimated, and how long it is
+// until the next estimate is due after that.
+func storageEstimateDue(ls *operatorv1.LogStorage, now time.Time) (bool, time.Duration) {
+	interval := defaultStorageEstimateInterval
+	if ls.Spec.StorageEstimation.Interval != nil && ls.Spec.StorageEstimation.Interval.Duration > 0 {
+		interval = ls.Spec.StorageEstimation.Interval.Duration
+	}
+	if ls.Status.StorageEstimate == nil {
+		return true, interval
+	}
+	next := ls.Status.StorageEstimate.Time.Add(interval)
+	if !now.Before(next) {
+		return true, interval
+	}
+	return false, next.Sub(now)
+}
+
+// retentionDays returns the number of days that the logs of a log type of the storage usage are kept.
+func retentionDays(ls *operatorv1.LogStorage, logType string) int64 {
+	switch logType {
+	case "flows":
+		return int64(*ls.Spec.Retention.Flows)
+	case "dns":
+		return int64(*ls.Spec.Retention.DNSLogs)
+	case "audit":
+		return int64(*ls.Spec.Retention.AuditReports)
+	}
+	// The retention of the L7 logs isn't configurable, their ILM policy deletes them after a day.
+	return 1
+}
+
+// estimateStorage estimates the storage consumption of the logs from the storage usage of Elasticsearch. The disks are
+// projected to fill up at the daily ingest, regardless of the logs that are deleted once they are past their
+// retention.
+func estimateStorage(ls *operatorv1.LogStorage, usage *utils.StorageUsage, now time.Time) *operatorv1.StorageEstimate {
+	var ingested, retained int64
+	for logType, bytes := range usage.IngestedBytesPerDay {
+		ingested += bytes
+		retained += bytes * retentionDays(ls, logType)
+	}
+
+	estimate := &operatorv1.StorageEstimate{
+		Time:           metav1.NewTime(now),
+		Capacity:       *resource.NewQuantity(usage.CapacityBytes, resource.BinarySI),
+		Used:           *resource.NewQuantity(usage.UsedBytes, resource.BinarySI),
+		IngestedPerDay: *resource.NewQuantity(ingested, resource.BinarySI),
+		Retained:       *resource.NewQuantity(retained, resource.BinarySI),
+	}
+	if ingested > 0 {
+		_, _, floodStage := diskWatermarks(&ls.Spec)
+		free := float64(usage.CapacityBytes)*float64(floodStage)/100 - float64(usage.UsedBytes)
+		days := int32(math.Min(math.Max(free/float64(ingested), 0), math.MaxInt32))
+		estimate.DaysUntilFull = &days
+	}
+	if ls.Spec.StorageEstimation.PricePerGiBMonth != "" {
+		// The price is validated by the pattern of the CRD.
+		if price, err := strconv.ParseFloat(ls.Spec.StorageEstimation.PricePerGiBMonth, 64); err == nil {
+			estimate.MonthlyCost = strconv.FormatFloat(float64(retained)/(1<<30)*price, 'f', 2, 64)
+		}
+	}
+	return estimate
+}
+
+// applyStorageEstimate periodically estimates the storage consumption of the logs when it is enabled in LogStorage.
+// The estimate is set in the status of the LogStorage, which is updated at the end of the reconciliation. The returned
+// result requeues the request for the next estimate.
+func (r *ReconcileLogStorage) applyStorageEstimate(ls *operatorv1.LogStorage, reqLogger logr.Logger, ctx context.Context) (reconcile.Result, bool, error) {
+	if ls.Spec.StorageEstimation == nil {
+		ls.Status.StorageEstimate = nil
+		return reconcile.Result{}, true, nil
+	}
+	now := time.Now()
+	due, untilDue := storageEstimateDue(ls, now)
+	if !due {
+		return reconcile.Result{RequeueAfter: untilDue}, true, nil
+	}
+
+	esClient, err := r.esCliCreator(r.client, ctx, relasticsearch.HTTPSEndpoint(rmeta.OSTypeLinux, r.clusterDomain))
+	if err != nil {
+		reqLogger.Error(err, "failed to create the Elasticsearch client")
+		r.status.SetDegraded("Failed to connect to Elasticsearch", err.Error())
+		return reconcile.Result{}, false, err
+	}
+	usage, err := esClient.StorageUsage(ctx)
+	if err != nil {
+		reqLogger.Error(err, "failed to get the storage usage of Elasticsearch")
+		r.status.SetDegraded("Failed to estimate the storage consumption of the logs", err.Error())
+		return reconcile.Result{}, false, err
+	}
+	ls.Status.StorageEstimate = estimateStorage(ls, usage, now)
+	return reconcile.Result{RequeueAfter: untilDue}, true, nil
+}
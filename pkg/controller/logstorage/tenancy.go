@@ -0,0 +1,157 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/controller/utils"
+	"github.com/tigera/operator/pkg/crypto"
+	"github.com/tigera/operator/pkg/render"
+	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
+	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+)
+
+// validateTenancy returns an error if a tenant of the LogStorage has the name of a tenant role or of a Kibana space of
+// the LogStorage, or if the log indices of one of its managed clusters can't be told apart from those of other
+// clusters.
+func validateTenancy(ls *operatorv1.LogStorage) error {
+	tenantRoles := map[string]bool{}
+	for _, role := range ls.Spec.TenantRoles {
+		tenantRoles[role.Name] = true
+	}
+	kibanaSpaces := map[string]bool{}
+	for _, space := range ls.Spec.KibanaSpaces {
+		kibanaSpaces[space.ID] = true
+	}
+	for _, tenant := range ls.Spec.Tenants() {
+		if tenantRoles[tenant.Name] {
+			return fmt.Errorf("tenant %q has the name of a tenant role", tenant.Name)
+		}
+		if kibanaSpaces[tenant.Name] && len(tenant.Groups) != 0 {
+			return fmt.Errorf("tenant %q has the ID of a Kibana space", tenant.Name)
+		}
+		for _, cluster := range tenant.Clusters {
+			if cluster == "" || strings.ContainsAny(cluster, ".*,") {
+				return fmt.Errorf("cluster %q of tenant %q is not a valid cluster name", cluster, tenant.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// getTenantUserSecret returns the credentials of the Elasticsearch user of the tenant, generating them if they don't
+// exist yet.
+func (r *ReconcileLogStorage) getTenantUserSecret(ctx context.Context, tenant string) (*corev1.Secret, error) {
+	userSecret, err := utils.GetSecret(ctx, r.client, render.ElasticsearchTenantUserSecretName(tenant), common.OperatorNamespace())
+	if err != nil {
+		return nil, err
+	}
+	if userSecret == nil {
+		userSecret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      render.ElasticsearchTenantUserSecretName(tenant),
+				Namespace: common.OperatorNamespace(),
+			},
+			Data: map[string][]byte{
+				"username": []byte(render.ElasticsearchTenantUserName(tenant)),
+				"password": []byte(crypto.GeneratePassword(16)),
+			},
+		}
+	}
+	userSecret.Labels = map[string]string{render.LogStorageTenantLabel: tenant}
+	return userSecret, nil
+}
+
+// applyTenants creates or updates the Elasticsearch users of the tenants of the LogStorage and the secrets with their
+// credentials, and deletes the users and the secrets of the tenants that were removed from it. The roles of the tenants
+// are created with the tenant roles. Nothing is done when the LogStorage has no tenancy.
+//
+// It returns a reconcile.Result, a 'proceed' bool indicating if the Reconcile function should continue, and an error.
+func (r *ReconcileLogStorage) applyTenants(ls *operatorv1.LogStorage, hdler utils.ComponentHandler, reqLogger logr.Logger, ctx context.Context) (reconcile.Result, bool, error) {
+	if ls.Spec.Tenancy == nil {
+		return reconcile.Result{}, true, nil
+	}
+	if err := validateTenancy(ls); err != nil {
+		r.status.SetDegraded("Invalid tenancy", err.Error())
+		return reconcile.Result{}, false, nil
+	}
+
+	desired := map[string]bool{}
+	var userSecrets []*corev1.Secret
+	passwords := map[string]string{}
+	for _, tenant := range ls.Spec.Tenants() {
+		userSecret, err := r.getTenantUserSecret(ctx, tenant.Name)
+		if err != nil {
+			reqLogger.Error(err, "Failed to get the Elasticsearch user secret of the tenant", "tenant", tenant.Name)
+			r.status.SetDegraded("Failed to get the Elasticsearch user secret of a tenant", err.Error())
+			return reconcile.Result{}, false, err
+		}
+		userSecrets = append(userSecrets, userSecret)
+		passwords[tenant.Name] = string(userSecret.Data["password"])
+		desired[fmt.Sprintf("%s/%s", userSecret.Namespace, userSecret.Name)] = true
+		if tenant.UserSecretNamespace != "" {
+			desired[fmt.Sprintf("%s/%s", tenant.UserSecretNamespace, userSecret.Name)] = true
+		}
+	}
+
+	secretList := &corev1.SecretList{}
+	if err := r.client.List(ctx, secretList, client.HasLabels{render.LogStorageTenantLabel}); err != nil {
+		reqLogger.Error(err, "Failed to list the Elasticsearch user secrets of the tenants")
+		r.status.SetDegraded("Failed to list the Elasticsearch user secrets of the tenants", err.Error())
+		return reconcile.Result{}, false, err
+	}
+	var removed []*corev1.Secret
+	for i := range secretList.Items {
+		s := &secretList.Items[i]
+		if !desired[fmt.Sprintf("%s/%s", s.Namespace, s.Name)] {
+			removed = append(removed, s)
+		}
+	}
+
+	tenantsComponent := render.LogStorageTenants(&render.LogStorageTenantsConfiguration{
+		LogStorage:         ls,
+		UserSecrets:        userSecrets,
+		RemovedUserSecrets: removed,
+	})
+	if err := hdler.CreateOrUpdateOrDelete(ctx, tenantsComponent, r.status); err != nil {
+		reqLogger.Error(err, "Error creating / updating resource")
+		r.status.SetDegraded("Error creating / updating resource", err.Error())
+		return reconcile.Result{}, false, err
+	}
+
+	esClient, err := r.esCliCreator(r.client, ctx, relasticsearch.HTTPSEndpoint(rmeta.OSTypeLinux, r.clusterDomain))
+	if err != nil {
+		reqLogger.Error(err, "failed to create the Elasticsearch client")
+		r.status.SetDegraded("Failed to connect to Elasticsearch", err.Error())
+		return reconcile.Result{}, false, err
+	}
+	if err = esClient.SetTenantUsers(ctx, ls, passwords); err != nil {
+		reqLogger.Error(err, "failed to create or update the Elasticsearch users of the tenants")
+		r.status.SetDegraded("Failed to create or update the Elasticsearch users of the tenants", err.Error())
+		return reconcile.Result{}, false, err
+	}
+	return reconcile.Result{}, true, nil
+}
@@ -0,0 +1,382 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
+	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/components"
+	"github.com/tigera/operator/pkg/controller/utils"
+	"github.com/tigera/operator/pkg/controller/utils/imageset"
+	"github.com/tigera/operator/pkg/render"
+	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
+	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/tls/certificatemanagement"
+)
+
+const (
+	// upgradePreflightPollInterval is how often the job of the pre-flight checks is polled while it runs.
+	upgradePreflightPollInterval = 10 * time.Second
+	// upgradePreflightRetryInterval is how long after failing the pre-flight checks are run again, so that the upgrade
+	// proceeds once the issues have been fixed.
+	upgradePreflightRetryInterval = 5 * time.Minute
+
+	// eckManagedAnnotation stops the ECK operator from reconciling the resource that has it while it is "false".
+	eckManagedAnnotation = "eck.k8s.elastic.co/managed"
+)
+
+// upgradePending returns true if the running Elasticsearch cluster is of a different version than the one of the
+// operator, i.e. applying the Elasticsearch CR would upgrade it, or if the ECK operator is upgraded. A cluster without
+// a version is not upgraded.
+func upgradePending(ls *operatorv1.LogStorage, elasticsearch *esv1.Elasticsearch) bool {
+	if ls == nil || ls.DeletionTimestamp != nil || elasticsearch == nil || elasticsearch.Spec.Version == "" {
+		return false
+	}
+	return elasticsearch.Spec.Version != components.ComponentEckElasticsearch.Version || eckUpgradePending(ls, elasticsearch)
+}
+
+// eckUpgradePending returns true if the Elasticsearch cluster is managed by a different version of the ECK operator
+// than the one of the operator, as recorded by the controller version annotation of the Elasticsearch CR.
+func eckUpgradePending(ls *operatorv1.LogStorage, elasticsearch *esv1.Elasticsearch) bool {
+	if ls == nil || ls.DeletionTimestamp != nil || elasticsearch == nil {
+		return false
+	}
+	version, ok := elasticsearch.Annotations[annotation.ControllerVersionAnnotation]
+	return ok && version != components.ComponentECKElasticsearchOperator.Version
+}
+
+// preflightJobCurrent returns true if the checks of the job were run for the versions of the operator.
+func preflightJobCurrent(job *batchv1.Job) bool {
+	return job.Spec.Template.Annotations[render.ElasticsearchUpgradePreflightVersionAnnotation] == components.ComponentEckElasticsearch.Version &&
+		job.Spec.Template.Annotations[render.ElasticsearchUpgradePreflightECKVersionAnnotation] == components.ComponentECKElasticsearchOperator.Version
+}
+
+// checkUpgradePreflight runs the pre-flight checks of an upgrade of Elasticsearch through a job, when an upgrade is
+// pending and not forced through the LogStorage spec.
+//
+// It returns a reconcile.Result, a 'blocked' bool indicating if the Elasticsearch and Kibana upgrade must not be
+// applied yet, a status message describing why if so, and an error.
+func (r *ReconcileLogStorage) checkUpgradePreflight(
+	ls *operatorv1.LogStorage,
+	elasticsearch *esv1.Elasticsearch,
+	install *operatorv1.InstallationSpec,
+	variant operatorv1.ProductVariant,
+	pullSecrets []*corev1.Secret,
+	trustedBundle certificatemanagement.TrustedBundle,
+	hdler utils.ComponentHandler,
+	reqLogger logr.Logger,
+	ctx context.Context,
+) (reconcile.Result, bool, string, error) {
+	forced := ls != nil && ls.Spec.UpgradePreflight != nil && ls.Spec.UpgradePreflight.Force
+	enabled := upgradePending(ls, elasticsearch) && !forced
+
+	preflightComponent := render.ElasticsearchUpgradePreflight(&render.ElasticsearchUpgradePreflightConfiguration{
+		LogStorage:    ls,
+		Installation:  install,
+		PullSecrets:   pullSecrets,
+		TrustedBundle: trustedBundle,
+		Provider:      r.provider,
+		Enabled:       enabled,
+	})
+	if err := imageset.ApplyImageSet(ctx, r.client, variant, preflightComponent); err != nil {
+		reqLogger.Error(err, "Error with images from ImageSet")
+		r.status.SetDegraded("Error with images from ImageSet", err.Error())
+		return reconcile.Result{}, true, "", err
+	}
+
+	if enabled {
+		// Recreate the job once the retry interval has passed after it failed, so that the checks run again.
+		job := &batchv1.Job{}
+		err := r.client.Get(ctx, client.ObjectKey{Name: render.ElasticsearchUpgradePreflightName, Namespace: render.ElasticsearchNamespace}, job)
+		if err != nil && !errors.IsNotFound(err) {
+			reqLogger.Error(err, "Failed to get the Elasticsearch upgrade pre-flight job")
+			r.status.SetDegraded("Failed to get the Elasticsearch upgrade pre-flight job", err.Error())
+			return reconcile.Result{}, true, "", err
+		}
+		if err == nil && preflightJobCurrent(job) {
+			if failed := jobCondition(job, batchv1.JobFailed); failed != nil && time.Since(failed.LastTransitionTime.Time) > upgradePreflightRetryInterval {
+				reqLogger.Info("Retrying the Elasticsearch upgrade pre-flight checks")
+				if err := r.client.Delete(ctx, job, client.PropagationPolicy("Background")); err != nil && !errors.IsNotFound(err) {
+					reqLogger.Error(err, "Failed to delete the Elasticsearch upgrade pre-flight job")
+					r.status.SetDegraded("Failed to delete the Elasticsearch upgrade pre-flight job", err.Error())
+					return reconcile.Result{}, true, "", err
+				}
+				return reconcile.Result{RequeueAfter: upgradePreflightPollInterval}, true, "Waiting for the Elasticsearch upgrade pre-flight checks to be retried", nil
+			}
+		}
+	}
+
+	if err := hdler.CreateOrUpdateOrDelete(ctx, preflightComponent, r.status); err != nil {
+		reqLogger.Error(err, "Error creating / updating resource")
+		r.status.SetDegraded("Error creating / updating resource", err.Error())
+		return reconcile.Result{}, true, "", err
+	}
+
+	if !enabled {
+		if forced && upgradePending(ls, elasticsearch) {
+			reqLogger.Info("Skipping the Elasticsearch upgrade pre-flight checks because the upgrade is forced")
+		}
+		return reconcile.Result{}, false, "", nil
+	}
+
+	job := &batchv1.Job{}
+	if err := r.client.Get(ctx, client.ObjectKey{Name: render.ElasticsearchUpgradePreflightName, Namespace: render.ElasticsearchNamespace}, job); err != nil {
+		reqLogger.Error(err, "Failed to get the Elasticsearch upgrade pre-flight job")
+		r.status.SetDegraded("Failed to get the Elasticsearch upgrade pre-flight job", err.Error())
+		return reconcile.Result{}, true, "", err
+	}
+
+	if jobCondition(job, batchv1.JobComplete) != nil {
+		reqLogger.Info("The Elasticsearch upgrade pre-flight checks passed", "version", components.ComponentEckElasticsearch.Version,
+			"eckOperatorVersion", components.ComponentECKElasticsearchOperator.Version)
+		return reconcile.Result{}, false, "", nil
+	}
+
+	if jobCondition(job, batchv1.JobFailed) != nil {
+		reason, err := r.upgradePreflightFailure(ctx)
+		if err != nil {
+			reqLogger.Error(err, "Failed to get the pods of the Elasticsearch upgrade pre-flight job")
+		}
+		msg := fmt.Sprintf("Elasticsearch upgrade to %s is blocked by failed pre-flight checks: %s. Fix the issue or set spec.upgradePreflight.force on the LogStorage to upgrade anyway",
+			components.ComponentEckElasticsearch.Version, reason)
+		return reconcile.Result{RequeueAfter: upgradePreflightRetryInterval}, true, msg, nil
+	}
+
+	return reconcile.Result{RequeueAfter: upgradePreflightPollInterval}, true,
+		fmt.Sprintf("Waiting for the pre-flight checks of the Elasticsearch upgrade to %s to complete", components.ComponentEckElasticsearch.Version), nil
+}
+
+// checkECKOperatorUpgrade staggers an upgrade of the ECK operator that passed the pre-flight checks: Elasticsearch and
+// Kibana are annotated as unmanaged before the new ECK operator is rolled out, so that it doesn't reconcile (and
+// possibly restart) them, and Elasticsearch and Kibana are only updated once the new ECK operator is running and the
+// health of the cluster is green, at which point the annotation is removed. The health isn't checked if the upgrade is
+// forced through the LogStorage spec.
+//
+// It returns a reconcile.Result, a status message describing why the update of Elasticsearch and Kibana is held back,
+// which is empty if it isn't, and an error.
+func (r *ReconcileLogStorage) checkECKOperatorUpgrade(
+	ls *operatorv1.LogStorage,
+	elasticsearch *esv1.Elasticsearch,
+	kibana *kbv1.Kibana,
+	subComponents []*render.LogStorageSubComponent,
+	reqLogger logr.Logger,
+	ctx context.Context,
+) (reconcile.Result, string, error) {
+	if !eckUpgradePending(ls, elasticsearch) {
+		return reconcile.Result{}, "", nil
+	}
+
+	current := &appsv1.StatefulSet{}
+	err := r.client.Get(ctx, client.ObjectKey{Name: render.ECKOperatorName, Namespace: render.ECKOperatorNamespace}, current)
+	if err != nil && !errors.IsNotFound(err) {
+		reqLogger.Error(err, "Failed to get the ECK operator")
+		r.status.SetDegraded("Failed to get the ECK operator", err.Error())
+		return reconcile.Result{}, "", err
+	}
+	if err != nil || !eckOperatorRolledOut(current, renderedECKOperator(subComponents)) {
+		if err := r.setECKManaged(ctx, false, elasticsearch, kibana); err != nil {
+			reqLogger.Error(err, "Failed to pause the reconciliation of Elasticsearch and Kibana by the ECK operator")
+			r.status.SetDegraded("Failed to pause the reconciliation of Elasticsearch and Kibana by the ECK operator", err.Error())
+			return reconcile.Result{}, "", err
+		}
+		return reconcile.Result{RequeueAfter: upgradePreflightPollInterval}, fmt.Sprintf("Waiting for the ECK operator to be upgraded to %s before Elasticsearch is updated",
+			components.ComponentECKElasticsearchOperator.Version), nil
+	}
+
+	// The annotation is only removed once, so that the cluster isn't paused again while the new ECK operator takes
+	// over Elasticsearch and updates its controller version annotation.
+	if elasticsearch.Annotations[eckManagedAnnotation] != "false" {
+		return reconcile.Result{}, "", nil
+	}
+
+	// The status of the Elasticsearch CR isn't updated while it is unmanaged, so the health is queried from the
+	// cluster.
+	forced := ls.Spec.UpgradePreflight != nil && ls.Spec.UpgradePreflight.Force
+	if !forced {
+		esClient, err := r.esCliCreator(r.client, ctx, relasticsearch.HTTPSEndpoint(rmeta.OSTypeLinux, r.clusterDomain))
+		if err != nil {
+			reqLogger.Error(err, "Failed to create the Elasticsearch client")
+			r.status.SetDegraded("Failed to connect to Elasticsearch", err.Error())
+			return reconcile.Result{}, "", err
+		}
+		health, err := esClient.ClusterHealth(ctx)
+		if err != nil {
+			reqLogger.Error(err, "Failed to get the health of Elasticsearch")
+			r.status.SetDegraded("Failed to get the health of Elasticsearch", err.Error())
+			return reconcile.Result{}, "", err
+		}
+		if health.Status != string(esv1.ElasticsearchGreenHealth) {
+			return reconcile.Result{RequeueAfter: upgradePreflightPollInterval}, fmt.Sprintf("Elasticsearch upgrade to ECK operator %s is paused because the health of the cluster is %q. Fix the issue or set spec.upgradePreflight.force on the LogStorage to upgrade anyway",
+				components.ComponentECKElasticsearchOperator.Version, health.Status), nil
+		}
+	}
+
+	if err := r.setECKManaged(ctx, true, elasticsearch, kibana); err != nil {
+		reqLogger.Error(err, "Failed to resume the reconciliation of Elasticsearch and Kibana by the ECK operator")
+		r.status.SetDegraded("Failed to resume the reconciliation of Elasticsearch and Kibana by the ECK operator", err.Error())
+		return reconcile.Result{}, "", err
+	}
+	reqLogger.Info("The ECK operator is upgraded, updating Elasticsearch", "eckOperatorVersion", components.ComponentECKElasticsearchOperator.Version)
+	return reconcile.Result{}, "", nil
+}
+
+// setECKManaged sets whether the ECK operator reconciles the Elasticsearch and Kibana CRs, through the managed
+// annotation of ECK. The annotation is removed, rather than set to "true", when they are managed.
+func (r *ReconcileLogStorage) setECKManaged(ctx context.Context, managed bool, elasticsearch *esv1.Elasticsearch, kibana *kbv1.Kibana) error {
+	objs := []client.Object{elasticsearch}
+	if kibana != nil {
+		objs = append(objs, kibana)
+	}
+	for _, obj := range objs {
+		annotations := obj.GetAnnotations()
+		_, unmanaged := annotations[eckManagedAnnotation]
+		if unmanaged != managed {
+			continue
+		}
+		patchFrom := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+		if managed {
+			delete(annotations, eckManagedAnnotation)
+		} else {
+			if annotations == nil {
+				annotations = map[string]string{}
+			}
+			annotations[eckManagedAnnotation] = "false"
+		}
+		obj.SetAnnotations(annotations)
+		if err := r.client.Patch(ctx, obj, patchFrom); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// upgradeHeldBackComponent is a log storage sub-component without the objects whose upgrade is held back by failing or
+// pending pre-flight checks, so that the other objects of the sub-component are still updated.
+type upgradeHeldBackComponent struct {
+	render.Component
+	kibana *kbv1.Kibana
+}
+
+func (c upgradeHeldBackComponent) Objects() ([]client.Object, []client.Object) {
+	toCreate, toDelete := c.Component.Objects()
+	var objs []client.Object
+	for _, obj := range toCreate {
+		if !upgradeHeldBack(obj, c.kibana) {
+			objs = append(objs, obj)
+		}
+	}
+	return objs, toDelete
+}
+
+// upgradeHeldBack returns true if the upgrade of the object is covered by the pre-flight checks: the Elasticsearch CR,
+// and the Kibana CR if its version changes, since Kibana can't run ahead of Elasticsearch.
+func upgradeHeldBack(obj client.Object, kibana *kbv1.Kibana) bool {
+	switch o := obj.(type) {
+	case *esv1.Elasticsearch:
+		return true
+	case *kbv1.Kibana:
+		return kibana != nil && kibana.Spec.Version != o.Spec.Version
+	}
+	return false
+}
+
+// renderedECKOperator returns the StatefulSet of the ECK operator of the rendered log storage sub-components, or nil if
+// it isn't rendered.
+func renderedECKOperator(subComponents []*render.LogStorageSubComponent) *appsv1.StatefulSet {
+	for _, subComponent := range subComponents {
+		if subComponent.Name != render.LogStorageSubComponentECKOperator {
+			continue
+		}
+		toCreate, _ := subComponent.Objects()
+		for _, obj := range toCreate {
+			if sts, ok := obj.(*appsv1.StatefulSet); ok && sts.Name == render.ECKOperatorName {
+				return sts
+			}
+		}
+	}
+	return nil
+}
+
+// eckOperatorRolledOut returns true if all the replicas of the StatefulSet of the ECK operator run its latest revision
+// and are ready, and its containers run the images of the desired StatefulSet, if any.
+func eckOperatorRolledOut(current, desired *appsv1.StatefulSet) bool {
+	replicas := int32(1)
+	if current.Spec.Replicas != nil {
+		replicas = *current.Spec.Replicas
+	}
+	if current.Status.ObservedGeneration < current.Generation ||
+		current.Status.UpdatedReplicas != replicas ||
+		current.Status.ReadyReplicas != replicas ||
+		current.Status.CurrentRevision != current.Status.UpdateRevision {
+		return false
+	}
+	if desired == nil {
+		return true
+	}
+	images := map[string]string{}
+	for _, c := range current.Spec.Template.Spec.Containers {
+		images[c.Name] = c.Image
+	}
+	for _, c := range desired.Spec.Template.Spec.Containers {
+		if images[c.Name] != c.Image {
+			return false
+		}
+	}
+	return true
+}
+
+// upgradePreflightFailure returns the reason for which the pre-flight checks failed, as reported in the termination
+// message of the pods of the job.
+func (r *ReconcileLogStorage) upgradePreflightFailure(ctx context.Context) (string, error) {
+	pods := &corev1.PodList{}
+	if err := r.client.List(ctx, pods,
+		client.InNamespace(render.ElasticsearchNamespace),
+		client.MatchingLabels{"k8s-app": render.ElasticsearchUpgradePreflightName},
+	); err != nil {
+		return "unknown", err
+	}
+	for _, pod := range pods.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.State.Terminated != nil && status.State.Terminated.ExitCode != 0 && status.State.Terminated.Message != "" {
+				return status.State.Terminated.Message, nil
+			}
+		}
+	}
+	return fmt.Sprintf("see the logs of the %s/%s job", render.ElasticsearchNamespace, render.ElasticsearchUpgradePreflightName), nil
+}
+
+// jobCondition returns the condition of the given type of the job if it is true, or nil otherwise.
+func jobCondition(job *batchv1.Job, conditionType batchv1.JobConditionType) *batchv1.JobCondition {
+	for i, c := range job.Status.Conditions {
+		if c.Type == conditionType && c.Status == corev1.ConditionTrue {
+			return &job.Status.Conditions[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
+	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
+	"github.com/go-logr/logr"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/controller/utils"
+	"github.com/tigera/operator/pkg/controller/utils/imageset"
+	"github.com/tigera/operator/pkg/crypto"
+	"github.com/tigera/operator/pkg/render"
+	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
+	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/tls/certificatemanagement"
+)
+
+// logStorageVerificationRetryInterval is how long after failing the verification job is run again.
+const logStorageVerificationRetryInterval = 5 * time.Minute
+
+// logStorageVerificationUserRole is the Elasticsearch role of the user of the verification job. It can only write,
+// search and delete the test document in the index of the job.
+var logStorageVerificationUserRole = map[string]interface{}{
+	"indices": []interface{}{
+		map[string]interface{}{
+			"names":      []string{render.LogStorageVerificationIndex},
+			"privileges": []string{"create_index", "write", "read"},
+		},
+	},
+}
+
+// setVerifiedCondition sets the Verified condition of the LogStorage and updates its status if the condition changed,
+// since the status isn't updated at the end of the reconciliation while the verification hasn't succeeded.
+func (r *ReconcileLogStorage) setVerifiedCondition(ctx context.Context, ls *operatorv1.LogStorage, status metav1.ConditionStatus, reason, message string) error {
+	existing := meta.FindStatusCondition(ls.Status.Conditions, operatorv1.LogStorageConditionVerified)
+	if existing != nil && existing.Status == status && existing.Reason == reason && existing.Message == message {
+		return nil
+	}
+	meta.SetStatusCondition(&ls.Status.Conditions, metav1.Condition{
+		Type:               operatorv1.LogStorageConditionVerified,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: ls.Generation,
+	})
+	return r.client.Status().Update(ctx, ls)
+}
+
+// verifyLogStorage runs the job that verifies that Elasticsearch and Kibana are functional, once they are operational.
+// The job is run again whenever either cluster is rolled out again. The LogStorage is degraded until the verification
+// succeeds.
+//
+// It returns a reconcile.Result, a 'proceed' bool indicating if the Reconcile function should proceed with the
+// reconcile process, and an error.
+func (r *ReconcileLogStorage) verifyLogStorage(
+	ls *operatorv1.LogStorage,
+	install *operatorv1.InstallationSpec,
+	variant operatorv1.ProductVariant,
+	pullSecrets []*corev1.Secret,
+	trustedBundle certificatemanagement.TrustedBundle,
+	elasticsearch *esv1.Elasticsearch,
+	kibana *kbv1.Kibana,
+	hdler utils.ComponentHandler,
+	reqLogger logr.Logger,
+	ctx context.Context,
+) (reconcile.Result, bool, error) {
+	enabled := ls != nil && ls.DeletionTimestamp == nil && ls.Spec.Verification != nil && elasticsearch != nil
+
+	cfg := &render.LogStorageVerificationConfiguration{
+		LogStorage:    ls,
+		Installation:  install,
+		PullSecrets:   pullSecrets,
+		TrustedBundle: trustedBundle,
+		Provider:      r.provider,
+		ClusterDomain: r.clusterDomain,
+		Enabled:       enabled,
+	}
+	if enabled {
+		cfg.ElasticsearchUID, cfg.ElasticsearchGeneration = elasticsearch.UID, elasticsearch.Generation
+		if kibana != nil {
+			cfg.KibanaUID, cfg.KibanaGeneration = kibana.UID, kibana.Generation
+		}
+	} else if ls != nil {
+		meta.RemoveStatusCondition(&ls.Status.Conditions, operatorv1.LogStorageConditionVerified)
+	}
+
+	userSecret, err := utils.GetSecret(ctx, r.client, render.LogStorageVerificationUserSecret, common.OperatorNamespace())
+	if err != nil {
+		reqLogger.Error(err, "Failed to get the Elasticsearch user secret of the LogStorage verification job")
+		r.status.SetDegraded("Failed to get the Elasticsearch user secret of the LogStorage verification job", err.Error())
+		return reconcile.Result{}, false, err
+	}
+	if enabled && userSecret == nil {
+		userSecret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      render.LogStorageVerificationUserSecret,
+				Namespace: common.OperatorNamespace(),
+			},
+			Data: map[string][]byte{
+				"username": []byte(render.LogStorageVerificationUserName),
+				"password": []byte(crypto.GeneratePassword(16)),
+			},
+		}
+	}
+	// The user is deleted together with its secret when the verification is disabled. It goes away with Elasticsearch
+	// when the cluster itself is removed.
+	if userSecret != nil && (enabled || (ls != nil && ls.DeletionTimestamp == nil && elasticsearch != nil)) {
+		if result, proceed, err := r.applyLogStorageVerificationUser(enabled, userSecret, reqLogger, ctx); err != nil || !proceed {
+			return result, proceed, err
+		}
+	}
+	cfg.UserSecret = userSecret
+
+	verificationComponent := render.LogStorageVerification(cfg)
+	if err := imageset.ApplyImageSet(ctx, r.client, variant, verificationComponent); err != nil {
+		reqLogger.Error(err, "Error with images from ImageSet")
+		r.status.SetDegraded("Error with images from ImageSet", err.Error())
+		return reconcile.Result{}, false, err
+	}
+
+	var job *batchv1.Job
+	if enabled {
+		// Jobs can't be updated, so the job is recreated when the clusters are rolled out again or when the retry
+		// interval has passed after it failed.
+		job = &batchv1.Job{}
+		err := r.client.Get(ctx, client.ObjectKey{Name: render.LogStorageVerificationName, Namespace: render.ElasticsearchNamespace}, job)
+		if err != nil && !errors.IsNotFound(err) {
+			reqLogger.Error(err, "Failed to get the LogStorage verification job")
+			r.status.SetDegraded("Failed to get the LogStorage verification job", err.Error())
+			return reconcile.Result{}, false, err
+		}
+		if errors.IsNotFound(err) {
+			job = nil
+		} else {
+			changed := job.Spec.Template.Annotations[render.LogStorageVerificationHashAnnotation] != render.LogStorageVerificationHash(cfg)
+			failed := jobCondition(job, batchv1.JobFailed)
+			if changed || (failed != nil && time.Since(failed.LastTransitionTime.Time) > logStorageVerificationRetryInterval) {
+				if err := r.client.Delete(ctx, job, client.PropagationPolicy("Background")); err != nil && !errors.IsNotFound(err) {
+					reqLogger.Error(err, "Failed to delete the LogStorage verification job")
+					r.status.SetDegraded("Failed to delete the LogStorage verification job", err.Error())
+					return reconcile.Result{}, false, err
+				}
+				r.status.SetDegraded("Waiting for the LogStorage verification job to be recreated", "")
+				return reconcile.Result{RequeueAfter: upgradePreflightPollInterval}, false, nil
+			}
+			if failed != nil {
+				msg := fmt.Sprintf("see the logs of the %s/%s job", render.ElasticsearchNamespace, render.LogStorageVerificationName)
+				if err := r.setVerifiedCondition(ctx, ls, metav1.ConditionFalse, "VerificationFailed",
+					fmt.Sprintf("The verification job failed (%s), %s", failed.Reason, msg)); err != nil {
+					reqLogger.Error(err, "Failed to update the LogStorage status")
+				}
+				r.status.SetDegraded("Failed to verify that Elasticsearch and Kibana are functional", msg)
+				return reconcile.Result{RequeueAfter: logStorageVerificationRetryInterval}, false, nil
+			}
+		}
+	}
+
+	if err := hdler.CreateOrUpdateOrDelete(ctx, verificationComponent, r.status); err != nil {
+		reqLogger.Error(err, "Error creating / updating resource")
+		r.status.SetDegraded("Error creating / updating resource", err.Error())
+		return reconcile.Result{}, false, err
+	}
+
+	if enabled {
+		if job == nil || jobCondition(job, batchv1.JobComplete) == nil {
+			if err := r.setVerifiedCondition(ctx, ls, metav1.ConditionUnknown, "VerificationPending",
+				"Waiting for the verification job to complete"); err != nil {
+				reqLogger.Error(err, "Failed to update the LogStorage status")
+			}
+			r.status.SetDegraded("Waiting for the LogStorage verification job to complete", "")
+			return reconcile.Result{RequeueAfter: upgradePreflightPollInterval}, false, nil
+		}
+		// The status is updated at the end of the reconciliation.
+		meta.SetStatusCondition(&ls.Status.Conditions, metav1.Condition{
+			Type:               operatorv1.LogStorageConditionVerified,
+			Status:             metav1.ConditionTrue,
+			Reason:             "VerificationSucceeded",
+			Message:            "The verification job succeeded",
+			ObservedGeneration: ls.Generation,
+		})
+	}
+	return reconcile.Result{}, true, nil
+}
+
+// applyLogStorageVerificationUser creates or updates the Elasticsearch user of the verification job, or deletes it
+// when the verification is disabled.
+//
+// It returns a reconcile.Result, a 'proceed' bool indicating if the Reconcile function should continue, and an error.
+func (r *ReconcileLogStorage) applyLogStorageVerificationUser(enabled bool, userSecret *corev1.Secret, reqLogger logr.Logger, ctx context.Context) (reconcile.Result, bool, error) {
+	esClient, err := r.esCliCreator(r.client, ctx, relasticsearch.HTTPSEndpoint(rmeta.OSTypeLinux, r.clusterDomain))
+	if err != nil {
+		reqLogger.Error(err, "failed to create the Elasticsearch client")
+		r.status.SetDegraded("Failed to connect to Elasticsearch", err.Error())
+		return reconcile.Result{}, false, err
+	}
+
+	if !enabled {
+		if err = esClient.DeleteUser(ctx, render.LogStorageVerificationUserName); err != nil {
+			reqLogger.Error(err, "failed to delete the Elasticsearch user of the LogStorage verification job")
+			r.status.SetDegraded("Failed to delete the Elasticsearch user of the LogStorage verification job", err.Error())
+			return reconcile.Result{}, false, err
+		}
+		return reconcile.Result{}, true, nil
+	}
+	if err = esClient.SetRole(ctx, render.LogStorageVerificationRoleName, logStorageVerificationUserRole); err != nil {
+		reqLogger.Error(err, "failed to create or update the Elasticsearch role of the LogStorage verification job")
+		r.status.SetDegraded("Failed to create or update the Elasticsearch role of the LogStorage verification job", err.Error())
+		return reconcile.Result{}, false, err
+	}
+	username, password := string(userSecret.Data["username"]), string(userSecret.Data["password"])
+	if err = esClient.SetUser(ctx, username, password, []string{render.LogStorageVerificationRoleName}); err != nil {
+		reqLogger.Error(err, "failed to create or update the Elasticsearch user of the LogStorage verification job")
+		r.status.SetDegraded("Failed to create or update the Elasticsearch user of the LogStorage verification job", err.Error())
+		return reconcile.Result{}, false, err
+	}
+	return reconcile.Result{}, true, nil
+}
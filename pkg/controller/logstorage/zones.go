@@ -0,0 +1,54 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+)
+
+// elasticsearchZones returns the sorted zones of the K8s nodes that Elasticsearch can run on, as read from their
+// topology.kubernetes.io/zone label. Elasticsearch runs on the nodes selected by the data node selector of the
+// LogStorage, or by the control plane node selector of the Installation if it has none. Nodes without the label are
+// ignored.
+func elasticsearchZones(ctx context.Context, cli client.Client, ls *operatorv1.LogStorage, install *operatorv1.InstallationSpec) ([]string, error) {
+	nodeSelector := install.ControlPlaneNodeSelector
+	if ls.Spec.DataNodeSelector != nil {
+		nodeSelector = ls.Spec.DataNodeSelector
+	}
+
+	nodes := &corev1.NodeList{}
+	if err := cli.List(ctx, nodes, client.MatchingLabels(nodeSelector)); err != nil {
+		return nil, err
+	}
+
+	found := map[string]bool{}
+	var zones []string
+	for _, node := range nodes.Items {
+		zone := node.Labels[corev1.LabelTopologyZone]
+		if zone == "" || found[zone] {
+			continue
+		}
+		found[zone] = true
+		zones = append(zones, zone)
+	}
+	sort.Strings(zones)
+	return zones, nil
+}
@@ -88,7 +88,13 @@ func Add(mgr manager.Manager, opts options.AddOptions) error {
 		{Name: networkpolicy.TigeraComponentDefaultDenyPolicyName, Namespace: render.ManagerNamespace},
 	})
 
-	return add(mgr, controller)
+	if err := add(mgr, controller); err != nil {
+		return err
+	}
+	return utils.AddReadyzCheck(mgr, "manager-controller", map[string]*utils.ReadyFlag{
+		"license-api": licenseAPIReady,
+		"tier-watch":  tierWatchReady,
+	})
 }
 
 // newReconciler returns a new reconcile.Reconciler
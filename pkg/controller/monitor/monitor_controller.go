@@ -97,7 +97,13 @@ func Add(mgr manager.Manager, opts options.AddOptions) error {
 
 	go waitToAddPrometheusWatch(controller, k8sClient, log, prometheusReady)
 
-	return add(mgr, controller)
+	if err := add(mgr, controller); err != nil {
+		return err
+	}
+	return utils.AddReadyzCheck(mgr, "monitor-controller", map[string]*utils.ReadyFlag{
+		"prometheus-api": prometheusReady,
+		"tier-watch":     tierWatchReady,
+	})
 }
 
 func newReconciler(mgr manager.Manager, opts options.AddOptions, prometheusReady *utils.ReadyFlag, tierWatchReady *utils.ReadyFlag) reconcile.Reconciler {
@@ -36,4 +36,18 @@ type AddOptions struct {
 
 	// Whether or not the cluster supports PodSecurityPolicies.
 	UsePSP bool
+
+	// Whether or not the cluster serves CronJobs from the batch/v1 API. When it doesn't, CronJobs are rendered with the
+	// batch/v1beta1 API.
+	UseBatchV1CronJobs bool
+
+	// Whether or not to deploy the test log generator. Only meant for demos and CI.
+	EnableTestLogGenerator bool
+
+	// InfrastructureProvider is the Cluster API infrastructure provider of the cluster, if it was provisioned by Cluster
+	// API. It enables defaults that are specific to the infrastructure.
+	InfrastructureProvider common.InfrastructureProvider
+
+	// MaxConcurrentReconciles is the number of reconciles that the controllers that support it run concurrently.
+	MaxConcurrentReconciles int
 }
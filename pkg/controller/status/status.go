@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -42,6 +43,10 @@ import (
 
 var log = logf.Log.WithName("status_manager")
 
+// copyDriftConditionPeriod is how long a repaired copy is reported by the CopyDriftDetected condition, so that the
+// condition stays visible after the copy is found unmodified on the next reconcile.
+const copyDriftConditionPeriod = time.Hour
+
 // StatusManager manages the status for a single controller and component, and reports the status via
 // a TigeraStatus API object. The status manager uses the following conditions/states to represent the
 // component's current status:
@@ -80,6 +85,26 @@ type StatusManager interface {
 	SetMetaData(meta *metav1.ObjectMeta)
 }
 
+// RenderedComponentTracker is implemented by the status managers that report the objects that the operator renders
+// for their component in the TigeraStatus.
+type RenderedComponentTracker interface {
+	AddRenderedComponents(rcs ...operator.RenderedComponent)
+	RemoveRenderedComponents(rcs ...operator.RenderedComponent)
+}
+
+// CopyDriftTracker is implemented by the status managers that report in the TigeraStatus the copies of secrets and
+// ConfigMaps that the operator repaired because they were modified since the operator wrote them.
+type CopyDriftTracker interface {
+	SetCopyDrift(name string, drifted bool)
+}
+
+// SubComponentTracker is implemented by the status managers that report the degradation of the parts of their
+// component separately, so that the failure of one part doesn't mask the state of the others.
+type SubComponentTracker interface {
+	SetSubComponentDegraded(name, msg string)
+	ClearSubComponentDegraded(name string)
+}
+
 // This regex matches any characters that are not allowed in a Status Reason.
 var reasonInvalidCharacters = regexp.MustCompile(`[^A-Za-z0-9_,:]`)
 
@@ -102,6 +127,10 @@ type statusManager struct {
 	statefulsets              map[string]types.NamespacedName
 	cronjobs                  map[string]types.NamespacedName
 	certificatestatusrequests map[string]map[string]string
+	renderedComponents        map[string]operator.RenderedComponent
+	renderedInReconcile       map[string]bool
+	driftedCopies             map[string]time.Time
+	degradedSubComponents     map[string]string
 	windowsNodeUpgrades       *windowsNodeUpgrades
 	lock                      sync.Mutex
 	enabled                   *bool
@@ -114,6 +143,10 @@ type statusManager struct {
 	// Track degraded state set by calicoWindowsUpgrader.
 	windowsUpgradeDegradedMsg string
 
+	// copyDriftReported is whether a drifted copy was ever reported, after which the CopyDriftDetected condition is
+	// maintained.
+	copyDriftReported bool
+
 	// Keep track of currently calculated status.
 	progressing []string
 	failing     []string
@@ -151,6 +184,9 @@ func New(client client.Client, component string, kubernetesVersion *common.Versi
 		statefulsets:              make(map[string]types.NamespacedName),
 		cronjobs:                  make(map[string]types.NamespacedName),
 		certificatestatusrequests: make(map[string]map[string]string),
+		renderedComponents:        make(map[string]operator.RenderedComponent),
+		driftedCopies:             make(map[string]time.Time),
+		degradedSubComponents:     make(map[string]string),
 		windowsNodeUpgrades:       newWindowsNodeUpgrades(),
 		kubernetesVersion:         kubernetesVersion,
 		crExists:                  crExists,
@@ -216,13 +252,14 @@ func (m *statusManager) updateStatus() {
 			m.clearDegraded()
 		}
 	}
+	m.setCopyDriftCondition()
 
 }
 
 func (m *statusManager) isExplicitlyDegraded() bool {
 	m.lock.Lock()
 	defer m.lock.Unlock()
-	return m.degraded
+	return m.degraded || len(m.degradedSubComponents) > 0
 }
 
 // Run starts the status manager state monitoring routine.
@@ -265,6 +302,9 @@ func (m *statusManager) OnCRFound() {
 	defer m.lock.Unlock()
 	t := true
 	m.enabled = &t
+	// The controllers call OnCRFound at the start of each reconcile, so the objects that are rendered from here on are
+	// the ones that the reconcile renders.
+	m.renderedInReconcile = map[string]bool{}
 }
 
 // OnCRNotFound indicates that the CR managed by the parent controller has not been found. The
@@ -283,6 +323,10 @@ func (m *statusManager) OnCRNotFound() {
 	m.deployments = make(map[string]types.NamespacedName)
 	m.statefulsets = make(map[string]types.NamespacedName)
 	m.cronjobs = make(map[string]types.NamespacedName)
+	m.renderedComponents = make(map[string]operator.RenderedComponent)
+	m.driftedCopies = make(map[string]time.Time)
+	m.copyDriftReported = false
+	m.degradedSubComponents = make(map[string]string)
 }
 
 // AddDaemonsets tells the status manager to monitor the health of the given daemonsets.
@@ -408,6 +452,142 @@ func (m *statusManager) RemoveCronJobs(cjs ...types.NamespacedName) {
 	}
 }
 
+// renderedComponentKey returns the key that identifies the object of a RenderedComponent.
+func renderedComponentKey(rc operator.RenderedComponent) string {
+	return fmt.Sprintf("%s/%s/%s", rc.Kind, rc.Namespace, rc.Name)
+}
+
+// AddRenderedComponents adds the given objects to the objects that the TigeraStatus reports the operator renders, or
+// updates them.
+func (m *statusManager) AddRenderedComponents(rcs ...operator.RenderedComponent) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for _, rc := range rcs {
+		key := renderedComponentKey(rc)
+		m.renderedComponents[key] = rc
+		if m.renderedInReconcile != nil {
+			m.renderedInReconcile[key] = true
+		}
+	}
+}
+
+// pruneRenderedComponents removes the objects that the last reconcile didn't render from the objects that the
+// TigeraStatus reports the operator renders, e.g. the ones that were deleted outside of the component handler. The
+// controllers clear the degraded state once a reconcile has applied all of its components, so only the objects of a
+// complete reconcile are kept. It must be called with the lock held.
+func (m *statusManager) pruneRenderedComponents() {
+	if m.renderedInReconcile == nil {
+		return
+	}
+	for key := range m.renderedComponents {
+		if !m.renderedInReconcile[key] {
+			delete(m.renderedComponents, key)
+		}
+	}
+	m.renderedInReconcile = nil
+}
+
+// RemoveRenderedComponents removes the given objects from the objects that the TigeraStatus reports the operator
+// renders, e.g. once they have been deleted.
+func (m *statusManager) RemoveRenderedComponents(rcs ...operator.RenderedComponent) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	for _, rc := range rcs {
+		delete(m.renderedComponents, renderedComponentKey(rc))
+	}
+}
+
+// SetCopyDrift records whether the copy of a secret or ConfigMap was found modified, and repaired, the last time that
+// the operator wrote it. The copy is identified by its kind, namespace and name. A repaired copy is reported for the
+// copyDriftConditionPeriod after its last repair, even if it is found unmodified since.
+func (m *statusManager) SetCopyDrift(name string, drifted bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if drifted {
+		m.driftedCopies[name] = time.Now()
+		m.copyDriftReported = true
+	} else if repaired, ok := m.driftedCopies[name]; ok && time.Since(repaired) > copyDriftConditionPeriod {
+		delete(m.driftedCopies, name)
+	}
+}
+
+// setCopyDriftCondition sets the CopyDriftDetected condition, once a drifted copy was reported. The condition is true
+// while a copy was repaired within the copyDriftConditionPeriod.
+func (m *statusManager) setCopyDriftCondition() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if !m.copyDriftReported {
+		return
+	}
+	for name, repaired := range m.driftedCopies {
+		if time.Since(repaired) > copyDriftConditionPeriod {
+			delete(m.driftedCopies, name)
+		}
+	}
+	condition := operator.TigeraStatusCondition{
+		Type:    operator.ComponentCopyDriftDetected,
+		Status:  operator.ConditionFalse,
+		Reason:  string(operator.AllCopiesInSync),
+		Message: "All copies match their source",
+	}
+	if len(m.driftedCopies) > 0 {
+		var copies []string
+		for name := range m.driftedCopies {
+			copies = append(copies, name)
+		}
+		sort.Strings(copies)
+		condition.Status = operator.ConditionTrue
+		condition.Reason = string(operator.CopyDriftRepaired)
+		condition.Message = fmt.Sprintf("Repaired copies that were modified since the operator wrote them: %s", strings.Join(copies, ", "))
+	}
+	m.set(true, condition)
+}
+
+// SetSubComponentDegraded sets the degraded state of a part of the component with the provided message.
+func (m *statusManager) SetSubComponentDegraded(name, msg string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.degradedSubComponents[name] = msg
+}
+
+// ClearSubComponentDegraded clears the degraded state of a part of the component.
+func (m *statusManager) ClearSubComponentDegraded(name string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	delete(m.degradedSubComponents, name)
+}
+
+// subComponentDegradedMessages returns the messages of the degraded parts of the component, sorted by their names.
+func (m *statusManager) subComponentDegradedMessages() []string {
+	var names []string
+	for name := range m.degradedSubComponents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var msgs []string
+	for _, name := range names {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", name, m.degradedSubComponents[name]))
+	}
+	return msgs
+}
+
+// renderedComponentList returns the objects that the operator renders, sorted by kind, namespace and name.
+func (m *statusManager) renderedComponentList() []operator.RenderedComponent {
+	if len(m.renderedComponents) == 0 {
+		return nil
+	}
+	var keys []string
+	for key := range m.renderedComponents {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	rcs := make([]operator.RenderedComponent, len(keys))
+	for i, key := range keys {
+		rcs[i] = m.renderedComponents[key]
+	}
+	return rcs
+}
+
 // RemoveCertificateSigningRequests tells the status manager to stop monitoring the health of the given CertificateSigningRequests.
 func (m *statusManager) RemoveCertificateSigningRequests(name string) {
 	m.lock.Lock()
@@ -432,6 +612,7 @@ func (m *statusManager) ClearDegraded() {
 	m.explicitDegradedReason = ""
 	m.explicitDegradedMsg = ""
 	m.windowsUpgradeDegradedMsg = ""
+	m.pruneRenderedComponents()
 }
 
 // IsAvailable returns true if the component is available and false otherwise.
@@ -445,7 +626,7 @@ func (m *statusManager) IsAvailable() bool {
 		return false
 	}
 
-	if m.degraded {
+	if m.degraded || len(m.degradedSubComponents) > 0 {
 		return false
 	}
 
@@ -476,7 +657,7 @@ func (m *statusManager) IsDegraded() bool {
 	// should start monitoring resources.
 	// windowsUpgradeDegradedReason indicates an error has occurred with the
 	// Calico Windows upgrade.
-	if m.degraded || m.windowsUpgradeDegradedMsg != "" {
+	if m.degraded || m.windowsUpgradeDegradedMsg != "" || len(m.degradedSubComponents) > 0 {
 		return true
 	}
 
@@ -616,14 +797,14 @@ func (m *statusManager) syncState() {
 	}
 
 	for _, depnn := range m.cronjobs {
-		cj := &batch.CronJob{}
-		if err := m.client.Get(context.TODO(), depnn, cj); err != nil {
+		active, err := m.cronJobActiveJobs(depnn)
+		if err != nil {
 			log.WithValues("reason", err).Info("Failed to query cronjobs")
 			continue
 		}
 
 		var numFailed = 0
-		for _, jref := range cj.Status.Active {
+		for _, jref := range active {
 			j := &batchv1.Job{}
 			if err := m.client.Get(context.TODO(), types.NamespacedName{Namespace: jref.Namespace, Name: jref.Name}, j); err != nil {
 				log.WithValues("reason", err).Info("couldn't query cronjob job")
@@ -636,7 +817,7 @@ func (m *statusManager) syncState() {
 		}
 
 		if numFailed > 0 {
-			failing = append(failing, "cronjob/"+cj.Name+" failed in ns '"+cj.Namespace+"'")
+			failing = append(failing, "cronjob/"+depnn.Name+" failed in ns '"+depnn.Namespace+"'")
 		}
 	}
 
@@ -780,8 +961,10 @@ func (m *statusManager) set(retry bool, conditions ...operator.TigeraStatusCondi
 		}
 	}
 
+	ts.Status.RenderedComponents = m.renderedComponentList()
+
 	// If nothing has changed, we don't need to update in the API.
-	if reflect.DeepEqual(ts.Status.Conditions, old.Status.Conditions) {
+	if reflect.DeepEqual(ts.Status.Conditions, old.Status.Conditions) && reflect.DeepEqual(ts.Status.RenderedComponents, old.Status.RenderedComponents) {
 		return
 	}
 
@@ -891,6 +1074,7 @@ func (m *statusManager) degradedMessage() string {
 	if m.windowsUpgradeDegradedMsg != "" {
 		msgs = append(msgs, m.windowsUpgradeDegradedMsg)
 	}
+	msgs = append(msgs, m.subComponentDegradedMessages()...)
 	msgs = append(msgs, m.failing...)
 	return strings.Join(msgs, "\n")
 }
@@ -912,6 +1096,9 @@ func (m *statusManager) degradedReason() string {
 	if m.windowsUpgradeDegradedMsg != "" {
 		return string(operator.UpgradeError)
 	}
+	if len(m.degradedSubComponents) != 0 {
+		return string(operator.ResourceUpdateError)
+	}
 	if len(m.failing) != 0 {
 		return string(operator.PodFailure)
 	}
@@ -944,6 +1131,20 @@ func (m *statusManager) SetMetaData(meta *metav1.ObjectMeta) {
 	m.observedGeneration = meta.Generation
 }
 
+// cronJobActiveJobs returns the jobs that the cronjob is running. The cronjob is queried with the batch/v1 API, and
+// with the batch/v1beta1 API on the clusters that don't serve CronJobs from the batch/v1 API.
+func (m *statusManager) cronJobActiveJobs(nn types.NamespacedName) ([]corev1.ObjectReference, error) {
+	cj := &batchv1.CronJob{}
+	if err := m.client.Get(context.TODO(), nn, cj); err == nil {
+		return cj.Status.Active, nil
+	}
+	cjBeta := &batch.CronJob{}
+	if err := m.client.Get(context.TODO(), nn, cjBeta); err != nil {
+		return nil, err
+	}
+	return cjBeta.Status.Active, nil
+}
+
 func hasPendingCSR(ctx context.Context, m *statusManager, labelMap map[string]string) (bool, error) {
 	if m.kubernetesVersion.ProvidesCertV1API() {
 		return hasPendingCSRUsingCertV1(ctx, m.client, labelMap)
@@ -17,6 +17,7 @@ package status
 import (
 	"context"
 	"fmt"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
@@ -459,6 +460,107 @@ var _ = Describe("Status reporting tests", func() {
 			Expect(sm.degradedMessage()).To(Equal("Controller set us degraded\nThis pod has died"))
 		})
 
+		It("should report the rendered components in the TigeraStatus", func() {
+			deployment := operator.RenderedComponent{Kind: "Deployment", Name: "DP1", Namespace: "NS1", ResourceVersion: "1", Owner: "Manager/tigera-secure"}
+			clusterRole := operator.RenderedComponent{Kind: "ClusterRole", Name: "CR1", ResourceVersion: "2", Owner: "Manager/tigera-secure"}
+			secret := operator.RenderedComponent{Kind: "Secret", Name: "S1", Namespace: "NS1", ResourceVersion: "3", Owner: "Manager/tigera-secure"}
+			sm.AddRenderedComponents(deployment, clusterRole, secret)
+			sm.RemoveRenderedComponents(operator.RenderedComponent{Kind: "Secret", Name: "S1", Namespace: "NS1"})
+			sm.ReadyToMonitor()
+			sm.updateStatus()
+
+			stat := &operator.TigeraStatus{}
+			Expect(client.Get(ctx, types.NamespacedName{Name: "test-component"}, stat)).NotTo(HaveOccurred())
+			Expect(stat.Status.RenderedComponents).To(Equal([]operator.RenderedComponent{clusterRole, deployment}))
+
+			// A change of only the rendered components is reported too.
+			deployment.ResourceVersion = "4"
+			sm.AddRenderedComponents(deployment)
+			sm.updateStatus()
+			Expect(client.Get(ctx, types.NamespacedName{Name: "test-component"}, stat)).NotTo(HaveOccurred())
+			Expect(stat.Status.RenderedComponents).To(Equal([]operator.RenderedComponent{clusterRole, deployment}))
+		})
+
+		It("should prune the rendered components that a complete reconcile no longer renders", func() {
+			deployment := operator.RenderedComponent{Kind: "Deployment", Name: "DP1", Namespace: "NS1", ResourceVersion: "1"}
+			clusterRole := operator.RenderedComponent{Kind: "ClusterRole", Name: "CR1", ResourceVersion: "2"}
+			sm.OnCRFound()
+			sm.AddRenderedComponents(deployment, clusterRole)
+			sm.ClearDegraded()
+			Expect(sm.renderedComponentList()).To(Equal([]operator.RenderedComponent{clusterRole, deployment}))
+
+			// A reconcile that fails before it clears the degraded state keeps the objects that it didn't render.
+			sm.OnCRFound()
+			sm.AddRenderedComponents(deployment)
+			Expect(sm.renderedComponentList()).To(Equal([]operator.RenderedComponent{clusterRole, deployment}))
+
+			// The cluster role was deleted outside of the component handler, so the next complete reconcile drops it.
+			sm.OnCRFound()
+			sm.AddRenderedComponents(deployment)
+			sm.ClearDegraded()
+			Expect(sm.renderedComponentList()).To(Equal([]operator.RenderedComponent{deployment}))
+		})
+
+		It("should report the copies that were repaired in the TigeraStatus", func() {
+			copyDriftCondition := func() *operator.TigeraStatusCondition {
+				stat := &operator.TigeraStatus{}
+				Expect(client.Get(ctx, types.NamespacedName{Name: "test-component"}, stat)).NotTo(HaveOccurred())
+				for _, c := range stat.Status.Conditions {
+					if c.Type == operator.ComponentCopyDriftDetected {
+						return &c
+					}
+				}
+				return nil
+			}
+			sm.ReadyToMonitor()
+			sm.updateStatus()
+			Expect(copyDriftCondition()).To(BeNil())
+
+			sm.SetCopyDrift("Secret NS1/S2", true)
+			sm.SetCopyDrift("Secret NS1/S1", true)
+			sm.SetCopyDrift("ConfigMap NS1/CM1", false)
+			sm.updateStatus()
+			condition := copyDriftCondition()
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(operator.ConditionTrue))
+			Expect(condition.Reason).To(Equal(string(operator.CopyDriftRepaired)))
+			Expect(condition.Message).To(Equal("Repaired copies that were modified since the operator wrote them: Secret NS1/S1, Secret NS1/S2"))
+
+			// The condition stays set for a period after the repairs, even though the copies are found unmodified.
+			sm.SetCopyDrift("Secret NS1/S1", false)
+			sm.SetCopyDrift("Secret NS1/S2", false)
+			sm.updateStatus()
+			condition = copyDriftCondition()
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(operator.ConditionTrue))
+
+			// The condition clears once the period has passed since the repairs.
+			for name := range sm.driftedCopies {
+				sm.driftedCopies[name] = time.Now().Add(-copyDriftConditionPeriod - time.Minute)
+			}
+			sm.updateStatus()
+			condition = copyDriftCondition()
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(operator.ConditionFalse))
+			Expect(condition.Reason).To(Equal(string(operator.AllCopiesInSync)))
+		})
+
+		It("should report the degraded sub-components separately", func() {
+			sm.SetSubComponentDegraded("Kibana", "Waiting for Kibana cluster to be operational")
+			sm.SetSubComponentDegraded("Curator", "Error creating / updating resource: boom")
+			Expect(sm.IsDegraded()).To(BeTrue())
+			Expect(sm.degradedReason()).To(Equal(string(operator.ResourceUpdateError)))
+			Expect(sm.degradedMessage()).To(Equal("Curator: Error creating / updating resource: boom\nKibana: Waiting for Kibana cluster to be operational"))
+
+			sm.SetDegraded("Waiting for Elasticsearch cluster to be operational", "")
+			Expect(sm.degradedReason()).To(Equal("Waiting for Elasticsearch cluster to be operational"))
+
+			sm.ClearDegraded()
+			sm.ClearSubComponentDegraded("Kibana")
+			sm.ClearSubComponentDegraded("Curator")
+			Expect(sm.IsDegraded()).To(BeFalse())
+		})
+
 		It("should contain all the NamespacesNames for all the resources added by multiple calls to Set<Resources>", func() {
 			sm.AddStatefulSets([]types.NamespacedName{{Namespace: "NS1", Name: "SS1"}})
 			sm.AddStatefulSets([]types.NamespacedName{{Namespace: "NS1", Name: "SS2"}})
@@ -39,11 +39,20 @@ import (
 
 	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
 
+	operatorv1 "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/common"
 	"github.com/tigera/operator/pkg/controller/status"
 	"github.com/tigera/operator/pkg/render"
 
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/scheduling"
+)
+
+const (
+	// maxComponentObjects is the maximum number of objects that a component is expected to render.
+	maxComponentObjects = 1000
+	// maxObjectDataSize is the maximum size of the data of a ConfigMap or Secret that the API server accepts.
+	maxObjectDataSize = 1024 * 1024
 )
 
 type ComponentHandler interface {
@@ -68,10 +77,16 @@ type componentHandler struct {
 	log    logr.Logger
 }
 
-func (c componentHandler) createOrUpdateObject(ctx context.Context, obj client.Object, osType rmeta.OSType) error {
+// errObjectIgnored is returned by createOrUpdateObject when the object exists and the user has marked it as ignored.
+var errObjectIgnored = fmt.Errorf("object is ignored")
+
+// createOrUpdateObject creates or updates the object. It returns the resource version of the object in the cluster
+// after it was applied, and whether the object is a copy of a secret or ConfigMap that was modified since the operator
+// last wrote it, and was repaired.
+func (c componentHandler) createOrUpdateObject(ctx context.Context, obj client.Object, osType rmeta.OSType, archs []string) (string, bool, error) {
 	om, ok := obj.(metav1.ObjectMetaAccessor)
 	if !ok {
-		return fmt.Errorf("Object is not ObjectMetaAccessor")
+		return "", false, fmt.Errorf("Object is not ObjectMetaAccessor")
 	}
 
 	// Add owner ref for controller owned resources,
@@ -81,7 +96,7 @@ func (c componentHandler) createOrUpdateObject(ctx context.Context, obj client.O
 	default:
 		if c.cr != nil {
 			if err := controllerutil.SetControllerReference(c.cr, om.GetObjectMeta(), c.scheme); err != nil {
-				return err
+				return "", false, err
 			}
 		}
 	}
@@ -90,8 +105,8 @@ func (c componentHandler) createOrUpdateObject(ctx context.Context, obj client.O
 	key := client.ObjectKeyFromObject(obj)
 
 	// Ensure that if the object is something the creates a pod that it is scheduled on nodes running the operating
-	// system as specified by the osType.
-	ensureOSSchedulingRestrictions(obj, osType)
+	// system as specified by the osType, and one of the CPU architectures that its images are published for.
+	ensureSchedulingRestrictions(obj, osType, archs)
 
 	// Make sure any objects with images also have an image pull policy.
 	modifyPodSpec(obj, setImagePullPolicy)
@@ -99,35 +114,48 @@ func (c componentHandler) createOrUpdateObject(ctx context.Context, obj client.O
 	// Make sure we have our standard selector and pod labels
 	setStandardSelectorAndLabels(obj)
 
+	// Copies of secrets and ConfigMaps record the hash of their data, so that a copy that is modified afterwards can be
+	// detected and repaired.
+	copied := isCopy(obj)
+	if copied {
+		setCopyHash(obj)
+	}
+
 	cur, ok := obj.DeepCopyObject().(client.Object)
 	if !ok {
 		logCtx.V(2).Info("Failed converting object", "obj", obj)
-		return fmt.Errorf("Failed converting object %+v", obj)
+		return "", false, fmt.Errorf("Failed converting object %+v", obj)
 	}
 	// Check to see if the object exists or not.
 	err := c.client.Get(ctx, key, cur)
 	if err != nil {
 		if !errors.IsNotFound(err) {
 			// Anything other than "Not found" we should retry.
-			return err
+			return "", false, err
 		}
 
 		// Otherwise, if it was not found, we should create it and move on.
 		logCtx.V(2).Info("Object does not exist, creating it", "error", err)
 		err = c.client.Create(ctx, obj)
 		if err != nil {
-			return err
+			return "", false, err
 		}
-		return nil
+		return obj.GetResourceVersion(), false, nil
 	}
 
 	// The object exists. Update it, unless the user has marked it as "ignored".
 	if IgnoreObject(cur) {
-		logCtx.Info("Ignoring annotated object")
-		return nil
+		logCtx.Info("Ignoring object that is managed by the user")
+		return cur.GetResourceVersion(), false, errObjectIgnored
 	}
 	logCtx.V(1).Info("Resource already exists, update it")
 
+	drifted := copied && copyDrifted(cur)
+	if drifted {
+		logCtx.Info("Repairing a copy that was modified since the operator wrote it", "source", obj.GetAnnotations()[rmeta.CopySourceAnnotation])
+	}
+
+	resourceVersion := cur.GetResourceVersion()
 	// if mergeState returns nil we don't want to update the object
 	if mobj := mergeState(obj, cur); mobj != nil {
 		switch obj.(type) {
@@ -135,12 +163,13 @@ func (c componentHandler) createOrUpdateObject(ctx context.Context, obj client.O
 			// Jobs can't be updated, they can only be deleted then created
 			if err := c.client.Delete(ctx, obj); err != nil {
 				logCtx.WithValues("key", key).Info("Failed to delete job for recreation.")
-				return err
+				return "", false, err
 			}
 
 			if err := c.client.Create(ctx, obj); err != nil {
-				return err
+				return "", false, err
 			}
+			resourceVersion = obj.GetResourceVersion()
 		case *v1.Secret:
 			objSecret := obj.(*v1.Secret)
 			curSecret := cur.(*v1.Secret)
@@ -150,26 +179,32 @@ func (c componentHandler) createOrUpdateObject(ctx context.Context, obj client.O
 				!(len(objSecret.Type) == 0 && curSecret.Type == v1.SecretTypeOpaque) {
 				if err := c.client.Delete(ctx, obj); err != nil {
 					logCtx.WithValues("key", key).Info("Failed to delete secret for recreation.")
-					return err
+					return "", false, err
 				}
 				obj.SetResourceVersion("")
 				if err := c.client.Create(ctx, obj); err != nil {
-					return err
+					return "", false, err
 				}
+				resourceVersion = obj.GetResourceVersion()
 			} else {
 				if err := c.client.Update(ctx, mobj); err != nil {
 					logCtx.WithValues("key", key).Info("Failed to update object.")
-					return err
+					return "", false, err
 				}
+				resourceVersion = mobj.GetResourceVersion()
 			}
 		default:
 			if err := c.client.Update(ctx, mobj); err != nil {
 				logCtx.WithValues("key", key).Info("Failed to update object.")
-				return err
+				return "", false, err
 			}
+			resourceVersion = mobj.GetResourceVersion()
 		}
 	}
-	return nil
+	if drifted {
+		recordCopyRepair(obj)
+	}
+	return resourceVersion, drifted, nil
 }
 
 func (c componentHandler) CreateOrUpdateOrDelete(ctx context.Context, component render.Component, status status.StatusManager) error {
@@ -192,22 +227,51 @@ func (c componentHandler) CreateOrUpdateOrDelete(ctx context.Context, component
 
 	objsToCreate, objsToDelete := component.Objects()
 	osType := component.SupportedOSType()
+	var archs []string
+	if arc, ok := component.(render.ArchitectureRestrictedComponent); ok {
+		var err error
+		if archs, err = c.restrictedArchitectures(ctx, arc.SupportedArchitectures()); err != nil {
+			cmpLog.Error(err, "Failed to check the architectures of the nodes")
+			return err
+		}
+	}
+
+	// Check that the rendered objects are within the limits of the datastore before applying any of them, so that the
+	// component is not left partially applied.
+	if err := validateObjectBudgets(objsToCreate); err != nil {
+		cmpLog.Error(err, "Rendered objects exceed the limits of the datastore")
+		return err
+	}
 
+	// A namespace that is being deleted blocks the creation of the objects in it until its deletion completes.
+	if err := c.checkNamespacesTerminating(ctx, objsToCreate); err != nil {
+		return err
+	}
+
+	var rendered, removed []operatorv1.RenderedComponent
+	copies := map[string]bool{}
 	for _, obj := range objsToCreate {
 		key := client.ObjectKeyFromObject(obj)
+		rc := c.renderedComponent(obj)
 
 		// Pass in a DeepCopy so any modifications made by createOrUpdateObject won't be included
 		// if we need to retry the function
-		err := c.createOrUpdateObject(ctx, obj.DeepCopyObject().(client.Object), osType)
+		resourceVersion, drifted, err := c.createOrUpdateObject(ctx, obj.DeepCopyObject().(client.Object), osType, archs)
 		// If the error is a resource Conflict, try the update again
 		if err != nil && errors.IsConflict(err) {
 			cmpLog.WithValues("key", key, "conflict_message", err).Info("Failed to update object, retrying.")
-			err = c.createOrUpdateObject(ctx, obj, osType)
-			if err != nil {
-				return err
-			}
+			var retryDrifted bool
+			resourceVersion, retryDrifted, err = c.createOrUpdateObject(ctx, obj, osType, archs)
+			drifted = drifted || retryDrifted
+		}
+		rc.ResourceVersion = resourceVersion
+		if err == errObjectIgnored {
+			// The object is left as the user manages it, which is reported in the status.
+			rc.Ignored = true
 		} else if err != nil {
 			return err
+		} else if isCopy(obj) {
+			copies[copyKey(obj)] = drifted
 		}
 
 		// Keep track of some objects so we can report on their status.
@@ -218,9 +282,10 @@ func (c componentHandler) CreateOrUpdateOrDelete(ctx context.Context, component
 			daemonSets = append(daemonSets, key)
 		case *apps.StatefulSet:
 			statefulsets = append(statefulsets, key)
-		case *batchv1beta.CronJob:
+		case *batchv1.CronJob, *batchv1beta.CronJob:
 			cronJobs = append(cronJobs, key)
 		}
+		rendered = append(rendered, rc)
 
 		continue
 	}
@@ -230,6 +295,7 @@ func (c componentHandler) CreateOrUpdateOrDelete(ctx context.Context, component
 		status.AddStatefulSets(statefulsets)
 		status.AddCronJobs(cronJobs)
 	}
+	trackRenderedComponents(status, rendered, nil)
 
 	for _, obj := range objsToDelete {
 		err := c.client.Delete(ctx, obj)
@@ -239,6 +305,10 @@ func (c componentHandler) CreateOrUpdateOrDelete(ctx context.Context, component
 			return err
 		}
 
+		removed = append(removed, c.renderedComponent(obj))
+		if isCopy(obj) {
+			copies[copyKey(obj)] = false
+		}
 		key := client.ObjectKeyFromObject(obj)
 		if status != nil {
 			switch obj.(type) {
@@ -248,12 +318,15 @@ func (c componentHandler) CreateOrUpdateOrDelete(ctx context.Context, component
 				status.RemoveDaemonsets(key)
 			case *apps.StatefulSet:
 				status.RemoveStatefulSets(key)
-			case *batchv1beta.CronJob:
+			case *batchv1.CronJob, *batchv1beta.CronJob:
 				status.RemoveCronJobs(key)
 			}
 		}
 	}
 
+	trackRenderedComponents(status, nil, removed)
+	trackCopyDrift(status, copies)
+
 	cmpLog.V(1).Info("Done reconciling component")
 	// TODO Get each controller to explicitly call ReadyToMonitor on the status manager instead of doing it here.
 	if status != nil {
@@ -262,6 +335,199 @@ func (c componentHandler) CreateOrUpdateOrDelete(ctx context.Context, component
 	return nil
 }
 
+// renderedComponent returns the entry of the inventory of the objects that the operator renders for the object.
+func (c componentHandler) renderedComponent(obj client.Object) operatorv1.RenderedComponent {
+	rc := operatorv1.RenderedComponent{
+		Kind:      objectKind(obj),
+		Name:      obj.GetName(),
+		Namespace: obj.GetNamespace(),
+	}
+	if cr, ok := c.cr.(client.Object); ok {
+		rc.Owner = fmt.Sprintf("%s/%s", objectKind(cr), cr.GetName())
+	}
+	return rc
+}
+
+// trackRenderedComponents updates the inventory of the objects that the operator renders that the status manager
+// reports, if it keeps one.
+func trackRenderedComponents(sm status.StatusManager, rendered, removed []operatorv1.RenderedComponent) {
+	tracker, ok := sm.(status.RenderedComponentTracker)
+	if !ok {
+		return
+	}
+	if len(rendered) > 0 {
+		tracker.AddRenderedComponents(rendered...)
+	}
+	if len(removed) > 0 {
+		tracker.RemoveRenderedComponents(removed...)
+	}
+}
+
+// DependentComponent is a component of a set of components that are applied together, such as the parts of the log
+// storage, and that depend on each other.
+type DependentComponent struct {
+	Name      string
+	Component render.Component
+
+	// DependsOn are the names of the components of the set that the component depends on. The components that are not
+	// in the set are ignored.
+	DependsOn []string
+}
+
+// phaseComponent is a component whose objects have been rendered, limited to the objects of one phase of the
+// application of a set of components: either those that it creates or those that it deletes.
+type phaseComponent struct {
+	render.Component
+	objsToCreate []client.Object
+	objsToDelete []client.Object
+}
+
+func (c *phaseComponent) Objects() ([]client.Object, []client.Object) {
+	return c.objsToCreate, c.objsToDelete
+}
+
+// sortComponents returns the components in dependency order, with each component after the components that it depends
+// on, or an error if the components depend on each other.
+func sortComponents(components []DependentComponent) ([]DependentComponent, error) {
+	byName := map[string]DependentComponent{}
+	for _, c := range components {
+		byName[c.Name] = c
+	}
+
+	var sorted []DependentComponent
+	visiting, visited := map[string]bool{}, map[string]bool{}
+	var visit func(c DependentComponent) error
+	visit = func(c DependentComponent) error {
+		if visited[c.Name] {
+			return nil
+		}
+		if visiting[c.Name] {
+			return fmt.Errorf("component %s depends on itself", c.Name)
+		}
+		visiting[c.Name] = true
+		for _, name := range c.DependsOn {
+			if dep, ok := byName[name]; ok {
+				if err := visit(dep); err != nil {
+					return err
+				}
+			}
+		}
+		visited[c.Name] = true
+		sorted = append(sorted, c)
+		return nil
+	}
+	for _, c := range components {
+		if err := visit(c); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
+
+// CreateOrUpdateOrDeleteInOrder applies a set of components that depend on each other. The objects that the
+// components create are applied root-first, and the objects that they delete are deleted leaf-first, e.g. Kibana is
+// deleted before the Elasticsearch cluster that it depends on. A component isn't created when a component that it
+// depends on failed to be created, and isn't deleted when a component that depends on it failed to be deleted, since
+// applying it would only fail in turn. It returns the errors of the components that failed or were held back, by name.
+func CreateOrUpdateOrDeleteInOrder(ctx context.Context, handler ComponentHandler, components []DependentComponent, status status.StatusManager) (map[string]error, error) {
+	sorted, err := sortComponents(components)
+	if err != nil {
+		return nil, err
+	}
+
+	creates := make([]render.Component, len(sorted))
+	deletes := make([]render.Component, len(sorted))
+	for i, c := range sorted {
+		objsToCreate, objsToDelete := c.Component.Objects()
+		creates[i] = &phaseComponent{Component: c.Component, objsToCreate: objsToCreate}
+		deletes[i] = &phaseComponent{Component: c.Component, objsToDelete: objsToDelete}
+	}
+
+	errs := map[string]error{}
+	for i, c := range sorted {
+		for _, name := range c.DependsOn {
+			if _, failed := errs[name]; failed {
+				errs[c.Name] = fmt.Errorf("waiting for %s to be created", name)
+				break
+			}
+		}
+		if errs[c.Name] != nil {
+			continue
+		}
+		if err := handler.CreateOrUpdateOrDelete(ctx, creates[i], status); err != nil {
+			errs[c.Name] = err
+		}
+	}
+
+	deleteFailed := map[string]bool{}
+	for i := len(sorted) - 1; i >= 0; i-- {
+		c := sorted[i]
+		var blocked string
+		for _, dependent := range sorted[i+1:] {
+			for _, name := range dependent.DependsOn {
+				if name == c.Name && deleteFailed[dependent.Name] {
+					blocked = dependent.Name
+				}
+			}
+		}
+		if blocked != "" {
+			deleteFailed[c.Name] = true
+			if errs[c.Name] == nil {
+				errs[c.Name] = fmt.Errorf("waiting for %s to be deleted", blocked)
+			}
+			continue
+		}
+		if err := handler.CreateOrUpdateOrDelete(ctx, deletes[i], status); err != nil {
+			deleteFailed[c.Name] = true
+			if errs[c.Name] == nil {
+				errs[c.Name] = err
+			}
+		}
+	}
+	return errs, nil
+}
+
+// validateObjectBudgets returns an error if the number of objects, or the size of the data of any of the ConfigMaps and
+// secrets, e.g. of a trusted bundle with too many certificates, exceeds what the datastore accepts.
+func validateObjectBudgets(objs []client.Object) error {
+	if len(objs) > maxComponentObjects {
+		return fmt.Errorf("component rendered %d objects, which exceeds the limit of %d objects", len(objs), maxComponentObjects)
+	}
+
+	for _, obj := range objs {
+		var dataSize int
+		switch o := obj.(type) {
+		case *v1.ConfigMap:
+			for _, v := range o.Data {
+				dataSize += len(v)
+			}
+			for _, v := range o.BinaryData {
+				dataSize += len(v)
+			}
+		case *v1.Secret:
+			for _, v := range o.Data {
+				dataSize += len(v)
+			}
+			for _, v := range o.StringData {
+				dataSize += len(v)
+			}
+		}
+		if dataSize > maxObjectDataSize {
+			return fmt.Errorf("the data of %s %s is %d bytes, which exceeds the limit of %d bytes",
+				objectKind(obj), client.ObjectKeyFromObject(obj), dataSize, maxObjectDataSize)
+		}
+	}
+	return nil
+}
+
+// objectKind returns the kind of the object, falling back to its type when the TypeMeta is not set.
+func objectKind(obj client.Object) string {
+	if kind := obj.GetObjectKind().GroupVersionKind().Kind; kind != "" {
+		return kind
+	}
+	return reflect.TypeOf(obj).Elem().Name()
+}
+
 // mergeState returns the object to pass to Update given the current and desired object states.
 func mergeState(desired client.Object, current runtime.Object) client.Object {
 	currentMeta := current.(metav1.ObjectMetaAccessor).GetObjectMeta()
@@ -423,6 +689,8 @@ func modifyPodSpec(obj client.Object, f func(*v1.PodSpec)) {
 		f(&x.Spec.Template.Spec)
 	case *apps.StatefulSet:
 		f(&x.Spec.Template.Spec)
+	case *batchv1.CronJob:
+		f(&x.Spec.JobTemplate.Spec.Template.Spec)
 	case *batchv1beta.CronJob:
 		f(&x.Spec.JobTemplate.Spec.Template.Spec)
 	case *batchv1.Job:
@@ -447,10 +715,38 @@ func setImagePullPolicy(podSpec *v1.PodSpec) {
 	}
 }
 
-// ensureOSSchedulingRestrictions ensures that if obj is a type that creates pods and if osType is not OSTypeAny that a
+// restrictedArchitectures returns archs if a node of the cluster runs a CPU architecture that is not in archs, or nil
+// if the images can run on all the nodes. This way the pod templates are only changed, and the pods restarted, when the
+// architectures are actually restricted in the cluster.
+func (c componentHandler) restrictedArchitectures(ctx context.Context, archs []string) ([]string, error) {
+	if len(archs) == 0 {
+		return nil, nil
+	}
+	nodes := &v1.NodeList{}
+	if err := c.client.List(ctx, nodes); err != nil {
+		return nil, err
+	}
+	supported := map[string]bool{}
+	for _, arch := range archs {
+		supported[arch] = true
+	}
+	for _, node := range nodes.Items {
+		if arch, ok := node.Labels[scheduling.ArchLabel]; ok && !supported[arch] {
+			return archs, nil
+		}
+	}
+	return nil, nil
+}
+
+// ensureSchedulingRestrictions ensures that if obj is a type that creates pods and if osType is not OSTypeAny that a
 // node selector is set on the pod template for the "kubernetes.io/os" label to ensure that the pod is scheduled
-// on a node running an operating system as specified by osType.
-func ensureOSSchedulingRestrictions(obj client.Object, osType rmeta.OSType) {
+// on a node running an operating system as specified by osType. If archs is not empty, a node affinity for the
+// "kubernetes.io/arch" label is also required, so that the pod is scheduled on a node running one of the archs.
+func ensureSchedulingRestrictions(obj client.Object, osType rmeta.OSType, archs []string) {
+	modifyPodSpec(obj, func(podSpec *v1.PodSpec) {
+		scheduling.RequireArchitectures(podSpec, archs)
+	})
+
 	if osType == rmeta.OSTypeAny {
 		return
 	}
@@ -461,24 +757,20 @@ func ensureOSSchedulingRestrictions(obj client.Object, osType rmeta.OSType) {
 		// Prometheus operator types don't have a template spec which is of v1.PodSpec type.
 		// We can't add it to the podSpecs list and assign osType in the for loop below.
 		podSpec := &x.Spec
-		podSpec.NodeSelector = map[string]string{"kubernetes.io/os": string(osType)}
+		podSpec.NodeSelector = map[string]string{scheduling.OSLabel: string(osType)}
 		return
 	case *monitoringv1.Prometheus:
 		// Prometheus operator types don't have a template spec which is of v1.PodSpec type.
 		// We can't add it to the podSpecs list and assign osType in the for loop below.
 		podSpec := &x.Spec
-		podSpec.NodeSelector = map[string]string{"kubernetes.io/os": string(osType)}
+		podSpec.NodeSelector = map[string]string{scheduling.OSLabel: string(osType)}
 		return
 	}
 
 	// Handle objects that do use a v1.PodSpec.
-	f := func(podSpec *v1.PodSpec) {
-		if podSpec.NodeSelector == nil {
-			podSpec.NodeSelector = make(map[string]string)
-		}
-		podSpec.NodeSelector["kubernetes.io/os"] = string(osType)
-	}
-	modifyPodSpec(obj, f)
+	modifyPodSpec(obj, func(podSpec *v1.PodSpec) {
+		scheduling.RequireOS(podSpec, osType)
+	})
 }
 
 // setStandardSelectorAndLabels will set the k8s-app and app.kubernetes.io/name Labels on the podTemplates
@@ -17,6 +17,7 @@ package utils
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
 
@@ -41,12 +42,14 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	dto "github.com/prometheus/client_model/go"
 
 	operatorv1 "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/apis"
 	"github.com/tigera/operator/pkg/controller/status"
 	"github.com/tigera/operator/pkg/render"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/secret"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
@@ -402,6 +405,43 @@ var _ = Describe("Component handler tests", func() {
 		Expect(ui.Spec.Description).To(Equal("another test"))
 	})
 
+	It("does not apply any of the objects when one of them exceeds the size limits", func() {
+		fc := &fakeComponent{
+			supportedOSType: rmeta.OSTypeLinux,
+			objs: []client.Object{
+				&v1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: "small-cm", Namespace: "default"},
+					Data:       map[string]string{"key": "value"},
+				},
+				&v1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: "large-cm", Namespace: "default"},
+					Data:       map[string]string{"key": strings.Repeat("a", maxObjectDataSize+1)},
+				},
+			},
+		}
+
+		err := handler.CreateOrUpdateOrDelete(ctx, fc, sm)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("the data of ConfigMap default/large-cm is"))
+
+		cm := &v1.ConfigMap{}
+		err = c.Get(ctx, client.ObjectKey{Name: "small-cm", Namespace: "default"}, cm)
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("does not apply any of the objects when the component renders too many objects", func() {
+		fc := &fakeComponent{supportedOSType: rmeta.OSTypeLinux}
+		for i := 0; i <= maxComponentObjects; i++ {
+			fc.objs = append(fc.objs, &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("cm-%d", i), Namespace: "default"},
+			})
+		}
+
+		err := handler.CreateOrUpdateOrDelete(ctx, fc, sm)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("which exceeds the limit of 1000 objects"))
+	})
+
 	It("merges labels and reconciles only operator added labels", func() {
 		fc := &fakeComponent{
 			supportedOSType: rmeta.OSTypeLinux,
@@ -1022,6 +1062,108 @@ var _ = Describe("Component handler tests", func() {
 		},
 	)
 
+	It("requires the architectures that the images of a component are published for", func() {
+		ds := &apps.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-daemonset"},
+			Spec: apps.DaemonSetSpec{
+				Template: v1.PodTemplateSpec{
+					Spec: v1.PodSpec{
+						Affinity: &v1.Affinity{
+							NodeAffinity: &v1.NodeAffinity{
+								RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+									NodeSelectorTerms: []v1.NodeSelectorTerm{
+										{MatchExpressions: []v1.NodeSelectorRequirement{{Key: "zone", Operator: v1.NodeSelectorOpIn, Values: []string{"a"}}}},
+										{MatchExpressions: []v1.NodeSelectorRequirement{{Key: "kubernetes.io/arch", Operator: v1.NodeSelectorOpIn, Values: []string{"arm64"}}}},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+		for name, arch := range map[string]string{"node-amd64": "amd64", "node-arm64": "arm64"} {
+			Expect(c.Create(ctx, &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"kubernetes.io/arch": arch}}})).ShouldNot(HaveOccurred())
+		}
+		component := &fakeArchitectureRestrictedComponent{
+			fakeComponent: fakeComponent{supportedOSType: rmeta.OSTypeLinux, objs: []client.Object{ds}},
+			archs:         []string{"amd64"},
+		}
+		Expect(handler.CreateOrUpdateOrDelete(ctx, component, sm)).ShouldNot(HaveOccurred())
+
+		actual := &apps.DaemonSet{}
+		Expect(c.Get(ctx, client.ObjectKey{Name: "test-daemonset"}, actual)).ShouldNot(HaveOccurred())
+		Expect(actual.Spec.Template.Spec.NodeSelector).To(Equal(map[string]string{"kubernetes.io/os": "linux"}))
+		terms := actual.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+		Expect(terms).To(Equal([]v1.NodeSelectorTerm{
+			{MatchExpressions: []v1.NodeSelectorRequirement{
+				{Key: "zone", Operator: v1.NodeSelectorOpIn, Values: []string{"a"}},
+				{Key: "kubernetes.io/arch", Operator: v1.NodeSelectorOpIn, Values: []string{"amd64"}},
+			}},
+			// The requirement that is already set for the architectures is kept.
+			{MatchExpressions: []v1.NodeSelectorRequirement{{Key: "kubernetes.io/arch", Operator: v1.NodeSelectorOpIn, Values: []string{"arm64"}}}},
+		}))
+	})
+
+	It("does not require the architectures when all the nodes run one of them", func() {
+		Expect(c.Create(ctx, &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-amd64", Labels: map[string]string{"kubernetes.io/arch": "amd64"}}})).ShouldNot(HaveOccurred())
+		component := &fakeArchitectureRestrictedComponent{
+			fakeComponent: fakeComponent{
+				supportedOSType: rmeta.OSTypeLinux,
+				objs:            []client.Object{&apps.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: "test-daemonset"}}},
+			},
+			archs: []string{"amd64"},
+		}
+		Expect(handler.CreateOrUpdateOrDelete(ctx, component, sm)).ShouldNot(HaveOccurred())
+
+		actual := &apps.DaemonSet{}
+		Expect(c.Get(ctx, client.ObjectKey{Name: "test-daemonset"}, actual)).ShouldNot(HaveOccurred())
+		Expect(actual.Spec.Template.Spec.NodeSelector).To(Equal(map[string]string{"kubernetes.io/os": "linux"}))
+		Expect(actual.Spec.Template.Spec.Affinity).To(BeNil())
+	})
+
+	It("does not update the objects that the user labels as ignored and reports them in the status", func() {
+		cm := &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-cm",
+				Namespace: "default",
+				Labels:    map[string]string{IgnoreLabel: "true"},
+			},
+			Data: map[string]string{"key": "user"},
+		}
+		Expect(c.Create(ctx, cm)).NotTo(HaveOccurred())
+
+		fc := &fakeComponent{
+			supportedOSType: rmeta.OSTypeLinux,
+			objs: []client.Object{
+				&v1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-cm", Namespace: "default"},
+					Data:       map[string]string{"key": "operator"},
+				},
+				&v1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: "other-cm", Namespace: "default"},
+					Data:       map[string]string{"key": "operator"},
+				},
+			},
+		}
+		tracker := &fakeRenderedComponentTracker{StatusManager: sm}
+		Expect(handler.CreateOrUpdateOrDelete(ctx, fc, tracker)).NotTo(HaveOccurred())
+
+		actual := &v1.ConfigMap{}
+		Expect(c.Get(ctx, client.ObjectKey{Name: "test-cm", Namespace: "default"}, actual)).NotTo(HaveOccurred())
+		Expect(actual.Data).To(Equal(map[string]string{"key": "user"}))
+		Expect(c.Get(ctx, client.ObjectKey{Name: "other-cm", Namespace: "default"}, actual)).NotTo(HaveOccurred())
+		Expect(actual.Data).To(Equal(map[string]string{"key": "operator"}))
+
+		Expect(tracker.rendered).To(HaveLen(2))
+		Expect(tracker.rendered[0].Name).To(Equal("test-cm"))
+		Expect(tracker.rendered[0].Ignored).To(BeTrue())
+		Expect(tracker.rendered[1].Name).To(Equal("other-cm"))
+		Expect(tracker.rendered[1].Ignored).To(BeFalse())
+		Expect(c.Get(ctx, client.ObjectKey{Name: "other-cm", Namespace: "default"}, actual)).NotTo(HaveOccurred())
+		Expect(tracker.rendered[1].ResourceVersion).To(Equal(actual.ResourceVersion))
+	})
+
 	It("allows you to replace a secret if the types change", func() {
 		// Please note that a fake client does not behave exactly as it would on K8s:
 		// - A secret without a type in a real cluster automatically becomes type Opaque
@@ -1048,6 +1190,87 @@ var _ = Describe("Component handler tests", func() {
 		Expect(secret.Type).To(Equal(corev1.SecretTypeTLS))
 	})
 
+	It("repairs the copies of secrets that were modified and reports them in the status", func() {
+		source := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-secret", Namespace: common.OperatorNamespace()},
+			Data:       map[string][]byte{"tls.crt": []byte("cert"), "tls.key": []byte("key")},
+		}
+		fc := &fakeComponent{
+			supportedOSType: rmeta.OSTypeLinux,
+			objs:            secret.ToRuntimeObjects(secret.CopyToNamespace("component-ns", source)...),
+		}
+		tracker := &fakeCopyDriftTracker{StatusManager: sm}
+		repairs := func() float64 {
+			m := &dto.Metric{}
+			Expect(copyDriftRepairs.WithLabelValues("Secret", "component-ns", "my-secret", common.OperatorNamespace()+"/my-secret").Write(m)).NotTo(HaveOccurred())
+			return m.GetCounter().GetValue()
+		}
+		before := repairs()
+
+		Expect(handler.CreateOrUpdateOrDelete(ctx, fc, tracker)).NotTo(HaveOccurred())
+		secretCopy := &corev1.Secret{}
+		Expect(c.Get(ctx, client.ObjectKey{Name: "my-secret", Namespace: "component-ns"}, secretCopy)).NotTo(HaveOccurred())
+		Expect(secretCopy.Annotations).To(HaveKeyWithValue(rmeta.CopySourceAnnotation, common.OperatorNamespace()+"/my-secret"))
+		Expect(secretCopy.Annotations).To(HaveKey(rmeta.CopyHashAnnotation))
+		Expect(tracker.copies).To(Equal(map[string]bool{"Secret component-ns/my-secret": false}))
+
+		By("truncating the copy")
+		secretCopy.Data = map[string][]byte{"tls.crt": []byte("cert")}
+		Expect(c.Update(ctx, secretCopy)).NotTo(HaveOccurred())
+		Expect(handler.CreateOrUpdateOrDelete(ctx, fc, tracker)).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, client.ObjectKey{Name: "my-secret", Namespace: "component-ns"}, secretCopy)).NotTo(HaveOccurred())
+		Expect(secretCopy.Data).To(Equal(source.Data))
+		Expect(tracker.copies).To(Equal(map[string]bool{"Secret component-ns/my-secret": true}))
+		Expect(repairs()).To(Equal(before + 1))
+
+		By("changing the source, which is not drift")
+		source.Data["tls.key"] = []byte("new-key")
+		fc.objs = secret.ToRuntimeObjects(secret.CopyToNamespace("component-ns", source)...)
+		Expect(handler.CreateOrUpdateOrDelete(ctx, fc, tracker)).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, client.ObjectKey{Name: "my-secret", Namespace: "component-ns"}, secretCopy)).NotTo(HaveOccurred())
+		Expect(secretCopy.Data).To(Equal(source.Data))
+		Expect(tracker.copies).To(Equal(map[string]bool{"Secret component-ns/my-secret": false}))
+		Expect(repairs()).To(Equal(before + 1))
+	})
+
+	It("does not apply a component whose namespace is being deleted and reports what blocks the deletion", func() {
+		now := metav1.Now()
+		Expect(c.Create(ctx, &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: "tigera-kibana", DeletionTimestamp: &now, Finalizers: []string{"example.com/cleanup"}},
+			Status: corev1.NamespaceStatus{
+				Phase: corev1.NamespaceTerminating,
+				Conditions: []corev1.NamespaceCondition{{
+					Type:    corev1.NamespaceFinalizersRemaining,
+					Status:  corev1.ConditionTrue,
+					Message: "Some content in the namespace has finalizers remaining: kibana.k8s.elastic.co/finalizer in 1 resource instances",
+				}},
+			},
+		})).NotTo(HaveOccurred())
+
+		fc := &fakeComponent{
+			supportedOSType: rmeta.OSTypeLinux,
+			objs: []client.Object{
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tigera-kibana"}},
+				&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "kibana-config", Namespace: "tigera-kibana"}},
+			},
+		}
+		err := handler.CreateOrUpdateOrDelete(ctx, fc, sm)
+		Expect(err).To(HaveOccurred())
+		Expect(err).To(BeAssignableToTypeOf(&NamespaceTerminatingError{}))
+		Expect(err.Error()).To(ContainSubstring("kibana.k8s.elastic.co/finalizer"))
+		Expect(err.Error()).To(ContainSubstring("example.com/cleanup"))
+		Expect(c.Get(ctx, client.ObjectKey{Name: "kibana-config", Namespace: "tigera-kibana"}, &corev1.ConfigMap{})).To(HaveOccurred())
+
+		event := &corev1.Event{}
+		Expect(c.Get(ctx, client.ObjectKey{Name: "tigera-kibana.terminating", Namespace: common.OperatorNamespace()}, event)).NotTo(HaveOccurred())
+		Expect(event.Reason).To(Equal(namespaceTerminatingReason))
+		Expect(event.Count).To(Equal(int32(1)))
+
+		Expect(handler.CreateOrUpdateOrDelete(ctx, fc, sm)).To(HaveOccurred())
+		Expect(c.Get(ctx, client.ObjectKey{Name: "tigera-kibana.terminating", Namespace: common.OperatorNamespace()}, event)).NotTo(HaveOccurred())
+		Expect(event.Count).To(Equal(int32(2)))
+	})
+
 	Context("common labels and labelselector", func() {
 		It("updates daemonsets", func() {
 			fc := &fakeComponent{
@@ -1314,9 +1537,102 @@ var _ = Describe("Mocked client Component handler tests", func() {
 	})
 })
 
+var _ = Describe("Ordered component tests", func() {
+	var handler *recordingHandler
+
+	BeforeEach(func() {
+		handler = &recordingHandler{failures: map[string]bool{}}
+	})
+
+	configMap := func(name string) client.Object {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+	}
+
+	// components returns a set of components where b and c depend on a, listed out of dependency order.
+	components := func(create bool) []DependentComponent {
+		component := func(name string) *fakeComponent {
+			if create {
+				return &fakeComponent{objs: []client.Object{configMap(name)}}
+			}
+			return &fakeComponent{objsToDelete: []client.Object{configMap(name)}}
+		}
+		return []DependentComponent{
+			{Name: "c", Component: component("c"), DependsOn: []string{"a"}},
+			{Name: "a", Component: component("a")},
+			{Name: "b", Component: component("b"), DependsOn: []string{"a", "missing"}},
+		}
+	}
+
+	It("should create the components root-first", func() {
+		errs, err := CreateOrUpdateOrDeleteInOrder(context.Background(), handler, components(true), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(errs).To(BeEmpty())
+		Expect(handler.applied).To(Equal([]string{"create a", "create c", "create b"}))
+	})
+
+	It("should delete the components leaf-first", func() {
+		errs, err := CreateOrUpdateOrDeleteInOrder(context.Background(), handler, components(false), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(errs).To(BeEmpty())
+		Expect(handler.applied).To(Equal([]string{"delete b", "delete c", "delete a"}))
+	})
+
+	It("should not create the dependents of a component that failed to be created", func() {
+		handler.failures["a"] = true
+		errs, err := CreateOrUpdateOrDeleteInOrder(context.Background(), handler, components(true), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(errs).To(HaveLen(3))
+		Expect(errs["b"]).To(MatchError("waiting for a to be created"))
+		Expect(handler.applied).To(Equal([]string{"create a"}))
+	})
+
+	It("should not delete the dependencies of a component that failed to be deleted", func() {
+		handler.failures["c"] = true
+		errs, err := CreateOrUpdateOrDeleteInOrder(context.Background(), handler, components(false), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(errs).To(HaveLen(2))
+		Expect(errs["a"]).To(MatchError("waiting for c to be deleted"))
+		Expect(handler.applied).To(Equal([]string{"delete b", "delete c"}))
+	})
+
+	It("should return an error when the components depend on each other", func() {
+		_, err := CreateOrUpdateOrDeleteInOrder(context.Background(), handler, []DependentComponent{
+			{Name: "a", Component: &fakeComponent{}, DependsOn: []string{"b"}},
+			{Name: "b", Component: &fakeComponent{}, DependsOn: []string{"a"}},
+		}, nil)
+		Expect(err).To(HaveOccurred())
+		Expect(handler.applied).To(BeEmpty())
+	})
+})
+
+// A fake component handler that records the objects that it creates and deletes, and fails to apply the objects
+// named in failures.
+type recordingHandler struct {
+	applied  []string
+	failures map[string]bool
+}
+
+func (h *recordingHandler) CreateOrUpdateOrDelete(ctx context.Context, component render.Component, status status.StatusManager) error {
+	objsToCreate, objsToDelete := component.Objects()
+	for _, obj := range objsToCreate {
+		h.applied = append(h.applied, "create "+obj.GetName())
+		if h.failures[obj.GetName()] {
+			return fmt.Errorf("failed to create %s", obj.GetName())
+		}
+	}
+	for _, obj := range objsToDelete {
+		h.applied = append(h.applied, "delete "+obj.GetName())
+		if h.failures[obj.GetName()] {
+			return fmt.Errorf("failed to delete %s", obj.GetName())
+		}
+	}
+	return nil
+}
+
 // A fake component that only returns ready and always creates the "test-namespace" Namespace.
 type fakeComponent struct {
 	objs            []client.Object
+	objsToDelete    []client.Object
 	supportedOSType rmeta.OSType
 }
 
@@ -1329,13 +1645,48 @@ func (c *fakeComponent) ResolveImages(is *operatorv1.ImageSet) error {
 }
 
 func (c *fakeComponent) Objects() ([]client.Object, []client.Object) {
-	return c.objs, nil
+	return c.objs, c.objsToDelete
 }
 
 func (c *fakeComponent) SupportedOSType() rmeta.OSType {
 	return c.supportedOSType
 }
 
+// A fake component whose images are only published for some architectures.
+type fakeArchitectureRestrictedComponent struct {
+	fakeComponent
+	archs []string
+}
+
+func (c *fakeArchitectureRestrictedComponent) SupportedArchitectures() []string {
+	return c.archs
+}
+
+// A status manager that records the objects that are reported as rendered.
+type fakeRenderedComponentTracker struct {
+	status.StatusManager
+	rendered []operatorv1.RenderedComponent
+}
+
+func (t *fakeRenderedComponentTracker) AddRenderedComponents(rcs ...operatorv1.RenderedComponent) {
+	t.rendered = append(t.rendered, rcs...)
+}
+
+func (t *fakeRenderedComponentTracker) RemoveRenderedComponents(rcs ...operatorv1.RenderedComponent) {
+}
+
+type fakeCopyDriftTracker struct {
+	status.StatusManager
+	copies map[string]bool
+}
+
+func (t *fakeCopyDriftTracker) SetCopyDrift(name string, drifted bool) {
+	if t.copies == nil {
+		t.copies = map[string]bool{}
+	}
+	t.copies[name] = drifted
+}
+
 type mockReturn struct {
 	Method string
 	Return interface{}
@@ -0,0 +1,107 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/tigera/operator/pkg/controller/status"
+	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+)
+
+// copyDriftRepairs counts the copies of secrets and ConfigMaps that the operator found modified since it last wrote
+// them, and overwrote with the data of their source.
+var copyDriftRepairs = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "tigera_operator_copy_drift_repairs_total",
+		Help: "Number of times the operator repaired a copy of a secret or ConfigMap whose data no longer matched the checksum of the data that the operator wrote.",
+	},
+	[]string{"kind", "namespace", "name", "source"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(copyDriftRepairs)
+}
+
+// isCopy returns whether the object is a copy of a secret or ConfigMap.
+func isCopy(obj client.Object) bool {
+	switch obj.(type) {
+	case *v1.Secret, *v1.ConfigMap:
+		_, ok := obj.GetAnnotations()[rmeta.CopySourceAnnotation]
+		return ok
+	}
+	return false
+}
+
+// copyDataHash returns the hash of the data of a secret or ConfigMap.
+func copyDataHash(obj client.Object) string {
+	switch o := obj.(type) {
+	case *v1.Secret:
+		data := map[string][]byte{}
+		for k, v := range o.Data {
+			data[k] = v
+		}
+		// The API server merges the string data into the data.
+		for k, v := range o.StringData {
+			data[k] = []byte(v)
+		}
+		return rmeta.AnnotationHash(data)
+	case *v1.ConfigMap:
+		return rmeta.AnnotationHash([]interface{}{o.Data, o.BinaryData})
+	}
+	return ""
+}
+
+// setCopyHash annotates a copy of a secret or ConfigMap with the hash of its data before it is written.
+func setCopyHash(obj client.Object) {
+	annotations := obj.GetAnnotations()
+	annotations[rmeta.CopyHashAnnotation] = copyDataHash(obj)
+	obj.SetAnnotations(annotations)
+}
+
+// copyDrifted returns whether the data of the copy in the cluster no longer matches the hash that the operator
+// annotated it with when it last wrote it, i.e. whether it was modified or truncated by someone else since. Copies
+// written before the operator annotated them are never reported.
+func copyDrifted(current client.Object) bool {
+	written := current.GetAnnotations()[rmeta.CopyHashAnnotation]
+	return written != "" && copyDataHash(current) != written
+}
+
+// recordCopyRepair counts the repair of a copy that drifted from its source.
+func recordCopyRepair(obj client.Object) {
+	copyDriftRepairs.WithLabelValues(objectKind(obj), obj.GetNamespace(), obj.GetName(), obj.GetAnnotations()[rmeta.CopySourceAnnotation]).Inc()
+}
+
+// copyKey returns the key that identifies a copy in the status.
+func copyKey(obj client.Object) string {
+	return fmt.Sprintf("%s %s/%s", objectKind(obj), obj.GetNamespace(), obj.GetName())
+}
+
+// trackCopyDrift reports whether the copies were found drifted from their source to the status manager, if it reports
+// drift.
+func trackCopyDrift(sm status.StatusManager, copies map[string]bool) {
+	tracker, ok := sm.(status.CopyDriftTracker)
+	if !ok {
+		return
+	}
+	for name, drifted := range copies {
+		tracker.SetCopyDrift(name, drifted)
+	}
+}
@@ -26,6 +26,7 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/common"
 )
 
 var log = logf.Log.WithName("discovery")
@@ -139,6 +140,42 @@ func AutoDiscoverProvider(ctx context.Context, clientset kubernetes.Interface) (
 	return operatorv1.ProviderNone, nil
 }
 
+const (
+	// capiClusterNameAnnotation is set by Cluster API on the nodes of the clusters that it provisions.
+	capiClusterNameAnnotation = "cluster.x-k8s.io/cluster-name"
+	// capiNodeSampleSize is the number of nodes that are checked for the annotations of Cluster API.
+	capiNodeSampleSize = 20
+)
+
+// capiProviderIDSchemes are the infrastructure providers of Cluster API by the scheme of the provider IDs that they
+// set on the nodes.
+var capiProviderIDSchemes = map[string]common.InfrastructureProvider{
+	"aws":     common.InfrastructureProviderAWS,
+	"azure":   common.InfrastructureProviderAzure,
+	"vsphere": common.InfrastructureProviderVSphere,
+}
+
+// AutoDiscoverInfrastructureProvider returns the infrastructure provider of the cluster if it was provisioned by
+// Cluster API. Cluster API annotates the nodes of the clusters that it provisions with the name of their Cluster, and
+// the infrastructure provider is identified by the scheme of the provider IDs of the nodes. It returns
+// InfrastructureProviderNone if the cluster wasn't provisioned by Cluster API or the provider isn't known.
+func AutoDiscoverInfrastructureProvider(ctx context.Context, c kubernetes.Interface) (common.InfrastructureProvider, error) {
+	nodes, err := c.CoreV1().Nodes().List(ctx, metav1.ListOptions{Limit: capiNodeSampleSize})
+	if err != nil {
+		return common.InfrastructureProviderNone, err
+	}
+	for _, n := range nodes.Items {
+		if _, ok := n.Annotations[capiClusterNameAnnotation]; !ok {
+			continue
+		}
+		scheme := strings.SplitN(n.Spec.ProviderID, "://", 2)[0]
+		if provider, ok := capiProviderIDSchemes[scheme]; ok {
+			return provider, nil
+		}
+	}
+	return common.InfrastructureProviderNone, nil
+}
+
 // autodetectFromGroup auto detects the platform based on the API groups that are present.
 func autodetectFromGroup(c kubernetes.Interface) ([]operatorv1.Provider, error) {
 	// List of detected providers, for detecting conflicts.
@@ -229,3 +266,40 @@ func SupportsPodSecurityPolicies(c kubernetes.Interface) (bool, error) {
 	}
 	return false, nil
 }
+
+// SupportsBatchV1CronJobs returns true if the cluster serves CronJobs from the batch/v1 API, and false otherwise.
+// batch/v1 CronJobs are available from Kubernetes v1.21, and the batch/v1beta1 API that older clusters serve them from
+// was removed in Kubernetes v1.25.
+func SupportsBatchV1CronJobs(c kubernetes.Interface) (bool, error) {
+	resources, err := c.Discovery().ServerResourcesForGroupVersion("batch/v1")
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	for _, r := range resources.APIResources {
+		if r.Kind == "CronJob" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// SupportsVerticalPodAutoscalers returns true if the cluster contains the autoscaling.k8s.io/v1 VerticalPodAutoscaler
+// API, which is installed with the vertical pod autoscaler, and false otherwise.
+func SupportsVerticalPodAutoscalers(c kubernetes.Interface) (bool, error) {
+	resources, err := c.Discovery().ServerResourcesForGroupVersion("autoscaling.k8s.io/v1")
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	for _, r := range resources.APIResources {
+		if r.Kind == "VerticalPodAutoscaler" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -27,6 +27,7 @@ import (
 	"k8s.io/client-go/kubernetes/fake"
 
 	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/common"
 )
 
 var _ = Describe("provider discovery", func() {
@@ -139,4 +140,27 @@ var _ = Describe("provider discovery", func() {
 				operatorv1.ProviderRKE2})))
 		Expect(p).To(Equal(operatorv1.ProviderNone))
 	})
+
+	It("should detect the infrastructure provider of a Cluster API cluster from the provider ID of its nodes", func() {
+		c := fake.NewSimpleClientset(&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "node1",
+				Annotations: map[string]string{"cluster.x-k8s.io/cluster-name": "workload"},
+			},
+			Spec: corev1.NodeSpec{ProviderID: "aws:///us-east-1a/i-0123456789"},
+		})
+		p, e := AutoDiscoverInfrastructureProvider(context.Background(), c)
+		Expect(e).To(BeNil())
+		Expect(p).To(Equal(common.InfrastructureProviderAWS))
+	})
+
+	It("should not detect an infrastructure provider for nodes that are not managed by Cluster API", func() {
+		c := fake.NewSimpleClientset(&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node1"},
+			Spec:       corev1.NodeSpec{ProviderID: "aws:///us-east-1a/i-0123456789"},
+		})
+		p, e := AutoDiscoverInfrastructureProvider(context.Background(), c)
+		Expect(e).To(BeNil())
+		Expect(p).To(Equal(common.InfrastructureProviderNone))
+	})
 })
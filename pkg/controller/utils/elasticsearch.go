@@ -22,6 +22,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/olivere/elastic/v7"
@@ -44,6 +48,91 @@ const (
 	ElasticConnRetryInterval     = "500ms"
 )
 
+const (
+	// IngestLatencyPipelineName is the name of the ingest pipeline that records when flow and DNS logs are indexed,
+	// and how long after they were written on the node that was.
+	IngestLatencyPipelineName = "tigera_ingest_latency"
+	ingestLatencyTemplateName = "tigera_ingest_latency"
+)
+
+const (
+	// SnapshotRepositoryName is the name of the Elasticsearch snapshot repository that the snapshots of the log indices
+	// are stored in.
+	SnapshotRepositoryName = "tigera-snapshots"
+	// SnapshotPolicyName is the name of the snapshot lifecycle management policy that takes the snapshots of the log
+	// indices.
+	SnapshotPolicyName      = "tigera-snapshots"
+	defaultSnapshotSchedule = "0 30 1 * * ?"
+)
+
+// IngestLatencyIndexPatterns are the index patterns of the logs that the ingestion latency is measured for, by log type.
+var IngestLatencyIndexPatterns = map[string]string{
+	"flows": "tigera_secure_ee_flows*",
+	"dns":   "tigera_secure_ee_dns*",
+}
+
+// ingestLatencyScript computes the ingestion latency of a log from the time at which it was indexed and its end_time,
+// which is when the log was written on the node. The end_time of flow logs is in epoch seconds, and that of DNS logs is
+// an RFC3339 timestamp.
+const ingestLatencyScript = `if (ctx.end_time == null) { return; }
+long indexed = ZonedDateTime.parse(ctx.ingest_timestamp).toInstant().toEpochMilli();
+long collected;
+if (ctx.end_time instanceof Number) {
+  collected = ((Number) ctx.end_time).longValue() * 1000L;
+} else {
+  collected = ZonedDateTime.parse(ctx.end_time.toString()).toInstant().toEpochMilli();
+}
+ctx.ingest_latency_ms = Math.max(0L, indexed - collected);`
+
+var ingestLatencyMappings = map[string]interface{}{
+	"properties": map[string]interface{}{
+		"ingest_timestamp":  map[string]interface{}{"type": "date"},
+		"ingest_latency_ms": map[string]interface{}{"type": "long"},
+	},
+}
+
+// IngestLatency is the distribution of the ingestion latency of the logs indexed in a time window.
+type IngestLatency struct {
+	Count uint64
+	Sum   time.Duration
+	// Buckets are the cumulative counts of the logs by the upper bound of their latency in seconds.
+	Buckets map[float64]uint64
+	// P99 is the 99th percentile of the latency, or zero if no logs were indexed.
+	P99 time.Duration
+}
+
+// ClusterHealth is the health of the Elasticsearch cluster and the number of its shards that are moving or unassigned.
+type ClusterHealth struct {
+	// Status is green, yellow or red.
+	Status             string
+	RelocatingShards   int
+	InitializingShards int
+	UnassignedShards   int
+}
+
+// StorageUsage is the disk usage of the Elasticsearch data nodes, and the storage taken by the logs of each type that
+// were written in the last day.
+type StorageUsage struct {
+	CapacityBytes int64
+	UsedBytes     int64
+	// IngestedBytesPerDay is the storage, including replicas, taken by the logs written in the last day, by log type.
+	IngestedBytesPerDay map[string]int64
+}
+
+// storageUsageIndex is an index pattern of logs, and the field of the time at which a log was written.
+type storageUsageIndex struct {
+	pattern   string
+	timeField string
+}
+
+// storageUsageIndices are the indices whose daily ingest is estimated, by log type. They take up most of the storage.
+var storageUsageIndices = map[string]storageUsageIndex{
+	"flows": {pattern: "tigera_secure_ee_flows*", timeField: "end_time"},
+	"dns":   {pattern: "tigera_secure_ee_dns*", timeField: "end_time"},
+	"l7":    {pattern: "tigera_secure_ee_l7*", timeField: "end_time"},
+	"audit": {pattern: "tigera_secure_ee_audit_*", timeField: "requestReceivedTimestamp"},
+}
+
 type Policy struct {
 	Phases struct {
 		Hot struct {
@@ -51,6 +140,7 @@ type Policy struct {
 				Rollover struct {
 					MaxSize string `json:"max_size"`
 					MaxAge  string `json:"max_age"`
+					MaxDocs int64  `json:"max_docs"`
 				}
 			}
 		}
@@ -63,6 +153,7 @@ type Policy struct {
 type policyDetail struct {
 	rolloverAge  string
 	rolloverSize string
+	rolloverDocs int64
 	deleteAge    string
 	policy       map[string]interface{}
 }
@@ -106,6 +197,22 @@ type ElasticsearchClientCreator func(client client.Client, ctx context.Context,
 
 type ElasticClient interface {
 	SetILMPolicies(context.Context, *operatorv1.LogStorage) error
+	SetTenantRoles(context.Context, *operatorv1.LogStorage) error
+	SetKibanaSpaceRoles(context.Context, *operatorv1.LogStorage) error
+	SetRole(ctx context.Context, name string, role map[string]interface{}) error
+	SetTenantUsers(ctx context.Context, ls *operatorv1.LogStorage, passwords map[string]string) error
+	SetUser(ctx context.Context, username, password string, roles []string) error
+	DeleteUser(ctx context.Context, username string) error
+	SetReadOnlyAccessUsers(ctx context.Context, passwords map[string]string) error
+	SetIngestLatencyPipeline(ctx context.Context, enabled bool) error
+	IngestLatency(ctx context.Context, indexPattern string, window time.Duration, bounds []float64) (*IngestLatency, error)
+	ClusterHealth(ctx context.Context) (*ClusterHealth, error)
+	SetSnapshotPolicy(context.Context, *operatorv1.LogStorage) error
+	SetDiskWatermarks(context.Context, *operatorv1.LogStorage) error
+	LastFlowLogTimes(ctx context.Context) (map[string]time.Time, error)
+	StorageUsage(ctx context.Context) (*StorageUsage, error)
+	NodeDiskUsage(ctx context.Context) (map[string]int, error)
+	DeleteIndex(ctx context.Context, index string) error
 }
 
 type esClient struct {
@@ -153,11 +260,1024 @@ func (es *esClient) SetILMPolicies(ctx context.Context, ls *operatorv1.LogStorag
 	return es.createOrUpdatePolicies(ctx, policyList)
 }
 
+// SetTenantRoles creates or updates the Elasticsearch roles of the tenant roles and the tenants in LogStorage, and
+// deletes the roles of the tenant roles and the tenants that were removed from it. Roles that are up to date are left
+// as they are.
+func (es *esClient) SetTenantRoles(ctx context.Context, ls *operatorv1.LogStorage) error {
+	desired := map[string]map[string]interface{}{}
+	for _, role := range ls.Spec.TenantRoles {
+		desired[render.ElasticsearchTenantRolePrefix+role.Name] = buildTenantRole(role)
+	}
+	for _, tenant := range ls.Spec.Tenants() {
+		desired[render.ElasticsearchTenantRolePrefix+tenant.Name] = buildTenancyRole(tenant)
+	}
+
+	res, err := es.client.PerformRequest(ctx, elastic.PerformRequestOptions{Method: "GET", Path: "/_security/role"})
+	if err != nil {
+		return err
+	}
+	existing := map[string]json.RawMessage{}
+	if err := json.Unmarshal(res.Body, &existing); err != nil {
+		return err
+	}
+	for name := range existing {
+		if _, ok := desired[name]; ok || !strings.HasPrefix(name, render.ElasticsearchTenantRolePrefix) {
+			continue
+		}
+		if _, err := es.client.XPackSecurityDeleteRole(name).Do(ctx); err != nil && !elastic.IsNotFound(err) {
+			return err
+		}
+	}
+
+	for name, role := range desired {
+		if current, ok := existing[name]; ok {
+			upToDate, err := roleUpToDate(current, role)
+			if err != nil {
+				return err
+			}
+			if upToDate {
+				continue
+			}
+		}
+		if _, err := es.client.XPackSecurityPutRole(name).Body(role).Do(ctx); err != nil {
+			log.Error(err, "Error applying tenant role", "role", name)
+			return err
+		}
+	}
+	return nil
+}
+
+// SetKibanaSpaceRoles creates or updates the Elasticsearch roles and role mappings that grant the OIDC groups of the
+// Kibana spaces in LogStorage access to their space, and deletes those of the spaces that were removed from it. Roles
+// and role mappings that are up to date are left as they are.
+func (es *esClient) SetKibanaSpaceRoles(ctx context.Context, ls *operatorv1.LogStorage) error {
+	desiredRoles := map[string]map[string]interface{}{}
+	desiredMappings := map[string]map[string]interface{}{}
+	for _, space := range render.LogStorageKibanaSpaces(ls) {
+		desiredRoles[render.KibanaSpaceRolePrefix+space.ID] = buildKibanaSpaceRole(space)
+		desiredMappings[render.KibanaSpaceRolePrefix+space.ID] = buildKibanaSpaceRoleMapping(space)
+	}
+
+	// The role mappings are applied after the roles and deleted before them, so that they never refer to a missing
+	// role.
+	existingMappings, err := es.getNamedObjects(ctx, "/_security/role_mapping")
+	if err != nil {
+		return err
+	}
+	if err := es.deleteStaleSecurityObjects(ctx, "/_security/role_mapping", existingMappings, desiredMappings); err != nil {
+		return err
+	}
+	existingRoles, err := es.getNamedObjects(ctx, "/_security/role")
+	if err != nil {
+		return err
+	}
+	if err := es.deleteStaleSecurityObjects(ctx, "/_security/role", existingRoles, desiredRoles); err != nil {
+		return err
+	}
+	if err := es.putChangedObjects(ctx, "/_security/role", existingRoles, desiredRoles); err != nil {
+		log.Error(err, "Error applying Kibana space roles")
+		return err
+	}
+	if err := es.putChangedObjects(ctx, "/_security/role_mapping", existingMappings, desiredMappings); err != nil {
+		log.Error(err, "Error applying Kibana space role mappings")
+		return err
+	}
+	return nil
+}
+
+// getNamedObjects returns the objects at the path of the API, e.g. roles, role mappings, ingest pipelines or index
+// templates, keyed by their names. There are none if the path isn't found.
+func (es *esClient) getNamedObjects(ctx context.Context, path string) (map[string]json.RawMessage, error) {
+	res, err := es.client.PerformRequest(ctx, elastic.PerformRequestOptions{Method: "GET", Path: path})
+	if err != nil {
+		if elastic.IsNotFound(err) {
+			// The role mapping API returns 404 when there are no role mappings, the other APIs when the requested
+			// object doesn't exist.
+			return map[string]json.RawMessage{}, nil
+		}
+		return nil, err
+	}
+	existing := map[string]json.RawMessage{}
+	if err := json.Unmarshal(res.Body, &existing); err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+// deleteStaleSecurityObjects deletes the roles or role mappings of the Kibana spaces that aren't desired.
+func (es *esClient) deleteStaleSecurityObjects(ctx context.Context, path string, existing map[string]json.RawMessage, desired map[string]map[string]interface{}) error {
+	for name := range existing {
+		if _, ok := desired[name]; ok || !strings.HasPrefix(name, render.KibanaSpaceRolePrefix) {
+			continue
+		}
+		_, err := es.client.PerformRequest(ctx, elastic.PerformRequestOptions{Method: "DELETE", Path: path + "/" + name})
+		if err != nil && !elastic.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// putChangedObjects creates or updates the desired objects at the path of the API that aren't up to date.
+func (es *esClient) putChangedObjects(ctx context.Context, path string, existing map[string]json.RawMessage, desired map[string]map[string]interface{}) error {
+	for name, obj := range desired {
+		if current, ok := existing[name]; ok {
+			upToDate, err := roleUpToDate(current, obj)
+			if err != nil {
+				return err
+			}
+			if upToDate {
+				continue
+			}
+		}
+		if _, err := es.client.PerformRequest(ctx, elastic.PerformRequestOptions{Method: "PUT", Path: path + "/" + name, Body: obj}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetRole creates or updates the Elasticsearch role, unless it is up to date.
+func (es *esClient) SetRole(ctx context.Context, name string, role map[string]interface{}) error {
+	return es.putIfChanged(ctx, "/_security/role", name, role)
+}
+
+// putIfChanged creates or updates the named object at the path of the API, unless it is up to date.
+func (es *esClient) putIfChanged(ctx context.Context, path, name string, obj map[string]interface{}) error {
+	existing, err := es.getNamedObjects(ctx, path+"/"+name)
+	if err != nil {
+		return err
+	}
+	return es.putChangedObjects(ctx, path, existing, map[string]map[string]interface{}{name: obj})
+}
+
+// deleteIfExists deletes the object at the path of the API, if it exists.
+func (es *esClient) deleteIfExists(ctx context.Context, path string) error {
+	existing, err := es.getNamedObjects(ctx, path)
+	if err != nil || len(existing) == 0 {
+		return err
+	}
+	_, err = es.client.PerformRequest(ctx, elastic.PerformRequestOptions{Method: "DELETE", Path: path})
+	if err != nil && !elastic.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// roleUpToDate returns whether the role or role mapping in Elasticsearch has all the fields of the desired one with
+// their values. Elasticsearch returns them with the defaults of the fields that aren't set, so the fields that are only
+// in the current one are ignored.
+func roleUpToDate(current json.RawMessage, desired map[string]interface{}) (bool, error) {
+	var currentRole interface{}
+	if err := json.Unmarshal(current, &currentRole); err != nil {
+		return false, err
+	}
+	// Round trip the desired role so that its values have the same types as those of the current role.
+	raw, err := json.Marshal(desired)
+	if err != nil {
+		return false, err
+	}
+	var desiredRole interface{}
+	if err := json.Unmarshal(raw, &desiredRole); err != nil {
+		return false, err
+	}
+	return jsonContains(currentRole, desiredRole), nil
+}
+
+// jsonContains returns whether the decoded JSON value has all the fields of the desired value, recursively.
+func jsonContains(value, desired interface{}) bool {
+	switch d := desired.(type) {
+	case map[string]interface{}:
+		v, ok := value.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for k, dv := range d {
+			if !jsonContains(v[k], dv) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		v, ok := value.([]interface{})
+		if !ok || len(v) != len(d) {
+			return false
+		}
+		for i := range d {
+			if !jsonContains(v[i], d[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return value == desired
+	}
+}
+
+// SetTenantUsers creates or updates the Elasticsearch users of the tenants in LogStorage with the role of their tenant
+// and the given passwords, keyed by the names of the tenants, and deletes the users of the tenants that were removed
+// from it.
+func (es *esClient) SetTenantUsers(ctx context.Context, ls *operatorv1.LogStorage, passwords map[string]string) error {
+	desired := map[string]string{}
+	for _, tenant := range ls.Spec.Tenants() {
+		desired[render.ElasticsearchTenantUserName(tenant.Name)] = tenant.Name
+	}
+
+	res, err := es.client.PerformRequest(ctx, elastic.PerformRequestOptions{Method: "GET", Path: "/_security/user"})
+	if err != nil {
+		return err
+	}
+	existing := map[string]json.RawMessage{}
+	if err := json.Unmarshal(res.Body, &existing); err != nil {
+		return err
+	}
+	for name := range existing {
+		if _, ok := desired[name]; ok || !strings.HasPrefix(name, render.ElasticsearchTenantUserPrefix) {
+			continue
+		}
+		_, err := es.client.PerformRequest(ctx, elastic.PerformRequestOptions{
+			Method: "DELETE",
+			Path:   fmt.Sprintf("/_security/user/%s", name),
+		})
+		if err != nil && !elastic.IsNotFound(err) {
+			return err
+		}
+	}
+
+	for name, tenant := range desired {
+		if err := es.SetUser(ctx, name, passwords[tenant], []string{render.ElasticsearchTenantRolePrefix + tenant}); err != nil {
+			log.Error(err, "Error applying tenant user", "user", name)
+			return err
+		}
+	}
+	return nil
+}
+
+// SetUser creates or updates the native Elasticsearch user with the password and roles.
+func (es *esClient) SetUser(ctx context.Context, username, password string, roles []string) error {
+	_, err := es.client.PerformRequest(ctx, elastic.PerformRequestOptions{
+		Method: "PUT",
+		Path:   fmt.Sprintf("/_security/user/%s", username),
+		Body: map[string]interface{}{
+			"password": password,
+			"roles":    roles,
+		},
+	})
+	return err
+}
+
+// DeleteUser deletes the native Elasticsearch user, if it exists.
+func (es *esClient) DeleteUser(ctx context.Context, username string) error {
+	_, err := es.client.PerformRequest(ctx, elastic.PerformRequestOptions{
+		Method: "DELETE",
+		Path:   fmt.Sprintf("/_security/user/%s", username),
+	})
+	if err != nil && !elastic.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// SetReadOnlyAccessUsers creates or updates the Elasticsearch users of the grants of read-only access with the read-only
+// access role and the given passwords, keyed by the names of the grants, and deletes the users of the grants that
+// aren't in passwords, i.e. that expired or were removed.
+func (es *esClient) SetReadOnlyAccessUsers(ctx context.Context, passwords map[string]string) error {
+	desired := map[string]string{}
+	for grant := range passwords {
+		desired[render.ElasticsearchReadOnlyAccessUserName(grant)] = grant
+	}
+
+	res, err := es.client.PerformRequest(ctx, elastic.PerformRequestOptions{Method: "GET", Path: "/_security/user"})
+	if err != nil {
+		return err
+	}
+	existing := map[string]json.RawMessage{}
+	if err := json.Unmarshal(res.Body, &existing); err != nil {
+		return err
+	}
+	for name := range existing {
+		if _, ok := desired[name]; ok || !strings.HasPrefix(name, render.ElasticsearchReadOnlyAccessUserPrefix) {
+			continue
+		}
+		_, err := es.client.PerformRequest(ctx, elastic.PerformRequestOptions{
+			Method: "DELETE",
+			Path:   fmt.Sprintf("/_security/user/%s", name),
+		})
+		if err != nil && !elastic.IsNotFound(err) {
+			return err
+		}
+	}
+
+	if len(desired) == 0 {
+		return nil
+	}
+	if _, err := es.client.XPackSecurityPutRole(render.ElasticsearchReadOnlyAccessRoleName).Body(buildReadOnlyAccessRole()).Do(ctx); err != nil {
+		log.Error(err, "Error applying the read-only access role")
+		return err
+	}
+	for name, grant := range desired {
+		if err := es.SetUser(ctx, name, passwords[grant], []string{render.ElasticsearchReadOnlyAccessRoleName}); err != nil {
+			log.Error(err, "Error applying read-only access user", "user", name)
+			return err
+		}
+	}
+	return nil
+}
+
+// buildReadOnlyAccessRole returns the Elasticsearch role of the grants of read-only access. The role can read all the
+// log indices, and can use all the Kibana spaces in read-only mode to explore them.
+func buildReadOnlyAccessRole() map[string]interface{} {
+	return map[string]interface{}{
+		"indices": []interface{}{
+			map[string]interface{}{
+				"names":      []string{"tigera_secure_ee_*"},
+				"privileges": []string{"read", "view_index_metadata"},
+			},
+		},
+		"applications": []interface{}{
+			map[string]interface{}{
+				"application": "kibana-.kibana",
+				"privileges":  []string{"read"},
+				"resources":   []string{"*"},
+			},
+		},
+	}
+}
+
+// buildTenantRole returns the Elasticsearch role of the tenant role. The role can read the documents of the indices
+// that match the document filters (document-level security) and the granted fields (field-level security), and can use
+// Kibana in read-only mode to explore them.
+func buildTenantRole(role operatorv1.TenantRole) map[string]interface{} {
+	indices := []interface{}{}
+	for _, idx := range role.Indices {
+		privileges := map[string]interface{}{
+			"names":      idx.Names,
+			"privileges": []string{"read", "view_index_metadata"},
+		}
+
+		if len(idx.DocumentFilters) != 0 {
+			var filters []interface{}
+			for _, f := range idx.DocumentFilters {
+				filters = append(filters, map[string]interface{}{
+					"terms": map[string]interface{}{f.Field: f.Values},
+				})
+			}
+			query, _ := json.Marshal(map[string]interface{}{
+				"bool": map[string]interface{}{"filter": filters},
+			})
+			privileges["query"] = string(query)
+		}
+
+		if len(idx.GrantedFields) != 0 || len(idx.DeniedFields) != 0 {
+			granted := idx.GrantedFields
+			if len(granted) == 0 {
+				granted = []string{"*"}
+			}
+			fieldSecurity := map[string]interface{}{"grant": granted}
+			if len(idx.DeniedFields) != 0 {
+				fieldSecurity["except"] = idx.DeniedFields
+			}
+			privileges["field_security"] = fieldSecurity
+		}
+
+		indices = append(indices, privileges)
+	}
+
+	return map[string]interface{}{
+		"indices": indices,
+		"applications": []interface{}{
+			map[string]interface{}{
+				"application": "kibana-.kibana",
+				"privileges":  []string{"read"},
+				"resources":   []string{"*"},
+			},
+		},
+		"metadata": map[string]interface{}{
+			"tigera_tenant_role": role.Name,
+		},
+	}
+}
+
+// buildKibanaSpaceRole returns the Elasticsearch role that grants read-only access to the Kibana space. Unless the space
+// has a tenant role, which restricts the documents and fields that can be read, the role can read all the log indices.
+func buildKibanaSpaceRole(space operatorv1.KibanaSpace) map[string]interface{} {
+	indices := []interface{}{}
+	if space.TenantRole == "" {
+		indices = append(indices, map[string]interface{}{
+			"names":      []string{"tigera_secure_ee_*"},
+			"privileges": []string{"read", "view_index_metadata"},
+		})
+	}
+	return map[string]interface{}{
+		"indices": indices,
+		"applications": []interface{}{
+			map[string]interface{}{
+				"application": "kibana-.kibana",
+				"privileges":  []string{"read"},
+				"resources":   []string{"space:" + space.ID},
+			},
+		},
+	}
+}
+
+// buildKibanaSpaceRoleMapping returns the Elasticsearch role mapping that grants the OIDC groups of the Kibana space
+// the role of the space, and the tenant role of the space if it has one.
+func buildKibanaSpaceRoleMapping(space operatorv1.KibanaSpace) map[string]interface{} {
+	roles := []string{render.KibanaSpaceRolePrefix + space.ID}
+	if space.TenantRole != "" {
+		roles = append(roles, render.ElasticsearchTenantRolePrefix+space.TenantRole)
+	}
+	groups := []interface{}{}
+	for _, group := range space.Groups {
+		groups = append(groups, map[string]interface{}{
+			"field": map[string]interface{}{"groups": group},
+		})
+	}
+	return map[string]interface{}{
+		"enabled": true,
+		"roles":   roles,
+		"rules":   map[string]interface{}{"any": groups},
+	}
+}
+
+// buildTenancyRole returns the Elasticsearch role of the tenant. The role can read all the documents of the log indices
+// of the managed clusters of the tenant, and can use the Kibana space of the tenant in read-only mode to explore them.
+func buildTenancyRole(tenant operatorv1.LogStorageTenant) map[string]interface{} {
+	role := buildTenantRole(operatorv1.TenantRole{
+		Name:    tenant.Name,
+		Indices: []operatorv1.TenantRoleIndices{{Names: render.TenantIndexPatterns(tenant)}},
+	})
+	role["applications"] = []interface{}{
+		map[string]interface{}{
+			"application": "kibana-.kibana",
+			"privileges":  []string{"read"},
+			"resources":   []string{"space:" + tenant.Name},
+		},
+	}
+	return role
+}
+
+// SetIngestLatencyPipeline creates the ingest pipeline that records the ingestion latency of flow and DNS logs, and
+// makes it the final pipeline of their indices. When disabled, the indices stop using the pipeline, which is then
+// deleted. Only the pipeline, template and index settings that differ from the desired ones are written.
+func (es *esClient) SetIngestLatencyPipeline(ctx context.Context, enabled bool) error {
+	patterns := ingestLatencyIndexPatterns()
+	indices := strings.Join(patterns, ",")
+	params := url.Values{"ignore_unavailable": []string{"true"}, "allow_no_indices": []string{"true"}}
+
+	finalPipelines, err := es.finalPipelines(ctx, indices, params)
+	if err != nil {
+		return err
+	}
+
+	if !enabled {
+		// The indices are cleared even if the template is already gone, e.g. when a previous attempt failed after
+		// deleting it.
+		var stale []string
+		for index, pipeline := range finalPipelines {
+			if pipeline == IngestLatencyPipelineName {
+				stale = append(stale, index)
+			}
+		}
+		if len(stale) != 0 {
+			_, err := es.client.PerformRequest(ctx, elastic.PerformRequestOptions{
+				Method: "PUT",
+				Path:   "/" + indices + "/_settings",
+				Params: params,
+				Body:   map[string]interface{}{"index.final_pipeline": nil},
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if err := es.deleteIfExists(ctx, "/_template/"+ingestLatencyTemplateName); err != nil {
+			return err
+		}
+		return es.deleteIfExists(ctx, "/_ingest/pipeline/"+IngestLatencyPipelineName)
+	}
+
+	pipeline := map[string]interface{}{
+		"description": "Records the ingestion latency of Tigera flow and DNS logs",
+		"processors": []interface{}{
+			map[string]interface{}{"set": map[string]interface{}{"field": "ingest_timestamp", "value": "{{_ingest.timestamp}}"}},
+			map[string]interface{}{"script": map[string]interface{}{"lang": "painless", "source": ingestLatencyScript, "ignore_failure": true}},
+		},
+	}
+	if err := es.putIfChanged(ctx, "/_ingest/pipeline", IngestLatencyPipelineName, pipeline); err != nil {
+		return err
+	}
+
+	// The template is merged with the templates of the indices, so that the indices created by a rollover use the
+	// pipeline too. Its settings are nested the way Elasticsearch returns them, so that they can be compared.
+	template := map[string]interface{}{
+		"index_patterns": patterns,
+		"order":          100,
+		"settings":       map[string]interface{}{"index": map[string]interface{}{"final_pipeline": IngestLatencyPipelineName}},
+		"mappings":       ingestLatencyMappings,
+	}
+	if err := es.putIfChanged(ctx, "/_template", ingestLatencyTemplateName, template); err != nil {
+		return err
+	}
+
+	upToDate := true
+	for _, pipeline := range finalPipelines {
+		if pipeline != IngestLatencyPipelineName {
+			upToDate = false
+			break
+		}
+	}
+	if upToDate {
+		return nil
+	}
+	if _, err := es.client.PerformRequest(ctx, elastic.PerformRequestOptions{
+		Method: "PUT",
+		Path:   "/" + indices + "/_mapping",
+		Params: params,
+		Body:   ingestLatencyMappings,
+	}); err != nil {
+		return err
+	}
+	_, err = es.client.PerformRequest(ctx, elastic.PerformRequestOptions{
+		Method: "PUT",
+		Path:   "/" + indices + "/_settings",
+		Params: params,
+		Body:   map[string]interface{}{"index.final_pipeline": IngestLatencyPipelineName},
+	})
+	return err
+}
+
+// finalPipelines returns the final pipelines of the indices, keyed by the names of the indices, "" for the indices
+// without one.
+func (es *esClient) finalPipelines(ctx context.Context, indices string, params url.Values) (map[string]string, error) {
+	res, err := es.client.PerformRequest(ctx, elastic.PerformRequestOptions{
+		Method: "GET",
+		Path:   "/" + indices + "/_settings/index.final_pipeline",
+		Params: params,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var settings map[string]struct {
+		Settings struct {
+			Index struct {
+				FinalPipeline string `json:"final_pipeline"`
+			} `json:"index"`
+		} `json:"settings"`
+	}
+	if err := json.Unmarshal(res.Body, &settings); err != nil {
+		return nil, err
+	}
+	pipelines := map[string]string{}
+	for index, s := range settings {
+		pipelines[index] = s.Settings.Index.FinalPipeline
+	}
+	return pipelines, nil
+}
+
+// SetSnapshotPolicy registers the snapshot repository of the LogStorage and creates or updates the snapshot lifecycle
+// management policy that takes the snapshots of the log indices. When the snapshots are removed from the LogStorage, the
+// policy and the repository are deleted, the snapshots that were taken are kept in the object store.
+func (es *esClient) SetSnapshotPolicy(ctx context.Context, ls *operatorv1.LogStorage) error {
+	snapshots := ls.Spec.Snapshots
+	if snapshots == nil {
+		for _, path := range []string{"/_slm/policy/" + SnapshotPolicyName, "/_snapshot/" + SnapshotRepositoryName} {
+			if _, err := es.client.PerformRequest(ctx, elastic.PerformRequestOptions{Method: "DELETE", Path: path}); err != nil && !elastic.IsNotFound(err) {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if _, err := es.client.PerformRequest(ctx, elastic.PerformRequestOptions{
+		Method: "PUT",
+		Path:   "/_snapshot/" + SnapshotRepositoryName,
+		Body:   buildSnapshotRepository(snapshots.Repository),
+	}); err != nil {
+		log.Error(err, "Error registering the snapshot repository")
+		return err
+	}
+	if _, err := es.client.PerformRequest(ctx, elastic.PerformRequestOptions{
+		Method: "PUT",
+		Path:   "/_slm/policy/" + SnapshotPolicyName,
+		Body:   buildSnapshotPolicy(snapshots),
+	}); err != nil {
+		log.Error(err, "Error applying the snapshot lifecycle policy")
+		return err
+	}
+	return nil
+}
+
+// buildSnapshotRepository returns the Elasticsearch snapshot repository of the object store. The repository uses the
+// default client of the repository plugin, whose credentials are in the keystore of Elasticsearch.
+func buildSnapshotRepository(repo operatorv1.SnapshotRepository) map[string]interface{} {
+	settings := map[string]interface{}{}
+	if repo.Type == operatorv1.SnapshotRepositoryAzure {
+		settings["container"] = repo.Bucket
+	} else {
+		settings["bucket"] = repo.Bucket
+	}
+	if repo.BasePath != "" {
+		settings["base_path"] = repo.BasePath
+	}
+	return map[string]interface{}{
+		"type":     strings.ToLower(string(repo.Type)),
+		"settings": settings,
+	}
+}
+
+// buildSnapshotPolicy returns the snapshot lifecycle management policy that takes the snapshots of the log indices on
+// the schedule of the snapshots, and deletes them according to their retention.
+func buildSnapshotPolicy(snapshots *operatorv1.Snapshots) map[string]interface{} {
+	schedule := snapshots.Schedule
+	if schedule == "" {
+		schedule = defaultSnapshotSchedule
+	}
+	policy := map[string]interface{}{
+		"schedule":   schedule,
+		"name":       "<tigera-snapshot-{now/d}>",
+		"repository": SnapshotRepositoryName,
+		"config": map[string]interface{}{
+			"indices":              []string{"tigera_secure_ee_*"},
+			"include_global_state": false,
+		},
+	}
+	if r := snapshots.Retention; r != nil {
+		retention := map[string]interface{}{}
+		if r.ExpireAfter != nil {
+			retention["expire_after"] = fmt.Sprintf("%ds", int64(r.ExpireAfter.Seconds()))
+		}
+		if r.MinCount != nil {
+			retention["min_count"] = *r.MinCount
+		}
+		if r.MaxCount != nil {
+			retention["max_count"] = *r.MaxCount
+		}
+		policy["retention"] = retention
+	}
+	return policy
+}
+
+// SetDiskWatermarks applies the disk allocation watermarks of the LogStorage through the cluster settings API, which
+// takes effect without restarting the Elasticsearch nodes. The watermarks that aren't set are reset to the defaults of
+// Elasticsearch.
+func (es *esClient) SetDiskWatermarks(ctx context.Context, ls *operatorv1.LogStorage) error {
+	var watermarks *operatorv1.DiskWatermarks
+	if ls.Spec.Nodes != nil {
+		watermarks = ls.Spec.Nodes.DiskWatermarks
+	}
+	if _, err := es.client.PerformRequest(ctx, elastic.PerformRequestOptions{
+		Method: "PUT",
+		Path:   "/_cluster/settings",
+		Body:   map[string]interface{}{"persistent": buildDiskWatermarkSettings(watermarks)},
+	}); err != nil {
+		log.Error(err, "Error applying the disk watermarks")
+		return err
+	}
+	return nil
+}
+
+// buildDiskWatermarkSettings returns the persistent cluster settings of the disk watermarks. The watermarks that aren't
+// set are null, which resets them to the defaults of Elasticsearch.
+func buildDiskWatermarkSettings(watermarks *operatorv1.DiskWatermarks) map[string]interface{} {
+	if watermarks == nil {
+		watermarks = &operatorv1.DiskWatermarks{}
+	}
+	settings := map[string]interface{}{}
+	for key, percent := range map[string]*int32{
+		"cluster.routing.allocation.disk.watermark.low":         watermarks.Low,
+		"cluster.routing.allocation.disk.watermark.high":        watermarks.High,
+		"cluster.routing.allocation.disk.watermark.flood_stage": watermarks.FloodStage,
+	} {
+		if percent != nil {
+			settings[key] = fmt.Sprintf("%d%%", *percent)
+		} else {
+			settings[key] = nil
+		}
+	}
+	return settings
+}
+
+// ClusterHealth returns the health of the Elasticsearch cluster.
+func (es *esClient) ClusterHealth(ctx context.Context) (*ClusterHealth, error) {
+	res, err := es.client.ClusterHealth().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &ClusterHealth{
+		Status:             res.Status,
+		RelocatingShards:   res.RelocatingShards,
+		InitializingShards: res.InitializingShards,
+		UnassignedShards:   res.UnassignedShards,
+	}, nil
+}
+
+// IngestLatency returns the distribution of the ingestion latency of the logs of the index pattern that were indexed
+// in the time window, bucketed by the given upper bounds in seconds.
+func (es *esClient) IngestLatency(ctx context.Context, indexPattern string, window time.Duration, bounds []float64) (*IngestLatency, error) {
+	ranges := []interface{}{}
+	for _, bound := range bounds {
+		ranges = append(ranges, map[string]interface{}{"key": strconv.FormatFloat(bound, 'f', -1, 64), "to": bound * 1000})
+	}
+	query := map[string]interface{}{
+		"size": 0,
+		"query": map[string]interface{}{
+			"range": map[string]interface{}{
+				"ingest_timestamp": map[string]interface{}{"gte": fmt.Sprintf("now-%ds", int64(window.Seconds()))},
+			},
+		},
+		"aggs": map[string]interface{}{
+			"latency": map[string]interface{}{"stats": map[string]interface{}{"field": "ingest_latency_ms"}},
+			"p99":     map[string]interface{}{"percentiles": map[string]interface{}{"field": "ingest_latency_ms", "percents": []float64{99}}},
+			"buckets": map[string]interface{}{"range": map[string]interface{}{"field": "ingest_latency_ms", "keyed": true, "ranges": ranges}},
+		},
+	}
+
+	res, err := es.client.PerformRequest(ctx, elastic.PerformRequestOptions{
+		Method: "POST",
+		Path:   "/" + indexPattern + "/_search",
+		Params: url.Values{"ignore_unavailable": []string{"true"}, "allow_no_indices": []string{"true"}},
+		Body:   query,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parseIngestLatency(res.Body, bounds)
+}
+
+func parseIngestLatency(body []byte, bounds []float64) (*IngestLatency, error) {
+	var res struct {
+		Aggregations struct {
+			Latency struct {
+				Count uint64   `json:"count"`
+				Sum   *float64 `json:"sum"`
+			} `json:"latency"`
+			P99 struct {
+				Values map[string]*float64 `json:"values"`
+			} `json:"p99"`
+			Buckets struct {
+				Buckets map[string]struct {
+					DocCount uint64 `json:"doc_count"`
+				} `json:"buckets"`
+			} `json:"buckets"`
+		} `json:"aggregations"`
+	}
+	if err := json.Unmarshal(body, &res); err != nil {
+		return nil, err
+	}
+
+	aggs := res.Aggregations
+	latency := &IngestLatency{Count: aggs.Latency.Count, Buckets: map[float64]uint64{}}
+	if aggs.Latency.Sum != nil {
+		latency.Sum = time.Duration(*aggs.Latency.Sum) * time.Millisecond
+	}
+	if p99 := aggs.P99.Values["99.0"]; p99 != nil {
+		latency.P99 = time.Duration(*p99) * time.Millisecond
+	}
+	for _, bound := range bounds {
+		latency.Buckets[bound] = aggs.Buckets.Buckets[strconv.FormatFloat(bound, 'f', -1, 64)].DocCount
+	}
+	return latency, nil
+}
+
+// flowLogIndexPrefix is the prefix of the flow log indices, which is followed by the name of the cluster of the logs.
+const flowLogIndexPrefix = "tigera_secure_ee_flows."
+
+// LastFlowLogTimes returns the end time of the most recent flow log of each cluster that was indexed in the last day,
+// by the name of the cluster.
+func (es *esClient) LastFlowLogTimes(ctx context.Context) (map[string]time.Time, error) {
+	query := map[string]interface{}{
+		"size": 0,
+		"query": map[string]interface{}{
+			"range": map[string]interface{}{
+				"end_time": map[string]interface{}{"gte": "now-1d"},
+			},
+		},
+		"aggs": map[string]interface{}{
+			"indices": map[string]interface{}{
+				"terms": map[string]interface{}{"field": "_index", "size": 10000},
+				"aggs": map[string]interface{}{
+					"last": map[string]interface{}{"max": map[string]interface{}{"field": "end_time"}},
+				},
+			},
+		},
+	}
+
+	res, err := es.client.PerformRequest(ctx, elastic.PerformRequestOptions{
+		Method: "POST",
+		Path:   "/" + flowLogIndexPrefix + "*/_search",
+		Params: url.Values{"ignore_unavailable": []string{"true"}, "allow_no_indices": []string{"true"}},
+		Body:   query,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parseLastFlowLogTimes(res.Body)
+}
+
+func parseLastFlowLogTimes(body []byte) (map[string]time.Time, error) {
+	var res struct {
+		Aggregations struct {
+			Indices struct {
+				Buckets []struct {
+					Key  string `json:"key"`
+					Last struct {
+						Value *float64 `json:"value"`
+					} `json:"last"`
+				} `json:"buckets"`
+			} `json:"indices"`
+		} `json:"aggregations"`
+	}
+	if err := json.Unmarshal(body, &res); err != nil {
+		return nil, err
+	}
+
+	times := map[string]time.Time{}
+	for _, bucket := range res.Aggregations.Indices.Buckets {
+		if bucket.Last.Value == nil || !strings.HasPrefix(bucket.Key, flowLogIndexPrefix) {
+			continue
+		}
+		// The indices of a cluster are named tigera_secure_ee_flows.<cluster>.<suffix>.
+		cluster := strings.SplitN(strings.TrimPrefix(bucket.Key, flowLogIndexPrefix), ".", 2)[0]
+		last := time.Unix(0, int64(*bucket.Last.Value)*int64(time.Millisecond))
+		if last.After(times[cluster]) {
+			times[cluster] = last
+		}
+	}
+	return times, nil
+}
+
+// StorageUsage returns the disk usage of the Elasticsearch data nodes, and estimates the storage taken by the logs of
+// each type that were written in the last day from the number of those logs and the average size of the logs of the
+// type.
+func (es *esClient) StorageUsage(ctx context.Context) (*StorageUsage, error) {
+	res, err := es.client.PerformRequest(ctx, elastic.PerformRequestOptions{
+		Method: "GET",
+		Path:   "/_cat/allocation",
+		Params: url.Values{"format": []string{"json"}, "bytes": []string{"b"}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	usage, err := parseAllocation(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{"ignore_unavailable": []string{"true"}, "allow_no_indices": []string{"true"}}
+	for logType, index := range storageUsageIndices {
+		res, err = es.client.PerformRequest(ctx, elastic.PerformRequestOptions{
+			Method: "GET",
+			Path:   "/" + index.pattern + "/_stats/store,docs",
+			Params: params,
+		})
+		if err != nil {
+			return nil, err
+		}
+		storeBytes, docs, err := parseIndexStats(res.Body)
+		if err != nil {
+			return nil, err
+		}
+		if docs == 0 {
+			usage.IngestedBytesPerDay[logType] = 0
+			continue
+		}
+
+		res, err = es.client.PerformRequest(ctx, elastic.PerformRequestOptions{
+			Method: "POST",
+			Path:   "/" + index.pattern + "/_count",
+			Params: params,
+			Body: map[string]interface{}{
+				"query": map[string]interface{}{
+					"range": map[string]interface{}{index.timeField: map[string]interface{}{"gte": "now-1d"}},
+				},
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		var count struct {
+			Count int64 `json:"count"`
+		}
+		if err := json.Unmarshal(res.Body, &count); err != nil {
+			return nil, err
+		}
+		usage.IngestedBytesPerDay[logType] = int64(float64(count.Count) * float64(storeBytes) / float64(docs))
+	}
+	return usage, nil
+}
+
+// parseAllocation sums the disk capacity and usage of the nodes in the response of the cat allocation API. The
+// unassigned shards are reported without disk figures.
+func parseAllocation(body []byte) (*StorageUsage, error) {
+	var nodes []struct {
+		DiskUsed  *string `json:"disk.used"`
+		DiskTotal *string `json:"disk.total"`
+	}
+	if err := json.Unmarshal(body, &nodes); err != nil {
+		return nil, err
+	}
+	usage := &StorageUsage{IngestedBytesPerDay: map[string]int64{}}
+	for _, node := range nodes {
+		if node.DiskUsed == nil || node.DiskTotal == nil {
+			continue
+		}
+		used, err := strconv.ParseInt(*node.DiskUsed, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		total, err := strconv.ParseInt(*node.DiskTotal, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		usage.UsedBytes += used
+		usage.CapacityBytes += total
+	}
+	return usage, nil
+}
+
+// DeleteIndex deletes the index, if it exists.
+func (es *esClient) DeleteIndex(ctx context.Context, index string) error {
+	if _, err := es.client.DeleteIndex(index).Do(ctx); err != nil && !elastic.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// NodeDiskUsage returns the percentage of the disk that is used on each Elasticsearch data node, by node name.
+func (es *esClient) NodeDiskUsage(ctx context.Context) (map[string]int, error) {
+	res, err := es.client.PerformRequest(ctx, elastic.PerformRequestOptions{
+		Method: "GET",
+		Path:   "/_cat/allocation",
+		Params: url.Values{"format": []string{"json"}, "h": []string{"node,disk.percent"}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parseNodeDiskUsage(res.Body)
+}
+
+// parseNodeDiskUsage returns the disk usage percentage of the nodes in the response of the cat allocation API. The
+// unassigned shards, which have no disk usage, are skipped.
+func parseNodeDiskUsage(body []byte) (map[string]int, error) {
+	var nodes []struct {
+		Node        string  `json:"node"`
+		DiskPercent *string `json:"disk.percent"`
+	}
+	if err := json.Unmarshal(body, &nodes); err != nil {
+		return nil, err
+	}
+	usage := map[string]int{}
+	for _, node := range nodes {
+		if node.DiskPercent == nil {
+			continue
+		}
+		percent, err := strconv.Atoi(*node.DiskPercent)
+		if err != nil {
+			return nil, err
+		}
+		usage[node.Node] = percent
+	}
+	return usage, nil
+}
+
+// parseIndexStats returns the storage taken by the indices in the response of the index stats API, including replicas,
+// and the number of documents in their primary shards.
+func parseIndexStats(body []byte) (int64, int64, error) {
+	var res struct {
+		All struct {
+			Primaries struct {
+				Docs struct {
+					Count int64 `json:"count"`
+				} `json:"docs"`
+			} `json:"primaries"`
+			Total struct {
+				Store struct {
+					SizeInBytes int64 `json:"size_in_bytes"`
+				} `json:"store"`
+			} `json:"total"`
+		} `json:"_all"`
+	}
+	if err := json.Unmarshal(body, &res); err != nil {
+		return 0, 0, err
+	}
+	return res.All.Total.Store.SizeInBytes, res.All.Primaries.Docs.Count, nil
+}
+
+func ingestLatencyIndexPatterns() []string {
+	var patterns []string
+	for _, pattern := range IngestLatencyIndexPatterns {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+	return patterns
+}
+
 // listILMPolicies generates ILM policies based on disk space and retention in LogStorage
 // Allocate 70% of ES disk space to flows, dns and bgp logs [majorPctOfTotalDisk]
 // Allocate 90% of the 70% ES disk space to flow logs, 5% of the 70% ES disk space to each dns and bgp logs.
 // Allocate 10% of ES disk space to logs that are NOT flows, dns or bgp [minorPctOfTotalDisk]
 // Equally distribute 10% of the ES disk space among these other log types
+// The retention limits of a type in LogStorage further bound the rollover of its indices
 func (es *esClient) listILMPolicies(ls *operatorv1.LogStorage) map[string]policyDetail {
 	totalEsStorage := getTotalEsDisk(ls)
 	majorPctOfTotalDisk := 0.7
@@ -168,22 +1288,46 @@ func (es *esClient) listILMPolicies(ls *operatorv1.LogStorage) map[string]policy
 	minorPctOfTotalDisk := 0.1
 	pctOfDisk := minorPctOfTotalDisk / float64(numOfIndicesWithMinorSpace)
 
+	// The audit limits are shared by the indices of the Calico Enterprise and the Kubernetes audit logs.
+	limits := map[operatorv1.RetentionIndexType]retentionLimit{}
+	for _, limit := range ls.Spec.Retention.Limits {
+		shares := int64(1)
+		if limit.IndexType == operatorv1.RetentionIndexTypeAuditReports {
+			shares = 2
+		}
+		l := retentionLimit{}
+		if limit.MaxDocs != nil {
+			l.maxDocs = *limit.MaxDocs / shares
+		}
+		if limit.MaxSize != nil {
+			l.maxSize = limit.MaxSize.Value() / shares
+		}
+		limits[limit.IndexType] = l
+	}
+
 	// Retention is not set in LogStorage for l7, benchmark and events logs, set default values used by curator
 	return map[string]policyDetail{
-		"tigera_secure_ee_flows": buildILMPolicy(totalEsStorage, majorPctOfTotalDisk, 0.85, int(*ls.Spec.Retention.Flows)),
-		"tigera_secure_ee_dns":   buildILMPolicy(totalEsStorage, majorPctOfTotalDisk, 0.05, int(*ls.Spec.Retention.DNSLogs)),
-		"tigera_secure_ee_bgp":   buildILMPolicy(totalEsStorage, majorPctOfTotalDisk, 0.05, int(*ls.Spec.Retention.BGPLogs)),
-		"tigera_secure_ee_l7":    buildILMPolicy(totalEsStorage, majorPctOfTotalDisk, 0.05, 1),
+		"tigera_secure_ee_flows": buildILMPolicy(totalEsStorage, majorPctOfTotalDisk, 0.85, int(*ls.Spec.Retention.Flows), limits[operatorv1.RetentionIndexTypeFlows]),
+		"tigera_secure_ee_dns":   buildILMPolicy(totalEsStorage, majorPctOfTotalDisk, 0.05, int(*ls.Spec.Retention.DNSLogs), limits[operatorv1.RetentionIndexTypeDNSLogs]),
+		"tigera_secure_ee_bgp":   buildILMPolicy(totalEsStorage, majorPctOfTotalDisk, 0.05, int(*ls.Spec.Retention.BGPLogs), limits[operatorv1.RetentionIndexTypeBGPLogs]),
+		"tigera_secure_ee_l7":    buildILMPolicy(totalEsStorage, majorPctOfTotalDisk, 0.05, 1, retentionLimit{}),
 
-		"tigera_secure_ee_audit_ee":           buildILMPolicy(totalEsStorage, minorPctOfTotalDisk, pctOfDisk, int(*ls.Spec.Retention.AuditReports)),
-		"tigera_secure_ee_audit_kube":         buildILMPolicy(totalEsStorage, minorPctOfTotalDisk, pctOfDisk, int(*ls.Spec.Retention.AuditReports)),
-		"tigera_secure_ee_snapshots":          buildILMPolicy(totalEsStorage, minorPctOfTotalDisk, pctOfDisk, int(*ls.Spec.Retention.Snapshots)),
-		"tigera_secure_ee_compliance_reports": buildILMPolicy(totalEsStorage, minorPctOfTotalDisk, pctOfDisk, int(*ls.Spec.Retention.ComplianceReports)),
-		"tigera_secure_ee_benchmark_results":  buildILMPolicy(totalEsStorage, minorPctOfTotalDisk, pctOfDisk, 91),
-		"tigera_secure_ee_events":             buildILMPolicy(totalEsStorage, minorPctOfTotalDisk, pctOfDisk, 91),
+		"tigera_secure_ee_audit_ee":           buildILMPolicy(totalEsStorage, minorPctOfTotalDisk, pctOfDisk, int(*ls.Spec.Retention.AuditReports), limits[operatorv1.RetentionIndexTypeAuditReports]),
+		"tigera_secure_ee_audit_kube":         buildILMPolicy(totalEsStorage, minorPctOfTotalDisk, pctOfDisk, int(*ls.Spec.Retention.AuditReports), limits[operatorv1.RetentionIndexTypeAuditReports]),
+		"tigera_secure_ee_snapshots":          buildILMPolicy(totalEsStorage, minorPctOfTotalDisk, pctOfDisk, int(*ls.Spec.Retention.Snapshots), limits[operatorv1.RetentionIndexTypeSnapshots]),
+		"tigera_secure_ee_compliance_reports": buildILMPolicy(totalEsStorage, minorPctOfTotalDisk, pctOfDisk, int(*ls.Spec.Retention.ComplianceReports), limits[operatorv1.RetentionIndexTypeComplianceReports]),
+		"tigera_secure_ee_benchmark_results":  buildILMPolicy(totalEsStorage, minorPctOfTotalDisk, pctOfDisk, 91, retentionLimit{}),
+		"tigera_secure_ee_events":             buildILMPolicy(totalEsStorage, minorPctOfTotalDisk, pctOfDisk, 91, retentionLimit{}),
 	}
 }
 
+// retentionLimit is the limit on the number of documents and the size of the indices of an ILM policy. Zero values
+// are unlimited.
+type retentionLimit struct {
+	maxDocs int64
+	maxSize int64
+}
+
 func (es *esClient) createOrUpdatePolicies(ctx context.Context, listPolicy map[string]policyDetail) error {
 	for indexName, pd := range listPolicy {
 		policyName := indexName + "_policy"
@@ -192,40 +1336,52 @@ func (es *esClient) createOrUpdatePolicies(ctx context.Context, listPolicy map[s
 		if err != nil {
 			if elastic.IsNotFound(err) {
 				// If policy doesn't exist, create one
-				return applyILMPolicy(ctx, es.client, indexName, pd.policy)
+				if err := applyILMPolicy(ctx, es.client, indexName, pd.policy); err != nil {
+					return err
+				}
+				continue
 			}
 			return err
 		}
 
 		// If policy exists, check if it needs to be updated
-		currentMaxAge, currentMaxSize, currentMinAge, err := extractPolicyDetails(res[policyName].Policy)
+		currentMaxAge, currentMaxSize, currentMaxDocs, currentMinAge, err := extractPolicyDetails(res[policyName].Policy)
 		if err != nil {
 			return err
 		}
 		if currentMaxAge != pd.rolloverAge ||
 			currentMaxSize != pd.rolloverSize ||
+			currentMaxDocs != pd.rolloverDocs ||
 			currentMinAge != pd.deleteAge {
-			return applyILMPolicy(ctx, es.client, indexName, pd.policy)
+			if err := applyILMPolicy(ctx, es.client, indexName, pd.policy); err != nil {
+				return err
+			}
 		}
 	}
 	return nil
 }
 
-func buildILMPolicy(totalEsStorage int64, totalDiskPercentage float64, percentOfDiskForLogType float64, retention int) policyDetail {
+func buildILMPolicy(totalEsStorage int64, totalDiskPercentage float64, percentOfDiskForLogType float64, retention int, limit retentionLimit) policyDetail {
 	pd := policyDetail{}
-	pd.rolloverSize = calculateRolloverSize(totalEsStorage, totalDiskPercentage, percentOfDiskForLogType)
+	pd.rolloverSize = calculateRolloverSize(totalEsStorage, totalDiskPercentage, percentOfDiskForLogType, limit.maxSize)
 	pd.rolloverAge = calculateRolloverAge(retention)
+	pd.rolloverDocs = calculateRolloverDocs(limit.maxDocs)
 	pd.deleteAge = fmt.Sprintf("%dd", retention)
 
+	rollover := map[string]interface{}{
+		"max_size": pd.rolloverSize,
+		"max_age":  pd.rolloverAge,
+	}
+	if pd.rolloverDocs > 0 {
+		rollover["max_docs"] = pd.rolloverDocs
+	}
+
 	pd.policy = map[string]interface{}{
 		"policy": map[string]interface{}{
 			"phases": map[string]interface{}{
 				"hot": map[string]interface{}{
 					"actions": map[string]interface{}{
-						"rollover": map[string]interface{}{
-							"max_size": pd.rolloverSize,
-							"max_age":  pd.rolloverAge,
-						},
+						"rollover": rollover,
 						"set_priority": map[string]interface{}{
 							"priority": 100,
 						},
@@ -264,7 +1420,8 @@ func applyILMPolicy(ctx context.Context, esClient *elastic.Client, indexName str
 // calculateRolloverSize returns max_size to rollover
 // max_size is based on the disk space allocated for the log type divided by ElasticsearchRetentionFactor
 // If calculated max_size is greater than ES recommended shard size (DefaultMaxIndexSizeGi), set it to DefaultMaxIndexSizeGi
-func calculateRolloverSize(totalEsStorage int64, diskPercentage float64, diskForLogType float64) string {
+// If a size limit is set for the log type, max_size is at most the limit divided by ElasticsearchRetentionFactor
+func calculateRolloverSize(totalEsStorage int64, diskPercentage float64, diskForLogType float64, limit int64) string {
 	rolloverSize := int64((float64(totalEsStorage) * diskPercentage * diskForLogType) / ElasticsearchRetentionFactor)
 	rolloverMax := resource.MustParse(fmt.Sprintf("%dGi", DefaultMaxIndexSizeGi))
 	maxRolloverSize := rolloverMax.Value()
@@ -272,10 +1429,25 @@ func calculateRolloverSize(totalEsStorage int64, diskPercentage float64, diskFor
 	if rolloverSize > maxRolloverSize {
 		rolloverSize = maxRolloverSize
 	}
+	if limitSize := limit / ElasticsearchRetentionFactor; limit > 0 && limitSize < rolloverSize {
+		rolloverSize = limitSize
+	}
 
 	return fmt.Sprintf("%db", rolloverSize)
 }
 
+// calculateRolloverDocs returns max_docs to rollover, which is the document limit of the log type divided by
+// ElasticsearchRetentionFactor, or 0 if the log type has no document limit
+func calculateRolloverDocs(limit int64) int64 {
+	if limit <= 0 {
+		return 0
+	}
+	if docs := limit / ElasticsearchRetentionFactor; docs > 0 {
+		return docs
+	}
+	return 1
+}
+
 // calculateRolloverAge returns max_age to rollover
 // max_age to rollover an index is retention period set in LogStorage divided by ElasticsearchRetentionFactor
 // If retention is < ElasticsearchRetentionFactor, set rollover age to 1 day
@@ -323,20 +1495,21 @@ func getESRoots(esCertSecret *corev1.Secret) (*x509.CertPool, error) {
 	return roots, nil
 }
 
-func extractPolicyDetails(policy map[string]interface{}) (string, string, string, error) {
+func extractPolicyDetails(policy map[string]interface{}) (string, string, int64, string, error) {
 	jsonPolicy, err := json.Marshal(policy)
 	if err != nil {
-		return "", "", "", err
+		return "", "", 0, "", err
 	}
 	existingPolicy := Policy{}
 	if err = json.Unmarshal(jsonPolicy, &existingPolicy); err != nil {
-		return "", "", "", err
+		return "", "", 0, "", err
 	}
 
 	currentMaxAge := existingPolicy.Phases.Hot.Actions.Rollover.MaxAge
 	currentMaxSize := existingPolicy.Phases.Hot.Actions.Rollover.MaxSize
+	currentMaxDocs := existingPolicy.Phases.Hot.Actions.Rollover.MaxDocs
 	currentMinAge := existingPolicy.Phases.Delete.MinAge
-	return currentMaxAge, currentMaxSize, currentMinAge, nil
+	return currentMaxAge, currentMaxSize, currentMaxDocs, currentMinAge, nil
 }
 
 func getTotalEsDisk(ls *operatorv1.LogStorage) int64 {
@@ -17,17 +17,22 @@ package utils
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	elastic "github.com/olivere/elastic/v7"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
 )
 
 const (
@@ -61,9 +66,24 @@ var _ = Describe("Elasticsearch tests", func() {
 			diskPercentage := 0.7
 			diskForLogType := 0.9
 
-			rolloverSize := calculateRolloverSize(totalEsStorage, diskPercentage, diskForLogType)
+			rolloverSize := calculateRolloverSize(totalEsStorage, diskPercentage, diskForLogType, 0)
 			Expect(rolloverSize).To(Equal(fmt.Sprintf("%db", expectedRolloverSize)))
 		})
+		It("should bound the rollover by the retention limits of the log type", func() {
+			totalDiskSize := resource.MustParse("800Gi")
+			maxSize := resource.MustParse("40Gi")
+			pd := buildILMPolicy(totalDiskSize.Value(), 0.7, .9, 10, retentionLimit{maxDocs: 1000000, maxSize: maxSize.Value()})
+
+			Expect(pd.rolloverSize).To(Equal(fmt.Sprintf("%db", maxSize.Value()/ElasticsearchRetentionFactor)))
+			Expect(pd.rolloverDocs).To(Equal(int64(250000)))
+			rollover := pd.policy["policy"].(map[string]interface{})["phases"].(map[string]interface{})["hot"].(map[string]interface{})["actions"].(map[string]interface{})["rollover"]
+			Expect(rollover).To(HaveKeyWithValue("max_docs", int64(250000)))
+
+			By("without retention limits")
+			pd = buildILMPolicy(totalDiskSize.Value(), 0.7, .9, 10, retentionLimit{})
+			Expect(pd.rolloverSize).To(Equal(fmt.Sprintf("%db", rolloverMax.Value())))
+			Expect(pd.rolloverDocs).To(BeZero())
+		})
 		It("rollover age", func() {
 			By("for retention period lesser than retention factor")
 			Expect("1d").To(Equal(calculateRolloverAge(2)))
@@ -74,7 +94,7 @@ var _ = Describe("Elasticsearch tests", func() {
 		It("apply new lifecycle policy", func() {
 			newPolicies = true
 			totalDiskSize := resource.MustParse("100Gi")
-			pd := buildILMPolicy(totalDiskSize.Value(), 0.7, .9, 10)
+			pd := buildILMPolicy(totalDiskSize.Value(), 0.7, .9, 10, retentionLimit{})
 
 			err := eClient.createOrUpdatePolicies(ctx, map[string]policyDetail{
 				indexName: pd,
@@ -84,13 +104,311 @@ var _ = Describe("Elasticsearch tests", func() {
 		It("update existing lifecycle policy", func() {
 			newPolicies = false
 			totalDiskSize := resource.MustParse("100Gi")
-			pd := buildILMPolicy(totalDiskSize.Value(), 0.7, .9, 5)
+			pd := buildILMPolicy(totalDiskSize.Value(), 0.7, .9, 5, retentionLimit{})
 			err := eClient.createOrUpdatePolicies(ctx, map[string]policyDetail{
 				indexName: pd,
 			})
 			Expect(err).To(BeNil())
 		})
 	})
+
+	Context("Ingest latency", func() {
+		It("should parse the latency distribution from the aggregations", func() {
+			latency, err := parseIngestLatency([]byte(`{
+  "aggregations": {
+    "latency": {"count": 3, "sum": 9500.0},
+    "p99": {"values": {"99.0": 6000.0}},
+    "buckets": {"buckets": {"1": {"doc_count": 1}, "5": {"doc_count": 2}, "10": {"doc_count": 3}}}
+  }
+}`), []float64{1, 5, 10, 30})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(latency).To(Equal(&IngestLatency{
+				Count:   3,
+				Sum:     9500 * time.Millisecond,
+				Buckets: map[float64]uint64{1: 1, 5: 2, 10: 3, 30: 0},
+				P99:     6 * time.Second,
+			}))
+		})
+
+		It("should report no latency when no logs were indexed", func() {
+			latency, err := parseIngestLatency([]byte(`{
+  "aggregations": {
+    "latency": {"count": 0, "sum": null},
+    "p99": {"values": {"99.0": null}},
+    "buckets": {"buckets": {"1": {"doc_count": 0}}}
+  }
+}`), []float64{1})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(latency.Count).To(BeZero())
+			Expect(latency.P99).To(BeZero())
+		})
+	})
+
+	Context("Last flow log times", func() {
+		It("should parse the most recent flow log of each cluster from the aggregations", func() {
+			times, err := parseLastFlowLogTimes([]byte(`{
+  "aggregations": {
+    "indices": {
+      "buckets": [
+        {"key": "tigera_secure_ee_flows.cluster-a.fluentd-000001", "last": {"value": 1600000000000}},
+        {"key": "tigera_secure_ee_flows.cluster-a.fluentd-000002", "last": {"value": 1600000060000}},
+        {"key": "tigera_secure_ee_flows.cluster-b.fluentd-000001", "last": {"value": 1600000030000}},
+        {"key": "tigera_secure_ee_flows.cluster-c.fluentd-000001", "last": {"value": null}}
+      ]
+    }
+  }
+}`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(times).To(HaveLen(2))
+			Expect(times["cluster-a"].Unix()).To(Equal(int64(1600000060)))
+			Expect(times["cluster-b"].Unix()).To(Equal(int64(1600000030)))
+		})
+	})
+
+	Context("Storage usage", func() {
+		It("should sum the disks of the nodes and skip the unassigned shards", func() {
+			usage, err := parseAllocation([]byte(`[
+  {"shards": "10", "disk.indices": "100", "disk.used": "1000", "disk.avail": "9000", "disk.total": "10000", "node": "es-0"},
+  {"shards": "12", "disk.indices": "200", "disk.used": "2000", "disk.avail": "8000", "disk.total": "10000", "node": "es-1"},
+  {"shards": "2", "disk.indices": null, "disk.used": null, "disk.avail": null, "disk.total": null, "node": "UNASSIGNED"}
+]`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(usage.UsedBytes).To(Equal(int64(3000)))
+			Expect(usage.CapacityBytes).To(Equal(int64(20000)))
+		})
+
+		It("should parse the disk usage of the nodes and skip the unassigned shards", func() {
+			usage, err := parseNodeDiskUsage([]byte(`[
+  {"node": "es-0", "disk.percent": "42"},
+  {"node": "es-1", "disk.percent": "91"},
+  {"node": "UNASSIGNED", "disk.percent": null}
+]`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(usage).To(Equal(map[string]int{"es-0": 42, "es-1": 91}))
+		})
+
+		It("should parse the storage and the documents of the indices", func() {
+			storeBytes, docs, err := parseIndexStats([]byte(`{
+  "_all": {
+    "primaries": {"docs": {"count": 100}, "store": {"size_in_bytes": 5000}},
+    "total": {"docs": {"count": 200}, "store": {"size_in_bytes": 10000}}
+  }
+}`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(storeBytes).To(Equal(int64(10000)))
+			Expect(docs).To(Equal(int64(100)))
+		})
+	})
+
+	Context("Tenant roles", func() {
+		It("should build a role with document and field level security", func() {
+			role := buildTenantRole(operatorv1.TenantRole{
+				Name: "team-a",
+				Indices: []operatorv1.TenantRoleIndices{
+					{
+						Names: []string{"tigera_secure_ee_flows.*"},
+						DocumentFilters: []operatorv1.TenantDocumentFilter{
+							{Field: "source_namespace", Values: []string{"app-a", "app-b"}},
+							{Field: "cluster", Values: []string{"cluster"}},
+						},
+						DeniedFields: []string{"source_ip", "dest_ip"},
+					},
+					{Names: []string{"tigera_secure_ee_dns.*"}},
+				},
+			})
+
+			body, err := json.Marshal(role)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(body).To(MatchJSON(`{
+  "indices": [
+    {
+      "names": ["tigera_secure_ee_flows.*"],
+      "privileges": ["read", "view_index_metadata"],
+      "query": "{\"bool\":{\"filter\":[{\"terms\":{\"source_namespace\":[\"app-a\",\"app-b\"]}},{\"terms\":{\"cluster\":[\"cluster\"]}}]}}",
+      "field_security": {"grant": ["*"], "except": ["source_ip", "dest_ip"]}
+    },
+    {
+      "names": ["tigera_secure_ee_dns.*"],
+      "privileges": ["read", "view_index_metadata"]
+    }
+  ],
+  "applications": [
+    {"application": "kibana-.kibana", "privileges": ["read"], "resources": ["*"]}
+  ],
+  "metadata": {"tigera_tenant_role": "team-a"}
+}`))
+		})
+
+		It("should only consider a role up to date if it has all the fields of the desired role", func() {
+			role := buildTenantRole(operatorv1.TenantRole{
+				Name:    "team-a",
+				Indices: []operatorv1.TenantRoleIndices{{Names: []string{"tigera_secure_ee_dns.*"}}},
+			})
+
+			current := json.RawMessage(`{
+  "cluster": [],
+  "indices": [
+    {
+      "names": ["tigera_secure_ee_dns.*"],
+      "privileges": ["read", "view_index_metadata"],
+      "allow_restricted_indices": false
+    }
+  ],
+  "applications": [
+    {"application": "kibana-.kibana", "privileges": ["read"], "resources": ["*"]}
+  ],
+  "run_as": [],
+  "metadata": {"tigera_tenant_role": "team-a"},
+  "transient_metadata": {"enabled": true}
+}`)
+			upToDate, err := roleUpToDate(current, role)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(upToDate).To(BeTrue())
+
+			role = buildTenantRole(operatorv1.TenantRole{
+				Name:    "team-a",
+				Indices: []operatorv1.TenantRoleIndices{{Names: []string{"tigera_secure_ee_dns.*", "tigera_secure_ee_flows.*"}}},
+			})
+			upToDate, err = roleUpToDate(current, role)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(upToDate).To(BeFalse())
+		})
+	})
+
+	Context("Kibana spaces", func() {
+		It("should build the roles and role mappings of the spaces", func() {
+			spaceA := operatorv1.KibanaSpace{ID: "team-a", Groups: []string{"team-a-devs", "team-a-ops"}}
+			spaceB := operatorv1.KibanaSpace{ID: "team-b", Groups: []string{"team-b"}, TenantRole: "team-b"}
+
+			body, err := json.Marshal(buildKibanaSpaceRole(spaceA))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(body).To(MatchJSON(`{
+  "applications": [{"application": "kibana-.kibana", "privileges": ["read"], "resources": ["space:team-a"]}],
+  "indices": [{"names": ["tigera_secure_ee_*"], "privileges": ["read", "view_index_metadata"]}]
+}`))
+			body, err = json.Marshal(buildKibanaSpaceRole(spaceB))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(body).To(MatchJSON(`{
+  "applications": [{"application": "kibana-.kibana", "privileges": ["read"], "resources": ["space:team-b"]}],
+  "indices": []
+}`))
+			body, err = json.Marshal(buildKibanaSpaceRoleMapping(spaceA))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(body).To(MatchJSON(`{
+  "enabled": true,
+  "roles": ["tigera_kibana_space_team-a"],
+  "rules": {"any": [{"field": {"groups": "team-a-devs"}}, {"field": {"groups": "team-a-ops"}}]}
+}`))
+			body, err = json.Marshal(buildKibanaSpaceRoleMapping(spaceB))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(body).To(MatchJSON(`{
+  "enabled": true,
+  "roles": ["tigera_kibana_space_team-b", "tigera_tenant_team-b"],
+  "rules": {"any": [{"field": {"groups": "team-b"}}]}
+}`))
+		})
+
+		It("should delete the roles and role mappings of the removed spaces and only write those that changed", func() {
+			space := operatorv1.KibanaSpace{ID: "team-a", Groups: []string{"team-a"}}
+			currentRole, err := json.Marshal(buildKibanaSpaceRole(space))
+			Expect(err).NotTo(HaveOccurred())
+			rt := &securityRoundTripper{responses: map[string]string{
+				"/_security/role":         fmt.Sprintf(`{"tigera_kibana_space_team-a": %s, "tigera_kibana_space_team-b": {}, "superuser": {}}`, currentRole),
+				"/_security/role_mapping": `{"tigera_kibana_space_team-a": {"enabled": true, "roles": [], "rules": {}}, "tigera_kibana_space_team-b": {}}`,
+			}}
+			eClient := mockElasticClient(&http.Client{Transport: rt}, baseURI)
+
+			ls := &operatorv1.LogStorage{Spec: operatorv1.LogStorageSpec{KibanaSpaces: []operatorv1.KibanaSpace{space}}}
+			Expect(eClient.SetKibanaSpaceRoles(context.Background(), ls)).NotTo(HaveOccurred())
+			Expect(rt.writes).To(Equal([]string{
+				"DELETE /_security/role_mapping/tigera_kibana_space_team-b",
+				"DELETE /_security/role/tigera_kibana_space_team-b",
+				"PUT /_security/role_mapping/tigera_kibana_space_team-a",
+			}))
+		})
+	})
+
+	Context("Tenancy", func() {
+		It("should build a role that can only read the indices of the clusters of the tenant", func() {
+			role := buildTenancyRole(operatorv1.LogStorageTenant{
+				Name:     "team-a",
+				Clusters: []string{"cluster-a", "cluster-b"},
+			})
+
+			body, err := json.Marshal(role)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(body).To(MatchJSON(`{
+  "indices": [
+    {
+      "names": ["tigera_secure_ee_*.cluster-a.*", "tigera_secure_ee_*.cluster-b.*"],
+      "privileges": ["read", "view_index_metadata"]
+    }
+  ],
+  "applications": [
+    {"application": "kibana-.kibana", "privileges": ["read"], "resources": ["space:team-a"]}
+  ],
+  "metadata": {"tigera_tenant_role": "team-a"}
+}`))
+		})
+	})
+
+	Context("Snapshots", func() {
+		It("should build the repository of the container of an Azure object store", func() {
+			repo := buildSnapshotRepository(operatorv1.SnapshotRepository{
+				Type:       operatorv1.SnapshotRepositoryAzure,
+				Bucket:     "logs",
+				BasePath:   "cluster-a",
+				SecretName: "azure-credentials",
+			})
+
+			body, err := json.Marshal(repo)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(body).To(MatchJSON(`{"type": "azure", "settings": {"container": "logs", "base_path": "cluster-a"}}`))
+		})
+
+		It("should build a policy with the default schedule and the retention of the snapshots", func() {
+			var minCount, maxCount int32 = 5, 50
+			policy := buildSnapshotPolicy(&operatorv1.Snapshots{
+				Repository: operatorv1.SnapshotRepository{Type: operatorv1.SnapshotRepositoryS3, Bucket: "logs", SecretName: "s3-credentials"},
+				Retention: &operatorv1.SnapshotRetention{
+					ExpireAfter: &metav1.Duration{Duration: 30 * 24 * time.Hour},
+					MinCount:    &minCount,
+					MaxCount:    &maxCount,
+				},
+			})
+
+			body, err := json.Marshal(policy)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(body).To(MatchJSON(`{
+  "schedule": "0 30 1 * * ?",
+  "name": "<tigera-snapshot-{now/d}>",
+  "repository": "tigera-snapshots",
+  "config": {"indices": ["tigera_secure_ee_*"], "include_global_state": false},
+  "retention": {"expire_after": "2592000s", "min_count": 5, "max_count": 50}
+}`))
+		})
+
+		It("should set the disk watermarks and reset those that aren't set", func() {
+			low, floodStage := int32(95), int32(99)
+			settings := buildDiskWatermarkSettings(&operatorv1.DiskWatermarks{Low: &low, FloodStage: &floodStage})
+
+			body, err := json.Marshal(settings)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(body).To(MatchJSON(`{
+  "cluster.routing.allocation.disk.watermark.low": "95%",
+  "cluster.routing.allocation.disk.watermark.high": null,
+  "cluster.routing.allocation.disk.watermark.flood_stage": "99%"
+}`))
+
+			body, err = json.Marshal(buildDiskWatermarkSettings(nil))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(body).To(MatchJSON(`{
+  "cluster.routing.allocation.disk.watermark.low": null,
+  "cluster.routing.allocation.disk.watermark.high": null,
+  "cluster.routing.allocation.disk.watermark.flood_stage": null
+}`))
+		})
+	})
 })
 
 type testRoundTripper struct {
@@ -181,6 +499,28 @@ func (t *testRoundTripper) RoundTrip(req *http.Request) (*http.Response, error)
 	}, nil
 }
 
+// securityRoundTripper responds to the GET requests of the security API with the responses of their paths, and records
+// the other requests.
+type securityRoundTripper struct {
+	responses map[string]string
+	writes    []string
+}
+
+func (t *securityRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	body := "{}"
+	if req.Method == "GET" {
+		body = t.responses[req.URL.Path]
+	} else {
+		t.writes = append(t.writes, req.Method+" "+req.URL.Path)
+	}
+	return &http.Response{
+		StatusCode: 200,
+		Request:    req,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+	}, nil
+}
+
 func mustOpen(name string) io.ReadCloser {
 	f, err := os.Open(name)
 	if err != nil {
@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -147,10 +148,21 @@ func ValidateImageSet(is *operator.ImageSet) error {
 	return fmt.Errorf("ImageSet %s: %s", is.Name, strings.Join(errMsgs, "; "))
 }
 
+// ResolveImages calls ResolveImages on each of the comps, concurrently.
 func ResolveImages(is *operator.ImageSet, comps ...render.Component) error {
+	errs := make([]error, len(comps))
+	var wg sync.WaitGroup
+	for i, comp := range comps {
+		wg.Add(1)
+		go func(i int, comp render.Component) {
+			defer wg.Done()
+			errs[i] = comp.ResolveImages(is)
+		}(i, comp)
+	}
+	wg.Wait()
+
 	errMsgs := []string{}
-	for _, comp := range comps {
-		err := comp.ResolveImages(is)
+	for _, err := range errs {
 		if err != nil {
 			errMsgs = append(errMsgs, err.Error())
 		}
@@ -162,3 +174,22 @@ func ResolveImages(is *operator.ImageSet, comps ...render.Component) error {
 
 	return fmt.Errorf("Invalid ImageSet: %s", strings.Join(errMsgs, ", "))
 }
+
+// RenderComponents gets the appropriate ImageSet, validates it, and then resolves the images of the comps and renders
+// their objects concurrently. See render.RenderComponents.
+func RenderComponents(ctx context.Context, c client.Client, v operator.ProductVariant, comps ...render.Component) ([]render.Component, error) {
+	imageSet, err := GetImageSet(ctx, c, v)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = ValidateImageSet(imageSet); err != nil {
+		return nil, err
+	}
+
+	rendered, err := render.RenderComponents(imageSet, comps...)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid ImageSet: %s", err)
+	}
+	return rendered, nil
+}
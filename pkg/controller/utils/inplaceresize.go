@@ -0,0 +1,65 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/tigera/operator/pkg/common"
+)
+
+// ResizePod sets the resources of the containers of the pod to those of the pod template, without restarting it.
+func ResizePod(ctx context.Context, clientset kubernetes.Interface, kubernetesVersion *common.VersionInfo, pod *corev1.Pod, template *corev1.PodTemplateSpec) error {
+	type containerResources struct {
+		Name      string                      `json:"name"`
+		Resources corev1.ResourceRequirements `json:"resources"`
+	}
+	var containers []containerResources
+	for _, desired := range template.Spec.Containers {
+		for _, c := range pod.Spec.Containers {
+			if c.Name == desired.Name && !equality.Semantic.DeepEqual(c.Resources, desired.Resources) {
+				containers = append(containers, containerResources{Name: desired.Name, Resources: desired.Resources})
+			}
+		}
+	}
+	if len(containers) == 0 {
+		return nil
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{"containers": containers},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Pods are resized through their resize subresource from Kubernetes 1.33, and by updating them before.
+	var subresources []string
+	if kubernetesVersion.ProvidesPodResizeSubresource() {
+		subresources = append(subresources, "resize")
+	}
+	if _, err := clientset.CoreV1().Pods(pod.Namespace).Patch(ctx, pod.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{}, subresources...); err != nil {
+		return fmt.Errorf("failed to resize pod %s/%s: %w", pod.Namespace, pod.Name, err)
+	}
+	return nil
+}
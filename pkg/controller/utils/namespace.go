@@ -0,0 +1,143 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/tigera/operator/pkg/common"
+)
+
+// namespaceTerminatingReason is the reason of the event that reports a rendered namespace stuck in Terminating.
+const namespaceTerminatingReason = "NamespaceTerminating"
+
+// NamespaceTerminatingError is returned when a namespace that a component renders is being deleted, e.g. because a
+// user deleted it. Nothing can be created in the namespace until its deletion has completed, so the component is
+// re-created once the namespace is gone.
+type NamespaceTerminatingError struct {
+	Namespace string
+	Diagnosis string
+}
+
+func (e *NamespaceTerminatingError) Error() string {
+	return fmt.Sprintf("namespace %s is being deleted and will be re-created once its deletion completes: %s", e.Namespace, e.Diagnosis)
+}
+
+// namespaceTerminationDiagnosis returns what blocks the deletion of the namespace, from the conditions that the
+// namespace controller sets on it and from the finalizers of the namespace.
+func namespaceTerminationDiagnosis(ns *v1.Namespace) string {
+	var reasons []string
+	for _, cond := range ns.Status.Conditions {
+		if cond.Status != v1.ConditionTrue {
+			continue
+		}
+		switch cond.Type {
+		case v1.NamespaceDeletionDiscoveryFailure,
+			v1.NamespaceDeletionGVParsingFailure,
+			v1.NamespaceDeletionContentFailure,
+			v1.NamespaceContentRemaining,
+			v1.NamespaceFinalizersRemaining:
+			reasons = append(reasons, cond.Message)
+		}
+	}
+	if len(ns.Spec.Finalizers) > 0 {
+		var finalizers []string
+		for _, f := range ns.Spec.Finalizers {
+			finalizers = append(finalizers, string(f))
+		}
+		reasons = append(reasons, fmt.Sprintf("namespace finalizers remaining: %s", strings.Join(finalizers, ", ")))
+	}
+	if len(ns.Finalizers) > 0 {
+		reasons = append(reasons, fmt.Sprintf("metadata finalizers remaining: %s", strings.Join(ns.Finalizers, ", ")))
+	}
+	if len(reasons) == 0 {
+		return "waiting for the contents of the namespace to be deleted"
+	}
+	return strings.Join(reasons, "; ")
+}
+
+// checkNamespacesTerminating returns a NamespaceTerminatingError if one of the namespaces in objs is being deleted.
+// The objects in the namespace can't be created while it is terminating, so rather than failing on each of them, the
+// component is not applied and the reason the namespace is stuck is reported in an event.
+func (c componentHandler) checkNamespacesTerminating(ctx context.Context, objs []client.Object) error {
+	for _, obj := range objs {
+		if _, ok := obj.(*v1.Namespace); !ok {
+			continue
+		}
+		ns := &v1.Namespace{}
+		if err := c.client.Get(ctx, types.NamespacedName{Name: obj.GetName()}, ns); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		if ns.DeletionTimestamp == nil {
+			continue
+		}
+
+		terminatingErr := &NamespaceTerminatingError{Namespace: ns.Name, Diagnosis: namespaceTerminationDiagnosis(ns)}
+		c.log.Info("Rendered namespace is being deleted", "namespace", ns.Name, "diagnosis", terminatingErr.Diagnosis)
+		if err := c.recordNamespaceTerminating(ctx, ns, terminatingErr.Diagnosis); err != nil {
+			c.log.Error(err, "Failed to record the event of the terminating namespace", "namespace", ns.Name)
+		}
+		return terminatingErr
+	}
+	return nil
+}
+
+// recordNamespaceTerminating surfaces the diagnosis of a terminating namespace as a warning event. The event is
+// created in the namespace of the operator, since nothing can be created in the terminating namespace, and it is
+// updated on each reconcile rather than creating a new event each time. The client reads events from the API server
+// rather than the cache, see main.go.
+func (c componentHandler) recordNamespaceTerminating(ctx context.Context, ns *v1.Namespace, diagnosis string) error {
+	now := metav1.Now()
+	event := &v1.Event{}
+	key := types.NamespacedName{Name: fmt.Sprintf("%s.terminating", ns.Name), Namespace: common.OperatorNamespace()}
+	if err := c.client.Get(ctx, key, event); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		event = &v1.Event{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+			InvolvedObject: v1.ObjectReference{
+				Kind:       "Namespace",
+				APIVersion: "v1",
+				Name:       ns.Name,
+				UID:        ns.UID,
+			},
+			Reason:         namespaceTerminatingReason,
+			Message:        diagnosis,
+			Type:           v1.EventTypeWarning,
+			Source:         v1.EventSource{Component: "tigera-operator"},
+			FirstTimestamp: now,
+			LastTimestamp:  now,
+			Count:          1,
+		}
+		return c.client.Create(ctx, event)
+	}
+	event.InvolvedObject.UID = ns.UID
+	event.Message = diagnosis
+	event.LastTimestamp = now
+	event.Count++
+	return c.client.Update(ctx, event)
+}
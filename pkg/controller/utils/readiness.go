@@ -0,0 +1,52 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// AddReadyzCheck adds the readiness check of a controller to the health probe endpoint of the operator. The check
+// fails until all the flags of the controller are ready, e.g. until its license and tier watches are established. It
+// is served at /readyz/<name>, and listed by /readyz?verbose.
+func AddReadyzCheck(mgr manager.Manager, name string, flags map[string]*ReadyFlag) error {
+	if err := mgr.AddReadyzCheck(name, ReadyzChecker(flags)); err != nil {
+		return fmt.Errorf("failed to add the readiness check of %s: %w", name, err)
+	}
+	return nil
+}
+
+// ReadyzChecker returns a readiness check that fails with the names of the flags that are not ready yet.
+func ReadyzChecker(flags map[string]*ReadyFlag) healthz.Checker {
+	return func(_ *http.Request) error {
+		var waiting []string
+		for name, flag := range flags {
+			if !flag.IsReady() {
+				waiting = append(waiting, name)
+			}
+		}
+		if len(waiting) == 0 {
+			return nil
+		}
+		sort.Strings(waiting)
+		return fmt.Errorf("waiting for %s", strings.Join(waiting, ", "))
+	}
+}
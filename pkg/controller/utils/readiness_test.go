@@ -0,0 +1,40 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("controller readiness checks", func() {
+	It("should fail with the flags that are not ready until all of them are", func() {
+		licenseAPIReady := &ReadyFlag{}
+		tierWatchReady := &ReadyFlag{}
+		check := ReadyzChecker(map[string]*ReadyFlag{"license-api": licenseAPIReady, "tier-watch": tierWatchReady})
+
+		Expect(check(nil)).To(MatchError("waiting for license-api, tier-watch"))
+
+		tierWatchReady.MarkAsReady()
+		Expect(check(nil)).To(MatchError("waiting for license-api"))
+
+		licenseAPIReady.MarkAsReady()
+		Expect(check(nil)).NotTo(HaveOccurred())
+	})
+
+	It("should be ready without flags", func() {
+		Expect(ReadyzChecker(nil)(nil)).NotTo(HaveOccurred())
+	})
+})
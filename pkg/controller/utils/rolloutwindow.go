@@ -0,0 +1,57 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+)
+
+const defaultRolloutWindowDuration = time.Hour
+
+// RolloutWindowOpen returns whether the rollout window is open at now. When it is closed, it also returns how long it
+// is until the window opens next. A nil window is always open. An error is returned if the window is invalid.
+func RolloutWindowOpen(window *operatorv1.RolloutWindow, now time.Time) (bool, time.Duration, error) {
+	if window == nil {
+		return true, 0, nil
+	}
+	schedule, err := cron.ParseStandard(window.Schedule)
+	if err != nil {
+		return false, 0, fmt.Errorf("invalid schedule %q: %w", window.Schedule, err)
+	}
+	duration := defaultRolloutWindowDuration
+	if window.Duration != nil {
+		if window.Duration.Duration <= 0 {
+			return false, 0, fmt.Errorf("the duration of the rollout window must be positive")
+		}
+		duration = window.Duration.Duration
+	}
+
+	// The window is open if it opened within the duration before now, and otherwise opens at the first time that the
+	// schedule fires after now.
+	now = now.UTC()
+	next := schedule.Next(now.Add(-duration))
+	if next.IsZero() {
+		return false, 0, fmt.Errorf("the schedule %q never fires", window.Schedule)
+	}
+	if !next.After(now) {
+		return true, 0, nil
+	}
+	return false, next.Sub(now), nil
+}
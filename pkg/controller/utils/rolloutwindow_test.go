@@ -0,0 +1,80 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+)
+
+var _ = Describe("rollout windows", func() {
+	// Saturday 2022-01-01 02:30 UTC.
+	saturday := time.Date(2022, time.January, 1, 2, 30, 0, 0, time.UTC)
+
+	It("should always be open without a window", func() {
+		open, _, err := RolloutWindowOpen(nil, saturday)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(open).To(BeTrue())
+	})
+
+	It("should be open within the duration after the schedule", func() {
+		open, _, err := RolloutWindowOpen(&operatorv1.RolloutWindow{Schedule: "0 2 * * 6"}, saturday)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(open).To(BeTrue())
+	})
+
+	It("should be closed after the duration and return when it opens next", func() {
+		window := &operatorv1.RolloutWindow{Schedule: "0 2 * * 6", Duration: &metav1.Duration{Duration: 10 * time.Minute}}
+		open, untilOpen, err := RolloutWindowOpen(window, saturday)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(open).To(BeFalse())
+		Expect(untilOpen).To(Equal(7*24*time.Hour - 30*time.Minute))
+	})
+
+	It("should accept the cron descriptors", func() {
+		open, untilOpen, err := RolloutWindowOpen(&operatorv1.RolloutWindow{Schedule: "@daily"}, saturday)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(open).To(BeFalse())
+		Expect(untilOpen).To(Equal(21*time.Hour + 30*time.Minute))
+	})
+
+	It("should match either of the day fields when both are restricted", func() {
+		// Saturday is the 1st, so the window opens on Saturdays and on the 15th.
+		open, untilOpen, err := RolloutWindowOpen(&operatorv1.RolloutWindow{Schedule: "30 3 15 * 6"}, saturday)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(open).To(BeFalse())
+		Expect(untilOpen).To(Equal(time.Hour))
+	})
+
+	DescribeTable("should reject invalid windows",
+		func(window *operatorv1.RolloutWindow) {
+			_, _, err := RolloutWindowOpen(window, saturday)
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("too few fields", &operatorv1.RolloutWindow{Schedule: "0 2 * *"}),
+		Entry("out of range", &operatorv1.RolloutWindow{Schedule: "0 24 * * *"}),
+		Entry("invalid step", &operatorv1.RolloutWindow{Schedule: "*/0 * * * *"}),
+		Entry("invalid range", &operatorv1.RolloutWindow{Schedule: "0 5-2 * * *"}),
+		Entry("never opens", &operatorv1.RolloutWindow{Schedule: "0 0 30 2 *"}),
+		Entry("non-positive duration", &operatorv1.RolloutWindow{Schedule: "0 2 * * *", Duration: &metav1.Duration{}}),
+	)
+})
@@ -43,6 +43,7 @@ import (
 	"github.com/tigera/operator/pkg/common"
 	"github.com/tigera/operator/pkg/controller/k8sapi"
 	"github.com/tigera/operator/pkg/render"
+	rmeta "github.com/tigera/operator/pkg/render/common/meta"
 )
 
 const (
@@ -50,6 +51,11 @@ const (
 	// This is for development and testing purposes only. Do not use this annotation
 	// for production, as this will cause problems with upgrade.
 	unsupportedIgnoreAnnotation = "unsupported.operator.tigera.io/ignore"
+
+	// IgnoreLabel marks an object that the operator renders as managed by the user when it is set to "true". The
+	// operator stops updating the object, while it keeps reconciling the other objects of its component, and reports
+	// the object as ignored in the TigeraStatus of the component.
+	IgnoreLabel = "operator.tigera.io/ignore"
 )
 
 var DefaultInstanceKey = client.ObjectKey{Name: "default"}
@@ -64,11 +70,14 @@ func ContextLoggerForResource(log logr.Logger, obj client.Object) logr.Logger {
 	return log.WithValues("Name", name, "Namespace", namespace, "Kind", gvk.Kind)
 }
 
-// IgnoreObject returns true if the object has been marked as ignored by the user,
-// and returns false otherwise.
+// IgnoreObject returns true if the object has been marked as ignored by the user, through either the ignore label or
+// the unsupported ignore annotation, and returns false otherwise.
 func IgnoreObject(obj runtime.Object) bool {
-	a := obj.(metav1.ObjectMetaAccessor).GetObjectMeta().GetAnnotations()
-	if val, ok := a[unsupportedIgnoreAnnotation]; ok && val == "true" {
+	meta := obj.(metav1.ObjectMetaAccessor).GetObjectMeta()
+	if val, ok := meta.GetLabels()[IgnoreLabel]; ok && val == "true" {
+		return true
+	}
+	if val, ok := meta.GetAnnotations()[unsupportedIgnoreAnnotation]; ok && val == "true" {
 		return true
 	}
 	return false
@@ -192,6 +201,19 @@ func LogStorageExists(ctx context.Context, cli client.Client) (bool, error) {
 	return true, nil
 }
 
+// GetLogStorage returns the LogStorage, or nil if it doesn't exist.
+func GetLogStorage(ctx context.Context, cli client.Client) (*operatorv1.LogStorage, error) {
+	logStorage := &operatorv1.LogStorage{}
+	err := cli.Get(ctx, DefaultTSEEInstanceKey, logStorage)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return logStorage, nil
+}
+
 func GetLogCollector(ctx context.Context, cli client.Client) (*operatorv1.LogCollector, error) {
 	logCollector := &operatorv1.LogCollector{}
 	err := cli.Get(ctx, DefaultTSEEInstanceKey, logCollector)
@@ -204,6 +226,38 @@ func GetLogCollector(ctx context.Context, cli client.Client) (*operatorv1.LogCol
 	return logCollector, nil
 }
 
+// containerRuntimeVersionPrefixes map the prefixes of the container runtime versions that the nodes report, e.g.
+// containerd://1.6.8, to the container runtimes.
+var containerRuntimeVersionPrefixes = map[string]operatorv1.ContainerRuntime{
+	"docker":     operatorv1.ContainerRuntimeDocker,
+	"containerd": operatorv1.ContainerRuntimeContainerd,
+	"cri-o":      operatorv1.ContainerRuntimeCRIO,
+}
+
+// DetectContainerRuntime returns the container runtime that the nodes report, or an empty runtime if none of the
+// nodes report a known one. It returns an error if the nodes report different runtimes, since the log files of the
+// containers are then found in different places depending on the node.
+func DetectContainerRuntime(ctx context.Context, cli client.Client) (operatorv1.ContainerRuntime, error) {
+	nodes := &corev1.NodeList{}
+	if err := cli.List(ctx, nodes); err != nil {
+		return "", err
+	}
+
+	var detected operatorv1.ContainerRuntime
+	for _, node := range nodes.Items {
+		prefix := strings.SplitN(node.Status.NodeInfo.ContainerRuntimeVersion, "://", 2)[0]
+		runtime, ok := containerRuntimeVersionPrefixes[prefix]
+		if !ok {
+			continue
+		}
+		if detected != "" && runtime != detected {
+			return "", fmt.Errorf("the nodes run different container runtimes (%s, %s), set the runtime in spec.containerLogs of the LogCollector and override it in its node pools", detected, runtime)
+		}
+		detected = runtime
+	}
+	return detected, nil
+}
+
 // FetchLicenseKey returns the license if it has been installed. It's useful
 // to prevent rollout of TSEE components that might require it.
 // It will return an error if the license is not installed/cannot be read
@@ -299,6 +353,62 @@ func GetNetworkingPullSecrets(i *operatorv1.InstallationSpec, c client.Client) (
 	return secrets, nil
 }
 
+// GetComponentPullSecrets returns the pull secrets of the Installation along with the pull secrets that a component
+// references. Like those of the Installation, the referenced secrets are read from the operator namespace. A secret
+// that is referenced by both is only returned once.
+func GetComponentPullSecrets(ctx context.Context, i *operatorv1.InstallationSpec, refs []corev1.LocalObjectReference, c client.Client) ([]*corev1.Secret, error) {
+	secrets, err := GetNetworkingPullSecrets(i, c)
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]bool{}
+	for _, s := range secrets {
+		names[s.Name] = true
+	}
+	for _, ref := range refs {
+		if names[ref.Name] {
+			continue
+		}
+		s := &corev1.Secret{}
+		if err := c.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: common.OperatorNamespace()}, s); err != nil {
+			return nil, fmt.Errorf("failed to read pull secret %s/%s: %w", common.OperatorNamespace(), ref.Name, err)
+		}
+		names[s.Name] = true
+		secrets = append(secrets, s)
+	}
+
+	return secrets, nil
+}
+
+// DeleteStalePullSecretCopies deletes the copies of pull secrets of the operator namespace in the given namespaces
+// that are not in pullSecrets, e.g. because a component no longer references them.
+func DeleteStalePullSecretCopies(ctx context.Context, c client.Client, pullSecrets []*corev1.Secret, namespaces ...string) error {
+	current := map[string]bool{}
+	for _, s := range pullSecrets {
+		current[s.Name] = true
+	}
+	for _, ns := range namespaces {
+		list := &corev1.SecretList{}
+		if err := c.List(ctx, list, client.InNamespace(ns)); err != nil {
+			return fmt.Errorf("failed to list the secrets in namespace %s: %w", ns, err)
+		}
+		for i := range list.Items {
+			s := &list.Items[i]
+			if s.Type != corev1.SecretTypeDockerConfigJson && s.Type != corev1.SecretTypeDockercfg {
+				continue
+			}
+			if !strings.HasPrefix(s.Annotations[rmeta.CopySourceAnnotation], common.OperatorNamespace()+"/") || current[s.Name] {
+				continue
+			}
+			if err := c.Delete(ctx, s); err != nil && !kerrors.IsNotFound(err) {
+				return fmt.Errorf("failed to delete the stale pull secret %s/%s: %w", s.Namespace, s.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
 // Return the ManagementCluster CR if present. No error is returned if it was not found.
 func GetManagementCluster(ctx context.Context, c client.Client) (*operatorv1.ManagementCluster, error) {
 	managementCluster := &operatorv1.ManagementCluster{}
@@ -29,6 +29,7 @@ import (
 
 	opv1 "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/apis"
+	"github.com/tigera/operator/pkg/common"
 	"github.com/tigera/operator/pkg/render"
 
 	apps "k8s.io/api/apps/v1"
@@ -95,6 +96,124 @@ var _ = Describe("Utils elasticsearch license type tests", func() {
 
 })
 
+var _ = Describe("Container runtime detection tests", func() {
+	var (
+		c   client.Client
+		ctx context.Context
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(apis.AddToScheme(scheme)).NotTo(HaveOccurred())
+		Expect(v1.SchemeBuilder.AddToScheme(scheme)).NotTo(HaveOccurred())
+		c = fake.NewClientBuilder().WithScheme(scheme).Build()
+		ctx = context.Background()
+	})
+
+	createNode := func(name, runtimeVersion string) {
+		Expect(c.Create(ctx, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status:     corev1.NodeStatus{NodeInfo: corev1.NodeSystemInfo{ContainerRuntimeVersion: runtimeVersion}},
+		})).NotTo(HaveOccurred())
+	}
+
+	It("should detect the runtime that the nodes report", func() {
+		createNode("node1", "containerd://1.6.8")
+		createNode("node2", "containerd://1.6.9")
+		createNode("node3", "unknown://1.0")
+
+		runtime, err := DetectContainerRuntime(ctx, c)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(runtime).To(Equal(opv1.ContainerRuntimeContainerd))
+	})
+
+	It("should return an error when the nodes report different runtimes", func() {
+		createNode("node1", "docker://20.10.17")
+		createNode("node2", "containerd://1.6.8")
+		createNode("node3", "containerd://1.6.8")
+
+		_, err := DetectContainerRuntime(ctx, c)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should return an empty runtime when the runtime can't be detected", func() {
+		createNode("node1", "")
+
+		runtime, err := DetectContainerRuntime(ctx, c)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(runtime).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Component pull secrets tests", func() {
+	var (
+		c       client.Client
+		ctx     context.Context
+		install *opv1.InstallationSpec
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(apis.AddToScheme(scheme)).NotTo(HaveOccurred())
+		Expect(v1.SchemeBuilder.AddToScheme(scheme)).NotTo(HaveOccurred())
+		c = fake.NewClientBuilder().WithScheme(scheme).Build()
+		ctx = context.Background()
+
+		for _, s := range []struct{ name, ns string }{
+			{"pull-secret", common.OperatorNamespace()},
+			{"es-registry", common.OperatorNamespace()},
+			{"fluentd-registry", "registries"},
+		} {
+			Expect(c.Create(ctx, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: s.name, Namespace: s.ns}})).NotTo(HaveOccurred())
+		}
+		install = &opv1.InstallationSpec{ImagePullSecrets: []corev1.LocalObjectReference{{Name: "pull-secret"}}}
+	})
+
+	It("should return the pull secrets of the installation and the referenced pull secrets", func() {
+		secrets, err := GetComponentPullSecrets(ctx, install, []corev1.LocalObjectReference{{Name: "es-registry"}, {Name: "pull-secret"}}, c)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(secrets).To(HaveLen(2))
+		Expect(secrets[0].Name).To(Equal("pull-secret"))
+		Expect(secrets[1].Name).To(Equal("es-registry"))
+		Expect(secrets[1].Namespace).To(Equal(common.OperatorNamespace()))
+	})
+
+	It("should only read the referenced pull secrets from the operator namespace", func() {
+		_, err := GetComponentPullSecrets(ctx, install, []corev1.LocalObjectReference{{Name: "fluentd-registry"}}, c)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should delete the copies of pull secrets that are no longer used", func() {
+		for _, s := range []struct{ name, source string }{
+			{"pull-secret", common.OperatorNamespace() + "/pull-secret"},
+			{"es-registry", common.OperatorNamespace() + "/es-registry"},
+			{"user-registry", ""},
+			{"other-registry", "registries/other-registry"},
+		} {
+			copied := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: s.name, Namespace: "tigera-elasticsearch"},
+				Type:       corev1.SecretTypeDockerConfigJson,
+			}
+			if s.source != "" {
+				copied.Annotations = map[string]string{"operator.tigera.io/copy-source": s.source}
+			}
+			Expect(c.Create(ctx, copied)).NotTo(HaveOccurred())
+		}
+		secrets, err := GetComponentPullSecrets(ctx, install, nil, c)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(DeleteStalePullSecretCopies(ctx, c, secrets, "tigera-elasticsearch")).NotTo(HaveOccurred())
+
+		list := &corev1.SecretList{}
+		Expect(c.List(ctx, list, client.InNamespace("tigera-elasticsearch"))).NotTo(HaveOccurred())
+		var names []string
+		for _, s := range list.Items {
+			names = append(names, s.Name)
+		}
+		Expect(names).To(ConsistOf("pull-secret", "user-registry", "other-registry"))
+	})
+})
+
 var _ = Describe("Tigera License polling test", func() {
 	var client fakeClient
 	var discovery *fakeDiscovery
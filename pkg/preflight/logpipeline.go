@@ -0,0 +1,347 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package preflight validates the prerequisites of the log pipeline before LogStorage and LogCollector are created,
+// e.g. in air-gapped clusters, where missing images, StorageClasses or APIs otherwise only show up as degraded
+// components after everything is rendered.
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	storagev1 "k8s.io/api/storage/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/components"
+	"github.com/tigera/operator/pkg/controller/logstorage"
+	"github.com/tigera/operator/pkg/controller/utils"
+	"github.com/tigera/operator/pkg/controller/utils/imageset"
+)
+
+// Status is the outcome of a check.
+type Status string
+
+const (
+	StatusPass Status = "PASS"
+	// StatusWarn is a prerequisite that is met, but in a way that is likely to cause problems later.
+	StatusWarn Status = "WARN"
+	StatusFail Status = "FAIL"
+)
+
+// Result is the outcome of the check of a prerequisite.
+type Result struct {
+	Check   string
+	Status  Status
+	Message string
+}
+
+// Report holds the outcomes of all the checks.
+type Report struct {
+	Results []Result
+}
+
+func (r *Report) add(check string, status Status, format string, args ...interface{}) {
+	r.Results = append(r.Results, Result{Check: check, Status: status, Message: fmt.Sprintf(format, args...)})
+}
+
+// Passed returns whether none of the checks failed.
+func (r *Report) Passed() bool {
+	for _, res := range r.Results {
+		if res.Status == StatusFail {
+			return false
+		}
+	}
+	return true
+}
+
+// Print writes a line per check, followed by the overall result.
+func (r *Report) Print(w io.Writer) {
+	for _, res := range r.Results {
+		fmt.Fprintf(w, "%s\t%s: %s\n", res.Status, res.Check, res.Message)
+	}
+	if r.Passed() {
+		fmt.Fprintln(w, "Result: PASS")
+	} else {
+		fmt.Fprintln(w, "Result: FAIL")
+	}
+}
+
+// requiredAPI is a kind that the log pipeline needs, served by any of the group versions.
+type requiredAPI struct {
+	groupVersions []string
+	kind          string
+	hint          string
+}
+
+var logPipelineAPIs = []requiredAPI{
+	{groupVersions: []string{"operator.tigera.io/v1"}, kind: "LogStorage", hint: "the operator CRDs must be installed"},
+	{groupVersions: []string{"operator.tigera.io/v1"}, kind: "LogCollector", hint: "the operator CRDs must be installed"},
+	{groupVersions: []string{"storage.k8s.io/v1"}, kind: "StorageClass"},
+	{groupVersions: []string{"policy/v1", "policy/v1beta1"}, kind: "PodDisruptionBudget"},
+	{groupVersions: []string{"batch/v1", "batch/v1beta1"}, kind: "CronJob"},
+	{groupVersions: []string{"projectcalico.org/v3"}, kind: "Tier", hint: "the Tigera API server must be installed"},
+	{groupVersions: []string{"projectcalico.org/v3"}, kind: "NetworkPolicy", hint: "the Tigera API server must be installed"},
+}
+
+// certificateManagementAPI is needed when the certificates of the log pipeline are signed by the certificate
+// management of the Installation.
+var certificateManagementAPI = requiredAPI{groupVersions: []string{"certificates.k8s.io/v1"}, kind: "CertificateSigningRequest"}
+
+// LogPipelineValidator checks the prerequisites of the log pipeline: that the registries of the images of LogStorage
+// and LogCollector, as resolved from the Installation and the ImageSet, are reachable, that the StorageClasses of
+// Elasticsearch exist and suit it, and that the APIs that the components are rendered with are served.
+type LogPipelineValidator struct {
+	Client    client.Client
+	Clientset kubernetes.Interface
+	// HTTPClient reaches the image registries. If nil, a client with a 10 second timeout is used.
+	HTTPClient *http.Client
+}
+
+// Validate runs all the checks and returns their report. Nothing is created or changed in the cluster.
+func (v *LogPipelineValidator) Validate(ctx context.Context) *Report {
+	report := &Report{}
+
+	_, install, err := utils.GetInstallation(ctx, v.Client)
+	if err != nil {
+		if !kerrors.IsNotFound(err) {
+			report.add("Installation", StatusFail, "failed to get the Installation: %s", err)
+			return report
+		}
+		report.add("Installation", StatusWarn, "the Installation doesn't exist, the default registries of the images are checked")
+		install = &operatorv1.InstallationSpec{}
+	}
+
+	ls := &operatorv1.LogStorage{}
+	if err = v.Client.Get(ctx, utils.DefaultTSEEInstanceKey, ls); err != nil {
+		if !kerrors.IsNotFound(err) {
+			report.add("LogStorage", StatusFail, "failed to get the LogStorage: %s", err)
+			return report
+		}
+		ls = nil
+	}
+
+	// The operator creates the default StorageClass of Elasticsearch on the clusters of the infrastructure providers
+	// of Cluster API. If the provider can't be discovered, the StorageClass is expected to exist.
+	provider, err := utils.AutoDiscoverInfrastructureProvider(ctx, v.Clientset)
+	if err != nil {
+		provider = common.InfrastructureProviderNone
+	}
+
+	v.checkRegistries(ctx, report, install)
+	v.checkStorageClasses(ctx, report, ls, provider)
+	v.checkAPIs(report, install)
+	return report
+}
+
+// logPipelineImages returns the images of the components of LogStorage and LogCollector.
+func logPipelineImages(install *operatorv1.InstallationSpec, is *operatorv1.ImageSet) ([]string, error) {
+	reg, path, prefix := install.Registry, install.ImagePath, install.ImagePrefix
+
+	var images []string
+	var firstErr error
+	add := func(image string, err error) {
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			return
+		}
+		images = append(images, image)
+	}
+
+	if operatorv1.IsFIPSModeEnabled(install.FIPSMode) {
+		add(components.GetReference(components.ComponentElasticsearchFIPS, reg, path, prefix, is))
+	} else {
+		add(components.GetReference(components.ComponentElasticsearch, reg, path, prefix, is))
+	}
+	add(components.GetReference(components.ComponentKibana, reg, path, prefix, is))
+	add(components.GetReference(components.ComponentElasticsearchOperator, reg, path, prefix, is))
+	add(components.GetReference(components.ComponentESGateway, reg, path, prefix, is))
+	add(components.GetReference(components.ComponentElasticsearchMetrics, reg, path, prefix, is))
+	add(components.GetReference(components.ComponentEsCurator, reg, path, prefix, is))
+	add(components.GetReference(components.ComponentFluentd, reg, path, prefix, is))
+	add(components.GetReference(components.ComponentFluentdWindows, reg, path, prefix, is))
+	if install.CertificateManagement != nil {
+		add(components.GetReference(components.ComponentCSRInitContainer, reg, path, prefix, is))
+	}
+	return images, firstErr
+}
+
+// registryHost returns the host of the registry of the image. An image without a registry host is pulled from the
+// registry of the Installation, or from docker.io if the Installation has none.
+func registryHost(image, registry string) string {
+	if parts := strings.SplitN(image, "/", 2); len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		return parts[0]
+	}
+	if registry = strings.TrimPrefix(strings.TrimPrefix(registry, "https://"), "http://"); registry != "" {
+		return strings.SplitN(registry, "/", 2)[0]
+	}
+	return "registry-1.docker.io"
+}
+
+// checkRegistries checks that the registry of each image of the log pipeline serves the registry API. The credentials
+// of the pull secrets and the presence of the images themselves aren't verified.
+func (v *LogPipelineValidator) checkRegistries(ctx context.Context, report *Report, install *operatorv1.InstallationSpec) {
+	// The log pipeline is only installed with Calico Enterprise, so its images are those of the Enterprise ImageSet.
+	is, err := imageset.GetImageSet(ctx, v.Client, operatorv1.TigeraSecureEnterprise)
+	if err != nil {
+		report.add("Images", StatusFail, "%s", err)
+		return
+	}
+	images, err := logPipelineImages(install, is)
+	if err != nil {
+		report.add("Images", StatusFail, "%s", err)
+		return
+	}
+
+	imagesByHost := map[string][]string{}
+	for _, image := range images {
+		host := registryHost(image, install.Registry)
+		imagesByHost[host] = append(imagesByHost[host], image)
+	}
+	var hosts []string
+	for host := range imagesByHost {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	httpClient := v.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	for _, host := range hosts {
+		check := fmt.Sprintf("Registry %s", host)
+		images := strings.Join(imagesByHost[host], ", ")
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("https://%s/v2/", host), nil)
+		if err != nil {
+			report.add(check, StatusFail, "%s", err)
+			continue
+		}
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			report.add(check, StatusFail, "the registry of %s is unreachable: %s", images, err)
+			continue
+		}
+		resp.Body.Close()
+		// Registries that require authentication answer the version check with 401.
+		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusUnauthorized {
+			report.add(check, StatusFail, "the registry of %s answered the registry API with %s", images, resp.Status)
+			continue
+		}
+		report.add(check, StatusPass, "the registry of %s is reachable", images)
+	}
+}
+
+// checkStorageClasses checks that the StorageClasses of Elasticsearch exist, and warns about those that can't expand
+// their volumes or bind them before the pods of Elasticsearch are scheduled. The StorageClass that the operator creates
+// for the infrastructure provider passes whether it exists yet or not.
+func (v *LogPipelineValidator) checkStorageClasses(ctx context.Context, report *Report, ls *operatorv1.LogStorage, provider common.InfrastructureProvider) {
+	names := []string{operatorv1.DefaultElasticsearchStorageClass}
+	if ls != nil {
+		names = nil
+		for _, name := range ls.Spec.StorageClassNames() {
+			if name == "" {
+				name = operatorv1.DefaultElasticsearchStorageClass
+			}
+			names = append(names, name)
+		}
+	}
+
+	for _, name := range names {
+		check := fmt.Sprintf("StorageClass %s", name)
+		sc := &storagev1.StorageClass{}
+		if err := v.Client.Get(ctx, client.ObjectKey{Name: name}, sc); err != nil {
+			if kerrors.IsNotFound(err) && logstorage.CreatesDefaultStorageClass(provider, name) {
+				report.add(check, StatusPass, "the operator creates the StorageClass for the %s infrastructure provider", provider)
+			} else if kerrors.IsNotFound(err) {
+				report.add(check, StatusFail, "the StorageClass doesn't exist, it must be created")
+			} else {
+				report.add(check, StatusFail, "failed to get the StorageClass: %s", err)
+			}
+			continue
+		}
+		if logstorage.IsDefaultStorageClass(sc) {
+			report.add(check, StatusPass, "created by the operator, provisioner %s", sc.Provisioner)
+			continue
+		}
+
+		var warnings []string
+		if sc.VolumeBindingMode == nil || *sc.VolumeBindingMode != storagev1.VolumeBindingWaitForFirstConsumer {
+			warnings = append(warnings, "it binds volumes immediately, so they may be provisioned in other zones than the Elasticsearch pods")
+		}
+		if sc.AllowVolumeExpansion == nil || !*sc.AllowVolumeExpansion {
+			warnings = append(warnings, "it doesn't allow volume expansion, so increasing the storage of Elasticsearch replaces its nodes")
+		}
+		if len(warnings) > 0 {
+			report.add(check, StatusWarn, "provisioner %s: %s", sc.Provisioner, strings.Join(warnings, "; "))
+			continue
+		}
+		report.add(check, StatusPass, "provisioner %s", sc.Provisioner)
+	}
+}
+
+// checkAPIs checks that the APIs that LogStorage and LogCollector need are served.
+func (v *LogPipelineValidator) checkAPIs(report *Report, install *operatorv1.InstallationSpec) {
+	apis := logPipelineAPIs
+	if install.CertificateManagement != nil {
+		apis = append(apis[:len(apis):len(apis)], certificateManagementAPI)
+	}
+
+	for _, api := range apis {
+		check := fmt.Sprintf("API %s", api.kind)
+		servedBy, err := v.servedBy(api)
+		if err != nil {
+			report.add(check, StatusFail, "failed to discover the API: %s", err)
+			continue
+		}
+		if servedBy == "" {
+			msg := fmt.Sprintf("%s isn't served by %s", api.kind, strings.Join(api.groupVersions, " or "))
+			if api.hint != "" {
+				msg = fmt.Sprintf("%s, %s", msg, api.hint)
+			}
+			report.add(check, StatusFail, "%s", msg)
+			continue
+		}
+		report.add(check, StatusPass, "served by %s", servedBy)
+	}
+}
+
+// servedBy returns the first group version that serves the kind of the API, or "" if none does.
+func (v *LogPipelineValidator) servedBy(api requiredAPI) (string, error) {
+	for _, gv := range api.groupVersions {
+		resources, err := v.Clientset.Discovery().ServerResourcesForGroupVersion(gv)
+		if err != nil {
+			if kerrors.IsNotFound(err) {
+				continue
+			}
+			return "", err
+		}
+		for _, r := range resources.APIResources {
+			if r.Kind == api.kind {
+				return gv, nil
+			}
+		}
+	}
+	return "", nil
+}
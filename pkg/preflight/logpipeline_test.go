@@ -0,0 +1,188 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preflight_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kfake "k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/apis"
+	"github.com/tigera/operator/pkg/preflight"
+	"github.com/tigera/operator/pkg/ptr"
+)
+
+var _ = Describe("Log pipeline preflight tests", func() {
+	var cli client.Client
+	var clientset *kfake.Clientset
+	var registry *httptest.Server
+	var validator *preflight.LogPipelineValidator
+	ctx := context.Background()
+
+	apiResources := func(groupVersion string, kinds ...string) *metav1.APIResourceList {
+		list := &metav1.APIResourceList{GroupVersion: groupVersion}
+		for _, kind := range kinds {
+			list.APIResources = append(list.APIResources, metav1.APIResource{Kind: kind})
+		}
+		return list
+	}
+
+	statuses := func(report *preflight.Report) map[string]preflight.Status {
+		s := map[string]preflight.Status{}
+		for _, res := range report.Results {
+			s[res.Check] = res.Status
+		}
+		return s
+	}
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(apis.AddToScheme(scheme)).NotTo(HaveOccurred())
+		Expect(storagev1.SchemeBuilder.AddToScheme(scheme)).NotTo(HaveOccurred())
+		cli = fake.NewClientBuilder().WithScheme(scheme).Build()
+
+		clientset = kfake.NewSimpleClientset()
+		clientset.Resources = []*metav1.APIResourceList{
+			apiResources("operator.tigera.io/v1", "LogStorage", "LogCollector"),
+			apiResources("storage.k8s.io/v1", "StorageClass"),
+			apiResources("policy/v1", "PodDisruptionBudget"),
+			apiResources("batch/v1", "CronJob"),
+			apiResources("projectcalico.org/v3", "Tier", "NetworkPolicy"),
+		}
+
+		registry = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/v2/" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+		}))
+
+		Expect(cli.Create(ctx, &operatorv1.Installation{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+			Spec: operatorv1.InstallationSpec{
+				Variant:  operatorv1.TigeraSecureEnterprise,
+				Registry: strings.TrimPrefix(registry.URL, "https://") + "/",
+			},
+		})).NotTo(HaveOccurred())
+
+		validator = &preflight.LogPipelineValidator{Client: cli, Clientset: clientset, HTTPClient: registry.Client()}
+	})
+
+	AfterEach(func() {
+		registry.Close()
+	})
+
+	createStorageClass := func(name string, expandable bool, mode storagev1.VolumeBindingMode) {
+		Expect(cli.Create(ctx, &storagev1.StorageClass{
+			ObjectMeta:           metav1.ObjectMeta{Name: name},
+			Provisioner:          "ebs.csi.aws.com",
+			AllowVolumeExpansion: ptr.BoolToPtr(expandable),
+			VolumeBindingMode:    &mode,
+		})).NotTo(HaveOccurred())
+	}
+
+	It("should pass when all the prerequisites are met", func() {
+		createStorageClass(operatorv1.DefaultElasticsearchStorageClass, true, storagev1.VolumeBindingWaitForFirstConsumer)
+
+		report := validator.Validate(ctx)
+		Expect(report.Passed()).To(BeTrue())
+		host := strings.TrimPrefix(registry.URL, "https://")
+		Expect(statuses(report)).To(Equal(map[string]preflight.Status{
+			"Registry " + host:                  preflight.StatusPass,
+			"StorageClass tigera-elasticsearch": preflight.StatusPass,
+			"API LogStorage":                    preflight.StatusPass,
+			"API LogCollector":                  preflight.StatusPass,
+			"API StorageClass":                  preflight.StatusPass,
+			"API PodDisruptionBudget":           preflight.StatusPass,
+			"API CronJob":                       preflight.StatusPass,
+			"API Tier":                          preflight.StatusPass,
+			"API NetworkPolicy":                 preflight.StatusPass,
+		}))
+
+		out := &bytes.Buffer{}
+		report.Print(out)
+		Expect(out.String()).To(HaveSuffix("Result: PASS\n"))
+	})
+
+	It("should check the StorageClasses of the NodeSets of the LogStorage", func() {
+		createStorageClass("tigera-elasticsearch", false, storagev1.VolumeBindingImmediate)
+		Expect(cli.Create(ctx, &operatorv1.LogStorage{
+			ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"},
+			Spec: operatorv1.LogStorageSpec{
+				StorageClassName: "tigera-elasticsearch",
+				Nodes: &operatorv1.Nodes{
+					Count:    2,
+					NodeSets: []operatorv1.NodeSet{{StorageClassName: "fast"}},
+				},
+			},
+		})).NotTo(HaveOccurred())
+
+		report := validator.Validate(ctx)
+		Expect(report.Passed()).To(BeFalse())
+		Expect(statuses(report)).To(HaveKeyWithValue("StorageClass tigera-elasticsearch", preflight.StatusWarn))
+		Expect(statuses(report)).To(HaveKeyWithValue("StorageClass fast", preflight.StatusFail))
+	})
+
+	It("should pass the default StorageClass that the operator creates for the infrastructure provider", func() {
+		_, err := clientset.CoreV1().Nodes().Create(ctx, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node", Annotations: map[string]string{"cluster.x-k8s.io/cluster-name": "cluster"}},
+			Spec:       corev1.NodeSpec{ProviderID: "aws:///us-west-2a/i-0123456789"},
+		}, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		report := validator.Validate(ctx)
+		Expect(statuses(report)).To(HaveKeyWithValue("StorageClass tigera-elasticsearch", preflight.StatusPass))
+
+		// Once created, the settings of the StorageClass are those of the operator.
+		Expect(cli.Create(ctx, &storagev1.StorageClass{
+			ObjectMeta:  metav1.ObjectMeta{Name: operatorv1.DefaultElasticsearchStorageClass, Labels: map[string]string{"operator.tigera.io/logstorage-default-storage-class": "true"}},
+			Provisioner: "ebs.csi.aws.com",
+		})).NotTo(HaveOccurred())
+		report = validator.Validate(ctx)
+		Expect(statuses(report)).To(HaveKeyWithValue("StorageClass tigera-elasticsearch", preflight.StatusPass))
+	})
+
+	It("should fail when the registry is unreachable or an API isn't served", func() {
+		createStorageClass(operatorv1.DefaultElasticsearchStorageClass, true, storagev1.VolumeBindingWaitForFirstConsumer)
+		registry.Close()
+		clientset.Resources = clientset.Resources[:4]
+
+		report := validator.Validate(ctx)
+		Expect(report.Passed()).To(BeFalse())
+		s := statuses(report)
+		Expect(s).To(HaveKeyWithValue("Registry "+strings.TrimPrefix(registry.URL, "https://"), preflight.StatusFail))
+		Expect(s).To(HaveKeyWithValue("API Tier", preflight.StatusFail))
+		Expect(s).To(HaveKeyWithValue("API CronJob", preflight.StatusPass))
+
+		out := &bytes.Buffer{}
+		report.Print(out)
+		Expect(out.String()).To(HaveSuffix("Result: FAIL\n"))
+	})
+})
@@ -0,0 +1,372 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"fmt"
+	"time"
+
+	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/components"
+	"github.com/tigera/operator/pkg/ptr"
+	"github.com/tigera/operator/pkg/render/common/disruptionpolicy"
+	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
+	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/networkpolicy"
+	"github.com/tigera/operator/pkg/render/common/secret"
+	"github.com/tigera/operator/pkg/tls/certificatemanagement"
+)
+
+const (
+	ArchiveRestoreName       = "tigera-archive-restore"
+	ArchiveRestorePolicyName = networkpolicy.TigeraComponentPolicyPrefix + "archive-restore"
+
+	// ArchiveRestoreUserSecret holds the credentials of the Elasticsearch user of the jobs, which can only read the
+	// mappings of the flow logs and write the indices of the restores.
+	ArchiveRestoreUserSecret = "tigera-archive-restore-user"
+	ArchiveRestoreUserName   = "tigera-archive-restore"
+	ArchiveRestoreRoleName   = "tigera_archive_restore"
+
+	// ArchiveRestoreHashAnnotation holds the hash of the period and the S3 store that the job restores, so that the job
+	// is recreated when the restore is changed.
+	ArchiveRestoreHashAnnotation = "hash.operator.tigera.io/archive-restore"
+
+	// ArchiveRestoreIndexPrefix is the prefix of the indices that the archived flow logs are restored into. It doesn't
+	// match the index templates of the flow logs, so the restored indices aren't managed by their ILM policies.
+	ArchiveRestoreIndexPrefix = "tigera_secure_ee_restored_flows."
+
+	// DefaultArchiveRestoreTTL is how long the index of a restore is kept after the restore completes by default.
+	DefaultArchiveRestoreTTL = 24 * time.Hour
+)
+
+var ArchiveRestoreSourceEntityRule = networkpolicy.CreateSourceEntityRule(ElasticsearchNamespace, ArchiveRestoreName)
+
+// archiveRestoreScript restores the flow logs that fluentd archived to S3 in the hours of the period into the index,
+// through the Elasticsearch gateway. It reads S3 with the boto3 client that the curator image ships for its AWS
+// support. The index is recreated with the mappings of the most recent flow log index, so that the restored flow logs
+// are searched like the live ones. The fluentd S3 output writes the flow logs in gzipped JSON lines under
+// <bucket path>/flows/, in objects whose names start with the hour of their logs. A failure is reported through the
+// termination message of the container.
+const archiveRestoreScript = `
+import datetime
+import gzip
+import json
+import os
+import ssl
+import sys
+import urllib.error
+import urllib.request
+
+import boto3
+
+
+def fail(msg):
+    with open('/dev/termination-log', 'w') as f:
+        f.write(msg)
+    sys.exit(msg)
+
+
+context = ssl.create_default_context(cafile=os.environ['CA_CRT_PATH'])
+password_manager = urllib.request.HTTPPasswordMgrWithPriorAuth()
+password_manager.add_password(None, os.environ['ELASTICSEARCH_URL'], os.environ['ELASTIC_USERNAME'],
+                              os.environ['ELASTIC_PASSWORD'], is_authenticated=True)
+opener = urllib.request.build_opener(urllib.request.HTTPSHandler(context=context),
+                                     urllib.request.HTTPBasicAuthHandler(password_manager))
+
+
+def es(method, path, body=None, content_type='application/json'):
+    data = None if body is None else body.encode()
+    req = urllib.request.Request(os.environ['ELASTICSEARCH_URL'] + path, data=data, method=method,
+                                 headers={'Content-Type': content_type})
+    try:
+        with opener.open(req) as res:
+            return res.status, res.read().decode()
+    except urllib.error.HTTPError as e:
+        return e.code, e.read().decode()
+
+
+index = os.environ['INDEX']
+status, body = es('GET', '/tigera_secure_ee_flows*/_mapping?ignore_unavailable=true&allow_no_indices=true')
+if status != 200:
+    fail('Failed to get the mappings of the flow logs: ' + body)
+indices = json.loads(body)
+mappings = indices[max(indices)]['mappings'] if indices else {}
+
+# A previous attempt may have restored part of the flow logs.
+status, body = es('DELETE', '/' + index)
+if status not in (200, 404):
+    fail('Failed to delete the index %s: %s' % (index, body))
+status, body = es('PUT', '/' + index, json.dumps({'settings': {'number_of_replicas': 0}, 'mappings': mappings}))
+if status != 200:
+    fail('Failed to create the index %s: %s' % (index, body))
+
+s3 = boto3.client('s3', region_name=os.environ['AWS_REGION'], aws_access_key_id=os.environ['AWS_KEY_ID'],
+                  aws_secret_access_key=os.environ['AWS_SECRET_KEY'])
+bucket = os.environ['S3_BUCKET_NAME']
+path = os.environ['S3_BUCKET_PATH'].strip('/')
+hour = datetime.datetime.strptime(os.environ['START'], '%Y-%m-%dT%H:%M:%SZ').replace(minute=0, second=0)
+stop = datetime.datetime.strptime(os.environ['END'], '%Y-%m-%dT%H:%M:%SZ')
+count = 0
+while hour < stop:
+    prefix = '/'.join(part for part in [path, 'flows', hour.strftime('%Y%m%d%H')] if part)
+    for page in s3.get_paginator('list_objects_v2').paginate(Bucket=bucket, Prefix=prefix):
+        for obj in page.get('Contents', []):
+            data = s3.get_object(Bucket=bucket, Key=obj['Key'])['Body'].read()
+            if obj['Key'].endswith('.gz'):
+                data = gzip.decompress(data)
+            logs = [line.strip() for line in data.decode().splitlines() if line.strip()]
+            for i in range(0, len(logs), 1000):
+                batch = logs[i:i + 1000]
+                status, body = es('POST', '/%s/_bulk' % index, ''.join('{"index":{}}\n%s\n' % log for log in batch),
+                                  'application/x-ndjson')
+                if status != 200 or json.loads(body)['errors']:
+                    fail('Failed to index the flow logs of %s: %s' % (obj['Key'], body))
+                count += len(batch)
+    hour += datetime.timedelta(hours=1)
+
+es('POST', '/%s/_refresh' % index)
+print('Restored %d flow logs into %s' % (count, index))
+`
+
+// ArchiveRestoreS3Domains returns the domains of the endpoints of the bucket of the S3 store, in the virtual-hosted and
+// the path style.
+func ArchiveRestoreS3Domains(s3 *operatorv1.S3StoreSpec) []string {
+	endpoint := "s3.amazonaws.com"
+	if s3.Region != "" {
+		endpoint = fmt.Sprintf("s3.%s.amazonaws.com", s3.Region)
+	}
+	return []string{s3.BucketName + "." + endpoint, endpoint}
+}
+
+// ArchiveRestoreIndex returns the index that the flow logs of the restore are restored into.
+func ArchiveRestoreIndex(restore operatorv1.ArchiveRestore) string {
+	return ArchiveRestoreIndexPrefix + restore.Name
+}
+
+// ArchiveRestoreJobName returns the name of the job of the restore.
+func ArchiveRestoreJobName(restore operatorv1.ArchiveRestore) string {
+	return ArchiveRestoreName + "-" + restore.Name
+}
+
+// ArchiveRestoreHash returns the hash of the period and the S3 store that the job of the restore restores, which the
+// job is annotated with.
+func ArchiveRestoreHash(cfg *ArchiveRestoreConfiguration, restore operatorv1.ArchiveRestore) string {
+	return rmeta.AnnotationHash([]interface{}{restore.Start.UTC(), restore.End.UTC(), cfg.S3})
+}
+
+// ArchiveRestore renders the jobs that restore the flow logs archived to S3 into temporary indices.
+func ArchiveRestore(cfg *ArchiveRestoreConfiguration) Component {
+	return &archiveRestoreComponent{cfg: cfg}
+}
+
+// ArchiveRestoreConfiguration contains all the config information needed to render the component.
+type ArchiveRestoreConfiguration struct {
+	LogStorage    *operatorv1.LogStorage
+	Installation  *operatorv1.InstallationSpec
+	PullSecrets   []*corev1.Secret
+	TrustedBundle certificatemanagement.TrustedBundle
+	Provider      operatorv1.Provider
+	ClusterDomain string
+
+	// S3 is the S3 store of the LogCollector that the flow logs are archived to, and S3Credential its credentials.
+	S3           *operatorv1.S3StoreSpec
+	S3Credential *S3Credential
+
+	// UserSecret holds the credentials of the Elasticsearch user of the jobs, in the namespace of the operator.
+	UserSecret *corev1.Secret
+
+	// Restores are the restores whose jobs are run. The jobs of the other restores are deleted by the controller once
+	// they expire. If there are none, the objects that the jobs share are deleted.
+	Restores []operatorv1.ArchiveRestore
+}
+
+type archiveRestoreComponent struct {
+	cfg   *ArchiveRestoreConfiguration
+	image string
+}
+
+func (c *archiveRestoreComponent) ResolveImages(is *operatorv1.ImageSet) error {
+	reg := c.cfg.Installation.Registry
+	path := c.cfg.Installation.ImagePath
+	prefix := c.cfg.Installation.ImagePrefix
+	var err error
+	// The jobs use the Python and the boto3 S3 client of the curator image.
+	c.image, err = components.GetReference(components.ComponentEsCurator, reg, path, prefix, is)
+	return err
+}
+
+func (c *archiveRestoreComponent) Objects() ([]client.Object, []client.Object) {
+	objs := []client.Object{c.allowTigeraPolicy(), c.serviceAccount(), c.s3CredentialSecret()}
+	if len(c.cfg.Restores) == 0 {
+		objs = append(objs,
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: ArchiveRestoreUserSecret, Namespace: common.OperatorNamespace()}},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: ArchiveRestoreUserSecret, Namespace: ElasticsearchNamespace}},
+		)
+		return nil, objs
+	}
+	objs = append(objs, c.cfg.UserSecret)
+	objs = append(objs, secret.ToRuntimeObjects(secret.CopyToNamespace(ElasticsearchNamespace, c.cfg.UserSecret)...)...)
+	for _, restore := range c.cfg.Restores {
+		objs = append(objs, c.job(restore))
+	}
+	return objs, nil
+}
+
+func (c *archiveRestoreComponent) Ready() bool {
+	return true
+}
+
+func (c *archiveRestoreComponent) SupportedOSType() rmeta.OSType {
+	return rmeta.OSTypeLinux
+}
+
+// SupportedArchitectures returns the architectures of the curator image that the jobs run.
+func (c *archiveRestoreComponent) SupportedArchitectures() []string {
+	return components.Architectures(components.ComponentEsCurator)
+}
+
+func (c *archiveRestoreComponent) serviceAccount() *corev1.ServiceAccount {
+	return &corev1.ServiceAccount{
+		TypeMeta:   metav1.TypeMeta{Kind: "ServiceAccount", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: ArchiveRestoreName, Namespace: ElasticsearchNamespace},
+	}
+}
+
+func (c *archiveRestoreComponent) s3CredentialSecret() *corev1.Secret {
+	data := map[string][]byte{}
+	if c.cfg.S3Credential != nil {
+		data[S3KeyIdName] = c.cfg.S3Credential.KeyId
+		data[S3KeySecretName] = c.cfg.S3Credential.KeySecret
+	}
+	return &corev1.Secret{
+		TypeMeta:   metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: S3FluentdSecretName, Namespace: ElasticsearchNamespace},
+		Data:       data,
+	}
+}
+
+func (c *archiveRestoreComponent) job(restore operatorv1.ArchiveRestore) *batchv1.Job {
+	env := []corev1.EnvVar{
+		{Name: "ELASTIC_USERNAME", ValueFrom: secret.GetEnvVarSource(ArchiveRestoreUserSecret, "username", false)},
+		{Name: "ELASTIC_PASSWORD", ValueFrom: secret.GetEnvVarSource(ArchiveRestoreUserSecret, "password", false)},
+		{Name: "ELASTICSEARCH_URL", Value: relasticsearch.HTTPSEndpoint(c.SupportedOSType(), c.cfg.ClusterDomain)},
+		{Name: "INDEX", Value: ArchiveRestoreIndex(restore)},
+		{Name: "START", Value: restore.Start.UTC().Format(time.RFC3339)},
+		{Name: "END", Value: restore.End.UTC().Format(time.RFC3339)},
+		{Name: "AWS_KEY_ID", ValueFrom: secret.GetEnvVarSource(S3FluentdSecretName, S3KeyIdName, false)},
+		{Name: "AWS_SECRET_KEY", ValueFrom: secret.GetEnvVarSource(S3FluentdSecretName, S3KeySecretName, false)},
+	}
+	if c.cfg.S3 != nil {
+		env = append(env,
+			corev1.EnvVar{Name: "AWS_REGION", Value: c.cfg.S3.Region},
+			corev1.EnvVar{Name: "S3_BUCKET_NAME", Value: c.cfg.S3.BucketName},
+			corev1.EnvVar{Name: "S3_BUCKET_PATH", Value: c.cfg.S3.BucketPath},
+		)
+	}
+	var volumes []corev1.Volume
+	var volumeMounts []corev1.VolumeMount
+	if c.cfg.TrustedBundle != nil {
+		env = append(env, corev1.EnvVar{Name: "CA_CRT_PATH", Value: c.cfg.TrustedBundle.MountPath()})
+		volumes = append(volumes, c.cfg.TrustedBundle.Volume())
+		volumeMounts = append(volumeMounts, c.cfg.TrustedBundle.VolumeMount(c.SupportedOSType()))
+	}
+
+	job := &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{Kind: "Job", APIVersion: "batch/v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ArchiveRestoreJobName(restore),
+			Namespace: ElasticsearchNamespace,
+			Labels:    map[string]string{"k8s-app": ArchiveRestoreName},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: ptr.Int32ToPtr(3),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"k8s-app": ArchiveRestoreName,
+					},
+					Annotations: map[string]string{
+						ArchiveRestoreHashAnnotation: ArchiveRestoreHash(c.cfg, restore),
+					},
+				},
+				Spec: corev1.PodSpec{
+					NodeSelector:       c.cfg.Installation.ControlPlaneNodeSelector,
+					Tolerations:        c.cfg.Installation.ControlPlaneTolerations,
+					ServiceAccountName: ArchiveRestoreName,
+					ImagePullSecrets:   secret.GetReferenceList(c.cfg.PullSecrets),
+					RestartPolicy:      corev1.RestartPolicyNever,
+					Containers: []corev1.Container{{
+						Name:                     ArchiveRestoreName,
+						Image:                    c.image,
+						Command:                  []string{"python3", "-c", archiveRestoreScript},
+						Env:                      env,
+						VolumeMounts:             volumeMounts,
+						TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+						SecurityContext: &corev1.SecurityContext{
+							AllowPrivilegeEscalation: ptr.BoolToPtr(false),
+						},
+					}},
+					Volumes: volumes,
+				},
+			},
+		},
+	}
+	disruptionpolicy.Apply(&job.Spec.Template.Spec, logStorageJobsDisruptionPolicy(c.cfg.LogStorage), true)
+	return job
+}
+
+// allowTigeraPolicy allows the jobs to reach the Elasticsearch gateway and S3.
+func (c *archiveRestoreComponent) allowTigeraPolicy() *v3.NetworkPolicy {
+	egressRules := networkpolicy.AppendDNSEgressRules([]v3.Rule{}, c.cfg.Provider == operatorv1.ProviderOpenShift)
+	egressRules = append(egressRules,
+		v3.Rule{
+			Action:      v3.Allow,
+			Protocol:    &networkpolicy.TCPProtocol,
+			Destination: networkpolicy.ESGatewayEntityRule,
+		},
+	)
+	if c.cfg.S3 != nil {
+		egressRules = append(egressRules, v3.Rule{
+			Action:   v3.Allow,
+			Protocol: &networkpolicy.TCPProtocol,
+			Destination: v3.EntityRule{
+				Domains: ArchiveRestoreS3Domains(c.cfg.S3),
+				Ports:   networkpolicy.Ports(443),
+			},
+		})
+	}
+
+	return &v3.NetworkPolicy{
+		TypeMeta: metav1.TypeMeta{Kind: "NetworkPolicy", APIVersion: "projectcalico.org/v3"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ArchiveRestorePolicyName,
+			Namespace: ElasticsearchNamespace,
+		},
+		Spec: v3.NetworkPolicySpec{
+			Order:    &networkpolicy.HighPrecedenceOrder,
+			Tier:     networkpolicy.TigeraComponentTierName,
+			Selector: networkpolicy.KubernetesAppSelector(ArchiveRestoreName),
+			Types:    []v3.PolicyType{v3.PolicyTypeIngress, v3.PolicyTypeEgress},
+			Egress:   egressRules,
+		},
+	}
+}
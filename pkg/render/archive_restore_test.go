@@ -0,0 +1,137 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/render"
+	"github.com/tigera/operator/pkg/render/common/networkpolicy"
+	rtest "github.com/tigera/operator/pkg/render/common/test"
+)
+
+var _ = Describe("Archive restore rendering tests", func() {
+	var cfg *render.ArchiveRestoreConfiguration
+	start := time.Date(2022, 6, 1, 10, 30, 0, 0, time.UTC)
+	restore := operatorv1.ArchiveRestore{
+		Name:  "incident",
+		Start: metav1.NewTime(start),
+		End:   metav1.NewTime(start.Add(2 * time.Hour)),
+	}
+
+	BeforeEach(func() {
+		cfg = &render.ArchiveRestoreConfiguration{
+			LogStorage:    &operatorv1.LogStorage{},
+			Installation:  &operatorv1.InstallationSpec{},
+			ClusterDomain: "cluster.local",
+			S3: &operatorv1.S3StoreSpec{
+				Region:     "us-west-1",
+				BucketName: "archive",
+				BucketPath: "logs",
+			},
+			S3Credential: &render.S3Credential{KeyId: []byte("id"), KeySecret: []byte("secret")},
+			UserSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: render.ArchiveRestoreUserSecret, Namespace: common.OperatorNamespace()},
+			},
+			Restores: []operatorv1.ArchiveRestore{restore},
+		}
+	})
+
+	It("should render a job for each restore", func() {
+		component := render.ArchiveRestore(cfg)
+		Expect(component.ResolveImages(nil)).NotTo(HaveOccurred())
+
+		toCreate, toDelete := component.Objects()
+		Expect(toDelete).To(BeEmpty())
+		expectedResources := []struct {
+			name    string
+			ns      string
+			group   string
+			version string
+			kind    string
+		}{
+			{render.ArchiveRestorePolicyName, render.ElasticsearchNamespace, "projectcalico.org", "v3", "NetworkPolicy"},
+			{render.ArchiveRestoreName, render.ElasticsearchNamespace, "", "v1", "ServiceAccount"},
+			{render.S3FluentdSecretName, render.ElasticsearchNamespace, "", "v1", "Secret"},
+			{render.ArchiveRestoreUserSecret, common.OperatorNamespace(), "", "v1", "Secret"},
+			{render.ArchiveRestoreUserSecret, render.ElasticsearchNamespace, "", "v1", "Secret"},
+			{"tigera-archive-restore-incident", render.ElasticsearchNamespace, "batch", "v1", "Job"},
+		}
+		Expect(toCreate).To(HaveLen(len(expectedResources)))
+		for i, expectedRes := range expectedResources {
+			rtest.ExpectResource(toCreate[i], expectedRes.name, expectedRes.ns, expectedRes.group, expectedRes.version, expectedRes.kind)
+		}
+
+		secret := rtest.GetResource(toCreate, render.S3FluentdSecretName, render.ElasticsearchNamespace, "", "v1", "Secret").(*corev1.Secret)
+		Expect(secret.Data).To(HaveKeyWithValue(render.S3KeyIdName, []byte("id")))
+
+		job := rtest.GetResource(toCreate, "tigera-archive-restore-incident", render.ElasticsearchNamespace, "batch", "v1", "Job").(*batchv1.Job)
+		Expect(job.Labels).To(HaveKeyWithValue("k8s-app", render.ArchiveRestoreName))
+		Expect(job.Spec.Template.Annotations).To(HaveKeyWithValue(render.ArchiveRestoreHashAnnotation, render.ArchiveRestoreHash(cfg, restore)))
+		Expect(job.Spec.Template.Spec.RestartPolicy).To(Equal(corev1.RestartPolicyNever))
+		Expect(job.Spec.Template.Spec.Containers[0].Env).To(ContainElements(
+			corev1.EnvVar{Name: "ELASTICSEARCH_URL", Value: "https://tigera-secure-es-gateway-http.tigera-elasticsearch.svc:9200"},
+			corev1.EnvVar{Name: "INDEX", Value: "tigera_secure_ee_restored_flows.incident"},
+			corev1.EnvVar{Name: "START", Value: "2022-06-01T10:30:00Z"},
+			corev1.EnvVar{Name: "END", Value: "2022-06-01T12:30:00Z"},
+			corev1.EnvVar{Name: "S3_BUCKET_NAME", Value: "archive"},
+			corev1.EnvVar{Name: "S3_BUCKET_PATH", Value: "logs"},
+			corev1.EnvVar{Name: "ELASTIC_USERNAME", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: render.ArchiveRestoreUserSecret},
+				Key:                  "username",
+			}}},
+		))
+
+		policy := rtest.GetResource(toCreate, render.ArchiveRestorePolicyName, render.ElasticsearchNamespace, "projectcalico.org", "v3", "NetworkPolicy").(*v3.NetworkPolicy)
+		Expect(policy.Spec.Egress).To(ContainElement(v3.Rule{
+			Action:   v3.Allow,
+			Protocol: &networkpolicy.TCPProtocol,
+			Destination: v3.EntityRule{
+				Domains: []string{"archive.s3.us-west-1.amazonaws.com", "s3.us-west-1.amazonaws.com"},
+				Ports:   networkpolicy.Ports(443),
+			},
+		}))
+	})
+
+	It("should change the hash when the period of the restore changes", func() {
+		hash := render.ArchiveRestoreHash(cfg, restore)
+		changed := restore
+		changed.End = metav1.NewTime(start.Add(3 * time.Hour))
+		Expect(render.ArchiveRestoreHash(cfg, changed)).NotTo(Equal(hash))
+	})
+
+	It("should delete the shared objects when there are no restores", func() {
+		cfg.Restores = nil
+		component := render.ArchiveRestore(cfg)
+		Expect(component.ResolveImages(nil)).NotTo(HaveOccurred())
+
+		toCreate, toDelete := component.Objects()
+		Expect(toCreate).To(BeEmpty())
+		Expect(toDelete).To(HaveLen(5))
+		Expect(toDelete).To(ContainElements(
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: render.ArchiveRestoreUserSecret, Namespace: common.OperatorNamespace()}},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: render.ArchiveRestoreUserSecret, Namespace: render.ElasticsearchNamespace}},
+		))
+	})
+})
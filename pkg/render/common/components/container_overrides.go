@@ -0,0 +1,125 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package components
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+)
+
+const (
+	// ContainerOverridesAnnotation is an unsupported escape hatch that appends args and env vars to the containers
+	// rendered for a CR. Its value is a JSON object keyed by "<workload>/<container>", for example:
+	//
+	//   unsupported.operator.tigera.io/container-overrides: |
+	//     {"elastic-operator/manager": {"args": ["--log-verbosity=1"]},
+	//      "elasticsearch/elasticsearch": {"env": [{"name": "ES_JAVA_OPTS", "value": "-Xms2g -Xmx2g"}]}}
+	//
+	// Args are appended to the args of the container. An env var replaces the env var of the container with the same
+	// name, if any, and is appended otherwise.
+	ContainerOverridesAnnotation = "unsupported.operator.tigera.io/container-overrides"
+
+	// ContainerOverridesHashAnnotation is set on the pod template of a workload that has container overrides, so that
+	// the overrides in use can be told from the pods.
+	ContainerOverridesHashAnnotation = "hash.operator.tigera.io/container-overrides"
+)
+
+// ContainerOverride holds the args and env vars to add to a rendered container.
+type ContainerOverride struct {
+	Args []string        `json:"args,omitempty"`
+	Env  []corev1.EnvVar `json:"env,omitempty"`
+}
+
+// ContainerOverrides maps "<workload>/<container>" to the override of the container.
+type ContainerOverrides map[string]ContainerOverride
+
+// ParseContainerOverrides reads the container overrides from the annotations of a CR. Only the given
+// "<workload>/<container>" keys may be overridden. Env vars must have a name and a literal value.
+func ParseContainerOverrides(annotations map[string]string, allowed ...string) (ContainerOverrides, error) {
+	value, ok := annotations[ContainerOverridesAnnotation]
+	if !ok {
+		return nil, nil
+	}
+
+	overrides := ContainerOverrides{}
+	decoder := json.NewDecoder(strings.NewReader(value))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&overrides); err != nil {
+		return nil, fmt.Errorf("annotation %s is not valid: %w", ContainerOverridesAnnotation, err)
+	}
+
+	allowedKeys := map[string]bool{}
+	for _, key := range allowed {
+		allowedKeys[key] = true
+	}
+	for key, override := range overrides {
+		if !allowedKeys[key] {
+			sort.Strings(allowed)
+			return nil, fmt.Errorf("annotation %s overrides %q, only %s can be overridden", ContainerOverridesAnnotation, key, strings.Join(allowed, ", "))
+		}
+		for _, arg := range override.Args {
+			if arg == "" {
+				return nil, fmt.Errorf("annotation %s has an empty arg for %q", ContainerOverridesAnnotation, key)
+			}
+		}
+		for _, env := range override.Env {
+			if env.Name == "" || env.ValueFrom != nil {
+				return nil, fmt.Errorf("annotation %s has an env var without a name or with a valueFrom for %q", ContainerOverridesAnnotation, key)
+			}
+		}
+	}
+	return overrides, nil
+}
+
+// Apply adds the overrides of the containers of the given workload to its pod template.
+func (o ContainerOverrides) Apply(workload string, template *corev1.PodTemplateSpec) {
+	applied := ContainerOverrides{}
+	for i := range template.Spec.Containers {
+		container := &template.Spec.Containers[i]
+		key := fmt.Sprintf("%s/%s", workload, container.Name)
+		override, ok := o[key]
+		if !ok {
+			continue
+		}
+		applied[key] = override
+
+		container.Args = append(container.Args, override.Args...)
+		for _, env := range override.Env {
+			replaced := false
+			for j := range container.Env {
+				if container.Env[j].Name == env.Name {
+					container.Env[j] = env
+					replaced = true
+				}
+			}
+			if !replaced {
+				container.Env = append(container.Env, env)
+			}
+		}
+	}
+
+	if len(applied) > 0 {
+		if template.Annotations == nil {
+			template.Annotations = map[string]string{}
+		}
+		template.Annotations[ContainerOverridesHashAnnotation] = rmeta.AnnotationHash(applied)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package components
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("Container overrides", func() {
+	It("should append the args and replace or append the env vars of the container", func() {
+		overrides, err := ParseContainerOverrides(map[string]string{
+			ContainerOverridesAnnotation: `{"elasticsearch/elasticsearch": {
+  "args": ["--extra"],
+  "env": [{"name": "ES_JAVA_OPTS", "value": "-Xms2g -Xmx2g"}, {"name": "EXTRA", "value": "true"}]
+}}`,
+		}, "elasticsearch/elasticsearch", "kibana/kibana")
+		Expect(err).NotTo(HaveOccurred())
+
+		template := corev1.PodTemplateSpec{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "elasticsearch", Args: []string{"start"}, Env: []corev1.EnvVar{{Name: "ES_JAVA_OPTS", Value: "-Xms1g -Xmx1g"}}},
+					{Name: "sidecar"},
+				},
+			},
+		}
+		overrides.Apply("elasticsearch", &template)
+
+		Expect(template.Spec.Containers[0].Args).To(Equal([]string{"start", "--extra"}))
+		Expect(template.Spec.Containers[0].Env).To(Equal([]corev1.EnvVar{
+			{Name: "ES_JAVA_OPTS", Value: "-Xms2g -Xmx2g"},
+			{Name: "EXTRA", Value: "true"},
+		}))
+		Expect(template.Spec.Containers[1]).To(Equal(corev1.Container{Name: "sidecar"}))
+		Expect(template.Annotations).To(HaveKey(ContainerOverridesHashAnnotation))
+	})
+
+	It("should leave the pod template alone without overrides", func() {
+		overrides, err := ParseContainerOverrides(nil, "kibana/kibana")
+		Expect(err).NotTo(HaveOccurred())
+
+		template := corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "kibana"}}}}
+		overrides.Apply("kibana", &template)
+		Expect(template.Annotations).To(BeNil())
+		Expect(template.Spec.Containers[0]).To(Equal(corev1.Container{Name: "kibana"}))
+	})
+
+	DescribeTable("should reject invalid overrides", func(value string) {
+		_, err := ParseContainerOverrides(map[string]string{ContainerOverridesAnnotation: value}, "kibana/kibana")
+		Expect(err).To(HaveOccurred())
+	},
+		Entry("invalid JSON", `{"kibana/kibana": `),
+		Entry("unknown field", `{"kibana/kibana": {"command": ["sh"]}}`),
+		Entry("container that can't be overridden", `{"elasticsearch/elasticsearch": {"args": ["--extra"]}}`),
+		Entry("empty arg", `{"kibana/kibana": {"args": [""]}}`),
+		Entry("env var without a name", `{"kibana/kibana": {"env": [{"value": "true"}]}}`),
+		Entry("env var from a secret", `{"kibana/kibana": {"env": [{"name": "PASSWORD", "valueFrom": {"secretKeyRef": {"name": "s", "key": "k"}}}]}}`),
+	)
+})
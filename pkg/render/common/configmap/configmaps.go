@@ -4,15 +4,20 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	rmeta "github.com/tigera/operator/pkg/render/common/meta"
 )
 
 // CopyToNamespace returns a new list of config maps generated from the ones given but with the namespace changed to the
-// given one.
+// given one. The copies are annotated with their source, so that the operator can detect copies that were modified.
 func CopyToNamespace(ns string, oConfigMaps ...*v1.ConfigMap) []*v1.ConfigMap {
 	var configMaps []*v1.ConfigMap
 	for _, s := range oConfigMaps {
 		x := s.DeepCopy()
 		x.ObjectMeta = metav1.ObjectMeta{Name: s.Name, Namespace: ns}
+		if s.Namespace != "" && s.Namespace != ns {
+			x.Annotations = map[string]string{rmeta.CopySourceAnnotation: s.Namespace + "/" + s.Name}
+		}
 
 		configMaps = append(configMaps, x)
 	}
@@ -0,0 +1,60 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package disruptionpolicy
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+)
+
+// Apply sets the termination grace period of the DisruptionPolicy on the pod spec, and makes the containers that don't
+// have a preStop hook of their own wait for the preStop delay of the policy before they are sent SIGTERM. The delay
+// sleeps in a shell, so it is only applied if the images of the containers ship one, which shell tells. The policies
+// of the components whose images don't are validated to have no delay. The policy may be nil, in which case the pod
+// spec is left unchanged.
+func Apply(spec *corev1.PodSpec, policy *operatorv1.DisruptionPolicy, shell bool) {
+	if policy == nil {
+		return
+	}
+	if policy.TerminationGracePeriodSeconds != nil {
+		gracePeriod := *policy.TerminationGracePeriodSeconds
+		spec.TerminationGracePeriodSeconds = &gracePeriod
+	}
+	if shell && policy.PreStopDelaySeconds != nil && *policy.PreStopDelaySeconds > 0 {
+		for i := range spec.Containers {
+			container := &spec.Containers[i]
+			if container.Lifecycle != nil && container.Lifecycle.PreStop != nil {
+				continue
+			}
+			if container.Lifecycle == nil {
+				container.Lifecycle = &corev1.Lifecycle{}
+			}
+			container.Lifecycle.PreStop = &corev1.LifecycleHandler{
+				Exec: &corev1.ExecAction{Command: []string{"/bin/sh", "-c", fmt.Sprintf("sleep %d", *policy.PreStopDelaySeconds)}},
+			}
+		}
+	}
+}
+
+// PodDisruptionBudget returns the PodDisruptionBudget of the DisruptionPolicy, or nil if the policy is nil or has none.
+func PodDisruptionBudget(policy *operatorv1.DisruptionPolicy) *operatorv1.PodDisruptionBudget {
+	if policy == nil {
+		return nil
+	}
+	return policy.PodDisruptionBudget
+}
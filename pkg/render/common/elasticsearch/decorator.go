@@ -79,15 +79,7 @@ func ContainerDecorateENVVars(
 	c corev1.Container,
 	cluster, esUserSecretName, clusterDomain string,
 	osType rmeta.OSType) corev1.Container {
-	certPath := elasticCertPath(osType)
-	esScheme, esHost, esPort, _ := url.ParseEndpoint(HTTPSEndpoint(osType, clusterDomain))
-	envVars := []corev1.EnvVar{
-		{Name: "ELASTIC_INDEX_SUFFIX", Value: cluster},
-		{Name: "ELASTIC_SCHEME", Value: esScheme},
-		{Name: "ELASTIC_HOST", Value: esHost},
-		{Name: "ELASTIC_PORT", Value: esPort},
-		{Name: "ELASTIC_ACCESS_MODE", Value: "serviceuser"},
-		{Name: "ELASTIC_SSL_VERIFY", Value: "true"},
+	c.Env = append(c.Env, envVars(cluster, clusterDomain, osType, "serviceuser", []corev1.EnvVar{
 		{
 			Name:      "ELASTIC_USER",
 			ValueFrom: secret.GetEnvVarSource(esUserSecretName, "username", false),
@@ -100,15 +92,41 @@ func ContainerDecorateENVVars(
 			Name:      "ELASTIC_PASSWORD",
 			ValueFrom: secret.GetEnvVarSource(esUserSecretName, "password", false),
 		},
-		{Name: "ELASTIC_CA", Value: certPath},
-		{Name: "ES_CA_CERT", Value: certPath},
-		{Name: "ES_CURATOR_BACKEND_CERT", Value: certPath},
-	}
+	})...)
+	return c
+}
 
-	c.Env = append(c.Env, envVars...)
+// ContainerDecorateTokenENVVars is like ContainerDecorateENVVars, but the container authenticates to es-gateway with
+// the service account token at tokenPath instead of the credentials of an Elasticsearch user.
+func ContainerDecorateTokenENVVars(
+	c corev1.Container,
+	cluster, tokenPath, clusterDomain string,
+	osType rmeta.OSType) corev1.Container {
+	c.Env = append(c.Env, envVars(cluster, clusterDomain, osType, "serviceaccounttoken", []corev1.EnvVar{
+		{Name: "ELASTIC_TOKEN_PATH", Value: tokenPath},
+	})...)
 	return c
 }
 
+func envVars(cluster, clusterDomain string, osType rmeta.OSType, accessMode string, credentials []corev1.EnvVar) []corev1.EnvVar {
+	certPath := elasticCertPath(osType)
+	esScheme, esHost, esPort, _ := url.ParseEndpoint(HTTPSEndpoint(osType, clusterDomain))
+	env := []corev1.EnvVar{
+		{Name: "ELASTIC_INDEX_SUFFIX", Value: cluster},
+		{Name: "ELASTIC_SCHEME", Value: esScheme},
+		{Name: "ELASTIC_HOST", Value: esHost},
+		{Name: "ELASTIC_PORT", Value: esPort},
+		{Name: "ELASTIC_ACCESS_MODE", Value: accessMode},
+		{Name: "ELASTIC_SSL_VERIFY", Value: "true"},
+	}
+	env = append(env, credentials...)
+	return append(env,
+		corev1.EnvVar{Name: "ELASTIC_CA", Value: certPath},
+		corev1.EnvVar{Name: "ES_CA_CERT", Value: certPath},
+		corev1.EnvVar{Name: "ES_CURATOR_BACKEND_CERT", Value: certPath},
+	)
+}
+
 func DefaultVolumeMount(osType rmeta.OSType) corev1.VolumeMount {
 	certPath := elasticCertDir(osType)
 	return corev1.VolumeMount{
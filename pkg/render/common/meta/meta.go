@@ -39,6 +39,13 @@ const (
 	// NOTE: Do not change this field since we use this value to identify
 	// certificates managed by this operator.
 	TigeraOperatorCAIssuerPrefix = "tigera-operator-signer"
+
+	// CopySourceAnnotation holds the namespace and name of the secret or ConfigMap that an object is a copy of.
+	CopySourceAnnotation = "operator.tigera.io/copy-source"
+
+	// CopyHashAnnotation holds the hash of the data of a copy as the operator last wrote it, so that a copy that was
+	// modified since can be told apart from one whose source changed.
+	CopyHashAnnotation = "hash.operator.tigera.io/copy"
 )
 
 var (
@@ -0,0 +1,84 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package scheduling restricts the nodes that the pods of the components are scheduled on to the nodes that run an
+// operating system and a CPU architecture that the images of the components support.
+package scheduling
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+)
+
+const (
+	OSLabel   = "kubernetes.io/os"
+	ArchLabel = "kubernetes.io/arch"
+)
+
+// RequireOS sets a node selector on the pod spec for the operating system of the nodes, unless the pods can run on
+// any operating system.
+func RequireOS(podSpec *corev1.PodSpec, osType rmeta.OSType) {
+	if osType == rmeta.OSTypeAny {
+		return
+	}
+	if podSpec.NodeSelector == nil {
+		podSpec.NodeSelector = make(map[string]string)
+	}
+	podSpec.NodeSelector[OSLabel] = string(osType)
+}
+
+// RequireArchitectures adds a required node affinity for the CPU architectures of the nodes to the pod spec, unless
+// archs is empty. Since the terms of the node affinity are ORed, the requirement is added to each of them, except to
+// the terms that already have a requirement for the architectures, such as one set by an override of the affinity.
+func RequireArchitectures(podSpec *corev1.PodSpec, archs []string) {
+	if len(archs) == 0 {
+		return
+	}
+	if podSpec.Affinity == nil {
+		podSpec.Affinity = &corev1.Affinity{}
+	}
+	if podSpec.Affinity.NodeAffinity == nil {
+		podSpec.Affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	nodeAffinity := podSpec.Affinity.NodeAffinity
+	if nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = &corev1.NodeSelector{}
+	}
+	required := nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if len(required.NodeSelectorTerms) == 0 {
+		required.NodeSelectorTerms = []corev1.NodeSelectorTerm{{}}
+	}
+
+	for i := range required.NodeSelectorTerms {
+		term := &required.NodeSelectorTerms[i]
+		if hasRequirement(term.MatchExpressions, ArchLabel) {
+			continue
+		}
+		term.MatchExpressions = append(term.MatchExpressions, corev1.NodeSelectorRequirement{
+			Key:      ArchLabel,
+			Operator: corev1.NodeSelectorOpIn,
+			Values:   append([]string{}, archs...),
+		})
+	}
+}
+
+func hasRequirement(requirements []corev1.NodeSelectorRequirement, key string) bool {
+	for _, r := range requirements {
+		if r.Key == key {
+			return true
+		}
+	}
+	return false
+}
@@ -79,12 +79,15 @@ func getSecretFromTLSConfig(
 }
 
 // CopyToNamespace returns a new list of secrets generated from the ones given but with the namespace changed to the
-// given one.
+// given one. The copies are annotated with their source, so that the operator can detect copies that were modified.
 func CopyToNamespace(ns string, oSecrets ...*corev1.Secret) []*corev1.Secret {
 	var secrets []*corev1.Secret
 	for _, s := range oSecrets {
 		x := s.DeepCopy()
 		x.ObjectMeta = metav1.ObjectMeta{Name: s.Name, Namespace: ns}
+		if s.Namespace != "" && s.Namespace != ns {
+			x.Annotations = map[string]string{rmeta.CopySourceAnnotation: s.Namespace + "/" + s.Name}
+		}
 
 		secrets = append(secrets, x)
 	}
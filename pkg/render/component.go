@@ -39,3 +39,15 @@ type Component interface {
 	// that create pods. Return OSTypeAny means that no node selector should be set for the "kubernetes.io/os" label.
 	SupportedOSType() rmeta.OSType
 }
+
+// ArchitectureRestrictedComponent is a Component whose images are not published for all CPU architectures. When a node
+// of the cluster runs an architecture that is not returned, the "componentHandler" converts the returned architectures
+// to a required node affinity for the "kubernetes.io/arch" label on the client.Objects that create pods, so that they
+// are not scheduled on nodes that can't run the images.
+type ArchitectureRestrictedComponent interface {
+	Component
+
+	// SupportedArchitectures returns the CPU architectures that the images of the Component are published for. Returning
+	// an empty list means that no node affinity should be set for the "kubernetes.io/arch" label.
+	SupportedArchitectures() []string
+}
@@ -48,6 +48,21 @@ func CreateElasticsearchKeystoreSecret() *corev1.Secret {
 	}
 }
 
+// CreateFluentdRedactionKeySecret creates a secret with the key of the HMAC that the redaction rules of the LogCollector
+// hash fields with.
+func CreateFluentdRedactionKeySecret() *corev1.Secret {
+	return &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      FluentdRedactionKeySecret,
+			Namespace: common.OperatorNamespace(),
+		},
+		Data: map[string][]byte{
+			FluentdRedactionKeyName: []byte(calicrypto.GeneratePassword(32)),
+		},
+	}
+}
+
 // CreateCertificateSecret is a convenience method for creating a secret that contains only a ca or cert to trust.
 func CreateCertificateSecret(caPem []byte, secretName string, namespace string) *corev1.Secret {
 	return &corev1.Secret{
@@ -16,26 +16,35 @@ package render
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
+	"strings"
 
 	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
 	"github.com/tigera/operator/pkg/render/common/networkpolicy"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	operatorv1 "github.com/tigera/operator/api/v1"
+	vpav1 "github.com/tigera/operator/pkg/apis/autoscaling.k8s.io/v1"
 	"github.com/tigera/operator/pkg/components"
+	"github.com/tigera/operator/pkg/render/common/disruptionpolicy"
 	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/poddisruptionbudget"
 	"github.com/tigera/operator/pkg/render/common/podsecuritypolicy"
 	"github.com/tigera/operator/pkg/render/common/resourcequota"
 	"github.com/tigera/operator/pkg/render/common/secret"
+	"github.com/tigera/operator/pkg/render/common/securitycontext"
 	"github.com/tigera/operator/pkg/tls/certificatemanagement"
 	"github.com/tigera/operator/pkg/url"
 )
@@ -48,11 +57,16 @@ const (
 	S3FluentdSecretName                      = "log-collector-s3-credentials"
 	S3KeyIdName                              = "key-id"
 	S3KeySecretName                          = "key-secret"
+	FluentdRedactionKeySecret                = "tigera-fluentd-redaction-key"
+	FluentdRedactionKeyName                  = "key"
+	fluentdRedactionKeyEnv                   = "FLUENTD_REDACTION_KEY"
 	FluentdPrometheusTLSSecretName           = "tigera-fluentd-prometheus-tls"
 	FluentdMetricsService                    = "fluentd-metrics"
+	FluentdWindowsMetricsService             = "fluentd-metrics-windows"
 	FluentdMetricsPortName                   = "fluentd-metrics-port"
 	FluentdMetricsPort                       = 9081
 	FluentdPolicyName                        = networkpolicy.TigeraComponentPolicyPrefix + "allow-fluentd-node"
+	hashAnnotationPrefix                     = "hash.operator.tigera.io/"
 	filterHashAnnotation                     = "hash.operator.tigera.io/fluentd-filters"
 	s3CredentialHashAnnotation               = "hash.operator.tigera.io/s3-credentials"
 	splunkCredentialHashAnnotation           = "hash.operator.tigera.io/splunk-credentials"
@@ -70,6 +84,8 @@ const (
 	SplunkFluentdSecretsVolName              = "splunk-certificates"
 	SplunkFluentdDefaultCertDir              = "/etc/ssl/splunk/"
 	SplunkFluentdDefaultCertPath             = SplunkFluentdDefaultCertDir + SplunkFluentdSecretCertificateKey
+	SplunkFluentdCASecretName                = "logcollector-splunk-ca"
+	AdditionalStoreCAKey                     = "ca.crt"
 
 	probeTimeoutSeconds        int32 = 5
 	probePeriodSeconds         int32 = 5
@@ -84,17 +100,105 @@ const (
 	fluentdWindowsName = "tigera-fluentd-windows"
 
 	FluentdNodeName        = "fluentd-node"
-	fluentdNodeWindowsName = "fluentd-node-windows"
+	FluentdNodeWindowsName = "fluentd-node-windows"
 
 	eksLogForwarderName = "eks-log-forwarder"
 
+	// ESGatewayTokenAudience is the audience of the service account tokens that fluentd authenticates to es-gateway
+	// with. es-gateway rejects the tokens of any other audience.
+	ESGatewayTokenAudience          = "tigera-es-gateway"
+	esGatewayTokenVolumeName        = "es-gateway-token"
+	esGatewayTokenExpirationSeconds = 3600
+
+	LogBufferName                 = "tigera-log-buffer"
+	LogBufferPortName             = "forward"
+	LogBufferPort                 = 24224
+	logBufferVolumeName           = "log-buffer"
+	logBufferConfigVolumeName     = "log-buffer-config"
+	logBufferConfigDir            = "/etc/fluentd/log-buffer"
+	logBufferPath                 = "/fluentd/buffer"
+	logBufferConfigHashAnnotation = "hash.operator.tigera.io/log-buffer-config"
+	logBufferDefaultStorage       = "10Gi"
+	logBufferDefaultRetentionSecs = 6 * 60 * 60
+
 	PacketCaptureAPIRole        = "packetcapture-api-role"
 	PacketCaptureAPIRoleBinding = "packetcapture-api-role-binding"
 )
 
+const (
+	defaultFluentdTerminationGracePeriod int64 = 30
+	// The longest that the preStop hook of fluentd waits for its buffers to be flushed.
+	fluentdMaxFlushWait int64 = 10
+)
+
+// FluentdTemplateHashAnnotation and FluentdResourcesHashAnnotation hold the hashes of the pod template of the fluentd
+// DaemonSet without the resources of its containers, and of only these resources. They tell a change of only the
+// resources apart from other changes.
+const (
+	FluentdTemplateHashAnnotation  = "hash.operator.tigera.io/fluentd-template"
+	FluentdResourcesHashAnnotation = "hash.operator.tigera.io/fluentd-resources"
+)
+
+// FluentdNodePoolLabel labels the fluentd DaemonSets of the node pools of the LogCollector, and their pods, with the
+// name of their pool.
+const FluentdNodePoolLabel = "operator.tigera.io/fluentd-node-pool"
+
+// FluentdLabel labels the pods of all the fluentd DaemonSets of an OS type with the name of the default DaemonSet of the
+// OS type, so that the metrics service of the OS type selects the pods of its node pools and control plane nodes too.
+const FluentdLabel = "operator.tigera.io/fluentd"
+
+// FluentdControlPlaneName is the name of the fluentd DaemonSet of the control plane nodes, when they run fluentd with
+// resources of their own.
+const FluentdControlPlaneName = "fluentd-node-control-plane"
+
+const (
+	// FluentdCandidateName is the name of the DaemonSet of the candidate fluentd of the LogCollector.
+	FluentdCandidateName = "fluentd-node-candidate"
+	// FluentdCandidateLabel labels the DaemonSet of the candidate fluentd, and its pods.
+	FluentdCandidateLabel = "operator.tigera.io/fluentd-candidate"
+	// defaultCandidateOutputSuffix is the suffix of the outputs of the candidate fluentd when the LogCollector doesn't
+	// set one.
+	defaultCandidateOutputSuffix = "candidate"
+	// candidatePositionsPath is the directory on the nodes that the candidate fluentd keeps its position files in, in
+	// place of /var/log/calico, so that the candidate and fluentd don't overwrite the read offsets of each other.
+	candidatePositionsPath = "/var/log/calico/fluentd-candidate"
+)
+
+// candidateLogDirs are the directories of /var/log/calico with the logs that fluentd tails, which are mounted into the
+// position directory of the candidate fluentd.
+var candidateLogDirs = []string{"flowlogs", "dnslogs", "l7logs", "audit", "bird", "bird6", "snort-alerts"}
+
+const (
+	// containerLogsPath is the directory of the log files of the containers that the kubelet links to the log files of
+	// the container runtime, whatever the runtime is.
+	containerLogsPath = "/var/log/containers"
+	// podLogsPath is the directory that the kubelet links to the log files of the container runtime, and that containerd
+	// and CRI-O write the log files to.
+	podLogsPath = "/var/log/pods"
+	// dockerContainerLogsPath is the directory that Docker writes the log files of the containers to by default.
+	dockerContainerLogsPath = "/var/lib/docker/containers"
+)
+
+// containerLogBasePath returns the directory that the container runtime writes the log files of the containers to.
+func containerLogBasePath(containerLogs *operatorv1.ContainerLogs) string {
+	if containerLogs.BasePath != "" {
+		return containerLogs.BasePath
+	}
+	if containerLogs.Runtime != nil && *containerLogs.Runtime == operatorv1.ContainerRuntimeDocker {
+		return dockerContainerLogsPath
+	}
+	return podLogsPath
+}
+
+// controlPlaneNodeLabels label the control plane nodes, the second one on older clusters.
+var controlPlaneNodeLabels = []string{"node-role.kubernetes.io/control-plane", "node-role.kubernetes.io/master"}
+
+// fluentdSelector selects the fluentd pods of all the DaemonSets, including the candidate fluentd.
+var fluentdSelector = fmt.Sprintf("%s || has(%s) || has(%s)", networkpolicy.KubernetesAppSelector(FluentdNodeName, FluentdNodeWindowsName, FluentdControlPlaneName), FluentdNodePoolLabel, FluentdCandidateLabel)
+
 var FluentdSourceEntityRule = v3.EntityRule{
 	NamespaceSelector: fmt.Sprintf("name == '%s'", LogCollectorNamespace),
-	Selector:          networkpolicy.KubernetesAppSelector(FluentdNodeName, fluentdNodeWindowsName),
+	Selector:          fluentdSelector,
 }
 
 var EKSLogForwarderEntityRule = networkpolicy.CreateSourceEntityRule(LogCollectorNamespace, eksLogForwarderName)
@@ -104,6 +208,68 @@ type FluentdFilters struct {
 	DNS  string
 }
 
+// WithRedactionRules returns the filters with the fluentd filters that apply the redaction rules appended to those of
+// the logs of their type, so that the logs are redacted before they are sent to any of the log stores.
+func WithRedactionRules(filters *FluentdFilters, rules []operatorv1.LogRedactionRule) *FluentdFilters {
+	if len(rules) == 0 {
+		return filters
+	}
+	redacted := &FluentdFilters{}
+	if filters != nil {
+		*redacted = *filters
+	}
+	redacted.Flow += redactionFilter("flows", operatorv1.LogRedactionLogTypeFlows, rules)
+	redacted.DNS += redactionFilter("dns", operatorv1.LogRedactionLogTypeDNS, rules)
+	return redacted
+}
+
+// RedactionKeyRequired returns whether any of the redaction rules hashes a field, which is hashed with an HMAC keyed with
+// the key of the cluster in the FluentdRedactionKeySecret, so that low-entropy values can't be recovered by hashing
+// all the candidates.
+func RedactionKeyRequired(rules []operatorv1.LogRedactionRule) bool {
+	for _, rule := range rules {
+		if rule.Action == operatorv1.LogRedactionHash {
+			return true
+		}
+	}
+	return false
+}
+
+// redactionFilter returns the fluentd filter of the logs of the tag that applies the redaction rules of the log type,
+// or an empty string if there are none.
+func redactionFilter(tag string, logType operatorv1.LogRedactionLogType, rules []operatorv1.LogRedactionRule) string {
+	var hashed, dropped []string
+	for _, rule := range rules {
+		if rule.LogType != logType {
+			continue
+		}
+		switch rule.Action {
+		case operatorv1.LogRedactionHash:
+			hashed = append(hashed, rule.Field)
+		case operatorv1.LogRedactionDrop:
+			dropped = append(dropped, rule.Field)
+		}
+	}
+	if len(hashed) == 0 && len(dropped) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n<filter %s>\n  @type record_transformer\n", tag)
+	if len(hashed) > 0 {
+		b.WriteString("  enable_ruby true\n  <record>\n")
+		for _, field := range hashed {
+			fmt.Fprintf(&b, "    %[1]s ${(require 'openssl'; record['%[1]s'].nil? ? nil : OpenSSL::HMAC.hexdigest('SHA256', ENV['%[2]s'], record['%[1]s'].to_s))}\n", field, fluentdRedactionKeyEnv)
+		}
+		b.WriteString("  </record>\n")
+	}
+	if len(dropped) > 0 {
+		fmt.Fprintf(&b, "  remove_keys %s\n", strings.Join(dropped, ","))
+	}
+	b.WriteString("</filter>\n")
+	return b.String()
+}
+
 type S3Credential struct {
 	KeyId     []byte
 	KeySecret []byte
@@ -155,8 +321,47 @@ type FluentdConfiguration struct {
 	TrustedBundle    certificatemanagement.TrustedBundle
 	ManagedCluster   bool
 
+	// LogBuffer configures the store that buffers logs on a managed cluster while they cannot be sent to the
+	// management cluster. It is only used for managed clusters.
+	LogBuffer *operatorv1.LogBuffer
+
+	// RemoveLogBuffer is set when the log buffer is not configured but still exists, so that it is only deleted once.
+	RemoveLogBuffer bool
+
+	// ContainerLogs is where the log files of the containers are found on the nodes, with the container runtime
+	// detected when the LogCollector doesn't set it. When it is nil, the log files of the containers are not mounted.
+	ContainerLogs *operatorv1.ContainerLogs
+
 	// Whether or not the cluster supports pod security policies.
 	UsePSP bool
+
+	// SplunkCA is the CA bundle that verifies the certificate of the splunk store, when the store has its own instead of
+	// using the trusted bundle.
+	SplunkCA []byte
+
+	// RedactionKeySecret is the secret in the operator namespace with the key of the HMAC that the redaction rules hash
+	// fields with. It is set when any of the redaction rules hashes a field.
+	RedactionKeySecret *corev1.Secret
+
+	// InPlaceResize is whether the resources of the running fluentd pods can be resized in place by the controller.
+	// When it is set and only the resources of the pod template change from the CurrentDaemonSet, the DaemonSet uses
+	// the OnDelete update strategy so that it doesn't restart the pods for the change.
+	InPlaceResize bool
+
+	// CurrentDaemonSet is the fluentd DaemonSet in the cluster, if any.
+	CurrentDaemonSet *appsv1.DaemonSet
+
+	// DeferRollouts keeps the hash annotations of the pod template of the CurrentDaemonSet, so that changed
+	// configuration or certificates don't restart fluentd while the rollout window of the LogCollector is closed.
+	DeferRollouts bool
+
+	// CurrentNodePools are the names of the node pools that have fluentd DaemonSets in the cluster. The DaemonSets of
+	// the pools that are no longer in the LogCollector are deleted.
+	CurrentNodePools []string
+
+	// VerticalPodAutoscalerAPI is whether the VerticalPodAutoscaler API is installed in the cluster, in which case the
+	// VerticalPodAutoscalers are deleted when they are not configured.
+	VerticalPodAutoscalerAPI bool
 }
 
 type fluentdComponent struct {
@@ -198,7 +403,7 @@ func (c *fluentdComponent) fluentdName() string {
 
 func (c *fluentdComponent) fluentdNodeName() string {
 	if c.cfg.OSType == rmeta.OSTypeWindows {
-		return fluentdNodeWindowsName
+		return FluentdNodeWindowsName
 	}
 	return FluentdNodeName
 }
@@ -226,6 +431,40 @@ func (c *fluentdComponent) volumeHostPath() string {
 	return "/var/log/calico"
 }
 
+// containerLogPaths returns the host paths that fluentd reads the log files of the containers from: the links in
+// /var/log/containers and the directories that they link to, which are /var/log/pods and, for Docker, the directory
+// that Docker writes the log files to. The log files of the containers are only collected on Linux.
+func (c *fluentdComponent) containerLogPaths() []string {
+	if c.cfg.ContainerLogs == nil || c.cfg.OSType == rmeta.OSTypeWindows {
+		return nil
+	}
+	paths := []string{containerLogsPath, podLogsPath}
+	if basePath := containerLogBasePath(c.cfg.ContainerLogs); basePath != podLogsPath {
+		paths = append(paths, basePath)
+	}
+	return paths
+}
+
+// withContainerRuntime returns a copy of the component that mounts the log files of the containers for the given
+// container runtime, or the component itself if the runtime is nil or the log files aren't mounted.
+func (c *fluentdComponent) withContainerRuntime(runtime *operatorv1.ContainerRuntime) *fluentdComponent {
+	if runtime == nil || c.cfg.ContainerLogs == nil {
+		return c
+	}
+	cfg := *c.cfg
+	cfg.ContainerLogs = c.cfg.ContainerLogs.DeepCopy()
+	cfg.ContainerLogs.Runtime = runtime
+	copied := *c
+	copied.cfg = &cfg
+	return &copied
+}
+
+// containerLogVolumeName returns the name of the volume of a host path that fluentd reads the log files of the
+// containers from.
+func containerLogVolumeName(index int) string {
+	return fmt.Sprintf("container-logs-%d", index)
+}
+
 func (c *fluentdComponent) path(path string) string {
 	if c.cfg.OSType == rmeta.OSTypeWindows {
 		// Use c: path prefix for windows.
@@ -258,6 +497,18 @@ func (c *fluentdComponent) Objects() ([]client.Object, []client.Object) {
 	if c.cfg.Filters != nil {
 		objs = append(objs, c.filtersConfigMap())
 	}
+	if c.cfg.RedactionKeySecret != nil {
+		objs = append(objs, c.cfg.RedactionKeySecret)
+		objs = append(objs, secret.ToRuntimeObjects(secret.CopyToNamespace(LogCollectorNamespace, c.cfg.RedactionKeySecret)...)...)
+	} else {
+		toDelete = append(toDelete, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: FluentdRedactionKeySecret, Namespace: LogCollectorNamespace}})
+	}
+	for _, ca := range c.additionalStoreCAs() {
+		objs = append(objs, c.additionalStoreCASecret(ca))
+	}
+	if len(c.cfg.SplunkCA) == 0 {
+		toDelete = append(toDelete, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: SplunkFluentdCASecretName, Namespace: LogCollectorNamespace}})
+	}
 	if c.cfg.EKSConfig != nil && c.cfg.OSType == rmeta.OSTypeLinux {
 		if c.cfg.Installation.KubernetesProvider != operatorv1.ProviderOpenShift {
 			objs = append(objs,
@@ -269,8 +520,22 @@ func (c *fluentdComponent) Objects() ([]client.Object, []client.Object) {
 		}
 		objs = append(objs, c.eksLogForwarderServiceAccount(),
 			c.eksLogForwarderSecret(),
+			c.eksLogForwarderPodDisruptionBudget(),
 			c.eksLogForwarderDeployment())
 	}
+	if c.cfg.OSType == rmeta.OSTypeLinux {
+		if c.logBufferEnabled() {
+			objs = append(objs, c.logBufferServiceAccount(), c.logBufferService(), c.logBufferConfigMap(), c.logBufferStatefulSet())
+		} else if c.cfg.RemoveLogBuffer {
+			// The volume claims of the stateful set are left behind, so that the buffered logs are not lost.
+			meta := metav1.ObjectMeta{Name: LogBufferName, Namespace: LogCollectorNamespace}
+			toDelete = append(toDelete,
+				&corev1.ServiceAccount{ObjectMeta: meta},
+				&corev1.Service{ObjectMeta: meta},
+				&corev1.ConfigMap{ObjectMeta: meta},
+				&appsv1.StatefulSet{ObjectMeta: meta})
+		}
+	}
 
 	// Windows PSP does not support allowedHostPaths yet.
 	// See: https://github.com/kubernetes/kubernetes/issues/93165#issuecomment-693049808
@@ -288,6 +553,38 @@ func (c *fluentdComponent) Objects() ([]client.Object, []client.Object) {
 	objs = append(objs, c.packetCaptureApiRole(), c.packetCaptureApiRoleBinding())
 	objs = append(objs, c.daemonset())
 
+	poolObjs, poolsToDelete := c.nodePoolDaemonSets()
+	objs = append(objs, poolObjs...)
+	toDelete = append(toDelete, poolsToDelete...)
+
+	// The candidate runs the Linux image of fluentd.
+	if c.cfg.OSType == rmeta.OSTypeLinux {
+		if c.candidateEnabled() {
+			objs = append(objs, c.candidateDaemonSet())
+		} else {
+			toDelete = append(toDelete, &appsv1.DaemonSet{
+				TypeMeta:   metav1.TypeMeta{Kind: "DaemonSet", APIVersion: "apps/v1"},
+				ObjectMeta: metav1.ObjectMeta{Name: FluentdCandidateName, Namespace: LogCollectorNamespace},
+			})
+		}
+	}
+
+	// Only Linux nodes are control plane nodes.
+	if c.cfg.OSType == rmeta.OSTypeLinux {
+		if c.controlPlaneDaemonSetEnabled() {
+			objs = append(objs, c.controlPlaneDaemonSet())
+		} else {
+			toDelete = append(toDelete, &appsv1.DaemonSet{
+				TypeMeta:   metav1.TypeMeta{Kind: "DaemonSet", APIVersion: "apps/v1"},
+				ObjectMeta: metav1.ObjectMeta{Name: FluentdControlPlaneName, Namespace: LogCollectorNamespace},
+			})
+		}
+	}
+
+	vpaObjs, vpaToDelete := c.verticalPodAutoscalers()
+	objs = append(objs, vpaObjs...)
+	toDelete = append(toDelete, vpaToDelete...)
+
 	return objs, toDelete
 }
 
@@ -334,6 +631,82 @@ func (c *fluentdComponent) filtersConfigMap() *corev1.ConfigMap {
 	}
 }
 
+// additionalStoreCA is the CA bundle of an additional log store.
+type additionalStoreCA struct {
+	store      string
+	secretName string
+	bundle     []byte
+}
+
+// additionalStoreCAs returns the CA bundles of the additional log stores that have their own.
+func (c *fluentdComponent) additionalStoreCAs() []additionalStoreCA {
+	var cas []additionalStoreCA
+	if len(c.cfg.SplunkCA) != 0 {
+		cas = append(cas, additionalStoreCA{store: "splunk", secretName: SplunkFluentdCASecretName, bundle: c.cfg.SplunkCA})
+	}
+	return cas
+}
+
+// additionalStoreCADir returns the directory that the CA bundle of the additional log store is mounted in, separately
+// from the trusted bundle.
+func (c *fluentdComponent) additionalStoreCADir(store string) string {
+	return c.path(fmt.Sprintf("/etc/fluentd/tls/%s/", store))
+}
+
+func (c *fluentdComponent) additionalStoreCASecret(ca additionalStoreCA) *corev1.Secret {
+	return &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ca.secretName,
+			Namespace: LogCollectorNamespace,
+		},
+		Data: map[string][]byte{
+			AdditionalStoreCAKey: ca.bundle,
+		},
+	}
+}
+
+// storeLogTypes returns the types of logs that an additional store exports. In audit only mode, there are no flow and DNS
+// logs to export, and the audit logs are exported by default.
+func (c *fluentdComponent) storeLogTypes(logTypes []operatorv1.SyslogLogType) []operatorv1.SyslogLogType {
+	if !c.auditOnly() {
+		return logTypes
+	}
+	var filtered []operatorv1.SyslogLogType
+	for _, t := range logTypes {
+		if t != operatorv1.SyslogLogFlows && t != operatorv1.SyslogLogDNS {
+			filtered = append(filtered, t)
+		}
+	}
+	if len(filtered) == 0 {
+		filtered = []operatorv1.SyslogLogType{operatorv1.SyslogLogAudit}
+	}
+	return filtered
+}
+
+// logTypeEnvVars returns the env vars that enable the export of the log types to an additional store, which are named
+// <prefix>_<log type>_LOG.
+func logTypeEnvVars(prefix string, logTypes []operatorv1.SyslogLogType) []corev1.EnvVar {
+	var names []string
+	for _, t := range logTypes {
+		switch t {
+		case operatorv1.SyslogLogAudit:
+			names = append(names, "AUDIT_EE", "AUDIT_KUBE")
+		case operatorv1.SyslogLogDNS:
+			names = append(names, "DNS")
+		case operatorv1.SyslogLogFlows:
+			names = append(names, "FLOW")
+		case operatorv1.SyslogLogIDSEvents:
+			names = append(names, "IDS_EVENT")
+		}
+	}
+	var envs []corev1.EnvVar
+	for _, name := range names {
+		envs = append(envs, corev1.EnvVar{Name: fmt.Sprintf("%s_%s_LOG", prefix, name), Value: "true"})
+	}
+	return envs
+}
+
 func (c *fluentdComponent) splunkCredentialSecret() []*corev1.Secret {
 	if c.cfg.SplkCredential == nil {
 		return nil
@@ -428,8 +801,7 @@ func (c *fluentdComponent) packetCaptureApiRoleBinding() *rbacv1.RoleBinding {
 
 // managerDeployment creates a deployment for the Tigera Secure manager component.
 func (c *fluentdComponent) daemonset() *appsv1.DaemonSet {
-	var terminationGracePeriod int64 = 0
-	maxUnavailable := intstr.FromInt(1)
+	terminationGracePeriod := c.terminationGracePeriod()
 
 	annots := c.cfg.TrustedBundle.HashAnnotations()
 
@@ -445,13 +817,19 @@ func (c *fluentdComponent) daemonset() *appsv1.DaemonSet {
 	if c.cfg.Filters != nil {
 		annots[filterHashAnnotation] = rmeta.AnnotationHash(c.cfg.Filters)
 	}
+	for _, ca := range c.additionalStoreCAs() {
+		annots[fmt.Sprintf("hash.operator.tigera.io/%s", ca.secretName)] = rmeta.AnnotationHash(ca.bundle)
+	}
 	var initContainers []corev1.Container
-	if c.cfg.MetricsServerTLS != nil && c.cfg.MetricsServerTLS.UseCertificateManagement() {
+	// The init container that requests the certificate only runs on Linux, so the controller doesn't configure the
+	// metrics TLS of Windows nodes with certificate management.
+	if c.cfg.MetricsServerTLS != nil && c.cfg.MetricsServerTLS.UseCertificateManagement() && c.cfg.OSType != rmeta.OSTypeWindows {
 		initContainers = append(initContainers, c.cfg.MetricsServerTLS.InitContainer(LogCollectorNamespace))
 	}
 
 	podTemplate := relasticsearch.DecorateAnnotations(&corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
+			Labels:      map[string]string{FluentdLabel: c.fluentdNodeName()},
 			Annotations: annots,
 		},
 		Spec: corev1.PodSpec{
@@ -463,8 +841,12 @@ func (c *fluentdComponent) daemonset() *appsv1.DaemonSet {
 			Containers:                    []corev1.Container{c.container()},
 			Volumes:                       c.volumes(),
 			ServiceAccountName:            c.fluentdNodeName(),
+			DNSConfig:                     c.cfg.LogCollector.Spec.DNSConfig,
 		},
-	}, c.cfg.ESClusterConfig, c.cfg.ESSecrets).(*corev1.PodTemplateSpec)
+	}, c.cfg.ESClusterConfig, c.esSecrets()).(*corev1.PodTemplateSpec)
+	if c.cfg.DeferRollouts && c.cfg.CurrentDaemonSet != nil {
+		podTemplate.Annotations = withHashAnnotations(podTemplate.Annotations, c.cfg.CurrentDaemonSet.Spec.Template.Annotations)
+	}
 
 	ds := &appsv1.DaemonSet{
 		TypeMeta: metav1.TypeMeta{Kind: "DaemonSet", APIVersion: "apps/v1"},
@@ -473,19 +855,363 @@ func (c *fluentdComponent) daemonset() *appsv1.DaemonSet {
 			Namespace: LogCollectorNamespace,
 		},
 		Spec: appsv1.DaemonSetSpec{
-			Template: *podTemplate,
-			UpdateStrategy: appsv1.DaemonSetUpdateStrategy{
-				RollingUpdate: &appsv1.RollingUpdateDaemonSet{
-					MaxUnavailable: &maxUnavailable,
-				},
-			},
+			Template:       *podTemplate,
+			UpdateStrategy: rollingUpdateStrategy(),
 		},
 	}
 
+	if c.candidatePromoted() {
+		applyCandidate(&ds.Spec.Template, c.cfg.LogCollector.Spec.Candidate)
+	}
+
 	setNodeCriticalPod(&(ds.Spec.Template))
+	// The nodes of the node pools are left to the DaemonSets of the pools.
+	ds.Spec.Template.Spec.Affinity = nodePoolAffinity(nil, c.cfg.LogCollector.Spec.NodePools)
+	if c.controlPlaneNodesExcluded() {
+		ds.Spec.Template.Spec.Affinity = withoutControlPlaneNodes(ds.Spec.Template.Spec.Affinity)
+	}
+
+	ds.Annotations = daemonSetResizeAnnotations(&ds.Spec.Template)
+	if c.resizeInPlace(ds) {
+		ds.Spec.UpdateStrategy = appsv1.DaemonSetUpdateStrategy{Type: appsv1.OnDeleteDaemonSetStrategyType}
+	}
+	return ds
+}
+
+// withHashAnnotations returns the annotations with their hash annotations replaced by those of current, so that
+// changes of the hashed configuration and certificates are not rolled out.
+func withHashAnnotations(annotations, current map[string]string) map[string]string {
+	result := map[string]string{}
+	for k, v := range annotations {
+		if !strings.HasPrefix(k, hashAnnotationPrefix) {
+			result[k] = v
+		}
+	}
+	for k, v := range current {
+		if strings.HasPrefix(k, hashAnnotationPrefix) {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// rollingUpdateStrategy returns the update strategy of the fluentd DaemonSets, which restarts the pods of one node at
+// a time.
+func rollingUpdateStrategy() appsv1.DaemonSetUpdateStrategy {
+	maxUnavailable := intstr.FromInt(1)
+	return appsv1.DaemonSetUpdateStrategy{
+		RollingUpdate: &appsv1.RollingUpdateDaemonSet{
+			MaxUnavailable: &maxUnavailable,
+		},
+	}
+}
+
+// nodePoolDaemonSetName returns the name of the fluentd DaemonSet of the node pool.
+func (c *fluentdComponent) nodePoolDaemonSetName(pool string) string {
+	return fmt.Sprintf("%s-pool-%s", c.fluentdNodeName(), pool)
+}
+
+// nodePoolDaemonSets returns the fluentd DaemonSets of the node pools of the LogCollector to create, and those of the
+// pools that were removed to delete.
+func (c *fluentdComponent) nodePoolDaemonSets() ([]client.Object, []client.Object) {
+	var objs, toDelete []client.Object
+	pools := c.cfg.LogCollector.Spec.NodePools
+	configured := map[string]bool{}
+	for i := range pools {
+		configured[pools[i].Name] = true
+		objs = append(objs, c.nodePoolDaemonSet(pools[i], pools[:i]))
+	}
+	for _, name := range c.cfg.CurrentNodePools {
+		if !configured[name] {
+			toDelete = append(toDelete, &appsv1.DaemonSet{
+				TypeMeta:   metav1.TypeMeta{Kind: "DaemonSet", APIVersion: "apps/v1"},
+				ObjectMeta: metav1.ObjectMeta{Name: c.nodePoolDaemonSetName(name), Namespace: LogCollectorNamespace},
+			})
+		}
+	}
+	return objs, toDelete
+}
+
+// nodePoolDaemonSet returns the fluentd DaemonSet of the node pool, which runs fluentd with the environment of the pool
+// on the nodes that match the pool and none of the pools before it. The log files of the containers are mounted for the
+// container runtime of the pool, if it overrides it.
+func (c *fluentdComponent) nodePoolDaemonSet(pool operatorv1.FluentdNodePool, before []operatorv1.FluentdNodePool) *appsv1.DaemonSet {
+	ds := c.withContainerRuntime(pool.ContainerRuntime).daemonset()
+	ds.Name = c.nodePoolDaemonSetName(pool.Name)
+	ds.Labels = map[string]string{FluentdNodePoolLabel: pool.Name}
+	ds.Spec.UpdateStrategy = rollingUpdateStrategy()
+
+	template := &ds.Spec.Template
+	if template.Labels == nil {
+		template.Labels = map[string]string{}
+	}
+	template.Labels[FluentdNodePoolLabel] = pool.Name
+	template.Spec.Affinity = nodePoolAffinity(pool.NodeSelector, before)
+	if c.controlPlaneNodesExcluded() {
+		template.Spec.Affinity = withoutControlPlaneNodes(template.Spec.Affinity)
+	}
+	for i := range template.Spec.Containers {
+		if template.Spec.Containers[i].Name == "fluentd" {
+			template.Spec.Containers[i].Env = overrideEnvVars(template.Spec.Containers[i].Env, pool.Env)
+		}
+	}
+
+	ds.Annotations = daemonSetResizeAnnotations(template)
+	return ds
+}
+
+// candidateEnabled returns whether the candidate fluentd runs next to fluentd, i.e. it is configured and it has not
+// been promoted yet.
+func (c *fluentdComponent) candidateEnabled() bool {
+	candidate := c.cfg.LogCollector.Spec.Candidate
+	return candidate != nil && !candidate.Promote && c.cfg.OSType == rmeta.OSTypeLinux
+}
+
+// candidatePromoted returns whether the candidate fluentd has been promoted, in which case fluentd runs with the image
+// and the environment of the candidate on all nodes.
+func (c *fluentdComponent) candidatePromoted() bool {
+	candidate := c.cfg.LogCollector.Spec.Candidate
+	return candidate != nil && candidate.Promote && c.cfg.OSType == rmeta.OSTypeLinux
+}
+
+// candidateDaemonSet returns the DaemonSet of the candidate fluentd, which runs with the image and the environment of
+// the candidate next to fluentd on the selected nodes. The candidate reads the same logs as fluentd, so its indices are
+// suffixed to tell the logs that it sends apart from those of fluentd. It keeps its position files in a directory of
+// its own, into which the directories of the logs are mounted read-only.
+func (c *fluentdComponent) candidateDaemonSet() *appsv1.DaemonSet {
+	candidate := c.cfg.LogCollector.Spec.Candidate
+	ds := c.daemonset()
+	ds.Name = FluentdCandidateName
+	ds.Labels = map[string]string{FluentdCandidateLabel: "true"}
+	ds.Spec.UpdateStrategy = rollingUpdateStrategy()
+
+	template := &ds.Spec.Template
+	if template.Labels == nil {
+		template.Labels = map[string]string{}
+	}
+	template.Labels[FluentdCandidateLabel] = "true"
+	template.Spec.Affinity = nodePoolAffinity(candidate.NodeSelector, nil)
+	if c.controlPlaneNodesExcluded() {
+		template.Spec.Affinity = withoutControlPlaneNodes(template.Spec.Affinity)
+	}
+	applyCandidate(template, candidate)
+
+	for i := range template.Spec.Volumes {
+		if template.Spec.Volumes[i].Name == "var-log-calico" && template.Spec.Volumes[i].HostPath != nil {
+			template.Spec.Volumes[i].HostPath.Path = candidatePositionsPath
+		}
+	}
+	template.Spec.Volumes = append(template.Spec.Volumes, corev1.Volume{
+		Name:         "host-var-log-calico",
+		VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: c.volumeHostPath()}},
+	})
+
+	suffix := candidate.OutputSuffix
+	if suffix == "" {
+		suffix = defaultCandidateOutputSuffix
+	}
+	for i := range template.Spec.Containers {
+		container := &template.Spec.Containers[i]
+		if container.Name != "fluentd" {
+			continue
+		}
+		for j := range container.Env {
+			if container.Env[j].Name == "ELASTIC_INDEX_SUFFIX" {
+				container.Env[j].Value = fmt.Sprintf("%s.%s", container.Env[j].Value, suffix)
+			}
+		}
+		for _, dir := range candidateLogDirs {
+			container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+				Name:      "host-var-log-calico",
+				MountPath: c.path("/var/log/calico/" + dir),
+				SubPath:   dir,
+				ReadOnly:  true,
+			})
+		}
+	}
+
+	ds.Annotations = daemonSetResizeAnnotations(template)
+	return ds
+}
+
+// applyCandidate sets the image and the environment of the candidate on the fluentd container of the pod template.
+func applyCandidate(template *corev1.PodTemplateSpec, candidate *operatorv1.FluentdCandidate) {
+	for i := range template.Spec.Containers {
+		container := &template.Spec.Containers[i]
+		if container.Name != "fluentd" {
+			continue
+		}
+		if candidate.Image != "" {
+			container.Image = candidate.Image
+		}
+		container.Env = overrideEnvVars(container.Env, candidate.Env)
+	}
+}
+
+// controlPlaneDaemonSetEnabled returns whether the control plane nodes run fluentd with a DaemonSet of their own, to
+// give fluentd other resources on them.
+func (c *fluentdComponent) controlPlaneDaemonSetEnabled() bool {
+	cp := c.cfg.LogCollector.Spec.ControlPlaneNodes
+	return cp != nil && cp.Scheduling != operatorv1.ControlPlaneNodeSchedulingExclude && cp.ResourceRequirements != nil
+}
+
+// controlPlaneNodesExcluded returns whether the control plane nodes are excluded from the fluentd DaemonSet and those of
+// the node pools, either because fluentd doesn't run on them or because they have a DaemonSet of their own.
+func (c *fluentdComponent) controlPlaneNodesExcluded() bool {
+	cp := c.cfg.LogCollector.Spec.ControlPlaneNodes
+	return cp != nil && (cp.Scheduling == operatorv1.ControlPlaneNodeSchedulingExclude || c.controlPlaneDaemonSetEnabled())
+}
+
+// controlPlaneDaemonSet returns the fluentd DaemonSet of the control plane nodes, which runs fluentd with the resources
+// of the control plane nodes. The control plane nodes take precedence over the node pools.
+func (c *fluentdComponent) controlPlaneDaemonSet() *appsv1.DaemonSet {
+	ds := c.daemonset()
+	ds.Name = FluentdControlPlaneName
+	ds.Spec.UpdateStrategy = rollingUpdateStrategy()
+
+	template := &ds.Spec.Template
+	var terms []corev1.NodeSelectorTerm
+	for _, label := range controlPlaneNodeLabels {
+		terms = append(terms, corev1.NodeSelectorTerm{
+			MatchExpressions: []corev1.NodeSelectorRequirement{{Key: label, Operator: corev1.NodeSelectorOpExists}},
+		})
+	}
+	template.Spec.Affinity = &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: terms},
+		},
+	}
+	for i := range template.Spec.Containers {
+		if template.Spec.Containers[i].Name == "fluentd" {
+			template.Spec.Containers[i].Resources = *c.cfg.LogCollector.Spec.ControlPlaneNodes.ResourceRequirements
+		}
+	}
+
+	ds.Annotations = daemonSetResizeAnnotations(template)
 	return ds
 }
 
+// withoutControlPlaneNodes returns the affinity that additionally requires the nodes not to be control plane nodes.
+func withoutControlPlaneNodes(affinity *corev1.Affinity) *corev1.Affinity {
+	var reqs []corev1.NodeSelectorRequirement
+	for _, label := range controlPlaneNodeLabels {
+		reqs = append(reqs, corev1.NodeSelectorRequirement{Key: label, Operator: corev1.NodeSelectorOpDoesNotExist})
+	}
+	if affinity == nil {
+		return &corev1.Affinity{
+			NodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+					NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: reqs}},
+				},
+			},
+		}
+	}
+
+	// The terms are ORed, so each of them has to exclude the control plane nodes.
+	terms := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	for i := range terms {
+		terms[i].MatchExpressions = append(terms[i].MatchExpressions, reqs...)
+	}
+	return affinity
+}
+
+// overrideEnvVars returns the environment variables with the values of the overrides, which are added when they are
+// not set.
+func overrideEnvVars(env []corev1.EnvVar, overrides []operatorv1.FluentdEnvVar) []corev1.EnvVar {
+	for _, override := range overrides {
+		found := false
+		for i := range env {
+			if env[i].Name == override.Name {
+				env[i] = corev1.EnvVar{Name: override.Name, Value: override.Value}
+				found = true
+			}
+		}
+		if !found {
+			env = append(env, corev1.EnvVar{Name: override.Name, Value: override.Value})
+		}
+	}
+	return env
+}
+
+// nodePoolAffinity returns the affinity of the pods to the nodes that match the selector and none of the excluded node
+// pools, or nil if any node matches.
+func nodePoolAffinity(selector map[string]string, excluded []operatorv1.FluentdNodePool) *corev1.Affinity {
+	if len(selector) == 0 && len(excluded) == 0 {
+		return nil
+	}
+
+	// The terms of a node selector are ORed and the requirements of a term are ANDed. A node doesn't match a pool if
+	// any of the labels of the pool doesn't match, so excluding a pool expands each term into one term per label.
+	terms := [][]corev1.NodeSelectorRequirement{nodeSelectorRequirements(selector, corev1.NodeSelectorOpIn)}
+	for _, pool := range excluded {
+		var expanded [][]corev1.NodeSelectorRequirement
+		for _, term := range terms {
+			for _, req := range nodeSelectorRequirements(pool.NodeSelector, corev1.NodeSelectorOpNotIn) {
+				expanded = append(expanded, append(append([]corev1.NodeSelectorRequirement{}, term...), req))
+			}
+		}
+		terms = expanded
+	}
+
+	nodeSelector := &corev1.NodeSelector{}
+	for _, term := range terms {
+		nodeSelector.NodeSelectorTerms = append(nodeSelector.NodeSelectorTerms, corev1.NodeSelectorTerm{MatchExpressions: term})
+	}
+	return &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{RequiredDuringSchedulingIgnoredDuringExecution: nodeSelector},
+	}
+}
+
+// nodeSelectorRequirements returns a requirement with the given operator for each label of the selector, ordered by
+// label.
+func nodeSelectorRequirements(selector map[string]string, op corev1.NodeSelectorOperator) []corev1.NodeSelectorRequirement {
+	var keys []string
+	for key := range selector {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var reqs []corev1.NodeSelectorRequirement
+	for _, key := range keys {
+		reqs = append(reqs, corev1.NodeSelectorRequirement{Key: key, Operator: op, Values: []string{selector[key]}})
+	}
+	return reqs
+}
+
+// daemonSetResizeAnnotations returns the annotations with the hashes of the pod template without the resources of its
+// containers, and of only these resources.
+func daemonSetResizeAnnotations(template *corev1.PodTemplateSpec) map[string]string {
+	withoutResources := template.DeepCopy()
+	var resources []corev1.ResourceRequirements
+	for i := range withoutResources.Spec.InitContainers {
+		resources = append(resources, withoutResources.Spec.InitContainers[i].Resources)
+		withoutResources.Spec.InitContainers[i].Resources = corev1.ResourceRequirements{}
+	}
+	for i := range withoutResources.Spec.Containers {
+		resources = append(resources, withoutResources.Spec.Containers[i].Resources)
+		withoutResources.Spec.Containers[i].Resources = corev1.ResourceRequirements{}
+	}
+	return map[string]string{
+		FluentdTemplateHashAnnotation:  rmeta.AnnotationHash(withoutResources),
+		FluentdResourcesHashAnnotation: rmeta.AnnotationHash(resources),
+	}
+}
+
+// resizeInPlace returns whether the pods of the DaemonSet are resized in place instead of being restarted. This is
+// the case when only the resources changed from the current DaemonSet, or when the current DaemonSet is already being
+// resized in place for the same pod template.
+func (c *fluentdComponent) resizeInPlace(ds *appsv1.DaemonSet) bool {
+	current := c.cfg.CurrentDaemonSet
+	if !c.cfg.InPlaceResize || current == nil {
+		return false
+	}
+	if current.Annotations[FluentdTemplateHashAnnotation] != ds.Annotations[FluentdTemplateHashAnnotation] {
+		return false
+	}
+	return current.Annotations[FluentdResourcesHashAnnotation] != ds.Annotations[FluentdResourcesHashAnnotation] ||
+		current.Spec.UpdateStrategy.Type == appsv1.OnDeleteDaemonSetStrategyType
+}
+
 // logCollectorTolerations creates the node's tolerations.
 func (c *fluentdComponent) tolerations() []corev1.Toleration {
 	tolerations := []corev1.Toleration{
@@ -531,44 +1257,176 @@ func (c *fluentdComponent) container() corev1.Container {
 			})
 	}
 
+	for _, ca := range c.additionalStoreCAs() {
+		volumeMounts = append(volumeMounts,
+			corev1.VolumeMount{
+				Name:      ca.secretName,
+				MountPath: c.additionalStoreCADir(ca.store),
+				ReadOnly:  true,
+			})
+	}
+
+	for i, path := range c.containerLogPaths() {
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: containerLogVolumeName(i), MountPath: path, ReadOnly: true})
+	}
+
 	volumeMounts = append(volumeMounts, c.cfg.TrustedBundle.VolumeMount(c.SupportedOSType()))
 
 	if c.cfg.MetricsServerTLS != nil {
 		volumeMounts = append(volumeMounts, c.cfg.MetricsServerTLS.VolumeMount(c.SupportedOSType()))
 	}
 
+	if c.esGatewayTokenEnabled() {
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: esGatewayTokenVolumeName, MountPath: c.esGatewayTokenDir(), ReadOnly: true})
+	}
+
 	isPrivileged := false
 	// On OpenShift Fluentd needs privileged access to access logs on host path volume
 	if c.cfg.Installation.KubernetesProvider == operatorv1.ProviderOpenShift {
 		isPrivileged = true
 	}
 
-	return relasticsearch.ContainerDecorateENVVars(corev1.Container{
+	container := corev1.Container{
 		Name:            "fluentd",
 		Image:           c.image,
 		Env:             envs,
 		SecurityContext: &corev1.SecurityContext{Privileged: &isPrivileged},
 		VolumeMounts:    volumeMounts,
+		Resources:       c.resources(),
 		StartupProbe:    c.startup(),
 		LivenessProbe:   c.liveness(),
 		ReadinessProbe:  c.readiness(),
+		Lifecycle:       c.lifecycle(),
 		Ports: []corev1.ContainerPort{{
 			Name:          "metrics-port",
 			ContainerPort: FluentdMetricsPort,
 		}},
-	}, c.cfg.ESClusterConfig.ClusterName(), ElasticsearchLogCollectorUserSecret, c.cfg.ClusterDomain, c.cfg.OSType)
+	}
+	if c.esGatewayTokenEnabled() {
+		return relasticsearch.ContainerDecorateTokenENVVars(container, c.cfg.ESClusterConfig.ClusterName(), c.esGatewayTokenDir()+"/token", c.cfg.ClusterDomain, c.cfg.OSType)
+	}
+	return relasticsearch.ContainerDecorateENVVars(container, c.cfg.ESClusterConfig.ClusterName(), ElasticsearchLogCollectorUserSecret, c.cfg.ClusterDomain, c.cfg.OSType)
+}
+
+// esGatewayTokenEnabled returns whether fluentd authenticates to es-gateway with a projected service account token
+// instead of the password of its Elasticsearch user, which the LogCollector opts in to. The es-gateway of a management
+// cluster can't validate the tokens of a managed cluster, so fluentd keeps using the password there.
+func (c *fluentdComponent) esGatewayTokenEnabled() bool {
+	return !c.cfg.ManagedCluster && c.cfg.LogCollector != nil && c.cfg.LogCollector.Spec.ESGatewayTokenAuthEnabled()
+}
+
+func (c *fluentdComponent) esGatewayTokenDir() string {
+	return c.path("/var/run/secrets/tigera/es-gateway")
+}
+
+// disruptionPolicy returns the disruption policy of the component in the LogCollector, or nil if it has none.
+func (c *fluentdComponent) disruptionPolicy(name operatorv1.LogCollectorComponentName) *operatorv1.DisruptionPolicy {
+	if c.cfg.LogCollector == nil {
+		return nil
+	}
+	return c.cfg.LogCollector.Spec.ComponentDisruptionPolicy(name)
+}
+
+// terminationGracePeriod returns how long fluentd is given to flush its buffers when it is stopped. The disruption
+// policy of fluentd takes precedence over the termination grace period of the LogCollector.
+func (c *fluentdComponent) terminationGracePeriod() int64 {
+	if policy := c.disruptionPolicy(operatorv1.ComponentNameFluentd); policy != nil && policy.TerminationGracePeriodSeconds != nil {
+		return *policy.TerminationGracePeriodSeconds
+	}
+	if c.cfg.LogCollector != nil && c.cfg.LogCollector.Spec.TerminationGracePeriodSeconds != nil {
+		return *c.cfg.LogCollector.Spec.TerminationGracePeriodSeconds
+	}
+	return defaultFluentdTerminationGracePeriod
 }
 
+// lifecycle flushes the buffers of fluentd before it is sent SIGTERM, and gives the flush some time to complete within
+// the termination grace period, so that the last logs of a node aren't lost when it is drained.
+func (c *fluentdComponent) lifecycle() *corev1.Lifecycle {
+	if c.cfg.OSType == rmeta.OSTypeWindows {
+		// Fluentd on Windows doesn't handle signals, it only flushes on shutdown.
+		return nil
+	}
+	flushWait := c.terminationGracePeriod() / 2
+	if flushWait > fluentdMaxFlushWait {
+		flushWait = fluentdMaxFlushWait
+	}
+	if policy := c.disruptionPolicy(operatorv1.ComponentNameFluentd); policy != nil && policy.PreStopDelaySeconds != nil {
+		flushWait = *policy.PreStopDelaySeconds
+	}
+	return &corev1.Lifecycle{
+		PreStop: &corev1.LifecycleHandler{
+			Exec: &corev1.ExecAction{
+				// SIGUSR1 makes fluentd flush its buffers.
+				Command: []string{"/bin/sh", "-c", fmt.Sprintf("kill -USR1 1; sleep %d", flushWait)},
+			},
+		},
+	}
+}
+
+// esOutputEnvVars returns the env vars that tune the Elasticsearch output of fluentd. Only the settings of the
+// LogCollector are set, fluentd uses its defaults for the others.
+func (c *fluentdComponent) esOutputEnvVars() []corev1.EnvVar {
+	if c.cfg.LogCollector == nil || c.cfg.LogCollector.Spec.ElasticsearchOutput == nil {
+		return nil
+	}
+	out := c.cfg.LogCollector.Spec.ElasticsearchOutput
+	var envs []corev1.EnvVar
+	if out.BulkMessageSize != nil {
+		envs = append(envs, corev1.EnvVar{Name: "ELASTIC_CHUNK_LIMIT_SIZE", Value: strconv.FormatInt(out.BulkMessageSize.Value(), 10)})
+	}
+	if out.BulkRequestThreshold != nil {
+		envs = append(envs, corev1.EnvVar{Name: "ELASTIC_BULK_MESSAGE_REQUEST_THRESHOLD", Value: strconv.FormatInt(out.BulkRequestThreshold.Value(), 10)})
+	}
+	if out.RequestTimeout != nil {
+		// Fluentd parses durations in seconds, it doesn't support the Go format of e.g. 1m30s.
+		envs = append(envs, corev1.EnvVar{Name: "ELASTIC_REQUEST_TIMEOUT", Value: fmt.Sprintf("%ds", int64(out.RequestTimeout.Seconds()))})
+	}
+	if out.ReconnectOnError != nil {
+		envs = append(envs, corev1.EnvVar{Name: "ELASTIC_RECONNECT_ON_ERROR", Value: strconv.FormatBool(*out.ReconnectOnError)})
+	}
+	return envs
+}
+
+// resources returns the resource requirements of the fluentd container, with the overrides of the LogCollector
+// componentResources applied.
+func (c *fluentdComponent) resources() corev1.ResourceRequirements {
+	var userOverrides *corev1.ResourceRequirements
+	for _, cr := range c.cfg.LogCollector.Spec.ComponentResources {
+		if cr.ComponentName == operatorv1.ComponentNameFluentd {
+			userOverrides = cr.ResourceRequirements
+		}
+	}
+	defaults := resourceDefaultsFluentd
+	if c.auditOnly() {
+		defaults = resourceDefaultsFluentdAuditOnly
+	}
+	return componentResourceRequirements(presetsProvider(c.cfg.Installation.KubernetesProvider, c.cfg.LogCollector.Spec.ProviderResourcePresets), defaults, userOverrides)
+}
+
+// auditOnly returns whether fluentd only collects the audit logs.
+func (c *fluentdComponent) auditOnly() bool {
+	return c.cfg.LogCollector.Spec.Mode == operatorv1.LogCollectorModeAuditOnly
+}
+
+func (c *fluentdComponent) metricsServiceName() string {
+	if c.cfg.OSType == rmeta.OSTypeWindows {
+		return FluentdWindowsMetricsService
+	}
+	return FluentdMetricsService
+}
+
+// metricsService exposes the metrics of the fluentd pods of the OS type, including those of the DaemonSets of the node
+// pools and the control plane nodes, so that each OS type is scraped through its own service monitor.
 func (c *fluentdComponent) metricsService() *corev1.Service {
 	return &corev1.Service{
 		TypeMeta: metav1.TypeMeta{Kind: "Service", APIVersion: "v1"},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      FluentdMetricsService,
+			Name:      c.metricsServiceName(),
 			Namespace: LogCollectorNamespace,
-			Labels:    map[string]string{"k8s-app": FluentdNodeName},
+			Labels:    map[string]string{"k8s-app": c.fluentdNodeName()},
 		},
 		Spec: corev1.ServiceSpec{
-			Selector: map[string]string{"k8s-app": FluentdNodeName},
+			Selector: map[string]string{FluentdLabel: c.fluentdNodeName()},
 			Type:     corev1.ServiceTypeClusterIP,
 			Ports: []corev1.ServicePort{
 				{
@@ -591,6 +1449,19 @@ func (c *fluentdComponent) envvars() []corev1.EnvVar {
 		{Name: "NODENAME", ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "spec.nodeName"}}},
 	}
 
+	if c.cfg.RedactionKeySecret != nil {
+		// The key of the HMAC of the hashed fields of the redaction filters.
+		envs = append(envs, corev1.EnvVar{
+			Name: fluentdRedactionKeyEnv,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: FluentdRedactionKeySecret},
+					Key:                  FluentdRedactionKeyName,
+				},
+			},
+		})
+	}
+
 	if c.cfg.LogCollector.Spec.AdditionalStores != nil {
 		s3 := c.cfg.LogCollector.Spec.AdditionalStores.S3
 		if s3 != nil {
@@ -650,31 +1521,7 @@ func (c *fluentdComponent) envvars() []corev1.EnvVar {
 				)
 			}
 
-			if syslog.LogTypes != nil {
-				for _, t := range syslog.LogTypes {
-					switch t {
-					case operatorv1.SyslogLogAudit:
-						envs = append(envs,
-							corev1.EnvVar{Name: "SYSLOG_AUDIT_EE_LOG", Value: "true"},
-						)
-						envs = append(envs,
-							corev1.EnvVar{Name: "SYSLOG_AUDIT_KUBE_LOG", Value: "true"},
-						)
-					case operatorv1.SyslogLogDNS:
-						envs = append(envs,
-							corev1.EnvVar{Name: "SYSLOG_DNS_LOG", Value: "true"},
-						)
-					case operatorv1.SyslogLogFlows:
-						envs = append(envs,
-							corev1.EnvVar{Name: "SYSLOG_FLOW_LOG", Value: "true"},
-						)
-					case operatorv1.SyslogLogIDSEvents:
-						envs = append(envs,
-							corev1.EnvVar{Name: "SYSLOG_IDS_EVENT_LOG", Value: "true"},
-						)
-					}
-				}
-			}
+			envs = append(envs, logTypeEnvVars("SYSLOG", c.storeLogTypes(syslog.LogTypes))...)
 		}
 		splunk := c.cfg.LogCollector.Spec.AdditionalStores.Splunk
 		if splunk != nil {
@@ -691,15 +1538,19 @@ func (c *fluentdComponent) envvars() []corev1.EnvVar {
 						},
 					},
 				},
-				corev1.EnvVar{Name: "SPLUNK_FLOW_LOG", Value: "true"},
+				corev1.EnvVar{Name: "SPLUNK_FLOW_LOG", Value: strconv.FormatBool(!c.auditOnly())},
 				corev1.EnvVar{Name: "SPLUNK_AUDIT_LOG", Value: "true"},
-				corev1.EnvVar{Name: "SPLUNK_DNS_LOG", Value: "true"},
+				corev1.EnvVar{Name: "SPLUNK_DNS_LOG", Value: strconv.FormatBool(!c.auditOnly())},
 				corev1.EnvVar{Name: "SPLUNK_HEC_HOST", Value: host},
 				corev1.EnvVar{Name: "SPLUNK_HEC_PORT", Value: port},
 				corev1.EnvVar{Name: "SPLUNK_PROTOCOL", Value: proto},
 				corev1.EnvVar{Name: "SPLUNK_FLUSH_INTERVAL", Value: fluentdDefaultFlush},
 			)
-			if len(c.cfg.SplkCredential.Certificate) != 0 {
+			if len(c.cfg.SplunkCA) != 0 {
+				envs = append(envs,
+					corev1.EnvVar{Name: "SPLUNK_CA_FILE", Value: c.additionalStoreCADir("splunk") + AdditionalStoreCAKey},
+				)
+			} else if len(c.cfg.SplkCredential.Certificate) != 0 {
 				envs = append(envs,
 					corev1.EnvVar{Name: "SPLUNK_CA_FILE", Value: SplunkFluentdDefaultCertPath},
 				)
@@ -707,7 +1558,7 @@ func (c *fluentdComponent) envvars() []corev1.EnvVar {
 		}
 	}
 
-	if c.cfg.Filters != nil {
+	if c.cfg.Filters != nil && !c.auditOnly() {
 		if c.cfg.Filters.Flow != "" {
 			envs = append(envs,
 				corev1.EnvVar{Name: "FLUENTD_FLOW_FILTERS", Value: "true"})
@@ -729,6 +1580,15 @@ func (c *fluentdComponent) envvars() []corev1.EnvVar {
 		corev1.EnvVar{Name: "ELASTIC_AUDIT_INDEX_SHARDS", Value: strconv.Itoa(c.cfg.ESClusterConfig.Shards())},
 		corev1.EnvVar{Name: "ELASTIC_BGP_INDEX_SHARDS", Value: strconv.Itoa(c.cfg.ESClusterConfig.Shards())},
 	)
+	envs = append(envs, c.esOutputEnvVars()...)
+
+	if c.logBufferEnabled() {
+		// Send the logs to the buffer instead of the management cluster, the buffer forwards them from there.
+		envs = append(envs,
+			corev1.EnvVar{Name: "LOG_BUFFER_HOST", Value: fmt.Sprintf("%s.%s.svc.%s", LogBufferName, LogCollectorNamespace, c.cfg.ClusterDomain)},
+			corev1.EnvVar{Name: "LOG_BUFFER_PORT", Value: strconv.Itoa(LogBufferPort)},
+		)
+	}
 
 	if c.SupportedOSType() != rmeta.OSTypeWindows {
 		envs = append(envs,
@@ -736,6 +1596,12 @@ func (c *fluentdComponent) envvars() []corev1.EnvVar {
 			corev1.EnvVar{Name: "TLS_KEY_PATH", Value: c.cfg.MetricsServerTLS.VolumeMountKeyFilePath()},
 			corev1.EnvVar{Name: "TLS_CRT_PATH", Value: c.cfg.MetricsServerTLS.VolumeMountCertificateFilePath()},
 		)
+	} else if c.cfg.MetricsServerTLS != nil {
+		// Serve the metrics of Windows nodes with TLS too, so that they are scraped like those of Linux nodes.
+		envs = append(envs,
+			corev1.EnvVar{Name: "TLS_KEY_PATH", Value: c.path(c.cfg.MetricsServerTLS.VolumeMountKeyFilePath())},
+			corev1.EnvVar{Name: "TLS_CRT_PATH", Value: c.path(c.cfg.MetricsServerTLS.VolumeMountCertificateFilePath())},
+		)
 	}
 
 	return envs
@@ -825,10 +1691,47 @@ func (c *fluentdComponent) volumes() []corev1.Volume {
 				},
 			})
 	}
+	for _, ca := range c.additionalStoreCAs() {
+		volumes = append(volumes,
+			corev1.Volume{
+				Name: ca.secretName,
+				VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{
+						SecretName: ca.secretName,
+						Items:      []corev1.KeyToPath{{Key: AdditionalStoreCAKey, Path: AdditionalStoreCAKey}},
+					},
+				},
+			})
+	}
+	for i, path := range c.containerLogPaths() {
+		volumes = append(volumes, corev1.Volume{
+			Name: containerLogVolumeName(i),
+			VolumeSource: corev1.VolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{Path: path},
+			},
+		})
+	}
 	if c.cfg.MetricsServerTLS != nil {
 		volumes = append(volumes, c.cfg.MetricsServerTLS.Volume())
 	}
 	volumes = append(volumes, trustedBundleVolume(c.cfg.TrustedBundle))
+	if c.esGatewayTokenEnabled() {
+		expiration := int64(esGatewayTokenExpirationSeconds)
+		volumes = append(volumes, corev1.Volume{
+			Name: esGatewayTokenVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Projected: &corev1.ProjectedVolumeSource{
+					Sources: []corev1.VolumeProjection{{
+						ServiceAccountToken: &corev1.ServiceAccountTokenProjection{
+							Audience:          ESGatewayTokenAudience,
+							ExpirationSeconds: &expiration,
+							Path:              "token",
+						},
+					}},
+				},
+			},
+		})
+	}
 
 	return volumes
 }
@@ -847,6 +1750,18 @@ func (c *fluentdComponent) fluentdPodSecurityPolicy() *policyv1beta1.PodSecurity
 			ReadOnly:   false,
 		},
 	}
+	containerLogPaths := c.containerLogPaths()
+	for _, pool := range c.cfg.LogCollector.Spec.NodePools {
+		containerLogPaths = append(containerLogPaths, c.withContainerRuntime(pool.ContainerRuntime).containerLogPaths()...)
+	}
+	allowed := map[string]bool{}
+	for _, path := range containerLogPaths {
+		if allowed[path] {
+			continue
+		}
+		allowed[path] = true
+		psp.Spec.AllowedHostPaths = append(psp.Spec.AllowedHostPaths, policyv1beta1.AllowedHostPath{PathPrefix: path, ReadOnly: true})
+	}
 	psp.Spec.RunAsUser.Rule = policyv1beta1.RunAsUserStrategyRunAsAny
 	return psp
 }
@@ -912,6 +1827,18 @@ func (c *fluentdComponent) eksLogForwarderSecret() *corev1.Secret {
 	}
 }
 
+// eksLogForwarderPodDisruptionBudget returns the PodDisruptionBudget of the EKS log forwarder.
+func (c *fluentdComponent) eksLogForwarderPodDisruptionBudget() *policyv1.PodDisruptionBudget {
+	var cfg *operatorv1.PodDisruptionBudget
+	if sources := c.cfg.LogCollector.Spec.AdditionalSources; sources != nil && sources.EksCloudwatchLog != nil {
+		cfg = sources.EksCloudwatchLog.PodDisruptionBudget
+	}
+	if cfg == nil {
+		cfg = disruptionpolicy.PodDisruptionBudget(c.disruptionPolicy(operatorv1.ComponentNameEKSLogForwarder))
+	}
+	return poddisruptionbudget.NewPodDisruptionBudget(eksLogForwarderName, LogCollectorNamespace, map[string]string{"k8s-app": eksLogForwarderName}, cfg)
+}
+
 func (c *fluentdComponent) eksLogForwarderDeployment() *appsv1.Deployment {
 	annots := map[string]string{
 		eksCloudwatchLogCredentialHashAnnotation: rmeta.AnnotationHash(c.cfg.EKSConfig),
@@ -936,7 +1863,7 @@ func (c *fluentdComponent) eksLogForwarderDeployment() *appsv1.Deployment {
 
 	var eksLogForwarderReplicas int32 = 1
 
-	return &appsv1.Deployment{
+	d := &appsv1.Deployment{
 		TypeMeta: metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      eksLogForwarderName,
@@ -968,6 +1895,7 @@ func (c *fluentdComponent) eksLogForwarderDeployment() *appsv1.Deployment {
 					Tolerations:        c.cfg.Installation.ControlPlaneTolerations,
 					ServiceAccountName: eksLogForwarderName,
 					ImagePullSecrets:   secret.GetReferenceList(c.cfg.PullSecrets),
+					DNSConfig:          c.cfg.LogCollector.Spec.DNSConfig,
 					InitContainers: []corev1.Container{relasticsearch.ContainerDecorateENVVars(corev1.Container{
 						Name:         eksLogForwarderName + "-startup",
 						Image:        c.image,
@@ -986,6 +1914,8 @@ func (c *fluentdComponent) eksLogForwarderDeployment() *appsv1.Deployment {
 			},
 		},
 	}
+	disruptionpolicy.Apply(&d.Spec.Template.Spec, c.disruptionPolicy(operatorv1.ComponentNameEKSLogForwarder), true)
+	return d
 }
 
 func trustedBundleVolume(bundle certificatemanagement.TrustedBundle) corev1.Volume {
@@ -1024,6 +1954,264 @@ func (c *fluentdComponent) eksLogForwarderVolumes() []corev1.Volume {
 	}
 }
 
+// verticalPodAutoscalers returns the VerticalPodAutoscalers of fluentd and the EKS log forwarder to create and to
+// delete. They are only deleted if the VerticalPodAutoscaler API is installed.
+func (c *fluentdComponent) verticalPodAutoscalers() ([]client.Object, []client.Object) {
+	var objs, toDelete []client.Object
+	vpa := c.cfg.LogCollector.Spec.VerticalPodAutoscaling
+	if vpa == nil && !c.cfg.VerticalPodAutoscalerAPI {
+		return nil, nil
+	}
+
+	fluentd := c.verticalPodAutoscaler("DaemonSet", c.fluentdNodeName())
+	if vpa != nil {
+		objs = append(objs, fluentd)
+	} else {
+		toDelete = append(toDelete, fluentd)
+	}
+
+	configured := map[string]bool{}
+	for _, pool := range c.cfg.LogCollector.Spec.NodePools {
+		configured[pool.Name] = true
+		poolVPA := c.verticalPodAutoscaler("DaemonSet", c.nodePoolDaemonSetName(pool.Name))
+		if vpa != nil {
+			objs = append(objs, poolVPA)
+		} else {
+			toDelete = append(toDelete, poolVPA)
+		}
+	}
+	for _, name := range c.cfg.CurrentNodePools {
+		if !configured[name] && c.cfg.VerticalPodAutoscalerAPI {
+			toDelete = append(toDelete, c.verticalPodAutoscaler("DaemonSet", c.nodePoolDaemonSetName(name)))
+		}
+	}
+
+	if c.cfg.OSType == rmeta.OSTypeLinux {
+		eksLogForwarder := c.verticalPodAutoscaler("Deployment", eksLogForwarderName)
+		if vpa != nil && c.cfg.EKSConfig != nil {
+			objs = append(objs, eksLogForwarder)
+		} else {
+			toDelete = append(toDelete, eksLogForwarder)
+		}
+	}
+	return objs, toDelete
+}
+
+// verticalPodAutoscaler returns the VerticalPodAutoscaler of the memory requests of the containers of the given
+// workload. The limits are left as they are.
+func (c *fluentdComponent) verticalPodAutoscaler(kind, name string) *vpav1.VerticalPodAutoscaler {
+	vpa := &vpav1.VerticalPodAutoscaler{
+		TypeMeta:   metav1.TypeMeta{Kind: "VerticalPodAutoscaler", APIVersion: "autoscaling.k8s.io/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: LogCollectorNamespace},
+	}
+	cfg := c.cfg.LogCollector.Spec.VerticalPodAutoscaling
+	if cfg == nil {
+		return vpa
+	}
+
+	mode := vpav1.UpdateModeOff
+	switch cfg.Mode {
+	case operatorv1.VerticalPodAutoscalingInitial:
+		mode = vpav1.UpdateModeInitial
+	case operatorv1.VerticalPodAutoscalingAuto:
+		mode = vpav1.UpdateModeAuto
+	}
+	policy := vpav1.ContainerResourcePolicy{
+		ContainerName:       "*",
+		ControlledResources: &[]corev1.ResourceName{corev1.ResourceMemory},
+	}
+	controlledValues := vpav1.ContainerControlledValuesRequestsOnly
+	policy.ControlledValues = &controlledValues
+	if cfg.MinAllowedMemory != nil {
+		policy.MinAllowed = corev1.ResourceList{corev1.ResourceMemory: *cfg.MinAllowedMemory}
+	}
+	if cfg.MaxAllowedMemory != nil {
+		policy.MaxAllowed = corev1.ResourceList{corev1.ResourceMemory: *cfg.MaxAllowedMemory}
+	}
+
+	vpa.Spec = vpav1.VerticalPodAutoscalerSpec{
+		TargetRef:      &autoscalingv1.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: kind, Name: name},
+		UpdatePolicy:   &vpav1.PodUpdatePolicy{UpdateMode: &mode},
+		ResourcePolicy: &vpav1.PodResourcePolicy{ContainerPolicies: []vpav1.ContainerResourcePolicy{policy}},
+	}
+	return vpa
+}
+
+// logBufferEnabled returns true if the logs of a managed cluster are buffered on the cluster before they are sent to
+// the management cluster. The buffer is only rendered by the linux component, but the fluentd pods of both operating
+// systems send their logs to it.
+func (c *fluentdComponent) logBufferEnabled() bool {
+	return c.cfg.ManagedCluster && c.cfg.LogBuffer != nil
+}
+
+func (c *fluentdComponent) logBufferServiceAccount() *corev1.ServiceAccount {
+	return &corev1.ServiceAccount{
+		TypeMeta:   metav1.TypeMeta{Kind: "ServiceAccount", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: LogBufferName, Namespace: LogCollectorNamespace},
+	}
+}
+
+func (c *fluentdComponent) logBufferService() *corev1.Service {
+	return &corev1.Service{
+		TypeMeta:   metav1.TypeMeta{Kind: "Service", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: LogBufferName, Namespace: LogCollectorNamespace},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{"k8s-app": LogBufferName},
+			Ports: []corev1.ServicePort{
+				{
+					Name:       LogBufferPortName,
+					Port:       int32(LogBufferPort),
+					TargetPort: intstr.FromInt(LogBufferPort),
+					Protocol:   corev1.ProtocolTCP,
+				},
+			},
+		},
+	}
+}
+
+// logBufferStorage returns the size of the volume that holds the buffered logs.
+func (c *fluentdComponent) logBufferStorage() resource.Quantity {
+	if c.cfg.LogBuffer != nil && c.cfg.LogBuffer.Storage != nil {
+		return *c.cfg.LogBuffer.Storage
+	}
+	return resource.MustParse(logBufferDefaultStorage)
+}
+
+// logBufferConfigMap renders the fluentd configuration of the log buffer. It receives the logs of the fluentd pods with
+// the forward protocol and writes them to Elasticsearch in the management cluster through the same Guardian tunnel
+// that is otherwise used directly, with the credentials in the environment of the container. Logs are dropped once
+// they couldn't be sent for longer than the retention, or when the buffer is full, the oldest first.
+func (c *fluentdComponent) logBufferConfigMap() *corev1.ConfigMap {
+	retentionSecs := logBufferDefaultRetentionSecs
+	if c.cfg.LogBuffer != nil && c.cfg.LogBuffer.Retention != nil {
+		retentionSecs = int(c.cfg.LogBuffer.Retention.Seconds())
+	}
+	storage := c.logBufferStorage()
+
+	config := fmt.Sprintf(`<source>
+  @type forward
+  port %d
+  bind 0.0.0.0
+</source>
+
+<match **>
+  @type elasticsearch
+  scheme "#{ENV['ELASTIC_SCHEME']}"
+  host "#{ENV['ELASTIC_HOST']}"
+  port "#{ENV['ELASTIC_PORT']}"
+  user "#{ENV['ELASTIC_USER']}"
+  password "#{ENV['ELASTIC_PASSWORD']}"
+  ca_file "#{ENV['ELASTIC_CA']}"
+  ssl_verify true
+  index_name tigera_secure_ee_${tag}.%s
+  <buffer tag>
+    @type file
+    path %s
+    # Leave some room on the volume for the files that fluentd writes while it flushes its buffer.
+    total_limit_size %d
+    overflow_action drop_oldest_chunk
+    retry_timeout %ds
+  </buffer>
+</match>
+`, LogBufferPort, c.cfg.ESClusterConfig.ClusterName(), logBufferPath, storage.Value()*9/10, retentionSecs)
+
+	return &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: LogBufferName, Namespace: LogCollectorNamespace},
+		Data:       map[string]string{"fluent.conf": config},
+	}
+}
+
+// logBufferStatefulSet renders a single fluentd instance with the configuration of logBufferConfigMap. The logs are
+// queued in a file buffer on a persistent volume, so that they are retained while the tunnel is down and replayed
+// once it is restored, up to the configured retention and volume size.
+func (c *fluentdComponent) logBufferStatefulSet() *appsv1.StatefulSet {
+	storage := c.logBufferStorage()
+	var storageClassName *string
+	if c.cfg.LogBuffer != nil && c.cfg.LogBuffer.StorageClassName != "" {
+		storageClassName = &c.cfg.LogBuffer.StorageClassName
+	}
+
+	annotations := c.cfg.TrustedBundle.HashAnnotations()
+	annotations[logBufferConfigHashAnnotation] = rmeta.AnnotationHash(c.logBufferConfigMap().Data)
+
+	var replicas int32 = 1
+
+	return &appsv1.StatefulSet{
+		TypeMeta: metav1.TypeMeta{Kind: "StatefulSet", APIVersion: "apps/v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      LogBufferName,
+			Namespace: LogCollectorNamespace,
+			Labels: map[string]string{
+				"k8s-app": LogBufferName,
+			},
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:    &replicas,
+			ServiceName: LogBufferName,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"k8s-app": LogBufferName,
+				},
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      LogBufferName,
+					Namespace: LogCollectorNamespace,
+					Labels: map[string]string{
+						"k8s-app": LogBufferName,
+					},
+					Annotations: annotations,
+				},
+				Spec: corev1.PodSpec{
+					Tolerations:        c.cfg.Installation.ControlPlaneTolerations,
+					NodeSelector:       c.cfg.Installation.ControlPlaneNodeSelector,
+					ServiceAccountName: LogBufferName,
+					ImagePullSecrets:   secret.GetReferenceList(c.cfg.PullSecrets),
+					// Let the non-root user of the container write to the volume of the buffer.
+					SecurityContext: &corev1.PodSecurityContext{FSGroup: &securitycontext.RunAsGroupID},
+					Containers: []corev1.Container{relasticsearch.ContainerDecorateENVVars(corev1.Container{
+						Name:            LogBufferName,
+						Image:           c.image,
+						Command:         []string{"fluentd", "-c", logBufferConfigDir + "/fluent.conf"},
+						SecurityContext: securitycontext.NewBaseContext(securitycontext.RunAsUserID, securitycontext.RunAsGroupID),
+						Ports:           []corev1.ContainerPort{{Name: LogBufferPortName, ContainerPort: int32(LogBufferPort)}},
+						VolumeMounts: []corev1.VolumeMount{
+							relasticsearch.DefaultVolumeMount(c.cfg.OSType),
+							{Name: certificatemanagement.TrustedCertConfigMapName, MountPath: "/etc/fluentd/elastic/"},
+							{Name: logBufferConfigVolumeName, MountPath: logBufferConfigDir, ReadOnly: true},
+							{Name: logBufferVolumeName, MountPath: logBufferPath},
+						},
+					}, c.cfg.ESClusterConfig.ClusterName(), ElasticsearchLogCollectorUserSecret, c.cfg.ClusterDomain, c.cfg.OSType)},
+					Volumes: []corev1.Volume{
+						trustedBundleVolume(c.cfg.TrustedBundle),
+						{
+							Name: logBufferConfigVolumeName,
+							VolumeSource: corev1.VolumeSource{
+								ConfigMap: &corev1.ConfigMapVolumeSource{
+									LocalObjectReference: corev1.LocalObjectReference{Name: LogBufferName},
+								},
+							},
+						},
+					},
+				},
+			},
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: logBufferVolumeName},
+					Spec: corev1.PersistentVolumeClaimSpec{
+						AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+						StorageClassName: storageClassName,
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceStorage: storage},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 func (c *fluentdComponent) eksLogForwarderPodSecurityPolicy() *policyv1beta1.PodSecurityPolicy {
 	psp := podsecuritypolicy.NewBasePolicy()
 	psp.GetObjectMeta().SetName(eksLogForwarderName)
@@ -1110,7 +2298,7 @@ func (c *fluentdComponent) allowTigeraPolicy() *v3.NetworkPolicy {
 		Spec: v3.NetworkPolicySpec{
 			Order:                  &networkpolicy.HighPrecedenceOrder,
 			Tier:                   networkpolicy.TigeraComponentTierName,
-			Selector:               networkpolicy.KubernetesAppSelector(FluentdNodeName, fluentdNodeWindowsName),
+			Selector:               fluentdSelector,
 			ServiceAccountSelector: "",
 			Types:                  []v3.PolicyType{v3.PolicyTypeIngress, v3.PolicyTypeEgress},
 			Ingress: []v3.Rule{
@@ -15,12 +15,15 @@
 package render_test
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
 	operatorv1 "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/apis"
+	vpav1 "github.com/tigera/operator/pkg/apis/autoscaling.k8s.io/v1"
 	"github.com/tigera/operator/pkg/common"
 	"github.com/tigera/operator/pkg/controller/certificatemanager"
 	"github.com/tigera/operator/pkg/dns"
@@ -31,7 +34,11 @@ import (
 	"github.com/tigera/operator/pkg/render/testutils"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -200,7 +207,7 @@ var _ = Describe("Tigera Secure Fluentd rendering tests", func() {
 		}{
 			{name: "tigera-fluentd", ns: "", group: "", version: "v1", kind: "Namespace"},
 			{name: render.FluentdPolicyName, ns: render.LogCollectorNamespace, group: "projectcalico.org", version: "v3", kind: "NetworkPolicy"},
-			{name: render.FluentdMetricsService, ns: render.LogCollectorNamespace, group: "", version: "v1", kind: "Service"},
+			{name: render.FluentdWindowsMetricsService, ns: render.LogCollectorNamespace, group: "", version: "v1", kind: "Service"},
 			{name: "fluentd-node-windows", ns: "tigera-fluentd", group: "", version: "v1", kind: "ServiceAccount"},
 			{name: render.PacketCaptureAPIRole, ns: render.LogCollectorNamespace, group: "rbac.authorization.k8s.io", version: "v1", kind: "Role"},
 			{name: render.PacketCaptureAPIRoleBinding, ns: render.LogCollectorNamespace, group: "rbac.authorization.k8s.io", version: "v1", kind: "RoleBinding"},
@@ -220,6 +227,9 @@ var _ = Describe("Tigera Secure Fluentd rendering tests", func() {
 			i++
 		}
 
+		svc := rtest.GetResource(resources, render.FluentdWindowsMetricsService, render.LogCollectorNamespace, "", "v1", "Service").(*corev1.Service)
+		Expect(svc.Spec.Selector).To(Equal(map[string]string{render.FluentdLabel: "fluentd-node-windows"}))
+
 		ds := rtest.GetResource(resources, "fluentd-node-windows", "tigera-fluentd", "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
 		Expect(ds.Spec.Template.Spec.Volumes[0].VolumeSource.HostPath.Path).To(Equal("c:/TigeraCalico"))
 
@@ -616,6 +626,225 @@ var _ = Describe("Tigera Secure Fluentd rendering tests", func() {
 		}
 	})
 
+	It("should render the CA bundle of the splunk store", func() {
+		cfg.LogCollector.Spec.AdditionalStores = &operatorv1.AdditionalLogStoreSpec{
+			Splunk: &operatorv1.SplunkStoreSpec{
+				Endpoint: "https://1.2.3.4:8088",
+				TLS: &operatorv1.AdditionalStoreTLS{
+					CASecret: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "splunk-ca"}, Key: "ca.crt"},
+				},
+			},
+		}
+		cfg.SplkCredential = &render.SplunkCredential{Token: []byte("TokenForHEC")}
+		cfg.SplunkCA = []byte("splunk-ca")
+		component := render.Fluentd(cfg)
+		resources, toDelete := component.Objects()
+
+		caSecret := rtest.GetResource(resources, render.SplunkFluentdCASecretName, render.LogCollectorNamespace, "", "v1", "Secret").(*corev1.Secret)
+		Expect(caSecret.Data).To(HaveKeyWithValue(render.AdditionalStoreCAKey, []byte("splunk-ca")))
+		Expect(toDelete).NotTo(ContainElement(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: render.SplunkFluentdCASecretName, Namespace: render.LogCollectorNamespace}}))
+
+		ds := rtest.GetResource(resources, "fluentd-node", "tigera-fluentd", "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Annotations).To(HaveKey("hash.operator.tigera.io/logcollector-splunk-ca"))
+		container := ds.Spec.Template.Spec.Containers[0]
+		Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "SPLUNK_CA_FILE", Value: "/etc/fluentd/tls/splunk/ca.crt"}))
+		Expect(container.VolumeMounts).To(ContainElement(corev1.VolumeMount{
+			Name: render.SplunkFluentdCASecretName, MountPath: "/etc/fluentd/tls/splunk/", ReadOnly: true,
+		}))
+		Expect(ds.Spec.Template.Spec.Volumes).To(ContainElement(corev1.Volume{
+			Name: render.SplunkFluentdCASecretName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: render.SplunkFluentdCASecretName,
+					Items:      []corev1.KeyToPath{{Key: "ca.crt", Path: "ca.crt"}},
+				},
+			},
+		}))
+	})
+
+	It("should delete the copy of the CA bundle of the splunk store when it is unset", func() {
+		component := render.Fluentd(cfg)
+		resources, toDelete := component.Objects()
+		Expect(rtest.GetResource(resources, render.SplunkFluentdCASecretName, render.LogCollectorNamespace, "", "v1", "Secret")).To(BeNil())
+		Expect(toDelete).To(ContainElement(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: render.SplunkFluentdCASecretName, Namespace: render.LogCollectorNamespace}}))
+	})
+
+	It("should keep fluentd off the control plane nodes when they are excluded", func() {
+		cfg.LogCollector.Spec.ControlPlaneNodes = &operatorv1.FluentdControlPlaneNodes{Scheduling: operatorv1.ControlPlaneNodeSchedulingExclude}
+		component := render.Fluentd(cfg)
+		resources, toDelete := component.Objects()
+
+		ds := rtest.GetResource(resources, "fluentd-node", "tigera-fluentd", "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms).To(Equal([]corev1.NodeSelectorTerm{{
+			MatchExpressions: []corev1.NodeSelectorRequirement{
+				{Key: "node-role.kubernetes.io/control-plane", Operator: corev1.NodeSelectorOpDoesNotExist},
+				{Key: "node-role.kubernetes.io/master", Operator: corev1.NodeSelectorOpDoesNotExist},
+			},
+		}}))
+		Expect(rtest.GetResource(resources, render.FluentdControlPlaneName, "tigera-fluentd", "apps", "v1", "DaemonSet")).To(BeNil())
+		Expect(rtest.GetResource(toDelete, render.FluentdControlPlaneName, "tigera-fluentd", "apps", "v1", "DaemonSet")).NotTo(BeNil())
+	})
+
+	It("should run fluentd with other resources on the control plane nodes", func() {
+		resources := corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("100Mi")},
+		}
+		cfg.LogCollector.Spec.ControlPlaneNodes = &operatorv1.FluentdControlPlaneNodes{ResourceRequirements: &resources}
+		cfg.LogCollector.Spec.NodePools = []operatorv1.FluentdNodePool{{Name: "busy", NodeSelector: map[string]string{"busy": "true"}}}
+		component := render.Fluentd(cfg)
+		objs, _ := component.Objects()
+
+		ds := rtest.GetResource(objs, render.FluentdControlPlaneName, "tigera-fluentd", "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.Containers[0].Resources).To(Equal(resources))
+		// The control plane pods are scraped through the metrics service of the default DaemonSet.
+		svc := rtest.GetResource(objs, render.FluentdMetricsService, "tigera-fluentd", "", "v1", "Service").(*corev1.Service)
+		Expect(ds.Spec.Template.Labels).To(HaveKeyWithValue(render.FluentdLabel, "fluentd-node"))
+		Expect(svc.Spec.Selector).To(Equal(map[string]string{render.FluentdLabel: "fluentd-node"}))
+		Expect(ds.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms).To(Equal([]corev1.NodeSelectorTerm{
+			{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "node-role.kubernetes.io/control-plane", Operator: corev1.NodeSelectorOpExists}}},
+			{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "node-role.kubernetes.io/master", Operator: corev1.NodeSelectorOpExists}}},
+		}))
+
+		pool := rtest.GetResource(objs, "fluentd-node-pool-busy", "tigera-fluentd", "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(pool.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms).To(Equal([]corev1.NodeSelectorTerm{{
+			MatchExpressions: []corev1.NodeSelectorRequirement{
+				{Key: "busy", Operator: corev1.NodeSelectorOpIn, Values: []string{"true"}},
+				{Key: "node-role.kubernetes.io/control-plane", Operator: corev1.NodeSelectorOpDoesNotExist},
+				{Key: "node-role.kubernetes.io/master", Operator: corev1.NodeSelectorOpDoesNotExist},
+			},
+		}}))
+	})
+
+	It("should flush the buffers of fluentd before it is stopped", func() {
+		component := render.Fluentd(cfg)
+		resources, _ := component.Objects()
+
+		ds := rtest.GetResource(resources, "fluentd-node", "tigera-fluentd", "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(*ds.Spec.Template.Spec.TerminationGracePeriodSeconds).To(Equal(int64(30)))
+		Expect(ds.Spec.Template.Spec.Containers[0].Lifecycle.PreStop.Exec.Command).To(Equal([]string{"/bin/sh", "-c", "kill -USR1 1; sleep 10"}))
+
+		var gracePeriod int64 = 8
+		cfg.LogCollector.Spec.TerminationGracePeriodSeconds = &gracePeriod
+		component = render.Fluentd(cfg)
+		resources, _ = component.Objects()
+
+		ds = rtest.GetResource(resources, "fluentd-node", "tigera-fluentd", "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(*ds.Spec.Template.Spec.TerminationGracePeriodSeconds).To(Equal(int64(8)))
+		Expect(ds.Spec.Template.Spec.Containers[0].Lifecycle.PreStop.Exec.Command).To(Equal([]string{"/bin/sh", "-c", "kill -USR1 1; sleep 4"}))
+	})
+
+	It("should stop fluentd with the settings of its disruption policy", func() {
+		var gracePeriod, policyGracePeriod, preStopDelay int64 = 8, 120, 45
+		cfg.LogCollector.Spec.TerminationGracePeriodSeconds = &gracePeriod
+		cfg.LogCollector.Spec.ComponentDisruptionPolicies = []operatorv1.LogCollectorComponentDisruptionPolicy{{
+			ComponentName: operatorv1.ComponentNameFluentd,
+			DisruptionPolicy: operatorv1.DisruptionPolicy{
+				TerminationGracePeriodSeconds: &policyGracePeriod,
+				PreStopDelaySeconds:           &preStopDelay,
+			},
+		}}
+		component := render.Fluentd(cfg)
+		resources, _ := component.Objects()
+
+		ds := rtest.GetResource(resources, "fluentd-node", "tigera-fluentd", "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(*ds.Spec.Template.Spec.TerminationGracePeriodSeconds).To(Equal(int64(120)))
+		Expect(ds.Spec.Template.Spec.Containers[0].Lifecycle.PreStop.Exec.Command).To(Equal([]string{"/bin/sh", "-c", "kill -USR1 1; sleep 45"}))
+	})
+
+	It("should authenticate to es-gateway with the credentials of its user by default", func() {
+		component := render.Fluentd(cfg)
+		resources, toDelete := component.Objects()
+		Expect(toDelete).NotTo(ContainElement(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchLogCollectorUserSecret, Namespace: render.LogCollectorNamespace}}))
+
+		ds := rtest.GetResource(resources, "fluentd-node", "tigera-fluentd", "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "ELASTIC_ACCESS_MODE", Value: "serviceuser"}))
+		for _, vol := range ds.Spec.Template.Spec.Volumes {
+			Expect(vol.Name).NotTo(Equal("es-gateway-token"))
+		}
+	})
+
+	It("should authenticate to es-gateway with a projected service account token", func() {
+		cfg.LogCollector.Spec.ESGatewayTokenAuth = operatorv1.ESGatewayTokenAuthEnabled
+		component := render.Fluentd(cfg)
+		resources, toDelete := component.Objects()
+		// The credentials of its user are kept until the token authentication is confirmed to work.
+		Expect(toDelete).NotTo(ContainElement(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchLogCollectorUserSecret, Namespace: render.LogCollectorNamespace}}))
+
+		ds := rtest.GetResource(resources, "fluentd-node", "tigera-fluentd", "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		container := ds.Spec.Template.Spec.Containers[0]
+		Expect(container.Env).To(ContainElements(
+			corev1.EnvVar{Name: "ELASTIC_ACCESS_MODE", Value: "serviceaccounttoken"},
+			corev1.EnvVar{Name: "ELASTIC_TOKEN_PATH", Value: "/var/run/secrets/tigera/es-gateway/token"},
+		))
+		for _, env := range container.Env {
+			Expect(env.Name).NotTo(Equal("ELASTIC_PASSWORD"))
+		}
+		Expect(container.VolumeMounts).To(ContainElement(corev1.VolumeMount{Name: "es-gateway-token", MountPath: "/var/run/secrets/tigera/es-gateway", ReadOnly: true}))
+
+		var token *corev1.ServiceAccountTokenProjection
+		for _, vol := range ds.Spec.Template.Spec.Volumes {
+			if vol.Name == "es-gateway-token" {
+				token = vol.Projected.Sources[0].ServiceAccountToken
+			}
+		}
+		Expect(token).NotTo(BeNil())
+		Expect(token.Audience).To(Equal(render.ESGatewayTokenAudience))
+		Expect(*token.ExpirationSeconds).To(Equal(int64(3600)))
+	})
+
+	It("should authenticate to the management cluster with the credentials of its user in a managed cluster", func() {
+		cfg.LogCollector.Spec.ESGatewayTokenAuth = operatorv1.ESGatewayTokenAuthEnabled
+		cfg.ManagedCluster = true
+		component := render.Fluentd(cfg)
+		resources, _ := component.Objects()
+
+		ds := rtest.GetResource(resources, "fluentd-node", "tigera-fluentd", "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "ELASTIC_ACCESS_MODE", Value: "serviceuser"}))
+		for _, vol := range ds.Spec.Template.Spec.Volumes {
+			Expect(vol.Name).NotTo(Equal("es-gateway-token"))
+		}
+	})
+
+	It("should render the settings of the Elasticsearch output", func() {
+		bulkMessageSize := resource.MustParse("4Mi")
+		bulkRequestThreshold := resource.MustParse("10Mi")
+		reconnectOnError := true
+		cfg.LogCollector.Spec.ElasticsearchOutput = &operatorv1.FluentdElasticsearchOutput{
+			BulkMessageSize:      &bulkMessageSize,
+			BulkRequestThreshold: &bulkRequestThreshold,
+			RequestTimeout:       &metav1.Duration{Duration: 90 * time.Second},
+			ReconnectOnError:     &reconnectOnError,
+		}
+		component := render.Fluentd(cfg)
+		resources, _ := component.Objects()
+
+		ds := rtest.GetResource(resources, "fluentd-node", "tigera-fluentd", "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		envs := ds.Spec.Template.Spec.Containers[0].Env
+		Expect(envs).To(ContainElements(
+			corev1.EnvVar{Name: "ELASTIC_CHUNK_LIMIT_SIZE", Value: "4194304"},
+			corev1.EnvVar{Name: "ELASTIC_BULK_MESSAGE_REQUEST_THRESHOLD", Value: "10485760"},
+			corev1.EnvVar{Name: "ELASTIC_REQUEST_TIMEOUT", Value: "90s"},
+			corev1.EnvVar{Name: "ELASTIC_RECONNECT_ON_ERROR", Value: "true"},
+		))
+	})
+
+	It("should serve the metrics of Windows nodes with TLS", func() {
+		cfg.OSType = rmeta.OSTypeWindows
+		component := render.Fluentd(cfg)
+		resources, _ := component.Objects()
+
+		ds := rtest.GetResource(resources, "fluentd-node-windows", "tigera-fluentd", "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		container := ds.Spec.Template.Spec.Containers[0]
+		Expect(container.Env).To(ContainElements(
+			corev1.EnvVar{Name: "TLS_KEY_PATH", Value: "c:/tigera-fluentd-prometheus-tls/tls.key"},
+			corev1.EnvVar{Name: "TLS_CRT_PATH", Value: "c:/tigera-fluentd-prometheus-tls/tls.crt"},
+		))
+		Expect(container.VolumeMounts).To(ContainElement(corev1.VolumeMount{
+			Name: render.FluentdPrometheusTLSSecretName, MountPath: "c:/tigera-fluentd-prometheus-tls", ReadOnly: true,
+		}))
+		Expect(ds.Spec.Template.Spec.InitContainers).To(BeEmpty())
+	})
+
 	It("should render with filter", func() {
 		cfg.Filters = &render.FluentdFilters{
 			Flow: "flow-filter",
@@ -660,6 +889,95 @@ var _ = Describe("Tigera Secure Fluentd rendering tests", func() {
 		Expect(envs).ToNot(ContainElement(corev1.EnvVar{Name: "FLUENTD_DNS_FILTERS", Value: "true"}))
 	})
 
+	It("should append the redaction rules to the filters", func() {
+		filters := render.WithRedactionRules(&render.FluentdFilters{Flow: "flow-filter"}, []operatorv1.LogRedactionRule{
+			{LogType: operatorv1.LogRedactionLogTypeFlows, Field: "source_ip", Action: operatorv1.LogRedactionHash},
+			{LogType: operatorv1.LogRedactionLogTypeFlows, Field: "dest_ip", Action: operatorv1.LogRedactionHash},
+			{LogType: operatorv1.LogRedactionLogTypeDNS, Field: "qname", Action: operatorv1.LogRedactionDrop},
+		})
+		Expect(filters.Flow).To(Equal(`flow-filter
+<filter flows>
+  @type record_transformer
+  enable_ruby true
+  <record>
+    source_ip ${(require 'openssl'; record['source_ip'].nil? ? nil : OpenSSL::HMAC.hexdigest('SHA256', ENV['FLUENTD_REDACTION_KEY'], record['source_ip'].to_s))}
+    dest_ip ${(require 'openssl'; record['dest_ip'].nil? ? nil : OpenSSL::HMAC.hexdigest('SHA256', ENV['FLUENTD_REDACTION_KEY'], record['dest_ip'].to_s))}
+  </record>
+</filter>
+`))
+		Expect(filters.DNS).To(Equal(`
+<filter dns>
+  @type record_transformer
+  remove_keys qname
+</filter>
+`))
+
+		cfg.Filters = filters
+		cfg.RedactionKeySecret = render.CreateFluentdRedactionKeySecret()
+		component := render.Fluentd(cfg)
+		resources, _ := component.Objects()
+		ds := rtest.GetResource(resources, "fluentd-node", "tigera-fluentd", "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		envs := ds.Spec.Template.Spec.Containers[0].Env
+		Expect(envs).To(ContainElement(corev1.EnvVar{Name: "FLUENTD_FLOW_FILTERS", Value: "true"}))
+		Expect(envs).To(ContainElement(corev1.EnvVar{Name: "FLUENTD_DNS_FILTERS", Value: "true"}))
+
+		// The fields are hashed with the key of the cluster.
+		Expect(envs).To(ContainElement(corev1.EnvVar{
+			Name: "FLUENTD_REDACTION_KEY",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: render.FluentdRedactionKeySecret},
+					Key:                  render.FluentdRedactionKeyName,
+				},
+			},
+		}))
+		rtest.ExpectResourceInList(resources, render.FluentdRedactionKeySecret, common.OperatorNamespace(), "", "v1", "Secret")
+		rtest.ExpectResourceInList(resources, render.FluentdRedactionKeySecret, render.LogCollectorNamespace, "", "v1", "Secret")
+	})
+
+	It("should delete the copy of the redaction key when no field is hashed", func() {
+		Expect(render.RedactionKeyRequired([]operatorv1.LogRedactionRule{
+			{LogType: operatorv1.LogRedactionLogTypeDNS, Field: "qname", Action: operatorv1.LogRedactionDrop},
+		})).To(BeFalse())
+
+		component := render.Fluentd(cfg)
+		_, toDelete := component.Objects()
+		Expect(toDelete).To(ContainElement(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: render.FluentdRedactionKeySecret, Namespace: render.LogCollectorNamespace}}))
+	})
+
+	It("should not change the filters without redaction rules", func() {
+		Expect(render.WithRedactionRules(nil, nil)).To(BeNil())
+	})
+
+	It("should only forward the audit logs in the AuditOnly mode", func() {
+		cfg.LogCollector.Spec.Mode = operatorv1.LogCollectorModeAuditOnly
+		cfg.Filters = &render.FluentdFilters{Flow: "flow-filter", DNS: "dns-filter"}
+		cfg.LogCollector.Spec.AdditionalStores = &operatorv1.AdditionalLogStoreSpec{
+			Syslog: &operatorv1.SyslogStoreSpec{
+				Endpoint: "tcp://1.2.3.4:80",
+				LogTypes: []operatorv1.SyslogLogType{operatorv1.SyslogLogFlows, operatorv1.SyslogLogDNS},
+			},
+		}
+		component := render.Fluentd(cfg)
+		resources, _ := component.Objects()
+
+		ds := rtest.GetResource(resources, "fluentd-node", "tigera-fluentd", "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		container := ds.Spec.Template.Spec.Containers[0]
+		Expect(container.Env).To(ContainElements(
+			corev1.EnvVar{Name: "FLOW_LOG_FILE", Value: "/var/log/calico/flowlogs/flows.log"},
+			corev1.EnvVar{Name: "DNS_LOG_FILE", Value: "/var/log/calico/dnslogs/dns.log"},
+			corev1.EnvVar{Name: "SYSLOG_AUDIT_EE_LOG", Value: "true"},
+			corev1.EnvVar{Name: "SYSLOG_AUDIT_KUBE_LOG", Value: "true"},
+		))
+		for _, name := range []string{"DISABLE_FLOW_LOGS", "DISABLE_DNS_LOGS", "FLUENTD_FLOW_FILTERS", "FLUENTD_DNS_FILTERS", "SYSLOG_FLOW_LOG", "SYSLOG_DNS_LOG"} {
+			for _, env := range container.Env {
+				Expect(env.Name).NotTo(Equal(name))
+			}
+		}
+		Expect(container.Resources.Requests.Memory().String()).To(Equal("128Mi"))
+		Expect(container.Resources.Limits.Memory().String()).To(Equal("256Mi"))
+	})
+
 	It("should render with EKS Cloudwatch Log", func() {
 		expectedResources := []struct {
 			name    string
@@ -676,6 +994,7 @@ var _ = Describe("Tigera Secure Fluentd rendering tests", func() {
 			{name: "eks-log-forwarder", ns: "", group: "policy", version: "v1beta1", kind: "PodSecurityPolicy"},
 			{name: "eks-log-forwarder", ns: "tigera-fluentd", group: "", version: "v1", kind: "ServiceAccount"},
 			{name: "tigera-eks-log-forwarder-secret", ns: "tigera-fluentd", group: "", version: "v1", kind: "Secret"},
+			{name: "eks-log-forwarder", ns: "tigera-fluentd", group: "policy", version: "v1", kind: "PodDisruptionBudget"},
 			{name: "eks-log-forwarder", ns: "tigera-fluentd", group: "apps", version: "v1", kind: "Deployment"},
 			{name: "tigera-fluentd", ns: "", group: "rbac.authorization.k8s.io", version: "v1", kind: "ClusterRole"},
 			{name: "tigera-fluentd", ns: "", group: "rbac.authorization.k8s.io", version: "v1", kind: "ClusterRoleBinding"},
@@ -715,6 +1034,10 @@ var _ = Describe("Tigera Secure Fluentd rendering tests", func() {
 			i++
 		}
 
+		pdb := rtest.GetResource(resources, "eks-log-forwarder", "tigera-fluentd", "policy", "v1", "PodDisruptionBudget").(*policyv1.PodDisruptionBudget)
+		Expect(pdb.Spec.Selector.MatchLabels).To(Equal(map[string]string{"k8s-app": "eks-log-forwarder"}))
+		Expect(pdb.Spec.MaxUnavailable.IntValue()).To(Equal(1))
+
 		deploy := rtest.GetResource(resources, "eks-log-forwarder", "tigera-fluentd", "apps", "v1", "Deployment").(*appsv1.Deployment)
 		Expect(deploy.Spec.Template.Spec.InitContainers).To(HaveLen(1))
 		Expect(deploy.Spec.Template.Spec.Containers).To(HaveLen(1))
@@ -729,6 +1052,386 @@ var _ = Describe("Tigera Secure Fluentd rendering tests", func() {
 		Expect(envs).To(ContainElement(corev1.EnvVar{Name: "EKS_CLOUDWATCH_LOG_FETCH_INTERVAL", Value: fetchIntervalVal}))
 	})
 
+	It("should render the resource requirements of the provider preset and the LogCollector overrides", func() {
+		component := render.Fluentd(cfg)
+		resources, _ := component.Objects()
+		ds := rtest.GetResource(resources, "fluentd-node", "tigera-fluentd", "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.Containers[0].Resources).To(Equal(corev1.ResourceRequirements{}))
+
+		// The presets of the provider are only used when they are enabled.
+		cfg.Installation.KubernetesProvider = operatorv1.ProviderEKS
+		component = render.Fluentd(cfg)
+		resources, _ = component.Objects()
+		ds = rtest.GetResource(resources, "fluentd-node", "tigera-fluentd", "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.Containers[0].Resources).To(Equal(corev1.ResourceRequirements{}))
+
+		presets := operatorv1.ProviderResourcePresetsEnabled
+		cfg.LogCollector.Spec.ProviderResourcePresets = &presets
+		component = render.Fluentd(cfg)
+		resources, _ = component.Objects()
+		ds = rtest.GetResource(resources, "fluentd-node", "tigera-fluentd", "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.Containers[0].Resources.Requests.Memory().String()).To(Equal("512Mi"))
+
+		cfg.LogCollector.Spec.ComponentResources = []operatorv1.LogCollectorComponentResource{
+			{
+				ComponentName: operatorv1.ComponentNameFluentd,
+				ResourceRequirements: &corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi")},
+				},
+			},
+		}
+		component = render.Fluentd(cfg)
+		resources, _ = component.Objects()
+		ds = rtest.GetResource(resources, "fluentd-node", "tigera-fluentd", "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		res := ds.Spec.Template.Spec.Containers[0].Resources
+		Expect(res.Requests.Memory().String()).To(Equal("2Gi"))
+		Expect(res.Requests.Cpu().String()).To(Equal("200m"))
+		// The limit is raised to the request.
+		Expect(res.Limits.Memory().String()).To(Equal("2Gi"))
+	})
+
+	It("should resize the pods in place when only the resources change", func() {
+		getDaemonSet := func() *appsv1.DaemonSet {
+			component := render.Fluentd(cfg)
+			resources, _ := component.Objects()
+			return rtest.GetResource(resources, "fluentd-node", "tigera-fluentd", "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		}
+		cfg.InPlaceResize = true
+		cfg.CurrentDaemonSet = getDaemonSet()
+		Expect(cfg.CurrentDaemonSet.Spec.UpdateStrategy.Type).NotTo(Equal(appsv1.OnDeleteDaemonSetStrategyType))
+
+		// Only the resources change.
+		cfg.Installation.KubernetesProvider = operatorv1.ProviderEKS
+		presets := operatorv1.ProviderResourcePresetsEnabled
+		cfg.LogCollector.Spec.ProviderResourcePresets = &presets
+		ds := getDaemonSet()
+		Expect(ds.Annotations[render.FluentdTemplateHashAnnotation]).To(Equal(cfg.CurrentDaemonSet.Annotations[render.FluentdTemplateHashAnnotation]))
+		Expect(ds.Annotations[render.FluentdResourcesHashAnnotation]).NotTo(Equal(cfg.CurrentDaemonSet.Annotations[render.FluentdResourcesHashAnnotation]))
+		Expect(ds.Spec.UpdateStrategy.Type).To(Equal(appsv1.OnDeleteDaemonSetStrategyType))
+
+		// The pods are still being resized.
+		cfg.CurrentDaemonSet = ds
+		Expect(getDaemonSet().Spec.UpdateStrategy.Type).To(Equal(appsv1.OnDeleteDaemonSetStrategyType))
+
+		// The pods are restarted for other changes.
+		cfg.Filters = &render.FluentdFilters{Flow: "flow-filter"}
+		Expect(getDaemonSet().Spec.UpdateStrategy.Type).NotTo(Equal(appsv1.OnDeleteDaemonSetStrategyType))
+
+		// The pods are restarted when in-place resizing isn't supported.
+		cfg.Filters = nil
+		cfg.InPlaceResize = false
+		Expect(getDaemonSet().Spec.UpdateStrategy.Type).NotTo(Equal(appsv1.OnDeleteDaemonSetStrategyType))
+	})
+
+	It("should keep the hash annotations of the current DaemonSet while rollouts are deferred", func() {
+		getDaemonSet := func() *appsv1.DaemonSet {
+			component := render.Fluentd(cfg)
+			resources, _ := component.Objects()
+			return rtest.GetResource(resources, "fluentd-node", "tigera-fluentd", "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		}
+		cfg.Filters = &render.FluentdFilters{Flow: "flow-filter"}
+		cfg.CurrentDaemonSet = getDaemonSet()
+		current := cfg.CurrentDaemonSet.Spec.Template.Annotations
+
+		cfg.Filters = &render.FluentdFilters{Flow: "new-flow-filter"}
+		Expect(getDaemonSet().Spec.Template.Annotations).NotTo(Equal(current))
+
+		cfg.DeferRollouts = true
+		Expect(getDaemonSet().Spec.Template.Annotations).To(Equal(current))
+	})
+
+	It("should render the VerticalPodAutoscalers of fluentd and the EKS log forwarder", func() {
+		maxMemory := resource.MustParse("2Gi")
+		cfg.LogCollector.Spec.VerticalPodAutoscaling = &operatorv1.LogCollectorVerticalPodAutoscaling{
+			Mode:             operatorv1.VerticalPodAutoscalingAuto,
+			MaxAllowedMemory: &maxMemory,
+		}
+		cfg.EKSConfig = &render.EksCloudwatchLogConfig{AwsId: []byte("aws-id"), AwsKey: []byte("aws-key")}
+		component := render.Fluentd(cfg)
+		resources, _ := component.Objects()
+
+		vpa := rtest.GetResource(resources, "fluentd-node", "tigera-fluentd", "autoscaling.k8s.io", "v1", "VerticalPodAutoscaler").(*vpav1.VerticalPodAutoscaler)
+		Expect(vpa.Spec.TargetRef.Kind).To(Equal("DaemonSet"))
+		Expect(vpa.Spec.TargetRef.Name).To(Equal("fluentd-node"))
+		Expect(*vpa.Spec.UpdatePolicy.UpdateMode).To(Equal(vpav1.UpdateModeAuto))
+		Expect(vpa.Spec.ResourcePolicy.ContainerPolicies).To(HaveLen(1))
+		policy := vpa.Spec.ResourcePolicy.ContainerPolicies[0]
+		Expect(*policy.ControlledResources).To(ConsistOf(corev1.ResourceMemory))
+		Expect(*policy.ControlledValues).To(Equal(vpav1.ContainerControlledValuesRequestsOnly))
+		Expect(policy.MaxAllowed.Memory().String()).To(Equal("2Gi"))
+
+		vpa = rtest.GetResource(resources, "eks-log-forwarder", "tigera-fluentd", "autoscaling.k8s.io", "v1", "VerticalPodAutoscaler").(*vpav1.VerticalPodAutoscaler)
+		Expect(vpa.Spec.TargetRef.Kind).To(Equal("Deployment"))
+	})
+
+	It("should only delete the VerticalPodAutoscalers when the API is installed", func() {
+		component := render.Fluentd(cfg)
+		_, toDelete := component.Objects()
+		Expect(rtest.GetResource(toDelete, "fluentd-node", "tigera-fluentd", "autoscaling.k8s.io", "v1", "VerticalPodAutoscaler")).To(BeNil())
+
+		cfg.VerticalPodAutoscalerAPI = true
+		component = render.Fluentd(cfg)
+		_, toDelete = component.Objects()
+		rtest.ExpectResourceInList(toDelete, "fluentd-node", "tigera-fluentd", "autoscaling.k8s.io", "v1", "VerticalPodAutoscaler")
+		rtest.ExpectResourceInList(toDelete, "eks-log-forwarder", "tigera-fluentd", "autoscaling.k8s.io", "v1", "VerticalPodAutoscaler")
+	})
+
+	It("should render a DaemonSet with the environment of each node pool", func() {
+		cfg.LogCollector.Spec.NodePools = []operatorv1.FluentdNodePool{
+			{
+				Name:         "gpu",
+				NodeSelector: map[string]string{"pool": "gpu"},
+				Env:          []operatorv1.FluentdEnvVar{{Name: "ELASTIC_FLUSH_INTERVAL", Value: "30s"}},
+			},
+			{
+				Name:         "ingress",
+				NodeSelector: map[string]string{"role": "ingress", "zone": "a"},
+			},
+		}
+		cfg.CurrentNodePools = []string{"gpu", "removed"}
+		component := render.Fluentd(cfg)
+		resources, toDelete := component.Objects()
+
+		// The nodes of the pools are excluded from the default DaemonSet.
+		ds := rtest.GetResource(resources, "fluentd-node", "tigera-fluentd", "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms).To(ConsistOf(
+			corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{
+				{Key: "pool", Operator: corev1.NodeSelectorOpNotIn, Values: []string{"gpu"}},
+				{Key: "role", Operator: corev1.NodeSelectorOpNotIn, Values: []string{"ingress"}},
+			}},
+			corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{
+				{Key: "pool", Operator: corev1.NodeSelectorOpNotIn, Values: []string{"gpu"}},
+				{Key: "zone", Operator: corev1.NodeSelectorOpNotIn, Values: []string{"a"}},
+			}},
+		))
+		Expect(ds.Spec.Template.Spec.Containers[0].Env).NotTo(ContainElement(corev1.EnvVar{Name: "ELASTIC_FLUSH_INTERVAL", Value: "30s"}))
+
+		ds = rtest.GetResource(resources, "fluentd-node-pool-gpu", "tigera-fluentd", "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Labels).To(HaveKeyWithValue(render.FluentdNodePoolLabel, "gpu"))
+		// The pods of the pools are scraped through the metrics service of the default DaemonSet.
+		Expect(ds.Spec.Template.Labels).To(HaveKeyWithValue(render.FluentdLabel, "fluentd-node"))
+		svc := rtest.GetResource(resources, render.FluentdMetricsService, "tigera-fluentd", "", "v1", "Service").(*corev1.Service)
+		Expect(svc.Spec.Selector).To(Equal(map[string]string{render.FluentdLabel: "fluentd-node"}))
+		Expect(ds.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms).To(ConsistOf(
+			corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{
+				{Key: "pool", Operator: corev1.NodeSelectorOpIn, Values: []string{"gpu"}},
+			}},
+		))
+		Expect(ds.Spec.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "ELASTIC_FLUSH_INTERVAL", Value: "30s"}))
+
+		// A node that matches both pools belongs to the first one.
+		ds = rtest.GetResource(resources, "fluentd-node-pool-ingress", "tigera-fluentd", "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms).To(ConsistOf(
+			corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{
+				{Key: "role", Operator: corev1.NodeSelectorOpIn, Values: []string{"ingress"}},
+				{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"a"}},
+				{Key: "pool", Operator: corev1.NodeSelectorOpNotIn, Values: []string{"gpu"}},
+			}},
+		))
+
+		rtest.ExpectResourceInList(toDelete, "fluentd-node-pool-removed", "tigera-fluentd", "apps", "v1", "DaemonSet")
+		Expect(rtest.GetResource(toDelete, "fluentd-node-pool-gpu", "tigera-fluentd", "apps", "v1", "DaemonSet")).To(BeNil())
+	})
+
+	It("should render the DNS configuration of the LogCollector on the fluentd pods", func() {
+		ndots := "2"
+		dnsConfig := &corev1.PodDNSConfig{
+			Nameservers: []string{"169.254.20.10"},
+			Searches:    []string{"svc.example.internal"},
+			Options:     []corev1.PodDNSConfigOption{{Name: "ndots", Value: &ndots}},
+		}
+		cfg.LogCollector.Spec.DNSConfig = dnsConfig
+		cfg.LogCollector.Spec.NodePools = []operatorv1.FluentdNodePool{{Name: "busy", NodeSelector: map[string]string{"busy": "true"}}}
+		component := render.Fluentd(cfg)
+		resources, _ := component.Objects()
+
+		ds := rtest.GetResource(resources, "fluentd-node", "tigera-fluentd", "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.DNSConfig).To(Equal(dnsConfig))
+		ds = rtest.GetResource(resources, "fluentd-node-pool-busy", "tigera-fluentd", "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.DNSConfig).To(Equal(dnsConfig))
+	})
+
+	It("should run the candidate fluentd next to fluentd on the selected nodes until it is promoted", func() {
+		cfg.LogCollector.Spec.Candidate = &operatorv1.FluentdCandidate{
+			NodeSelector: map[string]string{"canary": "true"},
+			Image:        "example.com/fluentd:next",
+			Env:          []operatorv1.FluentdEnvVar{{Name: "ELASTIC_FLUSH_INTERVAL", Value: "10s"}},
+		}
+		component := render.Fluentd(cfg)
+		Expect(component.ResolveImages(nil)).To(BeNil())
+		resources, _ := component.Objects()
+
+		ds := rtest.GetResource(resources, "fluentd-node", "tigera-fluentd", "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.Affinity).To(BeNil())
+		Expect(ds.Spec.Template.Spec.Containers[0].Image).NotTo(Equal("example.com/fluentd:next"))
+
+		ds = rtest.GetResource(resources, render.FluentdCandidateName, "tigera-fluentd", "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Labels).To(HaveKeyWithValue(render.FluentdCandidateLabel, "true"))
+		Expect(ds.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms).To(ConsistOf(
+			corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{
+				{Key: "canary", Operator: corev1.NodeSelectorOpIn, Values: []string{"true"}},
+			}},
+		))
+		container := ds.Spec.Template.Spec.Containers[0]
+		Expect(container.Image).To(Equal("example.com/fluentd:next"))
+		Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "ELASTIC_FLUSH_INTERVAL", Value: "10s"}))
+		Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "ELASTIC_INDEX_SUFFIX", Value: "clusterTestName.candidate"}))
+
+		// The candidate keeps its position files apart from those of fluentd, and reads the logs through read-only mounts.
+		dirOrCreate := corev1.HostPathDirectoryOrCreate
+		Expect(ds.Spec.Template.Spec.Volumes).To(ContainElements(
+			corev1.Volume{
+				Name:         "var-log-calico",
+				VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/var/log/calico/fluentd-candidate", Type: &dirOrCreate}},
+			},
+			corev1.Volume{
+				Name:         "host-var-log-calico",
+				VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/var/log/calico"}},
+			},
+		))
+		Expect(container.VolumeMounts).To(ContainElements(
+			corev1.VolumeMount{Name: "var-log-calico", MountPath: "/var/log/calico"},
+			corev1.VolumeMount{Name: "host-var-log-calico", MountPath: "/var/log/calico/flowlogs", SubPath: "flowlogs", ReadOnly: true},
+			corev1.VolumeMount{Name: "host-var-log-calico", MountPath: "/var/log/calico/dnslogs", SubPath: "dnslogs", ReadOnly: true},
+		))
+
+		// Once promoted, fluentd runs the candidate on all nodes and the candidate is removed.
+		cfg.LogCollector.Spec.Candidate.Promote = true
+		component = render.Fluentd(cfg)
+		Expect(component.ResolveImages(nil)).To(BeNil())
+		resources, toDelete := component.Objects()
+
+		ds = rtest.GetResource(resources, "fluentd-node", "tigera-fluentd", "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.Containers[0].Image).To(Equal("example.com/fluentd:next"))
+		Expect(ds.Spec.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "ELASTIC_FLUSH_INTERVAL", Value: "10s"}))
+		Expect(ds.Spec.Template.Spec.Volumes).To(ContainElement(corev1.Volume{
+			Name:         "var-log-calico",
+			VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/var/log/calico", Type: &dirOrCreate}},
+		}))
+		Expect(rtest.GetResource(resources, render.FluentdCandidateName, "tigera-fluentd", "apps", "v1", "DaemonSet")).To(BeNil())
+		rtest.ExpectResourceInList(toDelete, render.FluentdCandidateName, "tigera-fluentd", "apps", "v1", "DaemonSet")
+	})
+
+	It("should render a log buffer for a managed cluster", func() {
+		storage := resource.MustParse("20Gi")
+		cfg.ManagedCluster = true
+		cfg.LogBuffer = &operatorv1.LogBuffer{
+			Retention:        &metav1.Duration{Duration: time.Hour},
+			StorageClassName: "buffer-storage",
+			Storage:          &storage,
+		}
+		component := render.Fluentd(cfg)
+		resources, toDelete := component.Objects()
+		Expect(toDelete).NotTo(ContainElement(&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: render.LogBufferName, Namespace: render.LogCollectorNamespace}}))
+		rtest.ExpectResourceInList(toDelete, render.FluentdControlPlaneName, render.LogCollectorNamespace, "apps", "v1", "DaemonSet")
+
+		rtest.ExpectResourceInList(resources, render.LogBufferName, render.LogCollectorNamespace, "", "v1", "ServiceAccount")
+		rtest.ExpectResourceInList(resources, render.LogBufferName, render.LogCollectorNamespace, "", "v1", "Service")
+		cm := rtest.GetResource(resources, render.LogBufferName, render.LogCollectorNamespace, "", "v1", "ConfigMap").(*corev1.ConfigMap)
+		Expect(cm.Data["fluent.conf"]).To(ContainSubstring("port 24224"))
+		Expect(cm.Data["fluent.conf"]).To(ContainSubstring("retry_timeout 3600s"))
+		sts := rtest.GetResource(resources, render.LogBufferName, render.LogCollectorNamespace, "apps", "v1", "StatefulSet").(*appsv1.StatefulSet)
+		Expect(sts.Spec.VolumeClaimTemplates).To(HaveLen(1))
+		pvc := sts.Spec.VolumeClaimTemplates[0]
+		Expect(*pvc.Spec.StorageClassName).To(Equal("buffer-storage"))
+		Expect(pvc.Spec.Resources.Requests[corev1.ResourceStorage]).To(Equal(storage))
+		container := sts.Spec.Template.Spec.Containers[0]
+		Expect(*container.SecurityContext.RunAsNonRoot).To(BeTrue())
+		for _, env := range container.Env {
+			Expect(env.Name).NotTo(HavePrefix("LOG_BUFFER"))
+		}
+		Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "ELASTIC_HOST", Value: "tigera-secure-es-gateway-http.tigera-elasticsearch.svc"}))
+
+		ds := rtest.GetResource(resources, "fluentd-node", "tigera-fluentd", "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		envs = ds.Spec.Template.Spec.Containers[0].Env
+		Expect(envs).To(ContainElement(corev1.EnvVar{Name: "LOG_BUFFER_HOST", Value: "tigera-log-buffer.tigera-fluentd.svc.cluster.local"}))
+		Expect(envs).To(ContainElement(corev1.EnvVar{Name: "LOG_BUFFER_PORT", Value: "24224"}))
+	})
+
+	It("should delete the log buffer when it is no longer configured", func() {
+		cfg.ManagedCluster = true
+		component := render.Fluentd(cfg)
+		resources, toDelete := component.Objects()
+		Expect(rtest.GetResource(resources, render.LogBufferName, render.LogCollectorNamespace, "apps", "v1", "StatefulSet")).To(BeNil())
+		Expect(toDelete).NotTo(ContainElement(&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: render.LogBufferName, Namespace: render.LogCollectorNamespace}}))
+
+		cfg.RemoveLogBuffer = true
+		component = render.Fluentd(cfg)
+		resources, toDelete = component.Objects()
+		Expect(toDelete).To(ContainElement(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: render.LogBufferName, Namespace: render.LogCollectorNamespace}}))
+		Expect(toDelete).To(ContainElement(&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: render.LogBufferName, Namespace: render.LogCollectorNamespace}}))
+
+		ds := rtest.GetResource(resources, "fluentd-node", "tigera-fluentd", "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		for _, env := range ds.Spec.Template.Spec.Containers[0].Env {
+			Expect(env.Name).NotTo(Equal("LOG_BUFFER_HOST"))
+		}
+	})
+
+	It("should not mount the log files of the containers unless they are configured", func() {
+		component := render.Fluentd(cfg)
+		resources, _ := component.Objects()
+
+		ds := rtest.GetResource(resources, "fluentd-node", "tigera-fluentd", "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		for _, mount := range ds.Spec.Template.Spec.Containers[0].VolumeMounts {
+			Expect(mount.Name).NotTo(HavePrefix("container-logs-"))
+		}
+	})
+
+	It("should mount the log files of the containers of the runtime of the nodes", func() {
+		docker := operatorv1.ContainerRuntimeDocker
+		cfg.ContainerLogs = &operatorv1.ContainerLogs{Runtime: &docker, BasePath: "/data/docker/containers"}
+		component := render.Fluentd(cfg)
+		resources, _ := component.Objects()
+
+		ds := rtest.GetResource(resources, "fluentd-node", "tigera-fluentd", "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.Containers[0].VolumeMounts).To(ContainElements(
+			corev1.VolumeMount{Name: "container-logs-0", MountPath: "/var/log/containers", ReadOnly: true},
+			corev1.VolumeMount{Name: "container-logs-1", MountPath: "/var/log/pods", ReadOnly: true},
+			corev1.VolumeMount{Name: "container-logs-2", MountPath: "/data/docker/containers", ReadOnly: true},
+		))
+		Expect(ds.Spec.Template.Spec.Volumes).To(ContainElement(corev1.Volume{
+			Name:         "container-logs-2",
+			VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/data/docker/containers"}},
+		}))
+
+		psp := rtest.GetResource(resources, "tigera-fluentd", "", "policy", "v1beta1", "PodSecurityPolicy").(*policyv1beta1.PodSecurityPolicy)
+		Expect(psp.Spec.AllowedHostPaths).To(ContainElement(policyv1beta1.AllowedHostPath{PathPrefix: "/data/docker/containers", ReadOnly: true}))
+
+		containerd := operatorv1.ContainerRuntimeContainerd
+		cfg.ContainerLogs = &operatorv1.ContainerLogs{Runtime: &containerd}
+		component = render.Fluentd(cfg)
+		resources, _ = component.Objects()
+
+		ds = rtest.GetResource(resources, "fluentd-node", "tigera-fluentd", "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.Containers[0].VolumeMounts).To(ContainElement(
+			corev1.VolumeMount{Name: "container-logs-1", MountPath: "/var/log/pods", ReadOnly: true},
+		))
+		Expect(ds.Spec.Template.Spec.Containers[0].VolumeMounts).NotTo(ContainElement(
+			corev1.VolumeMount{Name: "container-logs-2", MountPath: "/var/lib/docker/containers", ReadOnly: true},
+		))
+	})
+
+	It("should mount the log files of the containers of the runtime of each node pool", func() {
+		containerd := operatorv1.ContainerRuntimeContainerd
+		docker := operatorv1.ContainerRuntimeDocker
+		cfg.ContainerLogs = &operatorv1.ContainerLogs{Runtime: &containerd}
+		cfg.LogCollector.Spec.NodePools = []operatorv1.FluentdNodePool{
+			{Name: "legacy", NodeSelector: map[string]string{"runtime": "docker"}, ContainerRuntime: &docker},
+		}
+		component := render.Fluentd(cfg)
+		resources, _ := component.Objects()
+
+		dockerMount := corev1.VolumeMount{Name: "container-logs-2", MountPath: "/var/lib/docker/containers", ReadOnly: true}
+		ds := rtest.GetResource(resources, "fluentd-node", "tigera-fluentd", "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.Containers[0].VolumeMounts).NotTo(ContainElement(dockerMount))
+
+		ds = rtest.GetResource(resources, "fluentd-node-pool-legacy", "tigera-fluentd", "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.Containers[0].VolumeMounts).To(ContainElement(dockerMount))
+
+		psp := rtest.GetResource(resources, "tigera-fluentd", "", "policy", "v1beta1", "PodSecurityPolicy").(*policyv1beta1.PodSecurityPolicy)
+		Expect(psp.Spec.AllowedHostPaths).To(ContainElement(policyv1beta1.AllowedHostPath{PathPrefix: "/var/lib/docker/containers", ReadOnly: true}))
+	})
+
 	Context("allow-tigera rendering", func() {
 		policyName := types.NamespacedName{Name: "allow-tigera.allow-fluentd-node", Namespace: "tigera-fluentd"}
 
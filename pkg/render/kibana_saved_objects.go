@@ -0,0 +1,291 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/components"
+	"github.com/tigera/operator/pkg/ptr"
+	"github.com/tigera/operator/pkg/render/common/disruptionpolicy"
+	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/networkpolicy"
+	"github.com/tigera/operator/pkg/render/common/secret"
+	"github.com/tigera/operator/pkg/tls/certificatemanagement"
+)
+
+const (
+	KibanaSavedObjectsName       = "tigera-kibana-saved-objects"
+	KibanaSavedObjectsPolicyName = networkpolicy.TigeraComponentPolicyPrefix + "kibana-saved-objects"
+
+	// KibanaSavedObjectsUserSecret holds the credentials of the Elasticsearch user of the job, which can only manage
+	// Kibana.
+	KibanaSavedObjectsUserSecret = "tigera-kibana-saved-objects-user"
+	KibanaSavedObjectsUserName   = "tigera-kibana-saved-objects"
+	KibanaSavedObjectsRoleName   = "tigera_kibana_saved_objects"
+
+	// KibanaSavedObjectsHashAnnotation holds the hash of the saved objects and of the Elasticsearch and Kibana clusters
+	// that the job was run for, so that the job is recreated when the saved objects change or the clusters are
+	// recreated.
+	KibanaSavedObjectsHashAnnotation = "hash.operator.tigera.io/kibana-saved-objects"
+
+	// KibanaDefaultSpaceID is the ID of the space that Kibana creates.
+	KibanaDefaultSpaceID = "default"
+
+	kibanaSavedObjectsDir = "/etc/kibana-saved-objects/"
+)
+
+// kibanaSavedObjectsScript imports each file of saved objects into the Kibana space that prefixes its name, overwriting
+// the objects with the same IDs. The import API responds with success false if any of the objects couldn't be
+// imported. A failure is reported through the termination message of the container.
+const kibanaSavedObjectsScript = `
+fail() { echo "$1" > /dev/termination-log; echo "$1"; exit 1; }
+kb() { curl -sS --fail --cacert "$CA_CRT_PATH" -u "$ELASTIC_USERNAME:$ELASTIC_PASSWORD" -H 'kbn-xsrf: true' "$KIBANA_URL$@"; }
+
+for file in ` + kibanaSavedObjectsDir + `*.ndjson; do
+  name=$(basename "$file")
+  space=${name%%.*}
+  prefix="/s/$space"
+  if [ "$space" = "` + KibanaDefaultSpaceID + `" ]; then
+    prefix=""
+  fi
+  kb "$prefix/api/saved_objects/_import?overwrite=true" -X POST -F file=@"$file" -o /tmp/result.json ||
+    fail "Failed to import the saved objects of $name"
+  grep -q '"success": *true' /tmp/result.json || fail "Failed to import the saved objects of $name: $(cat /tmp/result.json)"
+done
+`
+
+// KibanaSavedObjects renders the job that imports the Kibana saved objects of the LogStorage.
+func KibanaSavedObjects(cfg *KibanaSavedObjectsConfiguration) Component {
+	return &kibanaSavedObjectsComponent{cfg: cfg}
+}
+
+// KibanaSavedObjectsConfiguration contains all the config information needed to render the component.
+type KibanaSavedObjectsConfiguration struct {
+	LogStorage    *operatorv1.LogStorage
+	Installation  *operatorv1.InstallationSpec
+	PullSecrets   []*corev1.Secret
+	TrustedBundle certificatemanagement.TrustedBundle
+	Provider      operatorv1.Provider
+
+	// ConfigMaps are the ConfigMaps of the saved objects of the LogStorage, by name.
+	ConfigMaps map[string]*corev1.ConfigMap
+
+	// UserSecret holds the credentials of the Elasticsearch user of the job, in the namespace of the operator.
+	UserSecret *corev1.Secret
+
+	// ElasticsearchUID and KibanaUID identify the clusters that the saved objects are imported into. The saved objects
+	// are stored in Elasticsearch, so they are imported again when either cluster is recreated.
+	ElasticsearchUID types.UID
+	KibanaUID        types.UID
+
+	// Enabled is whether the LogStorage has saved objects and Kibana is running. If not, the objects of the job are
+	// deleted.
+	Enabled bool
+}
+
+// KibanaSavedObjectsHash returns the hash of the saved objects and of the clusters that they are imported into, which
+// the job is annotated with.
+func KibanaSavedObjectsHash(cfg *KibanaSavedObjectsConfiguration) string {
+	return rmeta.AnnotationHash([]interface{}{kibanaSavedObjectsFiles(cfg), cfg.ElasticsearchUID, cfg.KibanaUID})
+}
+
+// kibanaSavedObjectsFiles returns the files of saved objects that the job imports, by name. The names are prefixed by
+// the ID of the space that the objects are imported into, and end with .ndjson, as the import API requires.
+func kibanaSavedObjectsFiles(cfg *KibanaSavedObjectsConfiguration) map[string]string {
+	files := map[string]string{}
+	if cfg.LogStorage == nil || cfg.LogStorage.Spec.Kibana == nil {
+		return files
+	}
+	for _, source := range cfg.LogStorage.Spec.Kibana.SavedObjects {
+		cm := cfg.ConfigMaps[source.ConfigMapName]
+		if cm == nil {
+			continue
+		}
+		space := source.SpaceID
+		if space == "" {
+			space = KibanaDefaultSpaceID
+		}
+		for key, objects := range cm.Data {
+			name := fmt.Sprintf("%s.%s.%s", space, cm.Name, key)
+			if !strings.HasSuffix(name, ".ndjson") {
+				name += ".ndjson"
+			}
+			files[name] = objects
+		}
+	}
+	return files
+}
+
+type kibanaSavedObjectsComponent struct {
+	cfg   *KibanaSavedObjectsConfiguration
+	image string
+}
+
+func (c *kibanaSavedObjectsComponent) ResolveImages(is *operatorv1.ImageSet) error {
+	reg := c.cfg.Installation.Registry
+	path := c.cfg.Installation.ImagePath
+	prefix := c.cfg.Installation.ImagePrefix
+	var err error
+	// The job only needs the shell and curl of the Elasticsearch image.
+	if operatorv1.IsFIPSModeEnabled(c.cfg.Installation.FIPSMode) {
+		c.image, err = components.GetReference(components.ComponentElasticsearchFIPS, reg, path, prefix, is)
+	} else {
+		c.image, err = components.GetReference(components.ComponentElasticsearch, reg, path, prefix, is)
+	}
+	return err
+}
+
+func (c *kibanaSavedObjectsComponent) Objects() ([]client.Object, []client.Object) {
+	objs := []client.Object{c.allowTigeraPolicy(), c.serviceAccount(), c.configMap(), c.job()}
+	if !c.cfg.Enabled {
+		objs = append(objs,
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: KibanaSavedObjectsUserSecret, Namespace: common.OperatorNamespace()}},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: KibanaSavedObjectsUserSecret, Namespace: ElasticsearchNamespace}},
+		)
+		return nil, objs
+	}
+	objs = append(objs, c.cfg.UserSecret)
+	objs = append(objs, secret.ToRuntimeObjects(secret.CopyToNamespace(ElasticsearchNamespace, c.cfg.UserSecret)...)...)
+	return objs, nil
+}
+
+func (c *kibanaSavedObjectsComponent) Ready() bool {
+	return true
+}
+
+func (c *kibanaSavedObjectsComponent) SupportedOSType() rmeta.OSType {
+	return rmeta.OSTypeLinux
+}
+
+// SupportedArchitectures returns the architectures of the Elasticsearch image that the Job runs.
+func (c *kibanaSavedObjectsComponent) SupportedArchitectures() []string {
+	return components.Architectures(components.ComponentElasticsearch)
+}
+
+func (c *kibanaSavedObjectsComponent) serviceAccount() *corev1.ServiceAccount {
+	return &corev1.ServiceAccount{
+		TypeMeta:   metav1.TypeMeta{Kind: "ServiceAccount", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: KibanaSavedObjectsName, Namespace: ElasticsearchNamespace},
+	}
+}
+
+// configMap holds the files of saved objects that the job imports.
+func (c *kibanaSavedObjectsComponent) configMap() *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: KibanaSavedObjectsName, Namespace: ElasticsearchNamespace},
+		Data:       kibanaSavedObjectsFiles(c.cfg),
+	}
+}
+
+func (c *kibanaSavedObjectsComponent) job() *batchv1.Job {
+	env := []corev1.EnvVar{
+		{Name: "ELASTIC_USERNAME", ValueFrom: secret.GetEnvVarSource(KibanaSavedObjectsUserSecret, "username", false)},
+		{Name: "ELASTIC_PASSWORD", ValueFrom: secret.GetEnvVarSource(KibanaSavedObjectsUserSecret, "password", false)},
+		{Name: "KIBANA_URL", Value: fmt.Sprintf("https://%s.%s.svc:%d/%s", KibanaServiceName, KibanaNamespace, KibanaPort, KibanaBasePath)},
+	}
+	volumes := []corev1.Volume{{
+		Name: KibanaSavedObjectsName,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: KibanaSavedObjectsName},
+			},
+		},
+	}}
+	volumeMounts := []corev1.VolumeMount{{Name: KibanaSavedObjectsName, MountPath: kibanaSavedObjectsDir, ReadOnly: true}}
+	if c.cfg.TrustedBundle != nil {
+		env = append(env, corev1.EnvVar{Name: "CA_CRT_PATH", Value: c.cfg.TrustedBundle.MountPath()})
+		volumes = append(volumes, c.cfg.TrustedBundle.Volume())
+		volumeMounts = append(volumeMounts, c.cfg.TrustedBundle.VolumeMount(c.SupportedOSType()))
+	}
+
+	job := &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{Kind: "Job", APIVersion: "batch/v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      KibanaSavedObjectsName,
+			Namespace: ElasticsearchNamespace,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: ptr.Int32ToPtr(3),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"k8s-app": KibanaSavedObjectsName,
+					},
+					Annotations: map[string]string{
+						KibanaSavedObjectsHashAnnotation: KibanaSavedObjectsHash(c.cfg),
+					},
+				},
+				Spec: corev1.PodSpec{
+					NodeSelector:       c.cfg.Installation.ControlPlaneNodeSelector,
+					Tolerations:        c.cfg.Installation.ControlPlaneTolerations,
+					ServiceAccountName: KibanaSavedObjectsName,
+					ImagePullSecrets:   secret.GetReferenceList(c.cfg.PullSecrets),
+					RestartPolicy:      corev1.RestartPolicyNever,
+					Containers: []corev1.Container{{
+						Name:                     KibanaSavedObjectsName,
+						Image:                    c.image,
+						Command:                  []string{"/bin/bash", "-c", kibanaSavedObjectsScript},
+						Env:                      env,
+						VolumeMounts:             volumeMounts,
+						TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+						SecurityContext: &corev1.SecurityContext{
+							AllowPrivilegeEscalation: ptr.BoolToPtr(false),
+						},
+					}},
+					Volumes: volumes,
+				},
+			},
+		},
+	}
+	disruptionpolicy.Apply(&job.Spec.Template.Spec, logStorageJobsDisruptionPolicy(c.cfg.LogStorage), true)
+	return job
+}
+
+// allowTigeraPolicy allows the job to reach Kibana.
+func (c *kibanaSavedObjectsComponent) allowTigeraPolicy() *v3.NetworkPolicy {
+	egressRules := networkpolicy.AppendDNSEgressRules([]v3.Rule{}, c.cfg.Provider == operatorv1.ProviderOpenShift)
+	egressRules = append(egressRules, v3.Rule{
+		Action:      v3.Allow,
+		Protocol:    &networkpolicy.TCPProtocol,
+		Destination: KibanaPortEntityRule(KibanaHTTPPort(logStoragePorts(c.cfg.LogStorage))),
+	})
+
+	return &v3.NetworkPolicy{
+		TypeMeta: metav1.TypeMeta{Kind: "NetworkPolicy", APIVersion: "projectcalico.org/v3"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      KibanaSavedObjectsPolicyName,
+			Namespace: ElasticsearchNamespace,
+		},
+		Spec: v3.NetworkPolicySpec{
+			Order:    &networkpolicy.HighPrecedenceOrder,
+			Tier:     networkpolicy.TigeraComponentTierName,
+			Selector: networkpolicy.KubernetesAppSelector(KibanaSavedObjectsName),
+			Types:    []v3.PolicyType{v3.PolicyTypeIngress, v3.PolicyTypeEgress},
+			Egress:   egressRules,
+		},
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/render"
+	rtest "github.com/tigera/operator/pkg/render/common/test"
+)
+
+var _ = Describe("Kibana saved objects rendering tests", func() {
+	var cfg *render.KibanaSavedObjectsConfiguration
+
+	BeforeEach(func() {
+		cfg = &render.KibanaSavedObjectsConfiguration{
+			LogStorage: &operatorv1.LogStorage{
+				Spec: operatorv1.LogStorageSpec{
+					Kibana: &operatorv1.KibanaSpec{
+						SavedObjects: []operatorv1.KibanaSavedObjectsSource{
+							{ConfigMapName: "dashboards"},
+							{ConfigMapName: "team-a-dashboards", SpaceID: "team-a"},
+						},
+					},
+				},
+			},
+			Installation: &operatorv1.InstallationSpec{},
+			ConfigMaps: map[string]*corev1.ConfigMap{
+				"dashboards": {
+					ObjectMeta: metav1.ObjectMeta{Name: "dashboards"},
+					Data:       map[string]string{"flows.ndjson": `{"type": "dashboard", "id": "flows"}`},
+				},
+				"team-a-dashboards": {
+					ObjectMeta: metav1.ObjectMeta{Name: "team-a-dashboards"},
+					Data:       map[string]string{"dns": `{"type": "dashboard", "id": "dns"}`},
+				},
+			},
+			UserSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: render.KibanaSavedObjectsUserSecret, Namespace: common.OperatorNamespace()},
+			},
+			ElasticsearchUID: "es-uid",
+			KibanaUID:        "kb-uid",
+			Enabled:          true,
+		}
+	})
+
+	It("should render the job with the saved objects of each space", func() {
+		component := render.KibanaSavedObjects(cfg)
+		Expect(component.ResolveImages(nil)).NotTo(HaveOccurred())
+
+		toCreate, toDelete := component.Objects()
+		Expect(toDelete).To(BeEmpty())
+		expectedResources := []struct {
+			name    string
+			ns      string
+			group   string
+			version string
+			kind    string
+		}{
+			{render.KibanaSavedObjectsPolicyName, render.ElasticsearchNamespace, "projectcalico.org", "v3", "NetworkPolicy"},
+			{render.KibanaSavedObjectsName, render.ElasticsearchNamespace, "", "v1", "ServiceAccount"},
+			{render.KibanaSavedObjectsName, render.ElasticsearchNamespace, "", "v1", "ConfigMap"},
+			{render.KibanaSavedObjectsName, render.ElasticsearchNamespace, "batch", "v1", "Job"},
+			{render.KibanaSavedObjectsUserSecret, common.OperatorNamespace(), "", "v1", "Secret"},
+			{render.KibanaSavedObjectsUserSecret, render.ElasticsearchNamespace, "", "v1", "Secret"},
+		}
+		Expect(toCreate).To(HaveLen(len(expectedResources)))
+		for i, expectedRes := range expectedResources {
+			rtest.ExpectResource(toCreate[i], expectedRes.name, expectedRes.ns, expectedRes.group, expectedRes.version, expectedRes.kind)
+		}
+
+		cm := rtest.GetResource(toCreate, render.KibanaSavedObjectsName, render.ElasticsearchNamespace, "", "v1", "ConfigMap").(*corev1.ConfigMap)
+		Expect(cm.Data).To(Equal(map[string]string{
+			"default.dashboards.flows.ndjson":     `{"type": "dashboard", "id": "flows"}`,
+			"team-a.team-a-dashboards.dns.ndjson": `{"type": "dashboard", "id": "dns"}`,
+		}))
+
+		job := rtest.GetResource(toCreate, render.KibanaSavedObjectsName, render.ElasticsearchNamespace, "batch", "v1", "Job").(*batchv1.Job)
+		Expect(job.Spec.Template.Annotations).To(HaveKeyWithValue(render.KibanaSavedObjectsHashAnnotation, render.KibanaSavedObjectsHash(cfg)))
+		Expect(job.Spec.Template.Spec.RestartPolicy).To(Equal(corev1.RestartPolicyNever))
+		Expect(job.Spec.Template.Spec.Containers[0].Env).To(ContainElements(
+			corev1.EnvVar{Name: "KIBANA_URL", Value: "https://tigera-secure-kb-http.tigera-kibana.svc:5601/tigera-kibana"},
+			corev1.EnvVar{Name: "ELASTIC_USERNAME", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: render.KibanaSavedObjectsUserSecret},
+				Key:                  "username",
+			}}},
+		))
+		Expect(job.Spec.Template.Spec.Containers[0].VolumeMounts).To(ContainElement(
+			corev1.VolumeMount{Name: render.KibanaSavedObjectsName, MountPath: "/etc/kibana-saved-objects/", ReadOnly: true},
+		))
+	})
+
+	It("should change the hash when Kibana is recreated", func() {
+		hash := render.KibanaSavedObjectsHash(cfg)
+		cfg.KibanaUID = "new-kb-uid"
+		Expect(render.KibanaSavedObjectsHash(cfg)).NotTo(Equal(hash))
+	})
+
+	It("should delete the job when there are no saved objects", func() {
+		cfg.Enabled = false
+		component := render.KibanaSavedObjects(cfg)
+		Expect(component.ResolveImages(nil)).NotTo(HaveOccurred())
+
+		toCreate, toDelete := component.Objects()
+		Expect(toCreate).To(BeEmpty())
+		Expect(toDelete).To(HaveLen(6))
+		Expect(toDelete).To(ContainElements(
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: render.KibanaSavedObjectsUserSecret, Namespace: common.OperatorNamespace()}},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: render.KibanaSavedObjectsUserSecret, Namespace: render.ElasticsearchNamespace}},
+		))
+	})
+})
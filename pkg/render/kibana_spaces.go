@@ -0,0 +1,277 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+
+	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/components"
+	"github.com/tigera/operator/pkg/ptr"
+	"github.com/tigera/operator/pkg/render/common/disruptionpolicy"
+	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/networkpolicy"
+	"github.com/tigera/operator/pkg/render/common/secret"
+	"github.com/tigera/operator/pkg/tls/certificatemanagement"
+)
+
+const (
+	KibanaSpacesName       = "tigera-kibana-spaces"
+	KibanaSpacesPolicyName = networkpolicy.TigeraComponentPolicyPrefix + "kibana-spaces"
+
+	// KibanaSpacesUserSecret holds the credentials of the Elasticsearch user of the job, which can only manage Kibana.
+	KibanaSpacesUserSecret = "tigera-kibana-spaces-user"
+	KibanaSpacesUserName   = "tigera-kibana-spaces"
+	KibanaSpacesRoleName   = "tigera_kibana_spaces"
+
+	// KibanaSpacesHashAnnotation holds the hash of the Kibana spaces that the job was run for, so that the job is
+	// recreated when the spaces change.
+	KibanaSpacesHashAnnotation = "hash.operator.tigera.io/kibana-spaces"
+
+	// ElasticsearchTenantRolePrefix is the prefix of the names of the Elasticsearch roles of the tenant roles of the
+	// LogStorage.
+	ElasticsearchTenantRolePrefix = "tigera_tenant_"
+	// KibanaSpaceRolePrefix is the prefix of the names of the Elasticsearch roles and role mappings that grant access
+	// to the Kibana spaces.
+	KibanaSpaceRolePrefix = "tigera_kibana_space_"
+
+	kibanaSpacesConfigDir = "/etc/kibana-spaces/"
+)
+
+// kibanaSpacesScript copies the default saved objects of Kibana, i.e. its dashboards and index patterns, into each
+// space, after creating or updating the space. The access to the spaces is granted to their OIDC groups by the
+// operator, through Elasticsearch role mappings. A failure is reported through the termination message of the
+// container.
+const kibanaSpacesScript = `
+fail() { echo "$1" > /dev/termination-log; echo "$1"; exit 1; }
+kb() { curl -sS --fail --cacert "$CA_CRT_PATH" -u "$ELASTIC_USERNAME:$ELASTIC_PASSWORD" -H 'kbn-xsrf: true' "$KIBANA_URL$@"; }
+
+kb /api/saved_objects/_export -X POST -H 'Content-Type: application/json' -o /tmp/objects.ndjson \
+  -d '{"type":["index-pattern","dashboard","visualization","search"],"includeReferencesDeep":true}' ||
+  fail "Failed to export the saved objects of the default Kibana space"
+
+for space in ` + kibanaSpacesConfigDir + `*.space.json; do
+  id=$(basename "$space" .space.json)
+  if kb "/api/spaces/space/$id" -o /dev/null; then
+    kb "/api/spaces/space/$id" -X PUT -H 'Content-Type: application/json' -d @"$space" -o /dev/null || fail "Failed to update Kibana space $id"
+  else
+    kb /api/spaces/space -X POST -H 'Content-Type: application/json' -d @"$space" -o /dev/null || fail "Failed to create Kibana space $id"
+  fi
+  kb "/s/$id/api/saved_objects/_import?overwrite=true" -X POST -F file=@/tmp/objects.ndjson -o /dev/null ||
+    fail "Failed to copy the saved objects to Kibana space $id"
+done
+`
+
+// KibanaSpaces renders the job that creates the Kibana spaces of the LogStorage and of its tenants.
+func KibanaSpaces(cfg *KibanaSpacesConfiguration) Component {
+	return &kibanaSpacesComponent{cfg: cfg}
+}
+
+// KibanaSpacesConfiguration contains all the config information needed to render the component.
+type KibanaSpacesConfiguration struct {
+	LogStorage    *operatorv1.LogStorage
+	Installation  *operatorv1.InstallationSpec
+	PullSecrets   []*corev1.Secret
+	TrustedBundle certificatemanagement.TrustedBundle
+	Provider      operatorv1.Provider
+
+	// UserSecret holds the credentials of the Elasticsearch user of the job, in the namespace of the operator.
+	UserSecret *corev1.Secret
+
+	// Enabled is whether the LogStorage has Kibana spaces and Kibana is running. If not, the objects of the job are
+	// deleted.
+	Enabled bool
+}
+
+type kibanaSpacesComponent struct {
+	cfg   *KibanaSpacesConfiguration
+	image string
+}
+
+func (c *kibanaSpacesComponent) ResolveImages(is *operatorv1.ImageSet) error {
+	reg := c.cfg.Installation.Registry
+	path := c.cfg.Installation.ImagePath
+	prefix := c.cfg.Installation.ImagePrefix
+	var err error
+	// The job only needs the shell and curl of the Elasticsearch image.
+	if operatorv1.IsFIPSModeEnabled(c.cfg.Installation.FIPSMode) {
+		c.image, err = components.GetReference(components.ComponentElasticsearchFIPS, reg, path, prefix, is)
+	} else {
+		c.image, err = components.GetReference(components.ComponentElasticsearch, reg, path, prefix, is)
+	}
+	return err
+}
+
+func (c *kibanaSpacesComponent) Objects() ([]client.Object, []client.Object) {
+	objs := []client.Object{c.allowTigeraPolicy(), c.serviceAccount(), c.configMap(), c.job()}
+	if !c.cfg.Enabled {
+		objs = append(objs,
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: KibanaSpacesUserSecret, Namespace: common.OperatorNamespace()}},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: KibanaSpacesUserSecret, Namespace: ElasticsearchNamespace}},
+		)
+		return nil, objs
+	}
+	objs = append(objs, c.cfg.UserSecret)
+	objs = append(objs, secret.ToRuntimeObjects(secret.CopyToNamespace(ElasticsearchNamespace, c.cfg.UserSecret)...)...)
+	return objs, nil
+}
+
+func (c *kibanaSpacesComponent) Ready() bool {
+	return true
+}
+
+func (c *kibanaSpacesComponent) SupportedOSType() rmeta.OSType {
+	return rmeta.OSTypeLinux
+}
+
+// SupportedArchitectures returns the architectures of the Elasticsearch image that the Job runs.
+func (c *kibanaSpacesComponent) SupportedArchitectures() []string {
+	return components.Architectures(components.ComponentElasticsearch)
+}
+
+func (c *kibanaSpacesComponent) spaces() []operatorv1.KibanaSpace {
+	return LogStorageKibanaSpaces(c.cfg.LogStorage)
+}
+
+func (c *kibanaSpacesComponent) serviceAccount() *corev1.ServiceAccount {
+	return &corev1.ServiceAccount{
+		TypeMeta:   metav1.TypeMeta{Kind: "ServiceAccount", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: KibanaSpacesName, Namespace: ElasticsearchNamespace},
+	}
+}
+
+// configMap holds the request bodies of the Kibana API for each space.
+func (c *kibanaSpacesComponent) configMap() *corev1.ConfigMap {
+	data := map[string]string{}
+	for _, space := range c.spaces() {
+		data[space.ID+".space.json"] = kibanaSpaceBody(space)
+	}
+	return &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: KibanaSpacesName, Namespace: ElasticsearchNamespace},
+		Data:       data,
+	}
+}
+
+func kibanaSpaceBody(space operatorv1.KibanaSpace) string {
+	name := space.Name
+	if name == "" {
+		name = space.ID
+	}
+	body, _ := json.Marshal(map[string]interface{}{
+		"id":   space.ID,
+		"name": name,
+	})
+	return string(body)
+}
+
+func (c *kibanaSpacesComponent) job() *batchv1.Job {
+	env := []corev1.EnvVar{
+		{Name: "ELASTIC_USERNAME", ValueFrom: secret.GetEnvVarSource(KibanaSpacesUserSecret, "username", false)},
+		{Name: "ELASTIC_PASSWORD", ValueFrom: secret.GetEnvVarSource(KibanaSpacesUserSecret, "password", false)},
+		{Name: "KIBANA_URL", Value: fmt.Sprintf("https://%s.%s.svc:%d/%s", KibanaServiceName, KibanaNamespace, KibanaPort, KibanaBasePath)},
+	}
+	volumes := []corev1.Volume{{
+		Name: KibanaSpacesName,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: KibanaSpacesName},
+			},
+		},
+	}}
+	volumeMounts := []corev1.VolumeMount{{Name: KibanaSpacesName, MountPath: kibanaSpacesConfigDir, ReadOnly: true}}
+	if c.cfg.TrustedBundle != nil {
+		env = append(env, corev1.EnvVar{Name: "CA_CRT_PATH", Value: c.cfg.TrustedBundle.MountPath()})
+		volumes = append(volumes, c.cfg.TrustedBundle.Volume())
+		volumeMounts = append(volumeMounts, c.cfg.TrustedBundle.VolumeMount(c.SupportedOSType()))
+	}
+
+	job := &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{Kind: "Job", APIVersion: "batch/v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      KibanaSpacesName,
+			Namespace: ElasticsearchNamespace,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: ptr.Int32ToPtr(3),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"k8s-app": KibanaSpacesName,
+					},
+					Annotations: map[string]string{
+						KibanaSpacesHashAnnotation: rmeta.AnnotationHash(c.spaces()),
+					},
+				},
+				Spec: corev1.PodSpec{
+					NodeSelector:       c.cfg.Installation.ControlPlaneNodeSelector,
+					Tolerations:        c.cfg.Installation.ControlPlaneTolerations,
+					ServiceAccountName: KibanaSpacesName,
+					ImagePullSecrets:   secret.GetReferenceList(c.cfg.PullSecrets),
+					RestartPolicy:      corev1.RestartPolicyNever,
+					Containers: []corev1.Container{{
+						Name:                     KibanaSpacesName,
+						Image:                    c.image,
+						Command:                  []string{"/bin/bash", "-c", kibanaSpacesScript},
+						Env:                      env,
+						VolumeMounts:             volumeMounts,
+						TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+						SecurityContext: &corev1.SecurityContext{
+							AllowPrivilegeEscalation: ptr.BoolToPtr(false),
+						},
+					}},
+					Volumes: volumes,
+				},
+			},
+		},
+	}
+	disruptionpolicy.Apply(&job.Spec.Template.Spec, logStorageJobsDisruptionPolicy(c.cfg.LogStorage), true)
+	return job
+}
+
+// allowTigeraPolicy allows the job to reach Kibana.
+func (c *kibanaSpacesComponent) allowTigeraPolicy() *v3.NetworkPolicy {
+	egressRules := networkpolicy.AppendDNSEgressRules([]v3.Rule{}, c.cfg.Provider == operatorv1.ProviderOpenShift)
+	egressRules = append(egressRules,
+		v3.Rule{
+			Action:      v3.Allow,
+			Protocol:    &networkpolicy.TCPProtocol,
+			Destination: KibanaPortEntityRule(KibanaHTTPPort(logStoragePorts(c.cfg.LogStorage))),
+		},
+	)
+
+	return &v3.NetworkPolicy{
+		TypeMeta: metav1.TypeMeta{Kind: "NetworkPolicy", APIVersion: "projectcalico.org/v3"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      KibanaSpacesPolicyName,
+			Namespace: ElasticsearchNamespace,
+		},
+		Spec: v3.NetworkPolicySpec{
+			Order:    &networkpolicy.HighPrecedenceOrder,
+			Tier:     networkpolicy.TigeraComponentTierName,
+			Selector: networkpolicy.KubernetesAppSelector(KibanaSpacesName),
+			Types:    []v3.PolicyType{v3.PolicyTypeIngress, v3.PolicyTypeEgress},
+			Egress:   egressRules,
+		},
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/render"
+	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	rtest "github.com/tigera/operator/pkg/render/common/test"
+)
+
+var _ = Describe("Kibana spaces rendering tests", func() {
+	var cfg *render.KibanaSpacesConfiguration
+
+	BeforeEach(func() {
+		cfg = &render.KibanaSpacesConfiguration{
+			LogStorage: &operatorv1.LogStorage{
+				Spec: operatorv1.LogStorageSpec{
+					KibanaSpaces: []operatorv1.KibanaSpace{
+						{ID: "team-a", Name: "Team A", Groups: []string{"team-a-devs", "team-a-ops"}},
+						{ID: "team-b", Groups: []string{"team-b"}, TenantRole: "team-b"},
+					},
+				},
+			},
+			Installation: &operatorv1.InstallationSpec{},
+			UserSecret: &corev1.Secret{
+				TypeMeta:   metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+				ObjectMeta: metav1.ObjectMeta{Name: render.KibanaSpacesUserSecret, Namespace: common.OperatorNamespace()},
+				Data:       map[string][]byte{"username": []byte(render.KibanaSpacesUserName), "password": []byte("password")},
+			},
+			Enabled: true,
+		}
+	})
+
+	It("should render the job with the request body of each space", func() {
+		component := render.KibanaSpaces(cfg)
+		Expect(component.ResolveImages(nil)).NotTo(HaveOccurred())
+
+		toCreate, toDelete := component.Objects()
+		Expect(toDelete).To(BeEmpty())
+		expectedResources := []struct {
+			name    string
+			ns      string
+			group   string
+			version string
+			kind    string
+		}{
+			{render.KibanaSpacesPolicyName, render.ElasticsearchNamespace, "projectcalico.org", "v3", "NetworkPolicy"},
+			{render.KibanaSpacesName, render.ElasticsearchNamespace, "", "v1", "ServiceAccount"},
+			{render.KibanaSpacesName, render.ElasticsearchNamespace, "", "v1", "ConfigMap"},
+			{render.KibanaSpacesName, render.ElasticsearchNamespace, "batch", "v1", "Job"},
+			{render.KibanaSpacesUserSecret, common.OperatorNamespace(), "", "v1", "Secret"},
+			{render.KibanaSpacesUserSecret, render.ElasticsearchNamespace, "", "v1", "Secret"},
+		}
+		Expect(toCreate).To(HaveLen(len(expectedResources)))
+		for i, expectedRes := range expectedResources {
+			rtest.ExpectResource(toCreate[i], expectedRes.name, expectedRes.ns, expectedRes.group, expectedRes.version, expectedRes.kind)
+		}
+
+		cm := rtest.GetResource(toCreate, render.KibanaSpacesName, render.ElasticsearchNamespace, "", "v1", "ConfigMap").(*corev1.ConfigMap)
+		Expect(cm.Data).To(HaveLen(2))
+		Expect(cm.Data["team-a.space.json"]).To(MatchJSON(`{"id": "team-a", "name": "Team A"}`))
+		Expect(cm.Data["team-b.space.json"]).To(MatchJSON(`{"id": "team-b", "name": "team-b"}`))
+
+		job := rtest.GetResource(toCreate, render.KibanaSpacesName, render.ElasticsearchNamespace, "batch", "v1", "Job").(*batchv1.Job)
+		Expect(job.Spec.Template.Annotations).To(HaveKeyWithValue(render.KibanaSpacesHashAnnotation, rmeta.AnnotationHash(cfg.LogStorage.Spec.KibanaSpaces)))
+		Expect(job.Spec.Template.Spec.RestartPolicy).To(Equal(corev1.RestartPolicyNever))
+		Expect(job.Spec.Template.Spec.Containers[0].Env).To(ContainElements(
+			corev1.EnvVar{Name: "KIBANA_URL", Value: "https://tigera-secure-kb-http.tigera-kibana.svc:5601/tigera-kibana"},
+			corev1.EnvVar{Name: "ELASTIC_USERNAME", ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: render.KibanaSpacesUserSecret},
+					Key:                  "username",
+				},
+			}},
+		))
+		for _, env := range job.Spec.Template.Spec.Containers[0].Env {
+			Expect(env.ValueFrom).NotTo(Equal(&corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: render.ElasticsearchAdminUserSecret},
+					Key:                  "elastic",
+				},
+			}))
+		}
+		Expect(job.Spec.Template.Spec.Containers[0].VolumeMounts).To(ContainElement(
+			corev1.VolumeMount{Name: render.KibanaSpacesName, MountPath: "/etc/kibana-spaces/", ReadOnly: true},
+		))
+	})
+
+	It("should delete the job and its user secrets when there are no spaces", func() {
+		cfg.Enabled = false
+		cfg.UserSecret = nil
+		component := render.KibanaSpaces(cfg)
+		Expect(component.ResolveImages(nil)).NotTo(HaveOccurred())
+
+		toCreate, toDelete := component.Objects()
+		Expect(toCreate).To(BeEmpty())
+		Expect(toDelete).To(HaveLen(6))
+		Expect(toDelete).To(ContainElements(
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: render.KibanaSpacesUserSecret, Namespace: common.OperatorNamespace()}},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: render.KibanaSpacesUserSecret, Namespace: render.ElasticsearchNamespace}},
+		))
+	})
+})
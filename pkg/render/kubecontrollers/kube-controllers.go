@@ -34,6 +34,7 @@ import (
 	"github.com/tigera/operator/pkg/controller/k8sapi"
 	"github.com/tigera/operator/pkg/render"
 	rcomp "github.com/tigera/operator/pkg/render/common/components"
+	"github.com/tigera/operator/pkg/render/common/disruptionpolicy"
 	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
 	"github.com/tigera/operator/pkg/render/common/podsecuritypolicy"
@@ -75,6 +76,10 @@ type KubeControllersConfiguration struct {
 	// Whether or not the LogStorage CRD is present in the cluster.
 	LogStorageExists bool
 
+	// ESClusterConfig holds the name of the cluster in the names of the log indices, for the Elasticsearch kube
+	// controllers. The default cluster name is used if it is nil.
+	ESClusterConfig *relasticsearch.ClusterConfig
+
 	ClusterDomain string
 	MetricsPort   int
 
@@ -90,6 +95,10 @@ type KubeControllersConfiguration struct {
 
 	// Whether or not the cluster supports pod security policies.
 	UsePSP bool
+
+	// DisruptionPolicy defines how the pod of the Elasticsearch kube controllers is stopped. It may be nil, in which
+	// case the pod is stopped with the defaults.
+	DisruptionPolicy *operatorv1.DisruptionPolicy
 }
 
 func NewCalicoKubeControllers(cfg *KubeControllersConfiguration) *kubeControllersComponent {
@@ -470,7 +479,11 @@ func (c *kubeControllersComponent) controllersDeployment() *appsv1.Deployment {
 	}
 
 	if c.kubeControllerName == EsKubeController {
-		container = relasticsearch.ContainerDecorate(container, render.DefaultElasticsearchClusterName,
+		clusterName := render.DefaultElasticsearchClusterName
+		if c.cfg.ESClusterConfig != nil {
+			clusterName = c.cfg.ESClusterConfig.ClusterName()
+		}
+		container = relasticsearch.ContainerDecorate(container, clusterName,
 			ElasticsearchKubeControllersUserSecret, c.cfg.ClusterDomain, rmeta.OSTypeLinux)
 	}
 
@@ -507,6 +520,9 @@ func (c *kubeControllersComponent) controllersDeployment() *appsv1.Deployment {
 		},
 	}
 	render.SetClusterCriticalPod(&(d.Spec.Template))
+	if c.kubeControllerName == EsKubeController {
+		disruptionpolicy.Apply(&d.Spec.Template.Spec, c.cfg.DisruptionPolicy, false)
+	}
 
 	if overrides := c.cfg.Installation.CalicoKubeControllersDeployment; overrides != nil {
 		rcomp.ApplyDeploymentOverrides(&d, overrides)
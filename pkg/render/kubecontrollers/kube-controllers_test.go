@@ -33,6 +33,7 @@ import (
 	"github.com/tigera/operator/pkg/controller/k8sapi"
 	"github.com/tigera/operator/pkg/dns"
 	"github.com/tigera/operator/pkg/render"
+	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
 	rtest "github.com/tigera/operator/pkg/render/common/test"
 	"github.com/tigera/operator/pkg/render/kubecontrollers"
@@ -573,6 +574,21 @@ var _ = Describe("kube-controllers rendering tests", func() {
 		Expect(groupPrefix).To(Equal("gOIDC:"))
 	})
 
+	It("should use the cluster name of the Elasticsearch cluster config", func() {
+		instance.Variant = operatorv1.TigeraSecureEnterprise
+		cfg.LogStorageExists = true
+		cfg.KubeControllersGatewaySecret = &testutils.KubeControllersUserSecret
+		cfg.ManagerInternalSecret = internalManagerTLSSecret
+		cfg.ESClusterConfig = relasticsearch.NewClusterConfig("eu-west", 1, 1, 1)
+
+		component := kubecontrollers.NewElasticsearchKubeControllers(&cfg)
+		Expect(component.ResolveImages(nil)).To(BeNil())
+		resources, _ := component.Objects()
+
+		dp := rtest.GetResource(resources, kubecontrollers.EsKubeController, common.CalicoNamespace, "apps", "v1", "Deployment").(*appsv1.Deployment)
+		Expect(dp.Spec.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "ELASTIC_INDEX_SUFFIX", Value: "eu-west"}))
+	})
+
 	Context("With calico-kube-controllers overrides", func() {
 		var rr1 = corev1.ResourceRequirements{
 			Limits: corev1.ResourceList{
@@ -19,8 +19,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"hash/fnv"
+	"net"
 	"net/url"
+	"path"
+	"strconv"
 	"strings"
+	"sync"
 
 	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
 	"github.com/tigera/api/pkg/lib/numorstring"
@@ -32,25 +36,34 @@ import (
 	"github.com/elastic/cloud-on-k8s/pkg/controller/common/annotation"
 
 	"gopkg.in/inf.v0"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	batchv1beta "k8s.io/api/batch/v1beta1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/common"
 	"github.com/tigera/operator/pkg/components"
 	"github.com/tigera/operator/pkg/dns"
 	"github.com/tigera/operator/pkg/ptr"
+	rcomp "github.com/tigera/operator/pkg/render/common/components"
+	"github.com/tigera/operator/pkg/render/common/disruptionpolicy"
 	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
 	"github.com/tigera/operator/pkg/render/common/podaffinity"
+	"github.com/tigera/operator/pkg/render/common/poddisruptionbudget"
 	"github.com/tigera/operator/pkg/render/common/podsecuritypolicy"
 	"github.com/tigera/operator/pkg/render/common/secret"
 	"github.com/tigera/operator/pkg/render/common/securitycontext"
@@ -65,6 +78,16 @@ const (
 	ECKLicenseConfigMapName = "elastic-licensing"
 	ECKOperatorPolicyName   = networkpolicy.TigeraComponentPolicyPrefix + "elastic-operator-access"
 	ECKEnterpriseTrial      = "eck-trial-license"
+	ECKEnterpriseLicense    = "tigera-eck-enterprise-license"
+
+	// ECKLicenseKey is the key of the license file in the license secrets that the ECK operator applies.
+	ECKLicenseKey = "license"
+
+	// The validating webhook of the ECK operator, when it is enabled in the LogStorage.
+	ECKWebhookServiceName       = "elastic-webhook-server"
+	ECKWebhookCertSecret        = "tigera-eck-webhook-cert"
+	ECKWebhookConfigurationName = "elastic-webhook.k8s.elastic.co"
+	ECKWebhookPort              = 9443
 
 	ElasticsearchNamespace = "tigera-elasticsearch"
 
@@ -93,14 +116,40 @@ const (
 	KibanaPolicyName   = networkpolicy.TigeraComponentPolicyPrefix + "kibana-access"
 	KibanaPort         = 5601
 
-	DefaultElasticsearchClusterName = "cluster"
-	DefaultElasticsearchReplicas    = 0
+	// KibanaElasticsearchUserSecret holds the credentials of the Elasticsearch user of Kibana when Kibana connects to
+	// the Elasticsearch hosts of the LogStorage instead of the Elasticsearch cluster that ECK associates it with.
+	KibanaElasticsearchUserSecret = "tigera-kibana-elasticsearch-user"
+	KibanaElasticsearchUserName   = "tigera-kibana-system"
+
+	// kibanaOIDCRealm is the name of the OIDC realm of Elasticsearch, and of the auth provider of Kibana, that users
+	// log in to Kibana with through Dex.
+	kibanaOIDCRealm = "oidc1"
+	// kibanaFileRealm and kibanaNativeRealm are the names of the file and native realms of Elasticsearch, which are
+	// configured explicitly with the OIDC realm since configuring a realm disables the implicit ones. The file realm
+	// holds the users of ECK, and the native realm holds the users of the operator and the roles of the OIDC users.
+	kibanaFileRealm   = "file1"
+	kibanaNativeRealm = "native1"
+
+	DefaultElasticsearchClusterName = operatorv1.DefaultElasticsearchClusterName
+	DefaultElasticsearchReplicas    = operatorv1.DefaultElasticsearchReplicas
 	DefaultElasticStorageGi         = 10
 
+	// LogStoragePriorityClassName is the PriorityClass of the pods of Elasticsearch, Kibana and the curator when the
+	// default PriorityClass of the LogStorage is enabled, unless the LogStorage overrides it.
+	LogStoragePriorityClassName = operatorv1.DefaultLogStoragePriorityClassName
+	// LogStoragePriorityClassValue ranks the log storage above the pods without a PriorityClass, and far below the
+	// system-cluster-critical and system-node-critical PriorityClasses.
+	LogStoragePriorityClassValue = 1000000
+
 	EsCuratorName           = "elastic-curator"
 	EsCuratorServiceAccount = "tigera-elastic-curator"
 	EsCuratorPolicyName     = networkpolicy.TigeraComponentPolicyPrefix + "allow-elastic-curator"
 
+	// The containers of the LogStorage that rcomp.ContainerOverridesAnnotation can override.
+	ECKOperatorContainerOverridesKey   = ECKOperatorName + "/manager"
+	ElasticsearchContainerOverridesKey = "elasticsearch/elasticsearch"
+	KibanaContainerOverridesKey        = "kibana/kibana"
+
 	OIDCUsersConfigMapName = "tigera-known-oidc-users"
 	OIDCUsersEsSecreteName = "tigera-oidc-users-elasticsearch-credentials"
 
@@ -110,7 +159,7 @@ const (
 	// behaviour.
 	// Default: 80
 	// +optional
-	maxTotalStoragePercent int32 = 80
+	MaxTotalStoragePercent int32 = 80
 
 	// TSEE will remove dns and flow log indices once the combined data exceeds this
 	// threshold. The default value (70% of the cluster size) is used because flow
@@ -147,6 +196,40 @@ const (
 	csrRootCAConfigMapName    = "elasticsearch-config"
 )
 
+// The percentage of the memory limit of the Elasticsearch container that is used for the JVM heap when it is sized
+// by the JVM, in line with the recommendation to use half of the memory of the pod for the heap.
+const defaultJVMHeapMaxRAMPercentage int32 = 50
+
+// Cross-cluster search constants.
+const (
+	// SnapshotRepositorySecretName is the name of the copy of the secret of the snapshot repository in the
+	// Elasticsearch namespace. It doesn't depend on the name of the secret of the LogStorage, so that the copy is
+	// replaced when the LogStorage names another secret, and deleted when it no longer takes snapshots.
+	SnapshotRepositorySecretName = "tigera-elasticsearch-snapshot-repository"
+
+	// SecureSettingsSecretLabel labels the copies of the secure settings secrets in the Elasticsearch namespace, so
+	// that the copies of the secrets that the LogStorage no longer refers to can be found and deleted.
+	SecureSettingsSecretLabel = "operator.tigera.io/elasticsearch-secure-settings"
+)
+
+const (
+	ElasticsearchRemoteClusterCAHashAnnotation = "hash.operator.tigera.io/remote-cluster-cas"
+
+	remoteClusterCAVolumeName      = "remote-cluster-cas"
+	remoteClusterCAVolumeMountPath = "/usr/share/elasticsearch/config/remote-cluster-cas"
+)
+
+// eckTransportCAPaths are the CAs of the transport certificates that ECK configures: the CA of the certificates of the
+// cluster and the CAs of the remote clusters that are associated by ECK. The CAs of the remote clusters of the
+// LogStorage are appended to them, since setting the certificate authorities replaces the list that ECK configures.
+var eckTransportCAPaths = []string{
+	"/usr/share/elasticsearch/config/transport-certs/ca.crt",
+	"/usr/share/elasticsearch/config/transport-remote-certs/ca.crt",
+}
+
+// The Elasticsearch node attribute that holds the zone of the K8s nodes of an Elasticsearch node.
+const zoneAwarenessAttribute = "zone"
+
 // Certificate management constants.
 const (
 	// Volume that is added by ECK and is overridden if certificate management is used.
@@ -158,21 +241,63 @@ const (
 )
 
 var ElasticsearchSelector = fmt.Sprintf("elasticsearch.k8s.elastic.co/cluster-name == '%s'", ElasticsearchName)
-var ElasticsearchEntityRule = v3.EntityRule{
-	NamespaceSelector: fmt.Sprintf("projectcalico.org/name == '%s'", ElasticsearchNamespace),
-	Selector:          ElasticsearchSelector,
-	Ports:             []numorstring.Port{{MinPort: ElasticsearchDefaultPort, MaxPort: ElasticsearchDefaultPort}},
-}
+var ElasticsearchEntityRule = ElasticsearchPortEntityRule(ElasticsearchDefaultPort)
 var InternalElasticsearchEntityRule = v3.EntityRule{
 	NamespaceSelector: fmt.Sprintf("projectcalico.org/name == '%s'", ElasticsearchNamespace),
 	Selector:          ElasticsearchSelector,
 	Ports:             []numorstring.Port{{MinPort: ElasticsearchInternalPort, MaxPort: ElasticsearchInternalPort}},
 }
-var KibanaEntityRule = networkpolicy.CreateEntityRule(KibanaNamespace, KibanaName, KibanaPort)
+var KibanaEntityRule = KibanaPortEntityRule(KibanaPort)
 var KibanaSourceEntityRule = networkpolicy.CreateSourceEntityRule(KibanaNamespace, KibanaName)
 var ECKOperatorSourceEntityRule = networkpolicy.CreateSourceEntityRule(ECKOperatorNamespace, ECKOperatorName)
 var ESCuratorSourceEntityRule = networkpolicy.CreateSourceEntityRule(ElasticsearchNamespace, EsCuratorName)
 
+// SnapshotRepositoryCredentialKeys are the keys of the secret of a snapshot repository that hold the credentials of each
+// type of object store.
+var SnapshotRepositoryCredentialKeys = map[operatorv1.SnapshotRepositoryType][]string{
+	operatorv1.SnapshotRepositoryS3:    {"access_key", "secret_key"},
+	operatorv1.SnapshotRepositoryGCS:   {"credentials_file"},
+	operatorv1.SnapshotRepositoryAzure: {"account", "key"},
+}
+
+// snapshotRepositoryDomains are the domains of the endpoints of each type of object store. The endpoints of S3 and
+// Azure depend on the region and the storage account.
+var snapshotRepositoryDomains = map[operatorv1.SnapshotRepositoryType][]string{
+	operatorv1.SnapshotRepositoryS3:    {"*.amazonaws.com"},
+	operatorv1.SnapshotRepositoryGCS:   {"storage.googleapis.com", "oauth2.googleapis.com"},
+	operatorv1.SnapshotRepositoryAzure: {"*.blob.core.windows.net"},
+}
+
+// ElasticsearchPortEntityRule returns the entity rule of the Elasticsearch pods listening on the given HTTP port.
+func ElasticsearchPortEntityRule(port int32) v3.EntityRule {
+	return v3.EntityRule{
+		NamespaceSelector: fmt.Sprintf("projectcalico.org/name == '%s'", ElasticsearchNamespace),
+		Selector:          ElasticsearchSelector,
+		Ports:             networkpolicy.Ports(uint16(port)),
+	}
+}
+
+// KibanaPortEntityRule returns the entity rule of the Kibana pods listening on the given port.
+func KibanaPortEntityRule(port int32) v3.EntityRule {
+	return networkpolicy.CreateEntityRule(KibanaNamespace, KibanaName, uint16(port))
+}
+
+// ElasticsearchHTTPPort returns the port that the Elasticsearch pods listen on for HTTP.
+func ElasticsearchHTTPPort(ports *operatorv1.LogStoragePorts) int32 {
+	if ports != nil && ports.ElasticsearchHTTP != nil {
+		return *ports.ElasticsearchHTTP
+	}
+	return ElasticsearchDefaultPort
+}
+
+// KibanaHTTPPort returns the port that the Kibana pods listen on.
+func KibanaHTTPPort(ports *operatorv1.LogStoragePorts) int32 {
+	if ports != nil && ports.Kibana != nil {
+		return *ports.Kibana
+	}
+	return KibanaPort
+}
+
 var log = logf.Log.WithName("render")
 
 // LogStorage renders the components necessary for kibana and elasticsearch
@@ -182,6 +307,110 @@ func LogStorage(cfg *ElasticsearchConfiguration) Component {
 	}
 }
 
+// LogStorageSubComponentName identifies a part of the log storage rendering.
+type LogStorageSubComponentName string
+
+const (
+	LogStorageSubComponentRBAC          LogStorageSubComponentName = "RBAC"
+	LogStorageSubComponentECKOperator   LogStorageSubComponentName = "ECK operator"
+	LogStorageSubComponentElasticsearch LogStorageSubComponentName = "Elasticsearch"
+	LogStorageSubComponentKibana        LogStorageSubComponentName = "Kibana"
+	LogStorageSubComponentCurator       LogStorageSubComponentName = "Curator"
+)
+
+// LogStorageSubComponent is a part of the log storage rendering that can be applied independently of the other parts.
+type LogStorageSubComponent struct {
+	Name LogStorageSubComponentName
+	// DependsOn are the sub-components that must be created before, and deleted after, the sub-component.
+	DependsOn []LogStorageSubComponentName
+
+	es      *elasticsearchComponent
+	objects func() ([]client.Object, []client.Object)
+
+	// imagesLock serializes the image resolution of the sub-components, since they share the elasticsearchComponent.
+	imagesLock *sync.Mutex
+}
+
+// LogStorageSubComponents renders the components necessary for kibana and elasticsearch split into sub-components, in
+// the order they should be applied. Unlike LogStorage, this allows a failure to apply one of them (e.g. Kibana) not to
+// prevent the others from being applied.
+func LogStorageSubComponents(cfg *ElasticsearchConfiguration) []*LogStorageSubComponent {
+	es := &elasticsearchComponent{cfg: cfg}
+	return es.subComponents()
+}
+
+func (es *elasticsearchComponent) subComponents() []*LogStorageSubComponent {
+	imagesLock := &sync.Mutex{}
+	return []*LogStorageSubComponent{
+		{
+			Name:       LogStorageSubComponentRBAC,
+			es:         es,
+			objects:    joinObjects(es.clusterRBACObjects, es.curatorRBACObjects),
+			imagesLock: imagesLock,
+		},
+		{
+			Name:       LogStorageSubComponentECKOperator,
+			DependsOn:  []LogStorageSubComponentName{LogStorageSubComponentRBAC},
+			es:         es,
+			objects:    joinObjects(es.eckOperatorAccessObjects, es.eckOperatorDeploymentObjects),
+			imagesLock: imagesLock,
+		},
+		{
+			Name:       LogStorageSubComponentElasticsearch,
+			DependsOn:  []LogStorageSubComponentName{LogStorageSubComponentECKOperator},
+			es:         es,
+			objects:    joinObjects(es.elasticsearchClusterObjects, es.oidcUserRBACObjects, es.elasticsearchCertificateObjects),
+			imagesLock: imagesLock,
+		},
+		{
+			Name:       LogStorageSubComponentKibana,
+			DependsOn:  []LogStorageSubComponentName{LogStorageSubComponentElasticsearch},
+			es:         es,
+			objects:    joinObjects(es.kibanaClusterObjects, es.kibanaCertificateObjects),
+			imagesLock: imagesLock,
+		},
+		{
+			Name:       LogStorageSubComponentCurator,
+			DependsOn:  []LogStorageSubComponentName{LogStorageSubComponentElasticsearch},
+			es:         es,
+			objects:    joinObjects(es.curatorAccessObjects, es.curatorCronJobObjects),
+			imagesLock: imagesLock,
+		},
+	}
+}
+
+// ResolveImages resolves the images of all the sub-components, since they share the same elasticsearchComponent.
+func (c *LogStorageSubComponent) ResolveImages(is *operatorv1.ImageSet) error {
+	c.imagesLock.Lock()
+	defer c.imagesLock.Unlock()
+	return c.es.ResolveImages(is)
+}
+
+func (c *LogStorageSubComponent) Objects() ([]client.Object, []client.Object) {
+	return c.objects()
+}
+
+func (c *LogStorageSubComponent) Ready() bool {
+	return c.es.Ready()
+}
+
+func (c *LogStorageSubComponent) SupportedOSType() rmeta.OSType {
+	return c.es.SupportedOSType()
+}
+
+// SupportedArchitectures returns the architectures that the images of the pods of the sub-component are published for.
+func (c *LogStorageSubComponent) SupportedArchitectures() []string {
+	switch c.Name {
+	case LogStorageSubComponentElasticsearch:
+		return components.Architectures(components.ComponentElasticsearch)
+	case LogStorageSubComponentKibana:
+		return components.Architectures(components.ComponentKibana)
+	case LogStorageSubComponentCurator:
+		return components.Architectures(components.ComponentEsCurator)
+	}
+	return nil
+}
+
 // ElasticsearchConfiguration contains all the config information needed to render the component.
 type ElasticsearchConfiguration struct {
 	LogStorage                  *operatorv1.LogStorage
@@ -206,9 +435,54 @@ type ElasticsearchConfiguration struct {
 	UnusedTLSSecret             *corev1.Secret
 	ApplyTrial                  bool
 	KeyStoreSecret              *corev1.Secret
+	// EnterpriseLicenseSecret holds the Elastic enterprise license of the LogStorage, which takes the place of the trial.
+	EnterpriseLicenseSecret *corev1.Secret
+	// RemoteClusterCASecrets hold the CA certificates of the remote clusters of the LogStorage.
+	RemoteClusterCASecrets []*corev1.Secret
+	// SnapshotRepositorySecret holds the credentials of the object store that the snapshots of the LogStorage are
+	// stored in.
+	SnapshotRepositorySecret *corev1.Secret
+	// SecureSettingsSecrets hold the keys that the SecureSettings of the LogStorage add to the keystore of
+	// Elasticsearch.
+	SecureSettingsSecrets []*corev1.Secret
+	// KibanaElasticsearchUserSecret holds the credentials of the Elasticsearch user of Kibana when the LogStorage
+	// overrides the Elasticsearch hosts of Kibana.
+	KibanaElasticsearchUserSecret *corev1.Secret
+	// Authentication configures the identity provider of the manager, which Kibana logs users in through when it is
+	// an OIDC provider that is proxied by Dex.
+	Authentication *operatorv1.Authentication
+	// DexSecret holds the client secret of Dex, which Elasticsearch authenticates with to Dex for Kibana.
+	DexSecret *corev1.Secret
+	// ContainerOverrides hold the args and env vars that the annotations of the LogStorage add to its containers.
+	ContainerOverrides rcomp.ContainerOverrides
+	// CuratorSuspended suspends the curator CronJob, e.g. while Elasticsearch is recovering.
+	CuratorSuspended bool
+	// DeferRollouts keeps the hash annotations of the pod templates of the current Elasticsearch and Kibana, so that
+	// changed certificates or credentials don't restart them while the rollout window of the LogStorage is closed.
+	DeferRollouts bool
+	// ECKWebhookKeyPair is the certificate of the validating webhook of the ECK operator. It is only set when the
+	// webhook is enabled in the LogStorage.
+	ECKWebhookKeyPair certificatemanagement.KeyPairInterface
 
 	// Whether or not the cluster supports pod security policies.
 	UsePSP bool
+
+	// UseBatchV1CronJobs renders the curator CronJob with the batch/v1 API instead of the batch/v1beta1 API, which was
+	// removed in Kubernetes v1.25.
+	UseBatchV1CronJobs bool
+
+	// ExpandableStorageClasses are the StorageClasses of the volumes of Elasticsearch, by whether they allow volume
+	// expansion. When the storage of a NodeSet is increased on a StorageClass that allows expansion, the NodeSet keeps
+	// its name so that the ECK operator expands its volumes, instead of migrating the data to a new NodeSet.
+	ExpandableStorageClasses map[string]bool
+
+	// Zones are the zones of the K8s nodes that Elasticsearch can run on, sorted. The Elasticsearch nodes are spread
+	// over one NodeSet per zone when the zone awareness of the LogStorage is Auto or Enabled.
+	Zones []string
+
+	// RemoveElasticsearchPodDisruptionBudget is set if the PodDisruptionBudget of the Elasticsearch nodes exists, so
+	// that it is deleted once the LogStorage no longer configures one.
+	RemoveElasticsearchPodDisruptionBudget bool
 }
 
 type elasticsearchComponent struct {
@@ -268,80 +542,266 @@ func (es *elasticsearchComponent) SupportedOSType() rmeta.OSType {
 	return rmeta.OSTypeLinux
 }
 
-func (es *elasticsearchComponent) Objects() ([]client.Object, []client.Object) {
-	var toCreate, toDelete []client.Object
+// SupportedArchitectures returns the architectures that the images of Elasticsearch, Kibana and the curator are all
+// published for, since the sub-components share the images.
+func (es *elasticsearchComponent) SupportedArchitectures() []string {
+	return components.Architectures(components.ComponentElasticsearch, components.ComponentKibana, components.ComponentEsCurator)
+}
 
-	// Doesn't matter what the cluster type is, if LogStorage exists and the DeletionTimestamp is set finalized the
-	// deletion
-	if es.cfg.LogStorage != nil && es.cfg.LogStorage.DeletionTimestamp != nil {
+// Objects returns the objects of all the sub-components, in the order that they were rendered in before the rendering
+// was split into sub-components.
+func (es *elasticsearchComponent) Objects() ([]client.Object, []client.Object) {
+	return joinObjects(
+		es.eckOperatorAccessObjects,
+		es.clusterRBACObjects,
+		es.eckOperatorDeploymentObjects,
+		es.elasticsearchClusterObjects,
+		es.kibanaClusterObjects,
+		es.curatorAccessObjects,
+		es.curatorRBACObjects,
+		es.curatorCronJobObjects,
+		es.oidcUserRBACObjects,
+		es.elasticsearchCertificateObjects,
+		es.kibanaCertificateObjects,
+	)()
+}
 
-		if es.cfg.Elasticsearch != nil {
-			if es.cfg.Elasticsearch.DeletionTimestamp == nil {
-				toDelete = append(toDelete, es.cfg.Elasticsearch)
-			}
+// joinObjects returns a function that returns the objects to create and delete of each of the given functions, in
+// order.
+func joinObjects(objects ...func() ([]client.Object, []client.Object)) func() ([]client.Object, []client.Object) {
+	return func() ([]client.Object, []client.Object) {
+		var toCreate, toDelete []client.Object
+		for _, f := range objects {
+			create, del := f()
+			toCreate = append(toCreate, create...)
+			toDelete = append(toDelete, del...)
 		}
+		return toCreate, toDelete
+	}
+}
 
-		if es.cfg.Kibana != nil {
-			if es.cfg.Kibana.DeletionTimestamp == nil {
-				toDelete = append(toDelete, es.cfg.Kibana)
-			}
-		}
+// deleting returns true if the LogStorage is being deleted. Doesn't matter what the cluster type is, if LogStorage
+// exists and the DeletionTimestamp is set we only finalize the deletion.
+func (es *elasticsearchComponent) deleting() bool {
+	return es.cfg.LogStorage != nil && es.cfg.LogStorage.DeletionTimestamp != nil
+}
 
-		return toCreate, toDelete
+// clusterRBACObjects returns the cluster wide RBAC resources, pod security policies and PriorityClass of Elasticsearch,
+// the ECK operator and Kibana. The PriorityClass is cluster wide, so it is deleted along with the LogStorage, on managed
+// clusters and when it isn't enabled.
+func (es *elasticsearchComponent) clusterRBACObjects() ([]client.Object, []client.Object) {
+	if es.deleting() || es.cfg.ManagementClusterConnection != nil {
+		return nil, []client.Object{es.logStoragePriorityClass()}
 	}
 
-	if es.cfg.ManagementClusterConnection == nil {
+	var toCreate, toDelete []client.Object
+	if es.cfg.LogStorage.Spec.DefaultPriorityClassEnabled() {
+		toCreate = append(toCreate, es.logStoragePriorityClass())
+	} else {
+		toDelete = append(toDelete, es.logStoragePriorityClass())
+	}
+	// Apply the pod security policies for all providers except OpenShift
+	if es.cfg.Provider != operatorv1.ProviderOpenShift {
+		toCreate = append(toCreate,
+			es.elasticsearchClusterRoleBinding(),
+			es.elasticsearchClusterRole())
+
+		if es.cfg.UsePSP {
+			toCreate = append(toCreate,
+				es.eckOperatorPodSecurityPolicy(),
+				es.elasticsearchPodSecurityPolicy())
+		}
 
-		// ECK operator
 		toCreate = append(toCreate,
-			// In order to use restricted, we need to change:
-			// - securityContext.allowPrivilegeEscalation=false
-			// - securityContext.capabilities.drop=["ALL"]
-			// - securityContext.runAsNonRoot=true
-			// - securityContext.seccompProfile.type to "RuntimeDefault" or "Localhost"
-			CreateNamespace(ECKOperatorNamespace, es.cfg.Installation.KubernetesProvider, PSSBaseline),
-			es.eckOperatorAllowTigeraPolicy(),
-		)
+			es.kibanaClusterRoleBinding(),
+			es.kibanaClusterRole(),
+			es.kibanaPodSecurityPolicy())
+	}
+	return toCreate, toDelete
+}
 
-		toCreate = append(toCreate, secret.ToRuntimeObjects(secret.CopyToNamespace(ECKOperatorNamespace, es.cfg.PullSecrets...)...)...)
+// curatorRBACObjects returns the cluster wide RBAC resources and pod security policy of the curator.
+func (es *elasticsearchComponent) curatorRBACObjects() ([]client.Object, []client.Object) {
+	if es.deleting() || es.cfg.ManagementClusterConnection != nil || es.cfg.Provider == operatorv1.ProviderOpenShift {
+		return nil, nil
+	}
 
+	// If the curator is enabled and we have the curator secrets then create the curator RBAC.
+	var toCreate []client.Object
+	if es.curatorEnabled() && len(es.cfg.CuratorSecrets) > 0 {
 		toCreate = append(toCreate,
-			es.eckOperatorClusterRole(),
-			es.eckOperatorClusterRoleBinding(),
-			es.eckOperatorServiceAccount(),
-		)
-		// This is needed for the operator to be able to set privileged mode for pods.
-		// https://docs.docker.com/ee/ucp/authorization/#secure-kubernetes-defaults
-		if es.cfg.Provider == operatorv1.ProviderDockerEE {
-			toCreate = append(toCreate, es.eckOperatorClusterAdminClusterRoleBinding())
+			es.curatorClusterRole(),
+			es.curatorClusterRoleBinding())
+		if es.cfg.UsePSP {
+			toCreate = append(toCreate, es.curatorPodSecurityPolicy())
 		}
+	}
+	return toCreate, nil
+}
 
-		// Apply the pod security policies for all providers except OpenShift
-		if es.cfg.Provider != operatorv1.ProviderOpenShift {
-			toCreate = append(toCreate,
-				es.elasticsearchClusterRoleBinding(),
-				es.elasticsearchClusterRole())
+// eckOperatorAccessObjects returns the namespace, network policy, pull secrets and RBAC resources of the ECK operator.
+func (es *elasticsearchComponent) eckOperatorAccessObjects() ([]client.Object, []client.Object) {
+	if es.deleting() || es.cfg.ManagementClusterConnection != nil {
+		return nil, nil
+	}
 
-			if es.cfg.UsePSP {
-				toCreate = append(toCreate,
-					es.eckOperatorPodSecurityPolicy(),
-					es.elasticsearchPodSecurityPolicy())
-			}
+	toCreate := []client.Object{
+		// In order to use restricted, we need to change:
+		// - securityContext.allowPrivilegeEscalation=false
+		// - securityContext.capabilities.drop=["ALL"]
+		// - securityContext.runAsNonRoot=true
+		// - securityContext.seccompProfile.type to "RuntimeDefault" or "Localhost"
+		CreateNamespace(ECKOperatorNamespace, es.cfg.Installation.KubernetesProvider, PSSBaseline),
+		es.eckOperatorAllowTigeraPolicy(),
+	}
 
-			if !operatorv1.IsFIPSModeEnabled(es.cfg.Installation.FIPSMode) {
-				toCreate = append(toCreate,
-					es.kibanaClusterRoleBinding(),
-					es.kibanaClusterRole(),
-					es.kibanaPodSecurityPolicy())
-			}
+	toCreate = append(toCreate, secret.ToRuntimeObjects(secret.CopyToNamespace(ECKOperatorNamespace, es.cfg.PullSecrets...)...)...)
+
+	toCreate = append(toCreate,
+		es.eckOperatorClusterRole(),
+		es.eckOperatorClusterRoleBinding(),
+		es.eckOperatorServiceAccount(),
+	)
+	// This is needed for the operator to be able to set privileged mode for pods.
+	// https://docs.docker.com/ee/ucp/authorization/#secure-kubernetes-defaults
+	if es.cfg.Provider == operatorv1.ProviderDockerEE {
+		toCreate = append(toCreate, es.eckOperatorClusterAdminClusterRoleBinding())
+	}
+	return toCreate, nil
+}
+
+// eckOperatorDeploymentObjects returns the license, StatefulSet and validating webhook of the ECK operator.
+func (es *elasticsearchComponent) eckOperatorDeploymentObjects() ([]client.Object, []client.Object) {
+	if es.deleting() || es.cfg.ManagementClusterConnection != nil {
+		return nil, nil
+	}
+
+	var toCreate []client.Object
+	// An enterprise license takes the place of the trial license, which is only applied if there is no license yet.
+	if es.cfg.EnterpriseLicenseSecret != nil {
+		toCreate = append(toCreate, es.elasticEnterpriseLicense())
+	} else if es.cfg.ApplyTrial {
+		toCreate = append(toCreate, es.elasticEnterpriseTrial())
+	}
+	toCreate = append(toCreate, es.eckOperatorStatefulSet())
+
+	var toDelete []client.Object
+	if es.eckWebhookEnabled() {
+		toCreate = append(toCreate, es.eckWebhookService(), es.eckValidatingWebhookConfiguration())
+	} else {
+		toDelete = append(toDelete,
+			&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: ECKWebhookServiceName, Namespace: ECKOperatorNamespace}},
+			&admissionregistrationv1.ValidatingWebhookConfiguration{ObjectMeta: metav1.ObjectMeta{Name: ECKWebhookConfigurationName}},
+		)
+	}
+	if es.cfg.EnterpriseLicenseSecret == nil {
+		toDelete = append(toDelete, &corev1.Secret{
+			TypeMeta:   metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: ECKEnterpriseLicense, Namespace: ECKOperatorNamespace},
+		})
+	}
+	return toCreate, toDelete
+}
+
+// eckWebhookEnabled returns whether the ECK operator runs its validating webhook, which needs its certificate.
+func (es *elasticsearchComponent) eckWebhookEnabled() bool {
+	return es.cfg.LogStorage.Spec.ECKValidatingWebhookEnabled() && es.cfg.ECKWebhookKeyPair != nil
+}
+
+// eckWebhookService returns the Service of the validating webhook of the ECK operator.
+func (es *elasticsearchComponent) eckWebhookService() *corev1.Service {
+	return &corev1.Service{
+		TypeMeta: metav1.TypeMeta{Kind: "Service", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ECKWebhookServiceName,
+			Namespace: ECKOperatorNamespace,
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{"control-plane": "elastic-operator"},
+			Ports: []corev1.ServicePort{{
+				Name:       "https",
+				Port:       443,
+				TargetPort: intstr.FromInt(ECKWebhookPort),
+				Protocol:   corev1.ProtocolTCP,
+			}},
+		},
+	}
+}
+
+// eckWebhookCABundle returns the CA certificate that the API server verifies the certificate of the webhook with.
+func (es *elasticsearchComponent) eckWebhookCABundle() []byte {
+	keyPair := es.cfg.ECKWebhookKeyPair
+	if keyPair.UseCertificateManagement() {
+		return es.cfg.Installation.CertificateManagement.CACert
+	}
+	if issuer := keyPair.GetIssuer(); issuer != nil {
+		return issuer.GetCertificatePEM()
+	}
+	return keyPair.GetCertificatePEM()
+}
+
+// eckValidatingWebhookConfiguration returns the configuration of the validating webhook of the ECK operator, which
+// validates the Elasticsearch and Kibana resources of the LogStorage. The changes are admitted while the webhook is
+// unavailable, so that it doesn't block the reconciliation of the LogStorage, e.g. while the ECK operator restarts.
+func (es *elasticsearchComponent) eckValidatingWebhookConfiguration() *admissionregistrationv1.ValidatingWebhookConfiguration {
+	failurePolicy := admissionregistrationv1.Ignore
+	sideEffects := admissionregistrationv1.SideEffectClassNone
+	namespaceSelector := &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{{
+			Key:      "kubernetes.io/metadata.name",
+			Operator: metav1.LabelSelectorOpIn,
+			Values:   []string{ElasticsearchNamespace, KibanaNamespace},
+		}},
+	}
+	webhook := func(name, path, group, resource string) admissionregistrationv1.ValidatingWebhook {
+		return admissionregistrationv1.ValidatingWebhook{
+			Name: name,
+			ClientConfig: admissionregistrationv1.WebhookClientConfig{
+				Service: &admissionregistrationv1.ServiceReference{
+					Name:      ECKWebhookServiceName,
+					Namespace: ECKOperatorNamespace,
+					Path:      &path,
+				},
+				CABundle: es.eckWebhookCABundle(),
+			},
+			Rules: []admissionregistrationv1.RuleWithOperations{{
+				Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.Create, admissionregistrationv1.Update},
+				Rule: admissionregistrationv1.Rule{
+					APIGroups:   []string{group},
+					APIVersions: []string{"v1"},
+					Resources:   []string{resource},
+				},
+			}},
+			FailurePolicy:           &failurePolicy,
+			SideEffects:             &sideEffects,
+			NamespaceSelector:       namespaceSelector,
+			AdmissionReviewVersions: []string{"v1", "v1beta1"},
 		}
+	}
+
+	return &admissionregistrationv1.ValidatingWebhookConfiguration{
+		TypeMeta:   metav1.TypeMeta{Kind: "ValidatingWebhookConfiguration", APIVersion: "admissionregistration.k8s.io/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: ECKWebhookConfigurationName},
+		Webhooks: []admissionregistrationv1.ValidatingWebhook{
+			webhook("elastic-es-validation-v1.k8s.elastic.co", "/validate-elasticsearch-k8s-elastic-co-v1-elasticsearch", "elasticsearch.k8s.elastic.co", "elasticsearches"),
+			webhook("elastic-kb-validation-v1.k8s.elastic.co", "/validate-kibana-k8s-elastic-co-v1-kibana", "kibana.k8s.elastic.co", "kibanas"),
+		},
+	}
+}
 
-		if es.cfg.ApplyTrial {
-			toCreate = append(toCreate, es.elasticEnterpriseTrial())
+// elasticsearchClusterObjects returns the resources of the Elasticsearch cluster, or the external service pointing to
+// the management cluster's Elasticsearch for managed clusters.
+func (es *elasticsearchComponent) elasticsearchClusterObjects() ([]client.Object, []client.Object) {
+	var toCreate, toDelete []client.Object
+
+	if es.deleting() {
+		if es.cfg.Elasticsearch != nil && es.cfg.Elasticsearch.DeletionTimestamp == nil {
+			toDelete = append(toDelete, es.cfg.Elasticsearch)
 		}
-		toCreate = append(toCreate, es.eckOperatorStatefulSet())
+		return toCreate, toDelete
+	}
 
-		// Elasticsearch CRs
+	if es.cfg.ManagementClusterConnection == nil {
 		toCreate = append(toCreate, CreateNamespace(ElasticsearchNamespace, es.cfg.Installation.KubernetesProvider, PSSPrivileged))
 		toCreate = append(toCreate, es.elasticsearchAllowTigeraPolicy())
 		toCreate = append(toCreate, es.elasticsearchInternalAllowTigeraPolicy())
@@ -355,77 +815,58 @@ func (es *elasticsearchComponent) Objects() ([]client.Object, []client.Object) {
 			toCreate = append(toCreate, es.cfg.ElasticsearchUserSecret)
 		}
 
-		toCreate = append(toCreate, es.elasticsearchServiceAccount())
-		toCreate = append(toCreate, es.cfg.ClusterConfig.ConfigMap())
-
-		toCreate = append(toCreate, es.elasticsearchCluster())
-
-		if !operatorv1.IsFIPSModeEnabled(es.cfg.Installation.FIPSMode) {
-			// Kibana CRs
-			// In order to use restricted, we need to change:
-			// - securityContext.allowPrivilegeEscalation=false)
-			// - securityContext.capabilities.drop=["ALL"]
-			// - securityContext.runAsNonRoot=true
-			// - securityContext.seccompProfile.type to "RuntimeDefault" or "Localhost"
-			toCreate = append(toCreate, CreateNamespace(KibanaNamespace, es.cfg.Installation.KubernetesProvider, PSSBaseline))
-			toCreate = append(toCreate, es.kibanaAllowTigeraPolicy())
-			toCreate = append(toCreate, networkpolicy.AllowTigeraDefaultDeny(KibanaNamespace))
-			toCreate = append(toCreate, es.kibanaServiceAccount())
-
-			if len(es.cfg.PullSecrets) > 0 {
-				toCreate = append(toCreate, secret.ToRuntimeObjects(secret.CopyToNamespace(KibanaNamespace, es.cfg.PullSecrets...)...)...)
-			}
+		if len(es.cfg.RemoteClusterCASecrets) > 0 {
+			toCreate = append(toCreate, secret.ToRuntimeObjects(secret.CopyToNamespace(ElasticsearchNamespace, es.cfg.RemoteClusterCASecrets...)...)...)
+		}
 
-			if len(es.kibanaSecrets) > 0 {
-				toCreate = append(toCreate, secret.ToRuntimeObjects(es.kibanaSecrets...)...)
-			}
+		if es.cfg.SnapshotRepositorySecret != nil {
+			toCreate = append(toCreate, es.snapshotRepositorySecret())
+		} else {
+			toDelete = append(toDelete, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: SnapshotRepositorySecretName, Namespace: ElasticsearchNamespace}})
+		}
 
-			toCreate = append(toCreate, es.kibanaCR())
-
-			// Curator CRs
-			// If we have the curator secrets then create curator
-			if len(es.cfg.CuratorSecrets) > 0 {
-				toCreate = append(toCreate, es.esCuratorAllowTigeraPolicy())
-				toCreate = append(toCreate, secret.ToRuntimeObjects(secret.CopyToNamespace(ElasticsearchNamespace, es.cfg.CuratorSecrets...)...)...)
-				toCreate = append(toCreate, es.esCuratorServiceAccount())
-
-				// If the provider is not OpenShift apply the pod security policy for the curator.
-				if es.cfg.Provider != operatorv1.ProviderOpenShift {
-					toCreate = append(toCreate,
-						es.curatorClusterRole(),
-						es.curatorClusterRoleBinding())
-					if es.cfg.UsePSP {
-						toCreate = append(toCreate, es.curatorPodSecurityPolicy())
-					}
+		if len(es.cfg.SecureSettingsSecrets) > 0 {
+			copies := secret.CopyToNamespace(ElasticsearchNamespace, es.cfg.SecureSettingsSecrets...)
+			for _, s := range copies {
+				if s.Labels == nil {
+					s.Labels = map[string]string{}
 				}
-
-				toCreate = append(toCreate, es.curatorCronJob())
+				s.Labels[SecureSettingsSecretLabel] = "true"
 			}
+			toCreate = append(toCreate, secret.ToRuntimeObjects(copies...)...)
+		}
+
+		if es.kibanaOIDCEnabled() {
+			toCreate = append(toCreate, secret.ToRuntimeObjects(secret.CopyToNamespace(ElasticsearchNamespace, es.cfg.DexSecret)...)...)
 		} else {
-			if es.cfg.KeyStoreSecret != nil {
-				if operatorv1.IsFIPSModeEnabled(es.cfg.Installation.FIPSMode) {
-					es.cfg.KeyStoreSecret.Data["ES_JAVA_OPTS"] = []byte(es.javaOpts())
-				}
+			toDelete = append(toDelete, &corev1.Secret{
+				TypeMeta:   metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+				ObjectMeta: metav1.ObjectMeta{Name: DexObjectName, Namespace: ElasticsearchNamespace},
+			})
+		}
 
-				toCreate = append(toCreate, es.cfg.KeyStoreSecret)
-				toCreate = append(toCreate, secret.ToRuntimeObjects(secret.CopyToNamespace(ElasticsearchNamespace, es.cfg.KeyStoreSecret)...)...)
-			}
-			toDelete = append(toDelete, es.kibanaCR())
-			toDelete = append(toDelete, es.curatorCronJob())
+		toCreate = append(toCreate, es.elasticsearchServiceAccount())
+		toCreate = append(toCreate, es.cfg.ClusterConfig.ConfigMap())
+
+		toCreate = append(toCreate, es.elasticsearchCluster())
+		// Without a configured budget, the health-aware default PodDisruptionBudget of ECK is used.
+		if es.elasticsearchPodDisruptionBudgetConfig() != nil {
+			toCreate = append(toCreate, es.elasticsearchPodDisruptionBudget())
+		} else if es.cfg.RemoveElasticsearchPodDisruptionBudget {
+			toDelete = append(toDelete, es.elasticsearchPodDisruptionBudget())
 		}
 
-		toCreate = append(toCreate, es.oidcUserRole())
-		toCreate = append(toCreate, es.oidcUserRoleBinding())
+		if operatorv1.IsFIPSModeEnabled(es.cfg.Installation.FIPSMode) && es.cfg.KeyStoreSecret != nil {
+			es.cfg.KeyStoreSecret.Data["ES_JAVA_OPTS"] = []byte(es.javaOpts())
+			toCreate = append(toCreate, es.cfg.KeyStoreSecret)
+			toCreate = append(toCreate, secret.ToRuntimeObjects(secret.CopyToNamespace(ElasticsearchNamespace, es.cfg.KeyStoreSecret)...)...)
+		}
 
 		// If we converted from a ManagedCluster to a Standalone or Management then we need to delete the elasticsearch
 		// service as it differs between these cluster types
 		if es.cfg.ESService != nil && es.cfg.ESService.Spec.Type == corev1.ServiceTypeExternalName {
 			toDelete = append(toDelete, es.cfg.ESService)
 		}
-
-		if es.cfg.KbService != nil && es.cfg.KbService.Spec.Type == corev1.ServiceTypeExternalName {
-			toDelete = append(toDelete, es.cfg.KbService)
-		}
 	} else {
 		toCreate = append(toCreate,
 			CreateNamespace(ElasticsearchNamespace, es.cfg.Installation.KubernetesProvider, PSSPrivileged),
@@ -433,6 +874,25 @@ func (es *elasticsearchComponent) Objects() ([]client.Object, []client.Object) {
 		)
 	}
 
+	return toCreate, toDelete
+}
+
+// oidcUserRBACObjects returns the Role and RoleBinding that allow the manager to manage the OIDC users.
+func (es *elasticsearchComponent) oidcUserRBACObjects() ([]client.Object, []client.Object) {
+	if es.deleting() || es.cfg.ManagementClusterConnection != nil {
+		return nil, nil
+	}
+	return []client.Object{es.oidcUserRole(), es.oidcUserRoleBinding()}, nil
+}
+
+// elasticsearchCertificateObjects returns the TLS secret that is rendered in place of the certificate of Elasticsearch
+// to pass the checks of ECK when certificate management is used.
+func (es *elasticsearchComponent) elasticsearchCertificateObjects() ([]client.Object, []client.Object) {
+	if es.deleting() {
+		return nil, nil
+	}
+
+	var toCreate, toDelete []client.Object
 	if es.cfg.Installation.CertificateManagement != nil {
 		toCreate = append(toCreate, es.cfg.UnusedTLSSecret)
 		if es.cfg.ElasticsearchKeyPair.UseCertificateManagement() {
@@ -442,13 +902,6 @@ func (es *elasticsearchComponent) Objects() ([]client.Object, []client.Object) {
 			unusedSecret.Data = es.cfg.UnusedTLSSecret.Data
 			toCreate = append(toCreate, unusedSecret)
 		}
-		if es.cfg.KibanaKeyPair != nil && es.cfg.KibanaKeyPair.UseCertificateManagement() {
-			// We need to render a secret. It won't ever be used by Kibana for TLS, but is needed to pass ECK's checks.
-			// If the secret changes / gets reconciled, it will not trigger a re-render of Kibana.
-			unusedSecret := es.cfg.KibanaKeyPair.Secret(KibanaNamespace)
-			unusedSecret.Data = es.cfg.UnusedTLSSecret.Data
-			toCreate = append(toCreate, unusedSecret)
-		}
 	} else if es.cfg.UnusedTLSSecret != nil {
 		toDelete = append(toDelete, es.cfg.UnusedTLSSecret)
 	}
@@ -456,6 +909,207 @@ func (es *elasticsearchComponent) Objects() ([]client.Object, []client.Object) {
 	return toCreate, toDelete
 }
 
+// kibanaClusterObjects returns the resources of Kibana.
+func (es *elasticsearchComponent) kibanaClusterObjects() ([]client.Object, []client.Object) {
+	var toCreate, toDelete []client.Object
+
+	if es.deleting() {
+		if es.cfg.Kibana != nil && es.cfg.Kibana.DeletionTimestamp == nil {
+			toDelete = append(toDelete, es.cfg.Kibana)
+		}
+		return toCreate, toDelete
+	}
+
+	if es.cfg.ManagementClusterConnection == nil {
+		// In order to use restricted, we need to change:
+		// - securityContext.allowPrivilegeEscalation=false)
+		// - securityContext.capabilities.drop=["ALL"]
+		// - securityContext.runAsNonRoot=true
+		// - securityContext.seccompProfile.type to "RuntimeDefault" or "Localhost"
+		toCreate = append(toCreate, CreateNamespace(KibanaNamespace, es.cfg.Installation.KubernetesProvider, PSSBaseline))
+		toCreate = append(toCreate, es.kibanaAllowTigeraPolicy())
+		toCreate = append(toCreate, networkpolicy.AllowTigeraDefaultDeny(KibanaNamespace))
+		toCreate = append(toCreate, es.kibanaServiceAccount())
+
+		if len(es.cfg.PullSecrets) > 0 {
+			toCreate = append(toCreate, secret.ToRuntimeObjects(secret.CopyToNamespace(KibanaNamespace, es.cfg.PullSecrets...)...)...)
+		}
+
+		if len(es.kibanaSecrets) > 0 {
+			toCreate = append(toCreate, secret.ToRuntimeObjects(es.kibanaSecrets...)...)
+		}
+
+		if es.kibanaElasticsearchHostsOverridden() {
+			toCreate = append(toCreate, es.cfg.KibanaElasticsearchUserSecret)
+			toCreate = append(toCreate, secret.ToRuntimeObjects(secret.CopyToNamespace(KibanaNamespace, es.cfg.KibanaElasticsearchUserSecret)...)...)
+		} else {
+			// The secret in the operator namespace is deleted by the controller, once it has deleted the user.
+			toDelete = append(toDelete, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: KibanaElasticsearchUserSecret, Namespace: KibanaNamespace}})
+		}
+
+		toCreate = append(toCreate, es.kibanaCR())
+		toCreate = append(toCreate, es.kibanaPodDisruptionBudget())
+
+		if es.cfg.KbService != nil && es.cfg.KbService.Spec.Type == corev1.ServiceTypeExternalName {
+			toDelete = append(toDelete, es.cfg.KbService)
+		}
+	}
+
+	return toCreate, toDelete
+}
+
+// kibanaCertificateObjects returns the TLS secret that is rendered in place of the certificate of Kibana to pass the
+// checks of ECK when certificate management is used.
+func (es *elasticsearchComponent) kibanaCertificateObjects() ([]client.Object, []client.Object) {
+	if es.deleting() {
+		return nil, nil
+	}
+
+	var toCreate []client.Object
+	if es.cfg.Installation.CertificateManagement != nil && es.cfg.KibanaKeyPair != nil && es.cfg.KibanaKeyPair.UseCertificateManagement() {
+		// We need to render a secret. It won't ever be used by Kibana for TLS, but is needed to pass ECK's checks.
+		// If the secret changes / gets reconciled, it will not trigger a re-render of Kibana.
+		unusedSecret := es.cfg.KibanaKeyPair.Secret(KibanaNamespace)
+		unusedSecret.Data = es.cfg.UnusedTLSSecret.Data
+		toCreate = append(toCreate, unusedSecret)
+	}
+
+	return toCreate, nil
+}
+
+// snapshotSecureSettings returns the secure settings that add the credentials of the snapshot repository to the
+// keystore of Elasticsearch, as the settings of the default client of the repository plugin.
+func (es *elasticsearchComponent) snapshotSecureSettings() []cmnv1.SecretSource {
+	if es.cfg.SnapshotRepositorySecret == nil || es.cfg.LogStorage == nil || es.cfg.LogStorage.Spec.Snapshots == nil {
+		return nil
+	}
+	repoType := es.cfg.LogStorage.Spec.Snapshots.Repository.Type
+	var entries []cmnv1.KeyToPath
+	for _, key := range SnapshotRepositoryCredentialKeys[repoType] {
+		entries = append(entries, cmnv1.KeyToPath{
+			Key:  key,
+			Path: fmt.Sprintf("%s.client.default.%s", strings.ToLower(string(repoType)), key),
+		})
+	}
+	return []cmnv1.SecretSource{{SecretName: SnapshotRepositorySecretName, Entries: entries}}
+}
+
+// snapshotRepositorySecret returns the copy of the secret of the snapshot repository in the Elasticsearch namespace.
+func (es *elasticsearchComponent) snapshotRepositorySecret() *corev1.Secret {
+	s := secret.CopyToNamespace(ElasticsearchNamespace, es.cfg.SnapshotRepositorySecret)[0]
+	s.TypeMeta = metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"}
+	s.Name = SnapshotRepositorySecretName
+	return s
+}
+
+// secureSettings returns the secure settings that add the keys of the secrets of the SecureSettings of the LogStorage
+// to the keystore of Elasticsearch.
+func (es *elasticsearchComponent) secureSettings() []cmnv1.SecretSource {
+	if es.cfg.LogStorage == nil {
+		return nil
+	}
+	var sources []cmnv1.SecretSource
+	for _, source := range es.cfg.LogStorage.Spec.SecureSettings {
+		var entries []cmnv1.KeyToPath
+		for _, entry := range source.Entries {
+			entries = append(entries, cmnv1.KeyToPath{Key: entry.Key, Path: entry.Setting})
+		}
+		sources = append(sources, cmnv1.SecretSource{SecretName: source.SecretName, Entries: entries})
+	}
+	return sources
+}
+
+// kibanaOIDCEnabled returns whether Kibana logs users in through Dex, the identity provider of the manager. It
+// requires Dex to proxy an OIDC provider, and the domain of the manager to redirect to. Users log in to Kibana with
+// their Elasticsearch password in FIPS mode.
+func (es elasticsearchComponent) kibanaOIDCEnabled() bool {
+	auth := es.cfg.Authentication
+	return auth != nil && auth.Spec.OIDC != nil && auth.Spec.OIDC.Type != operatorv1.OIDCTypeTigera &&
+		es.cfg.DexSecret != nil && es.cfg.BaseURL != "" && !operatorv1.IsFIPSModeEnabled(es.cfg.Installation.FIPSMode)
+}
+
+// kibanaOIDCRealmConfig returns the settings of the OIDC realm of Elasticsearch that Kibana logs users in with, and of
+// the file and native realms that are otherwise enabled implicitly. The roles of the users are those of the native
+// users that are created for the known OIDC users.
+func (es elasticsearchComponent) kibanaOIDCRealmConfig() map[string]interface{} {
+	realm := fmt.Sprintf("xpack.security.authc.realms.oidc.%s", kibanaOIDCRealm)
+	issuer := fmt.Sprintf("%s/dex", es.cfg.BaseURL)
+	kibanaURL := fmt.Sprintf("%s/%s", es.cfg.BaseURL, KibanaBasePath)
+	return map[string]interface{}{
+		fmt.Sprintf("xpack.security.authc.realms.file.%s.order", kibanaFileRealm):     0,
+		fmt.Sprintf("xpack.security.authc.realms.native.%s.order", kibanaNativeRealm): 1,
+		realm + ".order":                       2,
+		realm + ".rp.client_id":                DexClientId,
+		realm + ".rp.response_type":            "code",
+		realm + ".rp.requested_scopes":         []string{"openid", "email", "profile", "groups"},
+		realm + ".rp.redirect_uri":             fmt.Sprintf("%s/api/security/oidc/callback", kibanaURL),
+		realm + ".rp.post_logout_redirect_uri": fmt.Sprintf("%s/logged_out", kibanaURL),
+		realm + ".op.issuer":                   issuer,
+		realm + ".op.authorization_endpoint":   fmt.Sprintf("%s/auth", issuer),
+		realm + ".op.token_endpoint":           fmt.Sprintf("%s/token", issuer),
+		realm + ".op.userinfo_endpoint":        fmt.Sprintf("%s/userinfo", issuer),
+		realm + ".op.jwkset_path":              fmt.Sprintf("%s/keys", issuer),
+		realm + ".claims.principal":            es.cfg.Authentication.Spec.OIDC.UsernameClaim,
+		realm + ".authorization_realms":        kibanaNativeRealm,
+	}
+}
+
+// kibanaOIDCSecureSettings returns the secure setting that adds the client secret of Dex to the keystore of
+// Elasticsearch, for the OIDC realm that Kibana logs users in with.
+func (es *elasticsearchComponent) kibanaOIDCSecureSettings() []cmnv1.SecretSource {
+	if !es.kibanaOIDCEnabled() {
+		return nil
+	}
+	return []cmnv1.SecretSource{{
+		SecretName: es.cfg.DexSecret.Name,
+		Entries: []cmnv1.KeyToPath{{
+			Key:  ClientSecretSecretField,
+			Path: fmt.Sprintf("xpack.security.authc.realms.oidc.%s.rp.client_secret", kibanaOIDCRealm),
+		}},
+	}}
+}
+
+// curatorAccessObjects returns the network policy, secrets and service account of the Elasticsearch curator.
+func (es *elasticsearchComponent) curatorAccessObjects() ([]client.Object, []client.Object) {
+	if es.deleting() || es.cfg.ManagementClusterConnection != nil {
+		return nil, nil
+	}
+
+	// If we have the curator secrets then create curator
+	var toCreate []client.Object
+	if es.curatorEnabled() && len(es.cfg.CuratorSecrets) > 0 {
+		toCreate = append(toCreate, es.esCuratorAllowTigeraPolicy())
+		toCreate = append(toCreate, secret.ToRuntimeObjects(secret.CopyToNamespace(ElasticsearchNamespace, es.cfg.CuratorSecrets...)...)...)
+		toCreate = append(toCreate, es.esCuratorServiceAccount())
+	}
+	return toCreate, nil
+}
+
+// curatorCronJobObjects returns the CronJob of the Elasticsearch curator.
+func (es *elasticsearchComponent) curatorCronJobObjects() ([]client.Object, []client.Object) {
+	if es.deleting() || es.cfg.ManagementClusterConnection != nil {
+		return nil, nil
+	}
+
+	var toCreate, toDelete []client.Object
+	if !es.curatorEnabled() {
+		// The indices are deleted by the ILM policies, remove the curator left over from older releases.
+		toDelete = append(toDelete, es.curatorCronJob())
+	} else if len(es.cfg.CuratorSecrets) > 0 {
+		toCreate = append(toCreate, es.curatorCronJob())
+	}
+	return toCreate, toDelete
+}
+
+// curatorEnabled returns whether the legacy curator CronJob is enabled in LogStorage. The curator is never enabled in
+// FIPS mode.
+func (es *elasticsearchComponent) curatorEnabled() bool {
+	if operatorv1.IsFIPSModeEnabled(es.cfg.Installation.FIPSMode) || es.cfg.LogStorage == nil {
+		return false
+	}
+	return es.cfg.LogStorage.Spec.Curator != nil && *es.cfg.LogStorage.Spec.Curator == operatorv1.CuratorEnabled
+}
+
 func (es *elasticsearchComponent) Ready() bool {
 	return true
 }
@@ -510,27 +1164,39 @@ func (es elasticsearchComponent) pvcTemplate() corev1.PersistentVolumeClaim {
 }
 
 func (es elasticsearchComponent) resourceRequirements() corev1.ResourceRequirements {
-	resources := corev1.ResourceRequirements{
-		Limits: corev1.ResourceList{
-			"cpu":    resource.MustParse("1"),
-			"memory": resource.MustParse("4Gi"),
-		},
-		Requests: corev1.ResourceList{
-			"cpu":    resource.MustParse("250m"),
-			"memory": resource.MustParse("4Gi"),
-		},
+	var userOverrides *corev1.ResourceRequirements
+	if es.cfg.LogStorage.Spec.Nodes != nil {
+		userOverrides = es.cfg.LogStorage.Spec.Nodes.ResourceRequirements
 	}
-	if es.cfg.LogStorage.Spec.Nodes != nil && es.cfg.LogStorage.Spec.Nodes.ResourceRequirements != nil {
-		userOverrides := *es.cfg.LogStorage.Spec.Nodes.ResourceRequirements
-		resources = overrideResourceRequirements(resources, userOverrides)
+	return componentResourceRequirements(es.presetsProvider(), resourceDefaultsElasticsearch, userOverrides)
+}
+
+// containerAwareHeapPercentage returns the percentage of the memory limit of the container to use for the JVM heap,
+// and whether the heap is sized by the JVM from the limit rather than set to a fixed size. The heap is only sized by
+// the JVM when the container has a memory limit, since the JVM would otherwise size it from the memory of the node.
+func (es elasticsearchComponent) containerAwareHeapPercentage(resources corev1.ResourceRequirements) (int32, bool) {
+	nodes := es.cfg.LogStorage.Spec.Nodes
+	if nodes == nil || nodes.JVMHeap == nil || nodes.JVMHeap.Sizing != operatorv1.JVMHeapSizingContainerAware {
+		return 0, false
+	}
+	if resources.Limits.Memory().IsZero() {
+		return 0, false
 	}
-	return resources
+	if nodes.JVMHeap.MaxRAMPercentage != nil {
+		return *nodes.JVMHeap.MaxRAMPercentage, true
+	}
+	return defaultJVMHeapMaxRAMPercentage, true
 }
 
 func (es elasticsearchComponent) javaOpts() string {
 	var javaOpts string
 	resources := es.resourceRequirements()
-	if es.cfg.LogStorage.Spec.Nodes != nil && es.cfg.LogStorage.Spec.Nodes.ResourceRequirements != nil {
+	_, providerPreset := defaultResourceRequirements(es.presetsProvider(), resourceDefaultsElasticsearch)
+	if percentage, ok := es.containerAwareHeapPercentage(resources); ok {
+		// Let the JVM read the memory limit from the cgroup of the container (v1 or v2), so that the heap follows the
+		// limit when it changes instead of being fixed to the size computed from the request.
+		javaOpts = fmt.Sprintf("-XX:+UseContainerSupport -XX:InitialRAMPercentage=%d -XX:MaxRAMPercentage=%d", percentage, percentage)
+	} else if providerPreset || (es.cfg.LogStorage.Spec.Nodes != nil && es.cfg.LogStorage.Spec.Nodes.ResourceRequirements != nil) {
 		// Now extract the memory request value to compute the recommended heap size for ES container
 		recommendedHeapSize := memoryQuantityToJVMHeapSize(resources.Requests.Memory())
 		javaOpts = fmt.Sprintf("-Xms%v -Xmx%v", recommendedHeapSize, recommendedHeapSize)
@@ -602,6 +1268,15 @@ func (es elasticsearchComponent) podTemplate() corev1.PodTemplateSpec {
 		Env:       env,
 	}
 
+	if port := ElasticsearchHTTPPort(es.ports()); port != ElasticsearchDefaultPort {
+		// The readiness probe of the image checks the default port, so the probe only checks that Elasticsearch
+		// listens on the configured port.
+		esContainer.Ports = []corev1.ContainerPort{{Name: "https", ContainerPort: port, Protocol: corev1.ProtocolTCP}}
+		esContainer.ReadinessProbe.ProbeHandler = corev1.ProbeHandler{
+			TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt(int(port))},
+		}
+	}
+
 	// For OpenShift, set the user to run as non-root specifically. This prevents issues with the elasticsearch
 	// image which requires that root users have permissions to run CHROOT which is not given in OpenShift.
 	if es.cfg.Provider == operatorv1.ProviderOpenShift {
@@ -753,6 +1428,28 @@ func (es elasticsearchComponent) podTemplate() corev1.PodTemplateSpec {
 		)
 	}
 
+	if len(es.cfg.RemoteClusterCASecrets) > 0 {
+		var sources []corev1.VolumeProjection
+		for _, s := range es.cfg.RemoteClusterCASecrets {
+			sources = append(sources, corev1.VolumeProjection{
+				Secret: &corev1.SecretProjection{
+					LocalObjectReference: corev1.LocalObjectReference{Name: s.Name},
+					Items:                []corev1.KeyToPath{{Key: corev1.TLSCertKey, Path: path.Join(s.Name, corev1.TLSCertKey)}},
+				},
+			})
+		}
+		volumes = append(volumes, corev1.Volume{
+			Name: remoteClusterCAVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Projected: &corev1.ProjectedVolumeSource{Sources: sources},
+			},
+		})
+		esContainer.VolumeMounts = append(esContainer.VolumeMounts,
+			corev1.VolumeMount{MountPath: remoteClusterCAVolumeMountPath, Name: remoteClusterCAVolumeName, ReadOnly: true},
+		)
+		annotations[ElasticsearchRemoteClusterCAHashAnnotation] = rmeta.SecretsAnnotationHash(es.cfg.RemoteClusterCASecrets...)
+	}
+
 	// Init container that logs the SELinux context of the `/usr/share/elasticsearch` folder.
 	// This init container is added as a workaround for a bug where Elasticsearch fails to starts when
 	// under some scenarios Kubernetes starts the main container before all the init containers have
@@ -813,12 +1510,44 @@ func (es elasticsearchComponent) podTemplate() corev1.PodTemplateSpec {
 			ServiceAccountName:           "tigera-elasticsearch",
 			Volumes:                      volumes,
 			AutomountServiceAccountToken: &autoMountToken,
+			PriorityClassName:            es.cfg.LogStorage.Spec.ComponentPriorityClassName(operatorv1.ComponentNameElasticsearch),
 		},
 	}
+	if nodes := es.cfg.LogStorage.Spec.Nodes; nodes != nil {
+		applyPodMetadata(&podTemplate, nodes.PodMetadata)
+	}
+	if policy := es.cfg.LogStorage.Spec.ComponentDisruptionPolicy(operatorv1.ComponentNameElasticsearch); policy != nil {
+		// ECK owns the preStop hook of Elasticsearch, which waits for the additional time in the environment before the
+		// node is stopped.
+		if policy.PreStopDelaySeconds != nil {
+			podTemplate.Spec.Containers[0].Env = append(podTemplate.Spec.Containers[0].Env, corev1.EnvVar{
+				Name:  "PRE_STOP_ADDITIONAL_WAIT_SECONDS",
+				Value: strconv.FormatInt(*policy.PreStopDelaySeconds, 10),
+			})
+		}
+		disruptionpolicy.Apply(&podTemplate.Spec, &operatorv1.DisruptionPolicy{TerminationGracePeriodSeconds: policy.TerminationGracePeriodSeconds}, false)
+	}
+	es.cfg.ContainerOverrides.Apply("elasticsearch", &podTemplate)
 
 	return podTemplate
 }
 
+// applyPodMetadata adds the labels and annotations of the metadata to the pod template, without overriding the labels
+// and annotations that the operator sets.
+func applyPodMetadata(podTemplate *corev1.PodTemplateSpec, metadata *operatorv1.Metadata) {
+	if metadata == nil {
+		return
+	}
+	if len(metadata.Labels) > 0 {
+		podTemplate.Labels = common.MapExistsOrInitialize(podTemplate.Labels)
+		common.MergeMaps(metadata.Labels, podTemplate.Labels)
+	}
+	if len(metadata.Annotations) > 0 {
+		podTemplate.Annotations = common.MapExistsOrInitialize(podTemplate.Annotations)
+		common.MergeMaps(metadata.Annotations, podTemplate.Annotations)
+	}
+}
+
 // render the Elasticsearch CR that the ECK operator uses to create elasticsearch cluster
 func (es elasticsearchComponent) elasticsearchCluster() *esv1.Elasticsearch {
 	elasticsearch := &esv1.Elasticsearch{
@@ -840,13 +1569,65 @@ func (es elasticsearchComponent) elasticsearchCluster() *esv1.Elasticsearch {
 					},
 				},
 			},
-			NodeSets: es.nodeSets(),
+			NodeSets:       es.nodeSets(),
+			SecureSettings: append(append(es.snapshotSecureSettings(), es.secureSettings()...), es.kibanaOIDCSecureSettings()...),
 		},
 	}
 
+	if es.elasticsearchPodDisruptionBudgetConfig() != nil {
+		// The default PodDisruptionBudget of ECK is disabled in favour of the configured one rendered by the operator,
+		// since the eviction of a pod is refused when more than one PodDisruptionBudget selects it.
+		elasticsearch.Spec.PodDisruptionBudget = &cmnv1.PodDisruptionBudgetTemplate{}
+	}
+
+	if es.cfg.DeferRollouts && es.cfg.Elasticsearch != nil {
+		current := map[string]map[string]string{}
+		for _, nodeSet := range es.cfg.Elasticsearch.Spec.NodeSets {
+			current[nodeSet.Name] = nodeSet.PodTemplate.Annotations
+		}
+		for i := range elasticsearch.Spec.NodeSets {
+			// New NodeSets have no pods to restart.
+			if annotations, ok := current[elasticsearch.Spec.NodeSets[i].Name]; ok {
+				template := &elasticsearch.Spec.NodeSets[i].PodTemplate
+				template.Annotations = withHashAnnotations(template.Annotations, annotations)
+			}
+		}
+	}
+
+	if port := ElasticsearchHTTPPort(es.ports()); port != ElasticsearchDefaultPort {
+		// The service keeps the default port, which ECK and the clients of Elasticsearch connect to.
+		elasticsearch.Spec.HTTP.Service.Spec.Ports = []corev1.ServicePort{{
+			Name:       "https",
+			Port:       ElasticsearchDefaultPort,
+			TargetPort: intstr.FromInt(int(port)),
+			Protocol:   corev1.ProtocolTCP,
+		}}
+	}
+
 	return elasticsearch
 }
 
+func (es elasticsearchComponent) ports() *operatorv1.LogStoragePorts {
+	return logStoragePorts(es.cfg.LogStorage)
+}
+
+// logStoragePorts returns the ports that the Elasticsearch and Kibana pods listen on, or nil if the defaults are used.
+func logStoragePorts(ls *operatorv1.LogStorage) *operatorv1.LogStoragePorts {
+	if ls == nil {
+		return nil
+	}
+	return ls.Spec.Ports
+}
+
+// logStorageJobsDisruptionPolicy returns the disruption policy of the jobs that the operator runs against
+// Elasticsearch and Kibana, or nil if the LogStorage is nil or has none.
+func logStorageJobsDisruptionPolicy(ls *operatorv1.LogStorage) *operatorv1.DisruptionPolicy {
+	if ls == nil {
+		return nil
+	}
+	return ls.Spec.ComponentDisruptionPolicy(operatorv1.ComponentNameLogStorageJobs)
+}
+
 // Determine the recommended JVM heap size as a string (with appropriate unit suffix) based on
 // the given resource.Quantity.
 //
@@ -913,9 +1694,25 @@ func memoryQuantityToJVMHeapSize(q *resource.Quantity) string {
 	return recommendedHeapSize
 }
 
+// elasticsearchPodDisruptionBudgetConfig returns the PodDisruptionBudget of the Elasticsearch nodes that is configured
+// in the LogStorage, or nil if there is none.
+func (es elasticsearchComponent) elasticsearchPodDisruptionBudgetConfig() *operatorv1.PodDisruptionBudget {
+	if nodes := es.cfg.LogStorage.Spec.Nodes; nodes != nil && nodes.PodDisruptionBudget != nil {
+		return nodes.PodDisruptionBudget
+	}
+	return disruptionpolicy.PodDisruptionBudget(es.cfg.LogStorage.Spec.ComponentDisruptionPolicy(operatorv1.ComponentNameElasticsearch))
+}
+
+// elasticsearchPodDisruptionBudget returns the PodDisruptionBudget of the Elasticsearch nodes, so that node drains
+// don't take down more Elasticsearch nodes at once than the LogStorage allows.
+func (es elasticsearchComponent) elasticsearchPodDisruptionBudget() *policyv1.PodDisruptionBudget {
+	return poddisruptionbudget.NewPodDisruptionBudget(ElasticsearchName, ElasticsearchNamespace,
+		map[string]string{"elasticsearch.k8s.elastic.co/cluster-name": ElasticsearchName}, es.elasticsearchPodDisruptionBudgetConfig())
+}
+
 // nodeSets calculates the number of NodeSets needed for the Elasticsearch cluster. Multiple NodeSets are returned only
-// if the "nodeSets" field has been set in the LogStorage CR. The number of Nodes for the cluster will be distributed as
-// evenly as possible between the NodeSets.
+// if the "nodeSets" field has been set in the LogStorage CR, or if the nodes are spread over the zones of the K8s nodes.
+// The number of Nodes for the cluster will be distributed as evenly as possible between the NodeSets.
 func (es elasticsearchComponent) nodeSets() []esv1.NodeSet {
 	nodeConfig := es.cfg.LogStorage.Spec.Nodes
 	pvcTemplate := es.pvcTemplate()
@@ -929,9 +1726,11 @@ func (es elasticsearchComponent) nodeSets() []esv1.NodeSet {
 	}
 
 	var nodeSets []esv1.NodeSet
-	if nodeConfig.NodeSets == nil || len(nodeConfig.NodeSets) < 1 {
+	if es.zoneNodeSetsEnabled() {
+		nodeSets = es.zoneNodeSets(pvcTemplate)
+	} else if nodeConfig.NodeSets == nil || len(nodeConfig.NodeSets) < 1 {
 		nodeSet := es.nodeSetTemplate(pvcTemplate)
-		nodeSet.Name = nodeSetName(pvcTemplate)
+		nodeSet.Name = es.expandedNodeSetName(pvcTemplate, "")
 		nodeSet.Count = int32(nodeConfig.Count)
 		nodeSet.PodTemplate = es.podTemplate()
 
@@ -953,9 +1752,10 @@ func (es elasticsearchComponent) nodeSets() []esv1.NodeSet {
 				break
 			}
 
-			nodeSet := es.nodeSetTemplate(pvcTemplate)
+			nodeSetPVCTemplate := nodeSetPVCTemplate(pvcTemplate, nodeSetConfig)
+			nodeSet := es.nodeSetTemplate(nodeSetPVCTemplate)
 			// Each NodeSet needs a unique name, so just add the index as a suffix
-			nodeSet.Name = fmt.Sprintf("%s-%d", nodeSetName(pvcTemplate), i)
+			nodeSet.Name = es.expandedNodeSetName(nodeSetPVCTemplate, fmt.Sprintf("-%d", i))
 			nodeSet.Count = int32(numNodes)
 
 			podTemplate := es.podTemplate()
@@ -997,15 +1797,277 @@ func (es elasticsearchComponent) nodeSets() []esv1.NodeSet {
 				}
 			}
 
+			// The tolerations and affinity of the NodeSet let different NodeSets target different node pools, e.g. a
+			// tainted pool of storage-optimized nodes.
+			if nodeSetConfig.Tolerations != nil {
+				podTemplate.Spec.Tolerations = nodeSetConfig.Tolerations
+			}
+			if nodeSetConfig.Affinity != nil {
+				podTemplate.Spec.Affinity = nodeSetConfig.Affinity
+			}
+
 			nodeSet.PodTemplate = podTemplate
 
-			nodeSets = append(nodeSets, nodeSet)
+			if nodeSetConfig.PodTemplatePatch != nil {
+				patched, err := ApplyPodTemplatePatch(nodeSet.PodTemplate, nodeSetConfig.PodTemplatePatch)
+				if err != nil {
+					// The patch is validated before rendering, so this shouldn't happen.
+					log.Error(err, "Failed to apply the pod template patch of the NodeSet", "nodeSet", i)
+				} else {
+					nodeSet.PodTemplate = patched
+				}
+			}
+
+			if nodeSetConfig.SelectionAttributes == nil && es.zoneAwarenessEnabled() && len(es.cfg.Zones) > 0 {
+				nodeSets = append(nodeSets, es.splitNodeSetByZone(nodeSet)...)
+			} else {
+				nodeSets = append(nodeSets, nodeSet)
+			}
+		}
+	}
+
+	return nodeSets
+}
+
+// nodeSetPVCTemplate returns the PVC template of the NodeSet, which overrides the storage class and size of the
+// PVC template of the cluster if they are set for the NodeSet. Since the name of the NodeSet is derived from its PVC
+// template, changing them creates a new NodeSet, unless only the storage size is increased on a StorageClass that allows
+// volume expansion.
+func nodeSetPVCTemplate(pvcTemplate corev1.PersistentVolumeClaim, nodeSetConfig operatorv1.NodeSet) corev1.PersistentVolumeClaim {
+	if nodeSetConfig.StorageClassName == "" && nodeSetConfig.StorageSize == nil {
+		return pvcTemplate
+	}
+
+	nodeSetPVCTemplate := *pvcTemplate.DeepCopy()
+	if nodeSetConfig.StorageClassName != "" {
+		nodeSetPVCTemplate.Spec.StorageClassName = &nodeSetConfig.StorageClassName
+	}
+	if nodeSetConfig.StorageSize != nil {
+		nodeSetPVCTemplate.Spec.Resources.Requests[corev1.ResourceStorage] = *nodeSetConfig.StorageSize
+	}
+	return nodeSetPVCTemplate
+}
+
+// ApplyPodTemplatePatch applies the strategic merge patch of a NodeSet to the pod template that the operator generated
+// for it. The service account, and the images, commands, arguments and security contexts of the containers and init
+// containers and the volumes of the generated template are protected: they are restored after the patch is applied,
+// also when the patch deletes them.
+func ApplyPodTemplatePatch(template corev1.PodTemplateSpec, patch *runtime.RawExtension) (corev1.PodTemplateSpec, error) {
+	original, err := json.Marshal(template)
+	if err != nil {
+		return template, err
+	}
+	patchedJSON, err := strategicpatch.StrategicMergePatch(original, patch.Raw, corev1.PodTemplateSpec{})
+	if err != nil {
+		return template, fmt.Errorf("failed to apply the pod template patch: %w", err)
+	}
+	var patched corev1.PodTemplateSpec
+	if err := json.Unmarshal(patchedJSON, &patched); err != nil {
+		return template, fmt.Errorf("the patched pod template is invalid: %w", err)
+	}
+
+	patched.Spec.ServiceAccountName = template.Spec.ServiceAccountName
+	patched.Spec.InitContainers = protectContainers(patched.Spec.InitContainers, template.Spec.InitContainers)
+	patched.Spec.Containers = protectContainers(patched.Spec.Containers, template.Spec.Containers)
+
+	volumes := append([]corev1.Volume{}, template.Spec.Volumes...)
+	for _, v := range patched.Spec.Volumes {
+		if !hasVolume(template.Spec.Volumes, v.Name) {
+			volumes = append(volumes, v)
+		}
+	}
+	patched.Spec.Volumes = volumes
+	return patched, nil
+}
+
+// protectContainers returns the generated containers, patched except for their protected fields, followed by the
+// containers that the patch added.
+func protectContainers(patched, generated []corev1.Container) []corev1.Container {
+	var containers []corev1.Container
+	for _, g := range generated {
+		c := g
+		for _, p := range patched {
+			if p.Name == g.Name {
+				c = p
+				c.Image, c.Command, c.Args, c.SecurityContext = g.Image, g.Command, g.Args, g.SecurityContext
+				break
+			}
+		}
+		containers = append(containers, c)
+	}
+	for _, p := range patched {
+		generatedContainer := false
+		for _, g := range generated {
+			if p.Name == g.Name {
+				generatedContainer = true
+				break
+			}
+		}
+		if !generatedContainer {
+			containers = append(containers, p)
+		}
+	}
+	return containers
+}
+
+func hasVolume(volumes []corev1.Volume, name string) bool {
+	for _, v := range volumes {
+		if v.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (es elasticsearchComponent) zoneAwarenessEnabled() bool {
+	nodes := es.cfg.LogStorage.Spec.Nodes
+	return nodes != nil && nodes.ZoneAwareness == operatorv1.ZoneAwarenessEnabled
+}
+
+// zoneNodeSetsEnabled returns whether the Elasticsearch nodes are spread over one NodeSet per zone when no NodeSets are
+// set: either the zone awareness is Enabled and the K8s nodes that Elasticsearch can run on have zones, or it is Auto,
+// they are in several zones, and the current Elasticsearch cluster, if any, already has zone NodeSets.
+func (es elasticsearchComponent) zoneNodeSetsEnabled() bool {
+	nodes := es.cfg.LogStorage.Spec.Nodes
+	if nodes == nil || len(nodes.NodeSets) != 0 {
+		return false
+	}
+	if nodes.ZoneAwareness == operatorv1.ZoneAwarenessEnabled {
+		return len(es.cfg.Zones) > 0
+	}
+	if len(es.cfg.Zones) < 2 || (nodes.ZoneAwareness != "" && nodes.ZoneAwareness != operatorv1.ZoneAwarenessAuto) {
+		return false
+	}
+	if es.cfg.Elasticsearch == nil {
+		return true
+	}
+	for _, current := range es.cfg.Elasticsearch.Spec.NodeSets {
+		if current.Config == nil {
+			continue
+		}
+		if _, ok := current.Config.Data[fmt.Sprintf("node.attr.%s", zoneAwarenessAttribute)]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// zoneNodeSets returns one NodeSet per zone, with the Elasticsearch nodes distributed as evenly as possible between
+// them. The pods of a NodeSet are scheduled on the K8s nodes of its zone, and the zone is set as an attribute of its
+// Elasticsearch nodes so that the replicas of a shard are allocated to other zones than the primary shard.
+func (es elasticsearchComponent) zoneNodeSets(pvcTemplate corev1.PersistentVolumeClaim) []esv1.NodeSet {
+	count := es.cfg.LogStorage.Spec.Nodes.Count
+	zones := int64(len(es.cfg.Zones))
+
+	var nodeSets []esv1.NodeSet
+	for i, zone := range es.cfg.Zones {
+		numNodes := count / zones
+		if int64(i) < count%zones {
+			numNodes++
+		}
+		if numNodes < 1 {
+			break
 		}
+
+		nodeSet := es.nodeSetTemplate(pvcTemplate)
+		// The zone is part of the name, so that adding or removing a zone doesn't rename the NodeSets of the others.
+		nodeSet.Name = es.expandedNodeSetName(pvcTemplate, "-"+zoneNodeSetSuffix(zone))
+		nodeSet.Count = int32(numNodes)
+		nodeSet.PodTemplate = es.podTemplate()
+		setNodeSetZone(&nodeSet, zone)
+
+		nodeSets = append(nodeSets, nodeSet)
 	}
+	return nodeSets
+}
+
+// splitNodeSetByZone spreads the Elasticsearch nodes of the NodeSet over one copy of the NodeSet per zone, the same
+// way as zoneNodeSets does for the Elasticsearch nodes of the cluster.
+func (es elasticsearchComponent) splitNodeSetByZone(nodeSet esv1.NodeSet) []esv1.NodeSet {
+	zones := int32(len(es.cfg.Zones))
 
+	var nodeSets []esv1.NodeSet
+	for i, zone := range es.cfg.Zones {
+		numNodes := nodeSet.Count / zones
+		if int32(i) < nodeSet.Count%zones {
+			numNodes++
+		}
+		if numNodes < 1 {
+			break
+		}
+
+		zoneNodeSet := *nodeSet.DeepCopy()
+		zoneNodeSet.Name = nodeSet.Name + "-" + zoneNodeSetSuffix(zone)
+		zoneNodeSet.Count = numNodes
+		setNodeSetZone(&zoneNodeSet, zone)
+
+		nodeSets = append(nodeSets, zoneNodeSet)
+	}
 	return nodeSets
 }
 
+// setNodeSetZone schedules the pods of the NodeSet on the K8s nodes of the zone, in addition to the nodes that its
+// affinity already requires, and sets the zone as an attribute of its Elasticsearch nodes so that the replicas of a
+// shard are allocated to other zones than the primary shard.
+func setNodeSetZone(nodeSet *esv1.NodeSet, zone string) {
+	nodeSet.Config.Data[fmt.Sprintf("node.attr.%s", zoneAwarenessAttribute)] = zone
+	nodeSet.Config.Data["cluster.routing.allocation.awareness.attributes"] = zoneAwarenessAttribute
+
+	zoneRequirement := corev1.NodeSelectorRequirement{
+		Key:      corev1.LabelTopologyZone,
+		Operator: corev1.NodeSelectorOpIn,
+		Values:   []string{zone},
+	}
+	podSpec := &nodeSet.PodTemplate.Spec
+	if podSpec.Affinity == nil {
+		podSpec.Affinity = &corev1.Affinity{}
+	}
+	if podSpec.Affinity.NodeAffinity == nil {
+		podSpec.Affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	required := podSpec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if required == nil || len(required.NodeSelectorTerms) == 0 {
+		podSpec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{zoneRequirement}}},
+		}
+		return
+	}
+	// The terms are ORed, so the zone is required by each of them.
+	for i := range required.NodeSelectorTerms {
+		required.NodeSelectorTerms[i].MatchExpressions = append(required.NodeSelectorTerms[i].MatchExpressions, zoneRequirement)
+	}
+}
+
+// zoneNodeSetSuffix returns the zone in a form that can be used in the name of a NodeSet.
+func zoneNodeSetSuffix(zone string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' {
+			return r
+		}
+		return '-'
+	}, strings.ToLower(zone))
+}
+
+// ipv6WildcardAddress is the address that Elasticsearch and Kibana listen on in IPv6-only clusters, instead of the
+// IPv4 wildcard address that ECK configures by default.
+const ipv6WildcardAddress = "::"
+
+// ipv6Only returns whether the cluster only has IPv6 pools. Dual-stack clusters keep the IPv4 defaults, since IPv4 is the
+// primary family of their services unless configured otherwise.
+func (es elasticsearchComponent) ipv6Only() bool {
+	cn := es.cfg.Installation.CalicoNetwork
+	return cn != nil && GetIPv6Pool(cn.IPPools) != nil && GetIPv4Pool(cn.IPPools) == nil
+}
+
+// eckOperatorIPFamilyArgs returns the arguments that make the ECK operator format the addresses of Elasticsearch and its
+// probes for IPv6 in IPv6-only clusters, instead of detecting the family from the IP of its own pod.
+func (es elasticsearchComponent) eckOperatorIPFamilyArgs() []string {
+	if es.ipv6Only() {
+		return []string{"--ip-family=IPv6"}
+	}
+	return nil
+}
+
 // nodeSetTemplate returns a NodeSet with default values needed for all Elasticsearch cluster setups.
 //
 // Note that this does not return a complete NodeSet, fields like Name and Count will at least need to be set on the returned
@@ -1026,6 +2088,56 @@ func (es elasticsearchComponent) nodeSetTemplate(pvcTemplate corev1.PersistentVo
 		config["xpack.security.authc.password_hashing.algorithm"] = "pbkdf2_stretch"
 	}
 
+	if es.ipv6Only() {
+		config["network.host"] = ipv6WildcardAddress
+	}
+
+	if port := ElasticsearchHTTPPort(es.ports()); port != ElasticsearchDefaultPort {
+		config["http.port"] = port
+	}
+
+	for _, rc := range es.cfg.LogStorage.Spec.RemoteClusters {
+		if rc.ProxyAddress == "" {
+			config[fmt.Sprintf("cluster.remote.%s.seeds", rc.Alias)] = rc.Seeds
+			continue
+		}
+		config[fmt.Sprintf("cluster.remote.%s.mode", rc.Alias)] = "proxy"
+		config[fmt.Sprintf("cluster.remote.%s.proxy_address", rc.Alias)] = rc.ProxyAddress
+		if rc.ServerName != "" {
+			config[fmt.Sprintf("cluster.remote.%s.server_name", rc.Alias)] = rc.ServerName
+		}
+	}
+	if es.kibanaOIDCEnabled() {
+		for key, value := range es.kibanaOIDCRealmConfig() {
+			config[key] = value
+		}
+	}
+
+	if len(es.cfg.RemoteClusterCASecrets) > 0 {
+		cas := append([]string{}, eckTransportCAPaths...)
+		for _, s := range es.cfg.RemoteClusterCASecrets {
+			cas = append(cas, path.Join(remoteClusterCAVolumeMountPath, s.Name, corev1.TLSCertKey))
+		}
+		config["xpack.security.transport.ssl.certificate_authorities"] = cas
+	}
+
+	if tls := es.cfg.LogStorage.Spec.TransportTLS; tls != nil {
+		if tls.VerificationMode != nil {
+			config["xpack.security.transport.ssl.verification_mode"] = strings.ToLower(string(*tls.VerificationMode))
+		}
+		if len(tls.SupportedProtocols) > 0 {
+			config["xpack.security.transport.ssl.supported_protocols"] = tls.SupportedProtocols
+		}
+		if len(tls.CipherSuites) > 0 {
+			config["xpack.security.transport.ssl.cipher_suites"] = tls.CipherSuites
+		}
+	}
+
+	// The extra configuration of the user is merged last, without overriding the settings that the operator manages.
+	if nodes := es.cfg.LogStorage.Spec.Nodes; nodes != nil {
+		mergeExtraConfig(config, nodes.ExtraConfig, nil, "Elasticsearch")
+	}
+
 	return esv1.NodeSet{
 		// This is configuration that ends up in /usr/share/elasticsearch/config/elasticsearch.yml on the Elastic container.
 		Config: &cmnv1.Config{
@@ -1055,6 +2167,42 @@ func nodeSetName(pvcTemplate corev1.PersistentVolumeClaim) string {
 	return hex.EncodeToString(pvcTemplateHash.Sum(nil))
 }
 
+// expandedNodeSetName returns the name of the NodeSet with the PVC template and the name suffix. If the NodeSet of an
+// existing Elasticsearch cluster has the same PVC template but less or the same storage, and the StorageClass allows
+// volume expansion, the name of the existing NodeSet is returned, so that its volumes are expanded instead of being
+// replaced, and the NodeSet keeps its name once they are expanded.
+func (es elasticsearchComponent) expandedNodeSetName(pvcTemplate corev1.PersistentVolumeClaim, suffix string) string {
+	name := nodeSetName(pvcTemplate) + suffix
+	if es.cfg.Elasticsearch == nil || pvcTemplate.Spec.StorageClassName == nil || !es.cfg.ExpandableStorageClasses[*pvcTemplate.Spec.StorageClassName] {
+		return name
+	}
+
+	for _, current := range es.cfg.Elasticsearch.Spec.NodeSets {
+		if current.Name == name {
+			return name
+		}
+	}
+
+	storage := pvcTemplate.Spec.Resources.Requests[corev1.ResourceStorage]
+	for _, current := range es.cfg.Elasticsearch.Spec.NodeSets {
+		if !strings.HasSuffix(current.Name, suffix) || len(current.VolumeClaimTemplates) != 1 {
+			continue
+		}
+		currentPVCTemplate := current.VolumeClaimTemplates[0]
+		currentStorage, ok := currentPVCTemplate.Spec.Resources.Requests[corev1.ResourceStorage]
+		if !ok || storage.Cmp(currentStorage) < 0 {
+			continue
+		}
+		// The PVC templates must only differ in their storage, since nothing else can be changed in place.
+		resized := *pvcTemplate.DeepCopy()
+		resized.Spec.Resources.Requests[corev1.ResourceStorage] = currentStorage
+		if nodeSetName(resized) == nodeSetName(currentPVCTemplate) {
+			return current.Name
+		}
+	}
+	return name
+}
+
 func (es elasticsearchComponent) eckOperatorClusterRole() *rbacv1.ClusterRole {
 	rules := []rbacv1.PolicyRule{
 		{
@@ -1202,17 +2350,42 @@ func (es elasticsearchComponent) esCuratorServiceAccount() *corev1.ServiceAccoun
 	}
 }
 
+// logStorageComponentResources returns the resource requirements of the component, with the overrides of the
+// LogStorage componentResources applied.
+func (es elasticsearchComponent) logStorageComponentResources(component resourceDefaultsComponent, name operatorv1.LogStorageComponentName) corev1.ResourceRequirements {
+	var userOverrides *corev1.ResourceRequirements
+	for _, c := range es.cfg.LogStorage.Spec.ComponentResources {
+		if c.ComponentName == name {
+			userOverrides = c.ResourceRequirements
+		}
+	}
+	return componentResourceRequirements(es.presetsProvider(), component, userOverrides)
+}
+
+// presetsProvider returns the provider whose resource presets are used for the log storage components.
+func (es elasticsearchComponent) presetsProvider() operatorv1.Provider {
+	return presetsProvider(es.cfg.Provider, es.cfg.LogStorage.Spec.ProviderResourcePresets)
+}
+
 func (es elasticsearchComponent) eckOperatorStatefulSet() *appsv1.StatefulSet {
 	gracePeriod := int64(10)
-	memoryLimit := resource.Quantity{}
-	memoryRequest := resource.Quantity{}
-	for _, c := range es.cfg.LogStorage.Spec.ComponentResources {
-		if c.ComponentName == operatorv1.ComponentNameECKOperator {
-			memoryLimit = c.ResourceRequirements.Limits[corev1.ResourceMemory]
-			memoryRequest = c.ResourceRequirements.Requests[corev1.ResourceMemory]
+	nodeSelector := es.cfg.Installation.ControlPlaneNodeSelector
+	tolerations := es.cfg.Installation.ControlPlaneTolerations
+	resources := es.logStorageComponentResources(resourceDefaultsECKOperator, operatorv1.ComponentNameECKOperator)
+	var priorityClassName string
+	if overrides := es.cfg.LogStorage.Spec.ECKOperator; overrides != nil {
+		if overrides.NodeSelector != nil {
+			nodeSelector = overrides.NodeSelector
 		}
+		if overrides.Tolerations != nil {
+			tolerations = overrides.Tolerations
+		}
+		if overrides.Resources != nil {
+			resources = componentResourceRequirements(es.presetsProvider(), resourceDefaultsECKOperator, overrides.Resources)
+		}
+		priorityClassName = overrides.PriorityClassName
 	}
-	return &appsv1.StatefulSet{
+	sts := &appsv1.StatefulSet{
 		TypeMeta: metav1.TypeMeta{Kind: "StatefulSet", APIVersion: "apps/v1"},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      ECKOperatorName,
@@ -1247,13 +2420,14 @@ func (es elasticsearchComponent) eckOperatorStatefulSet() *appsv1.StatefulSet {
 					ServiceAccountName: "elastic-operator",
 					ImagePullSecrets:   secret.GetReferenceList(es.cfg.PullSecrets),
 					HostNetwork:        false,
-					NodeSelector:       es.cfg.Installation.ControlPlaneNodeSelector,
-					Tolerations:        es.cfg.Installation.ControlPlaneTolerations,
+					NodeSelector:       nodeSelector,
+					Tolerations:        tolerations,
+					PriorityClassName:  priorityClassName,
 					Containers: []corev1.Container{{
 						Image: es.esOperatorImage,
 						Name:  "manager",
 						// Verbosity level of logs. -2=Error, -1=Warn, 0=Info, 0 and above=Debug
-						Args: []string{
+						Args: append([]string{
 							"manager",
 							"--namespaces=tigera-elasticsearch,tigera-kibana",
 							"--log-verbosity=0",
@@ -1266,7 +2440,7 @@ func (es elasticsearchComponent) eckOperatorStatefulSet() *appsv1.StatefulSet {
 							"--cert-rotate-before=24h",
 							"--enable-webhook=false",
 							"--manage-webhook-certs=false",
-						},
+						}, es.eckOperatorIPFamilyArgs()...),
 						Env: []corev1.EnvVar{
 							{
 								Name: "OPERATOR_NAMESPACE",
@@ -1278,22 +2452,44 @@ func (es elasticsearchComponent) eckOperatorStatefulSet() *appsv1.StatefulSet {
 							},
 							{Name: "OPERATOR_IMAGE", Value: es.esOperatorImage},
 						},
-						Resources: corev1.ResourceRequirements{
-							Limits: corev1.ResourceList{
-								"cpu":    resource.MustParse("1"),
-								"memory": memoryLimit,
-							},
-							Requests: corev1.ResourceList{
-								"cpu":    resource.MustParse("100m"),
-								"memory": memoryRequest,
-							},
-						},
+						Resources: resources,
 					}},
 					TerminationGracePeriodSeconds: &gracePeriod,
 				},
 			},
 		},
 	}
+	if es.eckWebhookEnabled() {
+		es.applyECKWebhook(&sts.Spec.Template)
+	}
+	// The ECK operator runs a single pod, so the PodDisruptionBudget of its disruption policy isn't rendered.
+	disruptionpolicy.Apply(&sts.Spec.Template.Spec, es.cfg.LogStorage.Spec.ComponentDisruptionPolicy(operatorv1.ComponentNameECKOperator), false)
+	es.cfg.ContainerOverrides.Apply(ECKOperatorName, &sts.Spec.Template)
+
+	return sts
+}
+
+// applyECKWebhook makes the ECK operator serve its validating webhook with the certificate of the webhook, which the
+// operator issues instead of ECK.
+func (es elasticsearchComponent) applyECKWebhook(template *corev1.PodTemplateSpec) {
+	keyPair := es.cfg.ECKWebhookKeyPair
+	container := &template.Spec.Containers[0]
+	for i, arg := range container.Args {
+		if arg == "--enable-webhook=false" {
+			container.Args[i] = "--enable-webhook=true"
+		}
+	}
+	container.Args = append(container.Args,
+		fmt.Sprintf("--webhook-cert-dir=%s", keyPair.VolumeMount(rmeta.OSTypeLinux).MountPath),
+		fmt.Sprintf("--webhook-port=%d", ECKWebhookPort),
+	)
+	container.Ports = append(container.Ports, corev1.ContainerPort{Name: "https-webhook", ContainerPort: ECKWebhookPort, Protocol: corev1.ProtocolTCP})
+	container.VolumeMounts = append(container.VolumeMounts, keyPair.VolumeMount(rmeta.OSTypeLinux))
+	template.Spec.Volumes = append(template.Spec.Volumes, keyPair.Volume())
+	if keyPair.UseCertificateManagement() {
+		template.Spec.InitContainers = append(template.Spec.InitContainers, keyPair.InitContainer(ECKOperatorNamespace))
+	}
+	template.Annotations[keyPair.HashAnnotationKey()] = keyPair.HashAnnotationValue()
 }
 
 func (es elasticsearchComponent) eckOperatorPodSecurityPolicy() *policyv1beta1.PodSecurityPolicy {
@@ -1311,6 +2507,29 @@ func (es elasticsearchComponent) kibanaServiceAccount() *corev1.ServiceAccount {
 	}
 }
 
+// kibanaPodDisruptionBudget returns the PodDisruptionBudget of the Kibana pods.
+func (es elasticsearchComponent) kibanaPodDisruptionBudget() *policyv1.PodDisruptionBudget {
+	var cfg *operatorv1.PodDisruptionBudget
+	if es.cfg.LogStorage.Spec.Kibana != nil {
+		cfg = es.cfg.LogStorage.Spec.Kibana.PodDisruptionBudget
+	}
+	if cfg == nil {
+		cfg = disruptionpolicy.PodDisruptionBudget(es.cfg.LogStorage.Spec.ComponentDisruptionPolicy(operatorv1.ComponentNameKibana))
+	}
+	return poddisruptionbudget.NewPodDisruptionBudget(KibanaName, KibanaNamespace, map[string]string{"k8s-app": KibanaName}, cfg)
+}
+
+// kibanaFIPSCipherSuites are the OpenSSL names of the cipher suites approved by FIPS 140-2 that Kibana is restricted to
+// in FIPS mode, in order of preference.
+var kibanaFIPSCipherSuites = []string{
+	"TLS_AES_256_GCM_SHA384",
+	"TLS_AES_128_GCM_SHA256",
+	"ECDHE-ECDSA-AES256-GCM-SHA384",
+	"ECDHE-RSA-AES256-GCM-SHA384",
+	"ECDHE-ECDSA-AES128-GCM-SHA256",
+	"ECDHE-RSA-AES128-GCM-SHA256",
+}
+
 func (es elasticsearchComponent) kibanaCR() *kbv1.Kibana {
 	server := map[string]interface{}{
 		"basePath":        fmt.Sprintf("/%s", KibanaBasePath),
@@ -1321,6 +2540,13 @@ func (es elasticsearchComponent) kibanaCR() *kbv1.Kibana {
 	if es.cfg.BaseURL != "" {
 		server["publicBaseUrl"] = fmt.Sprintf("%s/%s", es.cfg.BaseURL, KibanaBasePath)
 	}
+	kibanaPort := KibanaHTTPPort(es.ports())
+	if kibanaPort != KibanaPort {
+		server["port"] = kibanaPort
+	}
+	if es.ipv6Only() {
+		server["host"] = ipv6WildcardAddress
+	}
 
 	config := map[string]interface{}{
 		"elasticsearch.ssl.certificateAuthorities": []string{"/usr/share/kibana/config/elasticsearch-certs/tls.crt"},
@@ -1332,6 +2558,32 @@ func (es elasticsearchComponent) kibanaCR() *kbv1.Kibana {
 		},
 	}
 
+	if operatorv1.IsFIPSModeEnabled(es.cfg.Installation.FIPSMode) {
+		// Kibana only accepts the TLS versions and cipher suites approved by FIPS 140-2. The extra config can't override
+		// these settings.
+		server["ssl"] = map[string]interface{}{
+			"supportedProtocols": []string{"TLSv1.2", "TLSv1.3"},
+			"cipherSuites":       kibanaFIPSCipherSuites,
+		}
+	}
+
+	if es.kibanaOIDCEnabled() {
+		// Users log in through the identity provider of the manager, and the Elasticsearch users can still log in with
+		// their password.
+		config["xpack.security.authc.providers"] = map[string]interface{}{
+			"oidc": map[string]interface{}{
+				kibanaOIDCRealm: map[string]interface{}{
+					"order":       0,
+					"realm":       kibanaOIDCRealm,
+					"description": "Log in with the identity provider",
+				},
+			},
+			"basic": map[string]interface{}{
+				"basic1": map[string]interface{}{"order": 1},
+			},
+		}
+	}
+
 	var initContainers []corev1.Container
 	var volumes []corev1.Volume
 	var automountToken bool
@@ -1371,10 +2623,49 @@ func (es elasticsearchComponent) kibanaCR() *kbv1.Kibana {
 			})
 	}
 
+	var env []corev1.EnvVar
+	if es.kibanaElasticsearchHostsOverridden() {
+		// Kibana authenticates with the user that the operator manages rather than the one that ECK creates for the
+		// association, and verifies the certificates of the hosts with the operator CA.
+		config["elasticsearch.hosts"] = es.cfg.LogStorage.Spec.Kibana.ElasticsearchHosts
+		config["elasticsearch.username"] = string(es.cfg.KibanaElasticsearchUserSecret.Data["username"])
+		config["elasticsearch.password"] = "${ELASTICSEARCH_PASSWORD}"
+		config["elasticsearch.ssl.certificateAuthorities"] = []string{es.cfg.TrustedBundle.MountPath()}
+		env = append(env, corev1.EnvVar{
+			Name: "ELASTICSEARCH_PASSWORD",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: KibanaElasticsearchUserSecret},
+					Key:                  "password",
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, es.cfg.TrustedBundle.VolumeMount(rmeta.OSTypeLinux))
+		volumes = append(volumes, es.cfg.TrustedBundle.Volume())
+	}
+
 	count := int32(1)
 	if es.cfg.Installation.ControlPlaneReplicas != nil {
 		count = *es.cfg.Installation.ControlPlaneReplicas
 	}
+	nodeSelector := es.cfg.Installation.ControlPlaneNodeSelector
+	tolerations := es.cfg.Installation.ControlPlaneTolerations
+	resources := es.logStorageComponentResources(resourceDefaultsKibana, operatorv1.ComponentNameKibana)
+	if overrides := es.cfg.LogStorage.Spec.Kibana; overrides != nil {
+		if overrides.Replicas != nil {
+			count = *overrides.Replicas
+		}
+		if overrides.NodeSelector != nil {
+			nodeSelector = overrides.NodeSelector
+		}
+		if overrides.Tolerations != nil {
+			tolerations = overrides.Tolerations
+		}
+		if overrides.Resources != nil {
+			resources = componentResourceRequirements(es.presetsProvider(), resourceDefaultsKibana, overrides.Resources)
+		}
+		mergeExtraConfig(config, overrides.ExtraConfig, kibanaDefaultConfig, "Kibana")
+	}
 
 	kibana := &kbv1.Kibana{
 		TypeMeta: metav1.TypeMeta{Kind: "Kibana", APIVersion: "kibana.k8s.elastic.co/v1"},
@@ -1420,18 +2711,21 @@ func (es elasticsearchComponent) kibanaCR() *kbv1.Kibana {
 				Spec: corev1.PodSpec{
 					ImagePullSecrets:             secret.GetReferenceList(es.cfg.PullSecrets),
 					ServiceAccountName:           "tigera-kibana",
-					NodeSelector:                 es.cfg.Installation.ControlPlaneNodeSelector,
-					Tolerations:                  es.cfg.Installation.ControlPlaneTolerations,
+					NodeSelector:                 nodeSelector,
+					Tolerations:                  tolerations,
 					InitContainers:               initContainers,
 					AutomountServiceAccountToken: &automountToken,
+					PriorityClassName:            es.cfg.LogStorage.Spec.ComponentPriorityClassName(operatorv1.ComponentNameKibana),
 					Containers: []corev1.Container{{
-						Name: "kibana",
+						Name:      "kibana",
+						Env:       env,
+						Resources: resources,
 						ReadinessProbe: &corev1.Probe{
 							ProbeHandler: corev1.ProbeHandler{
 								HTTPGet: &corev1.HTTPGetAction{
 									Path: fmt.Sprintf("/%s/login", KibanaBasePath),
 									Port: intstr.IntOrString{
-										IntVal: KibanaPort,
+										IntVal: kibanaPort,
 									},
 									Scheme: corev1.URISchemeHTTPS,
 								},
@@ -1450,14 +2744,113 @@ func (es elasticsearchComponent) kibanaCR() *kbv1.Kibana {
 		},
 	}
 
-	if es.cfg.Installation.ControlPlaneReplicas != nil && *es.cfg.Installation.ControlPlaneReplicas > 1 {
+	if count > 1 {
 		kibana.Spec.PodTemplate.Spec.Affinity = podaffinity.NewPodAntiAffinity(KibanaName, KibanaNamespace)
 	}
+	if es.kibanaElasticsearchHostsOverridden() {
+		kibana.Spec.ElasticsearchRef = cmnv1.ObjectSelector{}
+	}
+	if overrides := es.cfg.LogStorage.Spec.Kibana; overrides != nil {
+		applyPodMetadata(&kibana.Spec.PodTemplate, overrides.PodMetadata)
+	}
+	if kibanaPort != KibanaPort {
+		// The service keeps the default port, which the clients of Kibana connect to.
+		kibana.Spec.HTTP.Service.Spec.Ports = []corev1.ServicePort{{
+			Name:       "https",
+			Port:       KibanaPort,
+			TargetPort: intstr.FromInt(int(kibanaPort)),
+			Protocol:   corev1.ProtocolTCP,
+		}}
+		kibana.Spec.PodTemplate.Spec.Containers[0].Ports = []corev1.ContainerPort{{Name: "https", ContainerPort: kibanaPort, Protocol: corev1.ProtocolTCP}}
+	}
+	disruptionpolicy.Apply(&kibana.Spec.PodTemplate.Spec, es.cfg.LogStorage.Spec.ComponentDisruptionPolicy(operatorv1.ComponentNameKibana), true)
+	es.cfg.ContainerOverrides.Apply("kibana", &kibana.Spec.PodTemplate)
+	if es.cfg.DeferRollouts && es.cfg.Kibana != nil {
+		kibana.Spec.PodTemplate.Annotations = withHashAnnotations(kibana.Spec.PodTemplate.Annotations, es.cfg.Kibana.Spec.PodTemplate.Annotations)
+	}
 
 	return kibana
 }
 
-func (es elasticsearchComponent) curatorCronJob() *batchv1beta.CronJob {
+// kibanaElasticsearchHostsOverridden returns whether Kibana connects to the Elasticsearch hosts of the LogStorage
+// instead of the Elasticsearch cluster that ECK associates it with.
+func (es elasticsearchComponent) kibanaElasticsearchHostsOverridden() bool {
+	kibana := es.cfg.LogStorage.Spec.Kibana
+	return kibana != nil && len(kibana.ElasticsearchHosts) > 0 && es.cfg.KibanaElasticsearchUserSecret != nil
+}
+
+// kibanaDefaultConfig are the settings of the Kibana configuration that the operator sets, but that the extra
+// configuration of the user can override.
+var kibanaDefaultConfig = map[string]bool{
+	"xpack.security.session.lifespan": true,
+}
+
+// mergeExtraConfig adds the extra configuration of the user to the configuration of a component. The settings that
+// the operator manages, except the overridable ones, are not overridden. Neither are the settings nested under them
+// or the settings that they are nested under, which would replace them in the YAML of the configuration.
+func mergeExtraConfig(config map[string]interface{}, extraConfig map[string]string, overridable map[string]bool, component string) {
+	var managedKeys []string
+	for key := range config {
+		if !overridable[key] {
+			managedKeys = append(managedKeys, key)
+		}
+	}
+	for key, value := range extraConfig {
+		managed := false
+		for _, managedKey := range managedKeys {
+			if key == managedKey || strings.HasPrefix(key, managedKey+".") || strings.HasPrefix(managedKey, key+".") {
+				managed = true
+				break
+			}
+		}
+		if managed {
+			log.Info(fmt.Sprintf("Ignoring extra %s configuration managed by the operator", component), "setting", key)
+			continue
+		}
+		config[key] = value
+	}
+}
+
+// curatorCronJob returns the curator CronJob, with the batch/v1 API when the cluster serves it and with the
+// batch/v1beta1 API otherwise.
+func (es elasticsearchComponent) curatorCronJob() client.Object {
+	const schedule = "@hourly"
+
+	meta := metav1.ObjectMeta{
+		Name:      EsCuratorName,
+		Namespace: ElasticsearchNamespace,
+	}
+	jobMeta := metav1.ObjectMeta{
+		Name: EsCuratorName,
+		Labels: map[string]string{
+			"k8s-app": EsCuratorName,
+		},
+	}
+
+	if es.cfg.UseBatchV1CronJobs {
+		return &batchv1.CronJob{
+			TypeMeta:   metav1.TypeMeta{Kind: "CronJob", APIVersion: "batch/v1"},
+			ObjectMeta: meta,
+			Spec: batchv1.CronJobSpec{
+				Schedule:    schedule,
+				Suspend:     &es.cfg.CuratorSuspended,
+				JobTemplate: batchv1.JobTemplateSpec{ObjectMeta: jobMeta, Spec: es.curatorJobSpec()},
+			},
+		}
+	}
+	return &batchv1beta.CronJob{
+		TypeMeta:   metav1.TypeMeta{Kind: "CronJob", APIVersion: "batch/v1beta1"},
+		ObjectMeta: meta,
+		Spec: batchv1beta.CronJobSpec{
+			Schedule:    schedule,
+			Suspend:     &es.cfg.CuratorSuspended,
+			JobTemplate: batchv1beta.JobTemplateSpec{ObjectMeta: jobMeta, Spec: es.curatorJobSpec()},
+		},
+	}
+}
+
+// curatorJobSpec returns the spec of the Jobs of the curator CronJob.
+func (es elasticsearchComponent) curatorJobSpec() batchv1.JobSpec {
 	f := false
 	t := true
 	elasticCuratorLivenessProbe := &corev1.Probe{
@@ -1474,63 +2867,45 @@ func (es elasticsearchComponent) curatorCronJob() *batchv1beta.CronJob {
 		},
 	}
 
-	const schedule = "@hourly"
-
-	return &batchv1beta.CronJob{
-		TypeMeta: metav1.TypeMeta{
-			Kind:       "CronJob",
-			APIVersion: "batch/v1",
-		},
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      EsCuratorName,
-			Namespace: ElasticsearchNamespace,
-		},
-		Spec: batchv1beta.CronJobSpec{
-			Schedule: schedule,
-			JobTemplate: batchv1beta.JobTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: EsCuratorName,
-					Labels: map[string]string{
-						"k8s-app": EsCuratorName,
-					},
+	jobSpec := batchv1.JobSpec{
+		Template: corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					"k8s-app": EsCuratorName,
 				},
-				Spec: batchv1.JobSpec{
-					Template: corev1.PodTemplateSpec{
-						ObjectMeta: metav1.ObjectMeta{
-							Labels: map[string]string{
-								"k8s-app": EsCuratorName,
-							},
+			},
+			Spec: corev1.PodSpec{
+				NodeSelector: es.cfg.Installation.ControlPlaneNodeSelector,
+				Tolerations:  es.cfg.Installation.ControlPlaneTolerations,
+				Containers: []corev1.Container{
+					relasticsearch.ContainerDecorate(corev1.Container{
+						Name:          EsCuratorName,
+						Image:         es.curatorImage,
+						Env:           es.curatorEnvVars(),
+						LivenessProbe: elasticCuratorLivenessProbe,
+						Resources:     es.logStorageComponentResources(resourceDefaultsCurator, operatorv1.ComponentNameEsCurator),
+						SecurityContext: &corev1.SecurityContext{
+							RunAsNonRoot:             &t,
+							AllowPrivilegeEscalation: &f,
 						},
-						Spec: corev1.PodSpec{
-							NodeSelector: es.cfg.Installation.ControlPlaneNodeSelector,
-							Tolerations:  es.cfg.Installation.ControlPlaneTolerations,
-							Containers: []corev1.Container{
-								relasticsearch.ContainerDecorate(corev1.Container{
-									Name:          EsCuratorName,
-									Image:         es.curatorImage,
-									Env:           es.curatorEnvVars(),
-									LivenessProbe: elasticCuratorLivenessProbe,
-									SecurityContext: &corev1.SecurityContext{
-										RunAsNonRoot:             &t,
-										AllowPrivilegeEscalation: &f,
-									},
-									VolumeMounts: []corev1.VolumeMount{
-										es.cfg.TrustedBundle.VolumeMount(es.SupportedOSType()),
-									},
-								}, DefaultElasticsearchClusterName, ElasticsearchCuratorUserSecret, es.cfg.ClusterDomain, es.SupportedOSType()),
-							},
-							ImagePullSecrets:   secret.GetReferenceList(es.cfg.PullSecrets),
-							RestartPolicy:      corev1.RestartPolicyOnFailure,
-							ServiceAccountName: EsCuratorServiceAccount,
-							Volumes: []corev1.Volume{
-								es.cfg.TrustedBundle.Volume(),
-							},
+						VolumeMounts: []corev1.VolumeMount{
+							es.cfg.TrustedBundle.VolumeMount(es.SupportedOSType()),
 						},
-					},
+					}, es.cfg.ClusterConfig.ClusterName(), ElasticsearchCuratorUserSecret, es.cfg.ClusterDomain, es.SupportedOSType()),
+				},
+				ImagePullSecrets:   secret.GetReferenceList(es.cfg.PullSecrets),
+				RestartPolicy:      corev1.RestartPolicyOnFailure,
+				ServiceAccountName: EsCuratorServiceAccount,
+				DNSConfig:          es.cfg.LogStorage.Spec.DNSConfig,
+				PriorityClassName:  es.cfg.LogStorage.Spec.ComponentPriorityClassName(operatorv1.ComponentNameEsCurator),
+				Volumes: []corev1.Volume{
+					es.cfg.TrustedBundle.Volume(),
 				},
 			},
 		},
 	}
+	disruptionpolicy.Apply(&jobSpec.Template.Spec, es.cfg.LogStorage.Spec.ComponentDisruptionPolicy(operatorv1.ComponentNameEsCurator), true)
+	return jobSpec
 }
 
 func (es elasticsearchComponent) curatorEnvVars() []corev1.EnvVar {
@@ -1541,7 +2916,7 @@ func (es elasticsearchComponent) curatorEnvVars() []corev1.EnvVar {
 		{Name: "EE_COMPLIANCE_REPORT_INDEX_RETENTION_PERIOD", Value: fmt.Sprint(*es.cfg.LogStorage.Spec.Retention.ComplianceReports)},
 		{Name: "EE_DNS_INDEX_RETENTION_PERIOD", Value: fmt.Sprint(*es.cfg.LogStorage.Spec.Retention.DNSLogs)},
 		{Name: "EE_BGP_INDEX_RETENTION_PERIOD", Value: fmt.Sprint(*es.cfg.LogStorage.Spec.Retention.BGPLogs)},
-		{Name: "EE_MAX_TOTAL_STORAGE_PCT", Value: fmt.Sprint(maxTotalStoragePercent)},
+		{Name: "EE_MAX_TOTAL_STORAGE_PCT", Value: fmt.Sprint(MaxTotalStoragePercent)},
 		{Name: "EE_MAX_LOGS_STORAGE_PCT", Value: fmt.Sprint(maxLogsStoragePercent)},
 	}
 }
@@ -1589,6 +2964,19 @@ func (es elasticsearchComponent) curatorPodSecurityPolicy() *policyv1beta1.PodSe
 	return psp
 }
 
+// logStoragePriorityClass returns the PriorityClass of the log storage pods. It never preempts other pods, so it only
+// favors the log storage in the scheduling queue and when the kubelet evicts pods under node pressure.
+func (es elasticsearchComponent) logStoragePriorityClass() *schedulingv1.PriorityClass {
+	preemptNever := corev1.PreemptNever
+	return &schedulingv1.PriorityClass{
+		TypeMeta:         metav1.TypeMeta{Kind: "PriorityClass", APIVersion: "scheduling.k8s.io/v1"},
+		ObjectMeta:       metav1.ObjectMeta{Name: LogStoragePriorityClassName},
+		Value:            LogStoragePriorityClassValue,
+		PreemptionPolicy: &preemptNever,
+		Description:      "Used for the Elasticsearch, Kibana and curator pods of the Tigera log storage.",
+	}
+}
+
 // Applying this in the eck namespace will start a trial license for enterprise features.
 func (es elasticsearchComponent) elasticEnterpriseTrial() *corev1.Secret {
 	return &corev1.Secret{
@@ -1605,6 +2993,24 @@ func (es elasticsearchComponent) elasticEnterpriseTrial() *corev1.Secret {
 	}
 }
 
+// elasticEnterpriseLicense returns the copy of the enterprise license of the LogStorage in the eck namespace, where the
+// ECK operator picks it up and applies it to the Elasticsearch cluster.
+func (es elasticsearchComponent) elasticEnterpriseLicense() *corev1.Secret {
+	return &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ECKEnterpriseLicense,
+			Namespace: ECKOperatorNamespace,
+			Labels: map[string]string{
+				"license.k8s.elastic.co/scope": "operator",
+			},
+		},
+		Data: map[string][]byte{
+			ECKLicenseKey: es.cfg.EnterpriseLicenseSecret.Data[ECKLicenseKey],
+		},
+	}
+}
+
 func (es elasticsearchComponent) elasticsearchClusterRole() *rbacv1.ClusterRole {
 	return &rbacv1.ClusterRole{
 		ObjectMeta: metav1.ObjectMeta{
@@ -1759,11 +3165,11 @@ func (es *elasticsearchComponent) eckOperatorAllowTigeraPolicy() *v3.NetworkPoli
 		{
 			Action:      v3.Allow,
 			Protocol:    &networkpolicy.TCPProtocol,
-			Destination: ElasticsearchEntityRule,
+			Destination: ElasticsearchPortEntityRule(ElasticsearchHTTPPort(es.ports())),
 		},
 	}...)
 
-	return &v3.NetworkPolicy{
+	policy := &v3.NetworkPolicy{
 		TypeMeta: metav1.TypeMeta{Kind: "NetworkPolicy", APIVersion: "projectcalico.org/v3"},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      ECKOperatorPolicyName,
@@ -1777,6 +3183,16 @@ func (es *elasticsearchComponent) eckOperatorAllowTigeraPolicy() *v3.NetworkPoli
 			Egress:   egressRules,
 		},
 	}
+	if es.eckWebhookEnabled() {
+		// The API server calls the webhook from the network of the control plane nodes.
+		policy.Spec.Types = []v3.PolicyType{v3.PolicyTypeIngress, v3.PolicyTypeEgress}
+		policy.Spec.Ingress = []v3.Rule{{
+			Action:      v3.Allow,
+			Protocol:    &networkpolicy.TCPProtocol,
+			Destination: v3.EntityRule{Ports: networkpolicy.Ports(ECKWebhookPort)},
+		}}
+	}
+	return policy
 }
 
 // Allow access to Elasticsearch client nodes from Kibana, ECK Operator and ES Gateway.
@@ -1800,9 +3216,11 @@ func (es *elasticsearchComponent) elasticsearchAllowTigeraPolicy() *v3.NetworkPo
 			Destination: networkpolicy.KubeAPIServerServiceSelectorEntityRule,
 		},
 	}...)
+	egressRules = append(egressRules, es.remoteClusterEgressRules()...)
+	egressRules = append(egressRules, es.snapshotEgressRules()...)
 
 	elasticSearchIngressDestinationEntityRule := v3.EntityRule{
-		Ports: networkpolicy.Ports(ElasticsearchDefaultPort),
+		Ports: networkpolicy.Ports(uint16(ElasticsearchHTTPPort(es.ports()))),
 	}
 	return &v3.NetworkPolicy{
 		TypeMeta: metav1.TypeMeta{Kind: "NetworkPolicy", APIVersion: "projectcalico.org/v3"},
@@ -1846,6 +3264,59 @@ func (es *elasticsearchComponent) elasticsearchAllowTigeraPolicy() *v3.NetworkPo
 	}
 }
 
+// remoteClusterEgressRules allows Elasticsearch to connect to the seeds and the proxy addresses of the remote clusters
+// used for cross-cluster search. Addresses that are not valid host:port addresses are skipped, as they are rejected by
+// the controller.
+func (es *elasticsearchComponent) remoteClusterEgressRules() []v3.Rule {
+	var rules []v3.Rule
+	for _, rc := range es.cfg.LogStorage.Spec.RemoteClusters {
+		addresses := rc.Seeds
+		if rc.ProxyAddress != "" {
+			addresses = []string{rc.ProxyAddress}
+		}
+		for _, address := range addresses {
+			host, port, err := net.SplitHostPort(address)
+			if err != nil {
+				continue
+			}
+			parsedPort, err := numorstring.PortFromString(port)
+			if err != nil {
+				continue
+			}
+			destination := v3.EntityRule{Ports: []numorstring.Port{parsedPort}}
+			if ip := net.ParseIP(host); ip == nil {
+				destination.Domains = []string{host}
+			} else if ip.To4() != nil {
+				destination.Nets = []string{ip.String() + "/32"}
+			} else {
+				destination.Nets = []string{ip.String() + "/128"}
+			}
+			rules = append(rules, v3.Rule{
+				Action:      v3.Allow,
+				Protocol:    &networkpolicy.TCPProtocol,
+				Destination: destination,
+			})
+		}
+	}
+	return rules
+}
+
+// snapshotEgressRules returns the rules that allow Elasticsearch to store the snapshots of the LogStorage in the object
+// store of its repository.
+func (es *elasticsearchComponent) snapshotEgressRules() []v3.Rule {
+	if es.cfg.LogStorage.Spec.Snapshots == nil {
+		return nil
+	}
+	return []v3.Rule{{
+		Action:   v3.Allow,
+		Protocol: &networkpolicy.TCPProtocol,
+		Destination: v3.EntityRule{
+			Domains: snapshotRepositoryDomains[es.cfg.LogStorage.Spec.Snapshots.Repository.Type],
+			Ports:   networkpolicy.Ports(443),
+		},
+	}}
+}
+
 // Allow internal communication within the ElasticSearch cluster
 func (es *elasticsearchComponent) elasticsearchInternalAllowTigeraPolicy() *v3.NetworkPolicy {
 	return &v3.NetworkPolicy{
@@ -1889,7 +3360,7 @@ func (es *elasticsearchComponent) kibanaAllowTigeraPolicy() *v3.NetworkPolicy {
 			Action:      v3.Allow,
 			Protocol:    &networkpolicy.TCPProtocol,
 			Source:      v3.EntityRule{},
-			Destination: ElasticsearchEntityRule,
+			Destination: ElasticsearchPortEntityRule(ElasticsearchHTTPPort(es.ports())),
 		},
 	}
 	egressRules = networkpolicy.AppendDNSEgressRules(egressRules, es.cfg.Provider == operatorv1.ProviderOpenShift)
@@ -1907,7 +3378,7 @@ func (es *elasticsearchComponent) kibanaAllowTigeraPolicy() *v3.NetworkPolicy {
 	}...)
 
 	kibanaPortIngressDestination := v3.EntityRule{
-		Ports: networkpolicy.Ports(KibanaPort),
+		Ports: networkpolicy.Ports(uint16(KibanaHTTPPort(es.ports()))),
 	}
 	return &v3.NetworkPolicy{
 		TypeMeta: metav1.TypeMeta{Kind: "NetworkPolicy", APIVersion: "projectcalico.org/v3"},
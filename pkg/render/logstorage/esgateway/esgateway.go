@@ -16,6 +16,7 @@ package esgateway
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/tigera/operator/pkg/render/intrusiondetection/dpi"
@@ -27,6 +28,7 @@ import (
 	"github.com/tigera/operator/pkg/common"
 	"github.com/tigera/operator/pkg/render/common/elasticsearch"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -36,8 +38,10 @@ import (
 	operatorv1 "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/components"
 	"github.com/tigera/operator/pkg/render"
+	"github.com/tigera/operator/pkg/render/common/disruptionpolicy"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
 	"github.com/tigera/operator/pkg/render/common/podaffinity"
+	"github.com/tigera/operator/pkg/render/common/poddisruptionbudget"
 	"github.com/tigera/operator/pkg/render/common/secret"
 	"github.com/tigera/operator/pkg/tls/certificatemanagement"
 )
@@ -52,9 +56,15 @@ const (
 	KibanaPortName        = "es-gateway-kibana-port"
 	Port                  = 5554
 
+	EsAdminUserSecretHashAnnotation = "hash.operator.tigera.io/elasticsearch-admin-user"
+
 	ElasticsearchHTTPSEndpoint = "https://tigera-secure-es-http.tigera-elasticsearch.svc:9200"
 
 	KibanaHTTPSEndpoint = "https://tigera-secure-kb-http.tigera-kibana.svc:5601"
+
+	// DefaultTargetCPUUtilizationPercentage is the CPU utilization that the autoscaler of the gateway maintains by
+	// default.
+	DefaultTargetCPUUtilizationPercentage = 80
 )
 
 func EsGateway(c *Config) render.Component {
@@ -78,6 +88,32 @@ type Config struct {
 	TrustedBundle              certificatemanagement.TrustedBundle
 	ClusterDomain              string
 	EsAdminUserName            string
+	EsAdminUserSecret          *corev1.Secret
+
+	// Spec holds the limits, timeouts, replicas and resources of the gateway. It may be nil, in which case the defaults
+	// of the gateway are used.
+	Spec *operatorv1.ESGatewaySpec
+
+	// Ports are the ports that the Elasticsearch and Kibana pods listen on. It may be nil, in which case the default
+	// ports are used.
+	Ports *operatorv1.LogStoragePorts
+
+	// FluentdUserSecret holds the credentials of the Elasticsearch user of fluentd. Fluentd authenticates to the
+	// gateway with the tokens of its service accounts, which the gateway swaps for these credentials. It may be nil,
+	// in which case the gateway doesn't accept service account tokens.
+	FluentdUserSecret *corev1.Secret
+
+	// DisruptionPolicy defines how the pods of the gateway are stopped and how many of them are disrupted at once. It
+	// may be nil, in which case the pods are stopped with the defaults and have no PodDisruptionBudget.
+	DisruptionPolicy *operatorv1.DisruptionPolicy
+
+	// RemovePodDisruptionBudget is set if the PodDisruptionBudget of the gateway exists while the disruption policy no
+	// longer defines one, so that it is deleted.
+	RemovePodDisruptionBudget bool
+
+	// RemoveHPA is set if the HorizontalPodAutoscaler of the gateway exists while autoscaling is disabled, so that it
+	// is deleted.
+	RemoveHPA bool
 }
 
 func (e *esGateway) ResolveImages(is *operatorv1.ImageSet) error {
@@ -109,8 +145,31 @@ func (e *esGateway) Objects() (toCreate, toDelete []client.Object) {
 	toCreate = append(toCreate, e.esGatewayService())
 	toCreate = append(toCreate, e.esGatewayRole())
 	toCreate = append(toCreate, e.esGatewayRoleBinding())
+	if e.cfg.FluentdUserSecret != nil {
+		toCreate = append(toCreate, secret.ToRuntimeObjects(secret.CopyToNamespace(render.ElasticsearchNamespace, e.cfg.FluentdUserSecret)...)...)
+	} else {
+		toDelete = append(toDelete, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchLogCollectorUserSecret, Namespace: render.ElasticsearchNamespace}})
+	}
+	if e.cfg.FluentdUserSecret != nil {
+		toCreate = append(toCreate, e.esGatewayClusterRole(), e.esGatewayClusterRoleBinding())
+	} else {
+		toDelete = append(toDelete, e.esGatewayClusterRole(), e.esGatewayClusterRoleBinding())
+	}
 	toCreate = append(toCreate, e.esGatewayServiceAccount())
 	toCreate = append(toCreate, e.esGatewayDeployment())
+	if e.autoscalingEnabled() {
+		toCreate = append(toCreate, e.esGatewayHorizontalPodAutoscaler())
+	} else if e.cfg.RemoveHPA {
+		toDelete = append(toDelete, &autoscalingv2.HorizontalPodAutoscaler{
+			TypeMeta:   metav1.TypeMeta{Kind: "HorizontalPodAutoscaler", APIVersion: "autoscaling/v2"},
+			ObjectMeta: metav1.ObjectMeta{Name: DeploymentName, Namespace: render.ElasticsearchNamespace},
+		})
+	}
+	if pdb := disruptionpolicy.PodDisruptionBudget(e.cfg.DisruptionPolicy); pdb != nil {
+		toCreate = append(toCreate, poddisruptionbudget.NewPodDisruptionBudget(DeploymentName, render.ElasticsearchNamespace, map[string]string{"k8s-app": DeploymentName}, pdb))
+	} else if e.cfg.RemovePodDisruptionBudget {
+		toDelete = append(toDelete, poddisruptionbudget.NewPodDisruptionBudget(DeploymentName, render.ElasticsearchNamespace, map[string]string{"k8s-app": DeploymentName}, nil))
+	}
 	// The following secret is used by the kube controllers and sent to managed clusters. It is also used by manifests in our docs.
 	if e.cfg.ESGatewayKeyPair.UseCertificateManagement() {
 		toCreate = append(toCreate, render.CreateCertificateSecret(e.cfg.Installation.CertificateManagement.CACert, elasticsearch.PublicCertSecret, common.OperatorNamespace()))
@@ -146,6 +205,40 @@ func (e esGateway) esGatewayRole() *rbacv1.Role {
 	}
 }
 
+// esGatewayClusterRole allows the gateway to validate the service account tokens that fluentd authenticates with.
+func (e esGateway) esGatewayClusterRole() *rbacv1.ClusterRole {
+	return &rbacv1.ClusterRole{
+		TypeMeta:   metav1.TypeMeta{Kind: "ClusterRole", APIVersion: "rbac.authorization.k8s.io/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: RoleName},
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups: []string{"authentication.k8s.io"},
+				Resources: []string{"tokenreviews"},
+				Verbs:     []string{"create"},
+			},
+		},
+	}
+}
+
+func (e esGateway) esGatewayClusterRoleBinding() *rbacv1.ClusterRoleBinding {
+	return &rbacv1.ClusterRoleBinding{
+		TypeMeta:   metav1.TypeMeta{Kind: "ClusterRoleBinding", APIVersion: "rbac.authorization.k8s.io/v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: RoleName},
+		RoleRef: rbacv1.RoleRef{
+			Kind:     "ClusterRole",
+			Name:     RoleName,
+			APIGroup: "rbac.authorization.k8s.io",
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      "ServiceAccount",
+				Name:      ServiceAccountName,
+				Namespace: render.ElasticsearchNamespace,
+			},
+		},
+	}
+}
+
 func (e esGateway) esGatewayRoleBinding() *rbacv1.RoleBinding {
 	return &rbacv1.RoleBinding{
 		TypeMeta: metav1.TypeMeta{Kind: "RoleBinding", APIVersion: "rbac.authorization.k8s.io/v1"},
@@ -190,6 +283,8 @@ func (e esGateway) esGatewayDeployment() *appsv1.Deployment {
 		}},
 		{Name: "ES_GATEWAY_FIPS_MODE_ENABLED", Value: operatorv1.IsFIPSModeEnabledString(e.cfg.Installation.FIPSMode)},
 	}
+	envVars = append(envVars, e.limitsEnvVars()...)
+	envVars = append(envVars, e.tokenEnvVars()...)
 
 	var initContainers []corev1.Container
 	if e.cfg.ESGatewayKeyPair.UseCertificateManagement() {
@@ -208,6 +303,10 @@ func (e esGateway) esGatewayDeployment() *appsv1.Deployment {
 
 	annotations := e.cfg.TrustedBundle.HashAnnotations()
 	annotations[e.cfg.ESGatewayKeyPair.HashAnnotationKey()] = e.cfg.ESGatewayKeyPair.HashAnnotationValue()
+	if e.cfg.EsAdminUserSecret != nil {
+		// The admin password is read from an env var, so roll the pods when the credentials are rotated.
+		annotations[EsAdminUserSecretHashAnnotation] = rmeta.SecretsAnnotationHash(e.cfg.EsAdminUserSecret)
+	}
 	podTemplate := &corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        DeploymentName,
@@ -243,9 +342,21 @@ func (e esGateway) esGatewayDeployment() *appsv1.Deployment {
 		},
 	}
 
-	if e.cfg.Installation.ControlPlaneReplicas != nil && *e.cfg.Installation.ControlPlaneReplicas > 1 {
+	// The replicas are left to the autoscaler when it is enabled, the component handler keeps the current ones.
+	replicas := e.cfg.Installation.ControlPlaneReplicas
+	if e.cfg.Spec != nil && e.cfg.Spec.Replicas != nil {
+		replicas = e.cfg.Spec.Replicas
+	}
+	if e.autoscalingEnabled() {
+		replicas = nil
+	}
+	if e.autoscalingEnabled() || (replicas != nil && *replicas > 1) {
 		podTemplate.Spec.Affinity = podaffinity.NewPodAntiAffinity(DeploymentName, render.ElasticsearchNamespace)
 	}
+	if e.cfg.Spec != nil && e.cfg.Spec.Resources != nil {
+		podTemplate.Spec.Containers[0].Resources = *e.cfg.Spec.Resources
+	}
+	disruptionpolicy.Apply(&podTemplate.Spec, e.cfg.DisruptionPolicy, false)
 
 	return &appsv1.Deployment{
 		TypeMeta: metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
@@ -261,11 +372,92 @@ func (e esGateway) esGatewayDeployment() *appsv1.Deployment {
 				Type: appsv1.RecreateDeploymentStrategyType,
 			},
 			Template: *podTemplate,
-			Replicas: e.cfg.Installation.ControlPlaneReplicas,
+			Replicas: replicas,
 		},
 	}
 }
 
+// autoscalingEnabled returns whether the replicas of the gateway are managed by a HorizontalPodAutoscaler.
+func (e esGateway) autoscalingEnabled() bool {
+	return e.cfg.Spec != nil && e.cfg.Spec.Autoscaling != nil
+}
+
+// esGatewayHorizontalPodAutoscaler scales the gateway deployment on the average CPU utilization of its pods.
+func (e esGateway) esGatewayHorizontalPodAutoscaler() *autoscalingv2.HorizontalPodAutoscaler {
+	autoscaling := e.cfg.Spec.Autoscaling
+	targetCPU := int32(DefaultTargetCPUUtilizationPercentage)
+	if autoscaling.TargetCPUUtilizationPercentage != nil {
+		targetCPU = *autoscaling.TargetCPUUtilizationPercentage
+	}
+	return &autoscalingv2.HorizontalPodAutoscaler{
+		TypeMeta: metav1.TypeMeta{Kind: "HorizontalPodAutoscaler", APIVersion: "autoscaling/v2"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      DeploymentName,
+			Namespace: render.ElasticsearchNamespace,
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       DeploymentName,
+			},
+			MinReplicas: autoscaling.MinReplicas,
+			MaxReplicas: autoscaling.MaxReplicas,
+			Metrics: []autoscalingv2.MetricSpec{
+				{
+					Type: autoscalingv2.ResourceMetricSourceType,
+					Resource: &autoscalingv2.ResourceMetricSource{
+						Name: corev1.ResourceCPU,
+						Target: autoscalingv2.MetricTarget{
+							Type:               autoscalingv2.UtilizationMetricType,
+							AverageUtilization: &targetCPU,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// tokenEnvVars returns the env vars that configure the service account tokens that the gateway accepts: their audience,
+// and the secret with the Elasticsearch credentials that the token of each service account is swapped for.
+func (e esGateway) tokenEnvVars() []corev1.EnvVar {
+	if e.cfg.FluentdUserSecret == nil {
+		return nil
+	}
+	var users []string
+	for _, sa := range []string{render.FluentdNodeName, render.FluentdNodeWindowsName} {
+		users = append(users, fmt.Sprintf("system:serviceaccount:%s:%s=%s", render.LogCollectorNamespace, sa, e.cfg.FluentdUserSecret.Name))
+	}
+	return []corev1.EnvVar{
+		{Name: "ES_GATEWAY_TOKEN_AUDIENCE", Value: render.ESGatewayTokenAudience},
+		{Name: "ES_GATEWAY_TOKEN_USERS", Value: strings.Join(users, ",")},
+	}
+}
+
+// limitsEnvVars returns the env vars that configure the limits and timeouts of the gateway. Only the limits that are
+// set in the spec are rendered, the gateway defaults the others.
+func (e esGateway) limitsEnvVars() []corev1.EnvVar {
+	var envVars []corev1.EnvVar
+	spec := e.cfg.Spec
+	if spec == nil {
+		return envVars
+	}
+	if spec.MaxConnections != nil {
+		envVars = append(envVars, corev1.EnvVar{Name: "ES_GATEWAY_MAX_CONNECTIONS", Value: strconv.Itoa(int(*spec.MaxConnections))})
+	}
+	if spec.RequestTimeout != nil {
+		envVars = append(envVars, corev1.EnvVar{Name: "ES_GATEWAY_REQUEST_TIMEOUT", Value: spec.RequestTimeout.Duration.String()})
+	}
+	if spec.IdleTimeout != nil {
+		envVars = append(envVars, corev1.EnvVar{Name: "ES_GATEWAY_IDLE_TIMEOUT", Value: spec.IdleTimeout.Duration.String()})
+	}
+	if spec.MaxBodySize != nil {
+		envVars = append(envVars, corev1.EnvVar{Name: "ES_GATEWAY_MAX_BODY_SIZE_BYTES", Value: strconv.FormatInt(spec.MaxBodySize.Value(), 10)})
+	}
+	return envVars
+}
+
 func (e esGateway) esGatewayServiceAccount() *corev1.ServiceAccount {
 	return &corev1.ServiceAccount{
 		ObjectMeta: metav1.ObjectMeta{
@@ -321,12 +513,12 @@ func (e *esGateway) esGatewayAllowTigeraPolicy() *v3.NetworkPolicy {
 		{
 			Action:      v3.Allow,
 			Protocol:    &networkpolicy.TCPProtocol,
-			Destination: render.ElasticsearchEntityRule,
+			Destination: render.ElasticsearchPortEntityRule(render.ElasticsearchHTTPPort(e.cfg.Ports)),
 		},
 		{
 			Action:      v3.Allow,
 			Protocol:    &networkpolicy.TCPProtocol,
-			Destination: render.KibanaEntityRule,
+			Destination: render.KibanaPortEntityRule(render.KibanaHTTPPort(e.cfg.Ports)),
 		},
 	}...)
 
@@ -429,6 +621,18 @@ func (e *esGateway) esGatewayAllowTigeraPolicy() *v3.NetworkPolicy {
 					Source:      dpi.DPISourceEntityRule,
 					Destination: esgatewayIngressDestinationEntityRule,
 				},
+				{
+					Action:      v3.Allow,
+					Protocol:    &networkpolicy.TCPProtocol,
+					Source:      render.LogStorageVerificationSourceEntityRule,
+					Destination: esgatewayIngressDestinationEntityRule,
+				},
+				{
+					Action:      v3.Allow,
+					Protocol:    &networkpolicy.TCPProtocol,
+					Source:      render.ArchiveRestoreSourceEntityRule,
+					Destination: esgatewayIngressDestinationEntityRule,
+				},
 				{
 					Action:      v3.Allow,
 					Protocol:    &networkpolicy.TCPProtocol,
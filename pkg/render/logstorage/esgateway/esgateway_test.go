@@ -17,6 +17,7 @@ package esgateway
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"k8s.io/apimachinery/pkg/types"
 
@@ -33,10 +34,14 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	operatorv1 "github.com/tigera/operator/api/v1"
@@ -132,6 +137,45 @@ var _ = Describe("ES Gateway rendering tests", func() {
 			compareResources(createResources, expectedResources)
 		})
 
+		It("should accept the service account tokens of fluentd", func() {
+			cfg.FluentdUserSecret = &corev1.Secret{
+				TypeMeta:   metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+				ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchLogCollectorUserSecret, Namespace: common.OperatorNamespace()},
+			}
+			component := EsGateway(cfg)
+
+			createResources, _ := component.Objects()
+			Expect(rtest.GetResource(createResources, render.ElasticsearchLogCollectorUserSecret, render.ElasticsearchNamespace, "", "v1", "Secret")).NotTo(BeNil())
+			clusterRole := rtest.GetResource(createResources, RoleName, "", "rbac.authorization.k8s.io", "v1", "ClusterRole").(*rbacv1.ClusterRole)
+			Expect(clusterRole.Rules).To(ContainElement(rbacv1.PolicyRule{
+				APIGroups: []string{"authentication.k8s.io"},
+				Resources: []string{"tokenreviews"},
+				Verbs:     []string{"create"},
+			}))
+			Expect(rtest.GetResource(createResources, RoleName, "", "rbac.authorization.k8s.io", "v1", "ClusterRoleBinding")).NotTo(BeNil())
+
+			deploy := rtest.GetResource(createResources, DeploymentName, render.ElasticsearchNamespace, "apps", "v1", "Deployment").(*appsv1.Deployment)
+			Expect(deploy.Spec.Template.Spec.Containers[0].Env).To(ContainElements(
+				corev1.EnvVar{Name: "ES_GATEWAY_TOKEN_AUDIENCE", Value: render.ESGatewayTokenAudience},
+				corev1.EnvVar{Name: "ES_GATEWAY_TOKEN_USERS", Value: "system:serviceaccount:tigera-fluentd:fluentd-node=tigera-fluentd-elasticsearch-access," +
+					"system:serviceaccount:tigera-fluentd:fluentd-node-windows=tigera-fluentd-elasticsearch-access"},
+			))
+		})
+
+		It("should not accept service account tokens unless fluentd opts in to them", func() {
+			component := EsGateway(cfg)
+
+			createResources, deleteResources := component.Objects()
+			Expect(rtest.GetResource(createResources, render.ElasticsearchLogCollectorUserSecret, render.ElasticsearchNamespace, "", "v1", "Secret")).To(BeNil())
+			Expect(deleteResources).To(ContainElement(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchLogCollectorUserSecret, Namespace: render.ElasticsearchNamespace}}))
+			Expect(rtest.GetResource(deleteResources, RoleName, "", "rbac.authorization.k8s.io", "v1", "ClusterRole")).NotTo(BeNil())
+
+			deploy := rtest.GetResource(createResources, DeploymentName, render.ElasticsearchNamespace, "apps", "v1", "Deployment").(*appsv1.Deployment)
+			for _, env := range deploy.Spec.Template.Spec.Containers[0].Env {
+				Expect(env.Name).NotTo(Equal("ES_GATEWAY_TOKEN_USERS"))
+			}
+		})
+
 		It("should not render PodAffinity when ControlPlaneReplicas is 1", func() {
 			var replicas int32 = 1
 			installation.ControlPlaneReplicas = &replicas
@@ -184,6 +228,117 @@ var _ = Describe("ES Gateway rendering tests", func() {
 			Expect(d.Spec.Template.Spec.Tolerations).To(ConsistOf(t))
 		})
 
+		It("should render the limits and timeouts of the gateway", func() {
+			maxConnections := int32(500)
+			maxBodySize := resource.MustParse("10Mi")
+			cfg.Spec = &operatorv1.ESGatewaySpec{
+				MaxConnections: &maxConnections,
+				RequestTimeout: &metav1.Duration{Duration: 2 * time.Minute},
+				IdleTimeout:    &metav1.Duration{Duration: 90 * time.Second},
+				MaxBodySize:    &maxBodySize,
+			}
+			component := EsGateway(cfg)
+
+			resources, _ := component.Objects()
+			d, ok := rtest.GetResource(resources, DeploymentName, render.ElasticsearchNamespace, "apps", "v1", "Deployment").(*appsv1.Deployment)
+			Expect(ok).To(BeTrue())
+			Expect(d.Spec.Template.Spec.Containers[0].Env).To(ContainElements(
+				corev1.EnvVar{Name: "ES_GATEWAY_MAX_CONNECTIONS", Value: "500"},
+				corev1.EnvVar{Name: "ES_GATEWAY_REQUEST_TIMEOUT", Value: "2m0s"},
+				corev1.EnvVar{Name: "ES_GATEWAY_IDLE_TIMEOUT", Value: "1m30s"},
+				corev1.EnvVar{Name: "ES_GATEWAY_MAX_BODY_SIZE_BYTES", Value: "10485760"},
+			))
+		})
+
+		It("should render the disruption policy of the gateway", func() {
+			gracePeriod := int64(60)
+			preStopDelay := int64(5)
+			minAvailable := intstr.FromInt(1)
+			cfg.DisruptionPolicy = &operatorv1.DisruptionPolicy{
+				TerminationGracePeriodSeconds: &gracePeriod,
+				PreStopDelaySeconds:           &preStopDelay,
+				PodDisruptionBudget:           &operatorv1.PodDisruptionBudget{MinAvailable: &minAvailable},
+			}
+			component := EsGateway(cfg)
+
+			createResources, deleteResources := component.Objects()
+			Expect(rtest.GetResource(deleteResources, DeploymentName, render.ElasticsearchNamespace, "policy", "v1", "PodDisruptionBudget")).To(BeNil())
+			pdb := rtest.GetResource(createResources, DeploymentName, render.ElasticsearchNamespace, "policy", "v1", "PodDisruptionBudget").(*policyv1.PodDisruptionBudget)
+			Expect(*pdb.Spec.MinAvailable).To(Equal(minAvailable))
+			Expect(pdb.Spec.Selector.MatchLabels).To(Equal(map[string]string{"k8s-app": DeploymentName}))
+
+			d := rtest.GetResource(createResources, DeploymentName, render.ElasticsearchNamespace, "apps", "v1", "Deployment").(*appsv1.Deployment)
+			Expect(*d.Spec.Template.Spec.TerminationGracePeriodSeconds).To(Equal(int64(60)))
+			// The gateway image doesn't ship a shell to sleep in.
+			Expect(d.Spec.Template.Spec.Containers[0].Lifecycle).To(BeNil())
+		})
+
+		It("should not delete the PodDisruptionBudget of the gateway when it doesn't exist", func() {
+			component := EsGateway(cfg)
+
+			_, deleteResources := component.Objects()
+			Expect(rtest.GetResource(deleteResources, DeploymentName, render.ElasticsearchNamespace, "policy", "v1", "PodDisruptionBudget")).To(BeNil())
+		})
+
+		It("should delete the PodDisruptionBudget of the gateway when there is no disruption policy", func() {
+			cfg.RemovePodDisruptionBudget = true
+			component := EsGateway(cfg)
+
+			createResources, deleteResources := component.Objects()
+			Expect(rtest.GetResource(createResources, DeploymentName, render.ElasticsearchNamespace, "policy", "v1", "PodDisruptionBudget")).To(BeNil())
+			Expect(rtest.GetResource(deleteResources, DeploymentName, render.ElasticsearchNamespace, "policy", "v1", "PodDisruptionBudget")).NotTo(BeNil())
+		})
+
+		It("should render the replicas and resources of the gateway", func() {
+			gatewayReplicas := int32(4)
+			resources := corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+				Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+			}
+			cfg.Spec = &operatorv1.ESGatewaySpec{Replicas: &gatewayReplicas, Resources: &resources}
+			component := EsGateway(cfg)
+
+			createResources, deleteResources := component.Objects()
+			deploy := rtest.GetResource(createResources, DeploymentName, render.ElasticsearchNamespace, "apps", "v1", "Deployment").(*appsv1.Deployment)
+			Expect(*deploy.Spec.Replicas).To(Equal(int32(4)))
+			Expect(deploy.Spec.Template.Spec.Containers[0].Resources).To(Equal(resources))
+			Expect(rtest.GetResource(createResources, DeploymentName, render.ElasticsearchNamespace, "autoscaling", "v2", "HorizontalPodAutoscaler")).To(BeNil())
+			Expect(rtest.GetResource(deleteResources, DeploymentName, render.ElasticsearchNamespace, "autoscaling", "v2", "HorizontalPodAutoscaler")).To(BeNil())
+		})
+
+		It("should delete the HorizontalPodAutoscaler of the gateway when autoscaling is disabled", func() {
+			cfg.RemoveHPA = true
+			component := EsGateway(cfg)
+
+			createResources, deleteResources := component.Objects()
+			Expect(rtest.GetResource(createResources, DeploymentName, render.ElasticsearchNamespace, "autoscaling", "v2", "HorizontalPodAutoscaler")).To(BeNil())
+			Expect(rtest.GetResource(deleteResources, DeploymentName, render.ElasticsearchNamespace, "autoscaling", "v2", "HorizontalPodAutoscaler")).NotTo(BeNil())
+		})
+
+		It("should render a HorizontalPodAutoscaler for the gateway when autoscaling is enabled", func() {
+			minReplicas := int32(2)
+			gatewayReplicas := int32(4)
+			cfg.Spec = &operatorv1.ESGatewaySpec{
+				Replicas:    &gatewayReplicas,
+				Autoscaling: &operatorv1.ESGatewayAutoscaling{MinReplicas: &minReplicas, MaxReplicas: 10},
+			}
+			component := EsGateway(cfg)
+
+			createResources, deleteResources := component.Objects()
+			Expect(rtest.GetResource(deleteResources, DeploymentName, render.ElasticsearchNamespace, "autoscaling", "v2", "HorizontalPodAutoscaler")).To(BeNil())
+			deploy := rtest.GetResource(createResources, DeploymentName, render.ElasticsearchNamespace, "apps", "v1", "Deployment").(*appsv1.Deployment)
+			Expect(deploy.Spec.Replicas).To(BeNil())
+			Expect(deploy.Spec.Template.Spec.Affinity).NotTo(BeNil())
+
+			hpa := rtest.GetResource(createResources, DeploymentName, render.ElasticsearchNamespace, "autoscaling", "v2", "HorizontalPodAutoscaler").(*autoscalingv2.HorizontalPodAutoscaler)
+			Expect(hpa.Spec.ScaleTargetRef).To(Equal(autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: DeploymentName}))
+			Expect(*hpa.Spec.MinReplicas).To(Equal(int32(2)))
+			Expect(hpa.Spec.MaxReplicas).To(Equal(int32(10)))
+			Expect(hpa.Spec.Metrics).To(HaveLen(1))
+			Expect(hpa.Spec.Metrics[0].Resource.Name).To(Equal(corev1.ResourceCPU))
+			Expect(*hpa.Spec.Metrics[0].Resource.Target.AverageUtilization).To(Equal(int32(DefaultTargetCPUUtilizationPercentage)))
+		})
+
 		Context("allow-tigera rendering", func() {
 			policyName := types.NamespacedName{Name: "allow-tigera.es-gateway-access", Namespace: "tigera-elasticsearch"}
 
@@ -26,10 +26,14 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+
 	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/common"
 	"github.com/tigera/operator/pkg/components"
 	"github.com/tigera/operator/pkg/ptr"
 	"github.com/tigera/operator/pkg/render"
+	"github.com/tigera/operator/pkg/render/common/disruptionpolicy"
 	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
 	"github.com/tigera/operator/pkg/render/common/secret"
@@ -43,6 +47,7 @@ const (
 	ElasticsearchMetricsName            = "tigera-elasticsearch-metrics"
 	ElasticsearchMetricsPolicyName      = networkpolicy.TigeraComponentPolicyPrefix + "elasticsearch-metrics"
 	ElasticsearchMetricsPort            = 9081
+	ElasticsearchMetricsRuleName        = "tigera-elasticsearch-alerts"
 )
 
 var ESMetricsSourceEntityRule = networkpolicy.CreateSourceEntityRule(render.ElasticsearchNamespace, ElasticsearchMetricsName)
@@ -61,6 +66,15 @@ type Config struct {
 	ClusterDomain        string
 	ServerTLS            certificatemanagement.KeyPairInterface
 	TrustedBundle        certificatemanagement.TrustedBundle
+	// Disabled removes the exporter, when the monitoring of the LogStorage disables it.
+	Disabled bool
+	// Alerts renders the PrometheusRule with the alerts on the metrics of Elasticsearch.
+	Alerts bool
+	// RemoveAlerts removes the PrometheusRule, when it exists and the alerts are disabled.
+	RemoveAlerts bool
+	// DisruptionPolicy defines how the pod of the exporter is stopped. It may be nil, in which case the pod is stopped
+	// with the defaults.
+	DisruptionPolicy *operatorv1.DisruptionPolicy
 }
 
 type elasticsearchMetrics struct {
@@ -84,12 +98,32 @@ func (e *elasticsearchMetrics) ResolveImages(is *operatorv1.ImageSet) error {
 }
 
 func (e *elasticsearchMetrics) Objects() (objsToCreate, objsToDelete []client.Object) {
+	if e.cfg.Disabled {
+		objsToDelete = []client.Object{
+			&v3.NetworkPolicy{TypeMeta: metav1.TypeMeta{Kind: "NetworkPolicy", APIVersion: "projectcalico.org/v3"}, ObjectMeta: metav1.ObjectMeta{Name: ElasticsearchMetricsPolicyName, Namespace: render.ElasticsearchNamespace}},
+			&corev1.Secret{TypeMeta: metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"}, ObjectMeta: metav1.ObjectMeta{Name: ElasticsearchMetricsSecret, Namespace: render.ElasticsearchNamespace}},
+			&corev1.Service{TypeMeta: metav1.TypeMeta{Kind: "Service", APIVersion: "v1"}, ObjectMeta: metav1.ObjectMeta{Name: ElasticsearchMetricsName, Namespace: render.ElasticsearchNamespace}},
+			&appsv1.Deployment{TypeMeta: metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"}, ObjectMeta: metav1.ObjectMeta{Name: ElasticsearchMetricsName, Namespace: render.ElasticsearchNamespace}},
+			&corev1.ServiceAccount{TypeMeta: metav1.TypeMeta{Kind: "ServiceAccount", APIVersion: "v1"}, ObjectMeta: metav1.ObjectMeta{Name: ElasticsearchMetricsName, Namespace: render.ElasticsearchNamespace}},
+		}
+		if e.cfg.RemoveAlerts {
+			objsToDelete = append(objsToDelete, e.prometheusRule())
+		}
+		return nil, objsToDelete
+	}
+
 	toCreate := []client.Object{
 		e.allowTigeraPolicy(),
 	}
 	toCreate = append(toCreate, secret.ToRuntimeObjects(secret.CopyToNamespace(render.ElasticsearchNamespace, e.cfg.ESMetricsCredsSecret)...)...)
 	toCreate = append(toCreate, e.metricsService(), e.metricsDeployment(), e.serviceAccount())
 
+	if e.cfg.Alerts {
+		toCreate = append(toCreate, e.prometheusRule())
+	} else if e.cfg.RemoveAlerts {
+		objsToDelete = append(objsToDelete, e.prometheusRule())
+	}
+
 	return toCreate, objsToDelete
 }
 
@@ -146,7 +180,7 @@ func (e elasticsearchMetrics) metricsDeployment() *appsv1.Deployment {
 		annotations[e.cfg.ServerTLS.HashAnnotationKey()] = e.cfg.ServerTLS.HashAnnotationValue()
 	}
 
-	return &appsv1.Deployment{
+	d := &appsv1.Deployment{
 		TypeMeta: metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      ElasticsearchMetricsName,
@@ -182,7 +216,7 @@ func (e elasticsearchMetrics) metricsDeployment() *appsv1.Deployment {
 								Env: []corev1.EnvVar{
 									{Name: "FIPS_MODE_ENABLED", Value: operatorv1.IsFIPSModeEnabledString(e.cfg.Installation.FIPSMode)},
 								},
-							}, render.DefaultElasticsearchClusterName, ElasticsearchMetricsSecret,
+							}, e.cfg.ESConfig.ClusterName(), ElasticsearchMetricsSecret,
 							e.cfg.ClusterDomain, e.SupportedOSType(),
 						),
 					},
@@ -194,6 +228,73 @@ func (e elasticsearchMetrics) metricsDeployment() *appsv1.Deployment {
 			}, e.cfg.ESConfig, []*corev1.Secret{e.cfg.ESMetricsCredsSecret}).(*corev1.PodTemplateSpec),
 		},
 	}
+	disruptionpolicy.Apply(&d.Spec.Template.Spec, e.cfg.DisruptionPolicy, false)
+	return d
+}
+
+// prometheusRule returns the alerts on the cluster health, the JVM heap usage and the indexing rate of Elasticsearch,
+// with the labels that the Tigera Prometheus selects its rules with.
+func (e *elasticsearchMetrics) prometheusRule() *monitoringv1.PrometheusRule {
+	return &monitoringv1.PrometheusRule{
+		TypeMeta: metav1.TypeMeta{Kind: monitoringv1.PrometheusRuleKind, APIVersion: "monitoring.coreos.com/v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ElasticsearchMetricsRuleName,
+			Namespace: common.TigeraPrometheusNamespace,
+			Labels: map[string]string{
+				"prometheus": "calico-node-prometheus",
+				"role":       "tigera-prometheus-rules",
+			},
+		},
+		Spec: monitoringv1.PrometheusRuleSpec{
+			Groups: []monitoringv1.RuleGroup{
+				{
+					Name: "elasticsearch.rules",
+					Rules: []monitoringv1.Rule{
+						{
+							Alert:  "ElasticsearchClusterRed",
+							Expr:   intstr.FromString(`elasticsearch_cluster_health_status{color="red"} == 1`),
+							For:    "5m",
+							Labels: map[string]string{"severity": "critical"},
+							Annotations: map[string]string{
+								"summary":     "Elasticsearch cluster {{$labels.cluster}} is red",
+								"description": "Some primary shards of the Elasticsearch cluster {{$labels.cluster}} are unassigned, so some logs can't be stored or queried.",
+							},
+						},
+						{
+							Alert:  "ElasticsearchClusterYellow",
+							Expr:   intstr.FromString(`elasticsearch_cluster_health_status{color="yellow"} == 1`),
+							For:    "30m",
+							Labels: map[string]string{"severity": "warning"},
+							Annotations: map[string]string{
+								"summary":     "Elasticsearch cluster {{$labels.cluster}} is yellow",
+								"description": "Some replica shards of the Elasticsearch cluster {{$labels.cluster}} have been unassigned for 30 minutes.",
+							},
+						},
+						{
+							Alert:  "ElasticsearchHeapUsageHigh",
+							Expr:   intstr.FromString(`elasticsearch_jvm_memory_used_bytes{area="heap"} / elasticsearch_jvm_memory_max_bytes{area="heap"} > 0.9`),
+							For:    "15m",
+							Labels: map[string]string{"severity": "warning"},
+							Annotations: map[string]string{
+								"summary":     "Elasticsearch node {{$labels.name}} is low on heap",
+								"description": "Elasticsearch node {{$labels.name}} has been using more than 90% of its JVM heap for 15 minutes.",
+							},
+						},
+						{
+							Alert:  "ElasticsearchIndexingStopped",
+							Expr:   intstr.FromString(`sum by (cluster) (rate(elasticsearch_indices_indexing_index_total[10m])) == 0`),
+							For:    "30m",
+							Labels: map[string]string{"severity": "warning"},
+							Annotations: map[string]string{
+								"summary":     "Elasticsearch cluster {{$labels.cluster}} is not indexing",
+								"description": "No documents have been indexed in the Elasticsearch cluster {{$labels.cluster}} for 30 minutes, so the logs may not be collected.",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
 }
 
 func (e *elasticsearchMetrics) allowTigeraPolicy() *v3.NetworkPolicy {
@@ -26,6 +26,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	operatorv1 "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/apis"
 	"github.com/tigera/operator/pkg/common"
@@ -259,6 +260,47 @@ var _ = Describe("Elasticsearch metrics", func() {
 			Expect(d.Spec.Template.Spec.Tolerations).To(ConsistOf(t))
 		})
 
+		It("should render the termination grace period of the disruption policy", func() {
+			gracePeriod := int64(15)
+			cfg.DisruptionPolicy = &operatorv1.DisruptionPolicy{TerminationGracePeriodSeconds: &gracePeriod}
+			component := ElasticsearchMetrics(cfg)
+
+			resources, _ := component.Objects()
+			d := rtest.GetResource(resources, ElasticsearchMetricsName, render.ElasticsearchNamespace, "apps", "v1", "Deployment").(*appsv1.Deployment)
+			Expect(*d.Spec.Template.Spec.TerminationGracePeriodSeconds).To(Equal(int64(15)))
+		})
+
+		It("should render the alerts on the metrics of Elasticsearch when they are enabled", func() {
+			cfg.Alerts = true
+			component := ElasticsearchMetrics(cfg)
+			resources, _ := component.Objects()
+
+			rule := rtest.GetResource(resources, ElasticsearchMetricsRuleName, common.TigeraPrometheusNamespace, "monitoring.coreos.com", "v1", "PrometheusRule").(*monitoringv1.PrometheusRule)
+			Expect(rule.Labels).To(Equal(map[string]string{"prometheus": "calico-node-prometheus", "role": "tigera-prometheus-rules"}))
+			var alerts []string
+			for _, r := range rule.Spec.Groups[0].Rules {
+				alerts = append(alerts, r.Alert)
+			}
+			Expect(alerts).To(ConsistOf("ElasticsearchClusterRed", "ElasticsearchClusterYellow", "ElasticsearchHeapUsageHigh", "ElasticsearchIndexingStopped"))
+		})
+
+		It("should neither render nor remove the alerts unless they are enabled or removed", func() {
+			component := ElasticsearchMetrics(cfg)
+			toCreate, toDelete := component.Objects()
+
+			Expect(rtest.GetResource(toCreate, ElasticsearchMetricsRuleName, common.TigeraPrometheusNamespace, "monitoring.coreos.com", "v1", "PrometheusRule")).To(BeNil())
+			Expect(toDelete).To(BeEmpty())
+		})
+
+		It("should remove the exporter and the alerts when the exporter is disabled", func() {
+			component := ElasticsearchMetrics(&Config{Installation: cfg.Installation, Disabled: true, RemoveAlerts: true})
+			toCreate, toDelete := component.Objects()
+
+			Expect(toCreate).To(BeEmpty())
+			Expect(rtest.GetResource(toDelete, ElasticsearchMetricsName, render.ElasticsearchNamespace, "apps", "v1", "Deployment")).NotTo(BeNil())
+			Expect(rtest.GetResource(toDelete, ElasticsearchMetricsRuleName, common.TigeraPrometheusNamespace, "monitoring.coreos.com", "v1", "PrometheusRule")).NotTo(BeNil())
+		})
+
 		Context("allow-tigera rendering", func() {
 			policyName := types.NamespacedName{Name: "allow-tigera.elasticsearch-metrics", Namespace: "tigera-elasticsearch"}
 
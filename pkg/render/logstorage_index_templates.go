@@ -0,0 +1,276 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/components"
+	"github.com/tigera/operator/pkg/ptr"
+	"github.com/tigera/operator/pkg/render/common/disruptionpolicy"
+	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/networkpolicy"
+	"github.com/tigera/operator/pkg/render/common/secret"
+	"github.com/tigera/operator/pkg/tls/certificatemanagement"
+)
+
+const (
+	ElasticsearchIndexTemplatesName       = "tigera-es-index-templates"
+	ElasticsearchIndexTemplatesPolicyName = networkpolicy.TigeraComponentPolicyPrefix + "es-index-templates"
+
+	// ElasticsearchIndexTemplatesUserSecret holds the credentials of the Elasticsearch user of the job, which can only
+	// manage the index templates.
+	ElasticsearchIndexTemplatesUserSecret = "tigera-es-index-templates-user"
+	ElasticsearchIndexTemplatesUserName   = "tigera-es-index-templates"
+	ElasticsearchIndexTemplatesRoleName   = "tigera_es_index_templates"
+
+	// ElasticsearchIndexTemplatesHashAnnotation holds the hash of the index templates and of the Elasticsearch cluster
+	// that the job applied them to, so that the job is recreated when the templates change or the cluster is
+	// recreated.
+	ElasticsearchIndexTemplatesHashAnnotation = "hash.operator.tigera.io/es-index-templates"
+
+	// IndexTemplateOverridesOrder is the order of the index templates of the overrides. Elasticsearch merges the
+	// legacy index templates that match an index in ascending order, so that the overrides take precedence over the
+	// templates that the components that write the logs install.
+	IndexTemplateOverridesOrder = 100
+
+	indexTemplateOverridesSuffix = "_overrides"
+	indexTemplatesDir            = "/etc/es-index-templates/"
+)
+
+// elasticsearchIndexTemplatesScript puts each index template file, and deletes the index templates of the overrides
+// that no longer have a file. A failure is reported through the termination message of the container.
+const elasticsearchIndexTemplatesScript = `
+fail() { echo "$1" > /dev/termination-log; echo "$1"; exit 1; }
+es() { curl -sS --fail --cacert "$CA_CRT_PATH" -u "$ELASTIC_USERNAME:$ELASTIC_PASSWORD" -H 'Content-Type: application/json' "https://` + ElasticsearchServiceName + `:9200$@"; }
+
+for file in ` + indexTemplatesDir + `*.json; do
+  [ -e "$file" ] || continue
+  name=$(basename "$file" .json)
+  es "/_template/$name" -X PUT --data-binary @"$file" -o /dev/null || fail "Failed to apply the index template $name"
+done
+
+names=$(es '/_cat/templates/tigera_secure_ee_*` + indexTemplateOverridesSuffix + `?h=name') || fail "Failed to list the index templates"
+for name in $names; do
+  if [ ! -e "` + indexTemplatesDir + `$name.json" ]; then
+    es "/_template/$name" -X DELETE -o /dev/null || fail "Failed to delete the index template $name"
+  fi
+done
+`
+
+// IndexTemplateOverridesName returns the name of the index template of the overrides of the indices of the log type.
+func IndexTemplateOverridesName(logType string) string {
+	return "tigera_secure_ee_" + logType + indexTemplateOverridesSuffix
+}
+
+// IndexTemplateOverridesPattern returns the pattern of the names of the indices of the log type, which are named
+// tigera_secure_ee_<log type>.<cluster>.<suffix>.
+func IndexTemplateOverridesPattern(logType string) string {
+	return "tigera_secure_ee_" + logType + ".*"
+}
+
+// ElasticsearchIndexTemplates renders the job that applies the overrides of the index templates of the LogStorage.
+func ElasticsearchIndexTemplates(cfg *ElasticsearchIndexTemplatesConfiguration) Component {
+	return &elasticsearchIndexTemplatesComponent{cfg: cfg}
+}
+
+// ElasticsearchIndexTemplatesConfiguration contains all the config information needed to render the component.
+type ElasticsearchIndexTemplatesConfiguration struct {
+	LogStorage    *operatorv1.LogStorage
+	Installation  *operatorv1.InstallationSpec
+	PullSecrets   []*corev1.Secret
+	TrustedBundle certificatemanagement.TrustedBundle
+	Provider      operatorv1.Provider
+
+	// Templates are the bodies of the index templates of the overrides, by their name.
+	Templates map[string]string
+
+	// ElasticsearchUID identifies the cluster that the index templates are applied to.
+	ElasticsearchUID types.UID
+
+	// UserSecret holds the credentials of the Elasticsearch user of the job, in the namespace of the operator.
+	UserSecret *corev1.Secret
+
+	// Enabled is whether the LogStorage overrides the index templates and Elasticsearch is running. If not, the
+	// objects of the job are deleted.
+	Enabled bool
+}
+
+// ElasticsearchIndexTemplatesHash returns the hash of the index templates and of the cluster that they are applied to,
+// which the job is annotated with.
+func ElasticsearchIndexTemplatesHash(cfg *ElasticsearchIndexTemplatesConfiguration) string {
+	return rmeta.AnnotationHash([]interface{}{cfg.Templates, cfg.ElasticsearchUID})
+}
+
+type elasticsearchIndexTemplatesComponent struct {
+	cfg   *ElasticsearchIndexTemplatesConfiguration
+	image string
+}
+
+func (c *elasticsearchIndexTemplatesComponent) ResolveImages(is *operatorv1.ImageSet) error {
+	reg := c.cfg.Installation.Registry
+	path := c.cfg.Installation.ImagePath
+	prefix := c.cfg.Installation.ImagePrefix
+	var err error
+	// The job only needs the shell and curl of the Elasticsearch image.
+	if operatorv1.IsFIPSModeEnabled(c.cfg.Installation.FIPSMode) {
+		c.image, err = components.GetReference(components.ComponentElasticsearchFIPS, reg, path, prefix, is)
+	} else {
+		c.image, err = components.GetReference(components.ComponentElasticsearch, reg, path, prefix, is)
+	}
+	return err
+}
+
+func (c *elasticsearchIndexTemplatesComponent) Objects() ([]client.Object, []client.Object) {
+	objs := []client.Object{c.allowTigeraPolicy(), c.serviceAccount(), c.configMap()}
+	if !c.cfg.Enabled {
+		objs = append(objs, c.job(),
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: ElasticsearchIndexTemplatesUserSecret, Namespace: common.OperatorNamespace()}},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: ElasticsearchIndexTemplatesUserSecret, Namespace: ElasticsearchNamespace}},
+		)
+		return nil, objs
+	}
+	objs = append(objs, c.cfg.UserSecret)
+	objs = append(objs, secret.ToRuntimeObjects(secret.CopyToNamespace(ElasticsearchNamespace, c.cfg.UserSecret)...)...)
+	return append(objs, c.job()), nil
+}
+
+func (c *elasticsearchIndexTemplatesComponent) Ready() bool {
+	return true
+}
+
+func (c *elasticsearchIndexTemplatesComponent) SupportedOSType() rmeta.OSType {
+	return rmeta.OSTypeLinux
+}
+
+// SupportedArchitectures returns the architectures of the Elasticsearch image that the Job runs.
+func (c *elasticsearchIndexTemplatesComponent) SupportedArchitectures() []string {
+	return components.Architectures(components.ComponentElasticsearch)
+}
+
+func (c *elasticsearchIndexTemplatesComponent) serviceAccount() *corev1.ServiceAccount {
+	return &corev1.ServiceAccount{
+		TypeMeta:   metav1.TypeMeta{Kind: "ServiceAccount", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: ElasticsearchIndexTemplatesName, Namespace: ElasticsearchNamespace},
+	}
+}
+
+// configMap holds a file for each index template that the job applies, named after the template.
+func (c *elasticsearchIndexTemplatesComponent) configMap() *corev1.ConfigMap {
+	data := map[string]string{}
+	for name, template := range c.cfg.Templates {
+		data[name+".json"] = template
+	}
+	return &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: ElasticsearchIndexTemplatesName, Namespace: ElasticsearchNamespace},
+		Data:       data,
+	}
+}
+
+func (c *elasticsearchIndexTemplatesComponent) job() *batchv1.Job {
+	env := []corev1.EnvVar{
+		{Name: "ELASTIC_USERNAME", ValueFrom: secret.GetEnvVarSource(ElasticsearchIndexTemplatesUserSecret, "username", false)},
+		{Name: "ELASTIC_PASSWORD", ValueFrom: secret.GetEnvVarSource(ElasticsearchIndexTemplatesUserSecret, "password", false)},
+	}
+	volumes := []corev1.Volume{{
+		Name: ElasticsearchIndexTemplatesName,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: ElasticsearchIndexTemplatesName},
+			},
+		},
+	}}
+	volumeMounts := []corev1.VolumeMount{{Name: ElasticsearchIndexTemplatesName, MountPath: indexTemplatesDir, ReadOnly: true}}
+	if c.cfg.TrustedBundle != nil {
+		env = append(env, corev1.EnvVar{Name: "CA_CRT_PATH", Value: c.cfg.TrustedBundle.MountPath()})
+		volumes = append(volumes, c.cfg.TrustedBundle.Volume())
+		volumeMounts = append(volumeMounts, c.cfg.TrustedBundle.VolumeMount(c.SupportedOSType()))
+	}
+
+	job := &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{Kind: "Job", APIVersion: "batch/v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ElasticsearchIndexTemplatesName,
+			Namespace: ElasticsearchNamespace,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: ptr.Int32ToPtr(3),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"k8s-app": ElasticsearchIndexTemplatesName,
+					},
+					Annotations: map[string]string{
+						ElasticsearchIndexTemplatesHashAnnotation: ElasticsearchIndexTemplatesHash(c.cfg),
+					},
+				},
+				Spec: corev1.PodSpec{
+					NodeSelector:       c.cfg.Installation.ControlPlaneNodeSelector,
+					Tolerations:        c.cfg.Installation.ControlPlaneTolerations,
+					ServiceAccountName: ElasticsearchIndexTemplatesName,
+					ImagePullSecrets:   secret.GetReferenceList(c.cfg.PullSecrets),
+					RestartPolicy:      corev1.RestartPolicyNever,
+					Containers: []corev1.Container{{
+						Name:                     ElasticsearchIndexTemplatesName,
+						Image:                    c.image,
+						Command:                  []string{"/bin/bash", "-c", elasticsearchIndexTemplatesScript},
+						Env:                      env,
+						VolumeMounts:             volumeMounts,
+						TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+						SecurityContext: &corev1.SecurityContext{
+							AllowPrivilegeEscalation: ptr.BoolToPtr(false),
+						},
+					}},
+					Volumes: volumes,
+				},
+			},
+		},
+	}
+	disruptionpolicy.Apply(&job.Spec.Template.Spec, logStorageJobsDisruptionPolicy(c.cfg.LogStorage), true)
+	return job
+}
+
+// allowTigeraPolicy allows the job to reach Elasticsearch.
+func (c *elasticsearchIndexTemplatesComponent) allowTigeraPolicy() *v3.NetworkPolicy {
+	egressRules := networkpolicy.AppendDNSEgressRules([]v3.Rule{}, c.cfg.Provider == operatorv1.ProviderOpenShift)
+	egressRules = append(egressRules, v3.Rule{
+		Action:      v3.Allow,
+		Protocol:    &networkpolicy.TCPProtocol,
+		Destination: ElasticsearchPortEntityRule(ElasticsearchHTTPPort(logStoragePorts(c.cfg.LogStorage))),
+	})
+
+	return &v3.NetworkPolicy{
+		TypeMeta: metav1.TypeMeta{Kind: "NetworkPolicy", APIVersion: "projectcalico.org/v3"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ElasticsearchIndexTemplatesPolicyName,
+			Namespace: ElasticsearchNamespace,
+		},
+		Spec: v3.NetworkPolicySpec{
+			Order:    &networkpolicy.HighPrecedenceOrder,
+			Tier:     networkpolicy.TigeraComponentTierName,
+			Selector: networkpolicy.KubernetesAppSelector(ElasticsearchIndexTemplatesName),
+			Types:    []v3.PolicyType{v3.PolicyTypeIngress, v3.PolicyTypeEgress},
+			Egress:   egressRules,
+		},
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/render"
+	rtest "github.com/tigera/operator/pkg/render/common/test"
+)
+
+var _ = Describe("Elasticsearch index templates rendering tests", func() {
+	var cfg *render.ElasticsearchIndexTemplatesConfiguration
+
+	BeforeEach(func() {
+		cfg = &render.ElasticsearchIndexTemplatesConfiguration{
+			LogStorage: &operatorv1.LogStorage{
+				Spec: operatorv1.LogStorageSpec{
+					IndexTemplates: &operatorv1.IndexTemplates{ConfigMapName: "index-templates"},
+				},
+			},
+			Installation: &operatorv1.InstallationSpec{},
+			Templates: map[string]string{
+				"tigera_secure_ee_flows_overrides": `{"index_patterns":["tigera_secure_ee_flows.*"],"order":100,"settings":{"number_of_shards":3}}`,
+			},
+			ElasticsearchUID: "es-uid",
+			UserSecret: &corev1.Secret{
+				TypeMeta:   metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+				ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchIndexTemplatesUserSecret, Namespace: common.OperatorNamespace()},
+				Data:       map[string][]byte{"username": []byte(render.ElasticsearchIndexTemplatesUserName), "password": []byte("password")},
+			},
+			Enabled: true,
+		}
+	})
+
+	It("should render the job with a file for each index template", func() {
+		component := render.ElasticsearchIndexTemplates(cfg)
+		Expect(component.ResolveImages(nil)).NotTo(HaveOccurred())
+
+		toCreate, toDelete := component.Objects()
+		Expect(toDelete).To(BeEmpty())
+		expectedResources := []struct {
+			name    string
+			ns      string
+			group   string
+			version string
+			kind    string
+		}{
+			{render.ElasticsearchIndexTemplatesPolicyName, render.ElasticsearchNamespace, "projectcalico.org", "v3", "NetworkPolicy"},
+			{render.ElasticsearchIndexTemplatesName, render.ElasticsearchNamespace, "", "v1", "ServiceAccount"},
+			{render.ElasticsearchIndexTemplatesName, render.ElasticsearchNamespace, "", "v1", "ConfigMap"},
+			{render.ElasticsearchIndexTemplatesUserSecret, common.OperatorNamespace(), "", "v1", "Secret"},
+			{render.ElasticsearchIndexTemplatesUserSecret, render.ElasticsearchNamespace, "", "v1", "Secret"},
+			{render.ElasticsearchIndexTemplatesName, render.ElasticsearchNamespace, "batch", "v1", "Job"},
+		}
+		Expect(toCreate).To(HaveLen(len(expectedResources)))
+		for i, expectedRes := range expectedResources {
+			rtest.ExpectResource(toCreate[i], expectedRes.name, expectedRes.ns, expectedRes.group, expectedRes.version, expectedRes.kind)
+		}
+
+		cm := rtest.GetResource(toCreate, render.ElasticsearchIndexTemplatesName, render.ElasticsearchNamespace, "", "v1", "ConfigMap").(*corev1.ConfigMap)
+		Expect(cm.Data).To(Equal(map[string]string{
+			"tigera_secure_ee_flows_overrides.json": `{"index_patterns":["tigera_secure_ee_flows.*"],"order":100,"settings":{"number_of_shards":3}}`,
+		}))
+
+		job := rtest.GetResource(toCreate, render.ElasticsearchIndexTemplatesName, render.ElasticsearchNamespace, "batch", "v1", "Job").(*batchv1.Job)
+		Expect(job.Spec.Template.Annotations).To(HaveKeyWithValue(render.ElasticsearchIndexTemplatesHashAnnotation, render.ElasticsearchIndexTemplatesHash(cfg)))
+		Expect(job.Spec.Template.Spec.RestartPolicy).To(Equal(corev1.RestartPolicyNever))
+		Expect(job.Spec.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{
+			Name: "ELASTIC_USERNAME",
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: render.ElasticsearchIndexTemplatesUserSecret},
+				Key:                  "username",
+			}},
+		}))
+		Expect(job.Spec.Template.Spec.Containers[0].VolumeMounts).To(ContainElement(
+			corev1.VolumeMount{Name: render.ElasticsearchIndexTemplatesName, MountPath: "/etc/es-index-templates/", ReadOnly: true},
+		))
+	})
+
+	It("should change the hash when the templates change or Elasticsearch is recreated", func() {
+		hash := render.ElasticsearchIndexTemplatesHash(cfg)
+		cfg.ElasticsearchUID = "new-es-uid"
+		Expect(render.ElasticsearchIndexTemplatesHash(cfg)).NotTo(Equal(hash))
+
+		hash = render.ElasticsearchIndexTemplatesHash(cfg)
+		cfg.Templates["tigera_secure_ee_dns_overrides"] = `{"index_patterns":["tigera_secure_ee_dns.*"],"order":100}`
+		Expect(render.ElasticsearchIndexTemplatesHash(cfg)).NotTo(Equal(hash))
+	})
+
+	It("should delete the job and its user secrets when the index templates are not overridden", func() {
+		cfg.Enabled = false
+		cfg.UserSecret = nil
+		component := render.ElasticsearchIndexTemplates(cfg)
+		Expect(component.ResolveImages(nil)).NotTo(HaveOccurred())
+
+		toCreate, toDelete := component.Objects()
+		Expect(toCreate).To(BeEmpty())
+		Expect(toDelete).To(HaveLen(6))
+		Expect(toDelete).To(ContainElements(
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchIndexTemplatesUserSecret, Namespace: common.OperatorNamespace()}},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchIndexTemplatesUserSecret, Namespace: render.ElasticsearchNamespace}},
+		))
+	})
+})
@@ -0,0 +1,249 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"strconv"
+
+	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/components"
+	"github.com/tigera/operator/pkg/ptr"
+	"github.com/tigera/operator/pkg/render/common/disruptionpolicy"
+	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/networkpolicy"
+	"github.com/tigera/operator/pkg/render/common/secret"
+	"github.com/tigera/operator/pkg/tls/certificatemanagement"
+)
+
+const (
+	ElasticsearchUpgradePreflightName       = "tigera-es-upgrade-preflight"
+	ElasticsearchUpgradePreflightPolicyName = networkpolicy.TigeraComponentPolicyPrefix + "es-upgrade-preflight"
+
+	// ElasticsearchUpgradePreflightVersionAnnotation holds the Elasticsearch version that the checks of the job were run
+	// for, so that the job is recreated when the target version changes.
+	ElasticsearchUpgradePreflightVersionAnnotation = "operator.tigera.io/elasticsearch-target-version"
+
+	// ElasticsearchUpgradePreflightECKVersionAnnotation holds the ECK operator version that the checks of the job were
+	// run for.
+	ElasticsearchUpgradePreflightECKVersionAnnotation = "operator.tigera.io/eck-operator-target-version"
+
+	defaultUpgradePreflightMaxDiskUsagePercent int32 = 80
+)
+
+// elasticsearchUpgradePreflightScript runs the pre-flight checks against the running Elasticsearch cluster. A failure
+// is reported through the termination message of the container, which the controller surfaces in the status.
+const elasticsearchUpgradePreflightScript = `
+fail() { echo "$1" > /dev/termination-log; echo "$1"; exit 1; }
+es() { curl -sS --fail --cacert "$CA_CRT_PATH" -u "elastic:$ELASTIC_PASSWORD" "https://` + ElasticsearchServiceName + `:9200$1"; }
+
+deprecations=$(es /_migration/deprecations) || fail "Failed to query the Elasticsearch deprecation API"
+if echo "$deprecations" | grep -q '"level":"critical"'; then
+  fail "The Elasticsearch deprecation API reports critical issues: $deprecations"
+fi
+
+usages=$(es '/_cat/allocation?h=disk.percent') || fail "Failed to query the disk usage of the Elasticsearch nodes"
+for usage in $usages; do
+  if [ "$usage" -gt "$MAX_DISK_USAGE_PERCENT" ]; then
+    fail "The disk usage of an Elasticsearch node is ${usage}%, which exceeds the maximum of ${MAX_DISK_USAGE_PERCENT}% for an upgrade"
+  fi
+done
+
+if [ -n "$SNAPSHOT_REPOSITORY" ]; then
+  snapshots=$(es "/_cat/snapshots/$SNAPSHOT_REPOSITORY?h=status,end_epoch") || fail "Failed to query the snapshots of repository $SNAPSHOT_REPOSITORY"
+  if ! echo "$snapshots" | grep -q SUCCESS; then
+    fail "Snapshot repository $SNAPSHOT_REPOSITORY has no successful snapshot"
+  fi
+  if [ -n "$MAX_SNAPSHOT_AGE_SECONDS" ]; then
+    latest=0
+    while read -r status end; do
+      if [ "$status" = SUCCESS ] && [ "$end" -gt "$latest" ]; then
+        latest=$end
+      fi
+    done <<< "$snapshots"
+    age=$(( $(date +%s) - latest ))
+    if [ "$age" -gt "$MAX_SNAPSHOT_AGE_SECONDS" ]; then
+      fail "The latest successful snapshot of repository $SNAPSHOT_REPOSITORY is ${age}s old, which exceeds the maximum of ${MAX_SNAPSHOT_AGE_SECONDS}s for an upgrade"
+    fi
+  fi
+fi
+`
+
+// ElasticsearchUpgradePreflight renders the job that checks whether Elasticsearch and the ECK operator can be upgraded
+// to the versions of the operator.
+func ElasticsearchUpgradePreflight(cfg *ElasticsearchUpgradePreflightConfiguration) Component {
+	return &elasticsearchUpgradePreflightComponent{cfg: cfg}
+}
+
+// ElasticsearchUpgradePreflightConfiguration contains all the config information needed to render the component.
+type ElasticsearchUpgradePreflightConfiguration struct {
+	LogStorage    *operatorv1.LogStorage
+	Installation  *operatorv1.InstallationSpec
+	PullSecrets   []*corev1.Secret
+	TrustedBundle certificatemanagement.TrustedBundle
+	Provider      operatorv1.Provider
+
+	// Enabled is whether the checks need to run, i.e. whether an upgrade of Elasticsearch or of the ECK operator is
+	// pending. If not, the objects of the checks are deleted.
+	Enabled bool
+}
+
+type elasticsearchUpgradePreflightComponent struct {
+	cfg   *ElasticsearchUpgradePreflightConfiguration
+	image string
+}
+
+func (c *elasticsearchUpgradePreflightComponent) ResolveImages(is *operatorv1.ImageSet) error {
+	reg := c.cfg.Installation.Registry
+	path := c.cfg.Installation.ImagePath
+	prefix := c.cfg.Installation.ImagePrefix
+	var err error
+	// The checks only need the shell and curl of the Elasticsearch image.
+	if operatorv1.IsFIPSModeEnabled(c.cfg.Installation.FIPSMode) {
+		c.image, err = components.GetReference(components.ComponentElasticsearchFIPS, reg, path, prefix, is)
+	} else {
+		c.image, err = components.GetReference(components.ComponentElasticsearch, reg, path, prefix, is)
+	}
+	return err
+}
+
+func (c *elasticsearchUpgradePreflightComponent) Objects() ([]client.Object, []client.Object) {
+	objs := []client.Object{c.allowTigeraPolicy(), c.serviceAccount(), c.job()}
+	if !c.cfg.Enabled {
+		return nil, objs
+	}
+	return objs, nil
+}
+
+func (c *elasticsearchUpgradePreflightComponent) Ready() bool {
+	return true
+}
+
+func (c *elasticsearchUpgradePreflightComponent) SupportedOSType() rmeta.OSType {
+	return rmeta.OSTypeLinux
+}
+
+// SupportedArchitectures returns the architectures of the Elasticsearch image that the Job runs.
+func (c *elasticsearchUpgradePreflightComponent) SupportedArchitectures() []string {
+	return components.Architectures(components.ComponentElasticsearch)
+}
+
+func (c *elasticsearchUpgradePreflightComponent) serviceAccount() *corev1.ServiceAccount {
+	return &corev1.ServiceAccount{
+		TypeMeta:   metav1.TypeMeta{Kind: "ServiceAccount", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: ElasticsearchUpgradePreflightName, Namespace: ElasticsearchNamespace},
+	}
+}
+
+func (c *elasticsearchUpgradePreflightComponent) job() *batchv1.Job {
+	maxDiskUsagePercent := defaultUpgradePreflightMaxDiskUsagePercent
+	var snapshotRepository, maxSnapshotAge string
+	if c.cfg.LogStorage != nil && c.cfg.LogStorage.Spec.UpgradePreflight != nil {
+		if c.cfg.LogStorage.Spec.UpgradePreflight.MaxDiskUsagePercent != nil {
+			maxDiskUsagePercent = *c.cfg.LogStorage.Spec.UpgradePreflight.MaxDiskUsagePercent
+		}
+		if age := c.cfg.LogStorage.Spec.UpgradePreflight.MaxSnapshotAge; age != nil && age.Duration > 0 {
+			maxSnapshotAge = strconv.Itoa(int(age.Seconds()))
+		}
+		snapshotRepository = c.cfg.LogStorage.Spec.UpgradePreflight.SnapshotRepository
+	}
+
+	env := []corev1.EnvVar{
+		{Name: "ELASTIC_PASSWORD", ValueFrom: secret.GetEnvVarSource(ElasticsearchAdminUserSecret, "elastic", false)},
+		{Name: "MAX_DISK_USAGE_PERCENT", Value: strconv.Itoa(int(maxDiskUsagePercent))},
+		{Name: "SNAPSHOT_REPOSITORY", Value: snapshotRepository},
+		{Name: "MAX_SNAPSHOT_AGE_SECONDS", Value: maxSnapshotAge},
+	}
+	var volumes []corev1.Volume
+	var volumeMounts []corev1.VolumeMount
+	if c.cfg.TrustedBundle != nil {
+		env = append(env, corev1.EnvVar{Name: "CA_CRT_PATH", Value: c.cfg.TrustedBundle.MountPath()})
+		volumes = append(volumes, c.cfg.TrustedBundle.Volume())
+		volumeMounts = append(volumeMounts, c.cfg.TrustedBundle.VolumeMount(c.SupportedOSType()))
+	}
+
+	job := &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{Kind: "Job", APIVersion: "batch/v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ElasticsearchUpgradePreflightName,
+			Namespace: ElasticsearchNamespace,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: ptr.Int32ToPtr(0),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"k8s-app": ElasticsearchUpgradePreflightName,
+					},
+					Annotations: map[string]string{
+						ElasticsearchUpgradePreflightVersionAnnotation:    components.ComponentEckElasticsearch.Version,
+						ElasticsearchUpgradePreflightECKVersionAnnotation: components.ComponentECKElasticsearchOperator.Version,
+					},
+				},
+				Spec: corev1.PodSpec{
+					NodeSelector:       c.cfg.Installation.ControlPlaneNodeSelector,
+					Tolerations:        c.cfg.Installation.ControlPlaneTolerations,
+					ServiceAccountName: ElasticsearchUpgradePreflightName,
+					ImagePullSecrets:   secret.GetReferenceList(c.cfg.PullSecrets),
+					RestartPolicy:      corev1.RestartPolicyNever,
+					Containers: []corev1.Container{{
+						Name:                     ElasticsearchUpgradePreflightName,
+						Image:                    c.image,
+						Command:                  []string{"/bin/bash", "-c", elasticsearchUpgradePreflightScript},
+						Env:                      env,
+						VolumeMounts:             volumeMounts,
+						TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+						SecurityContext: &corev1.SecurityContext{
+							AllowPrivilegeEscalation: ptr.BoolToPtr(false),
+						},
+					}},
+					Volumes: volumes,
+				},
+			},
+		},
+	}
+	disruptionpolicy.Apply(&job.Spec.Template.Spec, logStorageJobsDisruptionPolicy(c.cfg.LogStorage), true)
+	return job
+}
+
+// allowTigeraPolicy allows the job to query Elasticsearch.
+func (c *elasticsearchUpgradePreflightComponent) allowTigeraPolicy() *v3.NetworkPolicy {
+	egressRules := networkpolicy.AppendDNSEgressRules([]v3.Rule{}, c.cfg.Provider == operatorv1.ProviderOpenShift)
+	egressRules = append(egressRules, v3.Rule{
+		Action:      v3.Allow,
+		Protocol:    &networkpolicy.TCPProtocol,
+		Destination: ElasticsearchPortEntityRule(ElasticsearchHTTPPort(logStoragePorts(c.cfg.LogStorage))),
+	})
+
+	return &v3.NetworkPolicy{
+		TypeMeta: metav1.TypeMeta{Kind: "NetworkPolicy", APIVersion: "projectcalico.org/v3"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ElasticsearchUpgradePreflightPolicyName,
+			Namespace: ElasticsearchNamespace,
+		},
+		Spec: v3.NetworkPolicySpec{
+			Order:    &networkpolicy.HighPrecedenceOrder,
+			Tier:     networkpolicy.TigeraComponentTierName,
+			Selector: networkpolicy.KubernetesAppSelector(ElasticsearchUpgradePreflightName),
+			Types:    []v3.PolicyType{v3.PolicyTypeIngress, v3.PolicyTypeEgress},
+			Egress:   egressRules,
+		},
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/components"
+	"github.com/tigera/operator/pkg/ptr"
+	"github.com/tigera/operator/pkg/render"
+	rtest "github.com/tigera/operator/pkg/render/common/test"
+)
+
+var _ = Describe("Elasticsearch upgrade pre-flight rendering tests", func() {
+	var cfg *render.ElasticsearchUpgradePreflightConfiguration
+
+	BeforeEach(func() {
+		cfg = &render.ElasticsearchUpgradePreflightConfiguration{
+			LogStorage:   &operatorv1.LogStorage{},
+			Installation: &operatorv1.InstallationSpec{},
+			Enabled:      true,
+		}
+	})
+
+	It("should render the job when an upgrade is pending", func() {
+		cfg.LogStorage.Spec.UpgradePreflight = &operatorv1.UpgradePreflight{
+			MaxDiskUsagePercent: ptr.Int32ToPtr(70),
+			MaxSnapshotAge:      &metav1.Duration{Duration: 24 * time.Hour},
+			SnapshotRepository:  "backups",
+		}
+		component := render.ElasticsearchUpgradePreflight(cfg)
+		Expect(component.ResolveImages(nil)).NotTo(HaveOccurred())
+
+		toCreate, toDelete := component.Objects()
+		Expect(toDelete).To(BeEmpty())
+		expectedResources := []struct {
+			name    string
+			ns      string
+			group   string
+			version string
+			kind    string
+		}{
+			{render.ElasticsearchUpgradePreflightPolicyName, render.ElasticsearchNamespace, "projectcalico.org", "v3", "NetworkPolicy"},
+			{render.ElasticsearchUpgradePreflightName, render.ElasticsearchNamespace, "", "v1", "ServiceAccount"},
+			{render.ElasticsearchUpgradePreflightName, render.ElasticsearchNamespace, "batch", "v1", "Job"},
+		}
+		Expect(toCreate).To(HaveLen(len(expectedResources)))
+		for i, expectedRes := range expectedResources {
+			rtest.ExpectResource(toCreate[i], expectedRes.name, expectedRes.ns, expectedRes.group, expectedRes.version, expectedRes.kind)
+		}
+
+		job := rtest.GetResource(toCreate, render.ElasticsearchUpgradePreflightName, render.ElasticsearchNamespace, "batch", "v1", "Job").(*batchv1.Job)
+		Expect(job.Spec.Template.Annotations).To(HaveKeyWithValue(render.ElasticsearchUpgradePreflightVersionAnnotation, components.ComponentEckElasticsearch.Version))
+		Expect(job.Spec.Template.Annotations).To(HaveKeyWithValue(render.ElasticsearchUpgradePreflightECKVersionAnnotation, components.ComponentECKElasticsearchOperator.Version))
+		Expect(job.Spec.Template.Spec.RestartPolicy).To(Equal(corev1.RestartPolicyNever))
+		Expect(job.Spec.Template.Spec.Containers).To(HaveLen(1))
+		Expect(job.Spec.Template.Spec.Containers[0].Env).To(ContainElements(
+			corev1.EnvVar{Name: "MAX_DISK_USAGE_PERCENT", Value: "70"},
+			corev1.EnvVar{Name: "SNAPSHOT_REPOSITORY", Value: "backups"},
+			corev1.EnvVar{Name: "MAX_SNAPSHOT_AGE_SECONDS", Value: "86400"},
+		))
+	})
+
+	It("should not check the age of the snapshots by default", func() {
+		component := render.ElasticsearchUpgradePreflight(cfg)
+		Expect(component.ResolveImages(nil)).NotTo(HaveOccurred())
+
+		toCreate, _ := component.Objects()
+		job := rtest.GetResource(toCreate, render.ElasticsearchUpgradePreflightName, render.ElasticsearchNamespace, "batch", "v1", "Job").(*batchv1.Job)
+		Expect(job.Spec.Template.Spec.Containers[0].Env).To(ContainElements(
+			corev1.EnvVar{Name: "MAX_DISK_USAGE_PERCENT", Value: "80"},
+			corev1.EnvVar{Name: "SNAPSHOT_REPOSITORY", Value: ""},
+			corev1.EnvVar{Name: "MAX_SNAPSHOT_AGE_SECONDS", Value: ""},
+		))
+	})
+
+	It("should delete the job when no upgrade is pending", func() {
+		cfg.Enabled = false
+		component := render.ElasticsearchUpgradePreflight(cfg)
+		Expect(component.ResolveImages(nil)).NotTo(HaveOccurred())
+
+		toCreate, toDelete := component.Objects()
+		Expect(toCreate).To(BeEmpty())
+		Expect(toDelete).To(HaveLen(3))
+	})
+})
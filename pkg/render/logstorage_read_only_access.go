@@ -0,0 +1,93 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/secret"
+)
+
+const (
+	// ElasticsearchReadOnlyAccessRoleName is the Elasticsearch role of the users of the grants of read-only access. It
+	// can read the log indices and use Kibana in read-only mode.
+	ElasticsearchReadOnlyAccessRoleName = "tigera_read_only_access"
+
+	// ElasticsearchReadOnlyAccessUserPrefix is the prefix of the names of the Elasticsearch users of the grants of
+	// read-only access.
+	ElasticsearchReadOnlyAccessUserPrefix = "tigera-read-only-"
+
+	// LogStorageReadOnlyAccessLabel holds the name of the grant of a secret with the credentials of a grant of
+	// read-only access, so that the secrets of the grants that expire or are removed can be found.
+	LogStorageReadOnlyAccessLabel = "operator.tigera.io/logstorage-read-only-access"
+
+	// ReadOnlyAccessExpirationAnnotation holds the time at which the credentials of a secret of a grant of read-only
+	// access expire, in RFC 3339 format, for the holders of the credentials.
+	ReadOnlyAccessExpirationAnnotation = "operator.tigera.io/expiration-time"
+
+	// DefaultReadOnlyAccessTTL is how long the credentials of a grant of read-only access are valid by default.
+	DefaultReadOnlyAccessTTL = 8 * time.Hour
+)
+
+// ElasticsearchReadOnlyAccessUserName returns the name of the Elasticsearch user of the grant of read-only access.
+func ElasticsearchReadOnlyAccessUserName(grant string) string {
+	return ElasticsearchReadOnlyAccessUserPrefix + grant
+}
+
+// ElasticsearchReadOnlyAccessUserSecretName returns the name of the secret with the credentials of the Elasticsearch
+// user of the grant of read-only access.
+func ElasticsearchReadOnlyAccessUserSecretName(grant string) string {
+	return ElasticsearchReadOnlyAccessUserPrefix + grant + "-elasticsearch-user"
+}
+
+// LogStorageReadOnlyAccess renders the secrets with the credentials of the Elasticsearch users of the grants of
+// read-only access of the LogStorage.
+func LogStorageReadOnlyAccess(cfg *LogStorageReadOnlyAccessConfiguration) Component {
+	return &logStorageReadOnlyAccessComponent{cfg: cfg}
+}
+
+// LogStorageReadOnlyAccessConfiguration contains all the config information needed to render the component.
+type LogStorageReadOnlyAccessConfiguration struct {
+	// UserSecrets are the secrets with the credentials of the active grants, in the namespace of the operator.
+	UserSecrets []*corev1.Secret
+
+	// RemovedUserSecrets are the secrets of the grants that expired or were removed from the LogStorage.
+	RemovedUserSecrets []*corev1.Secret
+}
+
+type logStorageReadOnlyAccessComponent struct {
+	cfg *LogStorageReadOnlyAccessConfiguration
+}
+
+func (c *logStorageReadOnlyAccessComponent) ResolveImages(is *operatorv1.ImageSet) error {
+	return nil
+}
+
+func (c *logStorageReadOnlyAccessComponent) Objects() ([]client.Object, []client.Object) {
+	return secret.ToRuntimeObjects(c.cfg.UserSecrets...), secret.ToRuntimeObjects(c.cfg.RemovedUserSecrets...)
+}
+
+func (c *logStorageReadOnlyAccessComponent) Ready() bool {
+	return true
+}
+
+func (c *logStorageReadOnlyAccessComponent) SupportedOSType() rmeta.OSType {
+	return rmeta.OSTypeAny
+}
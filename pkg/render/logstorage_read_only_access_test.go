@@ -0,0 +1,57 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/render"
+	rtest "github.com/tigera/operator/pkg/render/common/test"
+)
+
+var _ = Describe("LogStorage read-only access rendering tests", func() {
+	userSecret := func(grant string) *corev1.Secret {
+		return &corev1.Secret{
+			TypeMeta: metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      render.ElasticsearchReadOnlyAccessUserSecretName(grant),
+				Namespace: common.OperatorNamespace(),
+				Labels:    map[string]string{render.LogStorageReadOnlyAccessLabel: grant},
+			},
+			Data: map[string][]byte{"username": []byte(render.ElasticsearchReadOnlyAccessUserName(grant)), "password": []byte("password")},
+		}
+	}
+
+	It("should render the user secrets of the active grants and delete the expired and removed secrets", func() {
+		removed := userSecret("responder")
+		component := render.LogStorageReadOnlyAccess(&render.LogStorageReadOnlyAccessConfiguration{
+			UserSecrets:        []*corev1.Secret{userSecret("auditor")},
+			RemovedUserSecrets: []*corev1.Secret{removed},
+		})
+		Expect(component.ResolveImages(nil)).NotTo(HaveOccurred())
+
+		toCreate, toDelete := component.Objects()
+		Expect(toCreate).To(HaveLen(1))
+		rtest.ExpectResource(toCreate[0], "tigera-read-only-auditor-elasticsearch-user", common.OperatorNamespace(), "", "v1", "Secret")
+		Expect(toCreate[0].(*corev1.Secret).Data).To(HaveKeyWithValue("username", []byte("tigera-read-only-auditor")))
+
+		Expect(toDelete).To(HaveLen(1))
+		Expect(toDelete[0]).To(Equal(removed))
+	})
+})
@@ -0,0 +1,132 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/secret"
+)
+
+const (
+	// ElasticsearchTenantUserPrefix is the prefix of the names of the Elasticsearch users of the tenants of the
+	// LogStorage.
+	ElasticsearchTenantUserPrefix = "tigera-tenant-"
+
+	// LogStorageTenantLabel holds the name of the tenant of a secret with the credentials of the Elasticsearch user of
+	// a tenant, so that the secrets of the tenants that are removed can be found.
+	LogStorageTenantLabel = "operator.tigera.io/logstorage-tenant"
+)
+
+// ElasticsearchTenantUserName returns the name of the Elasticsearch user of the tenant.
+func ElasticsearchTenantUserName(tenant string) string {
+	return ElasticsearchTenantUserPrefix + tenant
+}
+
+// ElasticsearchTenantUserSecretName returns the name of the secret with the credentials of the Elasticsearch user of
+// the tenant.
+func ElasticsearchTenantUserSecretName(tenant string) string {
+	return ElasticsearchTenantUserPrefix + tenant + "-elasticsearch-user"
+}
+
+// TenantIndexPatterns returns the patterns of the log indices of the managed clusters of the tenant, which are named
+// tigera_secure_ee_<log type>.<cluster>.<suffix>.
+func TenantIndexPatterns(tenant operatorv1.LogStorageTenant) []string {
+	var patterns []string
+	for _, cluster := range tenant.Clusters {
+		patterns = append(patterns, fmt.Sprintf("tigera_secure_ee_*.%s.*", cluster))
+	}
+	return patterns
+}
+
+// LogStorageKibanaSpaces returns the Kibana spaces of the LogStorage, followed by the spaces of the tenants that have
+// OIDC groups. The space of a tenant is restricted by the Elasticsearch role of the tenant.
+func LogStorageKibanaSpaces(ls *operatorv1.LogStorage) []operatorv1.KibanaSpace {
+	if ls == nil {
+		return nil
+	}
+	var spaces []operatorv1.KibanaSpace
+	spaces = append(spaces, ls.Spec.KibanaSpaces...)
+	for _, tenant := range ls.Spec.Tenants() {
+		if len(tenant.Groups) == 0 {
+			continue
+		}
+		spaces = append(spaces, operatorv1.KibanaSpace{
+			ID:         tenant.Name,
+			Groups:     tenant.Groups,
+			TenantRole: tenant.Name,
+		})
+	}
+	return spaces
+}
+
+// LogStorageTenants renders the secrets with the credentials of the Elasticsearch users of the tenants of the
+// LogStorage.
+func LogStorageTenants(cfg *LogStorageTenantsConfiguration) Component {
+	return &logStorageTenantsComponent{cfg: cfg}
+}
+
+// LogStorageTenantsConfiguration contains all the config information needed to render the component.
+type LogStorageTenantsConfiguration struct {
+	LogStorage *operatorv1.LogStorage
+
+	// UserSecrets are the secrets with the credentials of the Elasticsearch users of the tenants, in the namespace of
+	// the operator.
+	UserSecrets []*corev1.Secret
+
+	// RemovedUserSecrets are the secrets of the Elasticsearch users of the tenants that were removed from the
+	// LogStorage, and the copies of the secrets in the namespaces that the tenants no longer use.
+	RemovedUserSecrets []*corev1.Secret
+}
+
+type logStorageTenantsComponent struct {
+	cfg *LogStorageTenantsConfiguration
+}
+
+func (c *logStorageTenantsComponent) ResolveImages(is *operatorv1.ImageSet) error {
+	return nil
+}
+
+func (c *logStorageTenantsComponent) Objects() ([]client.Object, []client.Object) {
+	namespaces := map[string]string{}
+	for _, tenant := range c.cfg.LogStorage.Spec.Tenants() {
+		namespaces[tenant.Name] = tenant.UserSecretNamespace
+	}
+
+	var toCreate []client.Object
+	for _, s := range c.cfg.UserSecrets {
+		toCreate = append(toCreate, s)
+		tenant := s.Labels[LogStorageTenantLabel]
+		if ns := namespaces[tenant]; ns != "" && ns != s.Namespace {
+			copied := secret.CopyToNamespace(ns, s)[0]
+			copied.Labels = map[string]string{LogStorageTenantLabel: tenant}
+			toCreate = append(toCreate, copied)
+		}
+	}
+	return toCreate, secret.ToRuntimeObjects(c.cfg.RemovedUserSecrets...)
+}
+
+func (c *logStorageTenantsComponent) Ready() bool {
+	return true
+}
+
+func (c *logStorageTenantsComponent) SupportedOSType() rmeta.OSType {
+	return rmeta.OSTypeAny
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/render"
+	rtest "github.com/tigera/operator/pkg/render/common/test"
+)
+
+var _ = Describe("LogStorage tenancy rendering tests", func() {
+	var ls *operatorv1.LogStorage
+
+	BeforeEach(func() {
+		ls = &operatorv1.LogStorage{
+			Spec: operatorv1.LogStorageSpec{
+				KibanaSpaces: []operatorv1.KibanaSpace{
+					{ID: "ops", Groups: []string{"ops"}},
+				},
+				Tenancy: &operatorv1.LogStorageTenancy{
+					Tenants: []operatorv1.LogStorageTenant{
+						{Name: "team-a", Clusters: []string{"cluster-a"}, Groups: []string{"team-a"}, UserSecretNamespace: "team-a"},
+						{Name: "team-b", Clusters: []string{"cluster-b"}},
+					},
+				},
+			},
+		}
+	})
+
+	userSecret := func(tenant string) *corev1.Secret {
+		return &corev1.Secret{
+			TypeMeta: metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      render.ElasticsearchTenantUserSecretName(tenant),
+				Namespace: common.OperatorNamespace(),
+				Labels:    map[string]string{render.LogStorageTenantLabel: tenant},
+			},
+			Data: map[string][]byte{"username": []byte(render.ElasticsearchTenantUserName(tenant)), "password": []byte("password")},
+		}
+	}
+
+	It("should add a Kibana space for each tenant with groups", func() {
+		Expect(render.LogStorageKibanaSpaces(ls)).To(Equal([]operatorv1.KibanaSpace{
+			{ID: "ops", Groups: []string{"ops"}},
+			{ID: "team-a", Groups: []string{"team-a"}, TenantRole: "team-a"},
+		}))
+	})
+
+	It("should copy the user secrets of the tenants to their namespaces and delete the removed secrets", func() {
+		removed := userSecret("team-c")
+		component := render.LogStorageTenants(&render.LogStorageTenantsConfiguration{
+			LogStorage:         ls,
+			UserSecrets:        []*corev1.Secret{userSecret("team-a"), userSecret("team-b")},
+			RemovedUserSecrets: []*corev1.Secret{removed},
+		})
+		Expect(component.ResolveImages(nil)).NotTo(HaveOccurred())
+
+		toCreate, toDelete := component.Objects()
+		expectedResources := []struct {
+			name    string
+			ns      string
+			group   string
+			version string
+			kind    string
+		}{
+			{"tigera-tenant-team-a-elasticsearch-user", common.OperatorNamespace(), "", "v1", "Secret"},
+			{"tigera-tenant-team-a-elasticsearch-user", "team-a", "", "v1", "Secret"},
+			{"tigera-tenant-team-b-elasticsearch-user", common.OperatorNamespace(), "", "v1", "Secret"},
+		}
+		Expect(toCreate).To(HaveLen(len(expectedResources)))
+		for i, expectedRes := range expectedResources {
+			rtest.ExpectResource(toCreate[i], expectedRes.name, expectedRes.ns, expectedRes.group, expectedRes.version, expectedRes.kind)
+		}
+		copied := rtest.GetResource(toCreate, "tigera-tenant-team-a-elasticsearch-user", "team-a", "", "v1", "Secret").(*corev1.Secret)
+		Expect(copied.Labels).To(HaveKeyWithValue(render.LogStorageTenantLabel, "team-a"))
+		Expect(copied.Data).To(HaveKeyWithValue("username", []byte("tigera-tenant-team-a")))
+
+		Expect(toDelete).To(HaveLen(1))
+		Expect(toDelete[0]).To(Equal(removed))
+	})
+})
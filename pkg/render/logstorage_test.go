@@ -17,6 +17,7 @@ package render_test
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/types"
 
@@ -25,14 +26,19 @@ import (
 	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
 	"github.com/tigera/operator/pkg/render/testutils"
 
+	cmnv1 "github.com/elastic/cloud-on-k8s/pkg/apis/common/v1"
 	esv1 "github.com/elastic/cloud-on-k8s/pkg/apis/elasticsearch/v1"
 	kbv1 "github.com/elastic/cloud-on-k8s/pkg/apis/kibana/v1"
 	"github.com/tigera/operator/pkg/apis"
 	"github.com/tigera/operator/pkg/controller/certificatemanager"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	batchv1beta "k8s.io/api/batch/v1beta1"
+	policyv1 "k8s.io/api/policy/v1"
 	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
@@ -43,8 +49,11 @@ import (
 	operatorv1 "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/common"
 	"github.com/tigera/operator/pkg/dns"
+	"github.com/tigera/operator/pkg/ptr"
 	"github.com/tigera/operator/pkg/render"
+	rcomp "github.com/tigera/operator/pkg/render/common/components"
 	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
+	rmeta "github.com/tigera/operator/pkg/render/common/meta"
 	"github.com/tigera/operator/pkg/render/common/podaffinity"
 	rtest "github.com/tigera/operator/pkg/render/common/test"
 	"github.com/tigera/operator/pkg/tls/certificatemanagement"
@@ -52,6 +61,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 type resourceTestObj struct {
@@ -216,6 +226,7 @@ var _ = Describe("Elasticsearch rendering tests", func() {
 					{"tigera-kibana", render.KibanaNamespace, &corev1.ServiceAccount{}, nil},
 					{"tigera-pull-secret", render.KibanaNamespace, &corev1.Secret{}, nil},
 					{render.KibanaName, render.KibanaNamespace, &kbv1.Kibana{}, nil},
+					{render.KibanaName, render.KibanaNamespace, &policyv1.PodDisruptionBudget{}, nil},
 					{render.EsManagerRole, render.ElasticsearchNamespace, &rbacv1.Role{}, nil},
 					{render.EsManagerRoleBinding, render.ElasticsearchNamespace, &rbacv1.RoleBinding{}, nil},
 				}
@@ -225,7 +236,16 @@ var _ = Describe("Elasticsearch rendering tests", func() {
 				createResources, deleteResources := component.Objects()
 
 				compareResources(createResources, expectedCreateResources)
-				compareResources(deleteResources, []resourceTestObj{})
+				compareResources(deleteResources, []resourceTestObj{
+					{render.LogStoragePriorityClassName, "", &schedulingv1.PriorityClass{}, nil},
+					{render.ECKWebhookServiceName, render.ECKOperatorNamespace, &corev1.Service{}, nil},
+					{render.ECKWebhookConfigurationName, "", &admissionregistrationv1.ValidatingWebhookConfiguration{}, nil},
+					{render.ECKEnterpriseLicense, render.ECKOperatorNamespace, &corev1.Secret{}, nil},
+					{render.SnapshotRepositorySecretName, render.ElasticsearchNamespace, &corev1.Secret{}, nil},
+					{render.DexObjectName, render.ElasticsearchNamespace, &corev1.Secret{}, nil},
+					{render.KibanaElasticsearchUserSecret, render.KibanaNamespace, &corev1.Secret{}, nil},
+					{render.EsCuratorName, render.ElasticsearchNamespace, &batchv1beta.CronJob{}, nil},
+				})
 
 				// Check the namespaces.
 				namespace := rtest.GetResource(createResources, "tigera-eck-operator", "", "", "v1", "Namespace").(*corev1.Namespace)
@@ -296,6 +316,43 @@ var _ = Describe("Elasticsearch rendering tests", func() {
 				Expect(*kibana.Spec.PodTemplate.Spec.SecurityContext.RunAsUser).To(BeEquivalentTo(10001))
 			})
 
+			It("should render the same objects when split into sub-components", func() {
+				createResources, deleteResources := render.LogStorage(cfg).Objects()
+
+				var names []render.LogStorageSubComponentName
+				var subCreateResources, subDeleteResources []client.Object
+				subComponents := render.LogStorageSubComponents(cfg)
+				for _, sub := range subComponents {
+					names = append(names, sub.Name)
+					toCreate, toDelete := sub.Objects()
+					subCreateResources = append(subCreateResources, toCreate...)
+					subDeleteResources = append(subDeleteResources, toDelete...)
+				}
+
+				Expect(names).To(Equal([]render.LogStorageSubComponentName{
+					render.LogStorageSubComponentRBAC,
+					render.LogStorageSubComponentECKOperator,
+					render.LogStorageSubComponentElasticsearch,
+					render.LogStorageSubComponentKibana,
+					render.LogStorageSubComponentCurator,
+				}))
+				Expect(subCreateResources).To(ConsistOf(createResources))
+				Expect(subDeleteResources).To(ConsistOf(deleteResources))
+
+				kibanaResources, _ := subComponents[3].Objects()
+				Expect(rtest.GetResource(kibanaResources, render.KibanaName, render.KibanaNamespace, "kibana.k8s.elastic.co", "v1", "Kibana")).NotTo(BeNil())
+				Expect(rtest.GetResource(kibanaResources, render.ElasticsearchName, render.ElasticsearchNamespace, "elasticsearch.k8s.elastic.co", "v1", "Elasticsearch")).To(BeNil())
+			})
+
+			It("should only restrict the architectures of the sub-components to the ones of their own images", func() {
+				subComponents := render.LogStorageSubComponents(cfg)
+				Expect(subComponents[0].SupportedArchitectures()).To(BeEmpty())
+				Expect(subComponents[1].SupportedArchitectures()).To(BeEmpty())
+				Expect(subComponents[2].SupportedArchitectures()).To(Equal([]string{"amd64"}))
+				Expect(subComponents[3].SupportedArchitectures()).To(Equal([]string{"amd64"}))
+				Expect(subComponents[4].SupportedArchitectures()).To(Equal([]string{"amd64"}))
+			})
+
 			It("should render an elasticsearchComponent and delete the Elasticsearch and Kibana ExternalService", func() {
 				expectedCreateResources := []resourceTestObj{
 					{render.ECKOperatorNamespace, "", &corev1.Namespace{}, nil},
@@ -326,13 +383,22 @@ var _ = Describe("Elasticsearch rendering tests", func() {
 					{"tigera-kibana", render.KibanaNamespace, &corev1.ServiceAccount{}, nil},
 					{"tigera-pull-secret", render.KibanaNamespace, &corev1.Secret{}, nil},
 					{render.KibanaName, render.KibanaNamespace, &kbv1.Kibana{}, nil},
+					{render.KibanaName, render.KibanaNamespace, &policyv1.PodDisruptionBudget{}, nil},
 					{render.EsManagerRole, render.ElasticsearchNamespace, &rbacv1.Role{}, nil},
 					{render.EsManagerRoleBinding, render.ElasticsearchNamespace, &rbacv1.RoleBinding{}, nil},
 				}
 
 				expectedDeleteResources := []resourceTestObj{
+					{render.LogStoragePriorityClassName, "", &schedulingv1.PriorityClass{}, nil},
+					{render.ECKWebhookServiceName, render.ECKOperatorNamespace, &corev1.Service{}, nil},
+					{render.ECKWebhookConfigurationName, "", &admissionregistrationv1.ValidatingWebhookConfiguration{}, nil},
+					{render.ECKEnterpriseLicense, render.ECKOperatorNamespace, &corev1.Secret{}, nil},
+					{render.SnapshotRepositorySecretName, render.ElasticsearchNamespace, &corev1.Secret{}, nil},
+					{render.DexObjectName, render.ElasticsearchNamespace, &corev1.Secret{}, nil},
 					{render.ElasticsearchServiceName, render.ElasticsearchNamespace, &corev1.Service{}, nil},
+					{render.KibanaElasticsearchUserSecret, render.KibanaNamespace, &corev1.Secret{}, nil},
 					{render.KibanaServiceName, render.KibanaNamespace, &corev1.Service{}, nil},
+					{render.EsCuratorName, render.ElasticsearchNamespace, &batchv1beta.CronJob{}, nil},
 				}
 
 				cfg.ESService = &corev1.Service{
@@ -392,6 +458,7 @@ var _ = Describe("Elasticsearch rendering tests", func() {
 					{"tigera-kibana", render.KibanaNamespace, &corev1.ServiceAccount{}, nil},
 					{"tigera-pull-secret", render.KibanaNamespace, &corev1.Secret{}, nil},
 					{render.KibanaName, render.KibanaNamespace, &kbv1.Kibana{}, nil},
+					{render.KibanaName, render.KibanaNamespace, &policyv1.PodDisruptionBudget{}, nil},
 					{render.EsManagerRole, render.ElasticsearchNamespace, &rbacv1.Role{}, nil},
 					{render.EsManagerRoleBinding, render.ElasticsearchNamespace, &rbacv1.RoleBinding{}, nil},
 					// Certificate management comes with two additional cluster role bindings:
@@ -407,7 +474,16 @@ var _ = Describe("Elasticsearch rendering tests", func() {
 				createResources, deleteResources := component.Objects()
 
 				compareResources(createResources, expectedCreateResources)
-				compareResources(deleteResources, []resourceTestObj{})
+				compareResources(deleteResources, []resourceTestObj{
+					{render.LogStoragePriorityClassName, "", &schedulingv1.PriorityClass{}, nil},
+					{render.ECKWebhookServiceName, render.ECKOperatorNamespace, &corev1.Service{}, nil},
+					{render.ECKWebhookConfigurationName, "", &admissionregistrationv1.ValidatingWebhookConfiguration{}, nil},
+					{render.ECKEnterpriseLicense, render.ECKOperatorNamespace, &corev1.Secret{}, nil},
+					{render.SnapshotRepositorySecretName, render.ElasticsearchNamespace, &corev1.Secret{}, nil},
+					{render.DexObjectName, render.ElasticsearchNamespace, &corev1.Secret{}, nil},
+					{render.KibanaElasticsearchUserSecret, render.KibanaNamespace, &corev1.Secret{}, nil},
+					{render.EsCuratorName, render.ElasticsearchNamespace, &batchv1beta.CronJob{}, nil},
+				})
 
 				resultES := rtest.GetResource(createResources, render.ElasticsearchName, render.ElasticsearchNamespace,
 					"elasticsearch.k8s.elastic.co", "v1", "Elasticsearch").(*esv1.Elasticsearch)
@@ -438,6 +514,8 @@ var _ = Describe("Elasticsearch rendering tests", func() {
 
 		Context("Elasticsearch and Kibana both ready", func() {
 			BeforeEach(func() {
+				curator := operatorv1.CuratorEnabled
+				cfg.LogStorage.Spec.Curator = &curator
 				cfg.CuratorSecrets = []*corev1.Secret{
 					{ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchCuratorUserSecret, Namespace: common.OperatorNamespace()}},
 					{ObjectMeta: metav1.ObjectMeta{Name: relasticsearch.PublicCertSecret, Namespace: common.OperatorNamespace()}},
@@ -475,6 +553,7 @@ var _ = Describe("Elasticsearch rendering tests", func() {
 					{"tigera-kibana", render.KibanaNamespace, &corev1.ServiceAccount{}, nil},
 					{"tigera-pull-secret", render.KibanaNamespace, &corev1.Secret{}, nil},
 					{render.KibanaName, render.KibanaNamespace, &kbv1.Kibana{}, nil},
+					{render.KibanaName, render.KibanaNamespace, &policyv1.PodDisruptionBudget{}, nil},
 					{render.EsCuratorPolicyName, render.ElasticsearchNamespace, &v3.NetworkPolicy{}, nil},
 					{render.ElasticsearchCuratorUserSecret, render.ElasticsearchNamespace, &corev1.Secret{}, nil},
 					{relasticsearch.PublicCertSecret, render.ElasticsearchNamespace, &corev1.Secret{}, nil},
@@ -491,8 +570,9 @@ var _ = Describe("Elasticsearch rendering tests", func() {
 				component := render.LogStorage(cfg)
 				createResources, deleteResources := component.Objects()
 
-				cronjob, ok := rtest.GetResource(createResources, "elastic-curator", "tigera-elasticsearch", "batch", "v1", "CronJob").(*batchv1beta.CronJob)
+				cronjob, ok := rtest.GetResource(createResources, "elastic-curator", "tigera-elasticsearch", "batch", "v1beta1", "CronJob").(*batchv1beta.CronJob)
 				Expect(ok).To(BeTrue())
+				Expect(*cronjob.Spec.Suspend).To(BeFalse())
 
 				Expect(cronjob.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Env).To(ContainElements([]corev1.EnvVar{
 					{Name: "EE_FLOWS_INDEX_RETENTION_PERIOD", Value: fmt.Sprint(1)},
@@ -506,7 +586,112 @@ var _ = Describe("Elasticsearch rendering tests", func() {
 				}))
 
 				compareResources(createResources, expectedCreateResources)
-				compareResources(deleteResources, []resourceTestObj{})
+				compareResources(deleteResources, []resourceTestObj{
+					{render.LogStoragePriorityClassName, "", &schedulingv1.PriorityClass{}, nil},
+					{render.ECKWebhookServiceName, render.ECKOperatorNamespace, &corev1.Service{}, nil},
+					{render.ECKWebhookConfigurationName, "", &admissionregistrationv1.ValidatingWebhookConfiguration{}, nil},
+					{render.ECKEnterpriseLicense, render.ECKOperatorNamespace, &corev1.Secret{}, nil},
+					{render.SnapshotRepositorySecretName, render.ElasticsearchNamespace, &corev1.Secret{}, nil},
+					{render.DexObjectName, render.ElasticsearchNamespace, &corev1.Secret{}, nil},
+					{render.KibanaElasticsearchUserSecret, render.KibanaNamespace, &corev1.Secret{}, nil},
+				})
+			})
+
+			It("should delete the curator when it isn't enabled", func() {
+				curator := operatorv1.CuratorDisabled
+				cfg.LogStorage.Spec.Curator = &curator
+				component := render.LogStorage(cfg)
+				createResources, deleteResources := component.Objects()
+
+				Expect(rtest.GetResource(createResources, render.EsCuratorName, render.ElasticsearchNamespace, "batch", "v1beta1", "CronJob")).To(BeNil())
+				Expect(rtest.GetResource(createResources, render.EsCuratorName, "", "rbac.authorization.k8s.io", "v1", "ClusterRole")).To(BeNil())
+				compareResources(deleteResources, []resourceTestObj{
+					{render.LogStoragePriorityClassName, "", &schedulingv1.PriorityClass{}, nil},
+					{render.ECKWebhookServiceName, render.ECKOperatorNamespace, &corev1.Service{}, nil},
+					{render.ECKWebhookConfigurationName, "", &admissionregistrationv1.ValidatingWebhookConfiguration{}, nil},
+					{render.ECKEnterpriseLicense, render.ECKOperatorNamespace, &corev1.Secret{}, nil},
+					{render.SnapshotRepositorySecretName, render.ElasticsearchNamespace, &corev1.Secret{}, nil},
+					{render.DexObjectName, render.ElasticsearchNamespace, &corev1.Secret{}, nil},
+					{render.KibanaElasticsearchUserSecret, render.KibanaNamespace, &corev1.Secret{}, nil},
+					{render.EsCuratorName, render.ElasticsearchNamespace, &batchv1beta.CronJob{}, nil},
+				})
+			})
+
+			It("should only render the PriorityClass of the log storage on the pods when it is enabled", func() {
+				cfg.LogStorage.Spec.ComponentPriorityClasses = []operatorv1.LogStorageComponentPriorityClass{
+					{ComponentName: operatorv1.ComponentNameKibana, PriorityClassName: "custom"},
+				}
+				createResources, deleteResources := render.LogStorage(cfg).Objects()
+				Expect(rtest.GetResource(createResources, render.LogStoragePriorityClassName, "", "scheduling.k8s.io", "v1", "PriorityClass")).To(BeNil())
+				Expect(rtest.GetResource(deleteResources, render.LogStoragePriorityClassName, "", "scheduling.k8s.io", "v1", "PriorityClass")).NotTo(BeNil())
+				Expect(getElasticsearch(createResources).Spec.NodeSets[0].PodTemplate.Spec.PriorityClassName).To(BeEmpty())
+				kb := rtest.GetResource(createResources, render.KibanaName, render.KibanaNamespace, "kibana.k8s.elastic.co", "v1", "Kibana").(*kbv1.Kibana)
+				Expect(kb.Spec.PodTemplate.Spec.PriorityClassName).To(Equal("custom"))
+
+				enabled := operatorv1.DefaultPriorityClassEnabled
+				cfg.LogStorage.Spec.DefaultPriorityClass = &enabled
+				createResources, deleteResources = render.LogStorage(cfg).Objects()
+				Expect(rtest.GetResource(deleteResources, render.LogStoragePriorityClassName, "", "scheduling.k8s.io", "v1", "PriorityClass")).To(BeNil())
+
+				priorityClass := rtest.GetResource(createResources, render.LogStoragePriorityClassName, "", "scheduling.k8s.io", "v1", "PriorityClass").(*schedulingv1.PriorityClass)
+				Expect(priorityClass.Value).To(Equal(int32(render.LogStoragePriorityClassValue)))
+				Expect(*priorityClass.PreemptionPolicy).To(Equal(corev1.PreemptNever))
+
+				Expect(getElasticsearch(createResources).Spec.NodeSets[0].PodTemplate.Spec.PriorityClassName).To(Equal(render.LogStoragePriorityClassName))
+				kb = rtest.GetResource(createResources, render.KibanaName, render.KibanaNamespace, "kibana.k8s.elastic.co", "v1", "Kibana").(*kbv1.Kibana)
+				Expect(kb.Spec.PodTemplate.Spec.PriorityClassName).To(Equal("custom"))
+				cronjob := rtest.GetResource(createResources, render.EsCuratorName, render.ElasticsearchNamespace, "batch", "v1beta1", "CronJob").(*batchv1beta.CronJob)
+				Expect(cronjob.Spec.JobTemplate.Spec.Template.Spec.PriorityClassName).To(Equal(render.LogStoragePriorityClassName))
+			})
+
+			It("should render the DNS configuration of the LogStorage on the curator pods", func() {
+				dnsConfig := &corev1.PodDNSConfig{Nameservers: []string{"169.254.20.10"}, Searches: []string{"svc.example.internal"}}
+				cfg.LogStorage.Spec.DNSConfig = dnsConfig
+				component := render.LogStorage(cfg)
+				createResources, _ := component.Objects()
+
+				cronjob := rtest.GetResource(createResources, render.EsCuratorName, render.ElasticsearchNamespace, "batch", "v1beta1", "CronJob").(*batchv1beta.CronJob)
+				Expect(cronjob.Spec.JobTemplate.Spec.Template.Spec.DNSConfig).To(Equal(dnsConfig))
+			})
+
+			It("should render the disruption policy of the curator on the curator pods", func() {
+				gracePeriod, preStopDelay := int64(45), int64(10)
+				cfg.LogStorage.Spec.ComponentDisruptionPolicies = []operatorv1.LogStorageComponentDisruptionPolicy{{
+					ComponentName: operatorv1.ComponentNameEsCurator,
+					DisruptionPolicy: operatorv1.DisruptionPolicy{
+						TerminationGracePeriodSeconds: &gracePeriod,
+						PreStopDelaySeconds:           &preStopDelay,
+					},
+				}}
+
+				component := render.LogStorage(cfg)
+				createResources, _ := component.Objects()
+
+				cronjob := rtest.GetResource(createResources, render.EsCuratorName, render.ElasticsearchNamespace, "batch", "v1beta1", "CronJob").(*batchv1beta.CronJob)
+				podSpec := cronjob.Spec.JobTemplate.Spec.Template.Spec
+				Expect(*podSpec.TerminationGracePeriodSeconds).To(Equal(int64(45)))
+				Expect(podSpec.Containers[0].Lifecycle.PreStop.Exec.Command).To(Equal([]string{"/bin/sh", "-c", "sleep 10"}))
+			})
+
+			It("should curate the indices of the cluster name of the cluster config", func() {
+				cfg.ClusterConfig = relasticsearch.NewClusterConfig("eu-west", 1, 1, 1)
+				component := render.LogStorage(cfg)
+				createResources, _ := component.Objects()
+
+				cronjob := rtest.GetResource(createResources, render.EsCuratorName, render.ElasticsearchNamespace, "batch", "v1beta1", "CronJob").(*batchv1beta.CronJob)
+				Expect(cronjob.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "ELASTIC_INDEX_SUFFIX", Value: "eu-west"}))
+			})
+
+			It("should render the curator as a batch/v1 CronJob when the cluster serves them", func() {
+				cfg.UseBatchV1CronJobs = true
+				component := render.LogStorage(cfg)
+				createResources, _ := component.Objects()
+
+				cronjob, ok := rtest.GetResource(createResources, render.EsCuratorName, render.ElasticsearchNamespace, "batch", "v1", "CronJob").(*batchv1.CronJob)
+				Expect(ok).To(BeTrue())
+				Expect(cronjob.Spec.Schedule).To(Equal("@hourly"))
+				Expect(*cronjob.Spec.Suspend).To(BeFalse())
+				Expect(cronjob.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Name).To(Equal(render.EsCuratorName))
 			})
 
 			Context("allow-tigera rendering", func() {
@@ -594,6 +779,75 @@ var _ = Describe("Elasticsearch rendering tests", func() {
 				newNodeName := rtest.GetResource(updatedResources, "tigera-secure", "tigera-elasticsearch", "elasticsearch.k8s.elastic.co", "v1", "Elasticsearch").(*esv1.Elasticsearch).Spec.NodeSets[0].Name
 				Expect(newNodeName).NotTo(Equal(oldNodeSetName))
 			})
+
+			Context("with the storage of the NodeSet changed", func() {
+				var current *esv1.Elasticsearch
+
+				setStorage := func(storage string) {
+					cfg.LogStorage.Spec.Nodes.ResourceRequirements = &corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{"storage": resource.MustParse(storage)},
+					}
+				}
+				renderElasticsearch := func() *esv1.Elasticsearch {
+					resources, _ := render.LogStorage(cfg).Objects()
+					return rtest.GetResource(resources, "tigera-secure", "tigera-elasticsearch", "elasticsearch.k8s.elastic.co", "v1", "Elasticsearch").(*esv1.Elasticsearch)
+				}
+
+				BeforeEach(func() {
+					cfg.LogStorage = &operatorv1.LogStorage{
+						ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"},
+						Spec: operatorv1.LogStorageSpec{
+							StorageClassName: "tigera-elasticsearch",
+							Nodes:            &operatorv1.Nodes{Count: 1},
+						},
+					}
+					setStorage("10Gi")
+					cfg.Elasticsearch = &esv1.Elasticsearch{}
+					current = renderElasticsearch()
+					cfg.Elasticsearch = current
+				})
+
+				It("should keep the NodeSet when the storage is increased on a StorageClass that allows volume expansion", func() {
+					cfg.ExpandableStorageClasses = map[string]bool{"tigera-elasticsearch": true}
+					setStorage("20Gi")
+
+					nodeSet := renderElasticsearch().Spec.NodeSets[0]
+					Expect(nodeSet.Name).To(Equal(current.Spec.NodeSets[0].Name))
+					Expect(nodeSet.VolumeClaimTemplates[0].Spec.Resources.Requests).To(HaveKeyWithValue(corev1.ResourceStorage, resource.MustParse("20Gi")))
+
+					// The NodeSet keeps its name once its volumes are expanded.
+					cfg.Elasticsearch = renderElasticsearch()
+					Expect(renderElasticsearch().Spec.NodeSets[0].Name).To(Equal(current.Spec.NodeSets[0].Name))
+
+					// The volumes can be expanded again.
+					cfg.Elasticsearch = renderElasticsearch()
+					setStorage("30Gi")
+					Expect(renderElasticsearch().Spec.NodeSets[0].Name).To(Equal(current.Spec.NodeSets[0].Name))
+				})
+
+				It("should create a new NodeSet when the storage is decreased", func() {
+					cfg.ExpandableStorageClasses = map[string]bool{"tigera-elasticsearch": true}
+					setStorage("5Gi")
+					Expect(renderElasticsearch().Spec.NodeSets[0].Name).NotTo(Equal(current.Spec.NodeSets[0].Name))
+				})
+
+				It("should create a new NodeSet when the StorageClass doesn't allow volume expansion", func() {
+					cfg.ExpandableStorageClasses = map[string]bool{"tigera-elasticsearch": false}
+					setStorage("20Gi")
+					Expect(renderElasticsearch().Spec.NodeSets[0].Name).NotTo(Equal(current.Spec.NodeSets[0].Name))
+				})
+
+				It("should keep the hash annotations of the current NodeSets while rollouts are deferred", func() {
+					cfg.ElasticsearchUserSecret = &corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure-es-elastic-user", Namespace: render.ElasticsearchNamespace},
+						Data:       map[string][]byte{"elastic": []byte("rotated")},
+					}
+					Expect(renderElasticsearch().Spec.NodeSets[0].PodTemplate.Annotations).NotTo(Equal(current.Spec.NodeSets[0].PodTemplate.Annotations))
+
+					cfg.DeferRollouts = true
+					Expect(renderElasticsearch().Spec.NodeSets[0].PodTemplate.Annotations).To(Equal(current.Spec.NodeSets[0].PodTemplate.Annotations))
+				})
+			})
 		})
 
 		It("should render DataNodeSelectors defined in the LogStorage CR", func() {
@@ -610,6 +864,235 @@ var _ = Describe("Elasticsearch rendering tests", func() {
 			Expect(nodeSelectors["k2"]).To(Equal("v2"))
 		})
 
+		It("should render the transport TLS defined in the LogStorage CR", func() {
+			verificationMode := operatorv1.TransportTLSVerificationFull
+			cfg.LogStorage.Spec.TransportTLS = &operatorv1.ElasticsearchTransportTLS{
+				VerificationMode:   &verificationMode,
+				SupportedProtocols: []string{"TLSv1.3", "TLSv1.2"},
+				CipherSuites:       []string{"TLS_AES_256_GCM_SHA384", "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"},
+			}
+			component := render.LogStorage(cfg)
+
+			createResources, _ := component.Objects()
+			config := getElasticsearch(createResources).Spec.NodeSets[0].Config.Data
+			Expect(config["xpack.security.transport.ssl.verification_mode"]).To(Equal("full"))
+			Expect(config["xpack.security.transport.ssl.supported_protocols"]).To(Equal([]string{"TLSv1.3", "TLSv1.2"}))
+			Expect(config["xpack.security.transport.ssl.cipher_suites"]).To(Equal([]string{"TLS_AES_256_GCM_SHA384", "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"}))
+		})
+
+		It("should render the remote clusters defined in the LogStorage CR", func() {
+			cfg.LogStorage.Spec.RemoteClusters = []operatorv1.RemoteElasticsearchCluster{
+				{Alias: "cluster-a", Seeds: []string{"es.cluster-a.example.com:9300"}, CertificateAuthoritySecretName: "cluster-a-ca"},
+				{Alias: "cluster-b", Seeds: []string{"10.0.0.1:9300"}},
+			}
+			cfg.RemoteClusterCASecrets = []*corev1.Secret{{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster-a-ca", Namespace: common.OperatorNamespace()},
+				Data:       map[string][]byte{corev1.TLSCertKey: []byte("ca")},
+			}}
+			component := render.LogStorage(cfg)
+
+			createResources, _ := component.Objects()
+			Expect(rtest.GetResource(createResources, "cluster-a-ca", render.ElasticsearchNamespace, "", "v1", "Secret")).NotTo(BeNil())
+
+			nodeSet := getElasticsearch(createResources).Spec.NodeSets[0]
+			Expect(nodeSet.Config.Data["cluster.remote.cluster-a.seeds"]).To(Equal([]string{"es.cluster-a.example.com:9300"}))
+			Expect(nodeSet.Config.Data["cluster.remote.cluster-b.seeds"]).To(Equal([]string{"10.0.0.1:9300"}))
+			Expect(nodeSet.Config.Data["xpack.security.transport.ssl.certificate_authorities"]).To(Equal([]string{
+				"/usr/share/elasticsearch/config/transport-certs/ca.crt",
+				"/usr/share/elasticsearch/config/transport-remote-certs/ca.crt",
+				"/usr/share/elasticsearch/config/remote-cluster-cas/cluster-a-ca/tls.crt",
+			}))
+
+			podSpec := nodeSet.PodTemplate.Spec
+			Expect(podSpec.Volumes).To(ContainElement(corev1.Volume{
+				Name: "remote-cluster-cas",
+				VolumeSource: corev1.VolumeSource{
+					Projected: &corev1.ProjectedVolumeSource{
+						Sources: []corev1.VolumeProjection{{
+							Secret: &corev1.SecretProjection{
+								LocalObjectReference: corev1.LocalObjectReference{Name: "cluster-a-ca"},
+								Items:                []corev1.KeyToPath{{Key: corev1.TLSCertKey, Path: "cluster-a-ca/tls.crt"}},
+							},
+						}},
+					},
+				},
+			}))
+			Expect(podSpec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{
+				Name:      "remote-cluster-cas",
+				MountPath: "/usr/share/elasticsearch/config/remote-cluster-cas",
+				ReadOnly:  true,
+			}))
+			Expect(nodeSet.PodTemplate.Annotations).To(HaveKey(render.ElasticsearchRemoteClusterCAHashAnnotation))
+
+			policy := rtest.GetResource(createResources, render.ElasticsearchPolicyName, render.ElasticsearchNamespace, "projectcalico.org", "v3", "NetworkPolicy").(*v3.NetworkPolicy)
+			Expect(policy.Spec.Egress).To(ContainElement(v3.Rule{
+				Action:      v3.Allow,
+				Protocol:    &networkpolicy.TCPProtocol,
+				Destination: v3.EntityRule{Domains: []string{"es.cluster-a.example.com"}, Ports: networkpolicy.Ports(9300)},
+			}))
+			Expect(policy.Spec.Egress).To(ContainElement(v3.Rule{
+				Action:      v3.Allow,
+				Protocol:    &networkpolicy.TCPProtocol,
+				Destination: v3.EntityRule{Nets: []string{"10.0.0.1/32"}, Ports: networkpolicy.Ports(9300)},
+			}))
+		})
+
+		It("should render the remote clusters that are reached through a proxy address", func() {
+			cfg.LogStorage.Spec.RemoteClusters = []operatorv1.RemoteElasticsearchCluster{
+				{Alias: "us-east", ProxyAddress: "es.us-east.example.com:9400", ServerName: "es.us-east.example.com"},
+			}
+			component := render.LogStorage(cfg)
+
+			createResources, _ := component.Objects()
+			config := getElasticsearch(createResources).Spec.NodeSets[0].Config.Data
+			Expect(config["cluster.remote.us-east.mode"]).To(Equal("proxy"))
+			Expect(config["cluster.remote.us-east.proxy_address"]).To(Equal("es.us-east.example.com:9400"))
+			Expect(config["cluster.remote.us-east.server_name"]).To(Equal("es.us-east.example.com"))
+			Expect(config).NotTo(HaveKey("cluster.remote.us-east.seeds"))
+
+			policy := rtest.GetResource(createResources, render.ElasticsearchPolicyName, render.ElasticsearchNamespace, "projectcalico.org", "v3", "NetworkPolicy").(*v3.NetworkPolicy)
+			Expect(policy.Spec.Egress).To(ContainElement(v3.Rule{
+				Action:      v3.Allow,
+				Protocol:    &networkpolicy.TCPProtocol,
+				Destination: v3.EntityRule{Domains: []string{"es.us-east.example.com"}, Ports: networkpolicy.Ports(9400)},
+			}))
+		})
+
+		It("should add the credentials of the snapshot repository to the keystore of Elasticsearch", func() {
+			cfg.LogStorage.Spec.Snapshots = &operatorv1.Snapshots{
+				Repository: operatorv1.SnapshotRepository{Type: operatorv1.SnapshotRepositoryS3, Bucket: "logs", SecretName: "s3-credentials"},
+			}
+			cfg.SnapshotRepositorySecret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "s3-credentials", Namespace: common.OperatorNamespace()},
+				Data:       map[string][]byte{"access_key": []byte("id"), "secret_key": []byte("secret")},
+			}
+			component := render.LogStorage(cfg)
+
+			createResources, deleteResources := component.Objects()
+			copied := rtest.GetResource(createResources, render.SnapshotRepositorySecretName, render.ElasticsearchNamespace, "", "v1", "Secret").(*corev1.Secret)
+			Expect(copied.Data).To(Equal(cfg.SnapshotRepositorySecret.Data))
+			Expect(deleteResources).NotTo(ContainElement(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: render.SnapshotRepositorySecretName, Namespace: render.ElasticsearchNamespace}}))
+			Expect(getElasticsearch(createResources).Spec.SecureSettings).To(Equal([]cmnv1.SecretSource{{
+				SecretName: render.SnapshotRepositorySecretName,
+				Entries: []cmnv1.KeyToPath{
+					{Key: "access_key", Path: "s3.client.default.access_key"},
+					{Key: "secret_key", Path: "s3.client.default.secret_key"},
+				},
+			}}))
+
+			policy := rtest.GetResource(createResources, render.ElasticsearchPolicyName, render.ElasticsearchNamespace, "projectcalico.org", "v3", "NetworkPolicy").(*v3.NetworkPolicy)
+			Expect(policy.Spec.Egress).To(ContainElement(v3.Rule{
+				Action:      v3.Allow,
+				Protocol:    &networkpolicy.TCPProtocol,
+				Destination: v3.EntityRule{Domains: []string{"*.amazonaws.com"}, Ports: networkpolicy.Ports(443)},
+			}))
+			Expect(policy.Spec.Egress).NotTo(ContainElement(v3.Rule{
+				Action:      v3.Allow,
+				Protocol:    &networkpolicy.TCPProtocol,
+				Destination: v3.EntityRule{Ports: networkpolicy.Ports(443)},
+			}))
+		})
+
+		It("should delete the copy of the secret of the snapshot repository when the LogStorage doesn't take snapshots", func() {
+			component := render.LogStorage(cfg)
+
+			createResources, deleteResources := component.Objects()
+			Expect(rtest.GetResource(createResources, render.SnapshotRepositorySecretName, render.ElasticsearchNamespace, "", "v1", "Secret")).To(BeNil())
+			Expect(deleteResources).To(ContainElement(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: render.SnapshotRepositorySecretName, Namespace: render.ElasticsearchNamespace}}))
+		})
+
+		It("should add the keys of the secure settings secrets to the keystore of Elasticsearch", func() {
+			cfg.LogStorage.Spec.SecureSettings = []operatorv1.SecureSettingsSource{
+				{SecretName: "saml-keys"},
+				{SecretName: "s3-keys", Entries: []operatorv1.SecureSettingsEntry{{Key: "id", Setting: "s3.client.archive.access_key"}}},
+			}
+			cfg.SecureSettingsSecrets = []*corev1.Secret{
+				{TypeMeta: metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"}, ObjectMeta: metav1.ObjectMeta{Name: "saml-keys", Namespace: common.OperatorNamespace()}},
+				{TypeMeta: metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"}, ObjectMeta: metav1.ObjectMeta{Name: "s3-keys", Namespace: common.OperatorNamespace()}},
+			}
+			component := render.LogStorage(cfg)
+
+			createResources, _ := component.Objects()
+			for _, name := range []string{"saml-keys", "s3-keys"} {
+				copied := rtest.GetResource(createResources, name, render.ElasticsearchNamespace, "", "v1", "Secret").(*corev1.Secret)
+				Expect(copied.Labels).To(HaveKeyWithValue(render.SecureSettingsSecretLabel, "true"))
+			}
+			Expect(getElasticsearch(createResources).Spec.SecureSettings).To(Equal([]cmnv1.SecretSource{
+				{SecretName: "saml-keys"},
+				{SecretName: "s3-keys", Entries: []cmnv1.KeyToPath{{Key: "id", Path: "s3.client.archive.access_key"}}},
+			}))
+		})
+
+		It("should log users in to Kibana through Dex when it proxies an OIDC provider", func() {
+			cfg.BaseURL = "https://manager.example.com"
+			cfg.Authentication = &operatorv1.Authentication{Spec: operatorv1.AuthenticationSpec{
+				ManagerDomain: "https://manager.example.com",
+				OIDC:          &operatorv1.AuthenticationOIDC{IssuerURL: "https://idp.example.com", UsernameClaim: "email"},
+			}}
+			cfg.DexSecret = &corev1.Secret{
+				TypeMeta:   metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+				ObjectMeta: metav1.ObjectMeta{Name: render.DexObjectName, Namespace: common.OperatorNamespace()},
+				Data:       map[string][]byte{render.ClientSecretSecretField: []byte("secret")},
+			}
+			component := render.LogStorage(cfg)
+
+			createResources, _ := component.Objects()
+			Expect(rtest.GetResource(createResources, render.DexObjectName, render.ElasticsearchNamespace, "", "v1", "Secret")).NotTo(BeNil())
+
+			es := getElasticsearch(createResources)
+			Expect(es.Spec.SecureSettings).To(ContainElement(cmnv1.SecretSource{
+				SecretName: render.DexObjectName,
+				Entries:    []cmnv1.KeyToPath{{Key: render.ClientSecretSecretField, Path: "xpack.security.authc.realms.oidc.oidc1.rp.client_secret"}},
+			}))
+			config := es.Spec.NodeSets[0].Config.Data
+			Expect(config).To(HaveKeyWithValue("xpack.security.authc.realms.oidc.oidc1.op.issuer", "https://manager.example.com/dex"))
+			Expect(config).To(HaveKeyWithValue("xpack.security.authc.realms.oidc.oidc1.rp.redirect_uri", "https://manager.example.com/tigera-kibana/api/security/oidc/callback"))
+			Expect(config).To(HaveKeyWithValue("xpack.security.authc.realms.oidc.oidc1.claims.principal", "email"))
+			// The implicit realms are disabled by the OIDC realm, so they are configured explicitly.
+			Expect(config).To(HaveKeyWithValue("xpack.security.authc.realms.file.file1.order", BeEquivalentTo(0)))
+			Expect(config).To(HaveKeyWithValue("xpack.security.authc.realms.native.native1.order", BeEquivalentTo(1)))
+			Expect(config).To(HaveKeyWithValue("xpack.security.authc.realms.oidc.oidc1.authorization_realms", "native1"))
+
+			kb := rtest.GetResource(createResources, render.KibanaName, render.KibanaNamespace, "kibana.k8s.elastic.co", "v1", "Kibana").(*kbv1.Kibana)
+			Expect(kb.Spec.Config.Data).To(HaveKey("xpack.security.authc.providers"))
+		})
+
+		It("should not log users in to Kibana through Dex for the Tigera OIDC type", func() {
+			cfg.BaseURL = "https://manager.example.com"
+			cfg.Authentication = &operatorv1.Authentication{Spec: operatorv1.AuthenticationSpec{
+				OIDC: &operatorv1.AuthenticationOIDC{IssuerURL: "https://idp.example.com", UsernameClaim: "email", Type: operatorv1.OIDCTypeTigera},
+			}}
+			cfg.DexSecret = &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: render.DexObjectName, Namespace: common.OperatorNamespace()}}
+			component := render.LogStorage(cfg)
+
+			createResources, deleteResources := component.Objects()
+			Expect(getElasticsearch(createResources).Spec.NodeSets[0].Config.Data).NotTo(HaveKey("xpack.security.authc.realms.oidc.oidc1.op.issuer"))
+			Expect(rtest.GetResource(createResources, render.DexObjectName, render.ElasticsearchNamespace, "", "v1", "Secret")).To(BeNil())
+			Expect(rtest.GetResource(deleteResources, render.DexObjectName, render.ElasticsearchNamespace, "", "v1", "Secret")).NotTo(BeNil())
+			kb := rtest.GetResource(createResources, render.KibanaName, render.KibanaNamespace, "kibana.k8s.elastic.co", "v1", "Kibana").(*kbv1.Kibana)
+			Expect(kb.Spec.Config.Data).NotTo(HaveKey("xpack.security.authc.providers"))
+		})
+
+		It("should apply the container overrides of the LogStorage CR", func() {
+			cfg.ContainerOverrides = rcomp.ContainerOverrides{
+				render.ECKOperatorContainerOverridesKey:   {Args: []string{"--log-verbosity=1"}},
+				render.ElasticsearchContainerOverridesKey: {Env: []corev1.EnvVar{{Name: "EXTRA", Value: "true"}}},
+			}
+			component := render.LogStorage(cfg)
+
+			createResources, _ := component.Objects()
+			statefulSet := rtest.GetResource(createResources, render.ECKOperatorName, render.ECKOperatorNamespace, "apps", "v1", "StatefulSet").(*appsv1.StatefulSet)
+			Expect(statefulSet.Spec.Template.Spec.Containers[0].Args).To(ContainElement("--log-verbosity=1"))
+			Expect(statefulSet.Spec.Template.Annotations).To(HaveKey(rcomp.ContainerOverridesHashAnnotation))
+
+			podTemplate := getElasticsearch(createResources).Spec.NodeSets[0].PodTemplate
+			Expect(podTemplate.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "EXTRA", Value: "true"}))
+			Expect(podTemplate.Annotations).To(HaveKey(rcomp.ContainerOverridesHashAnnotation))
+
+			kb := rtest.GetResource(createResources, render.KibanaName, render.KibanaNamespace, "kibana.k8s.elastic.co", "v1", "Kibana").(*kbv1.Kibana)
+			Expect(kb.Spec.PodTemplate.Annotations).NotTo(HaveKey(rcomp.ContainerOverridesHashAnnotation))
+		})
+
 		It("should configures Kibana publicBaseUrl when BaseURL is specified", func() {
 			cfg.ElasticLicenseType = render.ElasticsearchLicenseTypeBasic
 			cfg.BaseURL = "https://test.domain.com"
@@ -624,6 +1107,82 @@ var _ = Describe("Elasticsearch rendering tests", func() {
 			Expect(x["publicBaseUrl"]).To(Equal("https://test.domain.com/tigera-kibana"))
 		})
 
+		It("should listen on the IPv6 wildcard address in IPv6-only clusters", func() {
+			cfg.Installation.CalicoNetwork = &operatorv1.CalicoNetworkSpec{
+				IPPools: []operatorv1.IPPool{{CIDR: "fd00:10:244::/64"}},
+			}
+			component := render.LogStorage(cfg)
+
+			createResources, _ := component.Objects()
+			Expect(getElasticsearch(createResources).Spec.NodeSets[0].Config.Data).To(HaveKeyWithValue("network.host", "::"))
+			kb := rtest.GetResource(createResources, render.KibanaName, render.KibanaNamespace, "kibana.k8s.elastic.co", "v1", "Kibana").(*kbv1.Kibana)
+			Expect(kb.Spec.Config.Data["server"]).To(HaveKeyWithValue("host", "::"))
+			eck := rtest.GetResource(createResources, render.ECKOperatorName, render.ECKOperatorNamespace, "apps", "v1", "StatefulSet").(*appsv1.StatefulSet)
+			Expect(eck.Spec.Template.Spec.Containers[0].Args).To(ContainElement("--ip-family=IPv6"))
+		})
+
+		It("should keep the IPv4 defaults in dual-stack clusters", func() {
+			cfg.Installation.CalicoNetwork = &operatorv1.CalicoNetworkSpec{
+				IPPools: []operatorv1.IPPool{{CIDR: "10.244.0.0/16"}, {CIDR: "fd00:10:244::/64"}},
+			}
+			component := render.LogStorage(cfg)
+
+			createResources, _ := component.Objects()
+			Expect(getElasticsearch(createResources).Spec.NodeSets[0].Config.Data).NotTo(HaveKey("network.host"))
+			kb := rtest.GetResource(createResources, render.KibanaName, render.KibanaNamespace, "kibana.k8s.elastic.co", "v1", "Kibana").(*kbv1.Kibana)
+			Expect(kb.Spec.Config.Data["server"]).NotTo(HaveKey("host"))
+			eck := rtest.GetResource(createResources, render.ECKOperatorName, render.ECKOperatorNamespace, "apps", "v1", "StatefulSet").(*appsv1.StatefulSet)
+			Expect(eck.Spec.Template.Spec.Containers[0].Args).NotTo(ContainElement(ContainSubstring("--ip-family")))
+		})
+
+		It("should copy the enterprise license to the namespace of the ECK operator instead of the trial", func() {
+			cfg.ApplyTrial = true
+			cfg.EnterpriseLicenseSecret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "elastic-license", Namespace: common.OperatorNamespace()},
+				Data:       map[string][]byte{render.ECKLicenseKey: []byte(`{"license":{"type":"enterprise"}}`)},
+			}
+			component := render.LogStorage(cfg)
+
+			createResources, deleteResources := component.Objects()
+			for _, obj := range createResources {
+				Expect(obj.GetName()).NotTo(Equal(render.ECKEnterpriseTrial))
+			}
+			Expect(rtest.GetResource(deleteResources, render.ECKEnterpriseLicense, render.ECKOperatorNamespace, "", "v1", "Secret")).To(BeNil())
+			license := rtest.GetResource(createResources, render.ECKEnterpriseLicense, render.ECKOperatorNamespace, "", "v1", "Secret").(*corev1.Secret)
+			Expect(license.Labels).To(HaveKeyWithValue("license.k8s.elastic.co/scope", "operator"))
+			Expect(license.Data).To(Equal(map[string][]byte{render.ECKLicenseKey: []byte(`{"license":{"type":"enterprise"}}`)}))
+		})
+
+		It("should render the validating webhook of the ECK operator when it is enabled", func() {
+			enabled := operatorv1.ECKValidatingWebhookEnabled
+			cfg.LogStorage.Spec.ECKOperator = &operatorv1.ECKOperatorSpec{ValidatingWebhook: &enabled}
+			secret, err := certificatemanagement.CreateSelfSignedSecret(render.ECKWebhookCertSecret, common.OperatorNamespace(), "", nil)
+			Expect(err).NotTo(HaveOccurred())
+			cfg.ECKWebhookKeyPair = certificatemanagement.NewKeyPair(secret, nil, dns.DefaultClusterDomain)
+			component := render.LogStorage(cfg)
+
+			createResources, deleteResources := component.Objects()
+			Expect(rtest.GetResource(deleteResources, render.ECKWebhookServiceName, render.ECKOperatorNamespace, "", "v1", "Service")).To(BeNil())
+			svc := rtest.GetResource(createResources, render.ECKWebhookServiceName, render.ECKOperatorNamespace, "", "v1", "Service").(*corev1.Service)
+			Expect(svc.Spec.Ports[0].TargetPort.IntValue()).To(Equal(render.ECKWebhookPort))
+
+			webhooks := rtest.GetResource(createResources, render.ECKWebhookConfigurationName, "", "admissionregistration.k8s.io", "v1", "ValidatingWebhookConfiguration").(*admissionregistrationv1.ValidatingWebhookConfiguration)
+			Expect(webhooks.Webhooks).To(HaveLen(2))
+			for _, webhook := range webhooks.Webhooks {
+				Expect(webhook.ClientConfig.Service.Name).To(Equal(render.ECKWebhookServiceName))
+				Expect(webhook.ClientConfig.CABundle).To(Equal(secret.Data[corev1.TLSCertKey]))
+				Expect(*webhook.FailurePolicy).To(Equal(admissionregistrationv1.Ignore))
+			}
+
+			eck := rtest.GetResource(createResources, render.ECKOperatorName, render.ECKOperatorNamespace, "apps", "v1", "StatefulSet").(*appsv1.StatefulSet)
+			Expect(eck.Spec.Template.Spec.Containers[0].Args).To(ContainElements("--enable-webhook=true", "--webhook-cert-dir=/"+render.ECKWebhookCertSecret))
+			Expect(eck.Spec.Template.Spec.Containers[0].Args).NotTo(ContainElement("--enable-webhook=false"))
+			Expect(eck.Spec.Template.Spec.Volumes).To(ContainElement(cfg.ECKWebhookKeyPair.Volume()))
+
+			policy := rtest.GetResource(createResources, render.ECKOperatorPolicyName, render.ECKOperatorNamespace, "projectcalico.org", "v3", "NetworkPolicy").(*v3.NetworkPolicy)
+			Expect(policy.Spec.Ingress).To(HaveLen(1))
+		})
+
 		Context("ECKOperator memory requests/limits", func() {
 			When("LogStorage Spec contains an entry for ECKOperator in ComponentResources", func() {
 				It("should set matching memory requests/limits in the elastic-operator StatefulSet.Spec manager container", func() {
@@ -664,8 +1223,46 @@ var _ = Describe("Elasticsearch rendering tests", func() {
 					}
 				})
 			})
+
+			When("LogStorage Spec contains ECKOperator overrides", func() {
+				It("should apply the resources and scheduling overrides to the elastic-operator StatefulSet", func() {
+					tolerations := []corev1.Toleration{{Key: "logging", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}}
+					cfg.Installation.ControlPlaneNodeSelector = map[string]string{"control-plane": "true"}
+					cfg.LogStorage.Spec.ComponentResources = []operatorv1.LogStorageComponentResource{
+						{
+							ComponentName: operatorv1.ComponentNameECKOperator,
+							ResourceRequirements: &corev1.ResourceRequirements{
+								Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("512Mi")},
+							},
+						},
+					}
+					cfg.LogStorage.Spec.ECKOperator = &operatorv1.ECKOperatorSpec{
+						Resources: &corev1.ResourceRequirements{
+							Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2"), corev1.ResourceMemory: resource.MustParse("1Gi")},
+							Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m"), corev1.ResourceMemory: resource.MustParse("1Gi")},
+						},
+						NodeSelector:      map[string]string{"logging": "true"},
+						Tolerations:       tolerations,
+						PriorityClassName: "logging-critical",
+					}
+
+					component := render.LogStorage(cfg)
+
+					createResources, _ := component.Objects()
+
+					statefulSet := rtest.GetResource(createResources, render.ECKOperatorName, render.ECKOperatorNamespace, "apps", "v1", "StatefulSet").(*appsv1.StatefulSet)
+					podSpec := statefulSet.Spec.Template.Spec
+					Expect(podSpec.NodeSelector).To(Equal(map[string]string{"logging": "true"}))
+					Expect(podSpec.Tolerations).To(Equal(tolerations))
+					Expect(podSpec.PriorityClassName).To(Equal("logging-critical"))
+					Expect(podSpec.Containers[0].Resources).To(Equal(corev1.ResourceRequirements{
+						Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2"), corev1.ResourceMemory: resource.MustParse("1Gi")},
+						Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m"), corev1.ResourceMemory: resource.MustParse("1Gi")},
+					}))
+				})
+			})
 		})
-		It("should not render kibana if FIPS mode is enabled", func() {
+		It("should render a FIPS compliant kibana if FIPS mode is enabled", func() {
 			fipsEnabled := operatorv1.FIPSModeEnabled
 			cfg.Installation.FIPSMode = &fipsEnabled
 			cfg.LogStorage.Spec.Nodes.ResourceRequirements = &corev1.ResourceRequirements{
@@ -694,6 +1291,9 @@ var _ = Describe("Elasticsearch rendering tests", func() {
 				{"tigera-elasticsearch", "", &rbacv1.ClusterRole{}, nil},
 				{render.ECKOperatorName, "", &policyv1beta1.PodSecurityPolicy{}, nil},
 				{"tigera-elasticsearch", "", &policyv1beta1.PodSecurityPolicy{}, nil},
+				{"tigera-kibana", "", &rbacv1.ClusterRoleBinding{}, nil},
+				{"tigera-kibana", "", &rbacv1.ClusterRole{}, nil},
+				{"tigera-kibana", "", &policyv1beta1.PodSecurityPolicy{}, nil},
 				{render.ECKEnterpriseTrial, render.ECKOperatorNamespace, &corev1.Secret{}, nil},
 				{render.ECKOperatorName, render.ECKOperatorNamespace, &appsv1.StatefulSet{}, nil},
 				{render.ElasticsearchNamespace, "", &corev1.Namespace{}, nil},
@@ -706,6 +1306,13 @@ var _ = Describe("Elasticsearch rendering tests", func() {
 				{render.ElasticsearchName, render.ElasticsearchNamespace, &esv1.Elasticsearch{}, nil},
 				{render.ElasticsearchKeystoreSecret, common.OperatorNamespace(), &corev1.Secret{}, nil},
 				{render.ElasticsearchKeystoreSecret, render.ElasticsearchNamespace, &corev1.Secret{}, nil},
+				{render.KibanaNamespace, "", &corev1.Namespace{}, nil},
+				{render.KibanaPolicyName, render.KibanaNamespace, &v3.NetworkPolicy{}, nil},
+				{networkpolicy.TigeraComponentDefaultDenyPolicyName, render.KibanaNamespace, &v3.NetworkPolicy{}, nil},
+				{"tigera-kibana", render.KibanaNamespace, &corev1.ServiceAccount{}, nil},
+				{"tigera-pull-secret", render.KibanaNamespace, &corev1.Secret{}, nil},
+				{render.KibanaName, render.KibanaNamespace, &kbv1.Kibana{}, nil},
+				{render.KibanaName, render.KibanaNamespace, &policyv1.PodDisruptionBudget{}, nil},
 				{render.EsManagerRole, render.ElasticsearchNamespace, &rbacv1.Role{}, nil},
 				{render.EsManagerRoleBinding, render.ElasticsearchNamespace, &rbacv1.RoleBinding{}, nil},
 			}
@@ -716,10 +1323,21 @@ var _ = Describe("Elasticsearch rendering tests", func() {
 
 			compareResources(createResources, expectedCreateResources)
 			compareResources(deleteResources, []resourceTestObj{
-				{render.KibanaName, render.KibanaNamespace, &kbv1.Kibana{}, nil},
+				{render.LogStoragePriorityClassName, "", &schedulingv1.PriorityClass{}, nil},
+				{render.ECKWebhookServiceName, render.ECKOperatorNamespace, &corev1.Service{}, nil},
+				{render.ECKWebhookConfigurationName, "", &admissionregistrationv1.ValidatingWebhookConfiguration{}, nil},
+				{render.ECKEnterpriseLicense, render.ECKOperatorNamespace, &corev1.Secret{}, nil},
+				{render.SnapshotRepositorySecretName, render.ElasticsearchNamespace, &corev1.Secret{}, nil},
+				{render.DexObjectName, render.ElasticsearchNamespace, &corev1.Secret{}, nil},
+				{render.KibanaElasticsearchUserSecret, render.KibanaNamespace, &corev1.Secret{}, nil},
 				{render.EsCuratorName, render.ElasticsearchNamespace, &batchv1beta.CronJob{}, nil},
 			})
 
+			kb := rtest.GetResource(createResources, render.KibanaName, render.KibanaNamespace, "kibana.k8s.elastic.co", "v1", "Kibana").(*kbv1.Kibana)
+			Expect(kb.Spec.Image).NotTo(ContainSubstring("-fips"))
+			Expect(kb.Spec.Config.Data).NotTo(HaveKey("xpack.security.fipsMode.enabled"))
+			Expect(kb.Spec.Config.Data["server"]).To(HaveKey("ssl"))
+
 			es := getElasticsearch(createResources)
 			esContainer := es.Spec.NodeSets[0].PodTemplate.Spec.Containers[0]
 			Expect(esContainer.Env).Should(ContainElement(corev1.EnvVar{
@@ -810,7 +1428,9 @@ var _ = Describe("Elasticsearch rendering tests", func() {
 				createResources, deleteResources := component.Objects()
 
 				compareResources(createResources, expectedCreateResources)
-				compareResources(deleteResources, []resourceTestObj{})
+				compareResources(deleteResources, []resourceTestObj{
+					{render.LogStoragePriorityClassName, "", &schedulingv1.PriorityClass{}, nil},
+				})
 			})
 		})
 		Context("Deleting LogStorage", deleteLogStorageTests(nil, managementClusterConnection))
@@ -917,6 +1537,34 @@ var _ = Describe("Elasticsearch rendering tests", func() {
 					}
 				})
 			})
+			When("the storage of a NodeSet is overridden", func() {
+				It("renders the storage of the NodeSet into its own PVC template and name", func() {
+					storageSize := resource.MustParse("500Gi")
+					cfg.LogStorage.Spec.Nodes = &operatorv1.Nodes{
+						Count: 2,
+						NodeSets: []operatorv1.NodeSet{
+							{StorageClassName: "local-nvme", StorageSize: &storageSize},
+							{},
+						},
+					}
+
+					component := render.LogStorage(cfg)
+
+					createResources, _ := component.Objects()
+					nodeSets := getElasticsearch(createResources).Spec.NodeSets
+
+					Expect(len(nodeSets)).Should(Equal(2))
+					hot := nodeSets[0].VolumeClaimTemplates[0]
+					Expect(*hot.Spec.StorageClassName).Should(Equal("local-nvme"))
+					Expect(hot.Spec.Resources.Requests[corev1.ResourceStorage]).Should(Equal(storageSize))
+
+					warm := nodeSets[1].VolumeClaimTemplates[0]
+					Expect(*warm.Spec.StorageClassName).Should(Equal(cfg.LogStorage.Spec.StorageClassName))
+					Expect(warm.Spec.Resources.Requests[corev1.ResourceStorage]).Should(Equal(resource.MustParse("10Gi")))
+
+					Expect(strings.TrimSuffix(nodeSets[0].Name, "-0")).ShouldNot(Equal(strings.TrimSuffix(nodeSets[1].Name, "-1")))
+				})
+			})
 			When("the number of Nodes is 2 and the number of NodeSets is 3", func() {
 				It("creates 2 1 Node NodeSets", func() {
 					cfg.LogStorage.Spec.Nodes = &operatorv1.Nodes{
@@ -1141,6 +1789,244 @@ var _ = Describe("Elasticsearch rendering tests", func() {
 					Expect(pvcResource).Should(Equal(expected))
 				})
 			})
+			When("the provider has resource presets", func() {
+				BeforeEach(func() {
+					cfg.Provider = operatorv1.ProviderEKS
+					presets := operatorv1.ProviderResourcePresetsEnabled
+					cfg.LogStorage.Spec.ProviderResourcePresets = &presets
+				})
+
+				It("uses the default resources unless the presets are enabled", func() {
+					cfg.LogStorage.Spec.ProviderResourcePresets = nil
+
+					component := render.LogStorage(cfg)
+
+					createResources, _ := component.Objects()
+					pod := getElasticsearch(createResources).Spec.NodeSets[0].PodTemplate.Spec.Containers[0]
+					Expect(pod.Resources.Requests.Memory().String()).To(Equal("4Gi"))
+					Expect(pod.Resources.Limits.Cpu().String()).To(Equal("1"))
+				})
+
+				It("sets the memory and cpu requirements of the preset in pod template", func() {
+					expectedRes := corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{
+							"cpu":    resource.MustParse("2"),
+							"memory": resource.MustParse("8Gi"),
+						},
+						Requests: corev1.ResourceList{
+							"cpu":    resource.MustParse("1"),
+							"memory": resource.MustParse("8Gi"),
+						},
+					}
+
+					component := render.LogStorage(cfg)
+
+					createResources, _ := component.Objects()
+					pod := getElasticsearch(createResources).Spec.NodeSets[0].PodTemplate.Spec.Containers[0]
+					Expect(pod.Resources).Should(Equal(expectedRes))
+					Expect(pod.Env[0].Value).To(Equal("-Xms4G -Xmx4G"))
+				})
+
+				It("overrides the preset with the ResourceRequirements and componentResources of the LogStorage", func() {
+					cfg.LogStorage.Spec.Nodes = &operatorv1.Nodes{
+						Count: 1,
+						ResourceRequirements: &corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								"cpu": resource.MustParse("500m"),
+							},
+						},
+					}
+					kibanaRes := corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{
+							"memory": resource.MustParse("4Gi"),
+						},
+						Requests: corev1.ResourceList{
+							"memory": resource.MustParse("4Gi"),
+						},
+					}
+					cfg.LogStorage.Spec.ComponentResources = []operatorv1.LogStorageComponentResource{
+						{ComponentName: operatorv1.ComponentNameKibana, ResourceRequirements: &kibanaRes},
+					}
+
+					component := render.LogStorage(cfg)
+
+					createResources, _ := component.Objects()
+					pod := getElasticsearch(createResources).Spec.NodeSets[0].PodTemplate.Spec.Containers[0]
+					Expect(pod.Resources.Requests.Cpu().String()).To(Equal("500m"))
+					Expect(pod.Resources.Limits.Cpu().String()).To(Equal("2"))
+
+					kb := rtest.GetResource(createResources, render.KibanaName, render.KibanaNamespace, "kibana.k8s.elastic.co", "v1", "Kibana").(*kbv1.Kibana)
+					kbRes := kb.Spec.PodTemplate.Spec.Containers[0].Resources
+					Expect(kbRes.Requests.Memory().String()).To(Equal("4Gi"))
+					Expect(kbRes.Requests.Cpu().String()).To(Equal("250m"))
+					Expect(kbRes.Limits.Memory().String()).To(Equal("4Gi"))
+				})
+
+				It("overrides the replicas, resources and scheduling of Kibana with the Kibana spec of the LogStorage", func() {
+					cfg.Installation.ControlPlaneReplicas = ptr.Int32ToPtr(1)
+					cfg.Installation.ControlPlaneNodeSelector = map[string]string{"control-plane": "true"}
+					kibanaRes := corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							"memory": resource.MustParse("2Gi"),
+						},
+					}
+					cfg.LogStorage.Spec.ComponentResources = []operatorv1.LogStorageComponentResource{
+						{ComponentName: operatorv1.ComponentNameKibana, ResourceRequirements: &corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{"memory": resource.MustParse("4Gi")},
+						}},
+					}
+					cfg.LogStorage.Spec.Kibana = &operatorv1.KibanaSpec{
+						Replicas:     ptr.Int32ToPtr(2),
+						Resources:    &kibanaRes,
+						NodeSelector: map[string]string{"kibana": "true"},
+						Tolerations:  []corev1.Toleration{{Key: "kibana", Operator: corev1.TolerationOpExists}},
+					}
+
+					component := render.LogStorage(cfg)
+
+					createResources, _ := component.Objects()
+					kb := rtest.GetResource(createResources, render.KibanaName, render.KibanaNamespace, "kibana.k8s.elastic.co", "v1", "Kibana").(*kbv1.Kibana)
+					Expect(kb.Spec.Count).To(Equal(int32(2)))
+					Expect(kb.Spec.PodTemplate.Spec.Affinity).NotTo(BeNil())
+					Expect(kb.Spec.PodTemplate.Spec.NodeSelector).To(Equal(map[string]string{"kibana": "true"}))
+					Expect(kb.Spec.PodTemplate.Spec.Tolerations).To(Equal([]corev1.Toleration{{Key: "kibana", Operator: corev1.TolerationOpExists}}))
+					kbRes := kb.Spec.PodTemplate.Spec.Containers[0].Resources
+					Expect(kbRes.Requests.Memory().String()).To(Equal("2Gi"))
+					Expect(kbRes.Requests.Cpu().String()).To(Equal("250m"))
+				})
+			})
+			When("extra Kibana configuration is set", func() {
+				It("merges it into the Kibana configuration without overriding the managed settings", func() {
+					cfg.LogStorage.Spec.Kibana = &operatorv1.KibanaSpec{
+						ExtraConfig: map[string]string{
+							"telemetry.enabled":               "false",
+							"xpack.security.session.lifespan": "8h",
+							"server.port":                     "8080",
+							"tigera.enabled":                  "false",
+							"elasticsearch":                   "{}",
+						},
+					}
+
+					component := render.LogStorage(cfg)
+
+					createResources, _ := component.Objects()
+					kb := rtest.GetResource(createResources, render.KibanaName, render.KibanaNamespace, "kibana.k8s.elastic.co", "v1", "Kibana").(*kbv1.Kibana)
+					config := kb.Spec.Config.Data
+					Expect(config).To(HaveKeyWithValue("telemetry.enabled", "false"))
+					Expect(config).To(HaveKeyWithValue("xpack.security.session.lifespan", "8h"))
+					Expect(config).NotTo(HaveKey("server.port"))
+					Expect(config).NotTo(HaveKey("tigera.enabled"))
+					Expect(config).NotTo(HaveKey("elasticsearch"))
+				})
+			})
+			When("the Elasticsearch hosts of Kibana are overridden", func() {
+				It("connects Kibana to the hosts with the user that the operator manages", func() {
+					cfg.LogStorage.Spec.Kibana = &operatorv1.KibanaSpec{
+						ElasticsearchHosts: []string{"https://tigera-secure-es-gateway-http.tigera-elasticsearch.svc:9200"},
+					}
+					cfg.KibanaElasticsearchUserSecret = &corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{Name: render.KibanaElasticsearchUserSecret, Namespace: common.OperatorNamespace()},
+						Data: map[string][]byte{
+							"username": []byte(render.KibanaElasticsearchUserName),
+							"password": []byte("password"),
+						},
+					}
+
+					component := render.LogStorage(cfg)
+
+					createResources, _ := component.Objects()
+					kb := rtest.GetResource(createResources, render.KibanaName, render.KibanaNamespace, "kibana.k8s.elastic.co", "v1", "Kibana").(*kbv1.Kibana)
+					Expect(kb.Spec.ElasticsearchRef.Name).To(BeEmpty())
+					config := kb.Spec.Config.Data
+					Expect(config).To(HaveKeyWithValue("elasticsearch.hosts", []string{"https://tigera-secure-es-gateway-http.tigera-elasticsearch.svc:9200"}))
+					Expect(config).To(HaveKeyWithValue("elasticsearch.username", render.KibanaElasticsearchUserName))
+					Expect(config).To(HaveKeyWithValue("elasticsearch.password", "${ELASTICSEARCH_PASSWORD}"))
+					Expect(config).To(HaveKeyWithValue("elasticsearch.ssl.certificateAuthorities", []string{cfg.TrustedBundle.MountPath()}))
+
+					podSpec := kb.Spec.PodTemplate.Spec
+					Expect(podSpec.Containers[0].Env).To(ContainElement(corev1.EnvVar{
+						Name: "ELASTICSEARCH_PASSWORD",
+						ValueFrom: &corev1.EnvVarSource{
+							SecretKeyRef: &corev1.SecretKeySelector{
+								LocalObjectReference: corev1.LocalObjectReference{Name: render.KibanaElasticsearchUserSecret},
+								Key:                  "password",
+							},
+						},
+					}))
+					Expect(podSpec.Containers[0].VolumeMounts).To(ContainElement(cfg.TrustedBundle.VolumeMount(rmeta.OSTypeLinux)))
+					Expect(podSpec.Volumes).To(ContainElement(cfg.TrustedBundle.Volume()))
+
+					Expect(rtest.GetResource(createResources, render.KibanaElasticsearchUserSecret, render.KibanaNamespace, "", "v1", "Secret")).NotTo(BeNil())
+				})
+
+				It("deletes the copy of the credentials of the user when the hosts are no longer overridden", func() {
+					component := render.LogStorage(cfg)
+
+					createResources, deleteResources := component.Objects()
+					Expect(rtest.GetResource(createResources, render.KibanaElasticsearchUserSecret, render.KibanaNamespace, "", "v1", "Secret")).To(BeNil())
+					Expect(deleteResources).To(ContainElement(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: render.KibanaElasticsearchUserSecret, Namespace: render.KibanaNamespace}}))
+				})
+			})
+			When("pod metadata is set", func() {
+				It("adds it to the Elasticsearch and Kibana pods without overriding the labels of the operator", func() {
+					cfg.LogStorage.Spec.Nodes = &operatorv1.Nodes{
+						Count:    2,
+						NodeSets: []operatorv1.NodeSet{{}, {}},
+						PodMetadata: &operatorv1.Metadata{
+							Annotations: map[string]string{"backup.velero.io/backup-volumes": "elasticsearch-data"},
+						},
+					}
+					cfg.LogStorage.Spec.Kibana = &operatorv1.KibanaSpec{
+						PodMetadata: &operatorv1.Metadata{
+							Labels:      map[string]string{"k8s-app": "kibana", "team": "observability"},
+							Annotations: map[string]string{"sidecar.istio.io/inject": "false"},
+						},
+					}
+
+					component := render.LogStorage(cfg)
+
+					createResources, _ := component.Objects()
+					for _, nodeSet := range getElasticsearch(createResources).Spec.NodeSets {
+						Expect(nodeSet.PodTemplate.Annotations).To(HaveKeyWithValue("backup.velero.io/backup-volumes", "elasticsearch-data"))
+					}
+
+					kb := rtest.GetResource(createResources, render.KibanaName, render.KibanaNamespace, "kibana.k8s.elastic.co", "v1", "Kibana").(*kbv1.Kibana)
+					Expect(kb.Spec.PodTemplate.Labels).To(HaveKeyWithValue("k8s-app", render.KibanaName))
+					Expect(kb.Spec.PodTemplate.Labels).To(HaveKeyWithValue("team", "observability"))
+					Expect(kb.Spec.PodTemplate.Annotations).To(HaveKeyWithValue("sidecar.istio.io/inject", "false"))
+					Expect(kb.Spec.PodTemplate.Annotations).To(HaveKey(render.KibanaTLSAnnotationHash))
+				})
+			})
+			When("the JVM heap is container aware", func() {
+				It("lets the JVM size the heap from the memory limit", func() {
+					cfg.LogStorage.Spec.Nodes = &operatorv1.Nodes{
+						Count: 1,
+						JVMHeap: &operatorv1.JVMHeap{
+							Sizing:           operatorv1.JVMHeapSizingContainerAware,
+							MaxRAMPercentage: ptr.Int32ToPtr(60),
+						},
+					}
+
+					component := render.LogStorage(cfg)
+
+					createResources, _ := component.Objects()
+					pod := getElasticsearch(createResources).Spec.NodeSets[0].PodTemplate.Spec.Containers[0]
+					Expect(pod.Env[0].Value).To(Equal("-XX:+UseContainerSupport -XX:InitialRAMPercentage=60 -XX:MaxRAMPercentage=60"))
+				})
+
+				It("uses half of the memory limit by default", func() {
+					cfg.LogStorage.Spec.Nodes = &operatorv1.Nodes{
+						Count:   1,
+						JVMHeap: &operatorv1.JVMHeap{Sizing: operatorv1.JVMHeapSizingContainerAware},
+					}
+
+					component := render.LogStorage(cfg)
+
+					createResources, _ := component.Objects()
+					pod := getElasticsearch(createResources).Spec.NodeSets[0].PodTemplate.Spec.Containers[0]
+					Expect(pod.Env[0].Value).To(Equal("-XX:+UseContainerSupport -XX:InitialRAMPercentage=50 -XX:MaxRAMPercentage=50"))
+				})
+			})
 		})
 		Context("Node selection", func() {
 			When("NodeSets is set but empty", func() {
@@ -1328,6 +2214,396 @@ var _ = Describe("Elasticsearch rendering tests", func() {
 					}))
 				})
 			})
+			When("zone awareness is enabled", func() {
+				It("splits the NodeSets without selection attributes per zone of the K8s nodes", func() {
+					cfg.Zones = []string{"us-west-2a", "us-west-2b"}
+					cfg.LogStorage.Spec.Nodes = &operatorv1.Nodes{
+						Count:         4,
+						ZoneAwareness: operatorv1.ZoneAwarenessEnabled,
+						NodeSets: []operatorv1.NodeSet{
+							{},
+							{
+								SelectionAttributes: []operatorv1.NodeSetSelectionAttribute{{
+									Name:      "zone",
+									NodeLabel: "topology.kubernetes.io/zone",
+									Value:     "us-west-2b",
+								}},
+							},
+						},
+					}
+
+					component := render.LogStorage(cfg)
+
+					createResources, _ := component.Objects()
+					nodeSets := getElasticsearch(createResources).Spec.NodeSets
+
+					Expect(nodeSets).To(HaveLen(3))
+					for i, zone := range []string{"us-west-2a", "us-west-2b"} {
+						Expect(nodeSets[i].Name).To(HaveSuffix("-0-" + zone))
+						Expect(nodeSets[i].Count).To(Equal(int32(1)))
+						Expect(nodeSets[i].Config.Data).To(HaveKeyWithValue("node.attr.zone", zone))
+						Expect(nodeSets[i].Config.Data).To(HaveKeyWithValue("cluster.routing.allocation.awareness.attributes", "zone"))
+						Expect(nodeSets[i].PodTemplate.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms).To(Equal([]corev1.NodeSelectorTerm{{
+							MatchExpressions: []corev1.NodeSelectorRequirement{{
+								Key:      "topology.kubernetes.io/zone",
+								Operator: corev1.NodeSelectorOpIn,
+								Values:   []string{zone},
+							}},
+						}}))
+						Expect(nodeSets[i].PodTemplate.Spec.AutomountServiceAccountToken).NotTo(Equal(ptr.BoolToPtr(true)))
+						for _, c := range nodeSets[i].PodTemplate.Spec.InitContainers {
+							Expect(c.Name).NotTo(Equal("elastic-topology-zone"))
+						}
+					}
+
+					Expect(nodeSets[2].Name).To(HaveSuffix("-1"))
+					Expect(nodeSets[2].Count).To(Equal(int32(2)))
+					Expect(nodeSets[2].Config.Data).Should(HaveKeyWithValue("node.attr.zone", "us-west-2b"))
+
+					clusterRole := rtest.GetResource(createResources, "tigera-elasticsearch", "", "rbac.authorization.k8s.io", "v1", "ClusterRole").(*rbacv1.ClusterRole)
+					for _, rule := range clusterRole.Rules {
+						Expect(rule.Resources).NotTo(ContainElement("nodes"))
+					}
+				})
+
+				It("spreads the Elasticsearch nodes over the zones even if there is only one", func() {
+					cfg.Zones = []string{"us-west-2a"}
+					cfg.LogStorage.Spec.Nodes = &operatorv1.Nodes{Count: 1, ZoneAwareness: operatorv1.ZoneAwarenessEnabled}
+
+					component := render.LogStorage(cfg)
+
+					createResources, _ := component.Objects()
+					nodeSets := getElasticsearch(createResources).Spec.NodeSets
+					Expect(nodeSets).To(HaveLen(1))
+					Expect(nodeSets[0].Name).To(HaveSuffix("-us-west-2a"))
+					Expect(nodeSets[0].Config.Data).To(HaveKeyWithValue("node.attr.zone", "us-west-2a"))
+				})
+			})
+			When("the nodes are in several zones", func() {
+				BeforeEach(func() {
+					cfg.LogStorage.Spec.Nodes = &operatorv1.Nodes{Count: 3}
+					cfg.Zones = []string{"us-west-2a", "us-west-2b"}
+				})
+
+				It("spreads the Elasticsearch nodes over one NodeSet per zone", func() {
+					component := render.LogStorage(cfg)
+
+					createResources, _ := component.Objects()
+					nodeSets := getElasticsearch(createResources).Spec.NodeSets
+
+					Expect(nodeSets).To(HaveLen(2))
+					for i, zone := range []string{"us-west-2a", "us-west-2b"} {
+						Expect(nodeSets[i].Name).To(HaveSuffix("-" + zone))
+						Expect(nodeSets[i].Config.Data).To(HaveKeyWithValue("node.attr.zone", zone))
+						Expect(nodeSets[i].Config.Data).To(HaveKeyWithValue("cluster.routing.allocation.awareness.attributes", "zone"))
+						Expect(nodeSets[i].PodTemplate.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms).To(Equal([]corev1.NodeSelectorTerm{{
+							MatchExpressions: []corev1.NodeSelectorRequirement{{
+								Key:      "topology.kubernetes.io/zone",
+								Operator: corev1.NodeSelectorOpIn,
+								Values:   []string{zone},
+							}},
+						}}))
+					}
+					Expect(nodeSets[0].Count).To(Equal(int32(2)))
+					Expect(nodeSets[1].Count).To(Equal(int32(1)))
+				})
+
+				It("keeps a single NodeSet when zone awareness is disabled", func() {
+					cfg.LogStorage.Spec.Nodes.ZoneAwareness = operatorv1.ZoneAwarenessDisabled
+					component := render.LogStorage(cfg)
+
+					createResources, _ := component.Objects()
+					Expect(getElasticsearch(createResources).Spec.NodeSets).To(HaveLen(1))
+				})
+
+				It("keeps the NodeSet of an existing cluster without zone NodeSets", func() {
+					cfg.Elasticsearch = &esv1.Elasticsearch{Spec: esv1.ElasticsearchSpec{NodeSets: []esv1.NodeSet{{
+						Name:   "es",
+						Config: &cmnv1.Config{Data: map[string]interface{}{"node.master": "true"}},
+					}}}}
+					component := render.LogStorage(cfg)
+
+					createResources, _ := component.Objects()
+					Expect(getElasticsearch(createResources).Spec.NodeSets).To(HaveLen(1))
+				})
+			})
+			When("tolerations and affinity are set for a NodeSet", func() {
+				It("overrides the tolerations and affinity of the pods of the NodeSet", func() {
+					tolerations := []corev1.Toleration{{
+						Key:      "storage-optimized",
+						Operator: corev1.TolerationOpExists,
+						Effect:   corev1.TaintEffectNoSchedule,
+					}}
+					affinity := &corev1.Affinity{
+						NodeAffinity: &corev1.NodeAffinity{
+							RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+								NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+									MatchExpressions: []corev1.NodeSelectorRequirement{{
+										Key:      "node-pool",
+										Operator: corev1.NodeSelectorOpIn,
+										Values:   []string{"storage-optimized"},
+									}},
+								}},
+							},
+						},
+					}
+					cfg.Installation.ControlPlaneTolerations = []corev1.Toleration{{Key: "control-plane", Operator: corev1.TolerationOpExists}}
+					cfg.LogStorage.Spec.Nodes = &operatorv1.Nodes{
+						Count: 2,
+						NodeSets: []operatorv1.NodeSet{
+							{},
+							{
+								SelectionAttributes: []operatorv1.NodeSetSelectionAttribute{{
+									Name:      "zone",
+									NodeLabel: "topology.kubernetes.io/zone",
+									Value:     "us-west-2b",
+								}},
+								Tolerations: tolerations,
+								Affinity:    affinity,
+							},
+						},
+					}
+
+					component := render.LogStorage(cfg)
+
+					createResources, _ := component.Objects()
+					nodeSets := getElasticsearch(createResources).Spec.NodeSets
+
+					Expect(len(nodeSets)).Should(Equal(2))
+					Expect(nodeSets[0].PodTemplate.Spec.Tolerations).To(Equal(cfg.Installation.ControlPlaneTolerations))
+					Expect(nodeSets[1].PodTemplate.Spec.Tolerations).To(Equal(tolerations))
+					Expect(nodeSets[1].PodTemplate.Spec.Affinity).To(Equal(affinity))
+					Expect(nodeSets[1].Config.Data).Should(HaveKeyWithValue("node.attr.zone", "us-west-2b"))
+				})
+			})
+			When("a pod template patch is set for a NodeSet", func() {
+				It("patches the pod template of the NodeSet except for the protected fields", func() {
+					patch := `{
+						"metadata": {"annotations": {"apm": "enabled"}},
+						"spec": {
+							"serviceAccountName": "other",
+							"initContainers": [
+								{"name": "elastic-internal-init-log-selinux-context", "$patch": "delete"}
+							],
+							"containers": [
+								{"name": "elasticsearch", "image": "other-image", "env": [{"name": "APM_ENABLED", "value": "true"}]},
+								{"name": "apm-agent", "image": "apm-agent:1.0"}
+							],
+							"volumes": [
+								{"name": "apm-config", "emptyDir": {}}
+							]
+						}
+					}`
+					cfg.LogStorage.Spec.Nodes = &operatorv1.Nodes{
+						Count: 2,
+						NodeSets: []operatorv1.NodeSet{
+							{},
+							{PodTemplatePatch: &runtime.RawExtension{Raw: []byte(patch)}},
+						},
+					}
+
+					component := render.LogStorage(cfg)
+					createResources, _ := component.Objects()
+					nodeSets := getElasticsearch(createResources).Spec.NodeSets
+
+					Expect(len(nodeSets)).Should(Equal(2))
+					generated, patched := nodeSets[0].PodTemplate, nodeSets[1].PodTemplate
+					Expect(patched.Annotations).To(HaveKeyWithValue("apm", "enabled"))
+					Expect(patched.Spec.ServiceAccountName).To(Equal(generated.Spec.ServiceAccountName))
+					Expect(patched.Spec.InitContainers).To(Equal(generated.Spec.InitContainers))
+
+					Expect(patched.Spec.Containers).To(HaveLen(2))
+					Expect(patched.Spec.Containers[0].Name).To(Equal("elasticsearch"))
+					Expect(patched.Spec.Containers[0].Image).To(Equal(generated.Spec.Containers[0].Image))
+					Expect(patched.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "APM_ENABLED", Value: "true"}))
+					Expect(patched.Spec.Containers[1].Name).To(Equal("apm-agent"))
+					Expect(patched.Spec.Containers[1].Image).To(Equal("apm-agent:1.0"))
+
+					Expect(patched.Spec.Volumes).To(Equal(append(generated.Spec.Volumes, corev1.Volume{
+						Name:         "apm-config",
+						VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+					})))
+				})
+			})
+			When("no PodDisruptionBudget is configured for the Elasticsearch nodes", func() {
+				It("leaves the default PodDisruptionBudget of ECK enabled", func() {
+					component := render.LogStorage(cfg)
+					createResources, deleteResources := component.Objects()
+
+					Expect(getElasticsearch(createResources).Spec.PodDisruptionBudget).To(BeNil())
+					Expect(rtest.GetResource(createResources, render.ElasticsearchName, render.ElasticsearchNamespace, "policy", "v1", "PodDisruptionBudget")).To(BeNil())
+					Expect(rtest.GetResource(deleteResources, render.ElasticsearchName, render.ElasticsearchNamespace, "policy", "v1", "PodDisruptionBudget")).To(BeNil())
+				})
+
+				It("deletes the PodDisruptionBudget of the operator when it exists", func() {
+					cfg.RemoveElasticsearchPodDisruptionBudget = true
+					component := render.LogStorage(cfg)
+					createResources, deleteResources := component.Objects()
+
+					Expect(rtest.GetResource(createResources, render.ElasticsearchName, render.ElasticsearchNamespace, "policy", "v1", "PodDisruptionBudget")).To(BeNil())
+					Expect(rtest.GetResource(deleteResources, render.ElasticsearchName, render.ElasticsearchNamespace, "policy", "v1", "PodDisruptionBudget")).NotTo(BeNil())
+				})
+			})
+			When("the PodDisruptionBudgets are configured", func() {
+				It("keeps the minimum of the Elasticsearch and Kibana pods available", func() {
+					esMinAvailable, kbMinAvailable := intstr.FromInt(2), intstr.FromString("50%")
+					cfg.LogStorage.Spec.Nodes = &operatorv1.Nodes{
+						Count:               3,
+						PodDisruptionBudget: &operatorv1.PodDisruptionBudget{MinAvailable: &esMinAvailable},
+					}
+					cfg.LogStorage.Spec.Kibana = &operatorv1.KibanaSpec{
+						PodDisruptionBudget: &operatorv1.PodDisruptionBudget{MinAvailable: &kbMinAvailable},
+					}
+
+					component := render.LogStorage(cfg)
+					createResources, _ := component.Objects()
+
+					Expect(getElasticsearch(createResources).Spec.PodDisruptionBudget).To(Equal(&cmnv1.PodDisruptionBudgetTemplate{}))
+					esPDB := rtest.GetResource(createResources, render.ElasticsearchName, render.ElasticsearchNamespace, "policy", "v1", "PodDisruptionBudget").(*policyv1.PodDisruptionBudget)
+					Expect(esPDB.Spec.MinAvailable).To(Equal(&esMinAvailable))
+					Expect(esPDB.Spec.MaxUnavailable).To(BeNil())
+					Expect(esPDB.Spec.Selector.MatchLabels).To(Equal(map[string]string{"elasticsearch.k8s.elastic.co/cluster-name": render.ElasticsearchName}))
+
+					kbPDB := rtest.GetResource(createResources, render.KibanaName, render.KibanaNamespace, "policy", "v1", "PodDisruptionBudget").(*policyv1.PodDisruptionBudget)
+					Expect(kbPDB.Spec.MinAvailable).To(Equal(&kbMinAvailable))
+					Expect(kbPDB.Spec.Selector.MatchLabels).To(Equal(map[string]string{"k8s-app": render.KibanaName}))
+				})
+			})
+			When("the disruption policies are configured", func() {
+				It("renders the termination grace periods, preStop delays and PodDisruptionBudgets of the components", func() {
+					eckGracePeriod, esGracePeriod, kbGracePeriod := int64(20), int64(300), int64(60)
+					esPreStopDelay, kbPreStopDelay := int64(30), int64(5)
+					esMinAvailable, kbMinAvailable := intstr.FromInt(2), intstr.FromInt(1)
+					cfg.LogStorage.Spec.ComponentDisruptionPolicies = []operatorv1.LogStorageComponentDisruptionPolicy{
+						{
+							ComponentName:    operatorv1.ComponentNameECKOperator,
+							DisruptionPolicy: operatorv1.DisruptionPolicy{TerminationGracePeriodSeconds: &eckGracePeriod},
+						},
+						{
+							ComponentName: operatorv1.ComponentNameElasticsearch,
+							DisruptionPolicy: operatorv1.DisruptionPolicy{
+								TerminationGracePeriodSeconds: &esGracePeriod,
+								PreStopDelaySeconds:           &esPreStopDelay,
+								PodDisruptionBudget:           &operatorv1.PodDisruptionBudget{MinAvailable: &esMinAvailable},
+							},
+						},
+						{
+							ComponentName: operatorv1.ComponentNameKibana,
+							DisruptionPolicy: operatorv1.DisruptionPolicy{
+								TerminationGracePeriodSeconds: &kbGracePeriod,
+								PreStopDelaySeconds:           &kbPreStopDelay,
+							},
+						},
+					}
+					cfg.LogStorage.Spec.Kibana = &operatorv1.KibanaSpec{
+						PodDisruptionBudget: &operatorv1.PodDisruptionBudget{MinAvailable: &kbMinAvailable},
+					}
+
+					component := render.LogStorage(cfg)
+					createResources, _ := component.Objects()
+
+					eck := rtest.GetResource(createResources, render.ECKOperatorName, render.ECKOperatorNamespace, "apps", "v1", "StatefulSet").(*appsv1.StatefulSet)
+					Expect(*eck.Spec.Template.Spec.TerminationGracePeriodSeconds).To(Equal(int64(20)))
+
+					podSpec := getElasticsearch(createResources).Spec.NodeSets[0].PodTemplate.Spec
+					Expect(*podSpec.TerminationGracePeriodSeconds).To(Equal(int64(300)))
+					Expect(podSpec.Containers[0].Lifecycle).To(BeNil())
+					Expect(podSpec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "PRE_STOP_ADDITIONAL_WAIT_SECONDS", Value: "30"}))
+					esPDB := rtest.GetResource(createResources, render.ElasticsearchName, render.ElasticsearchNamespace, "policy", "v1", "PodDisruptionBudget").(*policyv1.PodDisruptionBudget)
+					Expect(esPDB.Spec.MinAvailable).To(Equal(&esMinAvailable))
+
+					kb := rtest.GetResource(createResources, render.KibanaName, render.KibanaNamespace, "kibana.k8s.elastic.co", "v1", "Kibana").(*kbv1.Kibana)
+					Expect(*kb.Spec.PodTemplate.Spec.TerminationGracePeriodSeconds).To(Equal(int64(60)))
+					Expect(kb.Spec.PodTemplate.Spec.Containers[0].Lifecycle.PreStop.Exec.Command).To(Equal([]string{"/bin/sh", "-c", "sleep 5"}))
+					// The PodDisruptionBudget of the Kibana spec takes precedence over that of the policy.
+					kbPDB := rtest.GetResource(createResources, render.KibanaName, render.KibanaNamespace, "policy", "v1", "PodDisruptionBudget").(*policyv1.PodDisruptionBudget)
+					Expect(kbPDB.Spec.MinAvailable).To(Equal(&kbMinAvailable))
+				})
+			})
+			When("disk watermarks are set", func() {
+				It("doesn't render the watermarks into the configuration of the NodeSets", func() {
+					low, floodStage := int32(95), int32(99)
+					cfg.LogStorage.Spec.Nodes = &operatorv1.Nodes{
+						Count:          2,
+						NodeSets:       []operatorv1.NodeSet{{}, {}},
+						DiskWatermarks: &operatorv1.DiskWatermarks{Low: &low, FloodStage: &floodStage},
+					}
+
+					component := render.LogStorage(cfg)
+
+					createResources, _ := component.Objects()
+					nodeSets := getElasticsearch(createResources).Spec.NodeSets
+
+					Expect(len(nodeSets)).Should(Equal(2))
+					for _, nodeSet := range nodeSets {
+						// The watermarks are applied through the cluster settings API, so changing them doesn't restart the nodes.
+						Expect(nodeSet.Config.Data).ShouldNot(HaveKey("cluster.routing.allocation.disk.watermark.low"))
+						Expect(nodeSet.Config.Data).ShouldNot(HaveKey("cluster.routing.allocation.disk.watermark.flood_stage"))
+					}
+				})
+			})
+			When("extra Elasticsearch configuration is set", func() {
+				It("merges it into the configuration of each NodeSet without overriding the managed settings", func() {
+					cfg.LogStorage.Spec.Nodes = &operatorv1.Nodes{
+						Count:    2,
+						NodeSets: []operatorv1.NodeSet{{}, {}},
+						ExtraConfig: map[string]string{
+							"thread_pool.search.queue_size":      "2000",
+							"indices.breaker.total.limit":        "80%",
+							"cluster.max_shards_per_node":        "50000",
+							"cluster.max_shards_per_node.frozen": "5000",
+							"node":                               "{}",
+						},
+					}
+
+					component := render.LogStorage(cfg)
+
+					createResources, _ := component.Objects()
+					nodeSets := getElasticsearch(createResources).Spec.NodeSets
+
+					Expect(len(nodeSets)).Should(Equal(2))
+					for _, nodeSet := range nodeSets {
+						Expect(nodeSet.Config.Data).Should(HaveKeyWithValue("thread_pool.search.queue_size", "2000"))
+						Expect(nodeSet.Config.Data).Should(HaveKeyWithValue("indices.breaker.total.limit", "80%"))
+						Expect(nodeSet.Config.Data).Should(HaveKeyWithValue("cluster.max_shards_per_node", 10000))
+						Expect(nodeSet.Config.Data).ShouldNot(HaveKey("cluster.max_shards_per_node.frozen"))
+						Expect(nodeSet.Config.Data).ShouldNot(HaveKey("node"))
+					}
+				})
+			})
+			When("the ports of Elasticsearch and Kibana are overridden", func() {
+				It("listens on the ports and keeps the ports of the services", func() {
+					esPort, kbPort := int32(19200), int32(15601)
+					cfg.LogStorage.Spec.Ports = &operatorv1.LogStoragePorts{ElasticsearchHTTP: &esPort, Kibana: &kbPort}
+
+					component := render.LogStorage(cfg)
+					createResources, _ := component.Objects()
+
+					es := getElasticsearch(createResources)
+					Expect(es.Spec.HTTP.Service.Spec.Ports).To(Equal([]corev1.ServicePort{
+						{Name: "https", Port: 9200, TargetPort: intstr.FromInt(19200), Protocol: corev1.ProtocolTCP},
+					}))
+					Expect(es.Spec.NodeSets[0].Config.Data).Should(HaveKeyWithValue("http.port", esPort))
+					esContainer := es.Spec.NodeSets[0].PodTemplate.Spec.Containers[0]
+					Expect(esContainer.Ports).To(ConsistOf(corev1.ContainerPort{Name: "https", ContainerPort: esPort, Protocol: corev1.ProtocolTCP}))
+					Expect(esContainer.ReadinessProbe.TCPSocket.Port).To(Equal(intstr.FromInt(19200)))
+
+					kb := rtest.GetResource(createResources, render.KibanaName, render.KibanaNamespace, "kibana.k8s.elastic.co", "v1", "Kibana").(*kbv1.Kibana)
+					Expect(kb.Spec.HTTP.Service.Spec.Ports).To(Equal([]corev1.ServicePort{
+						{Name: "https", Port: 5601, TargetPort: intstr.FromInt(15601), Protocol: corev1.ProtocolTCP},
+					}))
+					Expect(kb.Spec.Config.Data["server"]).Should(HaveKeyWithValue("port", kbPort))
+					Expect(kb.Spec.PodTemplate.Spec.Containers[0].ReadinessProbe.HTTPGet.Port.IntVal).To(Equal(kbPort))
+
+					esPolicy := rtest.GetResource(createResources, render.ElasticsearchPolicyName, render.ElasticsearchNamespace, "projectcalico.org", "v3", "NetworkPolicy").(*v3.NetworkPolicy)
+					Expect(esPolicy.Spec.Ingress[0].Destination.Ports).To(Equal(networkpolicy.Ports(19200)))
+					kbPolicy := rtest.GetResource(createResources, render.KibanaPolicyName, render.KibanaNamespace, "projectcalico.org", "v3", "NetworkPolicy").(*v3.NetworkPolicy)
+					Expect(kbPolicy.Spec.Egress[0].Destination).To(Equal(render.ElasticsearchPortEntityRule(esPort)))
+					for _, rule := range kbPolicy.Spec.Ingress {
+						Expect(rule.Destination.Ports).To(Equal(networkpolicy.Ports(15601)))
+					}
+				})
+			})
 		})
 	})
 
@@ -1514,6 +2790,7 @@ var deleteLogStorageTests = func(managementCluster *operatorv1.ManagementCluster
 			expectedCreateResources := []resourceTestObj{}
 
 			expectedDeleteResources := []resourceTestObj{
+				{render.LogStoragePriorityClassName, "", &schedulingv1.PriorityClass{}, nil},
 				{render.ElasticsearchName, render.ElasticsearchNamespace, &esv1.Elasticsearch{}, nil},
 				{render.KibanaName, render.KibanaNamespace, &kbv1.Kibana{}, nil},
 			}
@@ -1525,7 +2802,7 @@ var deleteLogStorageTests = func(managementCluster *operatorv1.ManagementCluster
 			compareResources(createResources, expectedCreateResources)
 			compareResources(deleteResources, expectedDeleteResources)
 		})
-		It("doesn't return anything to delete when Elasticsearch and Kibana have their deletion times stamps set and the LogStorage finalizers are still set", func() {
+		It("only returns the PriorityClass to delete when Elasticsearch and Kibana have their deletion times stamps set and the LogStorage finalizers are still set", func() {
 			expectedCreateResources := []resourceTestObj{}
 
 			t := metav1.Now()
@@ -1536,7 +2813,9 @@ var deleteLogStorageTests = func(managementCluster *operatorv1.ManagementCluster
 			createResources, deleteResources := component.Objects()
 
 			compareResources(createResources, expectedCreateResources)
-			compareResources(deleteResources, []resourceTestObj{})
+			compareResources(deleteResources, []resourceTestObj{
+				{render.LogStoragePriorityClassName, "", &schedulingv1.PriorityClass{}, nil},
+			})
 		})
 	}
 }
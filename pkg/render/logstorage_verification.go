@@ -0,0 +1,269 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"fmt"
+	"time"
+
+	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/components"
+	"github.com/tigera/operator/pkg/ptr"
+	"github.com/tigera/operator/pkg/render/common/disruptionpolicy"
+	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
+	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/networkpolicy"
+	"github.com/tigera/operator/pkg/render/common/secret"
+	"github.com/tigera/operator/pkg/tls/certificatemanagement"
+)
+
+const (
+	LogStorageVerificationName       = "tigera-log-storage-verification"
+	LogStorageVerificationPolicyName = networkpolicy.TigeraComponentPolicyPrefix + "log-storage-verification"
+
+	// LogStorageVerificationUserSecret holds the credentials of the Elasticsearch user of the job, which can only
+	// access the index of the test document.
+	LogStorageVerificationUserSecret = "tigera-log-storage-verification-user"
+	LogStorageVerificationUserName   = "tigera-log-storage-verification"
+	LogStorageVerificationRoleName   = "tigera_log_storage_verification"
+
+	// LogStorageVerificationHashAnnotation holds the hash of the Elasticsearch and Kibana clusters that the job verified,
+	// so that the job is recreated when the clusters are rolled out again.
+	LogStorageVerificationHashAnnotation = "hash.operator.tigera.io/log-storage-verification"
+
+	// LogStorageVerificationIndex is the index that the job writes its test document to.
+	LogStorageVerificationIndex = "tigera_log_storage_verification"
+
+	defaultLogStorageVerificationTimeout = 5 * time.Minute
+)
+
+var LogStorageVerificationSourceEntityRule = networkpolicy.CreateSourceEntityRule(ElasticsearchNamespace, LogStorageVerificationName)
+
+// logStorageVerificationScript writes a test document through the Elasticsearch gateway, searches for it and deletes
+// it again, then opens the login page of Kibana unless Kibana isn't deployed. A failure is reported through the
+// termination message of the container.
+const logStorageVerificationScript = `
+fail() { echo "$1" > /dev/termination-log; echo "$1"; exit 1; }
+es() { curl -sS --fail --cacert "$CA_CRT_PATH" -u "$ELASTIC_USERNAME:$ELASTIC_PASSWORD" -H 'Content-Type: application/json' "$ELASTICSEARCH_URL$@"; }
+
+doc="/` + LogStorageVerificationIndex + `/_doc/$HOSTNAME"
+es "$doc?refresh=true" -X PUT -d "{\"job\": \"$HOSTNAME\", \"time\": \"$(date -u +%Y-%m-%dT%H:%M:%SZ)\"}" -o /dev/null ||
+  fail "Failed to write the test document to Elasticsearch"
+es "/` + LogStorageVerificationIndex + `/_search?q=job:$HOSTNAME" -o /tmp/result.json ||
+  fail "Failed to search for the test document in Elasticsearch"
+grep -q "\"job\": *\"$HOSTNAME\"" /tmp/result.json || fail "The test document was not found in Elasticsearch"
+es "$doc" -X DELETE -o /dev/null || fail "Failed to delete the test document from Elasticsearch"
+
+if [ -n "$KIBANA_URL" ]; then
+  curl -sS --fail --cacert "$CA_CRT_PATH" -o /dev/null "$KIBANA_URL/login" || fail "Failed to open the login page of Kibana"
+fi
+`
+
+// LogStorageVerification renders the job that verifies that Elasticsearch and Kibana are functional.
+func LogStorageVerification(cfg *LogStorageVerificationConfiguration) Component {
+	return &logStorageVerificationComponent{cfg: cfg}
+}
+
+// LogStorageVerificationConfiguration contains all the config information needed to render the component.
+type LogStorageVerificationConfiguration struct {
+	LogStorage    *operatorv1.LogStorage
+	Installation  *operatorv1.InstallationSpec
+	PullSecrets   []*corev1.Secret
+	TrustedBundle certificatemanagement.TrustedBundle
+	Provider      operatorv1.Provider
+	ClusterDomain string
+
+	// UserSecret holds the credentials of the Elasticsearch user of the job, in the namespace of the operator.
+	UserSecret *corev1.Secret
+
+	// ElasticsearchUID, KibanaUID and their generations identify the rollout of the clusters that the job verifies.
+	// KibanaUID is empty when Kibana isn't deployed, in which case Kibana isn't verified.
+	ElasticsearchUID        types.UID
+	ElasticsearchGeneration int64
+	KibanaUID               types.UID
+	KibanaGeneration        int64
+
+	// Enabled is whether the verification is enabled in the LogStorage. If not, the objects of the job are deleted.
+	Enabled bool
+}
+
+// LogStorageVerificationHash returns the hash of the rollout of the clusters that the job verifies, which the job is
+// annotated with.
+func LogStorageVerificationHash(cfg *LogStorageVerificationConfiguration) string {
+	return rmeta.AnnotationHash([]interface{}{cfg.ElasticsearchUID, cfg.ElasticsearchGeneration, cfg.KibanaUID, cfg.KibanaGeneration})
+}
+
+type logStorageVerificationComponent struct {
+	cfg   *LogStorageVerificationConfiguration
+	image string
+}
+
+func (c *logStorageVerificationComponent) ResolveImages(is *operatorv1.ImageSet) error {
+	reg := c.cfg.Installation.Registry
+	path := c.cfg.Installation.ImagePath
+	prefix := c.cfg.Installation.ImagePrefix
+	var err error
+	// The job only needs the shell and curl of the Elasticsearch image.
+	if operatorv1.IsFIPSModeEnabled(c.cfg.Installation.FIPSMode) {
+		c.image, err = components.GetReference(components.ComponentElasticsearchFIPS, reg, path, prefix, is)
+	} else {
+		c.image, err = components.GetReference(components.ComponentElasticsearch, reg, path, prefix, is)
+	}
+	return err
+}
+
+func (c *logStorageVerificationComponent) Objects() ([]client.Object, []client.Object) {
+	objs := []client.Object{c.allowTigeraPolicy(), c.serviceAccount(), c.job()}
+	if !c.cfg.Enabled {
+		objs = append(objs,
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: LogStorageVerificationUserSecret, Namespace: common.OperatorNamespace()}},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: LogStorageVerificationUserSecret, Namespace: ElasticsearchNamespace}},
+		)
+		return nil, objs
+	}
+	objs = append(objs, c.cfg.UserSecret)
+	objs = append(objs, secret.ToRuntimeObjects(secret.CopyToNamespace(ElasticsearchNamespace, c.cfg.UserSecret)...)...)
+	return objs, nil
+}
+
+func (c *logStorageVerificationComponent) Ready() bool {
+	return true
+}
+
+func (c *logStorageVerificationComponent) SupportedOSType() rmeta.OSType {
+	return rmeta.OSTypeLinux
+}
+
+// SupportedArchitectures returns the architectures of the Elasticsearch image that the Job runs.
+func (c *logStorageVerificationComponent) SupportedArchitectures() []string {
+	return components.Architectures(components.ComponentElasticsearch)
+}
+
+func (c *logStorageVerificationComponent) serviceAccount() *corev1.ServiceAccount {
+	return &corev1.ServiceAccount{
+		TypeMeta:   metav1.TypeMeta{Kind: "ServiceAccount", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: LogStorageVerificationName, Namespace: ElasticsearchNamespace},
+	}
+}
+
+// timeout returns how long the job may run before it is failed.
+func (c *logStorageVerificationComponent) timeout() time.Duration {
+	if c.cfg.LogStorage != nil && c.cfg.LogStorage.Spec.Verification != nil &&
+		c.cfg.LogStorage.Spec.Verification.Timeout != nil && c.cfg.LogStorage.Spec.Verification.Timeout.Duration > 0 {
+		return c.cfg.LogStorage.Spec.Verification.Timeout.Duration
+	}
+	return defaultLogStorageVerificationTimeout
+}
+
+func (c *logStorageVerificationComponent) job() *batchv1.Job {
+	env := []corev1.EnvVar{
+		{Name: "ELASTIC_USERNAME", ValueFrom: secret.GetEnvVarSource(LogStorageVerificationUserSecret, "username", false)},
+		{Name: "ELASTIC_PASSWORD", ValueFrom: secret.GetEnvVarSource(LogStorageVerificationUserSecret, "password", false)},
+		{Name: "ELASTICSEARCH_URL", Value: relasticsearch.HTTPSEndpoint(c.SupportedOSType(), c.cfg.ClusterDomain)},
+	}
+	if c.cfg.KibanaUID != "" {
+		env = append(env, corev1.EnvVar{Name: "KIBANA_URL", Value: fmt.Sprintf("https://%s.%s.svc:%d/%s", KibanaServiceName, KibanaNamespace, KibanaPort, KibanaBasePath)})
+	}
+	var volumes []corev1.Volume
+	var volumeMounts []corev1.VolumeMount
+	if c.cfg.TrustedBundle != nil {
+		env = append(env, corev1.EnvVar{Name: "CA_CRT_PATH", Value: c.cfg.TrustedBundle.MountPath()})
+		volumes = append(volumes, c.cfg.TrustedBundle.Volume())
+		volumeMounts = append(volumeMounts, c.cfg.TrustedBundle.VolumeMount(c.SupportedOSType()))
+	}
+
+	job := &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{Kind: "Job", APIVersion: "batch/v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      LogStorageVerificationName,
+			Namespace: ElasticsearchNamespace,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:          ptr.Int32ToPtr(3),
+			ActiveDeadlineSeconds: ptr.Int64ToPtr(int64(c.timeout().Seconds())),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"k8s-app": LogStorageVerificationName,
+					},
+					Annotations: map[string]string{
+						LogStorageVerificationHashAnnotation: LogStorageVerificationHash(c.cfg),
+					},
+				},
+				Spec: corev1.PodSpec{
+					NodeSelector:       c.cfg.Installation.ControlPlaneNodeSelector,
+					Tolerations:        c.cfg.Installation.ControlPlaneTolerations,
+					ServiceAccountName: LogStorageVerificationName,
+					ImagePullSecrets:   secret.GetReferenceList(c.cfg.PullSecrets),
+					RestartPolicy:      corev1.RestartPolicyNever,
+					Containers: []corev1.Container{{
+						Name:                     LogStorageVerificationName,
+						Image:                    c.image,
+						Command:                  []string{"/bin/bash", "-c", logStorageVerificationScript},
+						Env:                      env,
+						VolumeMounts:             volumeMounts,
+						TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+						SecurityContext: &corev1.SecurityContext{
+							AllowPrivilegeEscalation: ptr.BoolToPtr(false),
+						},
+					}},
+					Volumes: volumes,
+				},
+			},
+		},
+	}
+	disruptionpolicy.Apply(&job.Spec.Template.Spec, logStorageJobsDisruptionPolicy(c.cfg.LogStorage), true)
+	return job
+}
+
+// allowTigeraPolicy allows the job to reach the Elasticsearch gateway and Kibana.
+func (c *logStorageVerificationComponent) allowTigeraPolicy() *v3.NetworkPolicy {
+	egressRules := networkpolicy.AppendDNSEgressRules([]v3.Rule{}, c.cfg.Provider == operatorv1.ProviderOpenShift)
+	egressRules = append(egressRules,
+		v3.Rule{
+			Action:      v3.Allow,
+			Protocol:    &networkpolicy.TCPProtocol,
+			Destination: networkpolicy.ESGatewayEntityRule,
+		},
+		v3.Rule{
+			Action:      v3.Allow,
+			Protocol:    &networkpolicy.TCPProtocol,
+			Destination: KibanaPortEntityRule(KibanaHTTPPort(logStoragePorts(c.cfg.LogStorage))),
+		},
+	)
+
+	return &v3.NetworkPolicy{
+		TypeMeta: metav1.TypeMeta{Kind: "NetworkPolicy", APIVersion: "projectcalico.org/v3"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      LogStorageVerificationPolicyName,
+			Namespace: ElasticsearchNamespace,
+		},
+		Spec: v3.NetworkPolicySpec{
+			Order:    &networkpolicy.HighPrecedenceOrder,
+			Tier:     networkpolicy.TigeraComponentTierName,
+			Selector: networkpolicy.KubernetesAppSelector(LogStorageVerificationName),
+			Types:    []v3.PolicyType{v3.PolicyTypeIngress, v3.PolicyTypeEgress},
+			Egress:   egressRules,
+		},
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/render"
+	rtest "github.com/tigera/operator/pkg/render/common/test"
+)
+
+var _ = Describe("LogStorage verification rendering tests", func() {
+	var cfg *render.LogStorageVerificationConfiguration
+
+	BeforeEach(func() {
+		cfg = &render.LogStorageVerificationConfiguration{
+			LogStorage: &operatorv1.LogStorage{
+				Spec: operatorv1.LogStorageSpec{
+					Verification: &operatorv1.LogStorageVerification{},
+				},
+			},
+			Installation:  &operatorv1.InstallationSpec{},
+			ClusterDomain: "cluster.local",
+			UserSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: render.LogStorageVerificationUserSecret, Namespace: common.OperatorNamespace()},
+			},
+			ElasticsearchUID:        "es-uid",
+			ElasticsearchGeneration: 1,
+			KibanaUID:               "kb-uid",
+			KibanaGeneration:        1,
+			Enabled:                 true,
+		}
+	})
+
+	It("should render the job that verifies Elasticsearch through the gateway and Kibana", func() {
+		component := render.LogStorageVerification(cfg)
+		Expect(component.ResolveImages(nil)).NotTo(HaveOccurred())
+
+		toCreate, toDelete := component.Objects()
+		Expect(toDelete).To(BeEmpty())
+		expectedResources := []struct {
+			name    string
+			ns      string
+			group   string
+			version string
+			kind    string
+		}{
+			{render.LogStorageVerificationPolicyName, render.ElasticsearchNamespace, "projectcalico.org", "v3", "NetworkPolicy"},
+			{render.LogStorageVerificationName, render.ElasticsearchNamespace, "", "v1", "ServiceAccount"},
+			{render.LogStorageVerificationName, render.ElasticsearchNamespace, "batch", "v1", "Job"},
+			{render.LogStorageVerificationUserSecret, common.OperatorNamespace(), "", "v1", "Secret"},
+			{render.LogStorageVerificationUserSecret, render.ElasticsearchNamespace, "", "v1", "Secret"},
+		}
+		Expect(toCreate).To(HaveLen(len(expectedResources)))
+		for i, expectedRes := range expectedResources {
+			rtest.ExpectResource(toCreate[i], expectedRes.name, expectedRes.ns, expectedRes.group, expectedRes.version, expectedRes.kind)
+		}
+
+		job := rtest.GetResource(toCreate, render.LogStorageVerificationName, render.ElasticsearchNamespace, "batch", "v1", "Job").(*batchv1.Job)
+		Expect(job.Spec.Template.Annotations).To(HaveKeyWithValue(render.LogStorageVerificationHashAnnotation, render.LogStorageVerificationHash(cfg)))
+		Expect(*job.Spec.ActiveDeadlineSeconds).To(Equal(int64(300)))
+		Expect(job.Spec.Template.Spec.RestartPolicy).To(Equal(corev1.RestartPolicyNever))
+		Expect(job.Spec.Template.Spec.Containers[0].Env).To(ContainElements(
+			corev1.EnvVar{Name: "ELASTICSEARCH_URL", Value: "https://tigera-secure-es-gateway-http.tigera-elasticsearch.svc:9200"},
+			corev1.EnvVar{Name: "KIBANA_URL", Value: "https://tigera-secure-kb-http.tigera-kibana.svc:5601/tigera-kibana"},
+			corev1.EnvVar{Name: "ELASTIC_USERNAME", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: render.LogStorageVerificationUserSecret},
+				Key:                  "username",
+			}}},
+		))
+	})
+
+	It("should only verify Elasticsearch when Kibana isn't deployed", func() {
+		cfg.KibanaUID = ""
+		cfg.LogStorage.Spec.Verification.Timeout = &metav1.Duration{Duration: time.Minute}
+		component := render.LogStorageVerification(cfg)
+		Expect(component.ResolveImages(nil)).NotTo(HaveOccurred())
+
+		toCreate, _ := component.Objects()
+		job := rtest.GetResource(toCreate, render.LogStorageVerificationName, render.ElasticsearchNamespace, "batch", "v1", "Job").(*batchv1.Job)
+		Expect(*job.Spec.ActiveDeadlineSeconds).To(Equal(int64(60)))
+		for _, env := range job.Spec.Template.Spec.Containers[0].Env {
+			Expect(env.Name).NotTo(Equal("KIBANA_URL"))
+		}
+	})
+
+	It("should render the disruption policy of the jobs", func() {
+		gracePeriod, preStopDelay := int64(20), int64(5)
+		cfg.LogStorage.Spec.ComponentDisruptionPolicies = []operatorv1.LogStorageComponentDisruptionPolicy{{
+			ComponentName: operatorv1.ComponentNameLogStorageJobs,
+			DisruptionPolicy: operatorv1.DisruptionPolicy{
+				TerminationGracePeriodSeconds: &gracePeriod,
+				PreStopDelaySeconds:           &preStopDelay,
+			},
+		}}
+		component := render.LogStorageVerification(cfg)
+		Expect(component.ResolveImages(nil)).NotTo(HaveOccurred())
+
+		toCreate, _ := component.Objects()
+		job := rtest.GetResource(toCreate, render.LogStorageVerificationName, render.ElasticsearchNamespace, "batch", "v1", "Job").(*batchv1.Job)
+		Expect(*job.Spec.Template.Spec.TerminationGracePeriodSeconds).To(Equal(int64(20)))
+		Expect(job.Spec.Template.Spec.Containers[0].Lifecycle.PreStop.Exec.Command).To(Equal([]string{"/bin/sh", "-c", "sleep 5"}))
+	})
+
+	It("should change the hash when Elasticsearch is rolled out again", func() {
+		hash := render.LogStorageVerificationHash(cfg)
+		cfg.ElasticsearchGeneration = 2
+		Expect(render.LogStorageVerificationHash(cfg)).NotTo(Equal(hash))
+	})
+
+	It("should delete the job when the verification is disabled", func() {
+		cfg.Enabled = false
+		component := render.LogStorageVerification(cfg)
+		Expect(component.ResolveImages(nil)).NotTo(HaveOccurred())
+
+		toCreate, toDelete := component.Objects()
+		Expect(toCreate).To(BeEmpty())
+		Expect(toDelete).To(HaveLen(5))
+		Expect(toDelete).To(ContainElements(
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: render.LogStorageVerificationUserSecret, Namespace: common.OperatorNamespace()}},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: render.LogStorageVerificationUserSecret, Namespace: render.ElasticsearchNamespace}},
+		))
+	})
+})
@@ -191,6 +191,7 @@ func (mc *monitorComponent) Objects() ([]client.Object, []client.Object) {
 		mc.serviceMonitorCalicoNode(),
 		mc.serviceMonitorElasticsearch(),
 		mc.serviceMonitorFluentd(),
+		mc.serviceMonitorFluentdWindows(),
 		mc.serviceMonitorQueryServer(),
 		mc.prometheusHTTPAPIService(),
 		mc.clusterRole(),
@@ -668,6 +669,39 @@ func (mc *monitorComponent) serviceMonitorFluentd() *monitoringv1.ServiceMonitor
 	}
 }
 
+// serviceMonitorFluentdWindows creates a service monitor to make Prometheus watch Fluentd on Windows nodes. The node
+// of the pod is added as the node label, so that the series can be joined with those of windows_exporter.
+func (mc *monitorComponent) serviceMonitorFluentdWindows() *monitoringv1.ServiceMonitor {
+	return &monitoringv1.ServiceMonitor{
+		TypeMeta: metav1.TypeMeta{Kind: monitoringv1.ServiceMonitorsKind, APIVersion: MonitoringAPIVersion},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      render.FluentdWindowsMetricsService,
+			Namespace: common.TigeraPrometheusNamespace,
+			Labels:    map[string]string{"team": "network-operators"},
+		},
+		Spec: monitoringv1.ServiceMonitorSpec{
+			Selector:          metav1.LabelSelector{MatchLabels: map[string]string{"k8s-app": render.FluentdNodeWindowsName}},
+			NamespaceSelector: monitoringv1.NamespaceSelector{MatchNames: []string{render.LogCollectorNamespace}},
+			Endpoints: []monitoringv1.Endpoint{
+				{
+					HonorLabels:   true,
+					Interval:      "5s",
+					Port:          render.FluentdMetricsPortName,
+					ScrapeTimeout: "5s",
+					Scheme:        "https",
+					TLSConfig:     mc.tlsConfig(render.FluentdPrometheusTLSSecretName),
+					RelabelConfigs: []*monitoringv1.RelabelConfig{
+						{
+							SourceLabels: []string{"__meta_kubernetes_pod_node_name"},
+							TargetLabel:  "node",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 func (mc *monitorComponent) serviceMonitorQueryServer() *monitoringv1.ServiceMonitor {
 	return &monitoringv1.ServiceMonitor{
 		TypeMeta: metav1.TypeMeta{Kind: monitoringv1.ServiceMonitorsKind, APIVersion: MonitoringAPIVersion},
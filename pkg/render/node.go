@@ -536,6 +536,17 @@ func (c *nodeComponent) nodeCNIConfigMap() *corev1.ConfigMap {
 		k8sAPIRoot = fmt.Sprintf("\n          \"k8s_api_root\":\"%s\",", apiRoot)
 	}
 
+	// The CNI plugin rotates its log file with the same limits as Felix, if the LogCollector sets them.
+	var logFileRotation string
+	if rotation := c.logFileRotation(); rotation != nil {
+		if rotation.MaxFileSizeMB != nil {
+			logFileRotation += fmt.Sprintf("\n      \"log_file_max_size\": %d,", *rotation.MaxFileSizeMB)
+		}
+		if rotation.MaxFiles != nil {
+			logFileRotation += fmt.Sprintf("\n      \"log_file_max_count\": %d,", *rotation.MaxFiles)
+		}
+	}
+
 	var externalDataplane string = ""
 	if c.vppDataplaneEnabled() {
 		externalDataplane = `,
@@ -556,7 +567,7 @@ func (c *nodeComponent) nodeCNIConfigMap() *corev1.ConfigMap {
       "mtu": %d,
       "nodename_file_optional": %v,
       "log_level": "Info",
-      "log_file_path": "/var/log/calico/cni/cni.log",
+      "log_file_path": "/var/log/calico/cni/cni.log",%s
       "ipam": %s,
       "container_settings": {
           "allow_ip_forwarding": %v
@@ -573,7 +584,7 @@ func (c *nodeComponent) nodeCNIConfigMap() *corev1.ConfigMap {
       "capabilities": {"bandwidth": true}
     }%s
   ]
-}`, mtu, nodenameFileOptional, ipam, ipForward, k8sAPIRoot, externalDataplane, portmap)
+}`, mtu, nodenameFileOptional, logFileRotation, ipam, ipForward, k8sAPIRoot, externalDataplane, portmap)
 
 	return &corev1.ConfigMap{
 		TypeMeta: metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
@@ -948,6 +959,14 @@ func (c *nodeComponent) collectProcessPathEnabled() bool {
 		*c.cfg.LogCollector.Spec.CollectProcessPath == operatorv1.CollectProcessPathEnable
 }
 
+// logFileRotation returns the rotation of the log files in /var/log/calico set in the LogCollector, or nil.
+func (c *nodeComponent) logFileRotation() *operatorv1.LogFileRotation {
+	if c.cfg.LogCollector == nil {
+		return nil
+	}
+	return c.cfg.LogCollector.Spec.LogFileRotation
+}
+
 // cniContainer creates the node's init container that installs CNI.
 func (c *nodeComponent) cniContainer() corev1.Container {
 	// Determine environment to pass to the CNI init container.
@@ -1466,6 +1485,18 @@ func (c *nodeComponent) nodeEnvVars() []corev1.EnvVar {
 				corev1.EnvVar{Name: "FELIX_PROMETHEUSREPORTERCAFILE", Value: c.cfg.TLS.TrustedBundle.MountPath()},
 			)
 		}
+
+		// Felix rotates the flow, DNS and L7 log files that fluentd collects with the limits of the LogCollector.
+		if rotation := c.logFileRotation(); rotation != nil {
+			for _, logType := range []string{"FLOWLOGS", "DNSLOGS", "L7LOGS"} {
+				if rotation.MaxFileSizeMB != nil {
+					extraNodeEnv = append(extraNodeEnv, corev1.EnvVar{Name: fmt.Sprintf("FELIX_%sFILEMAXFILESIZEMB", logType), Value: fmt.Sprintf("%d", *rotation.MaxFileSizeMB)})
+				}
+				if rotation.MaxFiles != nil {
+					extraNodeEnv = append(extraNodeEnv, corev1.EnvVar{Name: fmt.Sprintf("FELIX_%sFILEMAXFILES", logType), Value: fmt.Sprintf("%d", *rotation.MaxFiles)})
+				}
+			}
+		}
 		nodeEnv = append(nodeEnv, extraNodeEnv...)
 	}
 
@@ -3194,6 +3194,37 @@ var _ = Describe("Node rendering tests", func() {
 				Expect(nodeDS.Spec.Template.Spec.InitContainers[1].Image).To(ContainSubstring("-fips"))
 			})
 
+			It("should rotate the log files of Felix and the CNI plugin with the limits of the LogCollector", func() {
+				cfg.Installation.Variant = operatorv1.TigeraSecureEnterprise
+				cfg.LogCollector = &operatorv1.LogCollector{
+					Spec: operatorv1.LogCollectorSpec{
+						LogFileRotation: &operatorv1.LogFileRotation{
+							MaxFileSizeMB: ptr.Int32ToPtr(50),
+							MaxFiles:      ptr.Int32ToPtr(3),
+						},
+					},
+				}
+				certificateManager, err := certificatemanager.Create(cli, nil, clusterDomain)
+				Expect(err).NotTo(HaveOccurred())
+				cfg.PrometheusServerTLS = certificateManager.KeyPair()
+				component := render.Node(&cfg)
+				Expect(component.ResolveImages(nil)).To(BeNil())
+				resources, _ := component.Objects()
+				nodeDS := rtest.GetResource(resources, common.NodeDaemonSetName, common.CalicoNamespace, "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+				Expect(nodeDS.Spec.Template.Spec.Containers[0].Env).To(ContainElements(
+					corev1.EnvVar{Name: "FELIX_FLOWLOGSFILEMAXFILESIZEMB", Value: "50"},
+					corev1.EnvVar{Name: "FELIX_FLOWLOGSFILEMAXFILES", Value: "3"},
+					corev1.EnvVar{Name: "FELIX_DNSLOGSFILEMAXFILESIZEMB", Value: "50"},
+					corev1.EnvVar{Name: "FELIX_DNSLOGSFILEMAXFILES", Value: "3"},
+					corev1.EnvVar{Name: "FELIX_L7LOGSFILEMAXFILESIZEMB", Value: "50"},
+					corev1.EnvVar{Name: "FELIX_L7LOGSFILEMAXFILES", Value: "3"},
+				))
+
+				cniCm := rtest.GetResource(resources, "cni-config", common.CalicoNamespace, "", "v1", "ConfigMap").(*corev1.ConfigMap)
+				Expect(cniCm.Data["config"]).To(ContainSubstring(`"log_file_max_size": 50,`))
+				Expect(cniCm.Data["config"]).To(ContainSubstring(`"log_file_max_count": 3,`))
+			})
+
 			It("should render the correct env and/or images when FIPS mode is enabled (OSS)", func() {
 				fipsEnabled := operatorv1.FIPSModeEnabled
 				cfg.Installation.FIPSMode = &fipsEnabled
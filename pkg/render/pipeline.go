@@ -0,0 +1,91 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+)
+
+// RenderComponents resolves the images of the given components and renders their objects, doing so concurrently for
+// each of the components. It returns components that pass back the rendered objects, in the same order as the given
+// components, so that they can be applied without being rendered again.
+//
+// Components that share state must be safe to render concurrently.
+func RenderComponents(is *operatorv1.ImageSet, comps ...Component) ([]Component, error) {
+	rendered := make([]Component, len(comps))
+	errs := make([]error, len(comps))
+
+	var wg sync.WaitGroup
+	for i, comp := range comps {
+		wg.Add(1)
+		go func(i int, comp Component) {
+			defer wg.Done()
+			if err := comp.ResolveImages(is); err != nil {
+				errs[i] = err
+				return
+			}
+			r := &renderedComponent{ready: comp.Ready(), osType: comp.SupportedOSType()}
+			// Only render components that are ready, the same way the component handler does.
+			if r.ready {
+				r.toCreate, r.toDelete = comp.Objects()
+			}
+			rendered[i] = r
+		}(i, comp)
+	}
+	wg.Wait()
+
+	var errMsgs []string
+	for _, err := range errs {
+		if err != nil {
+			errMsgs = append(errMsgs, err.Error())
+		}
+	}
+	if len(errMsgs) != 0 {
+		return nil, errors.New(strings.Join(errMsgs, ", "))
+	}
+	return rendered, nil
+}
+
+// renderedComponent is an implementation of a Component that passes back objects that were already rendered by
+// another component.
+type renderedComponent struct {
+	toCreate []client.Object
+	toDelete []client.Object
+	ready    bool
+	osType   rmeta.OSType
+}
+
+func (r *renderedComponent) ResolveImages(is *operatorv1.ImageSet) error {
+	return nil
+}
+
+func (r *renderedComponent) Objects() ([]client.Object, []client.Object) {
+	return r.toCreate, r.toDelete
+}
+
+func (r *renderedComponent) Ready() bool {
+	return r.ready
+}
+
+func (r *renderedComponent) SupportedOSType() rmeta.OSType {
+	return r.osType
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/render"
+	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+)
+
+type pipelineTestComponent struct {
+	name       string
+	ready      bool
+	resolveErr error
+	resolved   bool
+}
+
+func (c *pipelineTestComponent) ResolveImages(is *operatorv1.ImageSet) error {
+	c.resolved = true
+	return c.resolveErr
+}
+
+func (c *pipelineTestComponent) Objects() ([]client.Object, []client.Object) {
+	return []client.Object{&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: c.name}}}, nil
+}
+
+func (c *pipelineTestComponent) Ready() bool {
+	return c.ready
+}
+
+func (c *pipelineTestComponent) SupportedOSType() rmeta.OSType {
+	return rmeta.OSTypeLinux
+}
+
+var _ = Describe("Component render pipeline tests", func() {
+	It("should render the components in the given order", func() {
+		comps := []render.Component{
+			&pipelineTestComponent{name: "a", ready: true},
+			&pipelineTestComponent{name: "b", ready: false},
+			&pipelineTestComponent{name: "c", ready: true},
+		}
+
+		rendered, err := render.RenderComponents(nil, comps...)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rendered).To(HaveLen(3))
+
+		for i, name := range []string{"a", "b", "c"} {
+			Expect(comps[i].(*pipelineTestComponent).resolved).To(BeTrue())
+			Expect(rendered[i].Ready()).To(Equal(comps[i].Ready()))
+			Expect(rendered[i].SupportedOSType()).To(Equal(rmeta.OSTypeLinux))
+
+			toCreate, toDelete := rendered[i].Objects()
+			Expect(toDelete).To(BeEmpty())
+			if !comps[i].Ready() {
+				// Components that are not ready are not rendered.
+				Expect(toCreate).To(BeEmpty())
+				continue
+			}
+			Expect(toCreate).To(HaveLen(1))
+			Expect(toCreate[0].GetName()).To(Equal(name))
+		}
+	})
+
+	It("should return the errors of all the components", func() {
+		_, err := render.RenderComponents(nil,
+			&pipelineTestComponent{name: "a", ready: true, resolveErr: fmt.Errorf("bad image a")},
+			&pipelineTestComponent{name: "b", ready: true},
+			&pipelineTestComponent{name: "c", ready: true, resolveErr: fmt.Errorf("bad image c")},
+		)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("bad image a, bad image c"))
+	})
+})
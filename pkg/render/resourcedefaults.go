@@ -0,0 +1,158 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+)
+
+// resourceDefaultsComponent identifies a component in the catalog of default resource requirements.
+type resourceDefaultsComponent string
+
+const (
+	resourceDefaultsElasticsearch resourceDefaultsComponent = "Elasticsearch"
+	resourceDefaultsKibana        resourceDefaultsComponent = "Kibana"
+	resourceDefaultsFluentd       resourceDefaultsComponent = "Fluentd"
+	resourceDefaultsCurator       resourceDefaultsComponent = "EsCurator"
+	resourceDefaultsECKOperator   resourceDefaultsComponent = "ECKOperator"
+
+	// resourceDefaultsFluentdAuditOnly is fluentd when it only collects the audit logs, which are a small fraction of
+	// the volume of the flow and DNS logs.
+	resourceDefaultsFluentdAuditOnly resourceDefaultsComponent = "FluentdAuditOnly"
+)
+
+// resourceDefaults are the resource requirements of the components on providers that don't have a preset. Components
+// that are missing don't have default resource requirements.
+var resourceDefaults = map[resourceDefaultsComponent]corev1.ResourceRequirements{
+	resourceDefaultsElasticsearch: {
+		Limits: corev1.ResourceList{
+			"cpu":    resource.MustParse("1"),
+			"memory": resource.MustParse("4Gi"),
+		},
+		Requests: corev1.ResourceList{
+			"cpu":    resource.MustParse("250m"),
+			"memory": resource.MustParse("4Gi"),
+		},
+	},
+	resourceDefaultsECKOperator: {
+		Limits: corev1.ResourceList{
+			"cpu": resource.MustParse("1"),
+		},
+		Requests: corev1.ResourceList{
+			"cpu": resource.MustParse("100m"),
+		},
+	},
+	resourceDefaultsFluentdAuditOnly: {
+		Limits: corev1.ResourceList{
+			"cpu":    resource.MustParse("200m"),
+			"memory": resource.MustParse("256Mi"),
+		},
+		Requests: corev1.ResourceList{
+			"cpu":    resource.MustParse("50m"),
+			"memory": resource.MustParse("128Mi"),
+		},
+	},
+}
+
+// providerResourceDefaults are the presets of resource requirements of the components per provider, which replace the
+// resourceDefaults of the components. They are sized after the nodes that are typical for the provider, e.g. the m5
+// instances on EKS.
+var providerResourceDefaults = map[operatorv1.Provider]map[resourceDefaultsComponent]corev1.ResourceRequirements{
+	operatorv1.ProviderEKS: {
+		resourceDefaultsElasticsearch: {
+			Limits: corev1.ResourceList{
+				"cpu":    resource.MustParse("2"),
+				"memory": resource.MustParse("8Gi"),
+			},
+			Requests: corev1.ResourceList{
+				"cpu":    resource.MustParse("1"),
+				"memory": resource.MustParse("8Gi"),
+			},
+		},
+		resourceDefaultsKibana: {
+			Limits: corev1.ResourceList{
+				"cpu":    resource.MustParse("1"),
+				"memory": resource.MustParse("2Gi"),
+			},
+			Requests: corev1.ResourceList{
+				"cpu":    resource.MustParse("250m"),
+				"memory": resource.MustParse("1Gi"),
+			},
+		},
+		resourceDefaultsFluentd: {
+			Limits: corev1.ResourceList{
+				"cpu":    resource.MustParse("1"),
+				"memory": resource.MustParse("1Gi"),
+			},
+			Requests: corev1.ResourceList{
+				"cpu":    resource.MustParse("200m"),
+				"memory": resource.MustParse("512Mi"),
+			},
+		},
+		resourceDefaultsCurator: {
+			Limits: corev1.ResourceList{
+				"cpu":    resource.MustParse("500m"),
+				"memory": resource.MustParse("256Mi"),
+			},
+			Requests: corev1.ResourceList{
+				"cpu":    resource.MustParse("100m"),
+				"memory": resource.MustParse("128Mi"),
+			},
+		},
+	},
+}
+
+// presetsProvider returns the provider whose presets are used, which is none unless the presets are enabled, so that
+// existing clusters don't change resources on upgrade.
+func presetsProvider(provider operatorv1.Provider, presets *operatorv1.ProviderResourcePresetsOption) operatorv1.Provider {
+	if presets != nil && *presets == operatorv1.ProviderResourcePresetsEnabled {
+		return provider
+	}
+	return operatorv1.ProviderNone
+}
+
+// defaultResourceRequirements returns a copy of the default resource requirements of the component on the given
+// provider, and whether the provider has a preset for the component.
+func defaultResourceRequirements(provider operatorv1.Provider, component resourceDefaultsComponent) (corev1.ResourceRequirements, bool) {
+	if req, ok := providerResourceDefaults[provider][component]; ok {
+		return *req.DeepCopy(), true
+	}
+	if req, ok := resourceDefaults[component]; ok {
+		return *req.DeepCopy(), false
+	}
+	return corev1.ResourceRequirements{}, false
+}
+
+// componentResourceRequirements returns the resource requirements of the component on the given provider, with the
+// user overrides from the CRs applied. When the component has no defaults, the user overrides are used as they are.
+func componentResourceRequirements(provider operatorv1.Provider, component resourceDefaultsComponent, userOverrides *corev1.ResourceRequirements) corev1.ResourceRequirements {
+	defaults, _ := defaultResourceRequirements(provider, component)
+	if userOverrides == nil {
+		return defaults
+	}
+	if defaults.Limits == nil && defaults.Requests == nil {
+		return *userOverrides.DeepCopy()
+	}
+	if defaults.Limits == nil {
+		defaults.Limits = corev1.ResourceList{}
+	}
+	if defaults.Requests == nil {
+		defaults.Requests = corev1.ResourceList{}
+	}
+	return overrideResourceRequirements(defaults, *userOverrides)
+}
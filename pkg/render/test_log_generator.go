@@ -0,0 +1,144 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/components"
+	"github.com/tigera/operator/pkg/ptr"
+	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/common/secret"
+)
+
+const (
+	TestLogGeneratorName = "tigera-test-log-generator"
+
+	// TestLogGeneratorNodeLabel selects the nodes that the test log generator runs on.
+	TestLogGeneratorNodeLabel = "operator.tigera.io/test-log-generator"
+
+	testLogGeneratorIntervalSeconds = "10"
+)
+
+// testLogGeneratorScript appends a flow log and a DNS log to the files that fluentd tails, at a fixed interval, so
+// that the log pipeline can be validated without real traffic.
+const testLogGeneratorScript = `set -e
+mkdir -p /var/log/calico/flowlogs /var/log/calico/dnslogs
+while true; do
+  NOW=$(date +%s)
+  NOW_RFC3339=$(date -u +%Y-%m-%dT%H:%M:%SZ)
+  BYTES=$((NOW % 10000))
+  echo "{\"start_time\":$((NOW - INTERVAL)),\"end_time\":$NOW,\"source_ip\":\"10.0.0.1\",\"source_name\":\"frontend-1\",\"source_name_aggr\":\"frontend-*\",\"source_namespace\":\"demo\",\"source_port\":null,\"source_type\":\"wep\",\"source_labels\":{\"labels\":[\"app=frontend\"]},\"dest_ip\":\"10.0.0.2\",\"dest_name\":\"backend-1\",\"dest_name_aggr\":\"backend-*\",\"dest_namespace\":\"demo\",\"dest_port\":8080,\"dest_type\":\"wep\",\"dest_labels\":{\"labels\":[\"app=backend\"]},\"proto\":\"tcp\",\"action\":\"allow\",\"reporter\":\"src\",\"policies\":{\"all_policies\":[\"0|default|demo/default.allow-frontend|allow|0\"]},\"bytes_in\":$BYTES,\"bytes_out\":$BYTES,\"num_flows\":1,\"num_flows_started\":1,\"num_flows_completed\":1,\"packets_in\":10,\"packets_out\":10,\"http_requests_allowed_in\":0,\"http_requests_denied_in\":0,\"original_source_ips\":null,\"num_original_source_ips\":0}" >> /var/log/calico/flowlogs/flows.log
+  echo "{\"start_time\":\"$NOW_RFC3339\",\"end_time\":\"$NOW_RFC3339\",\"type\":\"log\",\"count\":1,\"client_ip\":null,\"client_name\":\"-\",\"client_name_aggr\":\"frontend-*\",\"client_namespace\":\"demo\",\"client_labels\":{\"app\":\"frontend\"},\"qname\":\"backend.demo.svc.cluster.local\",\"qclass\":\"IN\",\"qtype\":\"A\",\"rcode\":\"NoError\",\"rrsets\":[{\"name\":\"backend.demo.svc.cluster.local\",\"class\":\"IN\",\"type\":\"A\",\"rdata\":[\"10.0.0.2\"]}],\"servers\":[{\"name\":\"coredns-1\",\"name_aggr\":\"coredns-*\",\"namespace\":\"kube-system\",\"ip\":\"10.0.0.10\",\"labels\":{\"k8s-app\":\"kube-dns\"}}]}" >> /var/log/calico/dnslogs/dns.log
+  sleep "$INTERVAL"
+done
+`
+
+// TestLogGeneratorConfiguration contains all the config information needed to render the component.
+type TestLogGeneratorConfiguration struct {
+	Installation *operatorv1.InstallationSpec
+	PullSecrets  []*corev1.Secret
+	// Enabled is false when the operator isn't started with the test log generator flag, in which case the objects of
+	// the component are deleted.
+	Enabled bool
+}
+
+// TestLogGenerator renders a DaemonSet that writes flow and DNS logs to /var/log/calico on the nodes labeled with
+// TestLogGeneratorNodeLabel=true. It is meant for demos and CI only.
+func TestLogGenerator(cfg *TestLogGeneratorConfiguration) Component {
+	return &testLogGeneratorComponent{cfg: cfg}
+}
+
+type testLogGeneratorComponent struct {
+	cfg   *TestLogGeneratorConfiguration
+	image string
+}
+
+func (c *testLogGeneratorComponent) ResolveImages(is *operatorv1.ImageSet) error {
+	// The fluentd image has a shell, and is already pulled on the nodes.
+	var err error
+	c.image, err = components.GetReference(components.ComponentFluentd, c.cfg.Installation.Registry, c.cfg.Installation.ImagePath, c.cfg.Installation.ImagePrefix, is)
+	return err
+}
+
+func (c *testLogGeneratorComponent) SupportedOSType() rmeta.OSType {
+	return rmeta.OSTypeLinux
+}
+
+func (c *testLogGeneratorComponent) Objects() ([]client.Object, []client.Object) {
+	objs := []client.Object{
+		c.serviceAccount(),
+		c.daemonSet(),
+	}
+	if !c.cfg.Enabled {
+		return nil, objs
+	}
+	return objs, nil
+}
+
+func (c *testLogGeneratorComponent) Ready() bool {
+	return true
+}
+
+func (c *testLogGeneratorComponent) serviceAccount() *corev1.ServiceAccount {
+	return &corev1.ServiceAccount{
+		TypeMeta:   metav1.TypeMeta{Kind: "ServiceAccount", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: TestLogGeneratorName, Namespace: LogCollectorNamespace},
+	}
+}
+
+func (c *testLogGeneratorComponent) daemonSet() *appsv1.DaemonSet {
+	dirOrCreate := corev1.HostPathDirectoryOrCreate
+	return &appsv1.DaemonSet{
+		TypeMeta: metav1.TypeMeta{Kind: "DaemonSet", APIVersion: "apps/v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      TestLogGeneratorName,
+			Namespace: LogCollectorNamespace,
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"k8s-app": TestLogGeneratorName}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"k8s-app": TestLogGeneratorName},
+				},
+				Spec: corev1.PodSpec{
+					NodeSelector:                 map[string]string{TestLogGeneratorNodeLabel: "true"},
+					Tolerations:                  rmeta.TolerateAll,
+					ImagePullSecrets:             secret.GetReferenceList(c.cfg.PullSecrets),
+					ServiceAccountName:           TestLogGeneratorName,
+					AutomountServiceAccountToken: ptr.BoolToPtr(false),
+					Containers: []corev1.Container{{
+						Name:            TestLogGeneratorName,
+						Image:           c.image,
+						Command:         []string{"/bin/sh", "-c", testLogGeneratorScript},
+						Env:             []corev1.EnvVar{{Name: "INTERVAL", Value: testLogGeneratorIntervalSeconds}},
+						SecurityContext: &corev1.SecurityContext{Privileged: ptr.BoolToPtr(false)},
+						VolumeMounts:    []corev1.VolumeMount{{Name: "var-log-calico", MountPath: "/var/log/calico"}},
+					}},
+					Volumes: []corev1.Volume{{
+						Name: "var-log-calico",
+						VolumeSource: corev1.VolumeSource{
+							HostPath: &corev1.HostPathVolumeSource{Path: "/var/log/calico", Type: &dirOrCreate},
+						},
+					}},
+				},
+			},
+		},
+	}
+}
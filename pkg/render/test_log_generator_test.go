@@ -0,0 +1,62 @@
+// Copyright (c) 2022 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/render"
+	rtest "github.com/tigera/operator/pkg/render/common/test"
+)
+
+var _ = Describe("Test log generator rendering tests", func() {
+	var cfg *render.TestLogGeneratorConfiguration
+
+	BeforeEach(func() {
+		cfg = &render.TestLogGeneratorConfiguration{
+			Installation: &operatorv1.InstallationSpec{},
+			Enabled:      true,
+		}
+	})
+
+	It("should render the generator on the labeled nodes", func() {
+		component := render.TestLogGenerator(cfg)
+		Expect(component.ResolveImages(nil)).NotTo(HaveOccurred())
+
+		toCreate, toDelete := component.Objects()
+		Expect(toDelete).To(BeEmpty())
+		Expect(toCreate).To(HaveLen(2))
+		rtest.ExpectResource(toCreate[0], render.TestLogGeneratorName, render.LogCollectorNamespace, "", "v1", "ServiceAccount")
+		rtest.ExpectResource(toCreate[1], render.TestLogGeneratorName, render.LogCollectorNamespace, "apps", "v1", "DaemonSet")
+
+		ds := toCreate[1].(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.NodeSelector).To(Equal(map[string]string{render.TestLogGeneratorNodeLabel: "true"}))
+		Expect(ds.Spec.Template.Spec.Volumes[0].HostPath.Path).To(Equal("/var/log/calico"))
+		Expect(ds.Spec.Template.Spec.Containers[0].Image).To(ContainSubstring("fluentd"))
+	})
+
+	It("should delete the generator when it isn't enabled", func() {
+		cfg.Enabled = false
+		component := render.TestLogGenerator(cfg)
+		Expect(component.ResolveImages(nil)).NotTo(HaveOccurred())
+
+		toCreate, toDelete := component.Objects()
+		Expect(toCreate).To(BeEmpty())
+		Expect(toDelete).To(HaveLen(2))
+	})
+})
@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"fmt"
 
+	"github.com/tigera/operator/pkg/common"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -96,6 +97,8 @@ func (t *trustedBundle) Volume() corev1.Volume {
 	}
 }
 
+// ConfigMap returns the ConfigMap of the bundle in the namespace. The bundle is a copy of the certificates that the
+// operator keeps in its namespace, and is annotated with its CA as the source.
 func (t *trustedBundle) ConfigMap(namespace string) *corev1.ConfigMap {
 	pemBuf := bytes.Buffer{}
 	for _, cert := range t.certificates {
@@ -105,8 +108,9 @@ func (t *trustedBundle) ConfigMap(namespace string) *corev1.ConfigMap {
 	return &corev1.ConfigMap{
 		TypeMeta: metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      TrustedCertConfigMapName,
-			Namespace: namespace,
+			Name:        TrustedCertConfigMapName,
+			Namespace:   namespace,
+			Annotations: map[string]string{rmeta.CopySourceAnnotation: common.OperatorNamespace() + "/" + CASecretName},
 		},
 		Data: map[string]string{
 			TrustedCertConfigMapKeyName: pemBuf.String(),
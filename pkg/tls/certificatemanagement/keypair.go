@@ -21,6 +21,7 @@ import (
 	"fmt"
 
 	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/common"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -59,8 +60,10 @@ func (k *KeyPair) BYO() bool {
 	return !k.UseCertificateManagement() && k.Issuer == nil
 }
 
+// Secret returns the secret of the key pair in the namespace. The secrets outside of the namespace of the operator are
+// copies of the one in the namespace of the operator, and are annotated with it as their source.
 func (k *KeyPair) Secret(namespace string) *corev1.Secret {
-	return &corev1.Secret{
+	s := &corev1.Secret{
 		TypeMeta:   metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
 		ObjectMeta: metav1.ObjectMeta{Name: k.GetName(), Namespace: namespace},
 		Data: map[string][]byte{
@@ -68,6 +71,10 @@ func (k *KeyPair) Secret(namespace string) *corev1.Secret {
 			corev1.TLSCertKey:       k.CertificatePEM,
 		},
 	}
+	if namespace != "" && namespace != common.OperatorNamespace() {
+		s.Annotations = map[string]string{rmeta.CopySourceAnnotation: common.OperatorNamespace() + "/" + k.GetName()}
+	}
+	return s
 }
 
 func (k *KeyPair) HashAnnotationKey() string {